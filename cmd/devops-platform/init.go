@@ -12,7 +12,7 @@ import (
 func init() {
 	beans.RegisterStopWaiter(func() {
 		logrus.Info("服务启动成功...")
-		quit := make(chan os.Signal)
+		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, os.Interrupt)
 		s := <-quit
 		logrus.WithField("signal", s.String()).Info("接收到停止信号")
@@ -15,4 +15,5 @@ const (
 	BeanLog      = domain.BeanLog
 	BeanApp      = domain.BeanApp
 	BeanCasbin   = domain.BeanCasbin
+	BeanTekton   = domain.BeanTekton
 )
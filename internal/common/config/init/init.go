@@ -27,4 +27,5 @@ func init() {
 	beans.Register(domain.BeanLog, &conf.Log)
 	beans.Register(domain.BeanApp, &conf.App)
 	beans.Register(domain.BeanCasbin, &conf.Casbin)
+	beans.Register(domain.BeanTekton, &conf.Tekton)
 }
@@ -6,4 +6,5 @@ const (
 	BeanLog      = "config-log"
 	BeanApp      = "config-app"
 	BeanCasbin   = "config-casbin"
+	BeanTekton   = "config-tekton"
 )
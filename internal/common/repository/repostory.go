@@ -22,10 +22,11 @@ func (r *Repository) Inject(getBean func(string) interface{}) {
 	r.db = db
 }
 
+// DB 获取数据库连接，存在事务时使用事务连接；上下文会传递给GORM回调（如审计日志）
 func (r *Repository) DB(ctx context.Context) *gorm.DB {
 	db, ok := ctx.Value(database.BeanDB).(*gorm.DB)
 	if !ok {
-		return r.db
+		db = r.db
 	}
-	return db
+	return db.WithContext(ctx)
 }
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"context"
 	"devops-platform/internal/common/web/internal/domain"
 	"devops-platform/internal/common/web/internal/service"
 	"devops-platform/internal/pkg/security"
@@ -14,6 +15,7 @@ const (
 	ModeUnitTesting          = domain.ModeUnitTesting
 	ModeKey                  = domain.ModeKey
 	BeanAuthenticationVerify = domain.BeanAuthenticationVerify
+	RequestIDHeader          = domain.RequestIDHeader
 )
 
 func SetCurrentUser(ctx *gin.Context, user *security.UserContext) {
@@ -27,3 +29,12 @@ func Authenticated(ctx *gin.Context) bool {
 func AddIgnoreUrls(urls ...string) {
 	service.AddIgnoreUrls(urls...)
 }
+
+// RequestID 获取当前请求ID
+func RequestID(ctx context.Context) string {
+	return service.RequestID(ctx)
+}
+
+func IsIgnoreUrl(url string) bool {
+	return service.IsIgnoreUrl(url)
+}
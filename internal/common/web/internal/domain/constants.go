@@ -1,5 +1,7 @@
 package domain
 
+import "devops-platform/internal/pkg/common"
+
 //注册bean
 const (
 	BeanGinEngine            = "ginEngine"
@@ -7,6 +9,8 @@ const (
 	BeanAuthenticationVerify = "BeanAuthenticationVerify"
 	RealContext              = "real-context"
 	ErrKeyInContext          = "err"
+	RequestIDKey             = common.RequestIDKey
+	RequestIDHeader          = "X-Request-ID"
 )
 
 const (
@@ -7,6 +7,7 @@ import (
 	"devops-platform/pkg/beans"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"net/http"
 	"os"
@@ -74,7 +75,7 @@ func (s *HttpServerLifecycle) PreInject(getBean func(string) interface{}) {
 
 	router := gin.New()
 
-	router.Use(s.log, gin.Recovery(),
+	router.Use(requestID, s.log, gin.Recovery(),
 		func(c *gin.Context) {
 			logrus.Debugf("请求方法[%s],请求地址[%s]", c.Request.Method, c.Request.RequestURI)
 			cors.New(corsConfig())(c)
@@ -101,9 +102,9 @@ func corsConfig() cors.Config {
 		//准许使用的请求方式
 		AllowMethods: []string{"PUT", "PATCH", "POST", "GET", "DELETE"},
 		//准许使用的请求表头
-		AllowHeaders: []string{"Origin", "Authorization", "Content-Type"},
+		AllowHeaders: []string{"Origin", "Authorization", "Content-Type", domain.RequestIDHeader},
 		//显示的请求表头
-		ExposeHeaders: []string{"Content-Type"},
+		ExposeHeaders: []string{"Content-Type", "Content-Disposition", domain.RequestIDHeader},
 		//凭证共享,确定共享
 		AllowCredentials: true,
 		//容许跨域的原点网站,可以直接return true就万事大吉了
@@ -128,7 +129,8 @@ func (s *HttpServerLifecycle) log(c *gin.Context) {
 
 	logger := logrus.WithField("latencyTime", time.Now().Sub(startTime)).
 		WithField("HttpStatus", statusCode).
-		WithField("clientIP", c.ClientIP())
+		WithField("clientIP", c.ClientIP()).
+		WithField("requestID", c.GetString(domain.RequestIDKey))
 
 	/*
 	 * 打印日志中加err
@@ -150,3 +152,14 @@ func (s *HttpServerLifecycle) log(c *gin.Context) {
 		logger.Error(c.Request.Method, " ", c.Request.RequestURI)
 	}
 }
+
+// requestID 为每个请求分配请求ID，优先使用调用方传入的X-Request-ID
+func requestID(c *gin.Context) {
+	id := c.GetHeader(domain.RequestIDHeader)
+	if id == "" || len(id) > 64 {
+		id = uuid.NewString()
+	}
+	c.Set(domain.RequestIDKey, id)
+	c.Header(domain.RequestIDHeader, id)
+	c.Next()
+}
@@ -36,6 +36,17 @@ func SetCurrentUser(ctx *gin.Context, user *security.UserContext) {
 	SetContext(ctx, realContext)
 }
 
+// RequestID 获取当前请求ID，支持由gin上下文派生的上下文
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(domain.RequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
 func Authenticated(ctx *gin.Context) bool {
 	return CurrentUser(ctx) != nil
 }
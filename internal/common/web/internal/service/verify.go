@@ -19,15 +19,22 @@ func AddIgnoreUrls(urls ...string) {
 	ignoreUrls = append(ignoreUrls, urls...)
 }
 
-func Verify(ctx *gin.Context) {
-
-	requestURI := ctx.Request.RequestURI
+// IsIgnoreUrl 判断地址是否无需认证
+func IsIgnoreUrl(requestURI string) bool {
 	for _, url := range ignoreUrls {
 		if strings.HasPrefix(requestURI, url) {
-			ctx.Next()
-			return
+			return true
 		}
 	}
+	return false
+}
+
+func Verify(ctx *gin.Context) {
+
+	if IsIgnoreUrl(ctx.Request.RequestURI) {
+		ctx.Next()
+		return
+	}
 
 	if !Authenticated(ctx) {
 		AbortErr(ctx, domain.NewUnauthorizedError("对不起，认证不通过，请登录"))
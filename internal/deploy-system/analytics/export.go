@@ -0,0 +1,17 @@
+package analytics
+
+import (
+	"devops-platform/internal/deploy-system/analytics/internal/domain"
+)
+
+// Bean常量
+const (
+	BeanAnalyticsService = domain.BeanAnalyticsService
+)
+
+// 领域对象类型别名
+type (
+	DoraQuery   = domain.DoraQuery
+	DoraReport  = domain.DoraReport
+	DoraMetrics = domain.DoraMetrics
+)
@@ -0,0 +1,25 @@
+package init
+
+import (
+	"devops-platform/internal/deploy-system/analytics/internal/controller"
+	"devops-platform/internal/deploy-system/analytics/internal/domain"
+	"devops-platform/internal/deploy-system/analytics/internal/repository"
+	"devops-platform/internal/deploy-system/analytics/internal/service"
+	"devops-platform/pkg/beans"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 使用标准的init函数进行初始化
+func init() {
+	// 注册仓储
+	beans.Register(domain.BeanAnalyticsRepository, repository.NewRepository())
+
+	// 注册服务
+	beans.Register(domain.BeanAnalyticsService, service.NewAnalyticsService())
+
+	// 注册控制器
+	beans.Register(domain.BeanAnalyticsController, controller.NewAnalyticsController())
+
+	logrus.Info("部署分析模块初始化完成")
+}
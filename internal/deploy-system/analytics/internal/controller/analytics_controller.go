@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/analytics/internal/domain"
+	"devops-platform/internal/deploy-system/analytics/internal/service"
+	"devops-platform/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsController 部署分析控制器
+type AnalyticsController struct {
+	web.Controller
+	Service *service.AnalyticsService `inject:"AnalyticsService"`
+}
+
+// NewAnalyticsController 创建部署分析控制器实例
+func NewAnalyticsController() *AnalyticsController {
+	return &AnalyticsController{}
+}
+
+// GetDoraMetrics 查询DORA指标
+// @Summary 查询DORA指标
+// @Description 根据部署历史统计部署频率、变更失败率、平均恢复时间与变更前置时间，可按应用、应用分组或部门以及天、周、月划分
+// @Tags 部署分析
+// @Produce json
+// @Param start_time query string false "开始时间，格式 2006-01-02 15:04:05，默认结束时间前30天"
+// @Param end_time query string false "结束时间，格式 2006-01-02 15:04:05，默认当前时间"
+// @Param group_by query string false "统计维度: app, group, department"
+// @Param bucket query string false "时间粒度: day, week, month"
+// @Param app_id query int false "应用ID"
+// @Param env_id query int false "环境ID"
+// @Success 200 {object} common.Response{data=domain.DoraReport}
+// @Router /api/v1/analytics/dora [get]
+func (c *AnalyticsController) GetDoraMetrics(ctx *gin.Context) {
+	var query domain.DoraQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	report, err := c.Service.GetDoraMetrics(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, report)
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Inject 实现依赖注入
+func (c *AnalyticsController) Inject(getBean func(string) interface{}) {
+	c.injectRouting(getBean)
+}
+
+// injectRouting 注入路由
+func (c *AnalyticsController) injectRouting(getBean func(string) interface{}) {
+	router, ok := getBean(web.BeanGinEngine).(gin.IRouter)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", web.BeanGinEngine)
+		return
+	}
+
+	// 部署分析路由组
+	analyticsGroup := router.Group("/api/v1/analytics")
+	analyticsGroup.Use(middleware.JWTAuth())
+	{
+		// DORA指标
+		analyticsGroup.GET("/dora", c.GetDoraMetrics)
+	}
+}
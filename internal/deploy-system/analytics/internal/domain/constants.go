@@ -0,0 +1,40 @@
+package domain
+
+import "devops-platform/internal/pkg/enum"
+
+const (
+	// 模块Bean名称常量
+	BeanAnalyticsRepository = "AnalyticsRepository"
+	BeanAnalyticsService    = "AnalyticsService"
+	BeanAnalyticsController = "AnalyticsController"
+
+	// 统计维度
+	GroupByNone       = ""           // 不分组，只统计整体
+	GroupByApp        = "app"        // 按应用
+	GroupByAppGroup   = "group"      // 按应用分组
+	GroupByDepartment = "department" // 按应用所属部门
+
+	// 时间粒度
+	BucketNone  = ""      // 不按时间划分
+	BucketDay   = "day"   // 按天
+	BucketWeek  = "week"  // 按周，周一开始
+	BucketMonth = "month" // 按月
+
+	// 部署状态，与应用模块的部署记录一致
+	DeployStatusSuccess = "success"
+	DeployStatusFailed  = "failed"
+
+	// RollbackVersionSuffix 回滚部署的版本后缀，回滚部署本身不计入部署次数
+	RollbackVersionSuffix = "-rollback"
+
+	// BuildStatusSuccess 成功的构建，用于计算变更前置时间
+	BuildStatusSuccess = enum.BuildStatusSuccess
+
+	// DefaultDays 未指定时间范围时统计最近的天数
+	DefaultDays = 30
+	// MaxDays 单次统计的最大天数
+	MaxDays = 366
+
+	// UnassignedName 应用不属于任何分组或部门、或应用已删除时的统计名称
+	UnassignedName = "未归属"
+)
@@ -0,0 +1,257 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DoraQuery DORA指标查询条件
+type DoraQuery struct {
+	StartTime string     `form:"start_time"`
+	EndTime   string     `form:"end_time"`
+	GroupBy   string     `form:"group_by"`
+	Bucket    string     `form:"bucket"`
+	AppID     types.Long `form:"app_id"`
+	EnvID     types.Long `form:"env_id"`
+
+	Start time.Time `form:"-"`
+	End   time.Time `form:"-"`
+}
+
+// Validate 校验并解析查询条件，时间格式为 2006-01-02 15:04:05，默认统计最近30天
+func (q *DoraQuery) Validate() error {
+	q.GroupBy = strings.TrimSpace(q.GroupBy)
+	switch q.GroupBy {
+	case GroupByNone, GroupByApp, GroupByAppGroup, GroupByDepartment:
+	default:
+		return common.RequestParamError("", fmt.Errorf("不支持的统计维度: %s", q.GroupBy))
+	}
+	q.Bucket = strings.TrimSpace(q.Bucket)
+	switch q.Bucket {
+	case BucketNone, BucketDay, BucketWeek, BucketMonth:
+	default:
+		return common.RequestParamError("", fmt.Errorf("不支持的时间粒度: %s", q.Bucket))
+	}
+
+	var err error
+	q.End = time.Now()
+	if q.EndTime != "" {
+		q.End, err = time.ParseInLocation(types.TimeFormat, q.EndTime, time.Local)
+		if err != nil {
+			return common.RequestParamError("", errors.New("结束时间格式错误，应为 "+types.TimeFormat))
+		}
+	}
+	q.Start = q.End.AddDate(0, 0, -DefaultDays)
+	if q.StartTime != "" {
+		q.Start, err = time.ParseInLocation(types.TimeFormat, q.StartTime, time.Local)
+		if err != nil {
+			return common.RequestParamError("", errors.New("开始时间格式错误，应为 "+types.TimeFormat))
+		}
+	}
+	if !q.End.After(q.Start) {
+		return common.RequestParamError("", errors.New("结束时间必须晚于开始时间"))
+	}
+	if q.End.Sub(q.Start) > MaxDays*24*time.Hour {
+		return common.RequestParamError("", fmt.Errorf("统计时间范围不能超过%d天", MaxDays))
+	}
+	return nil
+}
+
+// DeployCountRow 按应用和日期汇总的部署次数
+type DeployCountRow struct {
+	AppID types.Long
+	// 部署开始日期，格式 2006-01-02
+	Day string
+	// 结束的部署次数（成功或失败），不含回滚部署本身
+	Total     int64
+	Successes int64
+	// 失败或被回滚的部署次数
+	Failures int64
+}
+
+// FailureRow 失败或被回滚的部署，以及之后第一次成功恢复该环境的部署
+type FailureRow struct {
+	ID       types.Long
+	AppID    types.Long
+	Day      string
+	FailedAt *time.Time
+	// 之后同一应用环境中第一次成功的部署，0表示尚未恢复
+	RestoredBy types.Long
+}
+
+// DeploymentEndRow 部署的结束时间
+type DeploymentEndRow struct {
+	ID      types.Long
+	EndTime *time.Time
+}
+
+// LeadTimeRow 成功的部署及其版本对应的构建
+type LeadTimeRow struct {
+	AppID      types.Long
+	Day        string
+	DeployedAt *time.Time
+	BuiltAt    *time.Time
+}
+
+// MemberRow 应用所属的统计对象（应用、应用分组或部门）
+type MemberRow struct {
+	AppID      types.Long
+	MemberID   types.Long
+	MemberName string
+}
+
+// DoraReport DORA指标统计结果
+type DoraReport struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	GroupBy   string    `json:"group_by"`
+	Bucket    string    `json:"bucket"`
+	// 整体指标，应用同时属于多个分组时只计一次
+	Summary *DoraMetrics `json:"summary"`
+	// 整体指标的时间序列，未指定时间粒度时为空
+	Buckets []*DoraBucket `json:"buckets,omitempty"`
+	// 按统计维度分组的指标，未指定统计维度时为空
+	Groups []*DoraGroup `json:"groups,omitempty"`
+}
+
+// DoraGroup 一个应用、应用分组或部门的指标
+type DoraGroup struct {
+	// 应用、应用分组或部门ID，0表示未归属
+	ID      types.Long    `json:"id"`
+	Name    string        `json:"name"`
+	Metrics *DoraMetrics  `json:"metrics"`
+	Buckets []*DoraBucket `json:"buckets,omitempty"`
+}
+
+// DoraBucket 一个时间段的指标
+type DoraBucket struct {
+	// 时间段开始日期，格式 2006-01-02
+	Start   string       `json:"start"`
+	Metrics *DoraMetrics `json:"metrics"`
+}
+
+// DoraMetrics DORA四项指标及其样本数
+type DoraMetrics struct {
+	// 结束的部署次数（成功或失败），不含回滚部署本身
+	Deployments int64 `json:"deployments"`
+	Successes   int64 `json:"successes"`
+	// 失败或被回滚的部署次数
+	Failures int64 `json:"failures"`
+	// 部署频率：平均每天成功的部署次数
+	DeploymentFrequency float64 `json:"deployment_frequency"`
+	// 变更失败率：失败或被回滚的部署占结束部署的比例，0~1
+	ChangeFailureRate float64 `json:"change_failure_rate"`
+	// 已恢复的失败次数
+	Restores int64 `json:"restores"`
+	// 平均恢复时间（秒）：从失败的部署结束到同一环境下一次成功部署结束，没有已恢复的失败时为null
+	MeanTimeToRestore *float64 `json:"mean_time_to_restore"`
+	// 有构建记录的成功部署次数
+	LeadTimeSamples int64 `json:"lead_time_samples"`
+	// 平均变更前置时间（秒）：从版本的构建创建到部署结束，没有构建记录时为null
+	LeadTime *float64 `json:"lead_time"`
+}
+
+// MetricsAccumulator 累加部署、恢复与前置时间样本，最后计算指标
+type MetricsAccumulator struct {
+	deployments, successes, failures int64
+	restores                         int64
+	restoreSeconds                   float64
+	leadTimes                        int64
+	leadSeconds                      float64
+}
+
+// AddCounts 累加部署次数
+func (a *MetricsAccumulator) AddCounts(row *DeployCountRow) {
+	a.deployments += row.Total
+	a.successes += row.Successes
+	a.failures += row.Failures
+}
+
+// AddRestore 累加一次恢复时间
+func (a *MetricsAccumulator) AddRestore(d time.Duration) {
+	a.restores++
+	a.restoreSeconds += d.Seconds()
+}
+
+// AddLeadTime 累加一次变更前置时间
+func (a *MetricsAccumulator) AddLeadTime(d time.Duration) {
+	a.leadTimes++
+	a.leadSeconds += d.Seconds()
+}
+
+// Metrics 按统计天数计算指标
+func (a *MetricsAccumulator) Metrics(days float64) *DoraMetrics {
+	metrics := &DoraMetrics{
+		Deployments:     a.deployments,
+		Successes:       a.successes,
+		Failures:        a.failures,
+		Restores:        a.restores,
+		LeadTimeSamples: a.leadTimes,
+	}
+	if days > 0 {
+		metrics.DeploymentFrequency = round(float64(a.successes)/days, 4)
+	}
+	if a.deployments > 0 {
+		metrics.ChangeFailureRate = round(float64(a.failures)/float64(a.deployments), 4)
+	}
+	if a.restores > 0 {
+		mttr := round(a.restoreSeconds/float64(a.restores), 1)
+		metrics.MeanTimeToRestore = &mttr
+	}
+	if a.leadTimes > 0 {
+		leadTime := round(a.leadSeconds/float64(a.leadTimes), 1)
+		metrics.LeadTime = &leadTime
+	}
+	return metrics
+}
+
+// round 保留指定位数的小数
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
+
+// BucketStart 日期所在时间段的开始日期，按周时从周一开始
+func BucketStart(day time.Time, bucket string) time.Time {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+	switch bucket {
+	case BucketWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case BucketMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+// NextBucket 下一个时间段的开始日期
+func NextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// ParseDay 解析数据库返回的日期，兼容 2006-01-02 与带时间的格式
+func ParseDay(value string) (time.Time, error) {
+	if len(value) > len(time.DateOnly) {
+		value = value[:len(time.DateOnly)]
+	}
+	return time.ParseInLocation(time.DateOnly, value, time.Local)
+}
+
+// Days 时间范围的天数
+func Days(start, end time.Time) float64 {
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours() / 24
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"devops-platform/internal/common/repository"
+	"devops-platform/internal/deploy-system/analytics/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// Repository 部署分析仓储，直接汇总部署历史、构建与应用归属数据
+type Repository struct {
+	repository.Repository
+}
+
+// NewRepository 创建仓储实例
+func NewRepository() *Repository {
+	return &Repository{}
+}
+
+// failureCondition 失败或被回滚的部署
+const failureCondition = "(d.status = ? OR EXISTS (SELECT 1 FROM deploy_history r WHERE r.rollback_of = d.id))"
+
+// CountDeployments 按应用和部署开始日期汇总结束的部署次数、成功次数与失败次数
+func (r *Repository) CountDeployments(ctx context.Context, query *domain.DoraQuery) ([]*domain.DeployCountRow, error) {
+	var rows []*domain.DeployCountRow
+	err := r.deployments(ctx, query).
+		Select("d.app_id, DATE(d.start_time) AS day, COUNT(*) AS total, "+
+			"SUM(CASE WHEN d.status = ? THEN 1 ELSE 0 END) AS successes, "+
+			"SUM(CASE WHEN "+failureCondition+" THEN 1 ELSE 0 END) AS failures",
+			domain.DeployStatusSuccess, domain.DeployStatusFailed).
+		Group("d.app_id, DATE(d.start_time)").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ListFailures 查询失败或被回滚的部署，以及之后同一应用环境中第一次成功的部署
+func (r *Repository) ListFailures(ctx context.Context, query *domain.DoraQuery) ([]*domain.FailureRow, error) {
+	var rows []*domain.FailureRow
+	err := r.deployments(ctx, query).
+		Select("d.id, d.app_id, DATE(d.start_time) AS day, d.end_time AS failed_at, "+
+			"COALESCE((SELECT MIN(s.id) FROM deploy_history s WHERE s.app_id = d.app_id AND s.env_id = d.env_id "+
+			"AND s.id > d.id AND s.status = ?), 0) AS restored_by", domain.DeployStatusSuccess).
+		Where(failureCondition, domain.DeployStatusFailed).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ListDeploymentEnds 查询部署的结束时间
+func (r *Repository) ListDeploymentEnds(ctx context.Context, ids []types.Long) ([]*domain.DeploymentEndRow, error) {
+	var rows []*domain.DeploymentEndRow
+	if len(ids) == 0 {
+		return rows, nil
+	}
+	err := r.DB(ctx).Table("deploy_history").Select("id, end_time").Where("id IN ?", ids).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ListLeadTimes 查询成功的部署及其版本（镜像tag）最早一次成功构建的创建时间，没有构建记录的部署不返回
+func (r *Repository) ListLeadTimes(ctx context.Context, query *domain.DoraQuery) ([]*domain.LeadTimeRow, error) {
+	var rows []*domain.LeadTimeRow
+	err := r.deployments(ctx, query).
+		Select("d.app_id, DATE(d.start_time) AS day, d.end_time AS deployed_at, b.created_at AS built_at").
+		Joins("JOIN app_build b ON b.id = (SELECT MIN(b2.id) FROM app_build b2 "+
+			"WHERE b2.app_id = d.app_id AND b2.image_tag = d.version AND b2.status = ?)", domain.BuildStatusSuccess).
+		Where("d.status = ?", domain.DeployStatusSuccess).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ListMembers 查询应用所属的统计对象：应用本身、应用分组或部门，不属于任何对象的应用不返回
+func (r *Repository) ListMembers(ctx context.Context, groupBy string, appIDs []types.Long) ([]*domain.MemberRow, error) {
+	var rows []*domain.MemberRow
+	if len(appIDs) == 0 {
+		return rows, nil
+	}
+	var db *gorm.DB
+	switch groupBy {
+	case domain.GroupByApp:
+		db = r.DB(ctx).Table("app").Select("id AS app_id, id AS member_id, name AS member_name").Where("id IN ?", appIDs)
+	case domain.GroupByAppGroup:
+		db = r.DB(ctx).Table("relation_app_group_app r").
+			Select("r.app_id, g.id AS member_id, g.name AS member_name").
+			Joins("JOIN app_group g ON g.id = r.group_id").
+			Where("r.app_id IN ?", appIDs)
+	case domain.GroupByDepartment:
+		db = r.DB(ctx).Table("app a").
+			Select("a.id AS app_id, dept.id AS member_id, dept.name AS member_name").
+			Joins("JOIN department dept ON dept.id = a.dept_id").
+			Where("a.id IN ?", appIDs)
+	default:
+		return rows, nil
+	}
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// deployments 统计范围内结束的部署（成功或失败），回滚部署本身不计入
+func (r *Repository) deployments(ctx context.Context, query *domain.DoraQuery) *gorm.DB {
+	db := r.DB(ctx).Table("deploy_history d").
+		Where("d.status IN ?", []string{domain.DeployStatusSuccess, domain.DeployStatusFailed}).
+		Where("d.rollback_of = 0 AND d.version NOT LIKE ?", "%"+domain.RollbackVersionSuffix).
+		Where("d.start_time >= ? AND d.start_time < ?", query.Start, query.End)
+	if query.AppID > 0 {
+		db = db.Where("d.app_id = ?", query.AppID)
+	}
+	if query.EnvID > 0 {
+		db = db.Where("d.env_id = ?", query.EnvID)
+	}
+	return db
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/analytics/internal/domain"
+	"devops-platform/internal/deploy-system/analytics/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"sort"
+	"time"
+)
+
+// AnalyticsService 部署分析服务
+type AnalyticsService struct {
+	Repo *repository.Repository `inject:"AnalyticsRepository"`
+}
+
+// NewAnalyticsService 创建部署分析服务实例
+func NewAnalyticsService() *AnalyticsService {
+	return &AnalyticsService{}
+}
+
+// GetDoraMetrics 统计部署频率、变更失败率、平均恢复时间与变更前置时间，可按应用、应用分组或部门以及时间段划分
+func (s *AnalyticsService) GetDoraMetrics(ctx context.Context, query *domain.DoraQuery) (*domain.DoraReport, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.Repo.CountDeployments(ctx, query)
+	if err != nil {
+		return nil, common.InternalError("统计部署次数失败", err)
+	}
+	failures, err := s.Repo.ListFailures(ctx, query)
+	if err != nil {
+		return nil, common.InternalError("查询失败部署失败", err)
+	}
+	restoredAt, err := s.restoredAt(ctx, failures)
+	if err != nil {
+		return nil, err
+	}
+	leadTimes, err := s.Repo.ListLeadTimes(ctx, query)
+	if err != nil {
+		return nil, common.InternalError("查询变更前置时间失败", err)
+	}
+	members, err := s.members(ctx, query.GroupBy, counts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(query)
+	for _, row := range counts {
+		report.add(row.AppID, row.Day, members, func(a *domain.MetricsAccumulator) {
+			a.AddCounts(row)
+		})
+	}
+	for _, row := range failures {
+		restored, ok := restoredAt[row.RestoredBy]
+		if row.FailedAt == nil || !ok || restored.Before(*row.FailedAt) {
+			continue
+		}
+		report.add(row.AppID, row.Day, members, func(a *domain.MetricsAccumulator) {
+			a.AddRestore(restored.Sub(*row.FailedAt))
+		})
+	}
+	for _, row := range leadTimes {
+		if row.DeployedAt == nil || row.BuiltAt == nil || row.DeployedAt.Before(*row.BuiltAt) {
+			continue
+		}
+		report.add(row.AppID, row.Day, members, func(a *domain.MetricsAccumulator) {
+			a.AddLeadTime(row.DeployedAt.Sub(*row.BuiltAt))
+		})
+	}
+	return report.build(), nil
+}
+
+// restoredAt 查询恢复失败的部署的结束时间
+func (s *AnalyticsService) restoredAt(ctx context.Context, failures []*domain.FailureRow) (map[types.Long]time.Time, error) {
+	ids := make([]types.Long, 0, len(failures))
+	seen := make(map[types.Long]bool)
+	for _, row := range failures {
+		if row.RestoredBy > 0 && !seen[row.RestoredBy] {
+			seen[row.RestoredBy] = true
+			ids = append(ids, row.RestoredBy)
+		}
+	}
+	rows, err := s.Repo.ListDeploymentEnds(ctx, ids)
+	if err != nil {
+		return nil, common.InternalError("查询恢复部署失败", err)
+	}
+	result := make(map[types.Long]time.Time, len(rows))
+	for _, row := range rows {
+		if row.EndTime != nil {
+			result[row.ID] = *row.EndTime
+		}
+	}
+	return result, nil
+}
+
+// members 查询统计范围内的应用所属的统计对象，应用可能属于多个应用分组
+func (s *AnalyticsService) members(ctx context.Context, groupBy string, counts []*domain.DeployCountRow) (map[types.Long][]*domain.MemberRow, error) {
+	if groupBy == domain.GroupByNone {
+		return nil, nil
+	}
+	appIDs := make([]types.Long, 0)
+	seen := make(map[types.Long]bool)
+	for _, row := range counts {
+		if !seen[row.AppID] {
+			seen[row.AppID] = true
+			appIDs = append(appIDs, row.AppID)
+		}
+	}
+	rows, err := s.Repo.ListMembers(ctx, groupBy, appIDs)
+	if err != nil {
+		return nil, common.InternalError("查询应用归属失败", err)
+	}
+	result := make(map[types.Long][]*domain.MemberRow, len(appIDs))
+	for _, row := range rows {
+		result[row.AppID] = append(result[row.AppID], row)
+	}
+	return result, nil
+}
+
+// doraReport 统计过程中的累加结果，按统计对象与时间段划分
+type doraReport struct {
+	query   *domain.DoraQuery
+	summary *series
+	groups  map[types.Long]*series
+}
+
+// series 一个统计对象的整体累加结果及各时间段的累加结果
+type series struct {
+	id      types.Long
+	name    string
+	total   domain.MetricsAccumulator
+	buckets map[string]*domain.MetricsAccumulator
+}
+
+func newReport(query *domain.DoraQuery) *doraReport {
+	return &doraReport{
+		query:   query,
+		summary: newSeries(0, ""),
+		groups:  make(map[types.Long]*series),
+	}
+}
+
+func newSeries(id types.Long, name string) *series {
+	return &series{id: id, name: name, buckets: make(map[string]*domain.MetricsAccumulator)}
+}
+
+// add 将应用在某天的样本累加到整体、应用所属的每个统计对象及对应的时间段
+func (r *doraReport) add(appID types.Long, day string, members map[types.Long][]*domain.MemberRow, apply func(a *domain.MetricsAccumulator)) {
+	bucket := ""
+	if r.query.Bucket != domain.BucketNone {
+		if t, err := domain.ParseDay(day); err == nil {
+			bucket = domain.BucketStart(t, r.query.Bucket).Format(time.DateOnly)
+		}
+	}
+
+	targets := []*series{r.summary}
+	if r.query.GroupBy != domain.GroupByNone {
+		owners := members[appID]
+		if len(owners) == 0 {
+			owners = []*domain.MemberRow{{AppID: appID, MemberName: domain.UnassignedName}}
+		}
+		for _, owner := range owners {
+			group, ok := r.groups[owner.MemberID]
+			if !ok {
+				group = newSeries(owner.MemberID, owner.MemberName)
+				r.groups[owner.MemberID] = group
+			}
+			targets = append(targets, group)
+		}
+	}
+
+	for _, target := range targets {
+		apply(&target.total)
+		if bucket == "" {
+			continue
+		}
+		acc, ok := target.buckets[bucket]
+		if !ok {
+			acc = &domain.MetricsAccumulator{}
+			target.buckets[bucket] = acc
+		}
+		apply(acc)
+	}
+}
+
+// build 计算指标，时间段覆盖整个统计范围，没有部署的时间段指标为0
+func (r *doraReport) build() *domain.DoraReport {
+	report := &domain.DoraReport{
+		StartTime: r.query.Start,
+		EndTime:   r.query.End,
+		GroupBy:   r.query.GroupBy,
+		Bucket:    r.query.Bucket,
+		Summary:   r.summary.total.Metrics(domain.Days(r.query.Start, r.query.End)),
+		Buckets:   r.buckets(r.summary),
+	}
+	if r.query.GroupBy == domain.GroupByNone {
+		return report
+	}
+
+	report.Groups = make([]*domain.DoraGroup, 0, len(r.groups))
+	for _, group := range r.groups {
+		report.Groups = append(report.Groups, &domain.DoraGroup{
+			ID:      group.id,
+			Name:    group.name,
+			Metrics: group.total.Metrics(domain.Days(r.query.Start, r.query.End)),
+			Buckets: r.buckets(group),
+		})
+	}
+	// 部署次数多的排在前面，未归属的排在最后
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if (a.ID == 0) != (b.ID == 0) {
+			return b.ID == 0
+		}
+		if a.Metrics.Deployments != b.Metrics.Deployments {
+			return a.Metrics.Deployments > b.Metrics.Deployments
+		}
+		return a.ID < b.ID
+	})
+	return report
+}
+
+// buckets 统计范围内各时间段的指标，部署频率按时间段与统计范围重叠的天数计算
+func (r *doraReport) buckets(s *series) []*domain.DoraBucket {
+	if r.query.Bucket == domain.BucketNone {
+		return nil
+	}
+	var result []*domain.DoraBucket
+	for start := domain.BucketStart(r.query.Start, r.query.Bucket); start.Before(r.query.End); {
+		end := domain.NextBucket(start, r.query.Bucket)
+		from, to := start, end
+		if from.Before(r.query.Start) {
+			from = r.query.Start
+		}
+		if to.After(r.query.End) {
+			to = r.query.End
+		}
+		acc, ok := s.buckets[start.Format(time.DateOnly)]
+		if !ok {
+			acc = &domain.MetricsAccumulator{}
+		}
+		result = append(result, &domain.DoraBucket{
+			Start:   start.Format(time.DateOnly),
+			Metrics: acc.Metrics(domain.Days(from, to)),
+		})
+		start = end
+	}
+	return result
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/analytics/internal/domain"
+	"devops-platform/internal/deploy-system/analytics/internal/repository"
+	"devops-platform/pkg/types"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testSchema 统计用到的表，只包含查询涉及的字段
+var testSchema = []string{
+	"CREATE TABLE app (id INTEGER PRIMARY KEY, name TEXT, dept_id INTEGER DEFAULT 0)",
+	"CREATE TABLE app_group (id INTEGER PRIMARY KEY, name TEXT)",
+	"CREATE TABLE relation_app_group_app (id INTEGER PRIMARY KEY AUTOINCREMENT, group_id INTEGER, app_id INTEGER)",
+	"CREATE TABLE department (id INTEGER PRIMARY KEY, name TEXT)",
+	"CREATE TABLE deploy_history (id INTEGER PRIMARY KEY, app_id INTEGER, env_id INTEGER, version TEXT, status TEXT, " +
+		"start_time DATETIME, end_time DATETIME, rollback_of INTEGER DEFAULT 0)",
+	"CREATE TABLE app_build (id INTEGER PRIMARY KEY AUTOINCREMENT, app_id INTEGER, image_tag TEXT, status INTEGER, created_at DATETIME)",
+}
+
+// newTestService 使用内存SQLite创建部署分析服务并写入种子数据
+func newTestService(t *testing.T) *AnalyticsService {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	for _, stmt := range testSchema {
+		if err = db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	seed(t, db)
+
+	repo := repository.NewRepository()
+	repo.Inject(func(string) interface{} { return db })
+	return &AnalyticsService{Repo: repo}
+}
+
+// at 2026年9月的本地时间
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 9, day, hour, minute, 0, 0, time.Local)
+}
+
+// seed 两个应用的部署历史：
+//
+//	应用1（部门10，分组100）环境1：
+//	  1 成功 v1，有构建，前置时间70分钟
+//	  2 失败 v2，由部署3恢复，恢复时间75分钟
+//	  3 成功 v3，有构建，前置时间60分钟
+//	  4 成功 v4，被部署5回滚，恢复时间30分钟
+//	  5 回滚部署，不计入部署次数
+//	应用2（无部门，分组100、101）环境2：
+//	  6 成功 v1，没有构建
+//	  7 失败，尚未恢复
+//	  8 运行中，不计入
+//	  9 统计范围之外，不计入
+func seed(t *testing.T, db *gorm.DB) {
+	statements := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{"INSERT INTO department (id, name) VALUES (10, '交易')", nil},
+		{"INSERT INTO app (id, name, dept_id) VALUES (1, 'order', 10), (2, 'user', 0)", nil},
+		{"INSERT INTO app_group (id, name) VALUES (100, 'core'), (101, 'edge')", nil},
+		{"INSERT INTO relation_app_group_app (group_id, app_id) VALUES (100, 1), (100, 2), (101, 2)", nil},
+	}
+	deployments := []struct {
+		id, appID, envID int
+		version, status  string
+		start, end       time.Time
+		rollbackOf       int
+	}{
+		{1, 1, 1, "v1", "success", at(1, 10, 0), at(1, 10, 10), 0},
+		{2, 1, 1, "v2", "failed", at(2, 10, 0), at(2, 10, 5), 0},
+		{3, 1, 1, "v3", "success", at(2, 11, 0), at(2, 11, 20), 0},
+		{4, 1, 1, "v4", "success", at(8, 10, 0), at(8, 10, 10), 0},
+		{5, 1, 1, "v3-rollback", "success", at(8, 10, 30), at(8, 10, 40), 4},
+		{6, 2, 2, "v1", "success", at(3, 12, 0), at(3, 12, 5), 0},
+		{7, 2, 2, "v2", "failed", at(9, 12, 0), at(9, 12, 10), 0},
+		{8, 2, 2, "v3", "running", at(10, 12, 0), time.Time{}, 0},
+		{9, 2, 2, "v0", "success", time.Date(2026, 8, 20, 12, 0, 0, 0, time.Local), time.Date(2026, 8, 20, 12, 5, 0, 0, time.Local), 0},
+	}
+	for _, d := range deployments {
+		var end interface{}
+		if !d.end.IsZero() {
+			end = d.end
+		}
+		statements = append(statements, struct {
+			sql  string
+			args []interface{}
+		}{"INSERT INTO deploy_history (id, app_id, env_id, version, status, start_time, end_time, rollback_of) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			[]interface{}{d.id, d.appID, d.envID, d.version, d.status, d.start, end, d.rollbackOf}})
+	}
+	builds := []struct {
+		appID   int
+		tag     string
+		status  int
+		created time.Time
+	}{
+		{1, "v1", 3, at(1, 9, 0)},
+		{1, "v1", 3, at(1, 9, 30)}, // 同一版本重复构建，取最早的一次
+		{1, "v2", -1, at(2, 9, 0)}, // 失败的构建不计入
+		{1, "v3", 3, at(2, 10, 20)},
+	}
+	for _, b := range builds {
+		statements = append(statements, struct {
+			sql  string
+			args []interface{}
+		}{"INSERT INTO app_build (app_id, image_tag, status, created_at) VALUES (?, ?, ?, ?)",
+			[]interface{}{b.appID, b.tag, b.status, b.created}})
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt.sql, stmt.args...).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// query 统计 2026-09-01 至 2026-09-15 共14天
+func query(groupBy, bucket string) *domain.DoraQuery {
+	return &domain.DoraQuery{
+		StartTime: "2026-09-01 00:00:00",
+		EndTime:   "2026-09-15 00:00:00",
+		GroupBy:   groupBy,
+		Bucket:    bucket,
+	}
+}
+
+func assertMetrics(t *testing.T, name string, got *domain.DoraMetrics, want *domain.DoraMetrics) {
+	t.Helper()
+	if got.Deployments != want.Deployments || got.Successes != want.Successes || got.Failures != want.Failures ||
+		got.Restores != want.Restores || got.LeadTimeSamples != want.LeadTimeSamples {
+		t.Errorf("%s: 次数 = %+v, 期望 %+v", name, got, want)
+	}
+	if got.DeploymentFrequency != want.DeploymentFrequency {
+		t.Errorf("%s: 部署频率 = %v, 期望 %v", name, got.DeploymentFrequency, want.DeploymentFrequency)
+	}
+	if got.ChangeFailureRate != want.ChangeFailureRate {
+		t.Errorf("%s: 变更失败率 = %v, 期望 %v", name, got.ChangeFailureRate, want.ChangeFailureRate)
+	}
+	assertSeconds(t, name+": 平均恢复时间", got.MeanTimeToRestore, want.MeanTimeToRestore)
+	assertSeconds(t, name+": 变更前置时间", got.LeadTime, want.LeadTime)
+}
+
+func assertSeconds(t *testing.T, name string, got, want *float64) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, 期望 %v", name, got, want)
+	case *got != *want:
+		t.Errorf("%s = %v, 期望 %v", name, *got, *want)
+	}
+}
+
+func seconds(v float64) *float64 {
+	return &v
+}
+
+func TestGetDoraMetricsSummary(t *testing.T) {
+	s := newTestService(t)
+	report, err := s.GetDoraMetrics(context.Background(), query("", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 6次结束的部署，4次成功；部署2、7失败，部署4被回滚
+	assertMetrics(t, "整体", report.Summary, &domain.DoraMetrics{
+		Deployments:         6,
+		Successes:           4,
+		Failures:            3,
+		DeploymentFrequency: 0.2857,
+		ChangeFailureRate:   0.5,
+		Restores:            2,
+		MeanTimeToRestore:   seconds((75*60 + 30*60) / 2),
+		LeadTimeSamples:     2,
+		LeadTime:            seconds((70*60 + 60*60) / 2),
+	})
+	if report.Buckets != nil || report.Groups != nil {
+		t.Errorf("未指定维度与粒度时不应返回分组或时间段: %+v %+v", report.Buckets, report.Groups)
+	}
+}
+
+func TestGetDoraMetricsFilter(t *testing.T) {
+	s := newTestService(t)
+	q := query("", "")
+	q.AppID = 2
+	report, err := s.GetDoraMetrics(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertMetrics(t, "应用2", report.Summary, &domain.DoraMetrics{
+		Deployments:         2,
+		Successes:           1,
+		Failures:            1,
+		DeploymentFrequency: 0.0714,
+		ChangeFailureRate:   0.5,
+	})
+}
+
+func TestGetDoraMetricsGroupBy(t *testing.T) {
+	s := newTestService(t)
+
+	tests := []struct {
+		groupBy string
+		want    map[types.Long]int64 // 统计对象ID -> 部署次数
+		names   map[types.Long]string
+		order   []types.Long
+	}{
+		{domain.GroupByApp, map[types.Long]int64{1: 4, 2: 2}, map[types.Long]string{1: "order", 2: "user"}, []types.Long{1, 2}},
+		// 应用2属于两个分组，两个分组都计入
+		{domain.GroupByAppGroup, map[types.Long]int64{100: 6, 101: 2}, map[types.Long]string{100: "core", 101: "edge"}, []types.Long{100, 101}},
+		// 应用2没有部门，计入未归属并排在最后
+		{domain.GroupByDepartment, map[types.Long]int64{10: 4, 0: 2}, map[types.Long]string{10: "交易", 0: domain.UnassignedName}, []types.Long{10, 0}},
+	}
+	for _, tt := range tests {
+		report, err := s.GetDoraMetrics(context.Background(), query(tt.groupBy, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Groups) != len(tt.order) {
+			t.Fatalf("%s: 分组数 = %d, 期望 %d", tt.groupBy, len(report.Groups), len(tt.order))
+		}
+		for i, group := range report.Groups {
+			if group.ID != tt.order[i] {
+				t.Errorf("%s: 第%d个分组 = %s, 期望 %s", tt.groupBy, i, group.ID, tt.order[i])
+			}
+			if group.Metrics.Deployments != tt.want[group.ID] || group.Name != tt.names[group.ID] {
+				t.Errorf("%s: 分组 %s(%s) 部署次数 = %d, 期望 %s(%d)", tt.groupBy, group.ID, group.Name,
+					group.Metrics.Deployments, tt.names[group.ID], tt.want[group.ID])
+			}
+		}
+		// 整体指标不因应用属于多个分组而重复计算
+		if report.Summary.Deployments != 6 {
+			t.Errorf("%s: 整体部署次数 = %d, 期望 6", tt.groupBy, report.Summary.Deployments)
+		}
+	}
+
+	report, err := s.GetDoraMetrics(context.Background(), query(domain.GroupByDepartment, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertMetrics(t, "部门10", report.Groups[0].Metrics, &domain.DoraMetrics{
+		Deployments:         4,
+		Successes:           3,
+		Failures:            2,
+		DeploymentFrequency: 0.2143,
+		ChangeFailureRate:   0.5,
+		Restores:            2,
+		MeanTimeToRestore:   seconds((75*60 + 30*60) / 2),
+		LeadTimeSamples:     2,
+		LeadTime:            seconds((70*60 + 60*60) / 2),
+	})
+}
+
+func TestGetDoraMetricsBuckets(t *testing.T) {
+	s := newTestService(t)
+	report, err := s.GetDoraMetrics(context.Background(), query(domain.GroupByApp, domain.BucketWeek))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2026-09-01 是周二，统计范围覆盖三周，第一周与最后一周只统计范围内的天数
+	if len(report.Buckets) != 3 {
+		t.Fatalf("时间段数 = %d, 期望 3", len(report.Buckets))
+	}
+	wantStarts := []string{"2026-08-31", "2026-09-07", "2026-09-14"}
+	for i, bucket := range report.Buckets {
+		if bucket.Start != wantStarts[i] {
+			t.Errorf("第%d个时间段 = %s, 期望 %s", i, bucket.Start, wantStarts[i])
+		}
+	}
+	assertMetrics(t, "第一周", report.Buckets[0].Metrics, &domain.DoraMetrics{
+		Deployments:         4,
+		Successes:           3,
+		Failures:            1,
+		DeploymentFrequency: 0.5,
+		ChangeFailureRate:   0.25,
+		Restores:            1,
+		MeanTimeToRestore:   seconds(75 * 60),
+		LeadTimeSamples:     2,
+		LeadTime:            seconds((70*60 + 60*60) / 2),
+	})
+	assertMetrics(t, "第二周", report.Buckets[1].Metrics, &domain.DoraMetrics{
+		Deployments:         2,
+		Successes:           1,
+		Failures:            2,
+		DeploymentFrequency: 0.1429,
+		ChangeFailureRate:   1,
+		Restores:            1,
+		MeanTimeToRestore:   seconds(30 * 60),
+	})
+	assertMetrics(t, "第三周", report.Buckets[2].Metrics, &domain.DoraMetrics{})
+
+	// 每个分组都有完整的时间序列
+	for _, group := range report.Groups {
+		if len(group.Buckets) != 3 {
+			t.Errorf("应用 %s 时间段数 = %d, 期望 3", group.ID, len(group.Buckets))
+		}
+	}
+	if got := report.Groups[1].Buckets[1].Metrics.Failures; got != 1 {
+		t.Errorf("应用2第二周失败次数 = %d, 期望 1", got)
+	}
+}
+
+func TestDoraQueryValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query domain.DoraQuery
+		ok    bool
+	}{
+		{"默认最近30天", domain.DoraQuery{}, true},
+		{"不支持的维度", domain.DoraQuery{GroupBy: "env"}, false},
+		{"不支持的粒度", domain.DoraQuery{Bucket: "year"}, false},
+		{"时间格式错误", domain.DoraQuery{StartTime: "2026-09-01"}, false},
+		{"结束早于开始", domain.DoraQuery{StartTime: "2026-09-02 00:00:00", EndTime: "2026-09-01 00:00:00"}, false},
+		{"超过最大天数", domain.DoraQuery{StartTime: "2025-01-01 00:00:00", EndTime: "2026-09-01 00:00:00"}, false},
+	}
+	for _, tt := range tests {
+		err := tt.query.Validate()
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+	q := domain.DoraQuery{}
+	if err := q.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !q.Start.Equal(q.End.AddDate(0, 0, -domain.DefaultDays)) {
+		t.Errorf("默认开始时间 = %v, 期望结束时间前%d天", q.Start, domain.DefaultDays)
+	}
+}
@@ -84,6 +84,9 @@ type AppQueryServer interface {
 	// GetAppEnvByID 根据ID获取应用环境
 	GetAppEnvByID(ctx context.Context, id types.Long) (*domain.AppEnv, error)
 
+	// GetImageRegistryByID 根据ID获取镜像仓库
+	GetImageRegistryByID(ctx context.Context, id types.Long) (*domain.ImageRegistry, error)
+
 	// ListImageRegistries 查询镜像仓库列表
 	ListImageRegistries(ctx context.Context) ([]*domain.ImageRegistry, error)
 
@@ -96,6 +99,9 @@ type DeployService interface {
 	// CreateReleasePlan 创建发布计划
 	CreateReleasePlan(ctx context.Context, command *domain.CreateReleaseCommand) (types.Long, error)
 
+	// ApproveReleasePlan 审批发布计划
+	ApproveReleasePlan(ctx context.Context, planID types.Long) error
+
 	// ExecuteReleasePlan 执行发布计划
 	ExecuteReleasePlan(ctx context.Context, planID types.Long) (types.Long, error)
 
@@ -130,3 +136,6 @@ type CreateEnvCommand = domain.CreateEnvCommand
 type CreateReleaseCommand = domain.CreateReleaseCommand
 type AppQuery = domain.AppQuery
 type AppVO = domain.AppVO
+type CommitRange = domain.CommitRange
+type BuildCommit = domain.BuildCommit
+type TemplateBuildConfig = domain.TemplateBuildConfig
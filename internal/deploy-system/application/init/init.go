@@ -5,6 +5,8 @@ import (
 	"devops-platform/internal/deploy-system/application/internal/domain"
 	"devops-platform/internal/deploy-system/application/internal/repository"
 	"devops-platform/internal/deploy-system/application/internal/service"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/internal/pkg/periodic"
 	"devops-platform/pkg/beans"
 
 	"github.com/sirupsen/logrus"
@@ -14,10 +16,46 @@ func init() {
 	// 注册仓储层
 	beans.Register(domain.BeanAppRepository, repository.NewAppRepository())
 
+	// 注册集群客户端，不在集群内运行时无法检查集群中的资源
+	cluster := &service.KubeClusterClient{}
+	if client, err := kube.NewInClusterClient(); err != nil {
+		logrus.WithError(err).Warn("应用集群客户端不可用，将跳过集群资源检查，Job部署将无法执行")
+	} else {
+		cluster.Client = client
+	}
+	beans.Register(domain.BeanClusterClient, cluster)
+
 	// 注册服务层
+	deployService := service.NewDeployService()
+	trainService := service.NewTrainService()
+	driftService := service.NewDriftService()
 	beans.Register(domain.BeanAppService, service.NewAppService())
-	beans.Register(domain.BeanDeployService, service.NewDeployService())
+	beans.Register(domain.BeanDeployService, deployService)
 	beans.Register(domain.BeanAppQuery, service.NewAppQuery())
+	beans.Register(domain.BeanConfigService, service.NewConfigService())
+	beans.Register(domain.BeanVolumeService, service.NewVolumeService())
+	beans.Register(domain.BeanWorkloadService, service.NewWorkloadService())
+	beans.Register(domain.BeanHPAService, service.NewHPAService())
+	beans.Register(domain.BeanMemberService, service.NewMemberService())
+	beans.Register(domain.BeanPromotionService, service.NewPromotionService())
+	beans.Register(domain.BeanTrainService, trainService)
+	beans.Register(domain.BeanLockService, service.NewLockService())
+	beans.Register(domain.BeanReleaseDiffService, service.NewReleaseDiffService())
+	beans.Register(domain.BeanDriftService, driftService)
+	beans.Register(domain.BeanTemplateService, service.NewTemplateService())
+	beans.Register(domain.BeanCatalogService, service.NewCatalogService())
+
+	// 注册定时任务运行记录同步任务
+	beans.Register(domain.BeanJobWatcher, periodic.New("定时任务运行记录同步", domain.JobSyncInterval, deployService.SyncJobRuns))
+
+	// 注册发布列车推进任务，服务重启后继续推进未结束的列车
+	beans.Register(domain.BeanTrainWatcher, periodic.New("发布列车推进", domain.TrainSyncInterval, trainService.AdvanceTrains))
+
+	// 注册部署队列任务，部署租约过期未释放时由新的部署接管
+	beans.Register(domain.BeanDeployQueueWatcher, periodic.New("部署队列", domain.DeployQueueInterval, deployService.DrainDeployQueues))
+
+	// 注册配置漂移检测任务
+	beans.Register(domain.BeanDriftWatcher, periodic.New("配置漂移检测", domain.DriftCheckInterval, driftService.ReconcileDrift))
 
 	// 注册控制器
 	beans.Register(domain.BeanController, controller.NewAppController())
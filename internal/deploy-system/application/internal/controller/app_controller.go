@@ -16,9 +16,21 @@ import (
 // AppController 应用管理控制器
 type AppController struct {
 	web.Controller
-	AppService    *service.AppService
-	DeployService *service.DeployService
-	AppQuery      *service.AppQuery
+	AppService       *service.AppService
+	DeployService    *service.DeployService
+	AppQuery         *service.AppQuery
+	ConfigService    *service.ConfigService
+	VolumeService    *service.VolumeService
+	WorkloadService  *service.WorkloadService
+	HPAService       *service.HPAService
+	MemberService    *service.MemberService
+	PromotionService *service.PromotionService
+	TrainService     *service.TrainService
+	LockService      *service.LockService
+	DiffService      *service.ReleaseDiffService
+	DriftService     *service.DriftService
+	TemplateService  *service.TemplateService
+	CatalogService   *service.CatalogService
 }
 
 // NewAppController 创建应用管理控制器
@@ -54,6 +66,90 @@ func (c *AppController) InjectService(getBean func(string) interface{}) {
 		return
 	}
 	c.AppQuery = appQuery
+
+	configService, ok := getBean(domain.BeanConfigService).(*service.ConfigService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanConfigService)
+		return
+	}
+	c.ConfigService = configService
+
+	volumeService, ok := getBean(domain.BeanVolumeService).(*service.VolumeService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanVolumeService)
+		return
+	}
+	c.VolumeService = volumeService
+
+	workloadService, ok := getBean(domain.BeanWorkloadService).(*service.WorkloadService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanWorkloadService)
+		return
+	}
+	c.WorkloadService = workloadService
+
+	hpaService, ok := getBean(domain.BeanHPAService).(*service.HPAService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanHPAService)
+		return
+	}
+	c.HPAService = hpaService
+
+	memberService, ok := getBean(domain.BeanMemberService).(*service.MemberService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanMemberService)
+		return
+	}
+	c.MemberService = memberService
+
+	promotionService, ok := getBean(domain.BeanPromotionService).(*service.PromotionService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanPromotionService)
+		return
+	}
+	c.PromotionService = promotionService
+
+	trainService, ok := getBean(domain.BeanTrainService).(*service.TrainService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanTrainService)
+		return
+	}
+	c.TrainService = trainService
+
+	lockService, ok := getBean(domain.BeanLockService).(*service.LockService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanLockService)
+		return
+	}
+	c.LockService = lockService
+
+	diffService, ok := getBean(domain.BeanReleaseDiffService).(*service.ReleaseDiffService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanReleaseDiffService)
+		return
+	}
+	c.DiffService = diffService
+
+	driftService, ok := getBean(domain.BeanDriftService).(*service.DriftService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanDriftService)
+		return
+	}
+	c.DriftService = driftService
+
+	templateService, ok := getBean(domain.BeanTemplateService).(*service.TemplateService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanTemplateService)
+		return
+	}
+	c.TemplateService = templateService
+
+	catalogService, ok := getBean(domain.BeanCatalogService).(*service.CatalogService)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanCatalogService)
+		return
+	}
+	c.CatalogService = catalogService
 }
 
 // CreateApplication 创建应用
@@ -146,6 +242,10 @@ func (c *AppController) GetApplication(ctx *gin.Context) {
 	groups, _ := c.AppQuery.GetAppGroups(ctx, app.ID)
 	app.Groups = groups
 
+	// 获取各环境挂载的共享存储卷
+	volumes, _ := c.VolumeService.ListMounts(ctx, app.ID, 0)
+	app.Volumes = volumes
+
 	common.ResponseSuccess(ctx, app)
 }
 
@@ -354,13 +454,39 @@ func (c *AppController) CreateReleasePlan(ctx *gin.Context) {
 	})
 }
 
+// ApproveReleasePlan 审批发布计划
+// @Summary 审批发布计划
+// @Description 审批待处理的发布计划，并通知订阅了 plan.approved 事件的渠道
+// @Tags 发布管理
+// @Produce json
+// @Param id path int true "发布计划ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/releases/{id}/approve [post]
+func (c *AppController) ApproveReleasePlan(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的发布计划ID")
+		return
+	}
+
+	if err := c.DeployService.ApproveReleasePlan(ctx, types.Long(id)); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, nil)
+}
+
 // ExecuteReleasePlan 执行发布计划
 // @Summary 执行发布计划
-// @Description 执行发布计划
+// @Description 执行发布计划；应用正在该环境部署时返回409，queue为true时排队，当前部署结束后自动执行
 // @Tags 发布管理
 // @Produce json
 // @Param id path int true "发布计划ID"
+// @Param queue query bool false "部署锁被占用时是否排队"
 // @Success 200 {object} common.Response
+// @Failure 409 {object} common.ErrorResponse{data=domain.DeployLock}
 // @Router /api/v1/releases/{id}/execute [post]
 func (c *AppController) ExecuteReleasePlan(ctx *gin.Context) {
 	idStr := ctx.Param("id")
@@ -370,7 +496,19 @@ func (c *AppController) ExecuteReleasePlan(ctx *gin.Context) {
 		return
 	}
 
-	deployID, err := c.DeployService.ExecuteReleasePlan(ctx, types.Long(id))
+	queue, err := strconv.ParseBool(ctx.DefaultQuery("queue", "false"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的queue参数")
+		return
+	}
+
+	var deployID types.Long
+	var queued bool
+	if queue {
+		deployID, queued, err = c.DeployService.ExecuteOrQueueReleasePlan(ctx, types.Long(id))
+	} else {
+		deployID, err = c.DeployService.ExecuteReleasePlan(ctx, types.Long(id))
+	}
 	if err != nil {
 		common.ResponseError(ctx, err)
 		return
@@ -378,6 +516,7 @@ func (c *AppController) ExecuteReleasePlan(ctx *gin.Context) {
 
 	common.ResponseSuccess(ctx, gin.H{
 		"deploy_id": deployID,
+		"queued":    queued,
 	})
 }
 
@@ -474,12 +613,12 @@ func (c *AppController) RollbackDeployment(ctx *gin.Context) {
 
 // ConfigureHPA 配置应用HPA
 // @Summary 配置应用HPA
-// @Description 配置应用HPA自动伸缩
+// @Description 以CPU、内存目标使用率的简写方式配置应用默认HPA，已有的Pod指标、外部指标与伸缩行为保持不变
 // @Tags 应用管理
 // @Accept json
 // @Produce json
 // @Param id path int true "应用ID"
-// @Param data body object true "HPA配置"
+// @Param data body domain.ConfigureHPACommand true "HPA配置"
 // @Success 200 {object} common.Response
 // @Router /api/v1/apps/{id}/hpa [post]
 func (c *AppController) ConfigureHPA(ctx *gin.Context) {
@@ -490,31 +629,20 @@ func (c *AppController) ConfigureHPA(ctx *gin.Context) {
 		return
 	}
 
-	var params struct {
-		MinReplicas  int `json:"min_replicas" binding:"required,min=1"`
-		MaxReplicas  int `json:"max_replicas" binding:"required,min=1"`
-		TargetCPU    int `json:"target_cpu" binding:"required,min=1,max=100"`
-		TargetMemory int `json:"target_memory"`
-	}
-	if err := ctx.ShouldBindJSON(&params); err != nil {
+	var command domain.ConfigureHPACommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
 		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
 		return
 	}
 
-	// 最大副本数必须大于等于最小副本数
-	if params.MaxReplicas < params.MinReplicas {
-		common.ResponseBadRequest(ctx, "最大副本数必须大于等于最小副本数")
-		return
-	}
-
-	id, err := c.DeployService.CreateHPA(ctx, types.Long(appID), params.MinReplicas, params.MaxReplicas, params.TargetCPU, params.TargetMemory)
+	hpa, err := c.HPAService.ConfigureHPA(ctx, types.Long(appID), &command)
 	if err != nil {
 		common.ResponseError(ctx, err)
 		return
 	}
 
 	common.ResponseSuccess(ctx, gin.H{
-		"id": id,
+		"id": hpa.ID,
 	})
 }
 
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// catalogContentTypes 应用目录各格式的Content-Type
+var catalogContentTypes = map[string]string{
+	domain.CatalogFormatYAML: "application/x-yaml; charset=utf-8",
+	domain.CatalogFormatJSON: "application/json; charset=utf-8",
+	domain.CatalogFormatCSV:  "text/csv; charset=utf-8",
+}
+
+// ExportAppCatalog 导出应用目录
+// @Summary 导出应用目录
+// @Description 导出全部应用分组、环境，以及当前用户有数据权限的未删除应用（含所属分组、关联的镜像仓库与默认HPA），
+// @Description 分组与镜像仓库按名称引用，导出的文件可直接用于导入。CSV的HPA只包含CPU、内存目标使用率
+// @Tags 应用目录
+// @Produce json,x-yaml,text/csv
+// @Param format query string false "文件格式: yaml(默认)/json/csv"
+// @Success 200 {file} file
+// @Router /api/v1/app-catalog/export [get]
+func (c *AppController) ExportAppCatalog(ctx *gin.Context) {
+	format := strings.ToLower(ctx.DefaultQuery("format", domain.CatalogFormatYAML))
+	contentType, ok := catalogContentTypes[format]
+	if !ok {
+		common.ResponseBadRequest(ctx, "参数错误: format只支持yaml、json或csv")
+		return
+	}
+	doc, err := c.CatalogService.ExportCatalog(ctx)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	data, err := domain.EncodeCatalog(format, doc)
+	if err != nil {
+		common.ResponseError(ctx, common.InternalError("导出应用目录失败", err))
+		return
+	}
+
+	filename := fmt.Sprintf("app-catalog-%s.%s", time.Now().Format("20060102150405"), format)
+	ctx.Header("Content-Disposition", "attachment; filename="+filename)
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// ImportAppCatalog 导入应用目录
+// @Summary 导入应用目录
+// @Description 请求体为YAML、JSON或CSV文件内容，未指定format时按Content-Type判断，默认YAML。
+// @Description 整个文件为一批，在一个事务中导入；任一行出错时整批不导入，返回每一行的错误。
+// @Description create模式下同名记录已存在时报错，upsert模式按名称更新已存在的记录，已有的分组、镜像仓库关联不会移除
+// @Tags 应用目录
+// @Accept json,x-yaml,text/csv
+// @Produce json
+// @Param format query string false "文件格式: yaml/json/csv"
+// @Param mode query string false "导入模式: create(默认)/upsert"
+// @Param dry_run query bool false "仅校验并返回导入计划，不写入"
+// @Success 200 {object} common.Response{data=domain.CatalogImportResultVO}
+// @Router /api/v1/app-catalog/import [post]
+func (c *AppController) ImportAppCatalog(ctx *gin.Context) {
+	var command domain.ImportCatalogCommand
+	if err := ctx.ShouldBindQuery(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	if command.Format == "" {
+		contentType := ctx.ContentType()
+		switch {
+		case strings.Contains(contentType, "csv"):
+			command.Format = domain.CatalogFormatCSV
+		case strings.Contains(contentType, "json"):
+			command.Format = domain.CatalogFormatJSON
+		default:
+			command.Format = domain.CatalogFormatYAML
+		}
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, domain.MaxCatalogSize))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := c.CatalogService.ImportCatalog(ctx, &command, data)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, result)
+}
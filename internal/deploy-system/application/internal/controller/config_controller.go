@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAppConfig 获取应用环境的当前配置
+// @Summary 获取应用环境配置
+// @Description 返回应用在环境下的最新配置版本，没有配置时返回404
+// @Tags 应用配置
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response{data=domain.AppConfigRevision}
+// @Router /api/v1/apps/{id}/envs/{env_id}/config [get]
+func (c *AppController) GetAppConfig(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	config, err := c.ConfigService.GetConfig(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, config)
+}
+
+// SaveAppConfig 保存应用环境配置
+// @Summary 保存应用环境配置
+// @Description 以完整内容保存配置并生成新版本，内容未变化时返回400
+// @Tags 应用配置
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param data body domain.SaveConfigCommand true "配置内容"
+// @Success 200 {object} common.Response{data=domain.AppConfigRevision}
+// @Router /api/v1/apps/{id}/envs/{env_id}/config [put]
+func (c *AppController) SaveAppConfig(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	var command domain.SaveConfigCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+	command.EnvID = envID
+
+	revision, err := c.ConfigService.SaveConfig(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, revision)
+}
+
+// ListConfigRevisions 查询配置版本
+// @Summary 查询配置版本
+// @Tags 应用配置
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=common.PageResult{list=[]domain.AppConfigRevision}}
+// @Router /api/v1/apps/{id}/envs/{env_id}/config/revisions [get]
+func (c *AppController) ListConfigRevisions(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	var query domain.ConfigRevisionQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	query.AppID = appID
+	query.EnvID = envID
+
+	revisions, total, err := c.ConfigService.ListRevisions(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccessWithPageExt(ctx, revisions, total, query.Page, query.Size)
+}
+
+// GetConfigRevision 获取配置版本
+// @Summary 获取配置版本
+// @Tags 应用配置
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param revision path int true "版本号"
+// @Success 200 {object} common.Response{data=domain.AppConfigRevision}
+// @Router /api/v1/apps/{id}/envs/{env_id}/config/revisions/{revision} [get]
+func (c *AppController) GetConfigRevision(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	number, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil || number <= 0 {
+		common.ResponseBadRequest(ctx, "无效的版本号")
+		return
+	}
+	revision, err := c.ConfigService.GetRevision(ctx, appID, envID, number)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, revision)
+}
+
+// DiffAppConfig 比较配置版本
+// @Summary 比较配置版本
+// @Description to默认为最新版本，from默认为to的上一个版本；第一个版本与空配置比较
+// @Tags 应用配置
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param from query int false "起始版本号"
+// @Param to query int false "目标版本号"
+// @Success 200 {object} common.Response{data=domain.ConfigDiffVO}
+// @Router /api/v1/apps/{id}/envs/{env_id}/config/diff [get]
+func (c *AppController) DiffAppConfig(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	var query struct {
+		From int `form:"from"`
+		To   int `form:"to"`
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	diff, err := c.ConfigService.DiffRevisions(ctx, appID, envID, query.From, query.To)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, diff)
+}
+
+// RestoreConfigRevision 恢复配置版本
+// @Summary 恢复配置版本
+// @Description 以指定版本的内容生成新的最新版本，内容与当前版本相同时返回当前版本
+// @Tags 应用配置
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param revision path int true "版本号"
+// @Success 200 {object} common.Response{data=domain.AppConfigRevision}
+// @Router /api/v1/apps/{id}/envs/{env_id}/config/revisions/{revision}/restore [post]
+func (c *AppController) RestoreConfigRevision(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	number, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil || number <= 0 {
+		common.ResponseBadRequest(ctx, "无效的版本号")
+		return
+	}
+	var body struct {
+		Comment string `json:"comment" binding:"max=500"`
+	}
+	if ctx.Request.ContentLength != 0 {
+		if err = ctx.ShouldBindJSON(&body); err != nil {
+			common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	revision, err := c.ConfigService.RestoreRevision(ctx, appID, envID, number, body.Comment)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, revision)
+}
+
+// RenderConfigMaps 渲染ConfigMap
+// @Summary 渲染ConfigMap
+// @Description 将配置版本渲染为环境变量与配置文件两个ConfigMap，Secret引用不包含在内
+// @Tags 应用配置
+// @Produce plain
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param revision query int false "版本号，默认最新版本"
+// @Success 200 {string} string "ConfigMap YAML"
+// @Router /api/v1/apps/{id}/envs/{env_id}/config/configmaps [get]
+func (c *AppController) RenderConfigMaps(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	number := 0
+	if value := ctx.Query("revision"); value != "" {
+		var err error
+		if number, err = strconv.Atoi(value); err != nil || number <= 0 {
+			common.ResponseBadRequest(ctx, "无效的版本号")
+			return
+		}
+	}
+
+	manifest, err := c.ConfigService.RenderConfigMaps(ctx, appID, envID, number)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/yaml; charset=utf-8", manifest)
+}
+
+// appEnvIDs 解析路径中的应用ID与环境ID，失败时直接返回400
+func appEnvIDs(ctx *gin.Context) (types.Long, types.Long, bool) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return 0, 0, false
+	}
+	envID, err := types.StringToLong(ctx.Param("env_id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的环境ID")
+		return 0, 0, false
+	}
+	return appID, envID, true
+}
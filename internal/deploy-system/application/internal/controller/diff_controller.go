@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiffReleasePlan 比较发布计划与环境中当前运行的部署
+// @Summary 比较发布计划与当前部署
+// @Description 返回镜像版本与digest、配置版本、渲染后的工作负载、HPA的结构化差异与统一格式文本差异；构建模块有两个版本的构建记录时返回之间的提交
+// @Tags 发布管理
+// @Produce json
+// @Param id path int true "发布计划ID"
+// @Success 200 {object} common.Response{data=domain.ReleaseDiffVO}
+// @Router /api/v1/releases/{id}/diff [get]
+func (c *AppController) DiffReleasePlan(ctx *gin.Context) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的发布计划ID")
+		return
+	}
+	diff, err := c.DiffService.DiffReleasePlan(ctx, id)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, diff)
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListDriftFindings 查询配置漂移
+// @Summary 查询配置漂移
+// @Description 按当前用户的数据权限过滤，按最近发现时间倒序；status为open表示漂移仍存在，resolved表示集群已恢复
+// @Tags 配置漂移
+// @Produce json
+// @Param app_id query int false "应用ID"
+// @Param env_id query int false "环境ID"
+// @Param status query string false "漂移状态：open、resolved"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=[]domain.DriftFinding}
+// @Router /api/v1/drift-findings [get]
+func (c *AppController) ListDriftFindings(ctx *gin.Context) {
+	var query domain.DriftQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	findings, total, err := c.DriftService.ListDriftFindings(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccessWithPageExt(ctx, findings, total, query.Page, query.Size)
+}
+
+// CheckAppEnvDrift 立即检测应用环境的配置漂移
+// @Summary 检测配置漂移
+// @Description 比较集群中的工作负载与最近一次成功部署的镜像、副本数与HPA，返回未恢复的漂移。需要应用开发者及以上角色
+// @Tags 配置漂移
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response{data=[]domain.DriftFinding}
+// @Router /api/v1/apps/{id}/envs/{env_id}/drift/check [post]
+func (c *AppController) CheckAppEnvDrift(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	findings, err := c.DriftService.CheckAppEnv(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, findings)
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SaveAppHPA 保存应用默认HPA配置
+// @Summary 保存应用默认HPA
+// @Description 按autoscaling/v2定义保存应用默认配置，环境没有单独配置时使用；已有配置时整体替换
+// @Tags 应用HPA
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.SaveHPACommand true "HPA配置"
+// @Success 200 {object} common.Response{data=domain.AppHPA}
+// @Router /api/v1/apps/{id}/hpa [put]
+func (c *AppController) SaveAppHPA(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	c.saveHPA(ctx, appID, 0)
+}
+
+// DeleteAppHPA 删除应用默认HPA配置
+// @Summary 删除应用默认HPA
+// @Description 环境的单独配置不受影响
+// @Tags 应用HPA
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/hpa [delete]
+func (c *AppController) DeleteAppHPA(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	if err = c.HPAService.DeleteHPA(ctx, appID, 0); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ListAppHPAs 查询应用的全部HPA配置
+// @Summary 查询应用HPA配置
+// @Description 返回应用默认配置（env_id为0）与各环境的单独配置
+// @Tags 应用HPA
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response{data=[]domain.AppHPA}
+// @Router /api/v1/apps/{id}/hpa/envs [get]
+func (c *AppController) ListAppHPAs(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	hpas, err := c.HPAService.ListHPAs(ctx, appID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, hpas)
+}
+
+// GetEnvHPA 获取应用在环境下生效的HPA配置
+// @Summary 获取环境HPA
+// @Description 环境没有单独配置时返回应用默认配置，此时env_id为0
+// @Tags 应用HPA
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response{data=domain.AppHPA}
+// @Router /api/v1/apps/{id}/hpa/envs/{env_id} [get]
+func (c *AppController) GetEnvHPA(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	hpa, err := c.HPAService.EffectiveHPA(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, hpa)
+}
+
+// SaveEnvHPA 保存应用在环境下单独的HPA配置
+// @Summary 保存环境HPA
+// @Tags 应用HPA
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param data body domain.SaveHPACommand true "HPA配置"
+// @Success 200 {object} common.Response{data=domain.AppHPA}
+// @Router /api/v1/apps/{id}/hpa/envs/{env_id} [put]
+func (c *AppController) SaveEnvHPA(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	c.saveHPA(ctx, appID, envID)
+}
+
+// DeleteEnvHPA 删除应用在环境下单独的HPA配置
+// @Summary 删除环境HPA
+// @Description 删除后环境恢复使用应用默认配置
+// @Tags 应用HPA
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/hpa/envs/{env_id} [delete]
+func (c *AppController) DeleteEnvHPA(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	if err := c.HPAService.DeleteHPA(ctx, appID, envID); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// RenderHPA 渲染应用在环境下的HPA
+// @Summary 渲染HPA
+// @Description 返回伸缩应用Deployment的autoscaling/v2 HorizontalPodAutoscaler YAML
+// @Tags 应用HPA
+// @Produce plain
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {string} string "HPA YAML"
+// @Router /api/v1/apps/{id}/hpa/envs/{env_id}/manifest [get]
+func (c *AppController) RenderHPA(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	manifest, err := c.HPAService.RenderHPA(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/yaml; charset=utf-8", manifest)
+}
+
+// saveHPA 绑定并保存HPA配置，envID为0时保存应用默认配置
+func (c *AppController) saveHPA(ctx *gin.Context, appID, envID types.Long) {
+	var command domain.SaveHPACommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+	command.EnvID = envID
+
+	hpa, err := c.HPAService.SaveHPA(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, hpa)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListJobRuns 查询应用的Job运行记录
+// @Summary 查询Job运行记录
+// @Description 一次性Job每次部署运行一次，CronJob每次调度运行一次；列表不含日志
+// @Tags 应用工作负载
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id query int false "环境ID"
+// @Param deploy_id query int false "部署记录ID"
+// @Param status query string false "状态: running, success, failed"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=common.PageResult{list=[]domain.JobRun}}
+// @Router /api/v1/apps/{id}/jobs/runs [get]
+func (c *AppController) ListJobRuns(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	var query domain.JobRunQuery
+	if err = ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	query.AppID = appID
+
+	runs, total, err := c.DeployService.ListJobRuns(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccessWithPageExt(ctx, runs, total, query.Page, query.Size)
+}
+
+// GetJobRun 获取Job运行记录
+// @Summary 获取Job运行记录
+// @Description 包含退出码与容器日志末尾
+// @Tags 应用工作负载
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param run_id path int true "运行记录ID"
+// @Success 200 {object} common.Response{data=domain.JobRun}
+// @Router /api/v1/apps/{id}/jobs/runs/{run_id} [get]
+func (c *AppController) GetJobRun(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	runID, err := types.StringToLong(ctx.Param("run_id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的运行记录ID")
+		return
+	}
+	run, err := c.DeployService.GetJobRun(ctx, appID, runID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, run)
+}
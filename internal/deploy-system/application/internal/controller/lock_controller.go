@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeployLock 获取应用环境的部署锁
+// @Summary 获取部署锁
+// @Description 返回当前部署持有的租约、冻结信息与排队中的发布计划，过期的锁不返回
+// @Tags 部署锁
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response{data=domain.DeployLockStatus}
+// @Router /api/v1/apps/{id}/envs/{env_id}/lock [get]
+func (c *AppController) GetDeployLock(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	status, err := c.LockService.GetStatus(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, status)
+}
+
+// FreezeAppEnv 冻结应用环境
+// @Summary 冻结应用环境
+// @Description 冻结期间不能执行发布计划，回滚不受限制；duration_minutes为0时需要手动解冻。只有系统管理员可以操作
+// @Tags 部署锁
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param data body domain.FreezeCommand true "冻结信息"
+// @Success 200 {object} common.Response{data=domain.DeployLock}
+// @Router /api/v1/apps/{id}/envs/{env_id}/lock [put]
+func (c *AppController) FreezeAppEnv(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	var command domain.FreezeCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID, command.EnvID = appID, envID
+	freeze, err := c.LockService.Freeze(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, freeze)
+}
+
+// UnfreezeAppEnv 解冻应用环境
+// @Summary 解冻应用环境
+// @Description 只有系统管理员可以操作，不影响正在进行的部署
+// @Tags 部署锁
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/envs/{env_id}/lock [delete]
+func (c *AppController) UnfreezeAppEnv(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	if err := c.LockService.Unfreeze(ctx, appID, envID); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ListDeployLocks 查询部署锁
+// @Summary 查询部署锁
+// @Description 返回全部未过期的部署租约与冻结，可按应用、环境过滤
+// @Tags 部署锁
+// @Produce json
+// @Param app_id query int false "应用ID"
+// @Param env_id query int false "环境ID"
+// @Success 200 {object} common.Response{data=[]domain.DeployLock}
+// @Router /api/v1/deploy-locks [get]
+func (c *AppController) ListDeployLocks(ctx *gin.Context) {
+	var appID, envID types.Long
+	var err error
+	if value := ctx.Query("app_id"); value != "" {
+		if appID, err = types.StringToLong(value); err != nil {
+			common.ResponseBadRequest(ctx, "无效的应用ID")
+			return
+		}
+	}
+	if value := ctx.Query("env_id"); value != "" {
+		if envID, err = types.StringToLong(value); err != nil {
+			common.ResponseBadRequest(ctx, "无效的环境ID")
+			return
+		}
+	}
+	locks, err := c.LockService.ListLocks(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, locks)
+}
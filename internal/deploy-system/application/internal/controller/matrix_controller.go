@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEnvMatrix 查询环境矩阵
+// @Summary 查询环境矩阵
+// @Description 返回每个应用在每个环境中最近一次成功的部署（版本、digest、部署人、部署时间）与待执行的发布计划，应用按当前用户的数据权限过滤
+// @Tags 环境管理
+// @Produce json
+// @Param group_id query int false "应用分组ID"
+// @Param dept_id query int false "部门ID"
+// @Success 200 {object} common.Response{data=domain.EnvMatrixVO}
+// @Router /api/v1/envs/matrix [get]
+func (c *AppController) GetEnvMatrix(ctx *gin.Context) {
+	var query domain.EnvMatrixQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	matrix, err := c.AppService.GetEnvMatrix(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, matrix)
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAppMembers 查询应用成员与归属
+// @Summary 查询应用成员
+// @Description 返回应用所属部门、全部成员及值班联系人
+// @Tags 应用成员
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response{data=domain.AppOwnershipVO}
+// @Router /api/v1/apps/{id}/members [get]
+func (c *AppController) GetAppMembers(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	ownership, err := c.MemberService.GetOwnership(ctx, appID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, ownership)
+}
+
+// AddAppMember 添加应用成员
+// @Summary 添加应用成员
+// @Description 需要应用维护者及以上角色，添加负责人需要负责人角色
+// @Tags 应用成员
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.AddMemberCommand true "成员信息"
+// @Success 200 {object} common.Response{data=domain.AppMember}
+// @Router /api/v1/apps/{id}/members [post]
+func (c *AppController) AddAppMember(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	var command domain.AddMemberCommand
+	if err = ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+
+	member, err := c.MemberService.AddMember(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, member)
+}
+
+// UpdateAppMember 修改应用成员
+// @Summary 修改应用成员
+// @Description 修改角色与值班标记，需要应用维护者及以上角色，涉及负责人角色的修改需要负责人角色
+// @Tags 应用成员
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param user_id path int true "用户ID"
+// @Param data body domain.UpdateMemberCommand true "成员信息"
+// @Success 200 {object} common.Response{data=domain.AppMember}
+// @Router /api/v1/apps/{id}/members/{user_id} [put]
+func (c *AppController) UpdateAppMember(ctx *gin.Context) {
+	appID, userID, ok := appMemberIDs(ctx)
+	if !ok {
+		return
+	}
+	var command domain.UpdateMemberCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+	command.UserID = userID
+
+	member, err := c.MemberService.UpdateMember(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, member)
+}
+
+// RemoveAppMember 移除应用成员
+// @Summary 移除应用成员
+// @Description 需要应用维护者及以上角色，移除负责人需要负责人角色，应用至少保留一个负责人
+// @Tags 应用成员
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param user_id path int true "用户ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/members/{user_id} [delete]
+func (c *AppController) RemoveAppMember(ctx *gin.Context) {
+	appID, userID, ok := appMemberIDs(ctx)
+	if !ok {
+		return
+	}
+	if err := c.MemberService.RemoveMember(ctx, appID, userID); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// appMemberIDs 解析路径中的应用ID与用户ID，无效时返回400
+func appMemberIDs(ctx *gin.Context) (types.Long, types.Long, bool) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return 0, 0, false
+	}
+	userID, err := types.StringToLong(ctx.Param("user_id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的用户ID")
+		return 0, 0, false
+	}
+	return appID, userID, true
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPromotionPath 获取应用的晋级路径
+// @Summary 获取晋级路径
+// @Description 按阶段顺序返回晋级路径，附带每个阶段最近一次成功的部署；没有配置时返回空数组
+// @Tags 环境晋级
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response{data=[]domain.PromotionStageVO}
+// @Router /api/v1/apps/{id}/promotion-path [get]
+func (c *AppController) GetPromotionPath(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	path, err := c.PromotionService.GetPath(ctx, appID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, path)
+}
+
+// SavePromotionPath 保存应用的晋级路径
+// @Summary 保存晋级路径
+// @Description 按数组顺序整体替换晋级路径，需要应用维护者及以上角色；最后一个阶段不能是可选阶段
+// @Tags 环境晋级
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.SavePromotionPathCommand true "晋级路径"
+// @Success 200 {object} common.Response{data=[]domain.PromotionStageVO}
+// @Router /api/v1/apps/{id}/promotion-path [put]
+func (c *AppController) SavePromotionPath(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	var command domain.SavePromotionPathCommand
+	if err = ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+
+	path, err := c.PromotionService.SavePath(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, path)
+}
+
+// DeletePromotionPath 删除应用的晋级路径
+// @Summary 删除晋级路径
+// @Description 删除后发布计划不再受阶段限制，需要应用维护者及以上角色
+// @Tags 环境晋级
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/promotion-path [delete]
+func (c *AppController) DeletePromotionPath(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	if err = c.PromotionService.DeletePath(ctx, appID); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// PromoteRelease 将来源阶段部署成功的版本晋级到下一阶段
+// @Summary 晋级到下一阶段
+// @Description 使用来源部署的镜像digest与配置版本为下一阶段创建发布计划，需要应用开发者及以上角色
+// @Tags 环境晋级
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.PromoteCommand true "晋级信息"
+// @Success 200 {object} common.Response{data=domain.PromotionResult}
+// @Router /api/v1/apps/{id}/promote [post]
+func (c *AppController) PromoteRelease(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	var command domain.PromoteCommand
+	if err = ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+
+	result, err := c.PromotionService.Promote(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, result)
+}
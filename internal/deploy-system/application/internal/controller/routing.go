@@ -32,9 +32,81 @@ func (c *AppController) injectRouting(getBean func(string) interface{}) {
 		appsGroup.PUT("/:id", c.UpdateApplication)    // 更新应用
 		appsGroup.DELETE("/:id", c.DeleteApplication) // 删除应用
 
+		// 按应用模板在一个事务中创建应用及工作负载定义、HPA、环境配置与构建配置
+		appsGroup.POST("/from-template", c.CreateAppFromTemplate)
+
+		// 应用成员与值班联系人，在全局权限之外按成员角色限制删除应用、发布与回滚
+		appsGroup.GET("/:id/members", c.GetAppMembers)               // 查询成员与归属
+		appsGroup.POST("/:id/members", c.AddAppMember)               // 添加成员
+		appsGroup.PUT("/:id/members/:user_id", c.UpdateAppMember)    // 修改成员
+		appsGroup.DELETE("/:id/members/:user_id", c.RemoveAppMember) // 移除成员
+
+		// 应用晋级路径，版本按路径逐个环境发布，不能跳过必经阶段
+		appsGroup.GET("/:id/promotion-path", c.GetPromotionPath)       // 获取晋级路径
+		appsGroup.PUT("/:id/promotion-path", c.SavePromotionPath)      // 保存晋级路径
+		appsGroup.DELETE("/:id/promotion-path", c.DeletePromotionPath) // 删除晋级路径
+		appsGroup.POST("/:id/promote", c.PromoteRelease)               // 晋级到下一阶段
+
 		// 应用HPA配置 - 修改参数名为 :id 以匹配其他路由
 		appsGroup.POST("/:id/hpa", c.ConfigureHPA) // 配置HPA
 		appsGroup.GET("/:id/hpa", c.GetAppHPA)     // 获取HPA配置
+
+		// 应用HPA配置（autoscaling/v2），环境没有单独配置时使用应用默认配置
+		appsGroup.PUT("/:id/hpa", c.SaveAppHPA)                      // 保存默认配置
+		appsGroup.DELETE("/:id/hpa", c.DeleteAppHPA)                 // 删除默认配置
+		appsGroup.GET("/:id/hpa/envs", c.ListAppHPAs)                // 查询全部配置
+		appsGroup.GET("/:id/hpa/envs/:env_id", c.GetEnvHPA)          // 获取环境生效配置
+		appsGroup.PUT("/:id/hpa/envs/:env_id", c.SaveEnvHPA)         // 保存环境配置
+		appsGroup.DELETE("/:id/hpa/envs/:env_id", c.DeleteEnvHPA)    // 删除环境配置
+		appsGroup.GET("/:id/hpa/envs/:env_id/manifest", c.RenderHPA) // 渲染HPA
+
+		// 应用环境配置，每次修改生成新版本
+		appsGroup.GET("/:id/envs/:env_id/config", c.GetAppConfig)                                       // 获取当前配置
+		appsGroup.PUT("/:id/envs/:env_id/config", c.SaveAppConfig)                                      // 保存配置
+		appsGroup.GET("/:id/envs/:env_id/config/diff", c.DiffAppConfig)                                 // 比较配置版本
+		appsGroup.GET("/:id/envs/:env_id/config/configmaps", c.RenderConfigMaps)                        // 渲染ConfigMap
+		appsGroup.GET("/:id/envs/:env_id/config/revisions", c.ListConfigRevisions)                      // 查询配置版本
+		appsGroup.GET("/:id/envs/:env_id/config/revisions/:revision", c.GetConfigRevision)              // 获取配置版本
+		appsGroup.POST("/:id/envs/:env_id/config/revisions/:revision/restore", c.RestoreConfigRevision) // 恢复配置版本
+
+		// 应用共享存储卷挂载
+		appsGroup.GET("/:id/volumes", c.ListVolumeMounts)               // 查询挂载
+		appsGroup.POST("/:id/volumes", c.CreateVolumeMount)             // 挂载存储卷
+		appsGroup.PUT("/:id/volumes/:mount_id", c.UpdateVolumeMount)    // 修改挂载
+		appsGroup.DELETE("/:id/volumes/:mount_id", c.DeleteVolumeMount) // 解除挂载
+		appsGroup.GET("/:id/envs/:env_id/volumes", c.GetPodVolumes)     // 渲染Pod卷定义
+
+		// 应用工作负载定义，每次修改生成新版本，环境可覆盖部分字段
+		appsGroup.GET("/:id/spec", c.GetWorkloadSpec)                             // 获取定义
+		appsGroup.PUT("/:id/spec", c.SaveWorkloadSpec)                            // 保存定义
+		appsGroup.GET("/:id/spec/revisions", c.ListWorkloadSpecRevisions)         // 查询定义版本
+		appsGroup.GET("/:id/spec/revisions/:revision", c.GetWorkloadSpecRevision) // 获取定义版本
+		appsGroup.GET("/:id/spec/envs/:env_id", c.GetWorkloadOverride)            // 获取环境覆盖
+		appsGroup.PUT("/:id/spec/envs/:env_id", c.SaveWorkloadOverride)           // 保存环境覆盖
+		appsGroup.DELETE("/:id/spec/envs/:env_id", c.DeleteWorkloadOverride)      // 删除环境覆盖
+		appsGroup.GET("/:id/spec/envs/:env_id/deployment", c.RenderWorkload)      // 渲染工作负载
+
+		// 部署锁，同一应用在同一环境同时只能有一个部署，系统管理员可以冻结应用环境
+		appsGroup.GET("/:id/envs/:env_id/lock", c.GetDeployLock)     // 获取部署锁
+		appsGroup.PUT("/:id/envs/:env_id/lock", c.FreezeAppEnv)      // 冻结应用环境
+		appsGroup.DELETE("/:id/envs/:env_id/lock", c.UnfreezeAppEnv) // 解冻应用环境
+
+		// 配置漂移，定期比较集群中的工作负载与最近一次成功的部署
+		appsGroup.POST("/:id/envs/:env_id/drift/check", c.CheckAppEnvDrift) // 立即检测配置漂移
+
+		// Job部署方式的运行记录
+		appsGroup.GET("/:id/jobs/runs", c.ListJobRuns)       // 查询运行记录
+		appsGroup.GET("/:id/jobs/runs/:run_id", c.GetJobRun) // 获取运行记录
+	}
+
+	// 共享存储卷目录路由
+	volumesGroup := authRouter.Group("/volumes")
+	{
+		volumesGroup.GET("", c.ListVolumes)         // 查询存储卷
+		volumesGroup.POST("", c.CreateVolume)       // 添加存储卷
+		volumesGroup.GET("/:id", c.GetVolume)       // 获取存储卷
+		volumesGroup.PUT("/:id", c.UpdateVolume)    // 修改存储卷
+		volumesGroup.DELETE("/:id", c.DeleteVolume) // 删除存储卷
 	}
 
 	// 应用分组路由
@@ -48,17 +120,46 @@ func (c *AppController) injectRouting(getBean func(string) interface{}) {
 	// 环境管理路由
 	envsGroup := authRouter.Group("/envs")
 	{
-		envsGroup.GET("", c.ListEnvironments)   // 查询环境列表
-		envsGroup.POST("", c.CreateEnvironment) // 创建环境
+		envsGroup.GET("", c.ListEnvironments)    // 查询环境列表
+		envsGroup.POST("", c.CreateEnvironment)  // 创建环境
+		envsGroup.GET("/matrix", c.GetEnvMatrix) // 查询环境矩阵
 	}
 
 	// 发布管理路由
 	releasesGroup := authRouter.Group("/releases")
 	{
 		releasesGroup.POST("", c.CreateReleasePlan)              // 创建发布计划
+		releasesGroup.POST("/:id/approve", c.ApproveReleasePlan) // 审批发布计划
 		releasesGroup.POST("/:id/execute", c.ExecuteReleasePlan) // 执行发布计划
+		releasesGroup.GET("/:id/diff", c.DiffReleasePlan)        // 比较发布计划与当前部署
+	}
+
+	// 发布列车路由，按依赖关系依次执行多个应用的发布计划
+	trainsGroup := authRouter.Group("/release-trains")
+	{
+		trainsGroup.GET("", c.ListReleaseTrains)              // 查询发布列车
+		trainsGroup.POST("", c.CreateReleaseTrain)            // 创建发布列车
+		trainsGroup.GET("/:id", c.GetReleaseTrain)            // 获取发布列车
+		trainsGroup.DELETE("/:id", c.DeleteReleaseTrain)      // 删除发布列车
+		trainsGroup.POST("/:id/actions", c.ActOnReleaseTrain) // 执行或中止发布列车
 	}
 
+	// 部署锁路由
+	authRouter.GET("/deploy-locks", c.ListDeployLocks) // 查询部署锁
+
+	// 应用模板路由
+	authRouter.GET("/app-templates", c.ListAppTemplates) // 查询应用模板
+
+	// 应用目录导入导出路由
+	catalogGroup := authRouter.Group("/app-catalog")
+	{
+		catalogGroup.GET("/export", c.ExportAppCatalog)  // 导出应用目录
+		catalogGroup.POST("/import", c.ImportAppCatalog) // 导入应用目录
+	}
+
+	// 配置漂移路由
+	authRouter.GET("/drift-findings", c.ListDriftFindings) // 查询配置漂移
+
 	// 部署历史路由
 	deploymentsGroup := authRouter.Group("/deployments")
 	{
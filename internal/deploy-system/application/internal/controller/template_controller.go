@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAppTemplates 查询应用模板
+// @Summary 查询应用模板
+// @Description 内置的应用模板，包含构建方法、工作负载定义、健康检查、HPA、环境变量与构建配置
+// @Tags 应用模板
+// @Produce json
+// @Success 200 {object} common.Response{data=[]domain.AppTemplate}
+// @Router /api/v1/app-templates [get]
+func (c *AppController) ListAppTemplates(ctx *gin.Context) {
+	common.ResponseSuccess(ctx, c.TemplateService.ListTemplates())
+}
+
+// CreateAppFromTemplate 按模板创建应用
+// @Summary 按模板创建应用
+// @Description 在一个事务中创建应用并登记负责人、加入分组，写入工作负载定义、应用默认HPA、所选环境的配置、镜像仓库关联，
+// @Description 指定代码仓库时保存构建配置，任一步失败时全部回滚
+// @Tags 应用模板
+// @Accept json
+// @Produce json
+// @Param data body domain.CreateFromTemplateCommand true "模板与应用信息"
+// @Success 200 {object} common.Response{data=domain.TemplateAppVO}
+// @Router /api/v1/apps/from-template [post]
+func (c *AppController) CreateAppFromTemplate(ctx *gin.Context) {
+	var command domain.CreateFromTemplateCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	result, err := c.TemplateService.CreateFromTemplate(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, result)
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListReleaseTrains 查询发布列车
+// @Summary 查询发布列车
+// @Description 按创建时间倒序分页返回发布列车，不含成员
+// @Tags 发布列车
+// @Produce json
+// @Param status query string false "列车状态"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=[]domain.ReleaseTrain}
+// @Router /api/v1/release-trains [get]
+func (c *AppController) ListReleaseTrains(ctx *gin.Context) {
+	var query domain.TrainQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	trains, total, err := c.TrainService.ListTrains(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccessWithPageExt(ctx, trains, total, query.Page, query.Size)
+}
+
+// CreateReleaseTrain 创建发布列车
+// @Summary 创建发布列车
+// @Description 成员的发布计划必须待处理或已审批且不属于其他列车，依赖关系不能有循环
+// @Tags 发布列车
+// @Accept json
+// @Produce json
+// @Param data body domain.CreateTrainCommand true "发布列车信息"
+// @Success 200 {object} common.Response{data=domain.ReleaseTrain}
+// @Router /api/v1/release-trains [post]
+func (c *AppController) CreateReleaseTrain(ctx *gin.Context) {
+	var command domain.CreateTrainCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	train, err := c.TrainService.CreateTrain(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, train)
+}
+
+// GetReleaseTrain 获取发布列车
+// @Summary 获取发布列车
+// @Description 包含按执行顺序排列的成员，成员状态按部署记录同步
+// @Tags 发布列车
+// @Produce json
+// @Param id path int true "发布列车ID"
+// @Success 200 {object} common.Response{data=domain.ReleaseTrain}
+// @Router /api/v1/release-trains/{id} [get]
+func (c *AppController) GetReleaseTrain(ctx *gin.Context) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的发布列车ID")
+		return
+	}
+	train, err := c.TrainService.GetTrain(ctx, id)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, train)
+}
+
+// DeleteReleaseTrain 删除发布列车
+// @Summary 删除发布列车
+// @Description 只能删除待执行的发布列车，成员的发布计划恢复为可单独执行
+// @Tags 发布列车
+// @Produce json
+// @Param id path int true "发布列车ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/release-trains/{id} [delete]
+func (c *AppController) DeleteReleaseTrain(ctx *gin.Context) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的发布列车ID")
+		return
+	}
+	if err = c.TrainService.DeleteTrain(ctx, id); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ActOnReleaseTrain 执行或中止发布列车
+// @Summary 执行或中止发布列车
+// @Description execute开始按依赖顺序执行，abort不再启动新的成员，rollback为true时回滚已部署成功的成员；需要每个成员应用的开发者及以上角色
+// @Tags 发布列车
+// @Accept json
+// @Produce json
+// @Param id path int true "发布列车ID"
+// @Param data body domain.TrainActionCommand true "操作"
+// @Success 200 {object} common.Response{data=domain.ReleaseTrain}
+// @Router /api/v1/release-trains/{id}/actions [post]
+func (c *AppController) ActOnReleaseTrain(ctx *gin.Context) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的发布列车ID")
+		return
+	}
+	var command domain.TrainActionCommand
+	if err = ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	train, err := c.TrainService.Act(ctx, id, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, train)
+}
@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListVolumes 查询共享存储卷目录
+// @Summary 查询共享存储卷
+// @Tags 共享存储卷
+// @Produce json
+// @Param env_id query int false "只返回该环境可用的卷"
+// @Success 200 {object} common.Response{data=[]domain.AppVolume}
+// @Router /api/v1/volumes [get]
+func (c *AppController) ListVolumes(ctx *gin.Context) {
+	var envID types.Long
+	if value := ctx.Query("env_id"); value != "" {
+		var err error
+		if envID, err = types.StringToLong(value); err != nil {
+			common.ResponseBadRequest(ctx, "无效的环境ID")
+			return
+		}
+	}
+	volumes, err := c.VolumeService.ListVolumes(ctx, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, volumes)
+}
+
+// GetVolume 获取共享存储卷
+// @Summary 获取共享存储卷
+// @Tags 共享存储卷
+// @Produce json
+// @Param id path int true "存储卷ID"
+// @Success 200 {object} common.Response{data=domain.AppVolume}
+// @Router /api/v1/volumes/{id} [get]
+func (c *AppController) GetVolume(ctx *gin.Context) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的存储卷ID")
+		return
+	}
+	volume, err := c.VolumeService.GetVolume(ctx, id)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, volume)
+}
+
+// CreateVolume 添加共享存储卷
+// @Summary 添加共享存储卷
+// @Description NFS需要服务器地址与绝对路径；PVC需要指定所属环境，并检查PVC在环境命名空间中存在
+// @Tags 共享存储卷
+// @Accept json
+// @Produce json
+// @Param data body domain.SaveVolumeCommand true "存储卷信息"
+// @Success 200 {object} common.Response{data=domain.AppVolume}
+// @Router /api/v1/volumes [post]
+func (c *AppController) CreateVolume(ctx *gin.Context) {
+	var command domain.SaveVolumeCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	volume, err := c.VolumeService.CreateVolume(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, volume)
+}
+
+// UpdateVolume 修改共享存储卷
+// @Summary 修改共享存储卷
+// @Tags 共享存储卷
+// @Accept json
+// @Produce json
+// @Param id path int true "存储卷ID"
+// @Param data body domain.SaveVolumeCommand true "存储卷信息"
+// @Success 200 {object} common.Response{data=domain.AppVolume}
+// @Router /api/v1/volumes/{id} [put]
+func (c *AppController) UpdateVolume(ctx *gin.Context) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的存储卷ID")
+		return
+	}
+	var command domain.SaveVolumeCommand
+	if err = ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.ID = id
+
+	volume, err := c.VolumeService.UpdateVolume(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, volume)
+}
+
+// DeleteVolume 删除共享存储卷
+// @Summary 删除共享存储卷
+// @Description 仍被应用挂载的存储卷不能删除
+// @Tags 共享存储卷
+// @Produce json
+// @Param id path int true "存储卷ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/volumes/{id} [delete]
+func (c *AppController) DeleteVolume(ctx *gin.Context) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的存储卷ID")
+		return
+	}
+	if err = c.VolumeService.DeleteVolume(ctx, id); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ListVolumeMounts 查询应用的存储卷挂载
+// @Summary 查询应用的存储卷挂载
+// @Tags 共享存储卷
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id query int false "环境ID，不传返回所有环境"
+// @Success 200 {object} common.Response{data=[]domain.VolumeMountVO}
+// @Router /api/v1/apps/{id}/volumes [get]
+func (c *AppController) ListVolumeMounts(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	var envID types.Long
+	if value := ctx.Query("env_id"); value != "" {
+		if envID, err = types.StringToLong(value); err != nil {
+			common.ResponseBadRequest(ctx, "无效的环境ID")
+			return
+		}
+	}
+	mounts, err := c.VolumeService.ListMounts(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, mounts)
+}
+
+// CreateVolumeMount 为应用挂载存储卷
+// @Summary 为应用挂载存储卷
+// @Description 同一应用环境内挂载路径不能重复，也不能与配置文件挂载目录相同
+// @Tags 共享存储卷
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.SaveVolumeMountCommand true "挂载信息"
+// @Success 200 {object} common.Response{data=domain.AppVolumeMount}
+// @Router /api/v1/apps/{id}/volumes [post]
+func (c *AppController) CreateVolumeMount(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	var command domain.SaveVolumeMountCommand
+	if err = ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+
+	mount, err := c.VolumeService.CreateMount(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, mount)
+}
+
+// UpdateVolumeMount 修改应用的存储卷挂载
+// @Summary 修改应用的存储卷挂载
+// @Tags 共享存储卷
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param mount_id path int true "挂载ID"
+// @Param data body domain.SaveVolumeMountCommand true "挂载信息"
+// @Success 200 {object} common.Response{data=domain.AppVolumeMount}
+// @Router /api/v1/apps/{id}/volumes/{mount_id} [put]
+func (c *AppController) UpdateVolumeMount(ctx *gin.Context) {
+	appID, mountID, ok := appMountIDs(ctx)
+	if !ok {
+		return
+	}
+	var command domain.SaveVolumeMountCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+	command.ID = mountID
+
+	mount, err := c.VolumeService.UpdateMount(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, mount)
+}
+
+// DeleteVolumeMount 解除应用的存储卷挂载
+// @Summary 解除应用的存储卷挂载
+// @Tags 共享存储卷
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param mount_id path int true "挂载ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/volumes/{mount_id} [delete]
+func (c *AppController) DeleteVolumeMount(ctx *gin.Context) {
+	appID, mountID, ok := appMountIDs(ctx)
+	if !ok {
+		return
+	}
+	if err := c.VolumeService.DeleteMount(ctx, appID, mountID); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// GetPodVolumes 渲染应用在环境下的Pod卷定义
+// @Summary 渲染应用的Pod卷定义
+// @Description 返回挂载列表及渲染出的Pod volumes与容器volumeMounts
+// @Tags 共享存储卷
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response{data=domain.PodVolumesVO}
+// @Router /api/v1/apps/{id}/envs/{env_id}/volumes [get]
+func (c *AppController) GetPodVolumes(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	volumes, err := c.VolumeService.PodVolumes(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, volumes)
+}
+
+// appMountIDs 解析路径中的应用ID与挂载ID，失败时直接返回400
+func appMountIDs(ctx *gin.Context) (types.Long, types.Long, bool) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return 0, 0, false
+	}
+	mountID, err := types.StringToLong(ctx.Param("mount_id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的挂载ID")
+		return 0, 0, false
+	}
+	return appID, mountID, true
+}
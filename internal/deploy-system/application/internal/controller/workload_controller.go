@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorkloadSpec 获取应用的工作负载定义
+// @Summary 获取应用工作负载定义
+// @Description 返回最新版本；指定env_id时返回合并环境覆盖后实际生效的定义
+// @Tags 应用工作负载
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id query int false "环境ID"
+// @Success 200 {object} common.Response{data=domain.AppWorkloadSpec}
+// @Router /api/v1/apps/{id}/spec [get]
+func (c *AppController) GetWorkloadSpec(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	if value := ctx.Query("env_id"); value != "" {
+		envID, err := types.StringToLong(value)
+		if err != nil {
+			common.ResponseBadRequest(ctx, "无效的环境ID")
+			return
+		}
+		effective, err := c.WorkloadService.EffectiveSpec(ctx, appID, envID)
+		if err != nil {
+			common.ResponseError(ctx, err)
+			return
+		}
+		common.ResponseSuccess(ctx, effective)
+		return
+	}
+
+	spec, err := c.WorkloadService.GetSpec(ctx, appID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, spec)
+}
+
+// SaveWorkloadSpec 保存应用的工作负载定义
+// @Summary 保存应用工作负载定义
+// @Description 以完整内容保存并生成新版本，内容未变化时返回400
+// @Tags 应用工作负载
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.SaveWorkloadSpecCommand true "工作负载定义"
+// @Success 200 {object} common.Response{data=domain.AppWorkloadSpec}
+// @Router /api/v1/apps/{id}/spec [put]
+func (c *AppController) SaveWorkloadSpec(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	var command domain.SaveWorkloadSpecCommand
+	if err = ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+
+	spec, err := c.WorkloadService.SaveSpec(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, spec)
+}
+
+// ListWorkloadSpecRevisions 查询工作负载定义版本
+// @Summary 查询工作负载定义版本
+// @Tags 应用工作负载
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=common.PageResult{list=[]domain.AppWorkloadSpec}}
+// @Router /api/v1/apps/{id}/spec/revisions [get]
+func (c *AppController) ListWorkloadSpecRevisions(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	var query domain.WorkloadSpecQuery
+	if err = ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	query.AppID = appID
+
+	specs, total, err := c.WorkloadService.ListSpecRevisions(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccessWithPageExt(ctx, specs, total, query.Page, query.Size)
+}
+
+// GetWorkloadSpecRevision 获取工作负载定义版本
+// @Summary 获取工作负载定义版本
+// @Tags 应用工作负载
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param revision path int true "版本号"
+// @Success 200 {object} common.Response{data=domain.AppWorkloadSpec}
+// @Router /api/v1/apps/{id}/spec/revisions/{revision} [get]
+func (c *AppController) GetWorkloadSpecRevision(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "无效的应用ID")
+		return
+	}
+	number, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil || number <= 0 {
+		common.ResponseBadRequest(ctx, "无效的版本号")
+		return
+	}
+	spec, err := c.WorkloadService.GetSpecRevision(ctx, appID, number)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, spec)
+}
+
+// GetWorkloadOverride 获取应用在环境下的工作负载覆盖
+// @Summary 获取环境覆盖
+// @Tags 应用工作负载
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response{data=domain.AppWorkloadOverride}
+// @Router /api/v1/apps/{id}/spec/envs/{env_id} [get]
+func (c *AppController) GetWorkloadOverride(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	override, err := c.WorkloadService.GetOverride(ctx, appID, envID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, override)
+}
+
+// SaveWorkloadOverride 保存应用在环境下的工作负载覆盖
+// @Summary 保存环境覆盖
+// @Description 可覆盖副本数、资源、节点选择器与污点容忍，字段为null时沿用应用定义
+// @Tags 应用工作负载
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param data body domain.SaveWorkloadOverrideCommand true "覆盖内容"
+// @Success 200 {object} common.Response{data=domain.AppWorkloadOverride}
+// @Router /api/v1/apps/{id}/spec/envs/{env_id} [put]
+func (c *AppController) SaveWorkloadOverride(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	var command domain.SaveWorkloadOverrideCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+	command.EnvID = envID
+
+	override, err := c.WorkloadService.SaveOverride(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, override)
+}
+
+// DeleteWorkloadOverride 删除应用在环境下的工作负载覆盖
+// @Summary 删除环境覆盖
+// @Tags 应用工作负载
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/spec/envs/{env_id} [delete]
+func (c *AppController) DeleteWorkloadOverride(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	if err := c.WorkloadService.DeleteOverride(ctx, appID, envID); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// RenderWorkload 渲染应用在环境下的工作负载
+// @Summary 渲染工作负载
+// @Description 合并环境覆盖、最新配置版本与共享存储卷，按部署方式返回Deployment、Job或CronJob YAML，预览的Job名称使用部署ID 0
+// @Tags 应用工作负载
+// @Produce plain
+// @Param id path int true "应用ID"
+// @Param env_id path int true "环境ID"
+// @Param version query string false "镜像标签，默认latest"
+// @Success 200 {string} string "工作负载YAML"
+// @Router /api/v1/apps/{id}/spec/envs/{env_id}/deployment [get]
+func (c *AppController) RenderWorkload(ctx *gin.Context) {
+	appID, envID, ok := appEnvIDs(ctx)
+	if !ok {
+		return
+	}
+	manifest, err := c.WorkloadService.RenderWorkload(ctx, appID, envID, ctx.Query("version"))
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/yaml; charset=utf-8", manifest)
+}
@@ -0,0 +1,558 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/pkg/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CatalogDocumentVersion 应用目录文档版本
+const CatalogDocumentVersion = "v1"
+
+// 应用目录文件格式
+const (
+	CatalogFormatYAML = "yaml"
+	CatalogFormatJSON = "json"
+	CatalogFormatCSV  = "csv"
+)
+
+// 导入模式
+const (
+	CatalogModeCreate = "create" // 只创建，同名记录已存在时报错
+	CatalogModeUpsert = "upsert" // 按名称更新已存在的记录，不存在时创建
+)
+
+// 应用目录记录类型
+const (
+	CatalogKindGroup = "group"
+	CatalogKindEnv   = "env"
+	CatalogKindApp   = "app"
+)
+
+// 导入动作
+const (
+	CatalogActionCreate    = "create"
+	CatalogActionUpdate    = "update"
+	CatalogActionUnchanged = "unchanged"
+)
+
+// MaxCatalogRows 单批导入的记录数上限，超过时应拆分为多批导入
+const MaxCatalogRows = 2000
+
+// MaxCatalogSize 导入文件的大小上限
+const MaxCatalogSize = 10 << 20
+
+// catalogListSeparator CSV中分组、镜像仓库列表的分隔符
+const catalogListSeparator = ";"
+
+// catalogCSVHeader CSV列，每行一条分组、环境或应用记录，按kind区分，不适用的列留空
+var catalogCSVHeader = []string{"kind", "name", "description", "cluster_id", "namespace", "status",
+	"groups", "registries", "min_replicas", "max_replicas", "target_cpu", "target_memory"}
+
+// CatalogDocument 应用目录文档：应用分组、环境与应用，应用按名称引用分组与镜像仓库，便于在不同系统间迁移
+type CatalogDocument struct {
+	Version string          `json:"version"`
+	Groups  []*CatalogGroup `json:"groups"`
+	Envs    []*CatalogEnv   `json:"envs"`
+	Apps    []*CatalogApp   `json:"apps"`
+}
+
+// CatalogGroup 应用分组记录
+type CatalogGroup struct {
+	// 行号：CSV为文件中的行号，YAML、JSON为在所属列表中的序号，从1开始
+	Row         int    `json:"-"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CatalogEnv 环境记录
+type CatalogEnv struct {
+	Row         int        `json:"-"`
+	Name        string     `json:"name"`
+	ClusterID   types.Long `json:"cluster_id"`
+	Namespace   string     `json:"namespace"`
+	Description string     `json:"description,omitempty"`
+}
+
+// CatalogApp 应用记录，分组与镜像仓库按名称引用，镜像仓库必须已存在
+type CatalogApp struct {
+	Row         int         `json:"-"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Status      string      `json:"status,omitempty"`
+	Groups      []string    `json:"groups,omitempty"`
+	Registries  []string    `json:"registries,omitempty"`
+	HPA         *CatalogHPA `json:"hpa,omitempty"`
+}
+
+// CatalogHPA 应用默认HPA配置，没有指定伸缩指标时按CPU、内存目标使用率生成
+type CatalogHPA struct {
+	MinReplicas  int          `json:"min_replicas"`
+	MaxReplicas  int          `json:"max_replicas"`
+	TargetCPU    int          `json:"target_cpu,omitempty"`
+	TargetMemory int          `json:"target_memory,omitempty"`
+	Metrics      []HPAMetric  `json:"metrics,omitempty"`
+	Behavior     *HPABehavior `json:"behavior,omitempty"`
+}
+
+// SaveCommand 转换为保存应用默认HPA的命令
+func (h *CatalogHPA) SaveCommand(appID types.Long) *SaveHPACommand {
+	command := &SaveHPACommand{
+		AppID:       appID,
+		MinReplicas: h.MinReplicas,
+		MaxReplicas: h.MaxReplicas,
+		Metrics:     h.Metrics,
+	}
+	if len(command.Metrics) == 0 {
+		if h.TargetCPU > 0 {
+			command.Metrics = append(command.Metrics, utilizationMetric("cpu", h.TargetCPU))
+		}
+		if h.TargetMemory > 0 {
+			command.Metrics = append(command.Metrics, utilizationMetric("memory", h.TargetMemory))
+		}
+	}
+	if h.Behavior != nil {
+		command.Behavior = *h.Behavior
+	}
+	return command
+}
+
+// NewCatalogHPA 由应用默认HPA配置生成记录，只有CPU、内存目标使用率时不输出完整的伸缩指标
+func NewCatalogHPA(hpa *AppHPA) *CatalogHPA {
+	hpa.Normalize()
+	result := &CatalogHPA{
+		MinReplicas:  hpa.MinReplicas,
+		MaxReplicas:  hpa.MaxReplicas,
+		TargetCPU:    hpa.TargetCPU,
+		TargetMemory: hpa.TargetMemory,
+	}
+	shorthand := 0
+	for _, target := range []int{hpa.TargetCPU, hpa.TargetMemory} {
+		if target > 0 {
+			shorthand++
+		}
+	}
+	if len(hpa.Metrics) != shorthand {
+		result.Metrics = hpa.Metrics
+	}
+	if hpa.Behavior.ScaleUp != nil || hpa.Behavior.ScaleDown != nil {
+		behavior := hpa.Behavior
+		result.Behavior = &behavior
+	}
+	return result
+}
+
+// CatalogRowError 一行记录的错误
+type CatalogRowError struct {
+	Row     int    `json:"row"`
+	Kind    string `json:"kind,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// CatalogChange 一行记录的导入动作
+type CatalogChange struct {
+	Row    int    `json:"row"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ImportCatalogCommand 导入应用目录命令
+type ImportCatalogCommand struct {
+	Format string `form:"format" binding:"omitempty,oneof=yaml json csv"`
+	Mode   string `form:"mode" binding:"omitempty,oneof=create upsert"`
+	DryRun bool   `form:"dry_run"`
+}
+
+// CatalogImportResultVO 应用目录导入结果。有任一行出错时整批不导入
+type CatalogImportResultVO struct {
+	Format string `json:"format"`
+	Mode   string `json:"mode"`
+	DryRun bool   `json:"dry_run"`
+	// 是否已写入，试运行或有错误时为false
+	Applied bool               `json:"applied"`
+	Total   int                `json:"total"`
+	Changes []*CatalogChange   `json:"changes"`
+	Errors  []*CatalogRowError `json:"errors"`
+	// 各导入动作的记录数，error为出错的记录数
+	Summary map[string]int `json:"summary"`
+}
+
+// NewCatalogImportResultVO 根据导入动作与错误构建导入结果
+func NewCatalogImportResultVO(command *ImportCatalogCommand, total int, changes []*CatalogChange, rowErrors []*CatalogRowError) *CatalogImportResultVO {
+	summary := map[string]int{
+		CatalogActionCreate:    0,
+		CatalogActionUpdate:    0,
+		CatalogActionUnchanged: 0,
+		"error":                0,
+	}
+	for _, change := range changes {
+		summary[change.Action]++
+	}
+	failed := make(map[string]bool, len(rowErrors))
+	for _, rowError := range rowErrors {
+		// 同一行可能有多个错误
+		key := fmt.Sprintf("%s/%d", rowError.Kind, rowError.Row)
+		if !failed[key] {
+			failed[key] = true
+			summary["error"]++
+		}
+	}
+	if changes == nil {
+		changes = make([]*CatalogChange, 0)
+	}
+	if rowErrors == nil {
+		rowErrors = make([]*CatalogRowError, 0)
+	}
+	return &CatalogImportResultVO{
+		Format:  command.Format,
+		Mode:    command.Mode,
+		DryRun:  command.DryRun,
+		Total:   total,
+		Changes: changes,
+		Errors:  rowErrors,
+		Summary: summary,
+	}
+}
+
+// Total 文档中的记录数
+func (d *CatalogDocument) Total() int {
+	return len(d.Groups) + len(d.Envs) + len(d.Apps)
+}
+
+// ParseCatalog 解析应用目录文件，CSV中无法解析的行作为行错误返回，其余记录继续解析
+func ParseCatalog(format string, data []byte) (*CatalogDocument, []*CatalogRowError, error) {
+	// 导出的CSV带有UTF-8 BOM
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	var (
+		doc       *CatalogDocument
+		rowErrors []*CatalogRowError
+		err       error
+	)
+	switch format {
+	case CatalogFormatCSV:
+		doc, rowErrors, err = parseCatalogCSV(data)
+	case CatalogFormatYAML:
+		// 转换为JSON后按JSON字段名解析，与导出的字段名一致
+		var value interface{}
+		if err = yaml.Unmarshal(data, &value); err != nil {
+			return nil, nil, fmt.Errorf("YAML格式错误: %w", err)
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return nil, nil, fmt.Errorf("YAML格式错误: %w", err)
+		}
+		doc, err = parseCatalogJSON(data)
+	default:
+		doc, err = parseCatalogJSON(data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc.Version != "" && doc.Version != CatalogDocumentVersion {
+		return nil, nil, fmt.Errorf("不支持的应用目录文档版本: %s", doc.Version)
+	}
+	if total := doc.Total() + len(rowErrors); total > MaxCatalogRows {
+		return nil, nil, fmt.Errorf("单批最多导入%d条记录，当前%d条，请拆分后导入", MaxCatalogRows, total)
+	}
+	return doc, rowErrors, nil
+}
+
+func parseCatalogJSON(data []byte) (*CatalogDocument, error) {
+	var doc CatalogDocument
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("文档格式错误: %w", err)
+	}
+	for i, group := range doc.Groups {
+		if group == nil {
+			return nil, fmt.Errorf("groups第%d条记录为空", i+1)
+		}
+		group.Row = i + 1
+	}
+	for i, env := range doc.Envs {
+		if env == nil {
+			return nil, fmt.Errorf("envs第%d条记录为空", i+1)
+		}
+		env.Row = i + 1
+	}
+	for i, app := range doc.Apps {
+		if app == nil {
+			return nil, fmt.Errorf("apps第%d条记录为空", i+1)
+		}
+		app.Row = i + 1
+	}
+	return &doc, nil
+}
+
+// parseCatalogCSV 按表头的列名解析CSV，列的顺序不限，缺少的列视为空
+func parseCatalogCSV(data []byte) (*CatalogDocument, []*CatalogRowError, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("CSV缺少表头: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"kind", "name"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("CSV缺少%s列", name)
+		}
+	}
+
+	doc := &CatalogDocument{}
+	var rowErrors []*CatalogRowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, &CatalogRowError{Row: parseErr.StartLine, Message: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("CSV格式错误: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := &catalogCSVRow{columns: columns, record: record}
+		if row.empty() {
+			continue
+		}
+		if rowError := row.appendTo(doc, line); rowError != nil {
+			rowErrors = append(rowErrors, rowError)
+		}
+	}
+	return doc, rowErrors, nil
+}
+
+// catalogCSVRow CSV的一行
+type catalogCSVRow struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r *catalogCSVRow) get(column string) string {
+	if i, ok := r.columns[column]; ok && i < len(r.record) {
+		return strings.TrimSpace(r.record[i])
+	}
+	return ""
+}
+
+func (r *catalogCSVRow) empty() bool {
+	for _, value := range r.record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// appendTo 按kind将一行追加到文档，数字列无法解析时返回行错误
+func (r *catalogCSVRow) appendTo(doc *CatalogDocument, line int) *CatalogRowError {
+	kind, name := strings.ToLower(r.get("kind")), r.get("name")
+	rowError := func(message string) *CatalogRowError {
+		return &CatalogRowError{Row: line, Kind: kind, Name: name, Message: message}
+	}
+	switch kind {
+	case CatalogKindGroup:
+		doc.Groups = append(doc.Groups, &CatalogGroup{Row: line, Name: name, Description: r.get("description")})
+	case CatalogKindEnv:
+		env := &CatalogEnv{Row: line, Name: name, Namespace: r.get("namespace"), Description: r.get("description")}
+		if value := r.get("cluster_id"); value != "" {
+			clusterID, err := types.StringToLong(value)
+			if err != nil {
+				return rowError("cluster_id必须是数字")
+			}
+			env.ClusterID = clusterID
+		}
+		doc.Envs = append(doc.Envs, env)
+	case CatalogKindApp:
+		app := &CatalogApp{
+			Row:         line,
+			Name:        name,
+			Description: r.get("description"),
+			Status:      r.get("status"),
+			Groups:      splitCatalogList(r.get("groups")),
+			Registries:  splitCatalogList(r.get("registries")),
+		}
+		numbers := make(map[string]int, 4)
+		for _, column := range []string{"min_replicas", "max_replicas", "target_cpu", "target_memory"} {
+			value := r.get(column)
+			if value == "" {
+				continue
+			}
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return rowError(column + "必须是整数")
+			}
+			numbers[column] = number
+		}
+		// 填写了任一HPA列时配置HPA
+		if len(numbers) > 0 {
+			app.HPA = &CatalogHPA{
+				MinReplicas:  numbers["min_replicas"],
+				MaxReplicas:  numbers["max_replicas"],
+				TargetCPU:    numbers["target_cpu"],
+				TargetMemory: numbers["target_memory"],
+			}
+		}
+		doc.Apps = append(doc.Apps, app)
+	default:
+		return rowError(fmt.Sprintf("kind必须是%s、%s或%s", CatalogKindGroup, CatalogKindEnv, CatalogKindApp))
+	}
+	return nil
+}
+
+func splitCatalogList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, catalogListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate 校验每条记录的必填项、长度、取值与文档内的重复名称，返回全部行错误
+func (d *CatalogDocument) Validate() []*CatalogRowError {
+	var rowErrors []*CatalogRowError
+	add := func(row int, kind, name, message string) {
+		rowErrors = append(rowErrors, &CatalogRowError{Row: row, Kind: kind, Name: name, Message: message})
+	}
+
+	groups := make(map[string]bool, len(d.Groups))
+	for _, group := range d.Groups {
+		group.Name = strings.TrimSpace(group.Name)
+		switch {
+		case group.Name == "":
+			add(group.Row, CatalogKindGroup, "", "分组名称不能为空")
+		case len([]rune(group.Name)) > 100:
+			add(group.Row, CatalogKindGroup, group.Name, "分组名称不能超过100个字符")
+		case groups[group.Name]:
+			add(group.Row, CatalogKindGroup, group.Name, "分组名称重复")
+		}
+		if len([]rune(group.Description)) > 500 {
+			add(group.Row, CatalogKindGroup, group.Name, "描述不能超过500个字符")
+		}
+		groups[group.Name] = true
+	}
+
+	envs := make(map[string]bool, len(d.Envs))
+	for _, env := range d.Envs {
+		env.Name = strings.TrimSpace(env.Name)
+		env.Namespace = strings.TrimSpace(env.Namespace)
+		switch {
+		case env.Name == "":
+			add(env.Row, CatalogKindEnv, "", "环境名称不能为空")
+		case len([]rune(env.Name)) > 100:
+			add(env.Row, CatalogKindEnv, env.Name, "环境名称不能超过100个字符")
+		case envs[env.Name]:
+			add(env.Row, CatalogKindEnv, env.Name, "环境名称重复")
+		}
+		if env.ClusterID <= 0 {
+			add(env.Row, CatalogKindEnv, env.Name, "必须指定集群ID")
+		}
+		if len(env.Namespace) > 63 || !dnsLabelPattern.MatchString(env.Namespace) {
+			add(env.Row, CatalogKindEnv, env.Name, fmt.Sprintf("命名空间无效: %q", env.Namespace))
+		}
+		if len([]rune(env.Description)) > 500 {
+			add(env.Row, CatalogKindEnv, env.Name, "描述不能超过500个字符")
+		}
+		envs[env.Name] = true
+	}
+
+	apps := make(map[string]bool, len(d.Apps))
+	for _, app := range d.Apps {
+		app.Name = strings.TrimSpace(app.Name)
+		switch {
+		case app.Name == "":
+			add(app.Row, CatalogKindApp, "", "应用名称不能为空")
+		case len([]rune(app.Name)) > 100:
+			add(app.Row, CatalogKindApp, app.Name, "应用名称不能超过100个字符")
+		case apps[app.Name]:
+			add(app.Row, CatalogKindApp, app.Name, "应用名称重复")
+		}
+		if len([]rune(app.Description)) > 500 {
+			add(app.Row, CatalogKindApp, app.Name, "描述不能超过500个字符")
+		}
+		if app.Status != "" && app.Status != AppStatusActive && app.Status != AppStatusInactive {
+			add(app.Row, CatalogKindApp, app.Name,
+				fmt.Sprintf("状态必须是%s或%s", AppStatusActive, AppStatusInactive))
+		}
+		if app.HPA != nil {
+			if err := app.HPA.SaveCommand(0).Validate(); err != nil {
+				add(app.Row, CatalogKindApp, app.Name, "HPA配置无效: "+err.Error())
+			}
+		}
+		apps[app.Name] = true
+	}
+	return rowErrors
+}
+
+// EncodeCatalog 按格式编码应用目录文档，CSV带UTF-8 BOM便于Excel识别中文；
+// CSV的HPA只包含CPU、内存目标使用率，其他伸缩指标与伸缩行为需要使用YAML或JSON
+func EncodeCatalog(format string, doc *CatalogDocument) ([]byte, error) {
+	switch format {
+	case CatalogFormatJSON:
+		return json.MarshalIndent(doc, "", "  ")
+	case CatalogFormatCSV:
+		return encodeCatalogCSV(doc)
+	default:
+		return kube.MarshalYAML(doc)
+	}
+}
+
+func encodeCatalogCSV(doc *CatalogDocument) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(&buffer)
+	records := [][]string{catalogCSVHeader}
+	for _, group := range doc.Groups {
+		records = append(records, []string{CatalogKindGroup, group.Name, group.Description,
+			"", "", "", "", "", "", "", "", ""})
+	}
+	for _, env := range doc.Envs {
+		records = append(records, []string{CatalogKindEnv, env.Name, env.Description,
+			env.ClusterID.String(), env.Namespace, "", "", "", "", "", "", ""})
+	}
+	for _, app := range doc.Apps {
+		record := []string{CatalogKindApp, app.Name, app.Description, "", "", app.Status,
+			strings.Join(app.Groups, catalogListSeparator), strings.Join(app.Registries, catalogListSeparator),
+			"", "", "", ""}
+		if app.HPA != nil {
+			record[8], record[9] = strconv.Itoa(app.HPA.MinReplicas), strconv.Itoa(app.HPA.MaxReplicas)
+			record[10], record[11] = catalogNumber(app.HPA.TargetCPU), catalogNumber(app.HPA.TargetMemory)
+		}
+		records = append(records, record)
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// catalogNumber 0表示未设置，导出为空
+func catalogNumber(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
@@ -0,0 +1,265 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// envNamePattern 环境变量名
+	envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// configKeyPattern ConfigMap/Secret的键名
+	configKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+)
+
+// AppConfigRevision 应用在某个环境下的配置版本，创建后不可修改，每次变更生成新版本
+type AppConfigRevision struct {
+	module.CreateOnlyModule
+	AppID      types.Long `json:"app_id" gorm:"not null;uniqueIndex:uk_app_env_revision,priority:1;comment:'应用ID'"`
+	EnvID      types.Long `json:"env_id" gorm:"not null;uniqueIndex:uk_app_env_revision,priority:2;comment:'环境ID'"`
+	Revision   int        `json:"revision" gorm:"not null;uniqueIndex:uk_app_env_revision,priority:3;comment:'版本号，从1开始'"`
+	Envs       types.Envs `json:"envs" gorm:"comment:'环境变量'"`
+	Files      types.Envs `json:"files" gorm:"comment:'配置文件，文件名到内容'"`
+	MountPath  string     `json:"mount_path" gorm:"size:255;comment:'配置文件挂载目录'"`
+	SecretRefs SecretRefs `json:"secret_refs" gorm:"comment:'引用的Secret'"`
+	Checksum   string     `json:"checksum" gorm:"size:64;not null;comment:'配置内容摘要'"`
+	Comment    string     `json:"comment" gorm:"size:500;comment:'变更说明'"`
+	RestoredID types.Long `json:"restored_id" gorm:"comment:'恢复或晋级自的配置版本ID，0表示直接修改'"`
+}
+
+// TableName 返回应用配置版本表名
+func (AppConfigRevision) TableName() string {
+	return "app_config_revision"
+}
+
+// SecretRef 从集群中已有Secret的键注入环境变量，平台不保存Secret内容
+type SecretRef struct {
+	Name   string `json:"name"`   // 环境变量名
+	Secret string `json:"secret"` // Secret名称
+	Key    string `json:"key"`    // Secret中的键
+}
+
+// SecretRefs Secret引用列表
+type SecretRefs []SecretRef
+
+func (SecretRefs) GormDataType() string {
+	return "json"
+}
+
+// 实现 sql.Scanner 接口
+func (r *SecretRefs) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New(fmt.Sprint("Failed to unmarshal JSON value:", value))
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// 实现 driver.Valuer 接口
+func (r SecretRefs) Value() (driver.Value, error) {
+	if r == nil {
+		r = SecretRefs{}
+	}
+	jsonStr, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(jsonStr).MarshalJSON()
+}
+
+// SaveConfigCommand 保存应用环境配置命令，生成新的配置版本
+type SaveConfigCommand struct {
+	AppID      types.Long `json:"-"`
+	EnvID      types.Long `json:"-"`
+	Envs       types.Envs `json:"envs"`
+	Files      types.Envs `json:"files"`
+	MountPath  string     `json:"mount_path" binding:"max=255"`
+	SecretRefs SecretRefs `json:"secret_refs" binding:"max=100"`
+	Comment    string     `json:"comment" binding:"max=500"`
+}
+
+// Validate 校验环境变量名、文件名、Secret引用与总大小
+func (command *SaveConfigCommand) Validate() error {
+	for name := range command.Envs {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("环境变量名无效: %s", name)
+		}
+	}
+	size := 0
+	for name, content := range command.Files {
+		if len(name) > MaxConfigKeyLength || !configKeyPattern.MatchString(name) || name == "." || name == ".." {
+			return fmt.Errorf("配置文件名无效: %s", name)
+		}
+		size += len(name) + len(content)
+	}
+	for name, value := range command.Envs {
+		size += len(name) + len(value)
+	}
+	if size > MaxConfigSize {
+		return fmt.Errorf("配置内容超过 %d 字节", MaxConfigSize)
+	}
+
+	if len(command.Files) > 0 {
+		command.MountPath = strings.TrimSpace(command.MountPath)
+		if command.MountPath == "" {
+			command.MountPath = DefaultConfigMountPath
+		}
+		if !path.IsAbs(command.MountPath) {
+			return fmt.Errorf("配置文件挂载目录必须是绝对路径: %s", command.MountPath)
+		}
+		command.MountPath = path.Clean(command.MountPath)
+	} else {
+		command.MountPath = ""
+	}
+
+	seen := make(map[string]bool, len(command.SecretRefs))
+	for _, ref := range command.SecretRefs {
+		if !envNamePattern.MatchString(ref.Name) {
+			return fmt.Errorf("环境变量名无效: %s", ref.Name)
+		}
+		if _, ok := command.Envs[ref.Name]; ok || seen[ref.Name] {
+			return fmt.Errorf("环境变量重复: %s", ref.Name)
+		}
+		seen[ref.Name] = true
+		if ref.Secret == "" || ref.Key == "" || !configKeyPattern.MatchString(ref.Key) {
+			return fmt.Errorf("环境变量 %s 的Secret引用无效", ref.Name)
+		}
+	}
+	return nil
+}
+
+// NewRevision 根据命令生成下一个配置版本
+func (command *SaveConfigCommand) NewRevision(revision int) *AppConfigRevision {
+	config := &AppConfigRevision{
+		AppID:      command.AppID,
+		EnvID:      command.EnvID,
+		Revision:   revision,
+		Envs:       command.Envs,
+		Files:      command.Files,
+		MountPath:  command.MountPath,
+		SecretRefs: command.SecretRefs,
+		Comment:    command.Comment,
+	}
+	if config.Envs == nil {
+		config.Envs = types.Envs{}
+	}
+	if config.Files == nil {
+		config.Files = types.Envs{}
+	}
+	if config.SecretRefs == nil {
+		config.SecretRefs = SecretRefs{}
+	}
+	sort.Slice(config.SecretRefs, func(i, j int) bool { return config.SecretRefs[i].Name < config.SecretRefs[j].Name })
+	config.Checksum = config.ContentChecksum()
+	return config
+}
+
+// ContentChecksum 配置内容的摘要，用于判断配置是否变化
+func (r *AppConfigRevision) ContentChecksum() string {
+	// map按键排序序列化，结果稳定
+	content, _ := json.Marshal(struct {
+		Envs       types.Envs `json:"envs"`
+		Files      types.Envs `json:"files"`
+		MountPath  string     `json:"mount_path"`
+		SecretRefs SecretRefs `json:"secret_refs"`
+	}{r.Envs, r.Files, r.MountPath, r.SecretRefs})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ConfigRevisionQuery 配置版本查询条件
+type ConfigRevisionQuery struct {
+	AppID types.Long `form:"-"`
+	EnvID types.Long `form:"-"`
+	Page  int        `form:"page"`
+	Size  int        `form:"size"`
+}
+
+// ConfigChange 一项配置变化
+type ConfigChange struct {
+	Key    string `json:"key"`
+	Action string `json:"action"` // added, removed, modified
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// ConfigDiffVO 两个配置版本之间的差异，from为0表示与空配置比较
+type ConfigDiffVO struct {
+	From       int             `json:"from"`
+	To         int             `json:"to"`
+	Envs       []*ConfigChange `json:"envs"`
+	Files      []*ConfigChange `json:"files"`
+	MountPath  *ConfigChange   `json:"mount_path,omitempty"`
+	SecretRefs []*ConfigChange `json:"secret_refs"`
+}
+
+// DiffConfig 比较两个配置版本，from为nil时视为空配置
+func DiffConfig(from, to *AppConfigRevision) *ConfigDiffVO {
+	if from == nil {
+		from = &AppConfigRevision{}
+	}
+	diff := &ConfigDiffVO{
+		From:       from.Revision,
+		To:         to.Revision,
+		Envs:       diffMap(from.Envs, to.Envs),
+		Files:      diffMap(from.Files, to.Files),
+		SecretRefs: diffMap(from.SecretRefs.toMap(), to.SecretRefs.toMap()),
+	}
+	if from.MountPath != to.MountPath {
+		diff.MountPath = &ConfigChange{Key: "mount_path", Action: ConfigChangeModified, Old: from.MountPath, New: to.MountPath}
+	}
+	return diff
+}
+
+// toMap Secret引用按环境变量名展开为 secret/key
+func (r SecretRefs) toMap() map[string]string {
+	refs := make(map[string]string, len(r))
+	for _, ref := range r {
+		refs[ref.Name] = ref.Secret + "/" + ref.Key
+	}
+	return refs
+}
+
+// diffMap 按键名排序列出新增、删除与修改的项
+func diffMap(from, to map[string]string) []*ConfigChange {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]*ConfigChange, 0)
+	for _, key := range keys {
+		oldValue, inFrom := from[key]
+		newValue, inTo := to[key]
+		switch {
+		case !inFrom:
+			changes = append(changes, &ConfigChange{Key: key, Action: ConfigChangeAdded, New: newValue})
+		case !inTo:
+			changes = append(changes, &ConfigChange{Key: key, Action: ConfigChangeRemoved, Old: oldValue})
+		case oldValue != newValue:
+			changes = append(changes, &ConfigChange{Key: key, Action: ConfigChangeModified, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // 模块Bean名称常量
 const (
 	// BeanModuleName 模块名称
@@ -14,6 +16,40 @@ const (
 	BeanDeployService = "deployService"
 	// BeanAppQuery 应用查询Bean名称
 	BeanAppQuery = "appQuery"
+	// BeanConfigService 应用配置服务Bean名称
+	BeanConfigService = "configService"
+	// BeanVolumeService 共享存储卷服务Bean名称
+	BeanVolumeService = "volumeService"
+	// BeanClusterClient 应用模块使用的集群客户端Bean名称
+	BeanClusterClient = "appClusterClient"
+	// BeanWorkloadService 应用工作负载定义服务Bean名称
+	BeanWorkloadService = "workloadService"
+	// BeanHPAService 应用HPA服务Bean名称
+	BeanHPAService = "hpaService"
+	// BeanMemberService 应用成员服务Bean名称
+	BeanMemberService = "memberService"
+	// BeanPromotionService 环境晋级服务Bean名称
+	BeanPromotionService = "promotionService"
+	// BeanTrainService 发布列车服务Bean名称
+	BeanTrainService = "trainService"
+	// BeanTrainWatcher 发布列车推进任务Bean名称
+	BeanTrainWatcher = "appTrainWatcher"
+	// BeanLockService 部署锁服务Bean名称
+	BeanLockService = "lockService"
+	// BeanReleaseDiffService 发布差异服务Bean名称
+	BeanReleaseDiffService = "releaseDiffService"
+	// BeanDeployQueueWatcher 排队发布计划检查任务Bean名称
+	BeanDeployQueueWatcher = "appDeployQueueWatcher"
+	// BeanJobWatcher Job运行状态同步任务Bean名称
+	BeanJobWatcher = "appJobWatcher"
+	// BeanDriftService 配置漂移检测服务Bean名称
+	BeanDriftService = "driftService"
+	// BeanDriftWatcher 配置漂移检测任务Bean名称
+	BeanDriftWatcher = "appDriftWatcher"
+	// BeanTemplateService 应用模板服务Bean名称
+	BeanTemplateService = "templateService"
+	// BeanCatalogService 应用目录导入导出服务Bean名称
+	BeanCatalogService = "catalogService"
 )
 
 // 应用状态常量
@@ -30,6 +66,10 @@ const (
 const (
 	// DeployStatusPending 部署状态-待处理
 	DeployStatusPending = "pending"
+	// DeployStatusApproved 发布计划状态-已审批
+	DeployStatusApproved = "approved"
+	// DeployStatusQueued 发布计划状态-排队等待部署锁
+	DeployStatusQueued = "queued"
 	// DeployStatusRunning 部署状态-运行中
 	DeployStatusRunning = "running"
 	// DeployStatusSuccess 部署状态-成功
@@ -40,6 +80,9 @@ const (
 	DeployStatusRollback = "rollback"
 )
 
+// RollbackVersionSuffix 回滚部署的版本号后缀
+const RollbackVersionSuffix = "-rollback"
+
 // 部署策略常量
 const (
 	// DeployStrategyRolling 部署策略-滚动更新
@@ -49,3 +92,88 @@ const (
 	// DeployStrategyCanary 部署策略-金丝雀发布
 	DeployStrategyCanary = "canary"
 )
+
+// 应用配置常量
+const (
+	// DefaultConfigMountPath 配置文件默认挂载目录
+	DefaultConfigMountPath = "/etc/config"
+	// MaxConfigSize 单个版本配置内容上限，与ConfigMap的1MiB限制一致
+	MaxConfigSize = 1 << 20
+	// MaxConfigKeyLength 配置文件名长度上限
+	MaxConfigKeyLength = 253
+
+	// 配置变化类型
+	ConfigChangeAdded    = "added"
+	ConfigChangeRemoved  = "removed"
+	ConfigChangeModified = "modified"
+)
+
+// 共享存储卷常量
+const (
+	// VolumeTypeNFS NFS存储卷
+	VolumeTypeNFS = "nfs"
+	// VolumeTypePVC 命名空间中已有的PVC
+	VolumeTypePVC = "pvc"
+	// MaxMountPathLength 挂载路径长度上限
+	MaxMountPathLength = 255
+	// ConfigFilesVolume Pod中挂载配置文件的卷名，共享存储卷不能使用
+	ConfigFilesVolume = "config-files"
+)
+
+// 渲染的Kubernetes资源
+const (
+	// LabelAppName 应用名称标签
+	LabelAppName = "app.kubernetes.io/name"
+	// LabelManagedBy 管理者标签
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	// ManagedBy 平台作为管理者的名称
+	ManagedBy = "devops-platform"
+	// AnnotationConfigRevision 资源对应的配置版本
+	AnnotationConfigRevision = "devops-platform/config-revision"
+	// AnnotationWorkloadRevision 资源对应的工作负载定义版本
+	AnnotationWorkloadRevision = "devops-platform/workload-revision"
+	// LabelDeployment 创建Job的部署记录ID，CronJob产生的Job同样带有该标签
+	LabelDeployment = "devops-platform/deployment"
+	// LabelCronJob CronJob产生的Job所属的CronJob名称
+	LabelCronJob = "devops-platform/cronjob"
+	// DefaultReplicas 工作负载定义未指定副本数时的默认值
+	DefaultReplicas = 1
+	// DefaultImageTag 渲染工作负载时未指定版本使用的镜像标签
+	DefaultImageTag = "latest"
+	// MaxResourceNameLength 资源名称长度上限（DNS-1123标签）
+	MaxResourceNameLength = 63
+)
+
+// Job部署常量
+const (
+	// JobConcurrencyAllow 定时任务允许并发运行
+	JobConcurrencyAllow = "Allow"
+	// JobConcurrencyForbid 上次运行未结束时跳过本次
+	JobConcurrencyForbid = "Forbid"
+	// JobConcurrencyReplace 上次运行未结束时替换为本次
+	JobConcurrencyReplace = "Replace"
+	// DefaultJobBackoffLimit 默认不重试，退出码即为单次运行的结果
+	DefaultJobBackoffLimit = 0
+	// DefaultJobTimeout 未设置运行时限时等待Job完成的时间
+	DefaultJobTimeout = time.Hour
+	// JobTimeoutGrace 设置了运行时限时额外等待的时间，用于Kubernetes标记失败
+	JobTimeoutGrace = time.Minute
+	// JobPollInterval 等待Job完成时查询状态的间隔
+	JobPollInterval = 5 * time.Second
+	// JobSyncInterval 同步定时任务运行记录的间隔
+	JobSyncInterval = 30 * time.Second
+	// TrainSyncInterval 推进发布列车的间隔
+	TrainSyncInterval = 5 * time.Second
+	// DeployLockTTL 部署租约的有效期，部署期间定期续期
+	DeployLockTTL = 10 * time.Minute
+	// DeployLockRenewInterval 部署租约的续期间隔
+	DeployLockRenewInterval = time.Minute
+	// DeployQueueInterval 检查排队发布计划的间隔，用于接管过期未释放的租约
+	DeployQueueInterval = 30 * time.Second
+	// DriftCheckInterval 检测集群中的工作负载与部署记录是否一致的间隔
+	DriftCheckInterval = 5 * time.Minute
+	// JobLogTailLines 收集日志的行数
+	JobLogTailLines = 500
+	// MaxJobLogSize 保存的日志大小上限，超出时保留末尾
+	MaxJobLogSize = 64 << 10
+)
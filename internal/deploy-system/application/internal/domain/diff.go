@@ -0,0 +1,309 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"devops-platform/pkg/types"
+)
+
+// maxDiffCells 逐行比较的规模上限，超过时整体视为删除后新增
+const maxDiffCells = 4000000
+
+// diffContextLines 统一格式差异中变化前后保留的上下文行数
+const diffContextLines = 3
+
+// ReleaseDiffVO 发布计划相对于环境中当前运行的部署的变化
+type ReleaseDiffVO struct {
+	PlanID types.Long `json:"plan_id"`
+	AppID  types.Long `json:"app_id"`
+	EnvID  types.Long `json:"env_id"`
+	// 当前运行的部署，为nil表示应用在该环境还没有成功的部署，所有内容都视为新增
+	Current *Deployment   `json:"current"`
+	Changed bool          `json:"changed"`
+	Image   *ImageDiff    `json:"image"`
+	Config  *ConfigDiffVO `json:"config"`
+	// 渲染后的工作负载按字段路径比较，应用没有工作负载定义时为空
+	Workload []*ConfigChange `json:"workload"`
+	// HPA规格按字段路径比较，Job部署方式没有HPA
+	HPA []*ConfigChange `json:"hpa"`
+	// 两个版本之间的提交，构建模块没有两个版本的构建记录时为nil
+	Commits *CommitRange `json:"commits"`
+	// 统一格式（unified diff）的文本差异
+	Unified string `json:"unified"`
+}
+
+// ImageDiff 镜像版本与digest的变化
+type ImageDiff struct {
+	OldVersion string `json:"old_version"`
+	NewVersion string `json:"new_version"`
+	OldDigest  string `json:"old_digest"`
+	NewDigest  string `json:"new_digest"`
+	// 完整的镜像引用，应用没有工作负载定义时为空
+	OldImage string `json:"old_image"`
+	NewImage string `json:"new_image"`
+	Changed  bool   `json:"changed"`
+}
+
+// CommitRange 两个版本之间的提交，由构建模块按构建记录提供
+type CommitRange struct {
+	FromCommit string `json:"from_commit"`
+	ToCommit   string `json:"to_commit"`
+	// 代码仓库的比较页面，仓库地址无法识别时为空
+	CompareURL string `json:"compare_url,omitempty"`
+	// 目标版本的构建早于当前版本，即版本回退
+	Reverted bool `json:"reverted"`
+	// 两个版本之间成功构建过的提交，按构建序号倒序
+	Commits []*BuildCommit `json:"commits"`
+}
+
+// BuildCommit 成功构建过的提交
+type BuildCommit struct {
+	Commit      string     `json:"commit"`
+	Branch      string     `json:"branch"`
+	BuildID     types.Long `json:"build_id"`
+	BuildNumber int        `json:"build_number"`
+	ImageTag    string     `json:"image_tag"`
+	BuiltAt     *time.Time `json:"built_at"`
+}
+
+// HasChanges 配置是否有变化
+func (d *ConfigDiffVO) HasChanges() bool {
+	return d.MountPath != nil || len(d.Envs) > 0 || len(d.Files) > 0 || len(d.SecretRefs) > 0
+}
+
+// DiffObjects 将两个对象序列化为JSON后按字段路径比较，对象为nil时视为空
+func DiffObjects(from, to interface{}) ([]*ConfigChange, error) {
+	fromFields, err := flattenObject(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := flattenObject(to)
+	if err != nil {
+		return nil, err
+	}
+	return diffMap(fromFields, toFields), nil
+}
+
+// flattenObject 将对象展开为 字段路径 -> 值，如 spec.template.spec.containers[0].image
+func flattenObject(obj interface{}) (map[string]string, error) {
+	fields := make(map[string]string)
+	if obj == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	flattenValue("", value, fields)
+	return fields, nil
+}
+
+// flattenValue 递归展开JSON值，标量按JSON格式记录，字符串直接记录
+func flattenValue(path string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), child, fields)
+		}
+	case nil:
+	case string:
+		fields[path] = v
+	default:
+		data, _ := json.Marshal(v)
+		fields[path] = string(data)
+	}
+}
+
+// ConfigText 将配置版本渲染为便于逐行比较的文本，nil视为空配置
+func ConfigText(config *AppConfigRevision) string {
+	if config == nil {
+		return ""
+	}
+	var b strings.Builder
+	if config.MountPath != "" {
+		fmt.Fprintf(&b, "mount_path: %s\n", config.MountPath)
+	}
+	for _, key := range sortedKeys(config.Envs) {
+		fmt.Fprintf(&b, "env %s=%s\n", key, config.Envs[key])
+	}
+	refs := config.SecretRefs.toMap()
+	for _, key := range sortedKeys(refs) {
+		fmt.Fprintf(&b, "secret %s=%s\n", key, refs[key])
+	}
+	for _, key := range sortedKeys(config.Files) {
+		fmt.Fprintf(&b, "file %s:\n", key)
+		content := strings.TrimSuffix(config.Files[key], "\n")
+		for _, line := range strings.Split(content, "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// sortedKeys 按键名排序
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UnifiedDiff 逐行比较两段文本，生成统一格式（unified diff）的差异，没有变化时返回空字符串
+func UnifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	a, b := splitLines(from), splitLines(to)
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	// 按上下文行数将变化分组为若干块
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		first := start - diffContextLines
+		if first < 0 {
+			first = 0
+		}
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContextLines {
+				break
+			}
+			end = next
+		}
+		last := end + diffContextLines
+		if last > len(ops) {
+			last = len(ops)
+		}
+		writeHunk(&out, ops[first:last])
+		start = last
+	}
+	return out.String()
+}
+
+// diffOp 逐行比较的一行结果：' '未变化，'-'删除，'+'新增
+type diffOp struct {
+	kind   byte
+	line   string
+	aIndex int
+	bIndex int
+}
+
+// writeHunk 输出一个差异块，行号从1开始
+func writeHunk(out *strings.Builder, ops []diffOp) {
+	aStart, bStart, aCount, bCount := 0, 0, 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			if aCount == 0 {
+				aStart = op.aIndex + 1
+			}
+			aCount++
+		}
+		if op.kind != '-' {
+			if bCount == 0 {
+				bStart = op.bIndex + 1
+			}
+			bCount++
+		}
+	}
+	// 一侧没有行时，起始行号为变化位置之前的行
+	if aCount == 0 {
+		aStart = ops[0].aIndex
+	}
+	if bCount == 0 {
+		bStart = ops[0].bIndex
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+	for _, op := range ops {
+		out.WriteByte(op.kind)
+		out.WriteString(op.line)
+		out.WriteByte('\n')
+	}
+}
+
+// diffLines 按最长公共子序列逐行比较
+func diffLines(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	if len(a)*len(b) > maxDiffCells {
+		for i, line := range a {
+			ops = append(ops, diffOp{kind: '-', line: line, aIndex: i, bIndex: 0})
+		}
+		for j, line := range b {
+			ops = append(ops, diffOp{kind: '+', line: line, aIndex: len(a), bIndex: j})
+		}
+		return ops
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i], aIndex: i, bIndex: j})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, diffOp{kind: '+', line: b[j], aIndex: i, bIndex: j})
+			j++
+		default:
+			ops = append(ops, diffOp{kind: '-', line: a[i], aIndex: i, bIndex: j})
+			i++
+		}
+	}
+	return ops
+}
+
+// splitLines 按行拆分，忽略末尾换行
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
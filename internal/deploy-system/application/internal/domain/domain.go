@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"devops-platform/internal/pkg/enum"
 	"devops-platform/internal/pkg/module"
+	"strings"
 	"time"
 
 	"devops-platform/pkg/types"
@@ -14,12 +16,15 @@ type Application struct {
 	Name        string       `json:"name" gorm:"size:100;not null;uniqueIndex"`
 	Description string       `json:"description" gorm:"size:500"`
 	Creator     types.Long   `json:"creator" gorm:"not null"`
+	DeptID      types.Long   `json:"dept_id" gorm:"index;comment:'所属部门ID'"`
 	Status      string       `json:"status" gorm:"size:20;not null;default:'active'"`
 	CreatedAt   time.Time    `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
 	Groups      []*AppGroup  `json:"groups,omitempty" gorm:"-"`
 	Envs        []AppEnv     `json:"envs,omitempty" gorm:"-"`
 	Deployments []Deployment `json:"deployments,omitempty" gorm:"-"`
+	// 各环境挂载的共享存储卷
+	Volumes []*VolumeMountVO `json:"volumes,omitempty" gorm:"-"`
 }
 
 // AppGroup 应用分组实体
@@ -46,14 +51,18 @@ type AppEnv struct {
 	Description string     `json:"description" gorm:"size:500"`
 }
 
-// AppHPA 应用HPA配置
+// AppHPA 应用HPA配置，按autoscaling/v2定义；EnvID为0的是应用默认配置，环境没有单独配置时使用
 type AppHPA struct {
 	module.Module
-	AppID        types.Long `json:"app_id" gorm:"not null;index"`
-	MinReplicas  int        `json:"min_replicas" gorm:"not null;default:1"`
-	MaxReplicas  int        `json:"max_replicas" gorm:"not null;default:10"`
-	TargetCPU    int        `json:"target_cpu" gorm:"not null;default:80"`
-	TargetMemory int        `json:"target_memory" gorm:"default:0"`
+	AppID       types.Long `json:"app_id" gorm:"not null;uniqueIndex:uk_hpa_app_env,priority:1"`
+	EnvID       types.Long `json:"env_id" gorm:"not null;default:0;uniqueIndex:uk_hpa_app_env,priority:2;comment:'环境ID，0表示应用默认配置'"`
+	MinReplicas int        `json:"min_replicas" gorm:"not null;default:1"`
+	MaxReplicas int        `json:"max_replicas" gorm:"not null;default:10"`
+	// 由CPU、内存的Utilization指标同步，兼容简写方式，0表示未设置
+	TargetCPU    int         `json:"target_cpu" gorm:"not null;default:0"`
+	TargetMemory int         `json:"target_memory" gorm:"default:0"`
+	Metrics      HPAMetrics  `json:"metrics" gorm:"comment:'伸缩指标'"`
+	Behavior     HPABehavior `json:"behavior" gorm:"comment:'扩缩容行为'"`
 }
 
 // ImageRegistry 镜像仓库配置
@@ -81,18 +90,39 @@ type ReleasePlan struct {
 	Version  string     `json:"version" gorm:"size:50;not null"`
 	Strategy string     `json:"strategy" gorm:"size:50;not null;default:'rolling'"`
 	Status   string     `json:"status" gorm:"size:20;not null;default:'pending'"`
+	// 镜像digest，为空时按标签部署
+	Digest string `json:"digest" gorm:"size:100"`
+	// 晋级产生的计划锁定来源部署的配置版本，执行时复制到目标环境；0表示使用目标环境的最新配置
+	ConfigRevisionID types.Long `json:"config_revision_id" gorm:"default:0"`
+	// 晋级来源部署记录ID，0表示不是晋级产生的计划
+	PromotedFrom types.Long `json:"promoted_from" gorm:"default:0"`
 }
 
 // Deployment 部署记录
 type Deployment struct {
 	module.Module
-	AppID     types.Long        `json:"app_id" gorm:"not null;index"`
-	EnvID     types.Long        `json:"env_id" gorm:"not null;index"`
-	Version   string            `json:"version" gorm:"size:50;not null"`
-	Status    string            `json:"status" gorm:"size:20;not null;default:'pending'"`
-	StartTime time.Time         `json:"start_time" gorm:"not null"`
-	EndTime   *time.Time        `json:"end_time"`
-	Steps     []*DeploymentStep `json:"steps,omitempty" gorm:"-"`
+	AppID     types.Long `json:"app_id" gorm:"not null;index"`
+	EnvID     types.Long `json:"env_id" gorm:"not null;index"`
+	Version   string     `json:"version" gorm:"size:50;not null"`
+	Status    string     `json:"status" gorm:"size:20;not null;default:'pending'"`
+	StartTime time.Time  `json:"start_time" gorm:"not null;index"`
+	EndTime   *time.Time `json:"end_time"`
+	// 部署的镜像digest，为空表示按标签部署
+	Digest string `json:"digest" gorm:"size:100"`
+	// 部署使用的配置版本，0表示应用在该环境下没有配置
+	ConfigRevisionID types.Long `json:"config_revision_id" gorm:"default:0"`
+	ConfigRevision   int        `json:"config_revision" gorm:"default:0"`
+	// 部署使用的工作负载定义版本，0表示应用没有工作负载定义
+	SpecRevision int `json:"spec_revision" gorm:"default:0"`
+	// 部署方式，与使用的工作负载定义版本一致
+	Mode enum.DeployMode `json:"mode" gorm:"default:0"`
+	// 回滚部署替换掉的部署，即回滚前环境中运行的部署，0表示不是回滚部署
+	RollbackOf types.Long `json:"rollback_of" gorm:"default:0;index"`
+	// 部署时生效的HPA规格，用于比较发布前后的变化
+	HPA   DeployedHPA       `json:"-" gorm:"column:hpa_spec"`
+	Steps []*DeploymentStep `json:"steps,omitempty" gorm:"-"`
+	// Job部署方式的运行记录，不含日志
+	Runs []*JobRun `json:"runs,omitempty" gorm:"-"`
 }
 
 // DeploymentStep 部署步骤记录
@@ -111,6 +141,7 @@ type CreateAppCommand struct {
 	Name        string     `json:"name" binding:"required,max=100"`
 	Description string     `json:"description" binding:"max=500"`
 	Creator     types.Long `json:"creator"`
+	DeptID      types.Long `json:"dept_id"`
 }
 
 // UpdateAppCommand 更新应用命令
@@ -119,6 +150,7 @@ type UpdateAppCommand struct {
 	Name        string     `json:"name" binding:"max=100"`
 	Description string     `json:"description" binding:"max=500"`
 	Status      string     `json:"status" binding:"max=20"`
+	DeptID      types.Long `json:"dept_id"`
 }
 
 // CreateEnvCommand 创建环境命令
@@ -135,6 +167,8 @@ type CreateReleaseCommand struct {
 	EnvID    types.Long `json:"env_id" binding:"required"`
 	Version  string     `json:"version" binding:"required,max=50"`
 	Strategy string     `json:"strategy" binding:"required,max=50"`
+	// 镜像digest，可选，指定后部署与晋级校验都以digest为准
+	Digest string `json:"digest" binding:"max=100"`
 }
 
 // AppQuery 应用查询参数
@@ -152,6 +186,7 @@ type AppVO struct {
 	Name        string       `json:"name"`
 	Description string       `json:"description"`
 	Creator     types.Long   `json:"creator"`
+	DeptID      types.Long   `json:"dept_id"`
 	Status      string       `json:"status"`
 	GroupIDs    []types.Long `json:"group_ids,omitempty"`
 	EnvCount    int          `json:"env_count"`
@@ -199,6 +234,11 @@ func (Deployment) TableName() string {
 	return "deploy_history"
 }
 
+// ReleaseVersion 部署的发布版本，回滚部署的版本带有后缀，实际运行的是原部署的版本
+func (d *Deployment) ReleaseVersion() string {
+	return strings.TrimSuffix(d.Version, RollbackVersionSuffix)
+}
+
 // TableName 返回部署步骤表名
 func (DeploymentStep) TableName() string {
 	return "deploy_steps"
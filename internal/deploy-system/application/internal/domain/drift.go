@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+)
+
+// 漂移项
+const (
+	DriftFieldWorkload = "workload" // 集群中不存在部署的工作负载
+	DriftFieldImage    = "image"    // 容器镜像
+	DriftFieldReplicas = "replicas" // 副本数，使用HPA时由HPA调整，不比较
+	DriftFieldHPA      = "hpa"      // HPA是否存在及副本数范围、伸缩指标
+)
+
+// 漂移状态
+const (
+	DriftStatusOpen     = "open"     // 漂移仍存在
+	DriftStatusResolved = "resolved" // 集群已恢复为部署时的状态
+)
+
+// DriftAbsent 工作负载或HPA不存在时记录的值
+const DriftAbsent = "(不存在)"
+
+// MaxDriftValueSize 记录的期望值与实际值的长度上限
+const MaxDriftValueSize = 1000
+
+// DriftFinding 集群中的工作负载与最近一次成功部署不一致的记录，每个应用环境每个漂移项最多一条未恢复的记录
+type DriftFinding struct {
+	module.Module
+	AppID types.Long `json:"app_id" gorm:"not null;index:idx_drift_app_env,priority:1"`
+	EnvID types.Long `json:"env_id" gorm:"not null;index:idx_drift_app_env,priority:2"`
+	// 比较时使用的部署记录
+	DeploymentID types.Long `json:"deployment_id" gorm:"not null"`
+	Field        string     `json:"field" gorm:"size:20;not null;comment:'漂移项：workload、image、replicas、hpa'"`
+	Expected     string     `json:"expected" gorm:"size:1000"`
+	Actual       string     `json:"actual" gorm:"size:1000"`
+	Status       string     `json:"status" gorm:"size:20;not null;index;comment:'漂移状态：open、resolved'"`
+	// 首次与最近一次发现漂移的时间
+	DetectedAt time.Time  `json:"detected_at" gorm:"not null"`
+	LastSeenAt time.Time  `json:"last_seen_at" gorm:"not null"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// TableName 返回配置漂移表名
+func (DriftFinding) TableName() string {
+	return "app_drift_finding"
+}
+
+// DriftQuery 配置漂移查询条件
+type DriftQuery struct {
+	AppID  types.Long `form:"app_id"`
+	EnvID  types.Long `form:"env_id"`
+	Status string     `form:"status" binding:"omitempty,oneof=open resolved"`
+	Page   int        `form:"page"`
+	Size   int        `form:"size"`
+}
+
+// DriftItem 一项漂移的期望值与实际值
+type DriftItem struct {
+	Field    string
+	Expected string
+	Actual   string
+}
+
+// DetectDrift 比较部署时渲染的工作负载、HPA与集群中的实际对象。
+// 工作负载不存在时只返回一项；使用HPA时副本数由HPA调整，不比较副本数
+func DetectDrift(expected *KubeDeployment, expectedHPA *HPASpec, live *KubeDeployment, liveHPA *KubeHPA) []*DriftItem {
+	if live == nil {
+		return []*DriftItem{newDriftItem(DriftFieldWorkload, "Deployment "+expected.Metadata.Name, DriftAbsent)}
+	}
+
+	var items []*DriftItem
+	if want, got := containerImages(expected), containerImages(live); want != got {
+		items = append(items, newDriftItem(DriftFieldImage, want, got))
+	}
+	if expectedHPA == nil && expected.Spec.Replicas != live.Spec.Replicas {
+		items = append(items, newDriftItem(DriftFieldReplicas,
+			fmt.Sprint(expected.Spec.Replicas), fmt.Sprint(live.Spec.Replicas)))
+	}
+
+	want, got := DriftAbsent, DriftAbsent
+	if expectedHPA != nil {
+		want = hpaSummary(expectedHPA)
+	}
+	if liveHPA != nil {
+		got = hpaSummary(&liveHPA.Spec)
+	}
+	if want != got {
+		items = append(items, newDriftItem(DriftFieldHPA, want, got))
+	}
+	return items
+}
+
+// containerImages 按容器名称排序的 容器=镜像 列表
+func containerImages(deployment *KubeDeployment) string {
+	images := make([]string, 0, len(deployment.Spec.Template.Spec.Containers))
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		images = append(images, container.Name+"="+container.Image)
+	}
+	sort.Strings(images)
+	return strings.Join(images, ", ")
+}
+
+// hpaSummary HPA的副本数范围与伸缩指标，伸缩行为会被Kubernetes补充默认值，不参与比较
+func hpaSummary(spec *HPASpec) string {
+	metrics, _ := json.Marshal(spec.Metrics)
+	return fmt.Sprintf("min=%d max=%d metrics=%s", spec.MinReplicas, spec.MaxReplicas, metrics)
+}
+
+func newDriftItem(field, expected, actual string) *DriftItem {
+	return &DriftItem{Field: field, Expected: truncateDrift(expected), Actual: truncateDrift(actual)}
+}
+
+// truncateDrift 截断超出长度上限的值
+func truncateDrift(value string) string {
+	if len(value) <= MaxDriftValueSize {
+		return value
+	}
+	return strings.ToValidUTF8(value[:MaxDriftValueSize-3], "") + "..."
+}
@@ -0,0 +1,320 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"devops-platform/pkg/types"
+)
+
+var (
+	// quantityPattern 指标数量，如 100、500m、1.5k、2Gi
+	quantityPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|Ki|Mi|Gi|Ti)?$`)
+	// metricNamePattern 自定义指标与外部指标名称
+	metricNamePattern = regexp.MustCompile(`^[A-Za-z_:][A-Za-z0-9_:./-]*$`)
+)
+
+// HPAMetric 伸缩指标，按Type设置resource、pods、external中对应的一项
+type HPAMetric struct {
+	Type     string          `json:"type" binding:"required,oneof=Resource Pods External"`
+	Resource *ResourceMetric `json:"resource,omitempty"`
+	Pods     *CustomMetric   `json:"pods,omitempty"`
+	External *CustomMetric   `json:"external,omitempty"`
+}
+
+// ResourceMetric 容器资源指标
+type ResourceMetric struct {
+	Name   string       `json:"name" binding:"required,oneof=cpu memory"`
+	Target MetricTarget `json:"target"`
+}
+
+// CustomMetric Pod自定义指标或外部指标
+type CustomMetric struct {
+	Name     string            `json:"name" binding:"required,max=253"`
+	Selector map[string]string `json:"selector,omitempty" binding:"max=20"`
+	Target   MetricTarget      `json:"target"`
+}
+
+// MetricTarget 指标目标，Utilization使用average_utilization，Value与AverageValue使用对应的数量
+type MetricTarget struct {
+	Type               string `json:"type" binding:"required,oneof=Utilization Value AverageValue"`
+	AverageUtilization *int32 `json:"average_utilization,omitempty" binding:"omitempty,min=1,max=1000"`
+	Value              string `json:"value,omitempty" binding:"max=40"`
+	AverageValue       string `json:"average_value,omitempty" binding:"max=40"`
+}
+
+// HPAMetrics 伸缩指标列表
+type HPAMetrics []HPAMetric
+
+func (HPAMetrics) GormDataType() string {
+	return "json"
+}
+
+// 实现 sql.Scanner 接口
+func (m *HPAMetrics) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+// 实现 driver.Valuer 接口
+func (m HPAMetrics) Value() (driver.Value, error) {
+	return valueJSON(m)
+}
+
+// HPABehavior 扩容与缩容行为，未设置的方向使用Kubernetes默认行为
+type HPABehavior struct {
+	ScaleUp   *HPAScalingRules `json:"scale_up,omitempty"`
+	ScaleDown *HPAScalingRules `json:"scale_down,omitempty"`
+}
+
+// HPAScalingRules 单个方向的伸缩规则
+type HPAScalingRules struct {
+	StabilizationWindowSeconds *int32             `json:"stabilization_window_seconds,omitempty" binding:"omitempty,min=0,max=3600"`
+	SelectPolicy               string             `json:"select_policy,omitempty" binding:"omitempty,oneof=Max Min Disabled"`
+	Policies                   []HPAScalingPolicy `json:"policies,omitempty" binding:"max=10,dive"`
+}
+
+// HPAScalingPolicy 伸缩策略：在period_seconds内最多变化value个Pod或value%
+type HPAScalingPolicy struct {
+	Type          string `json:"type" binding:"required,oneof=Pods Percent"`
+	Value         int32  `json:"value" binding:"required,min=1"`
+	PeriodSeconds int32  `json:"period_seconds" binding:"required,min=1,max=1800"`
+}
+
+func (HPABehavior) GormDataType() string {
+	return "json"
+}
+
+// 实现 sql.Scanner 接口
+func (b *HPABehavior) Scan(value interface{}) error {
+	return scanJSON(value, b)
+}
+
+// 实现 driver.Valuer 接口
+func (b HPABehavior) Value() (driver.Value, error) {
+	return valueJSON(b)
+}
+
+// DeployedHPA 部署时生效的HPA规格，用于比较发布前后的变化；MaxReplicas为0表示没有HPA
+type DeployedHPA HPASpec
+
+func (DeployedHPA) GormDataType() string {
+	return "json"
+}
+
+// 实现 sql.Scanner 接口
+func (h *DeployedHPA) Scan(value interface{}) error {
+	return scanJSON(value, (*HPASpec)(h))
+}
+
+// 实现 driver.Valuer 接口
+func (h DeployedHPA) Value() (driver.Value, error) {
+	if h.MaxReplicas == 0 {
+		return nil, nil
+	}
+	return valueJSON(HPASpec(h))
+}
+
+// Spec 返回HPA规格，没有HPA时返回nil
+func (h DeployedHPA) Spec() *HPASpec {
+	if h.MaxReplicas == 0 {
+		return nil
+	}
+	spec := HPASpec(h)
+	return &spec
+}
+
+// Validate 校验指标来源与目标类型的组合
+func (m *HPAMetric) Validate() error {
+	sources := 0
+	for _, set := range []bool{m.Resource != nil, m.Pods != nil, m.External != nil} {
+		if set {
+			sources++
+		}
+	}
+	switch {
+	case m.Type == "Resource" && m.Resource != nil && sources == 1:
+		return m.Resource.Target.validate("资源指标 "+m.Resource.Name, "Utilization", "AverageValue")
+	case m.Type == "Pods" && m.Pods != nil && sources == 1:
+		return m.Pods.validate("Pod指标", "AverageValue")
+	case m.Type == "External" && m.External != nil && sources == 1:
+		return m.External.validate("外部指标", "Value", "AverageValue")
+	}
+	return fmt.Errorf("%s类型的指标必须且只能设置 %s", m.Type, strings.ToLower(m.Type))
+}
+
+// validate 校验自定义指标名称、选择器与目标
+func (m *CustomMetric) validate(kind string, targetTypes ...string) error {
+	if !metricNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("%s名称无效: %s", kind, m.Name)
+	}
+	if err := validateLabels(kind+"选择器", m.Selector); err != nil {
+		return err
+	}
+	return m.Target.validate(kind+" "+m.Name, targetTypes...)
+}
+
+// validate 校验目标类型是否允许，以及对应的值
+func (t *MetricTarget) validate(metric string, allowed ...string) error {
+	permitted := false
+	for _, targetType := range allowed {
+		permitted = permitted || t.Type == targetType
+	}
+	if !permitted {
+		return fmt.Errorf("%s的目标类型只能是 %s", metric, strings.Join(allowed, "、"))
+	}
+	switch t.Type {
+	case "Utilization":
+		if t.AverageUtilization == nil {
+			return fmt.Errorf("%s必须设置 average_utilization", metric)
+		}
+		t.Value, t.AverageValue = "", ""
+	case "Value":
+		if !quantityPattern.MatchString(t.Value) {
+			return fmt.Errorf("%s的目标值无效: %s", metric, t.Value)
+		}
+		t.AverageUtilization, t.AverageValue = nil, ""
+	case "AverageValue":
+		if !quantityPattern.MatchString(t.AverageValue) {
+			return fmt.Errorf("%s的目标平均值无效: %s", metric, t.AverageValue)
+		}
+		t.AverageUtilization, t.Value = nil, ""
+	}
+	return nil
+}
+
+// Validate 校验伸缩规则，未设置选择策略时使用Max
+func (r *HPAScalingRules) Validate(direction string) error {
+	if r.SelectPolicy == "" {
+		r.SelectPolicy = "Max"
+	}
+	seen := make(map[string]bool, len(r.Policies))
+	for _, policy := range r.Policies {
+		key := fmt.Sprintf("%s/%d", policy.Type, policy.PeriodSeconds)
+		if seen[key] {
+			return fmt.Errorf("%s策略重复: 每%d秒的%s策略只能有一个", direction, policy.PeriodSeconds, policy.Type)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// utilizationMetric 按目标使用率伸缩的资源指标
+func utilizationMetric(name string, value int) HPAMetric {
+	target := int32(value)
+	return HPAMetric{Type: "Resource", Resource: &ResourceMetric{
+		Name:   name,
+		Target: MetricTarget{Type: "Utilization", AverageUtilization: &target},
+	}}
+}
+
+// Normalize 升级前保存的配置只有CPU、内存目标使用率，转换为等价的指标
+func (h *AppHPA) Normalize() {
+	if len(h.Metrics) > 0 {
+		return
+	}
+	if h.TargetCPU > 0 {
+		h.Metrics = append(h.Metrics, utilizationMetric("cpu", h.TargetCPU))
+	}
+	if h.TargetMemory > 0 {
+		h.Metrics = append(h.Metrics, utilizationMetric("memory", h.TargetMemory))
+	}
+}
+
+// SaveHPACommand 保存应用HPA配置命令，EnvID为0时保存应用默认配置
+type SaveHPACommand struct {
+	AppID       types.Long  `json:"-"`
+	EnvID       types.Long  `json:"-"`
+	MinReplicas int         `json:"min_replicas" binding:"required,min=1,max=1000"`
+	MaxReplicas int         `json:"max_replicas" binding:"required,min=1,max=1000"`
+	Metrics     []HPAMetric `json:"metrics" binding:"max=10,dive"`
+	Behavior    HPABehavior `json:"behavior"`
+}
+
+// Validate 校验副本数范围、指标与伸缩行为
+func (command *SaveHPACommand) Validate() error {
+	if command.MinReplicas > command.MaxReplicas {
+		return fmt.Errorf("最小副本数 %d 不能大于最大副本数 %d", command.MinReplicas, command.MaxReplicas)
+	}
+	if len(command.Metrics) == 0 {
+		return errors.New("至少需要设置一个伸缩指标")
+	}
+	resources := make(map[string]bool, 2)
+	for i := range command.Metrics {
+		metric := &command.Metrics[i]
+		if err := metric.Validate(); err != nil {
+			return err
+		}
+		if metric.Resource != nil {
+			if resources[metric.Resource.Name] {
+				return fmt.Errorf("资源指标 %s 重复", metric.Resource.Name)
+			}
+			resources[metric.Resource.Name] = true
+		}
+	}
+	if command.Behavior.ScaleUp != nil {
+		if err := command.Behavior.ScaleUp.Validate("扩容"); err != nil {
+			return err
+		}
+	}
+	if command.Behavior.ScaleDown != nil {
+		if err := command.Behavior.ScaleDown.Validate("缩容"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyTo 将命令内容写入HPA配置，并同步CPU、内存的目标使用率
+func (command *SaveHPACommand) ApplyTo(hpa *AppHPA) {
+	hpa.AppID = command.AppID
+	hpa.EnvID = command.EnvID
+	hpa.MinReplicas = command.MinReplicas
+	hpa.MaxReplicas = command.MaxReplicas
+	hpa.Metrics = command.Metrics
+	hpa.Behavior = command.Behavior
+	hpa.TargetCPU, hpa.TargetMemory = 0, 0
+	for _, metric := range command.Metrics {
+		if metric.Resource == nil || metric.Resource.Target.AverageUtilization == nil {
+			continue
+		}
+		switch metric.Resource.Name {
+		case "cpu":
+			hpa.TargetCPU = int(*metric.Resource.Target.AverageUtilization)
+		case "memory":
+			hpa.TargetMemory = int(*metric.Resource.Target.AverageUtilization)
+		}
+	}
+}
+
+// ConfigureHPACommand 配置应用默认HPA的简写方式，只设置CPU与内存的目标使用率
+type ConfigureHPACommand struct {
+	MinReplicas  int `json:"min_replicas" binding:"required,min=1,max=1000"`
+	MaxReplicas  int `json:"max_replicas" binding:"required,min=1,max=1000"`
+	TargetCPU    int `json:"target_cpu" binding:"required,min=1,max=100"`
+	TargetMemory int `json:"target_memory" binding:"min=0,max=100"`
+}
+
+// ToSaveCommand 转换为完整的保存命令，已有配置中的Pod指标、外部指标与伸缩行为保持不变
+func (command *ConfigureHPACommand) ToSaveCommand(appID types.Long, existing *AppHPA) *SaveHPACommand {
+	save := &SaveHPACommand{
+		AppID:       appID,
+		MinReplicas: command.MinReplicas,
+		MaxReplicas: command.MaxReplicas,
+		Metrics:     []HPAMetric{utilizationMetric("cpu", command.TargetCPU)},
+	}
+	if command.TargetMemory > 0 {
+		save.Metrics = append(save.Metrics, utilizationMetric("memory", command.TargetMemory))
+	}
+	if existing != nil {
+		for _, metric := range existing.Metrics {
+			if metric.Resource == nil {
+				save.Metrics = append(save.Metrics, metric)
+			}
+		}
+		save.Behavior = existing.Behavior
+	}
+	return save
+}
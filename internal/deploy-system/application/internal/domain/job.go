@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros Kubernetes CronJob支持的预定义调度
+var cronMacros = map[string]bool{
+	"@yearly": true, "@annually": true, "@monthly": true, "@weekly": true,
+	"@daily": true, "@midnight": true, "@hourly": true,
+}
+
+// cronFields 调度表达式各字段的取值范围：分、时、日、月、周
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"分钟", 0, 59}, {"小时", 0, 23}, {"日期", 1, 31}, {"月份", 1, 12}, {"星期", 0, 7},
+}
+
+// JobSettings Job部署方式的运行设置，Schedule为空时部署为一次性Job，否则部署为CronJob
+type JobSettings struct {
+	Schedule              string `json:"schedule" binding:"max=100"`
+	ConcurrencyPolicy     string `json:"concurrency_policy" binding:"omitempty,oneof=Allow Forbid Replace"`
+	BackoffLimit          *int32 `json:"backoff_limit" binding:"omitempty,min=0,max=10"`
+	ActiveDeadlineSeconds *int64 `json:"active_deadline_seconds" binding:"omitempty,min=1,max=86400"`
+}
+
+// Validate 校验调度表达式，并补充默认的并发策略与重试次数
+func (j *JobSettings) Validate() error {
+	j.Schedule = strings.Join(strings.Fields(j.Schedule), " ")
+	if j.Schedule == "" {
+		if j.ConcurrencyPolicy != "" {
+			return errors.New("一次性Job不能设置并发策略")
+		}
+	} else {
+		if err := validateSchedule(j.Schedule); err != nil {
+			return err
+		}
+		if j.ConcurrencyPolicy == "" {
+			j.ConcurrencyPolicy = JobConcurrencyForbid
+		}
+	}
+	if j.BackoffLimit == nil {
+		limit := int32(DefaultJobBackoffLimit)
+		j.BackoffLimit = &limit
+	}
+	return nil
+}
+
+// Scheduled 是否为定时任务
+func (j *JobSettings) Scheduled() bool {
+	return j != nil && j.Schedule != ""
+}
+
+// Timeout 等待一次运行完成的时间
+func (j *JobSettings) Timeout() time.Duration {
+	if j == nil || j.ActiveDeadlineSeconds == nil {
+		return DefaultJobTimeout
+	}
+	return time.Duration(*j.ActiveDeadlineSeconds)*time.Second + JobTimeoutGrace
+}
+
+// validateSchedule 校验标准的5段cron表达式，支持 * , - / 与预定义调度
+func validateSchedule(schedule string) error {
+	if strings.HasPrefix(schedule, "@") {
+		if !cronMacros[schedule] {
+			return fmt.Errorf("不支持的预定义调度: %s", schedule)
+		}
+		return nil
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("调度表达式必须包含分、时、日、月、周5段: %s", schedule)
+	}
+	for i, field := range fields {
+		for _, part := range strings.Split(field, ",") {
+			if err := validateCronPart(part, cronFields[i].min, cronFields[i].max); err != nil {
+				return fmt.Errorf("调度表达式的%s段无效: %s", cronFields[i].name, field)
+			}
+		}
+	}
+	return nil
+}
+
+// validateCronPart 校验cron字段中的一项，如 *、5、1-5、*/10、0-30/5
+func validateCronPart(part string, min, max int) error {
+	rangePart, step, hasStep := strings.Cut(part, "/")
+	if hasStep {
+		if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+			return errors.New("invalid step")
+		}
+	}
+	if rangePart == "*" {
+		return nil
+	}
+	low, high, isRange := strings.Cut(rangePart, "-")
+	if !isRange {
+		high = low
+	}
+	from, err := strconv.Atoi(low)
+	if err != nil {
+		return err
+	}
+	to, err := strconv.Atoi(high)
+	if err != nil {
+		return err
+	}
+	if from < min || to > max || from > to {
+		return errors.New("out of range")
+	}
+	return nil
+}
+
+// JobRun Job部署方式的一次运行：一次性Job每次部署运行一次，CronJob每次调度运行一次
+type JobRun struct {
+	module.Module
+	DeployID  types.Long `json:"deploy_id" gorm:"not null;uniqueIndex:uk_deploy_job,priority:1;comment:'部署记录ID'"`
+	AppID     types.Long `json:"app_id" gorm:"not null;index:idx_app_env,priority:1;comment:'应用ID'"`
+	EnvID     types.Long `json:"env_id" gorm:"not null;index:idx_app_env,priority:2;comment:'环境ID'"`
+	JobName   string     `json:"job_name" gorm:"size:63;not null;uniqueIndex:uk_deploy_job,priority:2;comment:'Job名称'"`
+	Scheduled bool       `json:"scheduled" gorm:"not null;default:false;comment:'是否由CronJob调度产生'"`
+	Status    string     `json:"status" gorm:"size:20;not null;default:'running'"`
+	ExitCode  *int32     `json:"exit_code" gorm:"comment:'容器退出码，未能获取时为空'"`
+	Message   string     `json:"message" gorm:"size:1000"`
+	Log       string     `json:"log,omitempty" gorm:"type:text;comment:'容器日志末尾'"`
+	StartTime time.Time  `json:"start_time" gorm:"not null"`
+	EndTime   *time.Time `json:"end_time"`
+}
+
+// TableName 返回Job运行记录表名
+func (JobRun) TableName() string {
+	return "app_job_run"
+}
+
+// Finish 记录运行结果，日志超过上限时保留末尾
+func (r *JobRun) Finish(status, message string, result *JobPodResult) {
+	r.Status = status
+	r.Message = message
+	if result != nil {
+		r.ExitCode = result.ExitCode
+		r.Log = result.Log
+		if len(r.Log) > MaxJobLogSize {
+			r.Log = strings.ToValidUTF8(r.Log[len(r.Log)-MaxJobLogSize:], "")
+		}
+		if r.Message == "" && result.Reason != "" {
+			r.Message = result.Reason
+		}
+	}
+	if len(r.Message) > 1000 {
+		r.Message = strings.ToValidUTF8(r.Message[:1000], "")
+	}
+	now := time.Now()
+	r.EndTime = &now
+}
+
+// JobRunQuery Job运行记录查询条件
+type JobRunQuery struct {
+	AppID types.Long `form:"-"`
+	EnvID types.Long `form:"env_id"`
+	// 只查询某次部署的运行
+	DeployID types.Long `form:"deploy_id"`
+	Status   string     `form:"status" binding:"omitempty,oneof=running success failed"`
+	Page     int        `form:"page"`
+	Size     int        `form:"size"`
+}
+
+// JobPodResult Job最后一个Pod的退出信息，容器未结束时ExitCode为空
+type JobPodResult struct {
+	PodName  string
+	ExitCode *int32
+	Reason   string
+	Log      string
+}
+
+// JobState 根据Job的状态判断运行是否结束，返回部署状态与说明
+func JobState(job *KubeJob) (string, string) {
+	if job.Status == nil {
+		return DeployStatusRunning, ""
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != "True" {
+			continue
+		}
+		switch condition.Type {
+		case "Complete":
+			return DeployStatusSuccess, ""
+		case "Failed":
+			message := condition.Reason
+			if condition.Message != "" {
+				message += ": " + condition.Message
+			}
+			return DeployStatusFailed, message
+		}
+	}
+	return DeployStatusRunning, ""
+}
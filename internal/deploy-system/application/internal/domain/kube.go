@@ -0,0 +1,634 @@
+package domain
+
+import (
+	"devops-platform/pkg/types"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// invalidNameChars 资源名称中不允许的字符
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ObjectMeta 渲染的Kubernetes资源元数据
+type ObjectMeta struct {
+	Name        string            `json:"name,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ConfigMap Kubernetes ConfigMap
+type ConfigMap struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   ObjectMeta        `json:"metadata"`
+	Data       map[string]string `json:"data"`
+}
+
+// ResourceName 由应用名生成资源名称（DNS-1123标签），suffix为空时只使用应用名
+func ResourceName(appName, suffix string) string {
+	if suffix != "" {
+		suffix = "-" + suffix
+	}
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(appName), "-"), "-")
+	if limit := MaxResourceNameLength - len(suffix); len(name) > limit {
+		name = strings.TrimRight(name[:limit], "-")
+	}
+	if name == "" {
+		name = "app"
+	}
+	return name + suffix
+}
+
+// ConfigEnvName 存放环境变量的ConfigMap名称
+func ConfigEnvName(appName string) string {
+	return ResourceName(appName, "env")
+}
+
+// ConfigFilesName 存放配置文件的ConfigMap名称
+func ConfigFilesName(appName string) string {
+	return ResourceName(appName, "files")
+}
+
+// NewConfigMaps 将配置版本渲染为ConfigMap：环境变量与配置文件各一个，内容为空时不生成
+func NewConfigMaps(appName, namespace string, revision *AppConfigRevision) []*ConfigMap {
+	newConfigMap := func(name string, data map[string]string) *ConfigMap {
+		return &ConfigMap{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Metadata: ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Labels:      map[string]string{LabelAppName: ResourceName(appName, ""), LabelManagedBy: ManagedBy},
+				Annotations: map[string]string{AnnotationConfigRevision: fmt.Sprint(revision.Revision)},
+			},
+			Data: data,
+		}
+	}
+	configMaps := make([]*ConfigMap, 0, 2)
+	if len(revision.Envs) > 0 {
+		configMaps = append(configMaps, newConfigMap(ConfigEnvName(appName), revision.Envs))
+	}
+	if len(revision.Files) > 0 {
+		configMaps = append(configMaps, newConfigMap(ConfigFilesName(appName), revision.Files))
+	}
+	return configMaps
+}
+
+// PodVolume Pod中的卷定义
+type PodVolume struct {
+	Name                  string                 `json:"name"`
+	NFS                   *NFSVolumeSource       `json:"nfs,omitempty"`
+	PersistentVolumeClaim *PVCVolumeSource       `json:"persistentVolumeClaim,omitempty"`
+	ConfigMap             *ConfigMapVolumeSource `json:"configMap,omitempty"`
+}
+
+// NFSVolumeSource NFS卷
+type NFSVolumeSource struct {
+	Server string `json:"server"`
+	Path   string `json:"path"`
+}
+
+// PVCVolumeSource PVC卷
+type PVCVolumeSource struct {
+	ClaimName string `json:"claimName"`
+}
+
+// ConfigMapVolumeSource ConfigMap卷
+type ConfigMapVolumeSource struct {
+	Name string `json:"name"`
+}
+
+// VolumeMount 容器中的卷挂载
+type VolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// NewPodVolumes 将存储卷挂载渲染为Pod卷与容器挂载，同一个卷多次挂载时只生成一个Pod卷
+func NewPodVolumes(mounts []*VolumeMountVO) ([]PodVolume, []VolumeMount) {
+	volumes := make([]PodVolume, 0, len(mounts))
+	volumeMounts := make([]VolumeMount, 0, len(mounts))
+	seen := make(map[types.Long]bool, len(mounts))
+	for _, mount := range mounts {
+		if !seen[mount.VolumeID] {
+			seen[mount.VolumeID] = true
+			volume := PodVolume{Name: mount.Volume.Name}
+			switch mount.Volume.Spec.Type {
+			case VolumeTypeNFS:
+				volume.NFS = &NFSVolumeSource{Server: mount.Volume.Spec.NfsServer, Path: mount.Volume.Spec.NfsPath}
+			case VolumeTypePVC:
+				volume.PersistentVolumeClaim = &PVCVolumeSource{ClaimName: mount.Volume.Spec.PvcName}
+			}
+			volumes = append(volumes, volume)
+		}
+		volumeMounts = append(volumeMounts, VolumeMount{
+			Name:      mount.Volume.Name,
+			MountPath: mount.MountPath,
+			SubPath:   mount.SubPath,
+			ReadOnly:  mount.ReadOnly,
+		})
+	}
+	return volumes, volumeMounts
+}
+
+// KubeDeployment Kubernetes Deployment
+type KubeDeployment struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Metadata   ObjectMeta     `json:"metadata"`
+	Spec       DeploymentSpec `json:"spec"`
+}
+
+// DeploymentSpec Deployment规格
+type DeploymentSpec struct {
+	Replicas int32           `json:"replicas"`
+	Selector LabelSelector   `json:"selector"`
+	Template PodTemplateSpec `json:"template"`
+}
+
+// LabelSelector 标签选择器
+type LabelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
+}
+
+// PodTemplateSpec Pod模板
+type PodTemplateSpec struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+}
+
+// PodSpec Pod规格
+type PodSpec struct {
+	Containers    []KubeContainer   `json:"containers"`
+	RestartPolicy string            `json:"restartPolicy,omitempty"`
+	Volumes       []PodVolume       `json:"volumes,omitempty"`
+	NodeSelector  map[string]string `json:"nodeSelector,omitempty"`
+	Tolerations   []KubeToleration  `json:"tolerations,omitempty"`
+}
+
+// KubeContainer 容器
+type KubeContainer struct {
+	Name           string              `json:"name"`
+	Image          string              `json:"image"`
+	Command        []string            `json:"command,omitempty"`
+	Args           []string            `json:"args,omitempty"`
+	Ports          []KubeContainerPort `json:"ports,omitempty"`
+	Env            []KubeEnvVar        `json:"env,omitempty"`
+	EnvFrom        []KubeEnvFrom       `json:"envFrom,omitempty"`
+	Resources      KubeResources       `json:"resources"`
+	LivenessProbe  *KubeProbe          `json:"livenessProbe,omitempty"`
+	ReadinessProbe *KubeProbe          `json:"readinessProbe,omitempty"`
+	StartupProbe   *KubeProbe          `json:"startupProbe,omitempty"`
+	VolumeMounts   []VolumeMount       `json:"volumeMounts,omitempty"`
+}
+
+// KubeContainerPort 容器端口
+type KubeContainerPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+// KubeEnvVar 从Secret键注入的环境变量
+type KubeEnvVar struct {
+	Name      string          `json:"name"`
+	ValueFrom KubeEnvVarValue `json:"valueFrom"`
+}
+
+// KubeEnvVarValue 环境变量来源
+type KubeEnvVarValue struct {
+	SecretKeyRef KubeKeySelector `json:"secretKeyRef"`
+}
+
+// KubeKeySelector Secret键引用
+type KubeKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// KubeEnvFrom 从ConfigMap注入全部环境变量
+type KubeEnvFrom struct {
+	ConfigMapRef ConfigMapVolumeSource `json:"configMapRef"`
+}
+
+// KubeResources 容器资源，数量为空的项不输出
+type KubeResources struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// KubeProbe 健康检查
+type KubeProbe struct {
+	HTTPGet             *KubeHTTPGet   `json:"httpGet,omitempty"`
+	TCPSocket           *KubeTCPSocket `json:"tcpSocket,omitempty"`
+	Exec                *ExecAction    `json:"exec,omitempty"`
+	InitialDelaySeconds int32          `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int32          `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      int32          `json:"timeoutSeconds,omitempty"`
+	SuccessThreshold    int32          `json:"successThreshold,omitempty"`
+	FailureThreshold    int32          `json:"failureThreshold,omitempty"`
+}
+
+// KubeHTTPGet HTTP健康检查
+type KubeHTTPGet struct {
+	Path   string `json:"path,omitempty"`
+	Port   int    `json:"port"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// KubeTCPSocket TCP健康检查
+type KubeTCPSocket struct {
+	Port int `json:"port"`
+}
+
+// KubeToleration 污点容忍
+type KubeToleration struct {
+	Key               string `json:"key,omitempty"`
+	Operator          string `json:"operator,omitempty"`
+	Value             string `json:"value,omitempty"`
+	Effect            string `json:"effect,omitempty"`
+	TolerationSeconds *int64 `json:"tolerationSeconds,omitempty"`
+}
+
+// WorkloadManifest 渲染工作负载所需的内容，Config为nil表示没有应用配置
+type WorkloadManifest struct {
+	AppName   string
+	Namespace string
+	Version   string
+	// 镜像digest，为空时只按标签引用镜像
+	Digest       string
+	SpecRevision int
+	Spec         WorkloadSpec
+	Config       *AppConfigRevision
+	Mounts       []*VolumeMountVO
+	// 部署记录ID，用于Job名称与标签，渲染预览时为0
+	DeployID types.Long
+}
+
+// Image 容器镜像，指定digest时以 镜像:标签@digest 引用
+func (m *WorkloadManifest) Image() string {
+	image := m.Spec.Image + ":" + m.Version
+	if m.Digest != "" {
+		image += "@" + m.Digest
+	}
+	return image
+}
+
+// NewDeployment 将工作负载定义、应用配置与共享存储卷渲染为Deployment
+func NewDeployment(manifest *WorkloadManifest) *KubeDeployment {
+	name := ResourceName(manifest.AppName, "")
+	template := newPodTemplate(manifest)
+	replicas := int32(DefaultReplicas)
+	if manifest.Spec.Replicas != nil {
+		replicas = *manifest.Spec.Replicas
+	}
+	return &KubeDeployment{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   ObjectMeta{Name: name, Namespace: manifest.Namespace, Labels: template.Metadata.Labels, Annotations: template.Metadata.Annotations},
+		Spec: DeploymentSpec{
+			Replicas: replicas,
+			Selector: LabelSelector{MatchLabels: map[string]string{LabelAppName: name}},
+			Template: template,
+		},
+	}
+}
+
+// newPodTemplate 渲染Deployment与Job共用的Pod模板
+func newPodTemplate(manifest *WorkloadManifest) PodTemplateSpec {
+	name := ResourceName(manifest.AppName, "")
+	labels := map[string]string{LabelAppName: name, LabelManagedBy: ManagedBy}
+	spec := manifest.Spec
+
+	container := KubeContainer{
+		Name:           name,
+		Image:          manifest.Image(),
+		Command:        spec.Command,
+		Args:           spec.Args,
+		Resources:      newKubeResources(spec.Resources),
+		LivenessProbe:  newKubeProbe(spec.LivenessProbe),
+		ReadinessProbe: newKubeProbe(spec.ReadinessProbe),
+		StartupProbe:   newKubeProbe(spec.StartupProbe),
+	}
+	for _, port := range spec.Ports {
+		container.Ports = append(container.Ports, KubeContainerPort{Name: port.Name, ContainerPort: port.ContainerPort, Protocol: port.Protocol})
+	}
+
+	annotations := map[string]string{AnnotationWorkloadRevision: fmt.Sprint(manifest.SpecRevision)}
+	var volumes []PodVolume
+	if config := manifest.Config; config != nil {
+		annotations[AnnotationConfigRevision] = fmt.Sprint(config.Revision)
+		if len(config.Envs) > 0 {
+			container.EnvFrom = append(container.EnvFrom, KubeEnvFrom{ConfigMapRef: ConfigMapVolumeSource{Name: ConfigEnvName(manifest.AppName)}})
+		}
+		for _, ref := range config.SecretRefs {
+			container.Env = append(container.Env, KubeEnvVar{
+				Name:      ref.Name,
+				ValueFrom: KubeEnvVarValue{SecretKeyRef: KubeKeySelector{Name: ref.Secret, Key: ref.Key}},
+			})
+		}
+		if len(config.Files) > 0 {
+			volumes = append(volumes, PodVolume{Name: ConfigFilesVolume, ConfigMap: &ConfigMapVolumeSource{Name: ConfigFilesName(manifest.AppName)}})
+			container.VolumeMounts = append(container.VolumeMounts, VolumeMount{Name: ConfigFilesVolume, MountPath: config.MountPath, ReadOnly: true})
+		}
+	}
+	sharedVolumes, volumeMounts := NewPodVolumes(manifest.Mounts)
+	volumes = append(volumes, sharedVolumes...)
+	container.VolumeMounts = append(container.VolumeMounts, volumeMounts...)
+
+	podSpec := PodSpec{
+		Containers:   []KubeContainer{container},
+		Volumes:      volumes,
+		NodeSelector: spec.NodeSelector,
+	}
+	for _, toleration := range spec.Tolerations {
+		podSpec.Tolerations = append(podSpec.Tolerations, KubeToleration(toleration))
+	}
+	return PodTemplateSpec{
+		Metadata: ObjectMeta{Labels: labels, Annotations: annotations},
+		Spec:     podSpec,
+	}
+}
+
+// KubeJob Kubernetes Job，Status只在从集群读取时存在
+type KubeJob struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Metadata   ObjectMeta     `json:"metadata"`
+	Spec       JobSpec        `json:"spec"`
+	Status     *KubeJobStatus `json:"status,omitempty"`
+}
+
+// JobSpec Job规格
+type JobSpec struct {
+	BackoffLimit          *int32          `json:"backoffLimit,omitempty"`
+	ActiveDeadlineSeconds *int64          `json:"activeDeadlineSeconds,omitempty"`
+	Template              PodTemplateSpec `json:"template"`
+}
+
+// KubeJobStatus Job状态
+type KubeJobStatus struct {
+	Active         int32              `json:"active,omitempty"`
+	Succeeded      int32              `json:"succeeded,omitempty"`
+	Failed         int32              `json:"failed,omitempty"`
+	StartTime      *time.Time         `json:"startTime,omitempty"`
+	CompletionTime *time.Time         `json:"completionTime,omitempty"`
+	Conditions     []KubeJobCondition `json:"conditions,omitempty"`
+}
+
+// KubeJobCondition Job状态条件，Complete或Failed为True时表示运行结束
+type KubeJobCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// KubeCronJob Kubernetes CronJob
+type KubeCronJob struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   ObjectMeta  `json:"metadata"`
+	Spec       CronJobSpec `json:"spec"`
+}
+
+// CronJobSpec CronJob规格
+type CronJobSpec struct {
+	Schedule          string          `json:"schedule"`
+	ConcurrencyPolicy string          `json:"concurrencyPolicy,omitempty"`
+	JobTemplate       JobTemplateSpec `json:"jobTemplate"`
+}
+
+// JobTemplateSpec CronJob中的Job模板
+type JobTemplateSpec struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     JobSpec    `json:"spec"`
+}
+
+// JobName 一次性Job的名称，由应用名与部署记录ID组成
+func JobName(appName string, deployID types.Long) string {
+	return ResourceName(appName, deployID.String())
+}
+
+// NewJob 将Job部署方式的工作负载定义渲染为一次性Job，Pod失败后不在原地重启
+func NewJob(manifest *WorkloadManifest) *KubeJob {
+	metadata, spec := newJobTemplate(manifest)
+	metadata.Name = JobName(manifest.AppName, manifest.DeployID)
+	metadata.Namespace = manifest.Namespace
+	return &KubeJob{APIVersion: "batch/v1", Kind: "Job", Metadata: metadata, Spec: spec}
+}
+
+// NewCronJob 将Job部署方式的工作负载定义渲染为CronJob，产生的Job带有部署记录与CronJob标签
+func NewCronJob(manifest *WorkloadManifest) *KubeCronJob {
+	name := ResourceName(manifest.AppName, "")
+	metadata, spec := newJobTemplate(manifest)
+	metadata.Labels[LabelCronJob] = name
+
+	cronJob := &KubeCronJob{
+		APIVersion: "batch/v1",
+		Kind:       "CronJob",
+		Metadata:   ObjectMeta{Name: name, Namespace: manifest.Namespace, Labels: map[string]string{LabelAppName: name, LabelManagedBy: ManagedBy}, Annotations: metadata.Annotations},
+		Spec:       CronJobSpec{JobTemplate: JobTemplateSpec{Metadata: metadata, Spec: spec}},
+	}
+	if job := manifest.Spec.Job; job != nil {
+		cronJob.Spec.Schedule = job.Schedule
+		cronJob.Spec.ConcurrencyPolicy = job.ConcurrencyPolicy
+	}
+	return cronJob
+}
+
+// newJobTemplate 渲染Job的元数据与规格，Job与Pod都带有部署记录标签
+func newJobTemplate(manifest *WorkloadManifest) (ObjectMeta, JobSpec) {
+	template := newPodTemplate(manifest)
+	template.Spec.RestartPolicy = "Never"
+	if manifest.DeployID > 0 {
+		template.Metadata.Labels[LabelDeployment] = manifest.DeployID.String()
+	}
+
+	labels := make(map[string]string, len(template.Metadata.Labels)+1)
+	for key, value := range template.Metadata.Labels {
+		labels[key] = value
+	}
+	spec := JobSpec{Template: template}
+	if job := manifest.Spec.Job; job != nil {
+		spec.BackoffLimit = job.BackoffLimit
+		spec.ActiveDeadlineSeconds = job.ActiveDeadlineSeconds
+	}
+	return ObjectMeta{Labels: labels, Annotations: template.Metadata.Annotations}, spec
+}
+
+// KubeHPA Kubernetes autoscaling/v2 HorizontalPodAutoscaler
+type KubeHPA struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       HPASpec    `json:"spec"`
+}
+
+// HPASpec HPA规格
+type HPASpec struct {
+	ScaleTargetRef ScaleTargetRef   `json:"scaleTargetRef"`
+	MinReplicas    int32            `json:"minReplicas"`
+	MaxReplicas    int32            `json:"maxReplicas"`
+	Metrics        []KubeMetricSpec `json:"metrics"`
+	Behavior       *KubeHPABehavior `json:"behavior,omitempty"`
+}
+
+// ScaleTargetRef 伸缩的目标工作负载
+type ScaleTargetRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// KubeMetricSpec 伸缩指标
+type KubeMetricSpec struct {
+	Type     string              `json:"type"`
+	Resource *KubeResourceMetric `json:"resource,omitempty"`
+	Pods     *KubeCustomMetric   `json:"pods,omitempty"`
+	External *KubeCustomMetric   `json:"external,omitempty"`
+}
+
+// KubeResourceMetric 资源指标
+type KubeResourceMetric struct {
+	Name   string           `json:"name"`
+	Target KubeMetricTarget `json:"target"`
+}
+
+// KubeCustomMetric Pod指标或外部指标
+type KubeCustomMetric struct {
+	Metric KubeMetricIdentifier `json:"metric"`
+	Target KubeMetricTarget     `json:"target"`
+}
+
+// KubeMetricIdentifier 指标名称与选择器
+type KubeMetricIdentifier struct {
+	Name     string         `json:"name"`
+	Selector *LabelSelector `json:"selector,omitempty"`
+}
+
+// KubeMetricTarget 指标目标
+type KubeMetricTarget struct {
+	Type               string `json:"type"`
+	AverageUtilization *int32 `json:"averageUtilization,omitempty"`
+	Value              string `json:"value,omitempty"`
+	AverageValue       string `json:"averageValue,omitempty"`
+}
+
+// KubeHPABehavior 扩缩容行为
+type KubeHPABehavior struct {
+	ScaleUp   *KubeScalingRules `json:"scaleUp,omitempty"`
+	ScaleDown *KubeScalingRules `json:"scaleDown,omitempty"`
+}
+
+// KubeScalingRules 伸缩规则
+type KubeScalingRules struct {
+	StabilizationWindowSeconds *int32              `json:"stabilizationWindowSeconds,omitempty"`
+	SelectPolicy               string              `json:"selectPolicy,omitempty"`
+	Policies                   []KubeScalingPolicy `json:"policies,omitempty"`
+}
+
+// KubeScalingPolicy 伸缩策略
+type KubeScalingPolicy struct {
+	Type          string `json:"type"`
+	Value         int32  `json:"value"`
+	PeriodSeconds int32  `json:"periodSeconds"`
+}
+
+// NewHPA 将HPA配置渲染为伸缩应用Deployment的HorizontalPodAutoscaler
+func NewHPA(appName, namespace string, hpa *AppHPA) *KubeHPA {
+	name := ResourceName(appName, "")
+	spec := HPASpec{
+		ScaleTargetRef: ScaleTargetRef{APIVersion: "apps/v1", Kind: "Deployment", Name: name},
+		MinReplicas:    int32(hpa.MinReplicas),
+		MaxReplicas:    int32(hpa.MaxReplicas),
+	}
+	target := func(t MetricTarget) KubeMetricTarget {
+		return KubeMetricTarget{Type: t.Type, AverageUtilization: t.AverageUtilization, Value: t.Value, AverageValue: t.AverageValue}
+	}
+	custom := func(m *CustomMetric) *KubeCustomMetric {
+		metric := &KubeCustomMetric{Metric: KubeMetricIdentifier{Name: m.Name}, Target: target(m.Target)}
+		if len(m.Selector) > 0 {
+			metric.Metric.Selector = &LabelSelector{MatchLabels: m.Selector}
+		}
+		return metric
+	}
+	for _, metric := range hpa.Metrics {
+		kubeMetric := KubeMetricSpec{Type: metric.Type}
+		switch {
+		case metric.Resource != nil:
+			kubeMetric.Resource = &KubeResourceMetric{Name: metric.Resource.Name, Target: target(metric.Resource.Target)}
+		case metric.Pods != nil:
+			kubeMetric.Pods = custom(metric.Pods)
+		case metric.External != nil:
+			kubeMetric.External = custom(metric.External)
+		}
+		spec.Metrics = append(spec.Metrics, kubeMetric)
+	}
+
+	rules := func(r *HPAScalingRules) *KubeScalingRules {
+		if r == nil {
+			return nil
+		}
+		kubeRules := &KubeScalingRules{StabilizationWindowSeconds: r.StabilizationWindowSeconds, SelectPolicy: r.SelectPolicy}
+		for _, policy := range r.Policies {
+			kubeRules.Policies = append(kubeRules.Policies, KubeScalingPolicy(policy))
+		}
+		return kubeRules
+	}
+	if hpa.Behavior.ScaleUp != nil || hpa.Behavior.ScaleDown != nil {
+		spec.Behavior = &KubeHPABehavior{ScaleUp: rules(hpa.Behavior.ScaleUp), ScaleDown: rules(hpa.Behavior.ScaleDown)}
+	}
+
+	return &KubeHPA{
+		APIVersion: "autoscaling/v2",
+		Kind:       "HorizontalPodAutoscaler",
+		Metadata:   ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{LabelAppName: name, LabelManagedBy: ManagedBy}},
+		Spec:       spec,
+	}
+}
+
+// newKubeResources 输出已设置的资源数量
+func newKubeResources(resources ResourceRequirements) KubeResources {
+	toMap := func(list ResourceList) map[string]string {
+		values := make(map[string]string, 2)
+		if list.CPU != "" {
+			values["cpu"] = list.CPU
+		}
+		if list.Memory != "" {
+			values["memory"] = list.Memory
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		return values
+	}
+	return KubeResources{Requests: toMap(resources.Requests), Limits: toMap(resources.Limits)}
+}
+
+// newKubeProbe 转换健康检查，未配置时返回nil
+func newKubeProbe(probe *Probe) *KubeProbe {
+	if probe == nil {
+		return nil
+	}
+	kubeProbe := &KubeProbe{
+		Exec:                probe.Exec,
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		SuccessThreshold:    probe.SuccessThreshold,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+	if probe.HTTPGet != nil {
+		kubeProbe.HTTPGet = &KubeHTTPGet{Path: probe.HTTPGet.Path, Port: probe.HTTPGet.Port, Scheme: probe.HTTPGet.Scheme}
+	}
+	if probe.TCPSocket != nil {
+		kubeProbe.TCPSocket = &KubeTCPSocket{Port: probe.TCPSocket.Port}
+	}
+	return kubeProbe
+}
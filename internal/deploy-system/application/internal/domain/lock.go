@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"fmt"
+	"time"
+)
+
+// 部署锁类型
+const (
+	DeployLockLease  = "lease"  // 部署租约：部署执行期间持有，结束时释放，过期后可被接管
+	DeployLockFreeze = "freeze" // 冻结：管理员手工加锁，解冻前禁止发布
+)
+
+// DeployLock 应用在环境下的部署锁，每个应用环境每种类型最多一条
+type DeployLock struct {
+	module.Module
+	AppID types.Long `json:"app_id" gorm:"not null;uniqueIndex:uk_lock_app_env_kind,priority:1"`
+	EnvID types.Long `json:"env_id" gorm:"not null;uniqueIndex:uk_lock_app_env_kind,priority:2"`
+	Kind  string     `json:"kind" gorm:"size:20;not null;uniqueIndex:uk_lock_app_env_kind,priority:3;comment:'锁类型：lease、freeze'"`
+	// 持有租约的部署记录，创建部署记录前为0
+	DeploymentID types.Long `json:"deployment_id" gorm:"default:0"`
+	Reason       string     `json:"reason" gorm:"size:500"`
+	// 过期时间，冻结为空表示直到手工解冻
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// TableName 返回部署锁表名
+func (DeployLock) TableName() string {
+	return "deploy_lock"
+}
+
+// Expired 锁是否已过期
+func (l *DeployLock) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !l.ExpiresAt.After(now)
+}
+
+// Holder 租约持有者的说明：已登记到部署记录时为部署记录，否则为获取租约的用户与时间
+func (l *DeployLock) Holder() string {
+	if l.DeploymentID > 0 {
+		return "部署记录 " + l.DeploymentID.String()
+	}
+	holder := l.LastModifiedBy.Name
+	if holder == "" {
+		holder = "系统"
+	}
+	return fmt.Sprintf("部署锁由%s于%s获取", holder, l.LastModifiedAt.Format(types.TimeFormat))
+}
+
+// FreezeCommand 冻结应用环境命令
+type FreezeCommand struct {
+	AppID  types.Long `json:"-"`
+	EnvID  types.Long `json:"-"`
+	Reason string     `json:"reason" binding:"required,max=500"`
+	// 冻结时长，0表示直到手工解冻
+	DurationMinutes int `json:"duration_minutes" binding:"min=0,max=43200"`
+}
+
+// DeployLockStatus 应用环境的部署锁状态与排队中的发布计划
+type DeployLockStatus struct {
+	AppID  types.Long     `json:"app_id"`
+	EnvID  types.Long     `json:"env_id"`
+	Lease  *DeployLock    `json:"lease"`
+	Freeze *DeployLock    `json:"freeze"`
+	Queue  []*ReleasePlan `json:"queue"`
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+)
+
+// PendingPlanStatuses 尚未执行的发布计划状态
+var PendingPlanStatuses = []string{DeployStatusPending, DeployStatusApproved, DeployStatusQueued}
+
+// EnvMatrixQuery 环境矩阵查询条件
+type EnvMatrixQuery struct {
+	GroupID types.Long `form:"group_id"`
+	DeptID  types.Long `form:"dept_id"`
+}
+
+// EnvMatrixVO 环境矩阵：每个应用在每个环境中当前运行的版本
+type EnvMatrixVO struct {
+	Envs []*AppEnv `json:"envs"`
+	// 应用按名称排序，每个应用的 cells 与 envs 一一对应
+	Apps []*EnvMatrixRow `json:"apps"`
+}
+
+// EnvMatrixRow 一个应用在各环境中的状态
+type EnvMatrixRow struct {
+	AppID   types.Long       `json:"app_id"`
+	AppName string           `json:"app_name"`
+	DeptID  types.Long       `json:"dept_id"`
+	Cells   []*EnvMatrixCell `json:"cells"`
+}
+
+// EnvMatrixCell 应用在一个环境中最近一次成功的部署与待执行的发布计划
+type EnvMatrixCell struct {
+	EnvID types.Long `json:"env_id"`
+	// 最近一次成功的部署，为nil表示应用在该环境还没有成功的部署
+	Current *CurrentDeployment `json:"current"`
+	// 比当前部署新的待执行（待处理、已审批或排队中）发布计划数量
+	PendingPlans int64 `json:"pending_plans"`
+	// 最新的比当前部署新的待执行发布计划，没有时为nil
+	PendingPlan *ReleasePlan `json:"pending_plan"`
+}
+
+// CurrentDeployment 环境中当前运行的部署
+type CurrentDeployment struct {
+	DeployID types.Long  `json:"deploy_id"`
+	Version  string      `json:"version"`
+	Digest   string      `json:"digest"`
+	Deployer module.User `json:"deployer"`
+	// 部署开始与完成时间
+	StartTime time.Time  `json:"start_time"`
+	EndTime   *time.Time `json:"end_time"`
+	// 是否为回滚产生的部署
+	Rollback bool `json:"rollback"`
+}
+
+// PendingPlanSummary 应用在一个环境中比当前部署新的待执行发布计划：在当前部署之后创建且不是当前部署的版本，
+// 排队中的计划总会在当前部署结束后执行；当前没有部署时都是新的
+type PendingPlanSummary struct {
+	AppID types.Long
+	EnvID types.Long
+	// 计划数量与最新计划的ID
+	Plans        int64
+	LatestPlanID types.Long
+}
+
+// NewCurrentDeployment 由部署记录生成当前部署
+func NewCurrentDeployment(deployment *Deployment) *CurrentDeployment {
+	return &CurrentDeployment{
+		DeployID:  deployment.ID,
+		Version:   deployment.Version,
+		Digest:    deployment.Digest,
+		Deployer:  deployment.CreatedBy,
+		StartTime: deployment.StartTime,
+		EndTime:   deployment.EndTime,
+		Rollback:  deployment.RollbackOf > 0 || strings.HasSuffix(deployment.Version, RollbackVersionSuffix),
+	}
+}
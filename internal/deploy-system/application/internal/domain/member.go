@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+)
+
+// 应用成员角色，权限依次递减
+const (
+	AppRoleOwner      = "owner"      // 负责人：删除应用、管理负责人
+	AppRoleMaintainer = "maintainer" // 维护者：管理成员
+	AppRoleDeveloper  = "developer"  // 开发者：执行发布与回滚
+	AppRoleViewer     = "viewer"     // 观察者：只读
+)
+
+// appRoleLevels 应用成员角色的权限级别
+var appRoleLevels = map[string]int{
+	AppRoleViewer:     1,
+	AppRoleDeveloper:  2,
+	AppRoleMaintainer: 3,
+	AppRoleOwner:      4,
+}
+
+// appRoleNames 应用成员角色名称
+var appRoleNames = map[string]string{
+	AppRoleViewer:     "观察者",
+	AppRoleDeveloper:  "开发者",
+	AppRoleMaintainer: "维护者",
+	AppRoleOwner:      "负责人",
+}
+
+// AppRoleAtLeast 成员角色是否不低于要求的角色，空角色表示不是成员
+func AppRoleAtLeast(role, required string) bool {
+	return role != "" && appRoleLevels[role] >= appRoleLevels[required]
+}
+
+// AppRoleName 应用成员角色名称
+func AppRoleName(role string) string {
+	return appRoleNames[role]
+}
+
+// AppMember 应用成员，值班联系人是标记了OnCall的成员
+type AppMember struct {
+	module.Module
+	AppID  types.Long `json:"app_id" gorm:"not null;uniqueIndex:uk_member_app_user,priority:1"`
+	UserID types.Long `json:"user_id" gorm:"not null;uniqueIndex:uk_member_app_user,priority:2;index:idx_member_user"`
+	Role   string     `json:"role" gorm:"size:20;not null;comment:'成员角色：owner、maintainer、developer、viewer'"`
+	OnCall bool       `json:"on_call" gorm:"not null;default:false;comment:'是否为值班联系人'"`
+}
+
+// TableName 返回应用成员表名
+func (AppMember) TableName() string {
+	return "app_member"
+}
+
+// AddMemberCommand 添加应用成员命令
+type AddMemberCommand struct {
+	AppID  types.Long `json:"-"`
+	UserID types.Long `json:"user_id" binding:"required"`
+	Role   string     `json:"role" binding:"required,oneof=owner maintainer developer viewer"`
+	OnCall bool       `json:"on_call"`
+}
+
+// UpdateMemberCommand 修改应用成员命令，OnCall为空时保持不变
+type UpdateMemberCommand struct {
+	AppID  types.Long `json:"-"`
+	UserID types.Long `json:"-"`
+	Role   string     `json:"role" binding:"required,oneof=owner maintainer developer viewer"`
+	OnCall *bool      `json:"on_call"`
+}
+
+// AppMemberVO 应用成员视图对象，附带用户的联系方式
+type AppMemberVO struct {
+	UserID   types.Long `json:"user_id"`
+	Username string     `json:"username"`
+	Name     string     `json:"name"`
+	Mobile   string     `json:"mobile"`
+	Email    string     `json:"email"`
+	DeptName string     `json:"dept_name"`
+	Role     string     `json:"role"`
+	OnCall   bool       `json:"on_call"`
+	JoinedAt types.Time `json:"joined_at"`
+}
+
+// AppOwnershipVO 应用归属：所属部门、成员与值班联系人
+type AppOwnershipVO struct {
+	AppID    types.Long     `json:"app_id"`
+	DeptID   types.Long     `json:"dept_id"`
+	DeptName string         `json:"dept_name"`
+	Members  []*AppMemberVO `json:"members"`
+	OnCall   []*AppMemberVO `json:"on_call"`
+}
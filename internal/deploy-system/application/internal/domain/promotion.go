@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// digestPattern 镜像digest，如 sha256:<64位十六进制>
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// IsImageDigest 判断是否为合法的镜像digest
+func IsImageDigest(digest string) bool {
+	return digestPattern.MatchString(digest)
+}
+
+// AppPromotionStage 应用晋级路径中的一个阶段，版本按Position从小到大依次晋级
+type AppPromotionStage struct {
+	module.Module
+	AppID    types.Long `json:"app_id" gorm:"not null;uniqueIndex:uk_stage_app_env,priority:1;uniqueIndex:uk_stage_app_position,priority:1"`
+	EnvID    types.Long `json:"env_id" gorm:"not null;uniqueIndex:uk_stage_app_env,priority:2"`
+	Position int        `json:"position" gorm:"not null;uniqueIndex:uk_stage_app_position,priority:2;comment:'阶段顺序，从1开始'"`
+	// 可选阶段可以跳过，发布到后续阶段时不要求该阶段已部署成功
+	Optional bool `json:"optional" gorm:"not null;default:false"`
+}
+
+// TableName 返回应用晋级阶段表名
+func (AppPromotionStage) TableName() string {
+	return "app_promotion_stage"
+}
+
+// PromotionStageItem 晋级路径中的阶段
+type PromotionStageItem struct {
+	EnvID    types.Long `json:"env_id" binding:"required"`
+	Optional bool       `json:"optional"`
+}
+
+// SavePromotionPathCommand 保存应用晋级路径命令，按数组顺序依次晋级
+type SavePromotionPathCommand struct {
+	AppID  types.Long           `json:"-"`
+	Stages []PromotionStageItem `json:"stages" binding:"required,min=2,max=10,dive"`
+}
+
+// Validate 校验阶段不重复，最后一个阶段不能是可选阶段
+func (command *SavePromotionPathCommand) Validate() error {
+	seen := make(map[types.Long]bool, len(command.Stages))
+	for _, stage := range command.Stages {
+		if seen[stage.EnvID] {
+			return fmt.Errorf("环境 %s 在晋级路径中重复", stage.EnvID)
+		}
+		seen[stage.EnvID] = true
+	}
+	if command.Stages[len(command.Stages)-1].Optional {
+		return errors.New("晋级路径的最后一个阶段不能是可选阶段")
+	}
+	return nil
+}
+
+// NewStages 生成晋级阶段，Position从1开始
+func (command *SavePromotionPathCommand) NewStages() []*AppPromotionStage {
+	stages := make([]*AppPromotionStage, 0, len(command.Stages))
+	for i, item := range command.Stages {
+		stages = append(stages, &AppPromotionStage{
+			AppID:    command.AppID,
+			EnvID:    item.EnvID,
+			Position: i + 1,
+			Optional: item.Optional,
+		})
+	}
+	return stages
+}
+
+// PromotionStageVO 晋级阶段视图对象，附带该阶段最近一次成功的部署
+type PromotionStageVO struct {
+	Position       int         `json:"position"`
+	EnvID          types.Long  `json:"env_id"`
+	EnvName        string      `json:"env_name"`
+	Optional       bool        `json:"optional"`
+	LastDeployment *Deployment `json:"last_deployment"`
+}
+
+// PromoteCommand 晋级命令：将阶段N中部署成功的版本发布到阶段N+1
+type PromoteCommand struct {
+	AppID     types.Long `json:"-"`
+	FromEnvID types.Long `json:"from_env_id" binding:"required"`
+	// 来源部署记录，为空时使用来源环境最近一次成功的部署
+	DeploymentID types.Long `json:"deployment_id"`
+	Strategy     string     `json:"strategy" binding:"max=50"`
+}
+
+// PromotionResult 晋级结果
+type PromotionResult struct {
+	PlanID       types.Long `json:"plan_id"`
+	FromEnvID    types.Long `json:"from_env_id"`
+	ToEnvID      types.Long `json:"to_env_id"`
+	DeploymentID types.Long `json:"deployment_id"`
+	Version      string     `json:"version"`
+	Digest       string     `json:"digest"`
+	// 来源部署使用的配置版本，执行发布计划时复制到目标环境
+	ConfigRevisionID types.Long `json:"config_revision_id"`
+}
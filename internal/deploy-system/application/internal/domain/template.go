@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/pkg/types"
+)
+
+// AppTemplate 应用模板：按技术栈预置的工作负载定义、HPA、健康检查、环境变量与构建配置
+type AppTemplate struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	BuildMethod enum.BuildMethod `json:"build_method"`
+	// 工作负载定义，镜像仓库在创建时按应用名生成
+	Spec WorkloadSpec `json:"spec"`
+	// 应用默认HPA配置，为nil表示不配置HPA
+	HPA *TemplateHPA `json:"hpa"`
+	// 写入所选环境配置的环境变量
+	Envs  types.Envs    `json:"envs"`
+	Build TemplateBuild `json:"build"`
+}
+
+// TemplateHPA 模板的HPA配置
+type TemplateHPA struct {
+	MinReplicas int         `json:"min_replicas"`
+	MaxReplicas int         `json:"max_replicas"`
+	Metrics     []HPAMetric `json:"metrics"`
+}
+
+// TemplateBuild 模板的构建配置，代码仓库与镜像仓库在创建应用时指定
+type TemplateBuild struct {
+	BuildType      enum.BuildType `json:"build_type"`
+	Branch         string         `json:"branch"`
+	DockerfilePath string         `json:"dockerfile_path"`
+	ContextDir     string         `json:"context_dir"`
+}
+
+// TemplateBuildConfig 按模板创建应用时保存的构建配置，由构建模块校验并保存
+type TemplateBuildConfig struct {
+	AppID           types.Long
+	RepoURL         string
+	BuildMethod     enum.BuildMethod
+	RegistryID      types.Long
+	ImageRepository string
+	TemplateBuild
+}
+
+// AppTemplates 内置的应用模板
+var AppTemplates = []*AppTemplate{
+	{
+		Name:        "java-springboot-service",
+		Title:       "Java Spring Boot 服务",
+		Description: "Maven构建的Spring Boot服务，使用Actuator健康检查，按CPU使用率伸缩",
+		BuildMethod: enum.BuildMethodJava,
+		Spec: WorkloadSpec{
+			Ports:          []ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: "TCP"}},
+			Resources:      resources("500m", "1Gi", "2", "2Gi"),
+			LivenessProbe:  httpProbe("/actuator/health/liveness", 8080, 60),
+			ReadinessProbe: httpProbe("/actuator/health/readiness", 8080, 30),
+			Replicas:       replicas(2),
+		},
+		HPA:  &TemplateHPA{MinReplicas: 2, MaxReplicas: 10, Metrics: []HPAMetric{utilizationMetric("cpu", 70)}},
+		Envs: types.Envs{"JAVA_OPTS": "-XX:MaxRAMPercentage=75.0", "TZ": "Asia/Shanghai"},
+		Build: TemplateBuild{
+			BuildType:      enum.BuildTypeCustom,
+			Branch:         "master",
+			DockerfilePath: "Dockerfile",
+		},
+	},
+	{
+		Name:        "golang-api",
+		Title:       "Go API 服务",
+		Description: "Go编写的HTTP API服务，使用 /healthz 健康检查，按CPU使用率伸缩",
+		BuildMethod: enum.BuildMethodGolang,
+		Spec: WorkloadSpec{
+			Ports:          []ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: "TCP"}},
+			Resources:      resources("100m", "128Mi", "1", "512Mi"),
+			LivenessProbe:  httpProbe("/healthz", 8080, 10),
+			ReadinessProbe: httpProbe("/healthz", 8080, 5),
+			Replicas:       replicas(2),
+		},
+		HPA:  &TemplateHPA{MinReplicas: 2, MaxReplicas: 10, Metrics: []HPAMetric{utilizationMetric("cpu", 70)}},
+		Envs: types.Envs{"TZ": "Asia/Shanghai"},
+		Build: TemplateBuild{
+			BuildType:      enum.BuildTypeCustom,
+			Branch:         "master",
+			DockerfilePath: "Dockerfile",
+		},
+	},
+	{
+		Name:        "static-frontend",
+		Title:       "静态前端",
+		Description: "Npm构建、Nginx托管的静态页面，使用TCP健康检查",
+		BuildMethod: enum.BuildMethodNpm,
+		Spec: WorkloadSpec{
+			Ports:          []ContainerPort{{Name: "http", ContainerPort: 80, Protocol: "TCP"}},
+			Resources:      resources("50m", "64Mi", "500m", "256Mi"),
+			LivenessProbe:  &Probe{TCPSocket: &TCPSocketAction{Port: 80}, InitialDelaySeconds: 5},
+			ReadinessProbe: httpProbe("/", 80, 3),
+			Replicas:       replicas(2),
+		},
+		HPA: &TemplateHPA{MinReplicas: 2, MaxReplicas: 5, Metrics: []HPAMetric{utilizationMetric("cpu", 80)}},
+		Build: TemplateBuild{
+			BuildType:      enum.BuildTypeCustom,
+			Branch:         "master",
+			DockerfilePath: "Dockerfile",
+		},
+	},
+}
+
+// GetAppTemplate 按名称获取应用模板的副本，创建应用时修改副本不影响内置模板，不存在时返回nil
+func GetAppTemplate(name string) *AppTemplate {
+	for _, template := range AppTemplates {
+		if template.Name != name {
+			continue
+		}
+		data, err := json.Marshal(template)
+		if err != nil {
+			return nil
+		}
+		var copied AppTemplate
+		if err = json.Unmarshal(data, &copied); err != nil {
+			return nil
+		}
+		return &copied
+	}
+	return nil
+}
+
+func resources(requestCPU, requestMemory, limitCPU, limitMemory string) ResourceRequirements {
+	return ResourceRequirements{
+		Requests: ResourceList{CPU: requestCPU, Memory: requestMemory},
+		Limits:   ResourceList{CPU: limitCPU, Memory: limitMemory},
+	}
+}
+
+func httpProbe(path string, port int, initialDelay int32) *Probe {
+	return &Probe{HTTPGet: &HTTPGetAction{Path: path, Port: port}, InitialDelaySeconds: initialDelay}
+}
+
+func replicas(n int32) *int32 {
+	return &n
+}
+
+// CreateFromTemplateCommand 按模板创建应用命令
+type CreateFromTemplateCommand struct {
+	Template    string     `json:"template" binding:"required"`
+	Name        string     `json:"name" binding:"required,max=100"`
+	Description string     `json:"description" binding:"max=500"`
+	DeptID      types.Long `json:"dept_id"`
+	// 应用加入的分组
+	GroupIDs []types.Long `json:"group_ids" binding:"max=20"`
+	// 写入模板环境变量的环境，为空时不创建配置
+	EnvIDs []types.Long `json:"env_ids" binding:"max=20"`
+	// 关联的镜像仓库，镜像为 仓库地址/镜像名，并用于构建配置
+	RegistryID types.Long `json:"registry_id" binding:"required"`
+	// 镜像名，默认为应用名的小写形式
+	ImageRepository string `json:"image_repository" binding:"max=255"`
+	// 代码仓库地址，为空时不创建构建配置
+	RepoURL string `json:"repo_url" binding:"max=500"`
+	// 构建分支，为空时使用模板的默认分支
+	Branch string `json:"branch" binding:"max=255"`
+}
+
+// Validate 校验模板存在并补全默认镜像名，工作负载的镜像地址由镜像仓库生成，必须指定镜像仓库
+func (command *CreateFromTemplateCommand) Validate() (*AppTemplate, error) {
+	command.Name = strings.TrimSpace(command.Name)
+	template := GetAppTemplate(command.Template)
+	if template == nil {
+		return nil, fmt.Errorf("应用模板不存在: %s", command.Template)
+	}
+	if command.RegistryID == 0 {
+		return nil, errors.New("必须指定镜像仓库，用于生成工作负载的镜像地址")
+	}
+	command.ImageRepository = strings.Trim(strings.TrimSpace(command.ImageRepository), "/")
+	if command.ImageRepository == "" {
+		command.ImageRepository = strings.ToLower(command.Name)
+	}
+	return template, nil
+}
+
+// TemplateImage 镜像仓库地址去掉协议头后拼接镜像名
+func TemplateImage(registryURL, repository string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(registryURL, "https://"), "http://")
+	host = strings.TrimRight(host, "/")
+	if host == "" {
+		return repository
+	}
+	return host + "/" + repository
+}
+
+// TemplateAppVO 按模板创建的应用及各项配置
+type TemplateAppVO struct {
+	AppID    types.Long `json:"app_id"`
+	Template string     `json:"template"`
+	// 工作负载定义版本
+	SpecRevision int `json:"spec_revision"`
+	// 应用默认HPA配置ID，模板没有HPA时为0
+	HPAID types.Long `json:"hpa_id"`
+	// 各环境的配置版本ID
+	ConfigRevisionIDs []types.Long `json:"config_revision_ids"`
+	RegistryID        types.Long   `json:"registry_id"`
+	// 构建配置ID，没有指定代码仓库时为0
+	BuildConfigID types.Long   `json:"build_config_id"`
+	GroupIDs      []types.Long `json:"group_ids"`
+}
@@ -0,0 +1,230 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 发布列车状态
+const (
+	TrainStatusPending    = "pending"     // 待执行
+	TrainStatusRunning    = "running"     // 执行中
+	TrainStatusSuccess    = "success"     // 全部成员部署成功
+	TrainStatusFailed     = "failed"      // 有成员部署失败
+	TrainStatusAborted    = "aborted"     // 已中止
+	TrainStatusRolledBack = "rolled_back" // 失败后已回滚部署成功的成员
+)
+
+// 发布列车成员状态
+const (
+	TrainMemberWaiting    = "waiting"     // 等待依赖完成
+	TrainMemberRunning    = "running"     // 部署中
+	TrainMemberSuccess    = "success"     // 部署成功
+	TrainMemberFailed     = "failed"      // 部署失败或无法启动
+	TrainMemberSkipped    = "skipped"     // 依赖未成功或列车已停止，不再部署
+	TrainMemberRolledBack = "rolled_back" // 部署成功后被列车回滚
+	// 需要回滚但没有更早的成功部署或回滚失败，成员部署的版本仍在运行
+	TrainMemberRollbackFailed = "rollback_failed"
+)
+
+// 发布列车操作
+const (
+	TrainActionExecute = "execute"
+	TrainActionAbort   = "abort"
+)
+
+// ReleaseTrain 发布列车：按依赖关系依次执行多个应用的发布计划
+type ReleaseTrain struct {
+	module.Module
+	Name        string `json:"name" gorm:"size:100;not null"`
+	Description string `json:"description" gorm:"size:500"`
+	Status      string `json:"status" gorm:"size:20;not null;default:'pending';index:idx_train_status"`
+	// 有成员失败时不再启动新的成员；关闭时只跳过依赖失败成员的成员
+	StopOnFailure bool `json:"stop_on_failure" gorm:"not null"`
+	// 列车失败时按执行顺序倒序回滚已部署成功的成员
+	RollbackOnFailure bool `json:"rollback_on_failure" gorm:"not null"`
+	// 中止时是否回滚已部署成功的成员，由中止操作指定
+	RollbackOnAbort bool                  `json:"rollback_on_abort" gorm:"not null;default:false"`
+	StartedAt       *time.Time            `json:"started_at"`
+	FinishedAt      *time.Time            `json:"finished_at"`
+	Members         []*ReleaseTrainMember `json:"members,omitempty" gorm:"-"`
+}
+
+// TableName 返回发布列车表名
+func (ReleaseTrain) TableName() string {
+	return "release_train"
+}
+
+// Finished 列车是否已结束，中止的列车在运行中的成员结束并完成回滚后才结束
+func (t *ReleaseTrain) Finished() bool {
+	return t.FinishedAt != nil
+}
+
+// ReleaseTrainMember 发布列车成员，一个发布计划只能属于一个发布列车
+type ReleaseTrainMember struct {
+	module.Module
+	TrainID types.Long `json:"train_id" gorm:"not null;index:idx_train_member_train"`
+	PlanID  types.Long `json:"plan_id" gorm:"not null;uniqueIndex:uk_train_member_plan"`
+	AppID   types.Long `json:"app_id" gorm:"not null"`
+	EnvID   types.Long `json:"env_id" gorm:"not null"`
+	Version string     `json:"version" gorm:"size:50;not null"`
+	// 执行顺序，依赖的成员总是排在前面
+	Position int `json:"position" gorm:"not null"`
+	// 依赖的成员的发布计划ID
+	DependsOn    TrainDependencies `json:"depends_on"`
+	Status       string            `json:"status" gorm:"size:20;not null;default:'waiting'"`
+	DeploymentID types.Long        `json:"deployment_id" gorm:"default:0"`
+	Message      string            `json:"message" gorm:"size:500"`
+}
+
+// TableName 返回发布列车成员表名
+func (ReleaseTrainMember) TableName() string {
+	return "release_train_member"
+}
+
+// TrainDependencies 依赖的发布计划ID列表
+type TrainDependencies []types.Long
+
+func (TrainDependencies) GormDataType() string {
+	return "json"
+}
+
+// 实现 sql.Scanner 接口
+func (d *TrainDependencies) Scan(value interface{}) error {
+	return scanJSON(value, d)
+}
+
+// 实现 driver.Valuer 接口
+func (d TrainDependencies) Value() (driver.Value, error) {
+	if d == nil {
+		d = TrainDependencies{}
+	}
+	return valueJSON(d)
+}
+
+// TrainMemberItem 发布列车成员定义
+type TrainMemberItem struct {
+	PlanID    types.Long   `json:"plan_id" binding:"required"`
+	DependsOn []types.Long `json:"depends_on"`
+}
+
+// CreateTrainCommand 创建发布列车命令
+type CreateTrainCommand struct {
+	Name        string            `json:"name" binding:"required,max=100"`
+	Description string            `json:"description" binding:"max=500"`
+	Members     []TrainMemberItem `json:"members" binding:"required,min=1,max=50,dive"`
+	// 为空时默认有成员失败即停止
+	StopOnFailure     *bool `json:"stop_on_failure"`
+	RollbackOnFailure bool  `json:"rollback_on_failure"`
+}
+
+// NewTrain 生成发布列车
+func (command *CreateTrainCommand) NewTrain() *ReleaseTrain {
+	train := &ReleaseTrain{
+		Name:              command.Name,
+		Description:       command.Description,
+		Status:            TrainStatusPending,
+		StopOnFailure:     true,
+		RollbackOnFailure: command.RollbackOnFailure,
+	}
+	if command.StopOnFailure != nil {
+		train.StopOnFailure = *command.StopOnFailure
+	}
+	return train
+}
+
+// ExecutionOrder 校验依赖关系并按拓扑顺序生成成员，没有依赖关系的成员保持定义顺序
+func (command *CreateTrainCommand) ExecutionOrder() ([]*ReleaseTrainMember, error) {
+	items := make(map[types.Long]TrainMemberItem, len(command.Members))
+	for _, item := range command.Members {
+		if _, ok := items[item.PlanID]; ok {
+			return nil, fmt.Errorf("发布计划 %s 在列车中重复", item.PlanID)
+		}
+		items[item.PlanID] = item
+	}
+	for _, item := range command.Members {
+		for _, dep := range item.DependsOn {
+			if dep == item.PlanID {
+				return nil, fmt.Errorf("发布计划 %s 不能依赖自身", item.PlanID)
+			}
+			if _, ok := items[dep]; !ok {
+				return nil, fmt.Errorf("发布计划 %s 依赖的发布计划 %s 不在列车中", item.PlanID, dep)
+			}
+		}
+	}
+
+	placed := make(map[types.Long]bool, len(command.Members))
+	members := make([]*ReleaseTrainMember, 0, len(command.Members))
+	for len(members) < len(command.Members) {
+		progressed := false
+		for _, item := range command.Members {
+			if placed[item.PlanID] || !allPlaced(item.DependsOn, placed) {
+				continue
+			}
+			placed[item.PlanID] = true
+			progressed = true
+			members = append(members, &ReleaseTrainMember{
+				PlanID:    item.PlanID,
+				Position:  len(members) + 1,
+				DependsOn: TrainDependencies(item.DependsOn),
+				Status:    TrainMemberWaiting,
+			})
+		}
+		if !progressed {
+			return nil, errors.New("发布列车的依赖关系存在循环")
+		}
+	}
+	return members, nil
+}
+
+// allPlaced 依赖是否都已排序
+func allPlaced(deps []types.Long, placed map[types.Long]bool) bool {
+	for _, dep := range deps {
+		if !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// TrainActionCommand 执行或中止发布列车命令
+type TrainActionCommand struct {
+	Action string `json:"action" binding:"required,oneof=execute abort"`
+	// 中止时是否回滚已部署成功的成员
+	Rollback bool `json:"rollback"`
+}
+
+// TrainQuery 发布列车查询条件
+type TrainQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending running success failed aborted rolled_back"`
+	Page   int    `form:"page"`
+	Size   int    `form:"size"`
+}
+
+// AggregateTrainStatus 根据成员状态汇总已结束的发布列车状态，有成员回滚失败时列车失败
+func AggregateTrainStatus(members []*ReleaseTrainMember) string {
+	status := TrainStatusSuccess
+	rolledBack := false
+	for _, member := range members {
+		switch member.Status {
+		case TrainMemberRolledBack:
+			rolledBack = true
+		case TrainMemberRollbackFailed:
+			return TrainStatusFailed
+		case TrainMemberFailed, TrainMemberSkipped:
+			status = TrainStatusFailed
+		case TrainMemberWaiting, TrainMemberRunning:
+			if status == TrainStatusSuccess {
+				status = TrainStatusRunning
+			}
+		}
+	}
+	if rolledBack {
+		return TrainStatusRolledBack
+	}
+	return status
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// hostnamePattern NFS服务器主机名
+	hostnamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?)*$`)
+	// dnsLabelPattern DNS-1123标签，用作Pod中的卷名
+	dnsLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// dnsSubdomainPattern DNS-1123子域名，用于PVC名称
+	dnsSubdomainPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// AppVolume 共享存储卷目录中的一项，PVC属于命名空间，只能在所属环境中挂载
+type AppVolume struct {
+	module.Module
+	Name        string             `json:"name" gorm:"size:63;not null;uniqueIndex;comment:'卷名称，同时作为Pod中的卷名'"`
+	Description string             `json:"description" gorm:"size:500"`
+	EnvID       types.Long         `json:"env_id" gorm:"index;default:0;comment:'限定的环境ID，0表示所有环境可用'"`
+	Spec        module.ShareVolume `json:"spec" gorm:"comment:'存储卷定义'"`
+}
+
+// TableName 返回共享存储卷表名
+func (AppVolume) TableName() string {
+	return "app_volume"
+}
+
+// AvailableIn 判断存储卷能否在指定环境中挂载
+func (v *AppVolume) AvailableIn(envID types.Long) bool {
+	return v.EnvID == 0 || v.EnvID == envID
+}
+
+// AppVolumeMount 应用在某个环境下挂载的共享存储卷
+type AppVolumeMount struct {
+	module.Module
+	AppID     types.Long `json:"app_id" gorm:"not null;uniqueIndex:uk_app_env_mount_path,priority:1;comment:'应用ID'"`
+	EnvID     types.Long `json:"env_id" gorm:"not null;uniqueIndex:uk_app_env_mount_path,priority:2;comment:'环境ID'"`
+	VolumeID  types.Long `json:"volume_id" gorm:"not null;index;comment:'存储卷ID'"`
+	MountPath string     `json:"mount_path" gorm:"size:255;not null;uniqueIndex:uk_app_env_mount_path,priority:3;comment:'容器内挂载路径'"`
+	SubPath   string     `json:"sub_path" gorm:"size:255;comment:'卷内子路径'"`
+	ReadOnly  bool       `json:"read_only" gorm:"not null;default:false;comment:'是否只读'"`
+}
+
+// TableName 返回应用存储卷挂载表名
+func (AppVolumeMount) TableName() string {
+	return "app_volume_mount"
+}
+
+// VolumeMountVO 应用存储卷挂载视图对象
+type VolumeMountVO struct {
+	*AppVolumeMount
+	EnvName string     `json:"env_name"`
+	Volume  *AppVolume `json:"volume"`
+}
+
+// SaveVolumeCommand 创建或修改共享存储卷命令
+type SaveVolumeCommand struct {
+	ID          types.Long `json:"-"`
+	Name        string     `json:"name" binding:"required,max=63"`
+	Description string     `json:"description" binding:"max=500"`
+	Type        string     `json:"type" binding:"required,oneof=nfs pvc"`
+	NfsServer   string     `json:"nfs_server" binding:"max=253"`
+	NfsPath     string     `json:"nfs_path" binding:"max=255"`
+	PvcName     string     `json:"pvc_name" binding:"max=253"`
+	EnvID       types.Long `json:"env_id"`
+}
+
+// Validate 校验卷名称与NFS/PVC参数，并规范化路径
+func (command *SaveVolumeCommand) Validate() error {
+	command.Name = strings.TrimSpace(command.Name)
+	if !dnsLabelPattern.MatchString(command.Name) {
+		return fmt.Errorf("卷名称 %s 不是有效的DNS-1123标签", command.Name)
+	}
+	if command.Name == ConfigFilesVolume {
+		return fmt.Errorf("卷名称 %s 为配置文件保留", command.Name)
+	}
+	switch command.Type {
+	case VolumeTypeNFS:
+		command.PvcName = ""
+		command.NfsServer = strings.TrimSpace(command.NfsServer)
+		if net.ParseIP(command.NfsServer) == nil && !hostnamePattern.MatchString(command.NfsServer) {
+			return fmt.Errorf("NFS服务器地址无效: %s", command.NfsServer)
+		}
+		command.NfsPath = strings.TrimSpace(command.NfsPath)
+		if !path.IsAbs(command.NfsPath) || strings.ContainsAny(command.NfsPath, " \t\n:,") {
+			return fmt.Errorf("NFS路径必须是绝对路径: %s", command.NfsPath)
+		}
+		command.NfsPath = path.Clean(command.NfsPath)
+	case VolumeTypePVC:
+		command.NfsServer, command.NfsPath = "", ""
+		if !dnsSubdomainPattern.MatchString(command.PvcName) {
+			return fmt.Errorf("PVC名称无效: %s", command.PvcName)
+		}
+		if command.EnvID == 0 {
+			return fmt.Errorf("PVC存储卷必须指定所属环境")
+		}
+	default:
+		return fmt.Errorf("不支持的存储卷类型: %s", command.Type)
+	}
+	return nil
+}
+
+// ApplyTo 将命令内容写入存储卷
+func (command *SaveVolumeCommand) ApplyTo(volume *AppVolume) {
+	volume.Name = command.Name
+	volume.Description = command.Description
+	volume.EnvID = command.EnvID
+	volume.Spec = module.ShareVolume{
+		Name:      command.Name,
+		Type:      command.Type,
+		NfsServer: command.NfsServer,
+		NfsPath:   command.NfsPath,
+		PvcName:   command.PvcName,
+	}
+}
+
+// SaveVolumeMountCommand 创建或修改应用存储卷挂载命令
+type SaveVolumeMountCommand struct {
+	ID        types.Long `json:"-"`
+	AppID     types.Long `json:"-"`
+	EnvID     types.Long `json:"env_id" binding:"required"`
+	VolumeID  types.Long `json:"volume_id" binding:"required"`
+	MountPath string     `json:"mount_path" binding:"required,max=255"`
+	SubPath   string     `json:"sub_path" binding:"max=255"`
+	ReadOnly  bool       `json:"read_only"`
+}
+
+// Validate 校验挂载路径与子路径，并规范化
+func (command *SaveVolumeMountCommand) Validate() error {
+	command.MountPath = strings.TrimSpace(command.MountPath)
+	if !path.IsAbs(command.MountPath) || strings.Contains(command.MountPath, ":") {
+		return fmt.Errorf("挂载路径必须是绝对路径: %s", command.MountPath)
+	}
+	command.MountPath = path.Clean(command.MountPath)
+	if command.MountPath == "/" {
+		return fmt.Errorf("不能挂载到根目录")
+	}
+
+	command.SubPath = strings.TrimSpace(command.SubPath)
+	if command.SubPath != "" {
+		if path.IsAbs(command.SubPath) {
+			return fmt.Errorf("子路径必须是相对路径: %s", command.SubPath)
+		}
+		for _, element := range strings.Split(command.SubPath, "/") {
+			if element == ".." {
+				return fmt.Errorf("子路径不能包含 '..': %s", command.SubPath)
+			}
+		}
+		command.SubPath = path.Clean(command.SubPath)
+	}
+	return nil
+}
+
+// ApplyTo 将命令内容写入挂载
+func (command *SaveVolumeMountCommand) ApplyTo(mount *AppVolumeMount) {
+	mount.AppID = command.AppID
+	mount.EnvID = command.EnvID
+	mount.VolumeID = command.VolumeID
+	mount.MountPath = command.MountPath
+	mount.SubPath = command.SubPath
+	mount.ReadOnly = command.ReadOnly
+}
+
+// PodVolumesVO 应用在环境下的存储卷挂载及渲染出的Pod卷定义
+type PodVolumesVO struct {
+	Mounts       []*VolumeMountVO `json:"mounts"`
+	Volumes      []PodVolume      `json:"volumes"`
+	VolumeMounts []VolumeMount    `json:"volume_mounts"`
+}
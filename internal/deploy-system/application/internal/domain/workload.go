@@ -0,0 +1,468 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// imagePattern 不带标签的镜像仓库，如 harbor.example.com:5000/team/app
+	imagePattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*$`)
+	// imageTagPattern 镜像标签
+	imageTagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	// portNamePattern 容器端口名（IANA服务名）
+	portNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// labelKeyPattern 标签键，可带DNS子域名前缀
+	labelKeyPattern = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	// labelValuePattern 标签值
+	labelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+	// cpuPattern CPU数量，如 500m、1、1.5
+	cpuPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?)(m?)$`)
+	// memoryPattern 内存数量，如 512Mi、1Gi、1G
+	memoryPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?)(Ki|Mi|Gi|Ti|k|M|G|T)?$`)
+)
+
+// 数量后缀对应的倍数
+var quantitySuffixes = map[string]float64{
+	"": 1, "m": 1e-3,
+	"k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12,
+	"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40,
+}
+
+// WorkloadSpec 应用的工作负载定义，镜像标签在部署时由发布版本决定
+type WorkloadSpec struct {
+	// 部署方式，Job方式下副本数不生效
+	Mode           enum.DeployMode      `json:"mode" binding:"oneof=0 1"`
+	Job            *JobSettings         `json:"job"`
+	Image          string               `json:"image" binding:"required,max=255"`
+	Command        []string             `json:"command" binding:"max=50"`
+	Args           []string             `json:"args" binding:"max=100"`
+	Ports          []ContainerPort      `json:"ports" binding:"max=20,dive"`
+	Resources      ResourceRequirements `json:"resources"`
+	LivenessProbe  *Probe               `json:"liveness_probe"`
+	ReadinessProbe *Probe               `json:"readiness_probe"`
+	StartupProbe   *Probe               `json:"startup_probe"`
+	Replicas       *int32               `json:"replicas" binding:"omitempty,min=0,max=100"`
+	NodeSelector   map[string]string    `json:"node_selector" binding:"max=20"`
+	Tolerations    []Toleration         `json:"tolerations" binding:"max=20,dive"`
+}
+
+// ContainerPort 容器端口
+type ContainerPort struct {
+	Name          string `json:"name" binding:"omitempty,max=15"`
+	ContainerPort int    `json:"container_port" binding:"required,min=1,max=65535"`
+	Protocol      string `json:"protocol" binding:"omitempty,oneof=TCP UDP SCTP"`
+}
+
+// ResourceRequirements 容器资源请求与限制
+type ResourceRequirements struct {
+	Requests ResourceList `json:"requests"`
+	Limits   ResourceList `json:"limits"`
+}
+
+// ResourceList CPU与内存数量，使用Kubernetes数量格式，空表示不设置
+type ResourceList struct {
+	CPU    string `json:"cpu" binding:"max=20"`
+	Memory string `json:"memory" binding:"max=20"`
+}
+
+// Probe 健康检查，HTTP、TCP与命令三种方式必须且只能选择一种
+type Probe struct {
+	HTTPGet             *HTTPGetAction   `json:"http_get"`
+	TCPSocket           *TCPSocketAction `json:"tcp_socket"`
+	Exec                *ExecAction      `json:"exec"`
+	InitialDelaySeconds int32            `json:"initial_delay_seconds" binding:"min=0,max=3600"`
+	PeriodSeconds       int32            `json:"period_seconds" binding:"min=0,max=3600"`
+	TimeoutSeconds      int32            `json:"timeout_seconds" binding:"min=0,max=3600"`
+	SuccessThreshold    int32            `json:"success_threshold" binding:"min=0,max=100"`
+	FailureThreshold    int32            `json:"failure_threshold" binding:"min=0,max=100"`
+}
+
+// HTTPGetAction HTTP健康检查
+type HTTPGetAction struct {
+	Path   string `json:"path" binding:"max=255"`
+	Port   int    `json:"port" binding:"required,min=1,max=65535"`
+	Scheme string `json:"scheme" binding:"omitempty,oneof=HTTP HTTPS"`
+}
+
+// TCPSocketAction TCP健康检查
+type TCPSocketAction struct {
+	Port int `json:"port" binding:"required,min=1,max=65535"`
+}
+
+// ExecAction 命令健康检查
+type ExecAction struct {
+	Command []string `json:"command" binding:"required,min=1,max=50"`
+}
+
+// Toleration 污点容忍
+type Toleration struct {
+	Key               string `json:"key" binding:"max=253"`
+	Operator          string `json:"operator" binding:"omitempty,oneof=Exists Equal"`
+	Value             string `json:"value" binding:"max=63"`
+	Effect            string `json:"effect" binding:"omitempty,oneof=NoSchedule PreferNoSchedule NoExecute"`
+	TolerationSeconds *int64 `json:"toleration_seconds"`
+}
+
+func (WorkloadSpec) GormDataType() string {
+	return "json"
+}
+
+// 实现 sql.Scanner 接口
+func (s *WorkloadSpec) Scan(value interface{}) error {
+	return scanJSON(value, s)
+}
+
+// 实现 driver.Valuer 接口
+func (s WorkloadSpec) Value() (driver.Value, error) {
+	return valueJSON(s)
+}
+
+// Validate 校验binding规则之外的约束：镜像格式、端口唯一、资源数量、健康检查与调度配置
+func (s *WorkloadSpec) Validate() error {
+	s.Image = strings.TrimSpace(s.Image)
+	if s.Replicas == nil {
+		replicas := int32(DefaultReplicas)
+		s.Replicas = &replicas
+	}
+	if !imagePattern.MatchString(s.Image) {
+		return fmt.Errorf("镜像仓库无效（不能包含标签或摘要）: %s", s.Image)
+	}
+	switch s.Mode {
+	case enum.DeployModeDeployment:
+		if s.Job != nil {
+			return errors.New("只有Job部署方式可以设置job")
+		}
+	case enum.DeployModeJob:
+		if s.Job == nil {
+			s.Job = &JobSettings{}
+		}
+		if err := s.Job.Validate(); err != nil {
+			return err
+		}
+		if s.ReadinessProbe != nil {
+			return errors.New("Job部署方式不支持就绪检查")
+		}
+	default:
+		return fmt.Errorf("不支持的部署方式: %d", s.Mode)
+	}
+
+	names := make(map[string]bool, len(s.Ports))
+	numbers := make(map[string]bool, len(s.Ports))
+	for i := range s.Ports {
+		port := &s.Ports[i]
+		if port.Protocol == "" {
+			port.Protocol = "TCP"
+		}
+		if port.Name != "" {
+			if !portNamePattern.MatchString(port.Name) || strings.Trim(port.Name, "0123456789-") == "" {
+				return fmt.Errorf("端口名称无效: %s", port.Name)
+			}
+			if names[port.Name] {
+				return fmt.Errorf("端口名称重复: %s", port.Name)
+			}
+			names[port.Name] = true
+		}
+		key := fmt.Sprintf("%d/%s", port.ContainerPort, port.Protocol)
+		if numbers[key] {
+			return fmt.Errorf("端口重复: %s", key)
+		}
+		numbers[key] = true
+	}
+
+	if err := s.Resources.Validate(); err != nil {
+		return err
+	}
+	probes := []struct {
+		name  string
+		probe *Probe
+	}{{"存活检查", s.LivenessProbe}, {"就绪检查", s.ReadinessProbe}, {"启动检查", s.StartupProbe}}
+	for _, p := range probes {
+		if p.probe == nil {
+			continue
+		}
+		if err := p.probe.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", p.name, err)
+		}
+		// 存活与启动检查的成功阈值只能是1
+		if p.probe != s.ReadinessProbe && p.probe.SuccessThreshold > 1 {
+			return fmt.Errorf("%s的成功阈值只能为1", p.name)
+		}
+	}
+	if err := validateNodeSelector(s.NodeSelector); err != nil {
+		return err
+	}
+	return validateTolerations(s.Tolerations)
+}
+
+// Validate 校验资源数量格式，以及请求不超过限制
+func (r *ResourceRequirements) Validate() error {
+	quantities := []struct {
+		name    string
+		value   string
+		pattern *regexp.Regexp
+	}{
+		{"CPU请求", r.Requests.CPU, cpuPattern},
+		{"CPU限制", r.Limits.CPU, cpuPattern},
+		{"内存请求", r.Requests.Memory, memoryPattern},
+		{"内存限制", r.Limits.Memory, memoryPattern},
+	}
+	for _, q := range quantities {
+		if q.value != "" && !q.pattern.MatchString(q.value) {
+			return fmt.Errorf("%s格式无效: %s", q.name, q.value)
+		}
+	}
+	if r.Requests.CPU != "" && r.Limits.CPU != "" && parseQuantity(cpuPattern, r.Requests.CPU) > parseQuantity(cpuPattern, r.Limits.CPU) {
+		return fmt.Errorf("CPU请求 %s 超过限制 %s", r.Requests.CPU, r.Limits.CPU)
+	}
+	if r.Requests.Memory != "" && r.Limits.Memory != "" && parseQuantity(memoryPattern, r.Requests.Memory) > parseQuantity(memoryPattern, r.Limits.Memory) {
+		return fmt.Errorf("内存请求 %s 超过限制 %s", r.Requests.Memory, r.Limits.Memory)
+	}
+	return nil
+}
+
+// Validate 校验健康检查方式
+func (p *Probe) Validate() error {
+	handlers := 0
+	if p.HTTPGet != nil {
+		handlers++
+		if p.HTTPGet.Path != "" && !strings.HasPrefix(p.HTTPGet.Path, "/") {
+			return fmt.Errorf("HTTP路径必须以 / 开头: %s", p.HTTPGet.Path)
+		}
+	}
+	if p.TCPSocket != nil {
+		handlers++
+	}
+	if p.Exec != nil {
+		handlers++
+	}
+	if handlers != 1 {
+		return errors.New("必须且只能指定 http_get、tcp_socket、exec 中的一种")
+	}
+	return nil
+}
+
+// validateNodeSelector 校验节点选择器的标签键与值
+func validateNodeSelector(selector map[string]string) error {
+	return validateLabels("节点选择器", selector)
+}
+
+// validateLabels 校验标签键与值，kind用于错误信息
+func validateLabels(kind string, labels map[string]string) error {
+	for key, value := range labels {
+		if len(key) > 316 || !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("%s标签键无效: %s", kind, key)
+		}
+		if len(value) > 63 || !labelValuePattern.MatchString(value) {
+			return fmt.Errorf("%s标签 %s 的值无效: %s", kind, key, value)
+		}
+	}
+	return nil
+}
+
+// validateTolerations 校验污点容忍的组合
+func validateTolerations(tolerations []Toleration) error {
+	for i := range tolerations {
+		toleration := &tolerations[i]
+		if toleration.Operator == "" {
+			toleration.Operator = "Equal"
+		}
+		if toleration.Key != "" && !labelKeyPattern.MatchString(toleration.Key) {
+			return fmt.Errorf("污点键无效: %s", toleration.Key)
+		}
+		switch toleration.Operator {
+		case "Exists":
+			if toleration.Value != "" {
+				return fmt.Errorf("污点 %s 使用Exists时不能指定值", toleration.Key)
+			}
+		case "Equal":
+			if toleration.Key == "" {
+				return errors.New("污点容忍使用Equal时必须指定键")
+			}
+		}
+		if toleration.TolerationSeconds != nil && toleration.Effect != "NoExecute" {
+			return fmt.Errorf("污点 %s 只有NoExecute效果可以指定容忍时间", toleration.Key)
+		}
+	}
+	return nil
+}
+
+// parseQuantity 解析已通过格式校验的数量
+func parseQuantity(pattern *regexp.Regexp, value string) float64 {
+	match := pattern.FindStringSubmatch(value)
+	number, _ := strconv.ParseFloat(match[1], 64)
+	return number * quantitySuffixes[match[3]]
+}
+
+// IsImageTag 判断版本能否作为镜像标签
+func IsImageTag(version string) bool {
+	return imageTagPattern.MatchString(version)
+}
+
+// Merge 应用环境覆盖，返回新的定义，不修改原定义
+func (s WorkloadSpec) Merge(override *WorkloadOverride) WorkloadSpec {
+	if override == nil {
+		return s
+	}
+	if override.Replicas != nil {
+		s.Replicas = override.Replicas
+	}
+	if override.Resources != nil {
+		s.Resources = *override.Resources
+	}
+	if override.NodeSelector != nil {
+		s.NodeSelector = override.NodeSelector
+	}
+	if override.Tolerations != nil {
+		s.Tolerations = override.Tolerations
+	}
+	return s
+}
+
+// AppWorkloadSpec 应用工作负载定义的版本，创建后不可修改，每次变更生成新版本
+type AppWorkloadSpec struct {
+	module.CreateOnlyModule
+	AppID    types.Long   `json:"app_id" gorm:"not null;uniqueIndex:uk_app_revision,priority:1;comment:'应用ID'"`
+	Revision int          `json:"revision" gorm:"not null;uniqueIndex:uk_app_revision,priority:2;comment:'版本号，从1开始'"`
+	Spec     WorkloadSpec `json:"spec" gorm:"not null;comment:'工作负载定义'"`
+	Checksum string       `json:"checksum" gorm:"size:64;not null;comment:'定义内容摘要'"`
+	Comment  string       `json:"comment" gorm:"size:500;comment:'变更说明'"`
+}
+
+// TableName 返回应用工作负载定义表名
+func (AppWorkloadSpec) TableName() string {
+	return "app_workload_spec"
+}
+
+// WorkloadOverride 环境对工作负载定义的覆盖，字段为null时沿用应用定义
+type WorkloadOverride struct {
+	Replicas     *int32                `json:"replicas" binding:"omitempty,min=0,max=100"`
+	Resources    *ResourceRequirements `json:"resources"`
+	NodeSelector map[string]string     `json:"node_selector" binding:"max=20"`
+	Tolerations  []Toleration          `json:"tolerations" binding:"max=20,dive"`
+}
+
+func (WorkloadOverride) GormDataType() string {
+	return "json"
+}
+
+// 实现 sql.Scanner 接口
+func (o *WorkloadOverride) Scan(value interface{}) error {
+	return scanJSON(value, o)
+}
+
+// 实现 driver.Valuer 接口
+func (o WorkloadOverride) Value() (driver.Value, error) {
+	return valueJSON(o)
+}
+
+// Validate 校验覆盖的资源与调度配置
+func (o *WorkloadOverride) Validate() error {
+	if o.Resources != nil {
+		if err := o.Resources.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateNodeSelector(o.NodeSelector); err != nil {
+		return err
+	}
+	return validateTolerations(o.Tolerations)
+}
+
+// AppWorkloadOverride 应用在某个环境下的工作负载覆盖
+type AppWorkloadOverride struct {
+	module.Module
+	AppID    types.Long       `json:"app_id" gorm:"not null;uniqueIndex:uk_app_env,priority:1;comment:'应用ID'"`
+	EnvID    types.Long       `json:"env_id" gorm:"not null;uniqueIndex:uk_app_env,priority:2;comment:'环境ID'"`
+	Override WorkloadOverride `json:"override" gorm:"not null;comment:'覆盖内容'"`
+}
+
+// TableName 返回应用工作负载环境覆盖表名
+func (AppWorkloadOverride) TableName() string {
+	return "app_workload_override"
+}
+
+// SaveWorkloadSpecCommand 保存应用工作负载定义命令，生成新版本
+type SaveWorkloadSpecCommand struct {
+	AppID types.Long `json:"-"`
+	WorkloadSpec
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+// NewRevision 根据命令生成指定版本号的定义
+func (command *SaveWorkloadSpecCommand) NewRevision(revision int) *AppWorkloadSpec {
+	spec := &AppWorkloadSpec{
+		AppID:    command.AppID,
+		Revision: revision,
+		Spec:     command.WorkloadSpec,
+		Comment:  command.Comment,
+	}
+	spec.Checksum = spec.Spec.Checksum()
+	return spec
+}
+
+// Checksum 定义内容的摘要，用于判断定义是否变化
+func (s WorkloadSpec) Checksum() string {
+	content, _ := json.Marshal(s)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveWorkloadOverrideCommand 保存环境覆盖命令
+type SaveWorkloadOverrideCommand struct {
+	AppID types.Long `json:"-"`
+	EnvID types.Long `json:"-"`
+	WorkloadOverride
+}
+
+// WorkloadSpecQuery 工作负载定义版本查询条件
+type WorkloadSpecQuery struct {
+	AppID types.Long `form:"-"`
+	Page  int        `form:"page"`
+	Size  int        `form:"size"`
+}
+
+// EffectiveSpecVO 应用在环境下实际生效的工作负载定义
+type EffectiveSpecVO struct {
+	AppID    types.Long        `json:"app_id"`
+	EnvID    types.Long        `json:"env_id"`
+	Revision int               `json:"revision"`
+	Override *WorkloadOverride `json:"override"`
+	Spec     WorkloadSpec      `json:"spec"`
+}
+
+// scanJSON 将JSON列扫描到对象
+func scanJSON(value interface{}, out interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		// 列为NULL时保持零值
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New(fmt.Sprint("Failed to unmarshal JSON value:", value))
+	}
+	return json.Unmarshal(bytes, out)
+}
+
+// valueJSON 将对象序列化为JSON列
+func valueJSON(in interface{}) (driver.Value, error) {
+	jsonStr, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(jsonStr).MarshalJSON()
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/datascope"
+)
+
+// ListAllApplications 查询全部应用，包括已删除的应用，不按数据权限过滤，用于导入时检查名称是否被占用
+func (r *AppRepository) ListAllApplications(ctx context.Context) ([]*domain.Application, error) {
+	var apps []*domain.Application
+	if err := r.DB(ctx).Order("id").Find(&apps).Error; err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// ListScopedApplications 查询未删除的应用，按当前用户的数据权限过滤，按名称排序
+func (r *AppRepository) ListScopedApplications(ctx context.Context) ([]*domain.Application, error) {
+	var apps []*domain.Application
+	if err := r.DB(ctx).Table("app").Select("app.*").
+		Scopes(datascope.Owned(ctx, r.DataScope, "app.dept_id", "app.creator")).
+		Where("app.status <> ?", domain.AppStatusDeleted).
+		Order("app.name").Find(&apps).Error; err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// ListAppGroupRelations 查询全部应用-分组关联
+func (r *AppRepository) ListAppGroupRelations(ctx context.Context) ([]*domain.AppGroupRelation, error) {
+	var relations []*domain.AppGroupRelation
+	if err := r.DB(ctx).Order("id").Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	return relations, nil
+}
+
+// ListAppImageRegistries 查询全部应用-镜像仓库关联
+func (r *AppRepository) ListAppImageRegistries(ctx context.Context) ([]*domain.AppImageRegistry, error) {
+	var links []*domain.AppImageRegistry
+	if err := r.DB(ctx).Order("id").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// ListDefaultAppHPAs 查询全部应用的默认HPA配置
+func (r *AppRepository) ListDefaultAppHPAs(ctx context.Context) ([]*domain.AppHPA, error) {
+	var hpas []*domain.AppHPA
+	if err := r.DB(ctx).Where("env_id = 0").Find(&hpas).Error; err != nil {
+		return nil, err
+	}
+	return hpas, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// CreateConfigRevision 创建配置版本，配置版本创建后不再修改
+func (r *AppRepository) CreateConfigRevision(ctx context.Context, revision *domain.AppConfigRevision) (types.Long, error) {
+	if err := r.DB(ctx).Create(revision).Error; err != nil {
+		return 0, err
+	}
+	return revision.ID, nil
+}
+
+// GetConfigRevisionByID 根据ID获取配置版本，不存在时返回nil
+func (r *AppRepository) GetConfigRevisionByID(ctx context.Context, id types.Long) (*domain.AppConfigRevision, error) {
+	var revision domain.AppConfigRevision
+	if err := r.DB(ctx).First(&revision, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetConfigRevision 获取应用环境的指定配置版本，不存在时返回nil
+func (r *AppRepository) GetConfigRevision(ctx context.Context, appID, envID types.Long, revision int) (*domain.AppConfigRevision, error) {
+	var config domain.AppConfigRevision
+	err := r.DB(ctx).Where("app_id = ? AND env_id = ? AND revision = ?", appID, envID, revision).First(&config).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+// GetLatestConfigRevision 获取应用环境的最新配置版本，没有配置时返回nil
+func (r *AppRepository) GetLatestConfigRevision(ctx context.Context, appID, envID types.Long) (*domain.AppConfigRevision, error) {
+	var config domain.AppConfigRevision
+	err := r.DB(ctx).Where("app_id = ? AND env_id = ?", appID, envID).Order("revision DESC").First(&config).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+// ListConfigRevisions 分页查询应用环境的配置版本，新版本在前
+func (r *AppRepository) ListConfigRevisions(ctx context.Context, query *domain.ConfigRevisionQuery) ([]*domain.AppConfigRevision, int64, error) {
+	db := r.DB(ctx).Model(&domain.AppConfigRevision{}).
+		Where("app_id = ? AND env_id = ?", query.AppID, query.EnvID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var revisions []*domain.AppConfigRevision
+	err := db.Order("revision DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&revisions).Error
+	return revisions, total, err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+)
+
+// ListLatestSuccessfulDeployments 查询所有未删除的应用在各环境中最近一次成功的部署，每个应用环境一条
+func (r *AppRepository) ListLatestSuccessfulDeployments(ctx context.Context) ([]*domain.Deployment, error) {
+	latest := r.DB(ctx).Model(&domain.Deployment{}).Select("MAX(id)").
+		Where("status = ?", domain.DeployStatusSuccess).
+		Group("app_id, env_id")
+	apps := r.DB(ctx).Model(&domain.Application{}).Select("id").
+		Where("status <> ?", domain.AppStatusDeleted)
+	var deployments []*domain.Deployment
+	if err := r.DB(ctx).Where("id IN (?) AND app_id IN (?)", latest, apps).
+		Order("app_id, env_id").Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
+
+// CreateDriftFinding 创建配置漂移记录
+func (r *AppRepository) CreateDriftFinding(ctx context.Context, finding *domain.DriftFinding) error {
+	return r.DB(ctx).Create(finding).Error
+}
+
+// UpdateDriftFinding 更新配置漂移记录
+func (r *AppRepository) UpdateDriftFinding(ctx context.Context, finding *domain.DriftFinding) error {
+	return r.DB(ctx).Save(finding).Error
+}
+
+// ListOpenDriftFindings 查询应用环境中未恢复的配置漂移
+func (r *AppRepository) ListOpenDriftFindings(ctx context.Context, appID, envID types.Long) ([]*domain.DriftFinding, error) {
+	var findings []*domain.DriftFinding
+	if err := r.DB(ctx).Where("app_id = ? AND env_id = ? AND status = ?", appID, envID, domain.DriftStatusOpen).
+		Order("id").Find(&findings).Error; err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
+
+// ListDriftFindings 分页查询配置漂移，按当前用户的数据权限过滤
+func (r *AppRepository) ListDriftFindings(ctx context.Context, query *domain.DriftQuery) ([]*domain.DriftFinding, int64, error) {
+	db := r.DB(ctx).Model(&domain.DriftFinding{}).Scopes(r.appDataScope(ctx, "app_id"))
+	if query.AppID > 0 {
+		db = db.Where("app_id = ?", query.AppID)
+	}
+	if query.EnvID > 0 {
+		db = db.Where("env_id = ?", query.EnvID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var findings []*domain.DriftFinding
+	err := db.Order("last_seen_at DESC, id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&findings).Error
+	return findings, total, err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// CreateJobRun 创建Job运行记录
+func (r *AppRepository) CreateJobRun(ctx context.Context, run *domain.JobRun) (types.Long, error) {
+	if err := r.DB(ctx).Create(run).Error; err != nil {
+		return 0, err
+	}
+	return run.ID, nil
+}
+
+// UpdateJobRun 更新Job运行记录
+func (r *AppRepository) UpdateJobRun(ctx context.Context, run *domain.JobRun) error {
+	return r.DB(ctx).Save(run).Error
+}
+
+// GetJobRunByID 根据ID获取Job运行记录（含日志），不存在时返回nil
+func (r *AppRepository) GetJobRunByID(ctx context.Context, id types.Long) (*domain.JobRun, error) {
+	var run domain.JobRun
+	if err := r.DB(ctx).First(&run, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetJobRun 根据部署记录与Job名称获取运行记录，不存在时返回nil
+func (r *AppRepository) GetJobRun(ctx context.Context, deployID types.Long, jobName string) (*domain.JobRun, error) {
+	var run domain.JobRun
+	if err := r.DB(ctx).Where("deploy_id = ? AND job_name = ?", deployID, jobName).First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListJobRuns 分页查询应用的Job运行记录，新记录在前，不含日志
+func (r *AppRepository) ListJobRuns(ctx context.Context, query *domain.JobRunQuery) ([]*domain.JobRun, int64, error) {
+	db := r.DB(ctx).Model(&domain.JobRun{}).Where("app_id = ?", query.AppID)
+	if query.EnvID > 0 {
+		db = db.Where("env_id = ?", query.EnvID)
+	}
+	if query.DeployID > 0 {
+		db = db.Where("deploy_id = ?", query.DeployID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runs []*domain.JobRun
+	err := db.Omit("log").Order("start_time DESC, id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&runs).Error
+	return runs, total, err
+}
+
+// ListDeploymentJobRuns 查询部署记录的全部Job运行，不含日志
+func (r *AppRepository) ListDeploymentJobRuns(ctx context.Context, deployID types.Long) ([]*domain.JobRun, error) {
+	var runs []*domain.JobRun
+	if err := r.DB(ctx).Omit("log").Where("deploy_id = ?", deployID).
+		Order("start_time ASC, id ASC").Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// ListRunningScheduledJobRuns 查询环境中尚未结束的定时任务运行
+func (r *AppRepository) ListRunningScheduledJobRuns(ctx context.Context, envID types.Long) ([]*domain.JobRun, error) {
+	var runs []*domain.JobRun
+	if err := r.DB(ctx).Where("env_id = ? AND scheduled = ? AND status = ?", envID, true, domain.DeployStatusRunning).
+		Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
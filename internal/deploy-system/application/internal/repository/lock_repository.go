@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// CreateDeployLock 创建部署锁，同一应用环境已有同类型的锁（违反唯一索引）时返回false
+func (r *AppRepository) CreateDeployLock(ctx context.Context, lock *domain.DeployLock) (bool, error) {
+	db := r.DB(ctx)
+	err := db.Create(lock).Error
+	if err == nil {
+		return true, nil
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return false, nil
+	}
+	return false, err
+}
+
+// UpdateDeployLock 更新部署锁
+func (r *AppRepository) UpdateDeployLock(ctx context.Context, lock *domain.DeployLock) error {
+	return r.DB(ctx).Save(lock).Error
+}
+
+// GetDeployLock 获取应用环境的部署锁，没有时返回nil
+func (r *AppRepository) GetDeployLock(ctx context.Context, appID, envID types.Long, kind string) (*domain.DeployLock, error) {
+	var lock domain.DeployLock
+	err := r.DB(ctx).Where("app_id = ? AND env_id = ? AND kind = ?", appID, envID, kind).First(&lock).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// TakeOverDeployLock 接管已过期的部署锁，锁已被续期或被其他人接管时返回false
+func (r *AppRepository) TakeOverDeployLock(ctx context.Context, lock *domain.DeployLock, now time.Time) (bool, error) {
+	result := r.DB(ctx).Model(&domain.DeployLock{}).
+		Where("id = ? AND expires_at IS NOT NULL AND expires_at <= ?", lock.ID, now).
+		Updates(map[string]interface{}{
+			"deployment_id":         lock.DeploymentID,
+			"reason":                lock.Reason,
+			"expires_at":            lock.ExpiresAt,
+			"last_modified_by_id":   lock.LastModifiedBy.ID,
+			"last_modified_by_name": lock.LastModifiedBy.Name,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RenewDeployLock 续期部署记录持有的租约
+func (r *AppRepository) RenewDeployLock(ctx context.Context, deploymentID types.Long, expiresAt time.Time) error {
+	return r.DB(ctx).Model(&domain.DeployLock{}).
+		Where("kind = ? AND deployment_id = ?", domain.DeployLockLease, deploymentID).
+		Update("expires_at", expiresAt).Error
+}
+
+// ReleaseDeployLock 释放部署记录持有的租约，租约已被接管时不影响新的持有者
+func (r *AppRepository) ReleaseDeployLock(ctx context.Context, deploymentID types.Long) error {
+	return r.DB(ctx).Where("kind = ? AND deployment_id = ?", domain.DeployLockLease, deploymentID).
+		Delete(&domain.DeployLock{}).Error
+}
+
+// DeleteDeployLock 删除部署锁
+func (r *AppRepository) DeleteDeployLock(ctx context.Context, id types.Long) error {
+	return r.DB(ctx).Delete(&domain.DeployLock{}, id).Error
+}
+
+// ListDeployLocks 查询部署锁，appID或envID为0时不限制
+func (r *AppRepository) ListDeployLocks(ctx context.Context, appID, envID types.Long) ([]*domain.DeployLock, error) {
+	db := r.DB(ctx)
+	if appID > 0 {
+		db = db.Where("app_id = ?", appID)
+	}
+	if envID > 0 {
+		db = db.Where("env_id = ?", envID)
+	}
+	var locks []*domain.DeployLock
+	err := db.Order("id ASC").Find(&locks).Error
+	return locks, err
+}
+
+// ListQueuedReleasePlans 查询排队中的发布计划，按排队先后排列，appID或envID为0时不限制
+func (r *AppRepository) ListQueuedReleasePlans(ctx context.Context, appID, envID types.Long) ([]*domain.ReleasePlan, error) {
+	db := r.DB(ctx).Where("status = ?", domain.DeployStatusQueued)
+	if appID > 0 {
+		db = db.Where("app_id = ?", appID)
+	}
+	if envID > 0 {
+		db = db.Where("env_id = ?", envID)
+	}
+	var plans []*domain.ReleasePlan
+	err := db.Order("last_modified_at ASC, id ASC").Find(&plans).Error
+	return plans, err
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/datascope"
+	"devops-platform/pkg/types"
+)
+
+// ListMatrixApps 查询环境矩阵中的应用，按当前用户的数据权限过滤，不含已删除的应用
+func (r *AppRepository) ListMatrixApps(ctx context.Context, query *domain.EnvMatrixQuery) ([]*domain.Application, error) {
+	db := r.DB(ctx).Model(&domain.Application{}).
+		Scopes(datascope.Owned(ctx, r.DataScope, "app.dept_id", "app.creator")).
+		Where("app.status <> ?", domain.AppStatusDeleted)
+	if query.DeptID > 0 {
+		db = db.Where("app.dept_id = ?", query.DeptID)
+	}
+	if query.GroupID > 0 {
+		db = db.Where("app.id IN (?)", r.DB(ctx).Table("relation_app_group_app").
+			Select("app_id").Where("group_id = ?", query.GroupID))
+	}
+	var apps []*domain.Application
+	if err := db.Order("app.name").Find(&apps).Error; err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// ListCurrentDeployments 查询应用在各环境中最近一次成功的部署，每个应用环境一条
+func (r *AppRepository) ListCurrentDeployments(ctx context.Context, appIDs []types.Long) ([]*domain.Deployment, error) {
+	var deployments []*domain.Deployment
+	if len(appIDs) == 0 {
+		return deployments, nil
+	}
+	latest := r.DB(ctx).Model(&domain.Deployment{}).Select("MAX(id)").
+		Where("app_id IN ? AND status = ?", appIDs, domain.DeployStatusSuccess).
+		Group("app_id, env_id")
+	if err := r.DB(ctx).Where("id IN (?)", latest).Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
+
+// ListPendingPlanSummaries 按应用环境统计比当前部署新的待执行发布计划，没有这样的计划的应用环境不返回
+func (r *AppRepository) ListPendingPlanSummaries(ctx context.Context, appIDs []types.Long) ([]*domain.PendingPlanSummary, error) {
+	var summaries []*domain.PendingPlanSummary
+	if len(appIDs) == 0 {
+		return summaries, nil
+	}
+	latest := r.DB(ctx).Model(&domain.Deployment{}).Select("app_id, env_id, MAX(id) AS id").
+		Where("app_id IN ? AND status = ?", appIDs, domain.DeployStatusSuccess).
+		Group("app_id, env_id")
+	err := r.DB(ctx).Table("app_release_plan AS p").
+		Select("p.app_id, p.env_id, COUNT(*) AS plans, MAX(p.id) AS latest_plan_id").
+		Joins("LEFT JOIN (?) AS latest ON latest.app_id = p.app_id AND latest.env_id = p.env_id", latest).
+		Joins("LEFT JOIN deploy_history AS d ON d.id = latest.id").
+		Where("p.app_id IN ? AND p.status IN ?", appIDs, domain.PendingPlanStatuses).
+		Where("d.id IS NULL OR (NOT (p.version = d.version AND (p.digest = '' OR p.digest = d.digest)) AND "+
+			"(p.status = ? OR p.created_at > d.created_at))", domain.DeployStatusQueued).
+		Group("p.app_id, p.env_id").
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// ListReleasePlansByIDs 按ID批量查询发布计划
+func (r *AppRepository) ListReleasePlansByIDs(ctx context.Context, ids []types.Long) ([]*domain.ReleasePlan, error) {
+	var plans []*domain.ReleasePlan
+	if len(ids) == 0 {
+		return plans, nil
+	}
+	if err := r.DB(ctx).Where("id IN ?", ids).Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// CreateAppMember 添加应用成员
+func (r *AppRepository) CreateAppMember(ctx context.Context, member *domain.AppMember) (types.Long, error) {
+	if err := r.DB(ctx).Create(member).Error; err != nil {
+		return 0, err
+	}
+	return member.ID, nil
+}
+
+// UpdateAppMember 更新应用成员，值班标记可能被清除，因此保存全部字段
+func (r *AppRepository) UpdateAppMember(ctx context.Context, member *domain.AppMember) error {
+	return r.DB(ctx).Save(member).Error
+}
+
+// GetAppMember 获取用户在应用中的成员记录，不是成员时返回nil
+func (r *AppRepository) GetAppMember(ctx context.Context, appID, userID types.Long) (*domain.AppMember, error) {
+	var member domain.AppMember
+	if err := r.DB(ctx).Where("app_id = ? AND user_id = ?", appID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListAppMembers 查询应用成员，按加入时间排序
+func (r *AppRepository) ListAppMembers(ctx context.Context, appID types.Long) ([]*domain.AppMember, error) {
+	var members []*domain.AppMember
+	err := r.DB(ctx).Where("app_id = ?", appID).Order("id ASC").Find(&members).Error
+	return members, err
+}
+
+// CountAppMembers 统计应用成员数，role不为空时只统计该角色
+func (r *AppRepository) CountAppMembers(ctx context.Context, appID types.Long, role string) (int64, error) {
+	var count int64
+	db := r.DB(ctx).Model(&domain.AppMember{}).Where("app_id = ?", appID)
+	if role != "" {
+		db = db.Where("role = ?", role)
+	}
+	err := db.Count(&count).Error
+	return count, err
+}
+
+// DeleteAppMember 移除应用成员
+func (r *AppRepository) DeleteAppMember(ctx context.Context, id types.Long) error {
+	return r.DB(ctx).Delete(&domain.AppMember{}, id).Error
+}
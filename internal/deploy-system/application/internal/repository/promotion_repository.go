@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// ListPromotionStages 查询应用的晋级路径，按阶段顺序排列，没有路径时返回空
+func (r *AppRepository) ListPromotionStages(ctx context.Context, appID types.Long) ([]*domain.AppPromotionStage, error) {
+	var stages []*domain.AppPromotionStage
+	err := r.DB(ctx).Where("app_id = ?", appID).Order("position ASC").Find(&stages).Error
+	return stages, err
+}
+
+// ReplacePromotionStages 替换应用的晋级路径，调用方负责事务
+func (r *AppRepository) ReplacePromotionStages(ctx context.Context, appID types.Long, stages []*domain.AppPromotionStage) error {
+	if err := r.DB(ctx).Where("app_id = ?", appID).Delete(&domain.AppPromotionStage{}).Error; err != nil {
+		return err
+	}
+	if len(stages) == 0 {
+		return nil
+	}
+	return r.DB(ctx).Create(&stages).Error
+}
+
+// GetLatestSuccessfulDeployment 获取应用在环境下最近一次成功的部署，没有时返回nil
+func (r *AppRepository) GetLatestSuccessfulDeployment(ctx context.Context, appID, envID types.Long) (*domain.Deployment, error) {
+	var deployment domain.Deployment
+	err := r.DB(ctx).Where("app_id = ? AND env_id = ? AND status = ?", appID, envID, domain.DeployStatusSuccess).
+		Order("id DESC").First(&deployment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// GetSuccessfulDeploymentBefore 获取应用在环境下指定部署之前最近一次成功的部署，没有时返回nil
+func (r *AppRepository) GetSuccessfulDeploymentBefore(ctx context.Context, appID, envID, beforeID types.Long) (*domain.Deployment, error) {
+	var deployment domain.Deployment
+	err := r.DB(ctx).Where("app_id = ? AND env_id = ? AND status = ? AND id < ?", appID, envID, domain.DeployStatusSuccess, beforeID).
+		Order("id DESC").First(&deployment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// HasSuccessfulDeployment 应用在环境下是否成功部署过该版本，digest不为空时还要求digest一致
+func (r *AppRepository) HasSuccessfulDeployment(ctx context.Context, appID, envID types.Long, version, digest string) (bool, error) {
+	var count int64
+	db := r.DB(ctx).Model(&domain.Deployment{}).
+		Where("app_id = ? AND env_id = ? AND version = ? AND status = ?", appID, envID, version, domain.DeployStatusSuccess)
+	if digest != "" {
+		db = db.Where("digest = ?", digest)
+	}
+	err := db.Count(&count).Error
+	return count > 0, err
+}
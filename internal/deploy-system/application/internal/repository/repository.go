@@ -5,7 +5,13 @@ import (
 	"devops-platform/internal/common/repository"
 
 	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/datascope"
+	"devops-platform/internal/pkg/enum"
 	"devops-platform/pkg/types"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // Repository 应用管理仓储接口
@@ -17,6 +23,8 @@ type Repository interface {
 	GetApplicationByName(ctx context.Context, name string) (*domain.Application, error)
 	ListApplications(ctx context.Context, query *domain.AppQuery) ([]*domain.AppVO, int64, error)
 	DeleteApplication(ctx context.Context, id types.Long) error
+	ListAllApplications(ctx context.Context) ([]*domain.Application, error)
+	ListScopedApplications(ctx context.Context) ([]*domain.Application, error)
 
 	// 应用分组相关
 	CreateAppGroup(ctx context.Context, group *domain.AppGroup) (types.Long, error)
@@ -30,6 +38,7 @@ type Repository interface {
 	RemoveAppFromGroup(ctx context.Context, appID, groupID types.Long) error
 	GetAppGroups(ctx context.Context, appID types.Long) ([]*domain.AppGroup, error)
 	GetGroupApps(ctx context.Context, groupID types.Long) ([]*domain.Application, error)
+	ListAppGroupRelations(ctx context.Context) ([]*domain.AppGroupRelation, error)
 
 	// 环境相关
 	CreateAppEnv(ctx context.Context, env *domain.AppEnv) (types.Long, error)
@@ -62,16 +71,109 @@ type Repository interface {
 	GetImageRegistryByID(ctx context.Context, id types.Long) (*domain.ImageRegistry, error)
 	ListImageRegistries(ctx context.Context) ([]*domain.ImageRegistry, error)
 	DeleteImageRegistry(ctx context.Context, id types.Long) error
+	CreateAppImageRegistry(ctx context.Context, link *domain.AppImageRegistry) (types.Long, error)
+	ListAppImageRegistries(ctx context.Context) ([]*domain.AppImageRegistry, error)
 
 	// 应用HPA相关
 	CreateAppHPA(ctx context.Context, hpa *domain.AppHPA) (types.Long, error)
 	UpdateAppHPA(ctx context.Context, hpa *domain.AppHPA) error
 	GetAppHPAByAppID(ctx context.Context, appID types.Long) (*domain.AppHPA, error)
+	GetAppHPA(ctx context.Context, appID, envID types.Long) (*domain.AppHPA, error)
+	ListAppHPAs(ctx context.Context, appID types.Long) ([]*domain.AppHPA, error)
+	ListDefaultAppHPAs(ctx context.Context) ([]*domain.AppHPA, error)
 	DeleteAppHPA(ctx context.Context, id types.Long) error
+
+	// 应用配置版本相关
+	CreateConfigRevision(ctx context.Context, revision *domain.AppConfigRevision) (types.Long, error)
+	GetConfigRevisionByID(ctx context.Context, id types.Long) (*domain.AppConfigRevision, error)
+	GetConfigRevision(ctx context.Context, appID, envID types.Long, revision int) (*domain.AppConfigRevision, error)
+	GetLatestConfigRevision(ctx context.Context, appID, envID types.Long) (*domain.AppConfigRevision, error)
+	ListConfigRevisions(ctx context.Context, query *domain.ConfigRevisionQuery) ([]*domain.AppConfigRevision, int64, error)
+
+	// 共享存储卷相关
+	CreateVolume(ctx context.Context, volume *domain.AppVolume) (types.Long, error)
+	UpdateVolume(ctx context.Context, volume *domain.AppVolume) error
+	GetVolumeByID(ctx context.Context, id types.Long) (*domain.AppVolume, error)
+	GetVolumeByName(ctx context.Context, name string) (*domain.AppVolume, error)
+	ListVolumes(ctx context.Context, envID types.Long) ([]*domain.AppVolume, error)
+	ListVolumesByIDs(ctx context.Context, ids []types.Long) ([]*domain.AppVolume, error)
+	DeleteVolume(ctx context.Context, id types.Long) error
+
+	// 应用存储卷挂载相关
+	CreateVolumeMount(ctx context.Context, mount *domain.AppVolumeMount) (types.Long, error)
+	UpdateVolumeMount(ctx context.Context, mount *domain.AppVolumeMount) error
+	GetVolumeMountByID(ctx context.Context, id types.Long) (*domain.AppVolumeMount, error)
+	ListVolumeMounts(ctx context.Context, appID, envID types.Long) ([]*domain.AppVolumeMount, error)
+	ListVolumeMountsByVolume(ctx context.Context, volumeID types.Long) ([]*domain.AppVolumeMount, error)
+	DeleteVolumeMount(ctx context.Context, id types.Long) error
+
+	// 工作负载定义相关
+	CreateWorkloadSpec(ctx context.Context, spec *domain.AppWorkloadSpec) (types.Long, error)
+	GetWorkloadSpec(ctx context.Context, appID types.Long, revision int) (*domain.AppWorkloadSpec, error)
+	GetLatestWorkloadSpec(ctx context.Context, appID types.Long) (*domain.AppWorkloadSpec, error)
+	ListWorkloadSpecs(ctx context.Context, query *domain.WorkloadSpecQuery) ([]*domain.AppWorkloadSpec, int64, error)
+	GetWorkloadOverride(ctx context.Context, appID, envID types.Long) (*domain.AppWorkloadOverride, error)
+	SaveWorkloadOverride(ctx context.Context, override *domain.AppWorkloadOverride) error
+	DeleteWorkloadOverride(ctx context.Context, id types.Long) error
+
+	// Job运行记录相关
+	CreateJobRun(ctx context.Context, run *domain.JobRun) (types.Long, error)
+	UpdateJobRun(ctx context.Context, run *domain.JobRun) error
+	GetJobRunByID(ctx context.Context, id types.Long) (*domain.JobRun, error)
+	GetJobRun(ctx context.Context, deployID types.Long, jobName string) (*domain.JobRun, error)
+	ListJobRuns(ctx context.Context, query *domain.JobRunQuery) ([]*domain.JobRun, int64, error)
+	ListDeploymentJobRuns(ctx context.Context, deployID types.Long) ([]*domain.JobRun, error)
+	ListRunningScheduledJobRuns(ctx context.Context, envID types.Long) ([]*domain.JobRun, error)
+
+	// 应用成员相关
+	CreateAppMember(ctx context.Context, member *domain.AppMember) (types.Long, error)
+	UpdateAppMember(ctx context.Context, member *domain.AppMember) error
+	GetAppMember(ctx context.Context, appID, userID types.Long) (*domain.AppMember, error)
+	ListAppMembers(ctx context.Context, appID types.Long) ([]*domain.AppMember, error)
+	CountAppMembers(ctx context.Context, appID types.Long, role string) (int64, error)
+	DeleteAppMember(ctx context.Context, id types.Long) error
+
+	// 应用晋级路径相关
+	ListPromotionStages(ctx context.Context, appID types.Long) ([]*domain.AppPromotionStage, error)
+	ReplacePromotionStages(ctx context.Context, appID types.Long, stages []*domain.AppPromotionStage) error
+	GetLatestSuccessfulDeployment(ctx context.Context, appID, envID types.Long) (*domain.Deployment, error)
+	GetSuccessfulDeploymentBefore(ctx context.Context, appID, envID, beforeID types.Long) (*domain.Deployment, error)
+	HasSuccessfulDeployment(ctx context.Context, appID, envID types.Long, version, digest string) (bool, error)
+
+	// 发布列车相关
+	CreateReleaseTrain(ctx context.Context, train *domain.ReleaseTrain) (types.Long, error)
+	UpdateReleaseTrain(ctx context.Context, train *domain.ReleaseTrain) error
+	GetReleaseTrain(ctx context.Context, id types.Long) (*domain.ReleaseTrain, error)
+	ListReleaseTrains(ctx context.Context, query *domain.TrainQuery) ([]*domain.ReleaseTrain, int64, error)
+	ListActiveReleaseTrains(ctx context.Context) ([]*domain.ReleaseTrain, error)
+	DeleteReleaseTrain(ctx context.Context, id types.Long) error
+	CreateTrainMembers(ctx context.Context, members []*domain.ReleaseTrainMember) error
+	UpdateTrainMember(ctx context.Context, member *domain.ReleaseTrainMember) error
+	ListTrainMembers(ctx context.Context, trainID types.Long) ([]*domain.ReleaseTrainMember, error)
+	GetTrainMemberByPlan(ctx context.Context, planID types.Long) (*domain.ReleaseTrainMember, error)
+
+	// 部署锁相关
+	CreateDeployLock(ctx context.Context, lock *domain.DeployLock) (bool, error)
+	UpdateDeployLock(ctx context.Context, lock *domain.DeployLock) error
+	GetDeployLock(ctx context.Context, appID, envID types.Long, kind string) (*domain.DeployLock, error)
+	TakeOverDeployLock(ctx context.Context, lock *domain.DeployLock, now time.Time) (bool, error)
+	RenewDeployLock(ctx context.Context, deploymentID types.Long, expiresAt time.Time) error
+	ReleaseDeployLock(ctx context.Context, deploymentID types.Long) error
+	DeleteDeployLock(ctx context.Context, id types.Long) error
+	ListDeployLocks(ctx context.Context, appID, envID types.Long) ([]*domain.DeployLock, error)
+	ListQueuedReleasePlans(ctx context.Context, appID, envID types.Long) ([]*domain.ReleasePlan, error)
+
+	// 配置漂移相关
+	ListLatestSuccessfulDeployments(ctx context.Context) ([]*domain.Deployment, error)
+	CreateDriftFinding(ctx context.Context, finding *domain.DriftFinding) error
+	UpdateDriftFinding(ctx context.Context, finding *domain.DriftFinding) error
+	ListOpenDriftFindings(ctx context.Context, appID, envID types.Long) ([]*domain.DriftFinding, error)
+	ListDriftFindings(ctx context.Context, query *domain.DriftQuery) ([]*domain.DriftFinding, int64, error)
 }
 
 type AppRepository struct {
 	repository.Repository
+	DataScope datascope.Resolver `inject:"DataScopeService"`
 }
 
 func NewAppRepository() *AppRepository {
@@ -109,9 +211,10 @@ func (r *AppRepository) GetApplicationByName(ctx context.Context, name string) (
 	return &app, nil
 }
 
-// ListApplications 查询应用列表
+// ListApplications 查询应用列表，按当前用户的数据权限过滤
 func (r *AppRepository) ListApplications(ctx context.Context, query *domain.AppQuery) ([]*domain.AppVO, int64, error) {
-	db := r.DB(ctx).Table("app").Select("app.*")
+	db := r.DB(ctx).Table("app").Select("app.*").
+		Scopes(datascope.Owned(ctx, r.DataScope, "app.dept_id", "app.creator"))
 
 	// 应用条件查询
 	if query.Name != "" {
@@ -347,13 +450,21 @@ func (r *AppRepository) GetDeploymentByID(ctx context.Context, id types.Long) (*
 		deployment.Steps = steps
 	}
 
+	// 获取Job运行记录
+	if deployment.Mode == enum.DeployModeJob {
+		runs, err := r.ListDeploymentJobRuns(ctx, deployment.ID)
+		if err == nil {
+			deployment.Runs = runs
+		}
+	}
+
 	return &deployment, nil
 }
 
-// ListDeployments 查询部署历史列表
+// ListDeployments 查询部署历史列表，仅包含数据权限范围内应用的记录
 func (r *AppRepository) ListDeployments(ctx context.Context, appID, envID types.Long) ([]*domain.Deployment, error) {
 	var deployments []*domain.Deployment
-	query := r.DB(ctx)
+	query := r.DB(ctx).Scopes(r.appDataScope(ctx, "app_id"))
 	if appID > 0 {
 		query = query.Where("app_id = ?", appID)
 	}
@@ -425,6 +536,14 @@ func (r *AppRepository) DeleteImageRegistry(ctx context.Context, id types.Long)
 	return r.DB(ctx).Delete(&domain.ImageRegistry{}, id).Error
 }
 
+// CreateAppImageRegistry 关联应用与镜像仓库
+func (r *AppRepository) CreateAppImageRegistry(ctx context.Context, link *domain.AppImageRegistry) (types.Long, error) {
+	if err := r.DB(ctx).Create(link).Error; err != nil {
+		return 0, err
+	}
+	return link.ID, nil
+}
+
 // CreateAppHPA 创建应用HPA
 func (r *AppRepository) CreateAppHPA(ctx context.Context, hpa *domain.AppHPA) (types.Long, error) {
 	if err := r.DB(ctx).Create(hpa).Error; err != nil {
@@ -433,21 +552,57 @@ func (r *AppRepository) CreateAppHPA(ctx context.Context, hpa *domain.AppHPA) (t
 	return hpa.ID, nil
 }
 
-// UpdateAppHPA 更新应用HPA
+// UpdateAppHPA 更新应用HPA，指标与伸缩行为可能被清空，因此保存全部字段
 func (r *AppRepository) UpdateAppHPA(ctx context.Context, hpa *domain.AppHPA) error {
-	return r.DB(ctx).Updates(hpa).Error
+	return r.DB(ctx).Save(hpa).Error
 }
 
-// GetAppHPAByAppID 根据应用ID获取HPA
+// GetAppHPAByAppID 根据应用ID获取应用默认HPA
 func (r *AppRepository) GetAppHPAByAppID(ctx context.Context, appID types.Long) (*domain.AppHPA, error) {
 	var hpa domain.AppHPA
-	if err := r.DB(ctx).Where("app_id = ?", appID).First(&hpa).Error; err != nil {
+	if err := r.DB(ctx).Where("app_id = ? AND env_id = 0", appID).First(&hpa).Error; err != nil {
+		return nil, err
+	}
+	return &hpa, nil
+}
+
+// GetAppHPA 获取应用在环境下的HPA，envID为0时获取应用默认配置，不存在时返回nil
+func (r *AppRepository) GetAppHPA(ctx context.Context, appID, envID types.Long) (*domain.AppHPA, error) {
+	var hpa domain.AppHPA
+	if err := r.DB(ctx).Where("app_id = ? AND env_id = ?", appID, envID).First(&hpa).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &hpa, nil
 }
 
+// ListAppHPAs 查询应用的全部HPA，应用默认配置在前
+func (r *AppRepository) ListAppHPAs(ctx context.Context, appID types.Long) ([]*domain.AppHPA, error) {
+	var hpas []*domain.AppHPA
+	err := r.DB(ctx).Where("app_id = ?", appID).Order("env_id ASC").Find(&hpas).Error
+	return hpas, err
+}
+
 // DeleteAppHPA 删除应用HPA
 func (r *AppRepository) DeleteAppHPA(ctx context.Context, id types.Long) error {
 	return r.DB(ctx).Delete(&domain.AppHPA{}, id).Error
 }
+
+// appDataScope 按数据权限过滤关联应用的记录，appIDColumn为记录中的应用ID列
+func (r *AppRepository) appDataScope(ctx context.Context, appIDColumn string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope, err := datascope.Current(ctx, r.DataScope)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		if scope.All {
+			return db
+		}
+		apps := r.DB(ctx).Table("app").Select("app.id").
+			Scopes(datascope.Owned(ctx, r.DataScope, "app.dept_id", "app.creator"))
+		return db.Where(appIDColumn+" IN (?)", apps)
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// CreateReleaseTrain 创建发布列车
+func (r *AppRepository) CreateReleaseTrain(ctx context.Context, train *domain.ReleaseTrain) (types.Long, error) {
+	if err := r.DB(ctx).Create(train).Error; err != nil {
+		return 0, err
+	}
+	return train.ID, nil
+}
+
+// UpdateReleaseTrain 更新发布列车，回滚标记可能被清除，因此保存全部字段
+func (r *AppRepository) UpdateReleaseTrain(ctx context.Context, train *domain.ReleaseTrain) error {
+	return r.DB(ctx).Save(train).Error
+}
+
+// GetReleaseTrain 获取发布列车，不存在时返回nil
+func (r *AppRepository) GetReleaseTrain(ctx context.Context, id types.Long) (*domain.ReleaseTrain, error) {
+	var train domain.ReleaseTrain
+	if err := r.DB(ctx).First(&train, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &train, nil
+}
+
+// ListReleaseTrains 分页查询发布列车，按创建时间倒序
+func (r *AppRepository) ListReleaseTrains(ctx context.Context, query *domain.TrainQuery) ([]*domain.ReleaseTrain, int64, error) {
+	db := r.DB(ctx).Model(&domain.ReleaseTrain{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var trains []*domain.ReleaseTrain
+	err := db.Order("id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&trains).Error
+	return trains, total, err
+}
+
+// ListActiveReleaseTrains 查询需要推进的发布列车：执行中的列车与尚未结束的中止列车
+func (r *AppRepository) ListActiveReleaseTrains(ctx context.Context) ([]*domain.ReleaseTrain, error) {
+	var trains []*domain.ReleaseTrain
+	err := r.DB(ctx).Where("status IN ? AND finished_at IS NULL",
+		[]string{domain.TrainStatusRunning, domain.TrainStatusAborted}).
+		Order("id ASC").Find(&trains).Error
+	return trains, err
+}
+
+// DeleteReleaseTrain 删除发布列车及其成员，调用方负责事务
+func (r *AppRepository) DeleteReleaseTrain(ctx context.Context, id types.Long) error {
+	if err := r.DB(ctx).Where("train_id = ?", id).Delete(&domain.ReleaseTrainMember{}).Error; err != nil {
+		return err
+	}
+	return r.DB(ctx).Delete(&domain.ReleaseTrain{}, id).Error
+}
+
+// CreateTrainMembers 批量创建发布列车成员
+func (r *AppRepository) CreateTrainMembers(ctx context.Context, members []*domain.ReleaseTrainMember) error {
+	return r.DB(ctx).Create(&members).Error
+}
+
+// UpdateTrainMember 更新发布列车成员
+func (r *AppRepository) UpdateTrainMember(ctx context.Context, member *domain.ReleaseTrainMember) error {
+	return r.DB(ctx).Save(member).Error
+}
+
+// ListTrainMembers 查询发布列车成员，按执行顺序排列
+func (r *AppRepository) ListTrainMembers(ctx context.Context, trainID types.Long) ([]*domain.ReleaseTrainMember, error) {
+	var members []*domain.ReleaseTrainMember
+	err := r.DB(ctx).Where("train_id = ?", trainID).Order("position ASC").Find(&members).Error
+	return members, err
+}
+
+// GetTrainMemberByPlan 获取发布计划所属的发布列车成员，不属于任何列车时返回nil
+func (r *AppRepository) GetTrainMemberByPlan(ctx context.Context, planID types.Long) (*domain.ReleaseTrainMember, error) {
+	var member domain.ReleaseTrainMember
+	if err := r.DB(ctx).Where("plan_id = ?", planID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
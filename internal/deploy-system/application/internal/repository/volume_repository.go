@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// CreateVolume 创建共享存储卷
+func (r *AppRepository) CreateVolume(ctx context.Context, volume *domain.AppVolume) (types.Long, error) {
+	if err := r.DB(ctx).Create(volume).Error; err != nil {
+		return 0, err
+	}
+	return volume.ID, nil
+}
+
+// UpdateVolume 更新共享存储卷
+func (r *AppRepository) UpdateVolume(ctx context.Context, volume *domain.AppVolume) error {
+	return r.DB(ctx).Save(volume).Error
+}
+
+// GetVolumeByID 根据ID获取共享存储卷，不存在时返回nil
+func (r *AppRepository) GetVolumeByID(ctx context.Context, id types.Long) (*domain.AppVolume, error) {
+	var volume domain.AppVolume
+	if err := r.DB(ctx).First(&volume, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &volume, nil
+}
+
+// GetVolumeByName 根据名称获取共享存储卷，不存在时返回nil
+func (r *AppRepository) GetVolumeByName(ctx context.Context, name string) (*domain.AppVolume, error) {
+	var volume domain.AppVolume
+	if err := r.DB(ctx).Where("name = ?", name).First(&volume).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &volume, nil
+}
+
+// ListVolumes 查询共享存储卷，envID不为0时只返回该环境可用的卷
+func (r *AppRepository) ListVolumes(ctx context.Context, envID types.Long) ([]*domain.AppVolume, error) {
+	db := r.DB(ctx)
+	if envID > 0 {
+		db = db.Where("env_id = 0 OR env_id = ?", envID)
+	}
+	var volumes []*domain.AppVolume
+	err := db.Order("name").Find(&volumes).Error
+	return volumes, err
+}
+
+// ListVolumesByIDs 根据ID批量获取共享存储卷
+func (r *AppRepository) ListVolumesByIDs(ctx context.Context, ids []types.Long) ([]*domain.AppVolume, error) {
+	var volumes []*domain.AppVolume
+	if len(ids) == 0 {
+		return volumes, nil
+	}
+	err := r.DB(ctx).Where("id IN ?", ids).Find(&volumes).Error
+	return volumes, err
+}
+
+// DeleteVolume 删除共享存储卷
+func (r *AppRepository) DeleteVolume(ctx context.Context, id types.Long) error {
+	return r.DB(ctx).Delete(&domain.AppVolume{}, id).Error
+}
+
+// CreateVolumeMount 创建应用存储卷挂载
+func (r *AppRepository) CreateVolumeMount(ctx context.Context, mount *domain.AppVolumeMount) (types.Long, error) {
+	if err := r.DB(ctx).Create(mount).Error; err != nil {
+		return 0, err
+	}
+	return mount.ID, nil
+}
+
+// UpdateVolumeMount 更新应用存储卷挂载
+func (r *AppRepository) UpdateVolumeMount(ctx context.Context, mount *domain.AppVolumeMount) error {
+	return r.DB(ctx).Save(mount).Error
+}
+
+// GetVolumeMountByID 根据ID获取应用存储卷挂载，不存在时返回nil
+func (r *AppRepository) GetVolumeMountByID(ctx context.Context, id types.Long) (*domain.AppVolumeMount, error) {
+	var mount domain.AppVolumeMount
+	if err := r.DB(ctx).First(&mount, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &mount, nil
+}
+
+// ListVolumeMounts 查询应用的存储卷挂载，envID为0时返回所有环境
+func (r *AppRepository) ListVolumeMounts(ctx context.Context, appID, envID types.Long) ([]*domain.AppVolumeMount, error) {
+	db := r.DB(ctx).Where("app_id = ?", appID)
+	if envID > 0 {
+		db = db.Where("env_id = ?", envID)
+	}
+	var mounts []*domain.AppVolumeMount
+	err := db.Order("env_id, mount_path").Find(&mounts).Error
+	return mounts, err
+}
+
+// ListVolumeMountsByVolume 查询挂载了指定存储卷的所有应用挂载
+func (r *AppRepository) ListVolumeMountsByVolume(ctx context.Context, volumeID types.Long) ([]*domain.AppVolumeMount, error) {
+	var mounts []*domain.AppVolumeMount
+	err := r.DB(ctx).Where("volume_id = ?", volumeID).Find(&mounts).Error
+	return mounts, err
+}
+
+// DeleteVolumeMount 删除应用存储卷挂载
+func (r *AppRepository) DeleteVolumeMount(ctx context.Context, id types.Long) error {
+	return r.DB(ctx).Delete(&domain.AppVolumeMount{}, id).Error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// CreateWorkloadSpec 创建工作负载定义版本，版本创建后不再修改
+func (r *AppRepository) CreateWorkloadSpec(ctx context.Context, spec *domain.AppWorkloadSpec) (types.Long, error) {
+	if err := r.DB(ctx).Create(spec).Error; err != nil {
+		return 0, err
+	}
+	return spec.ID, nil
+}
+
+// GetWorkloadSpec 获取应用的指定工作负载定义版本，不存在时返回nil
+func (r *AppRepository) GetWorkloadSpec(ctx context.Context, appID types.Long, revision int) (*domain.AppWorkloadSpec, error) {
+	var spec domain.AppWorkloadSpec
+	if err := r.DB(ctx).Where("app_id = ? AND revision = ?", appID, revision).First(&spec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// GetLatestWorkloadSpec 获取应用的最新工作负载定义，没有定义时返回nil
+func (r *AppRepository) GetLatestWorkloadSpec(ctx context.Context, appID types.Long) (*domain.AppWorkloadSpec, error) {
+	var spec domain.AppWorkloadSpec
+	if err := r.DB(ctx).Where("app_id = ?", appID).Order("revision DESC").First(&spec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// ListWorkloadSpecs 分页查询应用的工作负载定义版本，新版本在前
+func (r *AppRepository) ListWorkloadSpecs(ctx context.Context, query *domain.WorkloadSpecQuery) ([]*domain.AppWorkloadSpec, int64, error) {
+	db := r.DB(ctx).Model(&domain.AppWorkloadSpec{}).Where("app_id = ?", query.AppID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var specs []*domain.AppWorkloadSpec
+	err := db.Order("revision DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&specs).Error
+	return specs, total, err
+}
+
+// GetWorkloadOverride 获取应用在环境下的工作负载覆盖，不存在时返回nil
+func (r *AppRepository) GetWorkloadOverride(ctx context.Context, appID, envID types.Long) (*domain.AppWorkloadOverride, error) {
+	var override domain.AppWorkloadOverride
+	if err := r.DB(ctx).Where("app_id = ? AND env_id = ?", appID, envID).First(&override).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &override, nil
+}
+
+// SaveWorkloadOverride 创建或更新工作负载覆盖
+func (r *AppRepository) SaveWorkloadOverride(ctx context.Context, override *domain.AppWorkloadOverride) error {
+	return r.DB(ctx).Save(override).Error
+}
+
+// DeleteWorkloadOverride 删除工作负载覆盖
+func (r *AppRepository) DeleteWorkloadOverride(ctx context.Context, id types.Long) error {
+	return r.DB(ctx).Delete(&domain.AppWorkloadOverride{}, id).Error
+}
@@ -6,6 +6,8 @@ import (
 	"devops-platform/internal/deploy-system/application/internal/domain"
 	"devops-platform/internal/deploy-system/application/internal/repository"
 	"devops-platform/pkg/types"
+
+	"github.com/sirupsen/logrus"
 )
 
 // AppQuery 应用查询服务实现
@@ -18,6 +20,16 @@ func NewAppQuery() *AppQuery {
 	return &AppQuery{}
 }
 
+// Inject 注入仓储
+func (q *AppQuery) Inject(getBean func(string) interface{}) {
+	repo, ok := getBean(domain.BeanAppRepository).(repository.Repository)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", domain.BeanAppRepository)
+		return
+	}
+	q.repo = repo
+}
+
 // GetApplicationByID 根据ID获取应用
 func (q *AppQuery) GetApplicationByID(ctx context.Context, id types.Long) (*domain.Application, error) {
 	return q.repo.GetApplicationByID(ctx, id)
@@ -88,7 +100,12 @@ func (q *AppQuery) ListImageRegistries(ctx context.Context) ([]*domain.ImageRegi
 	return q.repo.ListImageRegistries(ctx)
 }
 
-// GetAppHPA 获取应用HPA配置
+// GetAppHPA 获取应用默认HPA配置
 func (q *AppQuery) GetAppHPA(ctx context.Context, appID types.Long) (*domain.AppHPA, error) {
-	return q.repo.GetAppHPAByAppID(ctx, appID)
+	hpa, err := q.repo.GetAppHPAByAppID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	hpa.Normalize()
+	return hpa, nil
 }
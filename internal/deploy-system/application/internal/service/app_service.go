@@ -8,14 +8,16 @@ import (
 
 	"devops-platform/internal/deploy-system/application/internal/domain"
 	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/security"
 	"devops-platform/pkg/types"
 )
 
 // AppService 应用管理服务实现
 type AppService struct {
 	service.Service
-	Repo   *repository.AppRepository
-	Logger *logrus.Logger
+	Repo    *repository.AppRepository `inject:"ApplicationRepository"`
+	Members *MemberService            `inject:"memberService"`
+	Logger  *logrus.Logger            `inject:"Logger"`
 }
 
 // NewAppService 创建应用管理服务实例
@@ -30,6 +32,11 @@ func (s *AppService) CreateApplication(ctx context.Context, command *domain.Crea
 	if err == nil && existApp != nil {
 		return 0, errors.New("应用名称已存在")
 	}
+	if command.DeptID > 0 {
+		if err = s.Members.CheckDepartment(ctx, command.DeptID); err != nil {
+			return 0, err
+		}
+	}
 
 	ctx, err = s.BeginTransaction(ctx, "create service app")
 	if err != nil {
@@ -38,15 +45,30 @@ func (s *AppService) CreateApplication(ctx context.Context, command *domain.Crea
 	defer func() {
 		err = s.FinishTransaction(ctx, err, "create service app")
 	}()
-	// 创建应用
+	// 创建应用，未指定创建人与所属部门时取当前用户，创建人登记为应用负责人
 	app := &domain.Application{
 		Name:        command.Name,
 		Description: command.Description,
 		Creator:     command.Creator,
+		DeptID:      command.DeptID,
 		Status:      domain.AppStatusActive,
 	}
+	if user := security.GetUserContext(ctx); user != nil {
+		if app.Creator == 0 {
+			app.Creator = user.UserID
+		}
+		if app.DeptID == 0 {
+			app.DeptID = user.DeptID
+		}
+	}
 
-	return s.Repo.CreateApplication(ctx, app)
+	if id, err = s.Repo.CreateApplication(ctx, app); err != nil {
+		return 0, err
+	}
+	if app.Creator != 0 {
+		err = s.Members.AddOwner(ctx, id, app.Creator)
+	}
+	return id, err
 }
 
 // UpdateApplication 更新应用
@@ -73,6 +95,16 @@ func (s *AppService) UpdateApplication(ctx context.Context, command *domain.Upda
 	if command.Status != "" {
 		app.Status = command.Status
 	}
+	// 变更所属部门需要应用负责人角色
+	if command.DeptID > 0 && command.DeptID != app.DeptID {
+		if err = s.Members.Authorize(ctx, app.ID, domain.AppRoleOwner); err != nil {
+			return err
+		}
+		if err = s.Members.CheckDepartment(ctx, command.DeptID); err != nil {
+			return err
+		}
+		app.DeptID = command.DeptID
+	}
 	ctx, err = s.BeginTransaction(ctx, "create service app")
 	if err != nil {
 		return
@@ -93,8 +125,11 @@ func (s *AppService) ListApplications(ctx context.Context, query *domain.AppQuer
 	return s.Repo.ListApplications(ctx, query)
 }
 
-// DeleteApplication 删除应用
+// DeleteApplication 删除应用，需要应用负责人角色
 func (s *AppService) DeleteApplication(ctx context.Context, id types.Long) (err error) {
+	if err = s.Members.Authorize(ctx, id, domain.AppRoleOwner); err != nil {
+		return err
+	}
 	ctx, err = s.BeginTransaction(ctx, "create service app")
 	if err != nil {
 		return
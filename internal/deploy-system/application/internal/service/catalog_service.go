@@ -0,0 +1,601 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CatalogService 应用目录服务：批量导入导出应用分组、环境、应用及其分组、镜像仓库关联与默认HPA
+type CatalogService struct {
+	service.Service
+	Repo    *repository.AppRepository `inject:"ApplicationRepository"`
+	Members *MemberService            `inject:"memberService"`
+}
+
+// NewCatalogService 创建应用目录服务实例
+func NewCatalogService() *CatalogService {
+	return &CatalogService{}
+}
+
+// ExportCatalog 导出应用目录：全部分组与环境，以及当前用户有数据权限的未删除应用
+func (s *CatalogService) ExportCatalog(ctx context.Context) (*domain.CatalogDocument, error) {
+	state, err := s.loadCatalogState(ctx)
+	if err != nil {
+		return nil, common.InternalError("加载应用目录失败", err)
+	}
+	apps, err := s.Repo.ListScopedApplications(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询应用失败", err)
+	}
+
+	doc := &domain.CatalogDocument{
+		Version: domain.CatalogDocumentVersion,
+		Groups:  make([]*domain.CatalogGroup, 0, len(state.groups)),
+		Envs:    make([]*domain.CatalogEnv, 0, len(state.envs)),
+		Apps:    make([]*domain.CatalogApp, 0, len(apps)),
+	}
+	for _, group := range state.groups {
+		doc.Groups = append(doc.Groups, &domain.CatalogGroup{Name: group.Name, Description: group.Description})
+	}
+	sort.Slice(doc.Groups, func(i, j int) bool { return doc.Groups[i].Name < doc.Groups[j].Name })
+	for _, env := range state.envs {
+		doc.Envs = append(doc.Envs, &domain.CatalogEnv{
+			Name:        env.Name,
+			ClusterID:   env.ClusterID,
+			Namespace:   env.Namespace,
+			Description: env.Description,
+		})
+	}
+	sort.Slice(doc.Envs, func(i, j int) bool { return doc.Envs[i].Name < doc.Envs[j].Name })
+
+	for _, app := range apps {
+		item := &domain.CatalogApp{Name: app.Name, Description: app.Description, Status: app.Status}
+		for groupID := range state.appGroups[app.ID] {
+			if name, ok := state.groupNames[groupID]; ok {
+				item.Groups = append(item.Groups, name)
+			}
+		}
+		sort.Strings(item.Groups)
+		for registryID := range state.appRegistries[app.ID] {
+			if name, ok := state.registryNames[registryID]; ok {
+				item.Registries = append(item.Registries, name)
+			}
+		}
+		sort.Strings(item.Registries)
+		if hpa := state.hpas[app.ID]; hpa != nil {
+			item.HPA = domain.NewCatalogHPA(hpa)
+		}
+		doc.Apps = append(doc.Apps, item)
+	}
+	return doc, nil
+}
+
+// ImportCatalog 导入应用目录，整个文件为一批：先校验全部记录并生成导入计划，
+// 任一行出错或试运行时不写入；否则在一个事务中按计划导入
+func (s *CatalogService) ImportCatalog(ctx context.Context, command *domain.ImportCatalogCommand, data []byte) (result *domain.CatalogImportResultVO, err error) {
+	if command.Format == "" {
+		command.Format = domain.CatalogFormatYAML
+	}
+	if command.Mode == "" {
+		command.Mode = domain.CatalogModeCreate
+	}
+	doc, rowErrors, err := domain.ParseCatalog(command.Format, data)
+	if err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+	// 无法解析的CSV行也计入记录数
+	total := doc.Total() + len(rowErrors)
+	rowErrors = append(rowErrors, doc.Validate()...)
+
+	state, err := s.loadCatalogState(ctx)
+	if err != nil {
+		return nil, common.InternalError("加载应用目录失败", err)
+	}
+	plan := &catalogImporter{repo: s.Repo, members: s.Members, state: state, mode: command.Mode}
+	if err = plan.run(ctx, doc); err != nil {
+		return nil, common.InternalError("生成导入计划失败", err)
+	}
+	rowErrors = append(rowErrors, plan.errors...)
+	sortCatalogRowErrors(rowErrors)
+	result = domain.NewCatalogImportResultVO(command, total, plan.changes, rowErrors)
+	if len(rowErrors) > 0 || command.DryRun {
+		return result, nil
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "import app catalog")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "import app catalog")
+	}()
+
+	// 在事务中重新加载，按最新的数据导入
+	if state, err = s.loadCatalogState(ctx); err != nil {
+		return nil, common.InternalError("加载应用目录失败", err)
+	}
+	importer := &catalogImporter{repo: s.Repo, members: s.Members, state: state, mode: command.Mode, apply: true}
+	if err = importer.run(ctx, doc); err != nil {
+		return nil, common.InternalError("导入应用目录失败", err)
+	}
+	result = domain.NewCatalogImportResultVO(command, total, importer.changes, importer.errors)
+	if len(importer.errors) > 0 {
+		return nil, common.ConflictError("应用目录在导入期间被修改，整批未导入，请重新导入", result)
+	}
+	result.Applied = true
+	logrus.WithFields(logrus.Fields{
+		"mode":   command.Mode,
+		"create": result.Summary[domain.CatalogActionCreate],
+		"update": result.Summary[domain.CatalogActionUpdate],
+	}).Info("应用目录导入完成")
+	return result, nil
+}
+
+// catalogState 按名称索引的分组、环境、应用、镜像仓库，及应用的分组、镜像仓库关联与默认HPA。
+// apps只含当前用户有数据权限的未删除应用，names含全部应用（包括已删除的），用于检查名称是否被占用
+type catalogState struct {
+	apps          map[string]*domain.Application
+	names         map[string]*domain.Application
+	groups        map[string]*domain.AppGroup
+	groupNames    map[types.Long]string
+	envs          map[string]*domain.AppEnv
+	registries    map[string]*domain.ImageRegistry
+	registryNames map[types.Long]string
+	appGroups     map[types.Long]map[types.Long]bool
+	appRegistries map[types.Long]map[types.Long]bool
+	hpas          map[types.Long]*domain.AppHPA
+}
+
+// loadCatalogState 加载当前的应用目录，同名环境以先创建的为准
+func (s *CatalogService) loadCatalogState(ctx context.Context) (*catalogState, error) {
+	state := &catalogState{
+		apps:          make(map[string]*domain.Application),
+		names:         make(map[string]*domain.Application),
+		groups:        make(map[string]*domain.AppGroup),
+		groupNames:    make(map[types.Long]string),
+		envs:          make(map[string]*domain.AppEnv),
+		registries:    make(map[string]*domain.ImageRegistry),
+		registryNames: make(map[types.Long]string),
+		appGroups:     make(map[types.Long]map[types.Long]bool),
+		appRegistries: make(map[types.Long]map[types.Long]bool),
+		hpas:          make(map[types.Long]*domain.AppHPA),
+	}
+	apps, err := s.Repo.ListAllApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range apps {
+		state.names[app.Name] = app
+	}
+	if apps, err = s.Repo.ListScopedApplications(ctx); err != nil {
+		return nil, err
+	}
+	for _, app := range apps {
+		state.apps[app.Name] = app
+	}
+	groups, err := s.Repo.ListAppGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		state.groups[group.Name] = group
+		state.groupNames[group.ID] = group.Name
+	}
+	envs, err := s.Repo.ListAppEnvs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range envs {
+		if current, ok := state.envs[env.Name]; !ok || env.ID < current.ID {
+			state.envs[env.Name] = env
+		}
+	}
+	registries, err := s.Repo.ListImageRegistries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, registry := range registries {
+		state.registries[registry.Name] = registry
+		state.registryNames[registry.ID] = registry.Name
+	}
+	relations, err := s.Repo.ListAppGroupRelations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, relation := range relations {
+		addCatalogLink(state.appGroups, relation.AppID, relation.GroupID)
+	}
+	links, err := s.Repo.ListAppImageRegistries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		addCatalogLink(state.appRegistries, link.AppID, link.RegistryID)
+	}
+	hpas, err := s.Repo.ListDefaultAppHPAs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, hpa := range hpas {
+		state.hpas[hpa.AppID] = hpa
+	}
+	return state, nil
+}
+
+func addCatalogLink(links map[types.Long]map[types.Long]bool, appID, targetID types.Long) {
+	if links[appID] == nil {
+		links[appID] = make(map[types.Long]bool)
+	}
+	links[appID][targetID] = true
+}
+
+// catalogImporter 按文档逐行导入，apply为false时只生成导入计划与行错误，不写入。
+// 导入计划中新建的记录ID为0，只用于后续行按名称引用
+type catalogImporter struct {
+	repo    *repository.AppRepository
+	members *MemberService
+	state   *catalogState
+	mode    string
+	apply   bool
+	changes []*domain.CatalogChange
+	errors  []*domain.CatalogRowError
+}
+
+// run 依次导入分组、环境与应用，返回的错误只有数据库错误，记录的问题作为行错误
+func (i *catalogImporter) run(ctx context.Context, doc *domain.CatalogDocument) error {
+	for _, group := range doc.Groups {
+		if group.Name == "" {
+			continue
+		}
+		if err := i.importGroup(ctx, group); err != nil {
+			return err
+		}
+	}
+	for _, env := range doc.Envs {
+		if env.Name == "" {
+			continue
+		}
+		if err := i.importEnv(ctx, env); err != nil {
+			return err
+		}
+	}
+	for _, app := range doc.Apps {
+		if app.Name == "" {
+			continue
+		}
+		if err := i.importApp(ctx, app); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *catalogImporter) fail(row int, kind, name, message string) {
+	i.errors = append(i.errors, &domain.CatalogRowError{Row: row, Kind: kind, Name: name, Message: message})
+}
+
+func (i *catalogImporter) record(row int, kind, name, action string, details []string) {
+	i.changes = append(i.changes, &domain.CatalogChange{
+		Row:    row,
+		Kind:   kind,
+		Name:   name,
+		Action: action,
+		Detail: strings.Join(details, "；"),
+	})
+}
+
+func (i *catalogImporter) importGroup(ctx context.Context, item *domain.CatalogGroup) error {
+	group, ok := i.state.groups[item.Name]
+	if !ok {
+		group = &domain.AppGroup{Name: item.Name, Description: item.Description}
+		if i.apply {
+			group.AuditCreated(ctx)
+			if _, err := i.repo.CreateAppGroup(ctx, group); err != nil {
+				return err
+			}
+		}
+		i.state.groups[item.Name] = group
+		i.record(item.Row, domain.CatalogKindGroup, item.Name, domain.CatalogActionCreate, nil)
+		return nil
+	}
+	if i.mode == domain.CatalogModeCreate {
+		i.fail(item.Row, domain.CatalogKindGroup, item.Name, "分组已存在")
+		return nil
+	}
+
+	var details []string
+	if item.Description != "" && item.Description != group.Description {
+		details = append(details, "更新描述")
+		group.Description = item.Description
+	}
+	if len(details) == 0 {
+		i.record(item.Row, domain.CatalogKindGroup, item.Name, domain.CatalogActionUnchanged, nil)
+		return nil
+	}
+	if i.apply {
+		group.AuditModified(ctx)
+		if err := i.repo.UpdateAppGroup(ctx, group); err != nil {
+			return err
+		}
+	}
+	i.record(item.Row, domain.CatalogKindGroup, item.Name, domain.CatalogActionUpdate, details)
+	return nil
+}
+
+func (i *catalogImporter) importEnv(ctx context.Context, item *domain.CatalogEnv) error {
+	env, ok := i.state.envs[item.Name]
+	if !ok {
+		env = &domain.AppEnv{
+			Name:        item.Name,
+			ClusterID:   item.ClusterID,
+			Namespace:   item.Namespace,
+			Description: item.Description,
+		}
+		if i.apply {
+			env.AuditCreated(ctx)
+			if _, err := i.repo.CreateAppEnv(ctx, env); err != nil {
+				return err
+			}
+		}
+		i.state.envs[item.Name] = env
+		i.record(item.Row, domain.CatalogKindEnv, item.Name, domain.CatalogActionCreate, nil)
+		return nil
+	}
+	if i.mode == domain.CatalogModeCreate {
+		i.fail(item.Row, domain.CatalogKindEnv, item.Name, "环境已存在")
+		return nil
+	}
+
+	var details []string
+	if item.ClusterID != env.ClusterID {
+		details = append(details, fmt.Sprintf("集群ID %s → %s", env.ClusterID, item.ClusterID))
+		env.ClusterID = item.ClusterID
+	}
+	if item.Namespace != env.Namespace {
+		details = append(details, fmt.Sprintf("命名空间 %s → %s", env.Namespace, item.Namespace))
+		env.Namespace = item.Namespace
+	}
+	if item.Description != "" && item.Description != env.Description {
+		details = append(details, "更新描述")
+		env.Description = item.Description
+	}
+	if len(details) == 0 {
+		i.record(item.Row, domain.CatalogKindEnv, item.Name, domain.CatalogActionUnchanged, nil)
+		return nil
+	}
+	if i.apply {
+		env.AuditModified(ctx)
+		if err := i.repo.UpdateAppEnv(ctx, env); err != nil {
+			return err
+		}
+	}
+	i.record(item.Row, domain.CatalogKindEnv, item.Name, domain.CatalogActionUpdate, details)
+	return nil
+}
+
+// importApp 创建或更新应用，按名称关联分组与镜像仓库；已有的关联不会移除
+func (i *catalogImporter) importApp(ctx context.Context, item *domain.CatalogApp) error {
+	failed := len(i.errors)
+	fail := func(message string) {
+		i.fail(item.Row, domain.CatalogKindApp, item.Name, message)
+	}
+	groups := make([]*domain.AppGroup, 0, len(item.Groups))
+	seen := make(map[string]bool, len(item.Groups)+len(item.Registries))
+	for _, name := range item.Groups {
+		if seen["group/"+name] {
+			continue
+		}
+		seen["group/"+name] = true
+		if group, ok := i.state.groups[name]; ok {
+			groups = append(groups, group)
+		} else {
+			fail("分组不存在: " + name)
+		}
+	}
+	registries := make([]*domain.ImageRegistry, 0, len(item.Registries))
+	for _, name := range item.Registries {
+		if seen["registry/"+name] {
+			continue
+		}
+		seen["registry/"+name] = true
+		if registry, ok := i.state.registries[name]; ok {
+			registries = append(registries, registry)
+		} else {
+			fail("镜像仓库不存在: " + name)
+		}
+	}
+
+	// 只匹配有数据权限的应用，更新应用需要应用负责人角色
+	app, exists := i.state.apps[item.Name]
+	switch {
+	case !exists:
+		if other, ok := i.state.names[item.Name]; ok && other.Status == domain.AppStatusDeleted {
+			fail("同名应用已删除，不能导入")
+		} else if ok {
+			fail("同名应用已存在，且不在数据权限范围内")
+		}
+	case i.mode == domain.CatalogModeCreate:
+		fail("应用已存在")
+	default:
+		if err := i.members.Authorize(ctx, app.ID, domain.AppRoleOwner); err != nil {
+			var forbidden *common.Error
+			if !errors.As(err, &forbidden) || forbidden.Type != common.ErrorTypeForbidden {
+				return err
+			}
+			fail(forbidden.Message)
+			break
+		}
+		if item.HPA != nil {
+			spec, err := i.repo.GetLatestWorkloadSpec(ctx, app.ID)
+			if err != nil {
+				return err
+			}
+			if spec != nil && spec.Spec.Mode == enum.DeployModeJob {
+				fail("Job部署方式的应用不能配置HPA")
+			}
+		}
+	}
+	if len(i.errors) > failed {
+		return nil
+	}
+
+	action := domain.CatalogActionCreate
+	var details []string
+	if exists {
+		action = domain.CatalogActionUpdate
+		details = i.updateApp(app, item)
+		if len(details) > 0 && i.apply {
+			app.AuditModified(ctx)
+			if err := i.repo.UpdateApplication(ctx, app); err != nil {
+				return err
+			}
+		}
+	} else {
+		app = &domain.Application{Name: item.Name, Description: item.Description, Status: item.Status}
+		if app.Status == "" {
+			app.Status = domain.AppStatusActive
+		}
+		if user := security.GetUserContext(ctx); user != nil {
+			app.Creator, app.DeptID = user.UserID, user.DeptID
+		}
+		if i.apply {
+			app.AuditCreated(ctx)
+			if _, err := i.repo.CreateApplication(ctx, app); err != nil {
+				return err
+			}
+			if app.Creator != 0 {
+				if err := i.members.AddOwner(ctx, app.ID, app.Creator); err != nil {
+					return err
+				}
+			}
+		}
+		i.state.apps[item.Name] = app
+		i.state.names[item.Name] = app
+	}
+
+	// 新建的应用在导入计划中ID为0，没有已有的关联
+	var added []string
+	for _, group := range groups {
+		if exists && i.state.appGroups[app.ID][group.ID] {
+			continue
+		}
+		if i.apply {
+			if err := i.repo.AddAppToGroup(ctx, app.ID, group.ID); err != nil {
+				return err
+			}
+		}
+		added = append(added, group.Name)
+	}
+	if len(added) > 0 {
+		details = append(details, "加入分组 "+strings.Join(added, "、"))
+	}
+	added = nil
+	for _, registry := range registries {
+		if exists && i.state.appRegistries[app.ID][registry.ID] {
+			continue
+		}
+		if i.apply {
+			link := &domain.AppImageRegistry{AppID: app.ID, RegistryID: registry.ID}
+			link.AuditCreated(ctx)
+			if _, err := i.repo.CreateAppImageRegistry(ctx, link); err != nil {
+				return err
+			}
+		}
+		added = append(added, registry.Name)
+	}
+	if len(added) > 0 {
+		details = append(details, "关联镜像仓库 "+strings.Join(added, "、"))
+	}
+
+	if item.HPA != nil {
+		detail, err := i.saveHPA(ctx, app.ID, exists, item.HPA)
+		if err != nil {
+			return err
+		}
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+
+	if exists && len(details) == 0 {
+		action = domain.CatalogActionUnchanged
+	}
+	i.record(item.Row, domain.CatalogKindApp, item.Name, action, details)
+	return nil
+}
+
+// updateApp 按记录更新应用的描述与状态，返回变更说明
+func (i *catalogImporter) updateApp(app *domain.Application, item *domain.CatalogApp) []string {
+	var details []string
+	if item.Description != "" && item.Description != app.Description {
+		details = append(details, "更新描述")
+		app.Description = item.Description
+	}
+	if item.Status != "" && item.Status != app.Status {
+		details = append(details, fmt.Sprintf("状态 %s → %s", app.Status, item.Status))
+		app.Status = item.Status
+	}
+	return details
+}
+
+// saveHPA 创建或更新应用默认HPA，配置未变化时返回空说明
+func (i *catalogImporter) saveHPA(ctx context.Context, appID types.Long, exists bool, item *domain.CatalogHPA) (string, error) {
+	command := item.SaveCommand(appID)
+	detail := fmt.Sprintf("HPA %d-%d", command.MinReplicas, command.MaxReplicas)
+	hpa := i.state.hpas[appID]
+	if !exists || hpa == nil {
+		hpa = &domain.AppHPA{}
+		command.ApplyTo(hpa)
+		if i.apply {
+			hpa.AuditCreated(ctx)
+			if _, err := i.repo.CreateAppHPA(ctx, hpa); err != nil {
+				return "", err
+			}
+		}
+		return "配置" + detail, nil
+	}
+
+	hpa.Normalize()
+	current := catalogHPAChecksum(hpa)
+	command.ApplyTo(hpa)
+	if catalogHPAChecksum(hpa) == current {
+		return "", nil
+	}
+	if i.apply {
+		hpa.AuditModified(ctx)
+		if err := i.repo.UpdateAppHPA(ctx, hpa); err != nil {
+			return "", err
+		}
+	}
+	return "更新" + detail, nil
+}
+
+// catalogHPAChecksum HPA的副本数范围、伸缩指标与伸缩行为，用于判断配置是否变化
+func catalogHPAChecksum(hpa *domain.AppHPA) string {
+	data, _ := json.Marshal([]interface{}{hpa.MinReplicas, hpa.MaxReplicas, hpa.Metrics, hpa.Behavior})
+	return string(data)
+}
+
+// sortCatalogRowErrors 按分组、环境、应用的顺序与行号排序，无法解析的CSV行在最前
+func sortCatalogRowErrors(rowErrors []*domain.CatalogRowError) {
+	order := map[string]int{domain.CatalogKindGroup: 1, domain.CatalogKindEnv: 2, domain.CatalogKindApp: 3}
+	sort.SliceStable(rowErrors, func(a, b int) bool {
+		if order[rowErrors[a].Kind] != order[rowErrors[b].Kind] {
+			return order[rowErrors[a].Kind] < order[rowErrors[b].Kind]
+		}
+		return rowErrors[a].Row < rowErrors[b].Row
+	})
+}
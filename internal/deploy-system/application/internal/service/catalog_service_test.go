@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/security"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ownAppsResolver 只允许访问本人创建的应用
+type ownAppsResolver struct{}
+
+func (ownAppsResolver) ResolveDataScope(_ context.Context, user *security.UserContext) (*security.DataScope, error) {
+	return &security.DataScope{UserID: user.UserID, OwnApps: true}, nil
+}
+
+// newCatalogTest 使用内存SQLite创建应用目录服务，写入用户9创建的应用order（开发者）与billing（负责人），
+// 以及用户1创建的应用pay
+func newCatalogTest(t *testing.T) *CatalogService {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err = db.AutoMigrate(&domain.Application{}, &domain.AppGroup{}, &domain.AppGroupRelation{}, &domain.AppEnv{},
+		&domain.ImageRegistry{}, &domain.AppImageRegistry{}, &domain.AppHPA{}, &domain.AppMember{},
+		&domain.AppWorkloadSpec{}); err != nil {
+		t.Fatal(err)
+	}
+	seed := []interface{}{
+		&domain.Application{ID: 1, Name: "order", Status: domain.AppStatusActive, Creator: 9},
+		&domain.Application{ID: 2, Name: "pay", Status: domain.AppStatusActive, Creator: 1},
+		&domain.Application{ID: 3, Name: "billing", Status: domain.AppStatusActive, Creator: 9},
+		&domain.AppMember{AppID: 1, UserID: 9, Role: domain.AppRoleDeveloper},
+		&domain.AppMember{AppID: 3, UserID: 9, Role: domain.AppRoleOwner},
+	}
+	for _, record := range seed {
+		if err = db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := repository.NewAppRepository()
+	repo.Inject(func(string) interface{} { return db })
+	repo.DataScope = ownAppsResolver{}
+	return &CatalogService{Repo: repo, Members: &MemberService{Repo: repo}}
+}
+
+func TestImportCatalogUpsertChecksScopeAndOwner(t *testing.T) {
+	service := newCatalogTest(t)
+	ctx := security.SetUserContext(context.Background(), &security.UserContext{UserID: 9, Username: "alice"})
+	data := []byte(`{"apps": [
+		{"name": "order", "description": "订单"},
+		{"name": "pay", "description": "支付"},
+		{"name": "billing", "description": "账单"}
+	]}`)
+
+	result, err := service.ImportCatalog(ctx, &domain.ImportCatalogCommand{
+		Format: domain.CatalogFormatJSON, Mode: domain.CatalogModeUpsert, DryRun: true}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rowErrors := make(map[string]string)
+	for _, rowError := range result.Errors {
+		rowErrors[rowError.Name] = rowError.Message
+	}
+	if len(rowErrors) != 2 || rowErrors["order"] == "" || rowErrors["pay"] == "" {
+		t.Fatalf("expected row errors for order and pay, got %v", rowErrors)
+	}
+	if rowErrors["pay"] != "同名应用已存在，且不在数据权限范围内" {
+		t.Errorf("expected pay outside data scope, got %s", rowErrors["pay"])
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Name != "billing" || result.Changes[0].Action != domain.CatalogActionUpdate {
+		t.Errorf("expected billing to be updated by its owner, got %+v", result.Changes)
+	}
+}
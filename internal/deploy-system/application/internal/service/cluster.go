@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/kube"
+)
+
+// ClusterClient 应用模块访问环境所在集群的接口，未接入集群时返回 kube.ErrNotInCluster
+type ClusterClient interface {
+	// PVCExists 判断环境命名空间中是否存在指定的PVC
+	PVCExists(ctx context.Context, env *domain.AppEnv, name string) (bool, error)
+	// CreateJob 在环境命名空间中创建Job
+	CreateJob(ctx context.Context, env *domain.AppEnv, job *domain.KubeJob) error
+	// GetJob 获取Job及其状态
+	GetJob(ctx context.Context, env *domain.AppEnv, name string) (*domain.KubeJob, error)
+	// ListJobs 按标签选择器查询Job
+	ListJobs(ctx context.Context, env *domain.AppEnv, selector string) ([]*domain.KubeJob, error)
+	// ApplyCronJob 创建或更新CronJob
+	ApplyCronJob(ctx context.Context, env *domain.AppEnv, cronJob *domain.KubeCronJob) error
+	// GetJobResult 获取Job最后创建的Pod的退出码与日志末尾，没有Pod时返回nil
+	GetJobResult(ctx context.Context, env *domain.AppEnv, jobName string, tailLines int) (*domain.JobPodResult, error)
+	// GetDeployment 获取Deployment，不存在时返回nil
+	GetDeployment(ctx context.Context, env *domain.AppEnv, name string) (*domain.KubeDeployment, error)
+	// GetHPA 获取HorizontalPodAutoscaler，不存在时返回nil
+	GetHPA(ctx context.Context, env *domain.AppEnv, name string) (*domain.KubeHPA, error)
+}
+
+// KubeClusterClient 基于平台所在集群的客户端，目前所有环境都部署在平台所在集群
+type KubeClusterClient struct {
+	Client *kube.Client
+}
+
+// PVCExists 判断环境命名空间中是否存在指定的PVC
+func (c *KubeClusterClient) PVCExists(ctx context.Context, env *domain.AppEnv, name string) (bool, error) {
+	if c.Client == nil {
+		return false, kube.ErrNotInCluster
+	}
+	path := fmt.Sprintf("/api/v1/namespaces/%s/persistentvolumeclaims/%s", env.Namespace, name)
+	if err := c.Client.Get(ctx, path, nil); err != nil {
+		if kube.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateJob 在环境命名空间中创建Job
+func (c *KubeClusterClient) CreateJob(ctx context.Context, env *domain.AppEnv, job *domain.KubeJob) error {
+	if c.Client == nil {
+		return kube.ErrNotInCluster
+	}
+	return c.Client.Create(ctx, batchPath(env.Namespace, "jobs", ""), job, nil)
+}
+
+// GetJob 获取Job及其状态
+func (c *KubeClusterClient) GetJob(ctx context.Context, env *domain.AppEnv, name string) (*domain.KubeJob, error) {
+	if c.Client == nil {
+		return nil, kube.ErrNotInCluster
+	}
+	var job domain.KubeJob
+	if err := c.Client.Get(ctx, batchPath(env.Namespace, "jobs", name), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs 按标签选择器查询Job
+func (c *KubeClusterClient) ListJobs(ctx context.Context, env *domain.AppEnv, selector string) ([]*domain.KubeJob, error) {
+	if c.Client == nil {
+		return nil, kube.ErrNotInCluster
+	}
+	var list struct {
+		Items []*domain.KubeJob `json:"items"`
+	}
+	path := batchPath(env.Namespace, "jobs", "") + "?labelSelector=" + url.QueryEscape(selector)
+	if err := c.Client.Get(ctx, path, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ApplyCronJob 创建或更新CronJob
+func (c *KubeClusterClient) ApplyCronJob(ctx context.Context, env *domain.AppEnv, cronJob *domain.KubeCronJob) error {
+	if c.Client == nil {
+		return kube.ErrNotInCluster
+	}
+	return c.Client.Apply(ctx, batchPath(env.Namespace, "cronjobs", cronJob.Metadata.Name), cronJob, nil)
+}
+
+// GetJobResult 获取Job最后创建的Pod的退出码与日志末尾，没有Pod时返回nil
+func (c *KubeClusterClient) GetJobResult(ctx context.Context, env *domain.AppEnv, jobName string, tailLines int) (*domain.JobPodResult, error) {
+	if c.Client == nil {
+		return nil, kube.ErrNotInCluster
+	}
+	var pods struct {
+		Items []struct {
+			Metadata struct {
+				Name              string `json:"name"`
+				CreationTimestamp string `json:"creationTimestamp"`
+			} `json:"metadata"`
+			Status struct {
+				ContainerStatuses []struct {
+					State struct {
+						Terminated *struct {
+							ExitCode int32  `json:"exitCode"`
+							Reason   string `json:"reason"`
+							Message  string `json:"message"`
+						} `json:"terminated"`
+					} `json:"state"`
+				} `json:"containerStatuses"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods?labelSelector=%s", env.Namespace, url.QueryEscape("job-name="+jobName))
+	if err := c.Client.Get(ctx, path, &pods); err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, nil
+	}
+	// 重试时有多个Pod，以最后创建的为准
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Metadata.CreationTimestamp < pods.Items[j].Metadata.CreationTimestamp
+	})
+	pod := pods.Items[len(pods.Items)-1]
+
+	result := &domain.JobPodResult{PodName: pod.Metadata.Name}
+	for _, status := range pod.Status.ContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil {
+			exitCode := terminated.ExitCode
+			result.ExitCode = &exitCode
+			result.Reason = terminated.Reason
+			if terminated.Message != "" {
+				result.Reason += ": " + terminated.Message
+			}
+		}
+	}
+
+	var log []byte
+	path = fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/log?tailLines=%d", env.Namespace, pod.Metadata.Name, tailLines)
+	if err := c.Client.Get(ctx, path, &log); err != nil {
+		return result, err
+	}
+	result.Log = string(log)
+	return result, nil
+}
+
+// GetDeployment 获取Deployment，不存在时返回nil
+func (c *KubeClusterClient) GetDeployment(ctx context.Context, env *domain.AppEnv, name string) (*domain.KubeDeployment, error) {
+	if c.Client == nil {
+		return nil, kube.ErrNotInCluster
+	}
+	var deployment domain.KubeDeployment
+	path := fmt.Sprintf("/apis/apps/v1/namespaces/%s/deployments/%s", env.Namespace, name)
+	if err := c.Client.Get(ctx, path, &deployment); err != nil {
+		if kube.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// GetHPA 获取HorizontalPodAutoscaler，不存在时返回nil
+func (c *KubeClusterClient) GetHPA(ctx context.Context, env *domain.AppEnv, name string) (*domain.KubeHPA, error) {
+	if c.Client == nil {
+		return nil, kube.ErrNotInCluster
+	}
+	var hpa domain.KubeHPA
+	path := fmt.Sprintf("/apis/autoscaling/v2/namespaces/%s/horizontalpodautoscalers/%s", env.Namespace, name)
+	if err := c.Client.Get(ctx, path, &hpa); err != nil {
+		if kube.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &hpa, nil
+}
+
+// batchPath batch/v1资源的API路径
+func batchPath(namespace, resource, name string) string {
+	path := fmt.Sprintf("/apis/batch/v1/namespaces/%s/%s", namespace, resource)
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+)
+
+// ConfigService 应用配置服务：按应用与环境管理环境变量、配置文件与Secret引用，每次变更生成不可修改的新版本
+type ConfigService struct {
+	service.Service
+	Repo *repository.AppRepository `inject:"ApplicationRepository"`
+}
+
+// NewConfigService 创建应用配置服务实例
+func NewConfigService() *ConfigService {
+	return &ConfigService{}
+}
+
+// GetConfig 获取应用环境的当前（最新）配置版本
+func (s *ConfigService) GetConfig(ctx context.Context, appID, envID types.Long) (*domain.AppConfigRevision, error) {
+	revision, err := s.Repo.GetLatestConfigRevision(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询应用配置失败", err)
+	}
+	if revision == nil {
+		return nil, common.NotFoundError("应用在该环境下没有配置", nil)
+	}
+	return revision, nil
+}
+
+// SaveConfig 保存应用环境配置，内容与当前版本相同时不生成新版本
+func (s *ConfigService) SaveConfig(ctx context.Context, command *domain.SaveConfigCommand) (revision *domain.AppConfigRevision, err error) {
+	if err = s.checkAppEnv(ctx, command.AppID, command.EnvID); err != nil {
+		return nil, err
+	}
+	if err = command.Validate(); err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "save app config")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "save app config")
+	}()
+
+	latest, err := s.Repo.GetLatestConfigRevision(ctx, command.AppID, command.EnvID)
+	if err != nil {
+		return nil, common.InternalError("查询应用配置失败", err)
+	}
+	number := 1
+	if latest != nil {
+		number = latest.Revision + 1
+	}
+	revision = command.NewRevision(number)
+	if latest != nil && latest.Checksum == revision.Checksum {
+		return nil, common.RequestParamError("配置未变化", nil)
+	}
+	return revision, s.createRevision(ctx, revision)
+}
+
+// ListRevisions 分页查询配置版本
+func (s *ConfigService) ListRevisions(ctx context.Context, query *domain.ConfigRevisionQuery) ([]*domain.AppConfigRevision, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = 10
+	}
+	revisions, total, err := s.Repo.ListConfigRevisions(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询配置版本失败", err)
+	}
+	return revisions, total, nil
+}
+
+// GetRevision 获取指定配置版本
+func (s *ConfigService) GetRevision(ctx context.Context, appID, envID types.Long, number int) (*domain.AppConfigRevision, error) {
+	revision, err := s.Repo.GetConfigRevision(ctx, appID, envID, number)
+	if err != nil {
+		return nil, common.InternalError("查询配置版本失败", err)
+	}
+	if revision == nil {
+		return nil, common.NotFoundError(fmt.Sprintf("配置版本 %d 不存在", number), nil)
+	}
+	return revision, nil
+}
+
+// DiffRevisions 比较两个配置版本，to为0时取最新版本，from为0时取to的上一个版本
+func (s *ConfigService) DiffRevisions(ctx context.Context, appID, envID types.Long, from, to int) (*domain.ConfigDiffVO, error) {
+	var target *domain.AppConfigRevision
+	var err error
+	if to > 0 {
+		target, err = s.GetRevision(ctx, appID, envID, to)
+	} else {
+		target, err = s.GetConfig(ctx, appID, envID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if from <= 0 {
+		from = target.Revision - 1
+	}
+
+	var base *domain.AppConfigRevision
+	if from > 0 {
+		if base, err = s.GetRevision(ctx, appID, envID, from); err != nil {
+			return nil, err
+		}
+	}
+	return domain.DiffConfig(base, target), nil
+}
+
+// RestoreRevision 将指定版本的内容恢复为当前配置，生成新版本；与当前版本相同时直接返回当前版本
+func (s *ConfigService) RestoreRevision(ctx context.Context, appID, envID types.Long, number int, comment string) (revision *domain.AppConfigRevision, err error) {
+	source, err := s.GetRevision(ctx, appID, envID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "restore app config")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "restore app config")
+	}()
+	return s.restore(ctx, source, source.EnvID, comment)
+}
+
+// RenderConfigMaps 将配置版本渲染为ConfigMap YAML，number为0时使用最新版本
+func (s *ConfigService) RenderConfigMaps(ctx context.Context, appID, envID types.Long, number int) ([]byte, error) {
+	app, err := s.Repo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	env, err := s.Repo.GetAppEnvByID(ctx, envID)
+	if err != nil {
+		return nil, common.NotFoundError("环境不存在", err)
+	}
+	var revision *domain.AppConfigRevision
+	if number > 0 {
+		revision, err = s.GetRevision(ctx, appID, envID, number)
+	} else {
+		revision, err = s.GetConfig(ctx, appID, envID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for i, configMap := range domain.NewConfigMaps(app.Name, env.Namespace, revision) {
+		data, err := kube.MarshalYAML(configMap)
+		if err != nil {
+			return nil, common.InternalError("生成ConfigMap失败", err)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// restoreByID 回滚部署时恢复部署使用的配置版本，调用方负责事务
+func (s *ConfigService) restoreByID(ctx context.Context, id types.Long, comment string) (*domain.AppConfigRevision, error) {
+	source, err := s.Repo.GetConfigRevisionByID(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询配置版本失败", err)
+	}
+	if source == nil {
+		return nil, common.NotFoundError("部署使用的配置版本不存在", nil)
+	}
+	return s.restore(ctx, source, source.EnvID, comment)
+}
+
+// promoteByID 执行晋级产生的发布计划时，将来源部署的配置版本复制为目标环境的最新版本，调用方负责事务
+func (s *ConfigService) promoteByID(ctx context.Context, id, envID types.Long) (*domain.AppConfigRevision, error) {
+	source, err := s.Repo.GetConfigRevisionByID(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询配置版本失败", err)
+	}
+	if source == nil {
+		return nil, common.NotFoundError("晋级来源的配置版本不存在", nil)
+	}
+	return s.restore(ctx, source, envID, fmt.Sprintf("晋级自环境 %s 的配置版本 %d", source.EnvID, source.Revision))
+}
+
+// restore 以source的内容生成envID环境的新的最新版本，与最新版本相同时直接返回最新版本
+func (s *ConfigService) restore(ctx context.Context, source *domain.AppConfigRevision, envID types.Long, comment string) (*domain.AppConfigRevision, error) {
+	latest, err := s.Repo.GetLatestConfigRevision(ctx, source.AppID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询应用配置失败", err)
+	}
+	if latest != nil && latest.Checksum == source.Checksum {
+		return latest, nil
+	}
+	if comment == "" {
+		comment = fmt.Sprintf("恢复配置版本 %d", source.Revision)
+	}
+	number := 1
+	if latest != nil {
+		number = latest.Revision + 1
+	}
+
+	revision := &domain.AppConfigRevision{
+		AppID:      source.AppID,
+		EnvID:      envID,
+		Revision:   number,
+		Envs:       source.Envs,
+		Files:      source.Files,
+		MountPath:  source.MountPath,
+		SecretRefs: source.SecretRefs,
+		Checksum:   source.Checksum,
+		Comment:    comment,
+		RestoredID: source.ID,
+	}
+	return revision, s.createRevision(ctx, revision)
+}
+
+// createRevision 保存新版本，版本号冲突说明有并发修改
+func (s *ConfigService) createRevision(ctx context.Context, revision *domain.AppConfigRevision) error {
+	revision.AuditCreated(ctx)
+	if _, err := s.Repo.CreateConfigRevision(ctx, revision); err != nil {
+		if current, _ := s.Repo.GetConfigRevision(ctx, revision.AppID, revision.EnvID, revision.Revision); current != nil {
+			return common.RequestParamError("配置已被其他人修改，请刷新后重试", err)
+		}
+		return common.InternalError("保存配置版本失败", err)
+	}
+	return nil
+}
+
+// checkAppEnv 校验应用与环境存在
+func (s *ConfigService) checkAppEnv(ctx context.Context, appID, envID types.Long) error {
+	if _, err := s.Repo.GetApplicationByID(ctx, appID); err != nil {
+		return common.NotFoundError("应用不存在", err)
+	}
+	if _, err := s.Repo.GetAppEnvByID(ctx, envID); err != nil {
+		return common.RequestParamError("", errors.New("环境不存在"))
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/notification"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/pkg/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+// runJob 执行Job部署方式的部署：一次性Job以运行结果作为部署结果，CronJob创建成功即部署成功
+func (s *DeployService) runJob(ctx context.Context, deployment *domain.Deployment) {
+	manifest, env, err := s.Workload.manifest(ctx, deployment.AppID, deployment.EnvID, deployment.Version, deployment.SpecRevision)
+	if err == nil && manifest.Spec.Job == nil {
+		err = fmt.Errorf("工作负载定义版本 %d 不是Job部署方式", manifest.SpecRevision)
+	}
+	if err != nil {
+		s.failStep(ctx, deployment.ID, "渲染工作负载", err)
+		return
+	}
+	manifest.DeployID = deployment.ID
+	manifest.Digest = deployment.Digest
+
+	if manifest.Spec.Job.Scheduled() {
+		s.runCronJob(ctx, deployment, manifest, env)
+		return
+	}
+	s.runOnceJob(ctx, deployment, manifest, env)
+}
+
+// runOnceJob 创建Job → 等待完成 → 收集退出码与日志
+func (s *DeployService) runOnceJob(ctx context.Context, deployment *domain.Deployment, manifest *domain.WorkloadManifest, env *domain.AppEnv) {
+	job := domain.NewJob(manifest)
+	step, err := s.startStep(ctx, deployment.ID, "创建Job")
+	if err != nil {
+		s.updateDeploymentStatus(ctx, deployment.ID, domain.DeployStatusFailed)
+		return
+	}
+	if err = s.Cluster.CreateJob(ctx, env, job); err != nil {
+		s.finishStep(ctx, step, domain.DeployStatusFailed, clusterMessage("创建Job失败", err))
+		s.updateDeploymentStatus(ctx, deployment.ID, domain.DeployStatusFailed)
+		return
+	}
+	run := &domain.JobRun{
+		DeployID:  deployment.ID,
+		AppID:     deployment.AppID,
+		EnvID:     deployment.EnvID,
+		JobName:   job.Metadata.Name,
+		Status:    domain.DeployStatusRunning,
+		StartTime: time.Now(),
+	}
+	if _, err = s.Repo.CreateJobRun(ctx, run); err != nil {
+		logrus.Errorf("创建Job运行记录失败: %v", err)
+	}
+	s.finishStep(ctx, step, domain.DeployStatusSuccess, "已创建Job "+job.Metadata.Name)
+
+	step, err = s.startStep(ctx, deployment.ID, "等待Job完成")
+	if err != nil {
+		s.updateDeploymentStatus(ctx, deployment.ID, domain.DeployStatusFailed)
+		return
+	}
+	status, message := s.waitJob(ctx, env, job.Metadata.Name, manifest.Spec.Job.Timeout())
+	stepMessage := message
+	if status == domain.DeployStatusSuccess {
+		stepMessage = "Job运行完成"
+	}
+	s.finishStep(ctx, step, status, stepMessage)
+
+	step, err = s.startStep(ctx, deployment.ID, "收集退出码与日志")
+	if err == nil {
+		result, err := s.Cluster.GetJobResult(ctx, env, job.Metadata.Name, domain.JobLogTailLines)
+		run.Finish(status, message, result)
+		switch {
+		case err != nil:
+			s.finishStep(ctx, step, domain.DeployStatusFailed, clusterMessage("获取Pod结果失败", err))
+		case result == nil:
+			s.finishStep(ctx, step, domain.DeployStatusFailed, "Job没有可用的Pod")
+		case result.ExitCode == nil:
+			s.finishStep(ctx, step, domain.DeployStatusSuccess, "容器未结束，没有退出码")
+		default:
+			s.finishStep(ctx, step, domain.DeployStatusSuccess, fmt.Sprintf("Pod %s 退出码 %d", result.PodName, *result.ExitCode))
+		}
+	} else {
+		run.Finish(status, message, nil)
+	}
+	if run.ID > 0 {
+		if err = s.Repo.UpdateJobRun(ctx, run); err != nil {
+			logrus.Errorf("更新Job运行记录失败: %v", err)
+		}
+	}
+
+	// 部署结果以Job是否成功完成为准
+	s.updateDeploymentStatus(ctx, deployment.ID, status)
+}
+
+// runCronJob 创建或更新CronJob，每次调度的运行由同步任务记录
+func (s *DeployService) runCronJob(ctx context.Context, deployment *domain.Deployment, manifest *domain.WorkloadManifest, env *domain.AppEnv) {
+	cronJob := domain.NewCronJob(manifest)
+	step, err := s.startStep(ctx, deployment.ID, "创建CronJob")
+	if err != nil {
+		s.updateDeploymentStatus(ctx, deployment.ID, domain.DeployStatusFailed)
+		return
+	}
+	if err = s.Cluster.ApplyCronJob(ctx, env, cronJob); err != nil {
+		s.finishStep(ctx, step, domain.DeployStatusFailed, clusterMessage("创建CronJob失败", err))
+		s.updateDeploymentStatus(ctx, deployment.ID, domain.DeployStatusFailed)
+		return
+	}
+	s.finishStep(ctx, step, domain.DeployStatusSuccess, fmt.Sprintf("CronJob %s 调度: %s，并发策略: %s",
+		cronJob.Metadata.Name, cronJob.Spec.Schedule, cronJob.Spec.ConcurrencyPolicy))
+	s.updateDeploymentStatus(ctx, deployment.ID, domain.DeployStatusSuccess)
+}
+
+// waitJob 等待Job运行结束，超时或查询失败时返回失败
+func (s *DeployService) waitJob(ctx context.Context, env *domain.AppEnv, name string, timeout time.Duration) (string, string) {
+	ticker := time.NewTicker(domain.JobPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-ctx.Done():
+			return domain.DeployStatusFailed, "等待Job完成被取消"
+		case <-deadline:
+			return domain.DeployStatusFailed, fmt.Sprintf("等待Job完成超时（%s）", timeout)
+		case <-ticker.C:
+			job, err := s.Cluster.GetJob(ctx, env, name)
+			if err != nil {
+				if kube.IsNotFound(err) {
+					return domain.DeployStatusFailed, "Job已被删除"
+				}
+				// 查询失败时继续等待，直到超时
+				logrus.WithError(err).WithField("job", name).Warn("查询Job状态失败")
+				continue
+			}
+			if status, message := domain.JobState(job); status != domain.DeployStatusRunning {
+				return status, message
+			}
+		}
+	}
+}
+
+// SyncJobRuns 同步CronJob产生的运行：登记新的运行，并收集已结束运行的退出码与日志
+func (s *DeployService) SyncJobRuns(ctx context.Context) {
+	envs, err := s.Repo.ListAppEnvs(ctx)
+	if err != nil {
+		logrus.Errorf("查询环境失败: %v", err)
+		return
+	}
+	// 多个环境可能使用同一个命名空间，每个命名空间只查询一次
+	namespaces := make(map[string][]*domain.AppEnv, len(envs))
+	var order []string
+	for _, env := range envs {
+		if _, ok := namespaces[env.Namespace]; !ok {
+			order = append(order, env.Namespace)
+		}
+		namespaces[env.Namespace] = append(namespaces[env.Namespace], env)
+	}
+	for _, namespace := range order {
+		if err = s.syncNamespaceJobRuns(ctx, namespaces[namespace]); err != nil {
+			if errors.Is(err, kube.ErrNotInCluster) {
+				return
+			}
+			logrus.WithError(err).WithField("namespace", namespace).Warn("同步定时任务运行记录失败")
+		}
+	}
+}
+
+// syncNamespaceJobRuns 同步一个命名空间中的定时任务运行，envs为使用该命名空间的环境
+func (s *DeployService) syncNamespaceJobRuns(ctx context.Context, envs []*domain.AppEnv) error {
+	env := envs[0]
+	jobs, err := s.Cluster.ListJobs(ctx, env, domain.LabelCronJob+","+domain.LabelManagedBy+"="+domain.ManagedBy)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		deployID, err := types.StringToLong(job.Metadata.Labels[domain.LabelDeployment])
+		if err != nil || deployID == 0 {
+			continue
+		}
+		seen[job.Metadata.Name] = true
+		run, err := s.Repo.GetJobRun(ctx, deployID, job.Metadata.Name)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			if run, err = s.registerJobRun(ctx, deployID, job); err != nil || run == nil {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if run.Status != domain.DeployStatusRunning {
+			continue
+		}
+		if status, message := domain.JobState(job); status != domain.DeployStatusRunning {
+			s.finishJobRun(ctx, env, run, status, message)
+		}
+	}
+
+	// Job被CronJob的历史数量限制清理后，无法再获取结果
+	for _, env := range envs {
+		runs, err := s.Repo.ListRunningScheduledJobRuns(ctx, env.ID)
+		if err != nil {
+			return err
+		}
+		for _, run := range runs {
+			if seen[run.JobName] {
+				continue
+			}
+			if _, err = s.Cluster.GetJob(ctx, env, run.JobName); kube.IsNotFound(err) {
+				s.finishJobRun(ctx, env, run, domain.DeployStatusFailed, "Job已被删除，无法获取运行结果")
+			}
+		}
+	}
+	return nil
+}
+
+// registerJobRun 登记CronJob新产生的运行，部署记录不存在时忽略
+func (s *DeployService) registerJobRun(ctx context.Context, deployID types.Long, job *domain.KubeJob) (*domain.JobRun, error) {
+	deployment, err := s.Repo.GetDeploymentByID(ctx, deployID)
+	if err != nil {
+		return nil, nil
+	}
+	run := &domain.JobRun{
+		DeployID:  deployID,
+		AppID:     deployment.AppID,
+		EnvID:     deployment.EnvID,
+		JobName:   job.Metadata.Name,
+		Scheduled: true,
+		Status:    domain.DeployStatusRunning,
+		StartTime: time.Now(),
+	}
+	if job.Status != nil && job.Status.StartTime != nil {
+		run.StartTime = *job.Status.StartTime
+	}
+	if _, err = s.Repo.CreateJobRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// finishJobRun 收集定时任务运行的结果，失败时发送通知
+func (s *DeployService) finishJobRun(ctx context.Context, env *domain.AppEnv, run *domain.JobRun, status, message string) {
+	result, err := s.Cluster.GetJobResult(ctx, env, run.JobName, domain.JobLogTailLines)
+	if err != nil && !kube.IsNotFound(err) {
+		logrus.WithError(err).WithField("job", run.JobName).Warn("获取Job运行结果失败")
+	}
+	run.Finish(status, message, result)
+	if err = s.Repo.UpdateJobRun(ctx, run); err != nil {
+		logrus.Errorf("更新Job运行记录失败: %v", err)
+		return
+	}
+	if status != domain.DeployStatusFailed {
+		return
+	}
+
+	event := &notification.Event{
+		Type:         notification.EventJobRunFailed,
+		AppID:        run.AppID,
+		EnvID:        run.EnvID,
+		DeploymentID: run.DeployID,
+		Message:      fmt.Sprintf("Job %s 运行失败", run.JobName),
+	}
+	if run.ExitCode != nil {
+		event.Message += fmt.Sprintf("，退出码 %d", *run.ExitCode)
+	}
+	if run.Message != "" {
+		event.Message += ": " + run.Message
+	}
+	if deployment, err := s.Repo.GetDeploymentByID(ctx, run.DeployID); err == nil {
+		event.Version = deployment.Version
+	}
+	s.notify(ctx, event)
+}
+
+// ListJobRuns 分页查询应用的Job运行记录
+func (s *DeployService) ListJobRuns(ctx context.Context, query *domain.JobRunQuery) ([]*domain.JobRun, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = 10
+	}
+	runs, total, err := s.Repo.ListJobRuns(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询Job运行记录失败", err)
+	}
+	return runs, total, nil
+}
+
+// GetJobRun 获取应用的Job运行记录，包含日志
+func (s *DeployService) GetJobRun(ctx context.Context, appID, runID types.Long) (*domain.JobRun, error) {
+	run, err := s.Repo.GetJobRunByID(ctx, runID)
+	if err != nil {
+		return nil, common.InternalError("查询Job运行记录失败", err)
+	}
+	if run == nil || run.AppID != appID {
+		return nil, common.NotFoundError("Job运行记录不存在", nil)
+	}
+	return run, nil
+}
+
+// startStep 创建运行中的部署步骤
+func (s *DeployService) startStep(ctx context.Context, deployID types.Long, name string) (*domain.DeploymentStep, error) {
+	step := &domain.DeploymentStep{
+		DeployID:  deployID,
+		Name:      name,
+		Status:    domain.DeployStatusRunning,
+		StartTime: time.Now(),
+	}
+	if _, err := s.Repo.CreateDeploymentStep(ctx, step); err != nil {
+		logrus.Errorf("创建部署步骤记录失败: %v", err)
+		return nil, err
+	}
+	return step, nil
+}
+
+// finishStep 记录部署步骤的结果
+func (s *DeployService) finishStep(ctx context.Context, step *domain.DeploymentStep, status, message string) {
+	endTime := time.Now()
+	step.Status = status
+	step.Message = message
+	if len(step.Message) > 1000 {
+		step.Message = strings.ToValidUTF8(step.Message[:1000], "")
+	}
+	step.EndTime = &endTime
+	if err := s.Repo.UpdateDeploymentStep(ctx, step); err != nil {
+		logrus.Errorf("更新部署步骤状态失败: %v", err)
+	}
+}
+
+// failStep 记录一个失败的步骤并结束部署
+func (s *DeployService) failStep(ctx context.Context, deployID types.Long, name string, err error) {
+	if step, stepErr := s.startStep(ctx, deployID, name); stepErr == nil {
+		s.finishStep(ctx, step, domain.DeployStatusFailed, err.Error())
+	}
+	s.updateDeploymentStatus(ctx, deployID, domain.DeployStatusFailed)
+}
+
+// clusterMessage 集群操作失败的说明，未接入集群时给出明确提示
+func clusterMessage(action string, err error) string {
+	if errors.Is(err, kube.ErrNotInCluster) {
+		return action + ": 平台未接入集群"
+	}
+	return action + ": " + err.Error()
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"devops-platform/internal/common/service"
 	"errors"
+	"fmt"
 	"time"
 
 	"devops-platform/internal/deploy-system/application/internal/domain"
 	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/deploy-system/notification"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
 	"devops-platform/pkg/types"
 
 	"github.com/sirupsen/logrus"
@@ -16,8 +20,15 @@ import (
 // DeployService 部署服务实现
 type DeployService struct {
 	service.Service
-	Repo   *repository.AppRepository
-	Logger *logrus.Logger
+	Repo     *repository.AppRepository `inject:"ApplicationRepository"`
+	Config   *ConfigService            `inject:"configService"`
+	Workload *WorkloadService          `inject:"workloadService"`
+	HPA      *HPAService               `inject:"hpaService"`
+	Members  *MemberService            `inject:"memberService"`
+	Locks    *LockService              `inject:"lockService"`
+	Cluster  ClusterClient             `inject:"appClusterClient"`
+	Logger   *logrus.Logger            `inject:"Logger"`
+	Notifier notification.Publisher    `inject:"NotificationService"`
 }
 
 // NewDeployService 创建部署服务实例
@@ -25,7 +36,7 @@ func NewDeployService() *DeployService {
 	return &DeployService{}
 }
 
-// CreateReleasePlan 创建发布计划
+// CreateReleasePlan 创建发布计划，应用配置了晋级路径时不能跳过必经阶段
 func (s *DeployService) CreateReleasePlan(ctx context.Context, command *domain.CreateReleaseCommand) (types.Long, error) {
 	// 检查应用是否存在
 	_, err := s.Repo.GetApplicationByID(ctx, command.AppID)
@@ -46,38 +57,230 @@ func (s *DeployService) CreateReleasePlan(ctx context.Context, command *domain.C
 		Version:  command.Version,
 		Strategy: command.Strategy,
 		Status:   domain.DeployStatusPending,
+		Digest:   command.Digest,
 	}
 
+	return s.createPlan(ctx, plan)
+}
+
+// createPlan 校验镜像digest与晋级路径后创建发布计划
+func (s *DeployService) createPlan(ctx context.Context, plan *domain.ReleasePlan) (types.Long, error) {
+	if plan.Digest != "" && !domain.IsImageDigest(plan.Digest) {
+		return 0, common.RequestParamError("", fmt.Errorf("镜像digest无效: %s", plan.Digest))
+	}
+	if err := s.checkPromotion(ctx, plan); err != nil {
+		return 0, err
+	}
 	return s.Repo.CreateReleasePlan(ctx, plan)
 }
 
-// ExecuteReleasePlan 执行发布计划
+// checkPromotion 发布到晋级路径中的阶段前，之前的必经阶段都必须成功部署过该版本；不在路径中的环境不受限制
+func (s *DeployService) checkPromotion(ctx context.Context, plan *domain.ReleasePlan) error {
+	stages, err := s.Repo.ListPromotionStages(ctx, plan.AppID)
+	if err != nil {
+		return common.InternalError("查询晋级路径失败", err)
+	}
+	position := 0
+	for _, stage := range stages {
+		if stage.EnvID == plan.EnvID {
+			position = stage.Position
+		}
+	}
+	for _, stage := range stages {
+		if stage.Position >= position || stage.Optional {
+			continue
+		}
+		passed, err := s.Repo.HasSuccessfulDeployment(ctx, plan.AppID, stage.EnvID, plan.Version, plan.Digest)
+		if err != nil {
+			return common.InternalError("查询部署记录失败", err)
+		}
+		if !passed {
+			name := stage.EnvID.String()
+			if env, err := s.Repo.GetAppEnvByID(ctx, stage.EnvID); err == nil {
+				name = env.Name
+			}
+			return common.RequestParamError("", fmt.Errorf("版本 %s 尚未在第%d阶段环境 %s 部署成功，不能跳过", plan.Version, stage.Position, name))
+		}
+	}
+	return nil
+}
+
+// ApproveReleasePlan 审批发布计划
+func (s *DeployService) ApproveReleasePlan(ctx context.Context, planID types.Long) error {
+	plan, err := s.Repo.GetReleasePlanByID(ctx, planID)
+	if err != nil {
+		return err
+	}
+
+	// 只有待处理的计划可以审批
+	if plan.Status != domain.DeployStatusPending {
+		return errors.New("只有待处理的发布计划可以审批")
+	}
+
+	plan.Status = domain.DeployStatusApproved
+	if err := s.Repo.UpdateReleasePlan(ctx, plan); err != nil {
+		return err
+	}
+
+	s.notify(ctx, &notification.Event{
+		Type:     notification.EventPlanApproved,
+		AppID:    plan.AppID,
+		EnvID:    plan.EnvID,
+		PlanID:   plan.ID,
+		Version:  plan.Version,
+		Strategy: plan.Strategy,
+	})
+	return nil
+}
+
+// ExecuteReleasePlan 执行发布计划，需要应用开发者及以上角色；应用正在该环境部署或已冻结时返回冲突错误
 func (s *DeployService) ExecuteReleasePlan(ctx context.Context, planID types.Long) (types.Long, error) {
+	deployID, _, err := s.execute(ctx, planID, false)
+	return deployID, err
+}
+
+// ExecuteOrQueueReleasePlan 执行发布计划，应用正在该环境部署时排队，当前部署结束后自动执行
+func (s *DeployService) ExecuteOrQueueReleasePlan(ctx context.Context, planID types.Long) (types.Long, bool, error) {
+	return s.execute(ctx, planID, true)
+}
+
+// execute 检查权限后执行发布计划，queue为true时部署租约冲突则排队
+func (s *DeployService) execute(ctx context.Context, planID types.Long, queue bool) (types.Long, bool, error) {
 	// 获取发布计划
 	plan, err := s.Repo.GetReleasePlanByID(ctx, planID)
 	if err != nil {
-		return 0, err
+		return 0, false, err
+	}
+	if err = s.Members.Authorize(ctx, plan.AppID, domain.AppRoleDeveloper); err != nil {
+		return 0, false, err
 	}
 
+	// 发布列车中的计划由列车按依赖顺序执行
+	member, err := s.Repo.GetTrainMemberByPlan(ctx, plan.ID)
+	if err != nil {
+		return 0, false, common.InternalError("查询发布列车失败", err)
+	}
+	if member != nil {
+		return 0, false, common.RequestParamError("", fmt.Errorf("发布计划属于发布列车 %s，请通过发布列车执行", member.TrainID))
+	}
+
+	deployID, err := s.executePlan(ctx, plan)
+	if err != nil && queue && isLeaseConflict(err) {
+		if plan.Status == domain.DeployStatusQueued {
+			return 0, true, nil
+		}
+		plan.Status = domain.DeployStatusQueued
+		plan.AuditModified(ctx)
+		if err = s.Repo.UpdateReleasePlan(ctx, plan); err != nil {
+			return 0, false, common.InternalError("发布计划排队失败", err)
+		}
+		return 0, true, nil
+	}
+	return deployID, false, err
+}
+
+// DrainDeployQueues 执行排队中的发布计划，用于接管过期未释放的部署租约
+func (s *DeployService) DrainDeployQueues(ctx context.Context) {
+	plans, err := s.Repo.ListQueuedReleasePlans(ctx, 0, 0)
+	if err != nil {
+		logrus.Errorf("查询排队中的发布计划失败: %v", err)
+		return
+	}
+	seen := make(map[[2]types.Long]bool, len(plans))
+	for _, plan := range plans {
+		key := [2]types.Long{plan.AppID, plan.EnvID}
+		if !seen[key] {
+			seen[key] = true
+			s.dequeue(ctx, plan.AppID, plan.EnvID)
+		}
+	}
+}
+
+// dequeue 执行应用环境中最早排队的发布计划，部署锁仍被持有时继续排队
+func (s *DeployService) dequeue(ctx context.Context, appID, envID types.Long) {
+	plans, err := s.Repo.ListQueuedReleasePlans(ctx, appID, envID)
+	if err != nil {
+		logrus.Errorf("查询排队中的发布计划失败: %v", err)
+		return
+	}
+	if len(plans) == 0 {
+		return
+	}
+	plan := plans[0]
+	if _, err = s.executePlan(ctx, plan); err != nil {
+		if isLeaseConflict(err) {
+			return
+		}
+		// 无法执行的计划不再排队，避免阻塞后续计划
+		logrus.WithError(err).WithField("plan", plan.ID).Error("执行排队中的发布计划失败")
+		plan.Status = domain.DeployStatusFailed
+		if err = s.Repo.UpdateReleasePlan(ctx, plan); err != nil {
+			logrus.Errorf("更新发布计划状态失败: %v", err)
+		}
+	}
+}
+
+// executePlan 获取部署租约，创建部署记录并异步执行发布计划
+func (s *DeployService) executePlan(ctx context.Context, plan *domain.ReleasePlan) (deployID types.Long, err error) {
 	// 已执行的计划不能重复执行
-	if plan.Status != domain.DeployStatusPending {
-		return 0, errors.New("只有待处理的发布计划可以执行")
+	if plan.Status != domain.DeployStatusPending && plan.Status != domain.DeployStatusApproved && plan.Status != domain.DeployStatusQueued {
+		return 0, errors.New("只有待处理、已审批或排队中的发布计划可以执行")
 	}
 
-	// 创建部署记录
+	// 同一应用在同一环境同时只能有一个部署，没有创建部署记录时放弃租约
+	lease, err := s.Locks.Acquire(ctx, plan.AppID, plan.EnvID, true)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if deployID == 0 {
+			s.Locks.Abandon(ctx, lease)
+		}
+	}()
+
+	// 创建部署记录，记录当前的配置版本；晋级产生的计划先将来源部署的配置版本复制到目标环境
 	now := time.Now()
 	deployment := &domain.Deployment{
 		AppID:     plan.AppID,
 		EnvID:     plan.EnvID,
 		Version:   plan.Version,
+		Digest:    plan.Digest,
 		Status:    domain.DeployStatusRunning,
 		StartTime: now,
 	}
-
-	deployID, err := s.Repo.CreateDeployment(ctx, deployment)
+	deployment.AuditCreated(ctx)
+	var config *domain.AppConfigRevision
+	if plan.ConfigRevisionID > 0 {
+		config, err = s.Config.promoteByID(ctx, plan.ConfigRevisionID, plan.EnvID)
+	} else {
+		config, err = s.Repo.GetLatestConfigRevision(ctx, plan.AppID, plan.EnvID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if config != nil {
+		deployment.ConfigRevisionID = config.ID
+		deployment.ConfigRevision = config.Revision
+	}
+	spec, err := s.Repo.GetLatestWorkloadSpec(ctx, plan.AppID)
 	if err != nil {
 		return 0, err
 	}
+	if spec != nil {
+		deployment.SpecRevision = spec.Revision
+		deployment.Mode = spec.Spec.Mode
+	}
+	if deployment.Mode != enum.DeployModeJob {
+		if deployment.HPA, err = s.HPA.deployedHPA(ctx, plan.AppID, plan.EnvID); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err = s.Repo.CreateDeployment(ctx, deployment); err != nil {
+		return 0, err
+	}
+	deployID = deployment.ID
+	s.Locks.Attach(ctx, lease, deployID)
 
 	// 更新发布计划状态
 	plan.Status = domain.DeployStatusRunning
@@ -85,6 +288,16 @@ func (s *DeployService) ExecuteReleasePlan(ctx context.Context, planID types.Lon
 		return deployID, err
 	}
 
+	s.notify(ctx, &notification.Event{
+		Type:         notification.EventDeployStarted,
+		AppID:        plan.AppID,
+		EnvID:        plan.EnvID,
+		PlanID:       plan.ID,
+		DeploymentID: deployID,
+		Version:      plan.Version,
+		Strategy:     plan.Strategy,
+	})
+
 	// 异步执行部署（实际项目中应该在此处启动新的goroutine执行）
 	// 为了简化示例，这里仅更新部署状态
 	go s.runDeployment(context.Background(), deployID, plan.Strategy)
@@ -103,11 +316,17 @@ func (s *DeployService) runDeployment(ctx context.Context, deployID types.Long,
 	}()
 
 	// 获取部署记录
-	_, err := s.Repo.GetDeploymentByID(ctx, deployID)
+	deployment, err := s.Repo.GetDeploymentByID(ctx, deployID)
 	if err != nil {
 		logrus.Errorf("获取部署记录失败: %v", err)
 		return
 	}
+	// 部署期间续期部署租约，部署结束时由updateDeploymentStatus释放
+	defer s.Locks.KeepAlive(ctx, deployID)()
+	if deployment.Mode == enum.DeployModeJob {
+		s.runJob(ctx, deployment)
+		return
+	}
 
 	// 根据不同的部署策略执行不同的部署步骤
 	steps := s.getDeploySteps(strategy)
@@ -202,7 +421,46 @@ func (s *DeployService) updateDeploymentStatus(ctx context.Context, deployID typ
 
 	if err := s.Repo.UpdateDeployment(ctx, deployment); err != nil {
 		logrus.Errorf("更新部署状态失败: %v", err)
+		return
+	}
+
+	// 部署结束时释放部署租约，并执行该应用环境中排队的发布计划
+	if status == domain.DeployStatusSuccess || status == domain.DeployStatusFailed {
+		s.Locks.Release(ctx, deployment.ID)
+		defer s.dequeue(ctx, deployment.AppID, deployment.EnvID)
+	}
+
+	// 部署结束时发送通知
+	eventType := ""
+	switch status {
+	case domain.DeployStatusSuccess:
+		eventType = notification.EventDeploySucceeded
+	case domain.DeployStatusFailed:
+		eventType = notification.EventDeployFailed
+	}
+	if eventType != "" {
+		s.notify(ctx, &notification.Event{
+			Type:         eventType,
+			AppID:        deployment.AppID,
+			EnvID:        deployment.EnvID,
+			DeploymentID: deployment.ID,
+			Version:      deployment.Version,
+		})
+	}
+}
+
+// notify 补充应用、环境名称后发布通知事件
+func (s *DeployService) notify(ctx context.Context, event *notification.Event) {
+	if s.Notifier == nil {
+		return
+	}
+	if app, err := s.Repo.GetApplicationByID(ctx, event.AppID); err == nil && app != nil {
+		event.AppName = app.Name
 	}
+	if env, err := s.Repo.GetAppEnvByID(ctx, event.EnvID); err == nil && env != nil {
+		event.EnvName = env.Name
+	}
+	s.Notifier.Publish(ctx, event)
 }
 
 // GetDeployment 获取部署记录
@@ -215,33 +473,93 @@ func (s *DeployService) ListDeployments(ctx context.Context, appID, envID types.
 	return s.Repo.ListDeployments(ctx, appID, envID)
 }
 
-// RollbackDeployment 回滚部署
+// RollbackDeployment 回滚部署，需要应用开发者及以上角色
 func (s *DeployService) RollbackDeployment(ctx context.Context, id types.Long) error {
 	// 获取部署记录
 	deployment, err := s.Repo.GetDeploymentByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if err = s.Members.Authorize(ctx, deployment.AppID, domain.AppRoleDeveloper); err != nil {
+		return err
+	}
 
 	// 只有成功或失败的部署可以回滚
 	if deployment.Status != domain.DeployStatusSuccess && deployment.Status != domain.DeployStatusFailed {
 		return errors.New("只有成功或失败的部署可以回滚")
 	}
 
+	// 一次性Job已经运行结束，回滚没有意义；CronJob回滚即恢复原来的调度与镜像
+	if deployment.Mode == enum.DeployModeJob {
+		spec, err := s.Repo.GetWorkloadSpec(ctx, deployment.AppID, deployment.SpecRevision)
+		if err != nil {
+			return err
+		}
+		if spec == nil || !spec.Spec.Job.Scheduled() {
+			return errors.New("一次性Job的部署不能回滚，请重新发布")
+		}
+	}
+
+	// 回滚同样需要部署租约，但不受冻结限制，冻结期间仍可以回滚止损
+	lease, err := s.Locks.Acquire(ctx, deployment.AppID, deployment.EnvID, false)
+	if err != nil {
+		return err
+	}
+	attached := false
+	defer func() {
+		if attached {
+			s.Locks.Release(ctx, lease.DeploymentID)
+			s.dequeue(ctx, deployment.AppID, deployment.EnvID)
+		} else {
+			s.Locks.Abandon(ctx, lease)
+		}
+	}()
+
 	// 创建回滚部署记录
 	now := time.Now()
 	rollbackDeployment := &domain.Deployment{
 		AppID:     deployment.AppID,
 		EnvID:     deployment.EnvID,
-		Version:   deployment.Version + "-rollback",
+		Version:   deployment.Version + domain.RollbackVersionSuffix,
+		Digest:    deployment.Digest,
 		Status:    domain.DeployStatusRollback,
 		StartTime: now,
+		// 工作负载定义按版本保存，回滚直接使用原部署的版本
+		SpecRevision: deployment.SpecRevision,
+		Mode:         deployment.Mode,
+	}
+	rollbackDeployment.AuditCreated(ctx)
+	// 记录被回滚替换掉的部署，用于统计变更失败率
+	current, err := s.Repo.GetLatestSuccessfulDeployment(ctx, deployment.AppID, deployment.EnvID)
+	if err != nil {
+		return err
+	}
+	if current != nil && current.ID != deployment.ID {
+		rollbackDeployment.RollbackOf = current.ID
+	}
+
+	// 恢复部署时使用的配置，配置已变化时生成新的配置版本
+	if deployment.ConfigRevisionID > 0 {
+		config, err := s.Config.restoreByID(ctx, deployment.ConfigRevisionID, "回滚部署 "+deployment.ID.String())
+		if err != nil {
+			return err
+		}
+		rollbackDeployment.ConfigRevisionID = config.ID
+		rollbackDeployment.ConfigRevision = config.Revision
+	}
+	// HPA配置没有版本，回滚不恢复HPA，记录回滚时生效的配置
+	if rollbackDeployment.Mode != enum.DeployModeJob {
+		if rollbackDeployment.HPA, err = s.HPA.deployedHPA(ctx, deployment.AppID, deployment.EnvID); err != nil {
+			return err
+		}
 	}
 
 	_, err = s.Repo.CreateDeployment(ctx, rollbackDeployment)
 	if err != nil {
 		return err
 	}
+	s.Locks.Attach(ctx, lease, rollbackDeployment.ID)
+	attached = true
 
 	// 执行回滚逻辑...
 	// 这里简单模拟回滚过程
@@ -251,56 +569,41 @@ func (s *DeployService) RollbackDeployment(ctx context.Context, id types.Long) e
 	rollbackDeployment.Status = domain.DeployStatusSuccess
 	endTime := time.Now()
 	rollbackDeployment.EndTime = &endTime
-	return s.Repo.UpdateDeployment(ctx, rollbackDeployment)
-}
-
-// CreateHPA 创建/更新应用HPA配置
-func (s *DeployService) CreateHPA(ctx context.Context, appID types.Long, minReplicas, maxReplicas, targetCPU, targetMemory int) (types.Long, error) {
-	// 检查应用是否存在
-	_, err := s.Repo.GetApplicationByID(ctx, appID)
-	if err != nil {
-		return 0, errors.New("应用不存在")
+	if err := s.Repo.UpdateDeployment(ctx, rollbackDeployment); err != nil {
+		return err
 	}
 
-	// 查看是否已存在HPA配置
-	existingHPA, err := s.Repo.GetAppHPAByAppID(ctx, appID)
-	if err == nil && existingHPA != nil {
-		// 更新现有配置
-		existingHPA.MinReplicas = minReplicas
-		existingHPA.MaxReplicas = maxReplicas
-		existingHPA.TargetCPU = targetCPU
-		existingHPA.TargetMemory = targetMemory
-
-		if err := s.Repo.UpdateAppHPA(ctx, existingHPA); err != nil {
-			return 0, err
-		}
-		return existingHPA.ID, nil
-	}
+	s.notify(ctx, &notification.Event{
+		Type:         notification.EventDeployRolledBack,
+		AppID:        rollbackDeployment.AppID,
+		EnvID:        rollbackDeployment.EnvID,
+		DeploymentID: rollbackDeployment.ID,
+		Version:      deployment.Version,
+		Message:      "已回滚部署记录 " + deployment.ID.String(),
+	})
+	return nil
+}
 
-	// 创建新的HPA配置
-	hpa := &domain.AppHPA{
-		AppID:        appID,
+// CreateHPA 创建/更新应用默认HPA配置
+func (s *DeployService) CreateHPA(ctx context.Context, appID types.Long, minReplicas, maxReplicas, targetCPU, targetMemory int) (types.Long, error) {
+	hpa, err := s.HPA.ConfigureHPA(ctx, appID, &domain.ConfigureHPACommand{
 		MinReplicas:  minReplicas,
 		MaxReplicas:  maxReplicas,
 		TargetCPU:    targetCPU,
 		TargetMemory: targetMemory,
+	})
+	if err != nil {
+		return 0, err
 	}
-
-	return s.Repo.CreateAppHPA(ctx, hpa)
+	return hpa.ID, nil
 }
 
-// GetAppHPA 获取应用HPA配置
+// GetAppHPA 获取应用默认HPA配置
 func (s *DeployService) GetAppHPA(ctx context.Context, appID types.Long) (*domain.AppHPA, error) {
-	return s.Repo.GetAppHPAByAppID(ctx, appID)
+	return s.HPA.GetHPA(ctx, appID, 0)
 }
 
-// DeleteAppHPA 删除应用HPA配置
+// DeleteAppHPA 删除应用默认HPA配置
 func (s *DeployService) DeleteAppHPA(ctx context.Context, appID types.Long) error {
-	// 获取HPA记录
-	hpa, err := s.Repo.GetAppHPAByAppID(ctx, appID)
-	if err != nil {
-		return err
-	}
-
-	return s.Repo.DeleteAppHPA(ctx, hpa.ID)
+	return s.HPA.DeleteHPA(ctx, appID, 0)
 }
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/pkg/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CommitSource 版本之间的提交来源，由构建模块根据构建记录实现
+type CommitSource interface {
+	// ListCommits 列出应用两个版本（镜像tag）之间的提交，没有两个版本的构建记录时返回nil
+	ListCommits(ctx context.Context, appID types.Long, fromVersion, toVersion string) (*domain.CommitRange, error)
+}
+
+// ReleaseDiffService 发布差异服务：比较发布计划与环境中当前运行的部署
+type ReleaseDiffService struct {
+	Repo     *repository.AppRepository `inject:"ApplicationRepository"`
+	Workload *WorkloadService          `inject:"workloadService"`
+	HPA      *HPAService               `inject:"hpaService"`
+	Commits  CommitSource              `inject:"BuildService"`
+}
+
+// NewReleaseDiffService 创建发布差异服务实例
+func NewReleaseDiffService() *ReleaseDiffService {
+	return &ReleaseDiffService{}
+}
+
+// releaseSide 比较的一侧：镜像、配置、渲染后的工作负载与HPA
+type releaseSide struct {
+	name     string
+	version  string
+	digest   string
+	image    string
+	config   *domain.AppConfigRevision
+	workload interface{}
+	hpa      *domain.HPASpec
+}
+
+// DiffReleasePlan 比较发布计划与应用在该环境中最近一次成功的部署：镜像、配置、工作负载定义、HPA与提交
+func (s *ReleaseDiffService) DiffReleasePlan(ctx context.Context, planID types.Long) (*domain.ReleaseDiffVO, error) {
+	plan, err := s.Repo.GetReleasePlanByID(ctx, planID)
+	if err != nil {
+		return nil, common.NotFoundError("发布计划不存在", err)
+	}
+	current, err := s.Repo.GetLatestSuccessfulDeployment(ctx, plan.AppID, plan.EnvID)
+	if err != nil {
+		return nil, common.InternalError("查询当前部署失败", err)
+	}
+
+	from, err := s.currentSide(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.planSide(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &domain.ReleaseDiffVO{
+		PlanID:  plan.ID,
+		AppID:   plan.AppID,
+		EnvID:   plan.EnvID,
+		Current: current,
+		Image: &domain.ImageDiff{
+			OldVersion: from.version,
+			NewVersion: to.version,
+			OldDigest:  from.digest,
+			NewDigest:  to.digest,
+			OldImage:   from.image,
+			NewImage:   to.image,
+			Changed:    from.version != to.version || from.digest != to.digest || from.image != to.image,
+		},
+	}
+	if to.config != nil {
+		diff.Config = domain.DiffConfig(from.config, to.config)
+	} else {
+		diff.Config = domain.DiffConfig(from.config, &domain.AppConfigRevision{})
+	}
+	if diff.Workload, err = domain.DiffObjects(from.workload, to.workload); err != nil {
+		return nil, common.InternalError("比较工作负载失败", err)
+	}
+	if diff.HPA, err = domain.DiffObjects(hpaObject(from.hpa), hpaObject(to.hpa)); err != nil {
+		return nil, common.InternalError("比较HPA失败", err)
+	}
+	diff.Changed = diff.Image.Changed || diff.Config.HasChanges() || len(diff.Workload) > 0 || len(diff.HPA) > 0
+	if diff.Unified, err = unifiedReleaseDiff(from, to); err != nil {
+		return nil, common.InternalError("生成文本差异失败", err)
+	}
+
+	// 提交列表只是补充信息，构建模块不可用时不影响差异结果
+	if s.Commits != nil && current != nil {
+		if diff.Commits, err = s.Commits.ListCommits(ctx, plan.AppID, current.ReleaseVersion(), plan.Version); err != nil {
+			logrus.WithError(err).WithField("plan", plan.ID).Warn("查询版本之间的提交失败")
+			diff.Commits = nil
+		}
+	}
+	return diff, nil
+}
+
+// currentSide 当前运行的部署使用的镜像、配置版本、工作负载定义版本与HPA，没有部署时为空
+// 回滚部署按其发布版本渲染，与集群中实际运行的镜像一致
+func (s *ReleaseDiffService) currentSide(ctx context.Context, deployment *domain.Deployment) (*releaseSide, error) {
+	side := &releaseSide{name: "(无部署)"}
+	if deployment == nil {
+		return side, nil
+	}
+	side.name = fmt.Sprintf("部署 %s (%s)", deployment.ID, deployment.Version)
+	side.version = deployment.ReleaseVersion()
+	side.digest = deployment.Digest
+	side.hpa = deployment.HPA.Spec()
+	if deployment.ConfigRevisionID > 0 {
+		config, err := s.Repo.GetConfigRevisionByID(ctx, deployment.ConfigRevisionID)
+		if err != nil {
+			return nil, common.InternalError("查询配置版本失败", err)
+		}
+		side.config = config
+	}
+	if deployment.SpecRevision > 0 {
+		if err := s.render(ctx, side, deployment.AppID, deployment.EnvID, deployment.SpecRevision); err != nil {
+			return nil, err
+		}
+	}
+	return side, nil
+}
+
+// planSide 执行发布计划时将使用的镜像、配置、最新工作负载定义与HPA，与执行时的取值方式一致
+func (s *ReleaseDiffService) planSide(ctx context.Context, plan *domain.ReleasePlan) (*releaseSide, error) {
+	side := &releaseSide{
+		name:    fmt.Sprintf("发布计划 %s (%s)", plan.ID, plan.Version),
+		version: plan.Version,
+		digest:  plan.Digest,
+	}
+	var err error
+	// 晋级产生的计划使用来源部署的配置版本
+	if plan.ConfigRevisionID > 0 {
+		side.config, err = s.Repo.GetConfigRevisionByID(ctx, plan.ConfigRevisionID)
+	} else {
+		side.config, err = s.Repo.GetLatestConfigRevision(ctx, plan.AppID, plan.EnvID)
+	}
+	if err != nil {
+		return nil, common.InternalError("查询配置版本失败", err)
+	}
+
+	spec, err := s.Repo.GetLatestWorkloadSpec(ctx, plan.AppID)
+	if err != nil {
+		return nil, common.InternalError("查询工作负载定义失败", err)
+	}
+	if spec == nil {
+		return side, nil
+	}
+	if err = s.render(ctx, side, plan.AppID, plan.EnvID, spec.Revision); err != nil {
+		return nil, err
+	}
+	if spec.Spec.Mode != enum.DeployModeJob {
+		hpa, err := s.HPA.deployedHPA(ctx, plan.AppID, plan.EnvID)
+		if err != nil {
+			return nil, err
+		}
+		side.hpa = hpa.Spec()
+	}
+	return side, nil
+}
+
+// render 按指定的工作负载定义版本渲染工作负载，使用该侧的版本、digest与配置
+func (s *ReleaseDiffService) render(ctx context.Context, side *releaseSide, appID, envID types.Long, revision int) error {
+	manifest, _, err := s.Workload.manifest(ctx, appID, envID, side.version, revision)
+	if err != nil {
+		return err
+	}
+	manifest.Digest = side.digest
+	manifest.Config = side.config
+	side.image = manifest.Image()
+	side.workload = workloadObject(manifest)
+	return nil
+}
+
+// hpaObject 没有HPA时返回nil，避免比较空规格的零值字段
+func hpaObject(spec *domain.HPASpec) interface{} {
+	if spec == nil {
+		return nil
+	}
+	return spec
+}
+
+// unifiedReleaseDiff 将镜像、配置、工作负载与HPA分别渲染为文本后生成统一格式差异
+func unifiedReleaseDiff(from, to *releaseSide) (string, error) {
+	fromWorkload, err := yamlText(from.workload)
+	if err != nil {
+		return "", err
+	}
+	toWorkload, err := yamlText(to.workload)
+	if err != nil {
+		return "", err
+	}
+	fromHPA, err := yamlText(hpaObject(from.hpa))
+	if err != nil {
+		return "", err
+	}
+	toHPA, err := yamlText(hpaObject(to.hpa))
+	if err != nil {
+		return "", err
+	}
+
+	sections := []struct {
+		name     string
+		from, to string
+	}{
+		{"image", imageText(from), imageText(to)},
+		{"config", domain.ConfigText(from.config), domain.ConfigText(to.config)},
+		{"workload", fromWorkload, toWorkload},
+		{"hpa", fromHPA, toHPA},
+	}
+	var b strings.Builder
+	for _, section := range sections {
+		b.WriteString(domain.UnifiedDiff(from.name+" "+section.name, to.name+" "+section.name, section.from, section.to))
+	}
+	return b.String(), nil
+}
+
+// imageText 镜像引用文本，没有工作负载定义时只有版本与digest
+func imageText(side *releaseSide) string {
+	if side.version == "" {
+		return ""
+	}
+	text := fmt.Sprintf("version: %s\n", side.version)
+	if side.digest != "" {
+		text += fmt.Sprintf("digest: %s\n", side.digest)
+	}
+	if side.image != "" {
+		text += fmt.Sprintf("image: %s\n", side.image)
+	}
+	return text
+}
+
+// yamlText 将对象渲染为YAML文本，nil时为空
+func yamlText(obj interface{}) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := kube.MarshalYAML(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
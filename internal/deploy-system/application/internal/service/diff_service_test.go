@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/pkg/types"
+	"testing"
+)
+
+// fakeCommits 记录查询提交时使用的版本
+type fakeCommits struct {
+	fromVersion, toVersion string
+}
+
+func (c *fakeCommits) ListCommits(_ context.Context, _ types.Long, fromVersion, toVersion string) (*domain.CommitRange, error) {
+	c.fromVersion, c.toVersion = fromVersion, toVersion
+	return nil, nil
+}
+
+func TestDiffReleasePlanAgainstRollbackDeployment(t *testing.T) {
+	drift, _, _, deployment := newDriftTest(t)
+	repo := drift.Repo
+	ctx := context.Background()
+	if err := repo.DB(ctx).AutoMigrate(&domain.ReleasePlan{}, &domain.AppHPA{}); err != nil {
+		t.Fatal(err)
+	}
+	// 当前运行的是回滚到v1.2.0的部署
+	if err := repo.DB(ctx).Model(deployment).Update("version", deployment.Version+domain.RollbackVersionSuffix).Error; err != nil {
+		t.Fatal(err)
+	}
+	plan := &domain.ReleasePlan{AppID: 1, EnvID: 1, Version: "v1.2.0", Status: domain.DeployStatusPending}
+	if err := repo.DB(ctx).Create(plan).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	commits := &fakeCommits{}
+	service := &ReleaseDiffService{Repo: repo, Workload: drift.Workload, HPA: &HPAService{Repo: repo}, Commits: commits}
+	diff, err := service.DiffReleasePlan(ctx, plan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Image.OldVersion != "v1.2.0" || diff.Image.OldImage != "registry/order:v1.2.0" || diff.Image.Changed {
+		t.Errorf("expected unchanged image registry/order:v1.2.0, got %s -> %s", diff.Image.OldImage, diff.Image.NewImage)
+	}
+	if len(diff.Workload) > 0 {
+		t.Errorf("expected no workload changes, got %v", diff.Workload)
+	}
+	if commits.fromVersion != "v1.2.0" || commits.toVersion != "v1.2.0" {
+		t.Errorf("expected commits between v1.2.0 and v1.2.0, got %s..%s", commits.fromVersion, commits.toVersion)
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/deploy-system/notification"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DriftService 配置漂移检测服务：比较集群中的工作负载与最近一次成功部署的镜像、副本数与HPA
+type DriftService struct {
+	Repo     *repository.AppRepository `inject:"ApplicationRepository"`
+	Workload *WorkloadService          `inject:"workloadService"`
+	Members  *MemberService            `inject:"memberService"`
+	Cluster  ClusterClient             `inject:"appClusterClient"`
+	Notifier notification.Publisher    `inject:"NotificationService"`
+}
+
+// NewDriftService 创建配置漂移检测服务实例
+func NewDriftService() *DriftService {
+	return &DriftService{}
+}
+
+// ReconcileDrift 检测所有应用环境的配置漂移，未接入集群时不检测
+func (s *DriftService) ReconcileDrift(ctx context.Context) {
+	deployments, err := s.Repo.ListLatestSuccessfulDeployments(ctx)
+	if err != nil {
+		logrus.Errorf("查询最近成功的部署失败: %v", err)
+		return
+	}
+	for _, deployment := range deployments {
+		if _, err = s.checkDeployment(ctx, deployment); err != nil {
+			if errors.Is(err, kube.ErrNotInCluster) {
+				return
+			}
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"app": deployment.AppID,
+				"env": deployment.EnvID,
+			}).Warn("检测配置漂移失败")
+		}
+	}
+}
+
+// CheckAppEnv 立即检测应用环境的配置漂移，返回未恢复的漂移，需要应用开发者及以上角色
+func (s *DriftService) CheckAppEnv(ctx context.Context, appID, envID types.Long) ([]*domain.DriftFinding, error) {
+	if err := s.Members.Authorize(ctx, appID, domain.AppRoleDeveloper); err != nil {
+		return nil, err
+	}
+	deployment, err := s.Repo.GetLatestSuccessfulDeployment(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询最近成功的部署失败", err)
+	}
+	if deployment == nil {
+		return nil, common.NotFoundError("应用在该环境还没有成功的部署", nil)
+	}
+	findings, err := s.checkDeployment(ctx, deployment)
+	if err != nil {
+		if errors.Is(err, kube.ErrNotInCluster) {
+			return nil, common.InternalError("集群客户端不可用，无法检测配置漂移", err)
+		}
+		return nil, err
+	}
+	return findings, nil
+}
+
+// ListDriftFindings 分页查询配置漂移
+func (s *DriftService) ListDriftFindings(ctx context.Context, query *domain.DriftQuery) ([]*domain.DriftFinding, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = 10
+	}
+	findings, total, err := s.Repo.ListDriftFindings(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询配置漂移失败", err)
+	}
+	return findings, total, nil
+}
+
+// checkDeployment 比较部署与集群中的工作负载并记录漂移，返回未恢复的漂移。
+// Job部署方式与没有工作负载定义的部署不在集群中常驻，不检测
+func (s *DriftService) checkDeployment(ctx context.Context, deployment *domain.Deployment) ([]*domain.DriftFinding, error) {
+	if deployment.Mode == enum.DeployModeJob || deployment.SpecRevision == 0 {
+		return nil, nil
+	}
+	manifest, env, err := s.Workload.manifest(ctx, deployment.AppID, deployment.EnvID, deployment.ReleaseVersion(), deployment.SpecRevision)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Digest = deployment.Digest
+	expected := domain.NewDeployment(manifest)
+
+	live, err := s.Cluster.GetDeployment(ctx, env, expected.Metadata.Name)
+	if err != nil {
+		return nil, err
+	}
+	var liveHPA *domain.KubeHPA
+	if live != nil {
+		if liveHPA, err = s.Cluster.GetHPA(ctx, env, expected.Metadata.Name); err != nil {
+			return nil, err
+		}
+	}
+	items := domain.DetectDrift(expected, deployment.HPA.Spec(), live, liveHPA)
+	return s.record(ctx, deployment, items, time.Now())
+}
+
+// record 记录新发现的漂移并通知，更新仍存在的漂移，集群已恢复的漂移标记为已恢复。
+// 工作负载不存在时无法比较其他项，保留其他项原来的状态
+func (s *DriftService) record(ctx context.Context, deployment *domain.Deployment, items []*domain.DriftItem, now time.Time) ([]*domain.DriftFinding, error) {
+	open, err := s.Repo.ListOpenDriftFindings(ctx, deployment.AppID, deployment.EnvID)
+	if err != nil {
+		return nil, common.InternalError("查询配置漂移失败", err)
+	}
+	existing := make(map[string]*domain.DriftFinding, len(open))
+	for _, finding := range open {
+		existing[finding.Field] = finding
+	}
+
+	findings := make([]*domain.DriftFinding, 0, len(items))
+	var detected []*domain.DriftFinding
+	workloadMissing := false
+	for _, item := range items {
+		workloadMissing = workloadMissing || item.Field == domain.DriftFieldWorkload
+		finding, ok := existing[item.Field]
+		delete(existing, item.Field)
+		if ok {
+			finding.DeploymentID = deployment.ID
+			finding.Expected, finding.Actual = item.Expected, item.Actual
+			finding.LastSeenAt = now
+			finding.AuditModified(ctx)
+			if err = s.Repo.UpdateDriftFinding(ctx, finding); err != nil {
+				return nil, common.InternalError("更新配置漂移失败", err)
+			}
+		} else {
+			finding = &domain.DriftFinding{
+				AppID:        deployment.AppID,
+				EnvID:        deployment.EnvID,
+				DeploymentID: deployment.ID,
+				Field:        item.Field,
+				Expected:     item.Expected,
+				Actual:       item.Actual,
+				Status:       domain.DriftStatusOpen,
+				DetectedAt:   now,
+				LastSeenAt:   now,
+			}
+			finding.AuditCreated(ctx)
+			if err = s.Repo.CreateDriftFinding(ctx, finding); err != nil {
+				return nil, common.InternalError("保存配置漂移失败", err)
+			}
+			detected = append(detected, finding)
+		}
+		findings = append(findings, finding)
+	}
+
+	for _, finding := range open {
+		if _, ok := existing[finding.Field]; !ok {
+			continue
+		}
+		if workloadMissing {
+			findings = append(findings, finding)
+			continue
+		}
+		resolvedAt := now
+		finding.Status = domain.DriftStatusResolved
+		finding.ResolvedAt = &resolvedAt
+		finding.AuditModified(ctx)
+		if err = s.Repo.UpdateDriftFinding(ctx, finding); err != nil {
+			return nil, common.InternalError("更新配置漂移失败", err)
+		}
+	}
+
+	if len(detected) > 0 {
+		s.notify(ctx, deployment, detected)
+	}
+	return findings, nil
+}
+
+// notify 新发现漂移时发送一次通知，列出全部新发现的漂移项
+func (s *DriftService) notify(ctx context.Context, deployment *domain.Deployment, detected []*domain.DriftFinding) {
+	if s.Notifier == nil {
+		return
+	}
+	lines := make([]string, 0, len(detected))
+	for _, finding := range detected {
+		lines = append(lines, fmt.Sprintf("%s: 期望 %s，实际 %s", finding.Field, finding.Expected, finding.Actual))
+	}
+	event := &notification.Event{
+		Type:         notification.EventDriftDetected,
+		AppID:        deployment.AppID,
+		EnvID:        deployment.EnvID,
+		DeploymentID: deployment.ID,
+		Version:      deployment.Version,
+		Message:      strings.Join(lines, "；"),
+	}
+	if app, err := s.Repo.GetApplicationByID(ctx, event.AppID); err == nil && app != nil {
+		event.AppName = app.Name
+	}
+	if env, err := s.Repo.GetAppEnvByID(ctx, event.EnvID); err == nil && env != nil {
+		event.EnvName = env.Name
+	}
+	s.Notifier.Publish(ctx, event)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/deploy-system/notification"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/internal/pkg/module"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeCluster 按名称返回预置的Deployment与HPA，err不为空时所有查询返回该错误
+type fakeCluster struct {
+	ClusterClient
+	deployments map[string]*domain.KubeDeployment
+	hpas        map[string]*domain.KubeHPA
+	err         error
+}
+
+func (c *fakeCluster) GetDeployment(_ context.Context, _ *domain.AppEnv, name string) (*domain.KubeDeployment, error) {
+	return c.deployments[name], c.err
+}
+
+func (c *fakeCluster) GetHPA(_ context.Context, _ *domain.AppEnv, name string) (*domain.KubeHPA, error) {
+	return c.hpas[name], c.err
+}
+
+// fakePublisher 记录发布的通知事件
+type fakePublisher struct {
+	events []*notification.Event
+}
+
+func (p *fakePublisher) Publish(_ context.Context, event *notification.Event) {
+	p.events = append(p.events, event)
+}
+
+// newDriftTest 使用内存SQLite与假集群客户端创建漂移检测服务，写入应用order在prod环境的一次成功部署：
+// 镜像 registry/order:v1.2.0，3个副本，没有HPA
+func newDriftTest(t *testing.T) (*DriftService, *fakeCluster, *fakePublisher, *domain.Deployment) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err = db.AutoMigrate(&domain.Application{}, &domain.AppEnv{}, &domain.Deployment{},
+		&domain.AppWorkloadSpec{}, &domain.AppWorkloadOverride{}, &domain.AppConfigRevision{},
+		&domain.AppVolume{}, &domain.AppVolumeMount{}, &domain.DriftFinding{}); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := int32(3)
+	seed := []interface{}{
+		&domain.Application{ID: 1, Name: "order", Status: domain.AppStatusActive},
+		&domain.AppEnv{Module: module.Module{ID: 1}, Name: "prod", Namespace: "prod"},
+		&domain.AppWorkloadSpec{AppID: 1, Revision: 1, Spec: domain.WorkloadSpec{Image: "registry/order", Replicas: &replicas}},
+	}
+	for _, record := range seed {
+		if err = db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	deployment := &domain.Deployment{AppID: 1, EnvID: 1, Version: "v1.2.0", Status: domain.DeployStatusSuccess,
+		StartTime: time.Now(), SpecRevision: 1}
+	if err = db.Create(deployment).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	repo := repository.NewAppRepository()
+	repo.Inject(func(string) interface{} { return db })
+	cluster := &fakeCluster{deployments: map[string]*domain.KubeDeployment{}, hpas: map[string]*domain.KubeHPA{}}
+	publisher := &fakePublisher{}
+	service := &DriftService{
+		Repo:     repo,
+		Workload: &WorkloadService{Repo: repo, Volumes: &VolumeService{Repo: repo, Cluster: cluster}},
+		Cluster:  cluster,
+		Notifier: publisher,
+	}
+	return service, cluster, publisher, deployment
+}
+
+// liveDeployment 集群中名为order的Deployment
+func liveDeployment(image string, replicas int32) *domain.KubeDeployment {
+	deployment := &domain.KubeDeployment{Metadata: domain.ObjectMeta{Name: "order"}}
+	deployment.Spec.Replicas = replicas
+	deployment.Spec.Template.Spec.Containers = []domain.KubeContainer{{Name: "order", Image: image}}
+	return deployment
+}
+
+func fieldsOf(findings []*domain.DriftFinding) map[string]*domain.DriftFinding {
+	result := make(map[string]*domain.DriftFinding, len(findings))
+	for _, finding := range findings {
+		result[finding.Field] = finding
+	}
+	return result
+}
+
+func TestCheckDeploymentWithoutDrift(t *testing.T) {
+	service, cluster, publisher, deployment := newDriftTest(t)
+	cluster.deployments["order"] = liveDeployment("registry/order:v1.2.0", 3)
+
+	findings, err := service.checkDeployment(context.Background(), deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 0 || len(publisher.events) != 0 {
+		t.Fatalf("expected no drift, got %d findings and %d events", len(findings), len(publisher.events))
+	}
+}
+
+func TestCheckDeploymentRecordsAndResolvesDrift(t *testing.T) {
+	service, cluster, publisher, deployment := newDriftTest(t)
+	ctx := context.Background()
+	cluster.deployments["order"] = liveDeployment("registry/order:v1.1.0", 5)
+
+	findings, err := service.checkDeployment(ctx, deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := fieldsOf(findings)
+	image, replicas := fields[domain.DriftFieldImage], fields[domain.DriftFieldReplicas]
+	if len(findings) != 2 || image == nil || replicas == nil {
+		t.Fatalf("expected image and replicas drift, got %+v", fields)
+	}
+	if image.Expected != "order=registry/order:v1.2.0" || image.Actual != "order=registry/order:v1.1.0" {
+		t.Errorf("unexpected image drift: %s -> %s", image.Expected, image.Actual)
+	}
+	if replicas.Expected != "3" || replicas.Actual != "5" {
+		t.Errorf("unexpected replicas drift: %s -> %s", replicas.Expected, replicas.Actual)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != notification.EventDriftDetected ||
+		publisher.events[0].AppName != "order" || publisher.events[0].EnvName != "prod" {
+		t.Fatalf("expected one drift event for order/prod, got %+v", publisher.events)
+	}
+
+	// 漂移仍存在时更新原记录，不重复通知
+	cluster.deployments["order"] = liveDeployment("registry/order:v1.2.0", 4)
+	findings, err = service.checkDeployment(ctx, deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].ID != replicas.ID || findings[0].Actual != "4" {
+		t.Fatalf("expected replicas drift %s updated to 4, got %+v", replicas.ID, findings)
+	}
+	if len(publisher.events) != 1 {
+		t.Errorf("expected no new event for existing drift, got %d events", len(publisher.events))
+	}
+
+	resolved, total, err := service.ListDriftFindings(ctx, &domain.DriftQuery{Status: domain.DriftStatusResolved})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || resolved[0].ID != image.ID || resolved[0].ResolvedAt == nil {
+		t.Fatalf("expected image drift resolved, got %+v", resolved)
+	}
+}
+
+func TestCheckDeploymentMissingWorkload(t *testing.T) {
+	service, _, publisher, deployment := newDriftTest(t)
+
+	findings, err := service.checkDeployment(context.Background(), deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Field != domain.DriftFieldWorkload || findings[0].Actual != domain.DriftAbsent {
+		t.Fatalf("expected missing workload drift, got %+v", findings)
+	}
+	if len(publisher.events) != 1 {
+		t.Errorf("expected one event, got %d", len(publisher.events))
+	}
+}
+
+func TestCheckDeploymentComparesHPA(t *testing.T) {
+	service, cluster, _, deployment := newDriftTest(t)
+	ctx := context.Background()
+	deployment.HPA = domain.DeployedHPA(domain.HPASpec{MinReplicas: 2, MaxReplicas: 10})
+	// 使用HPA时副本数由HPA调整，不比较副本数
+	cluster.deployments["order"] = liveDeployment("registry/order:v1.2.0", 7)
+
+	findings, err := service.checkDeployment(ctx, deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Field != domain.DriftFieldHPA || findings[0].Actual != domain.DriftAbsent {
+		t.Fatalf("expected missing HPA drift, got %+v", findings)
+	}
+
+	cluster.hpas["order"] = &domain.KubeHPA{Spec: domain.HPASpec{MinReplicas: 2, MaxReplicas: 10}}
+	if findings, err = service.checkDeployment(ctx, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 0 {
+		t.Fatalf("expected HPA drift resolved, got %+v", findings)
+	}
+}
+
+func TestReconcileDriftNotInCluster(t *testing.T) {
+	service, cluster, publisher, _ := newDriftTest(t)
+	cluster.err = kube.ErrNotInCluster
+
+	service.ReconcileDrift(context.Background())
+	findings, total, err := service.ListDriftFindings(context.Background(), &domain.DriftQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 || len(findings) != 0 || len(publisher.events) != 0 {
+		t.Fatalf("expected nothing recorded outside the cluster, got %d findings", total)
+	}
+}
+
+func TestReconcileDriftChecksLatestDeployment(t *testing.T) {
+	service, cluster, _, _ := newDriftTest(t)
+	ctx := context.Background()
+	// 回滚部署的版本带有后缀，期望的镜像使用原版本
+	rollback := &domain.Deployment{AppID: 1, EnvID: 1, Version: "v1.1.0" + domain.RollbackVersionSuffix,
+		Status: domain.DeployStatusSuccess, StartTime: time.Now(), SpecRevision: 1}
+	if _, err := service.Repo.CreateDeployment(ctx, rollback); err != nil {
+		t.Fatal(err)
+	}
+	cluster.deployments["order"] = liveDeployment("registry/order:v1.2.0", 3)
+
+	service.ReconcileDrift(ctx)
+	findings, total, err := service.ListDriftFindings(ctx, &domain.DriftQuery{AppID: 1, Status: domain.DriftStatusOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || findings[0].DeploymentID != rollback.ID || findings[0].Expected != "order=registry/order:v1.1.0" {
+		t.Fatalf("expected image drift against rollback %s, got %+v", rollback.ID, findings)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+)
+
+// GetEnvMatrix 环境矩阵：每个应用在每个环境中最近一次成功的部署，以及是否有待执行的发布计划
+func (s *AppService) GetEnvMatrix(ctx context.Context, query *domain.EnvMatrixQuery) (*domain.EnvMatrixVO, error) {
+	envs, err := s.Repo.ListAppEnvs(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询环境失败", err)
+	}
+	apps, err := s.Repo.ListMatrixApps(ctx, query)
+	if err != nil {
+		return nil, common.InternalError("查询应用失败", err)
+	}
+	appIDs := make([]types.Long, 0, len(apps))
+	for _, app := range apps {
+		appIDs = append(appIDs, app.ID)
+	}
+
+	deployments, err := s.Repo.ListCurrentDeployments(ctx, appIDs)
+	if err != nil {
+		return nil, common.InternalError("查询当前部署失败", err)
+	}
+	// 只统计比当前部署新的计划，早于当前部署或与当前部署版本相同的计划已过时
+	summaries, err := s.Repo.ListPendingPlanSummaries(ctx, appIDs)
+	if err != nil {
+		return nil, common.InternalError("查询待执行的发布计划失败", err)
+	}
+	planIDs := make([]types.Long, 0, len(summaries))
+	for _, summary := range summaries {
+		planIDs = append(planIDs, summary.LatestPlanID)
+	}
+	plans, err := s.Repo.ListReleasePlansByIDs(ctx, planIDs)
+	if err != nil {
+		return nil, common.InternalError("查询待执行的发布计划失败", err)
+	}
+
+	// 按 应用ID/环境ID 索引
+	type cellKey struct{ appID, envID types.Long }
+	current := make(map[cellKey]*domain.Deployment, len(deployments))
+	for _, deployment := range deployments {
+		current[cellKey{deployment.AppID, deployment.EnvID}] = deployment
+	}
+	latestPlans := make(map[types.Long]*domain.ReleasePlan, len(plans))
+	for _, plan := range plans {
+		latestPlans[plan.ID] = plan
+	}
+	pending := make(map[cellKey]*domain.PendingPlanSummary, len(summaries))
+	for _, summary := range summaries {
+		pending[cellKey{summary.AppID, summary.EnvID}] = summary
+	}
+
+	matrix := &domain.EnvMatrixVO{Envs: envs, Apps: make([]*domain.EnvMatrixRow, 0, len(apps))}
+	for _, app := range apps {
+		row := &domain.EnvMatrixRow{
+			AppID:   app.ID,
+			AppName: app.Name,
+			DeptID:  app.DeptID,
+			Cells:   make([]*domain.EnvMatrixCell, 0, len(envs)),
+		}
+		for _, env := range envs {
+			key := cellKey{app.ID, env.ID}
+			cell := &domain.EnvMatrixCell{EnvID: env.ID}
+			if deployment, ok := current[key]; ok {
+				cell.Current = domain.NewCurrentDeployment(deployment)
+			}
+			if summary, ok := pending[key]; ok {
+				cell.PendingPlans = summary.Plans
+				cell.PendingPlan = latestPlans[summary.LatestPlanID]
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		matrix.Apps = append(matrix.Apps, row)
+	}
+	return matrix, nil
+}
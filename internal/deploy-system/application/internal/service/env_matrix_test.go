@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"testing"
+	"time"
+)
+
+func TestEnvMatrixCountsOnlyPlansNewerThanCurrentDeployment(t *testing.T) {
+	trains, db := newTrainTest(t)
+	deployment := createDeployment(t, db, 1, "v2")
+	deployedAt := deployment.CreatedAt.Time
+	at := func(offset time.Duration) module.Module {
+		return module.Module{CreatedAt: types.Time{Time: deployedAt.Add(offset)}}
+	}
+	plans := []*domain.ReleasePlan{
+		// 早于当前部署的计划已过时
+		{Module: at(-time.Hour), AppID: 1, EnvID: 1, Version: "v1", Status: domain.DeployStatusPending},
+		// 与当前部署版本相同的计划已过时
+		{Module: at(time.Hour), AppID: 1, EnvID: 1, Version: "v2", Status: domain.DeployStatusApproved},
+		// 排队中的计划总会执行
+		{Module: at(-time.Hour), AppID: 1, EnvID: 1, Version: "v3", Status: domain.DeployStatusQueued},
+		{Module: at(time.Hour), AppID: 1, EnvID: 1, Version: "v4", Status: domain.DeployStatusApproved},
+		// 已执行的计划不统计
+		{Module: at(time.Hour), AppID: 1, EnvID: 1, Version: "v5", Status: domain.DeployStatusSuccess},
+		// 没有部署的应用环境中的计划都是新的
+		{Module: at(-time.Hour), AppID: 2, EnvID: 1, Version: "v1", Status: domain.DeployStatusPending},
+	}
+	if err := db.Create(&plans).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	service := &AppService{Repo: trains.Repo}
+	matrix, err := service.GetEnvMatrix(context.Background(), &domain.EnvMatrixQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := make(map[string]*domain.EnvMatrixCell)
+	for _, row := range matrix.Apps {
+		cells[row.AppName] = row.Cells[0]
+	}
+
+	order := cells["order"]
+	if order.Current == nil || order.Current.DeployID != deployment.ID {
+		t.Fatalf("expected current deployment %s for order", deployment.ID)
+	}
+	if order.PendingPlans != 2 || order.PendingPlan == nil || order.PendingPlan.Version != "v4" {
+		t.Errorf("expected 2 pending plans with latest v4 for order, got %d %+v", order.PendingPlans, order.PendingPlan)
+	}
+	pay := cells["pay"]
+	if pay.Current != nil || pay.PendingPlans != 1 || pay.PendingPlan == nil || pay.PendingPlan.ID != plans[5].ID {
+		t.Errorf("expected 1 pending plan without deployment for pay, got %d %+v", pay.PendingPlans, pay.PendingPlan)
+	}
+}
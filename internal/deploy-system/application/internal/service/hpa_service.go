@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/pkg/types"
+	"errors"
+)
+
+// HPAService 应用HPA服务：应用默认配置作用于所有环境，环境可单独配置
+type HPAService struct {
+	service.Service
+	Repo *repository.AppRepository `inject:"ApplicationRepository"`
+}
+
+// NewHPAService 创建应用HPA服务实例
+func NewHPAService() *HPAService {
+	return &HPAService{}
+}
+
+// GetHPA 获取应用在环境下单独的HPA配置，envID为0时获取应用默认配置
+func (s *HPAService) GetHPA(ctx context.Context, appID, envID types.Long) (*domain.AppHPA, error) {
+	hpa, err := s.Repo.GetAppHPA(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询HPA配置失败", err)
+	}
+	if hpa == nil {
+		if envID == 0 {
+			return nil, common.NotFoundError("应用没有HPA配置", nil)
+		}
+		return nil, common.NotFoundError("应用在该环境下没有单独的HPA配置", nil)
+	}
+	hpa.Normalize()
+	return hpa, nil
+}
+
+// EffectiveHPA 获取应用在环境下实际生效的HPA配置，环境没有单独配置时使用应用默认配置
+func (s *HPAService) EffectiveHPA(ctx context.Context, appID, envID types.Long) (*domain.AppHPA, error) {
+	hpa, err := s.findEffectiveHPA(ctx, appID, envID)
+	if err != nil {
+		return nil, err
+	}
+	if hpa == nil {
+		return nil, common.NotFoundError("应用没有HPA配置", nil)
+	}
+	return hpa, nil
+}
+
+// findEffectiveHPA 查找应用在环境下实际生效的HPA配置，没有配置时返回nil
+func (s *HPAService) findEffectiveHPA(ctx context.Context, appID, envID types.Long) (*domain.AppHPA, error) {
+	hpa, err := s.Repo.GetAppHPA(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询HPA配置失败", err)
+	}
+	if hpa == nil && envID != 0 {
+		if hpa, err = s.Repo.GetAppHPA(ctx, appID, 0); err != nil {
+			return nil, common.InternalError("查询HPA配置失败", err)
+		}
+	}
+	if hpa != nil {
+		hpa.Normalize()
+	}
+	return hpa, nil
+}
+
+// deployedHPA 应用在环境下生效的HPA规格，部署时记录以便比较发布前后的变化；没有HPA配置时返回空规格
+func (s *HPAService) deployedHPA(ctx context.Context, appID, envID types.Long) (domain.DeployedHPA, error) {
+	hpa, err := s.findEffectiveHPA(ctx, appID, envID)
+	if err != nil || hpa == nil {
+		return domain.DeployedHPA{}, err
+	}
+	app, err := s.Repo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		return domain.DeployedHPA{}, common.NotFoundError("应用不存在", err)
+	}
+	env, err := s.Repo.GetAppEnvByID(ctx, envID)
+	if err != nil {
+		return domain.DeployedHPA{}, common.NotFoundError("环境不存在", err)
+	}
+	return domain.DeployedHPA(domain.NewHPA(app.Name, env.Namespace, hpa).Spec), nil
+}
+
+// ListHPAs 查询应用的默认HPA配置与各环境的单独配置
+func (s *HPAService) ListHPAs(ctx context.Context, appID types.Long) ([]*domain.AppHPA, error) {
+	hpas, err := s.Repo.ListAppHPAs(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询HPA配置失败", err)
+	}
+	for _, hpa := range hpas {
+		hpa.Normalize()
+	}
+	return hpas, nil
+}
+
+// SaveHPA 保存应用默认或环境的HPA配置，已有配置时整体替换
+func (s *HPAService) SaveHPA(ctx context.Context, command *domain.SaveHPACommand) (*domain.AppHPA, error) {
+	if _, err := s.Repo.GetApplicationByID(ctx, command.AppID); err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	if command.EnvID != 0 {
+		if _, err := s.Repo.GetAppEnvByID(ctx, command.EnvID); err != nil {
+			return nil, common.RequestParamError("", errors.New("环境不存在"))
+		}
+	}
+	if err := command.Validate(); err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+	if err := s.checkScalable(ctx, command.AppID); err != nil {
+		return nil, err
+	}
+
+	hpa, err := s.Repo.GetAppHPA(ctx, command.AppID, command.EnvID)
+	if err != nil {
+		return nil, common.InternalError("查询HPA配置失败", err)
+	}
+	if hpa == nil {
+		hpa = &domain.AppHPA{}
+		command.ApplyTo(hpa)
+		hpa.AuditCreated(ctx)
+		if _, err = s.Repo.CreateAppHPA(ctx, hpa); err != nil {
+			return nil, common.InternalError("保存HPA配置失败", err)
+		}
+		return hpa, nil
+	}
+	command.ApplyTo(hpa)
+	hpa.AuditModified(ctx)
+	if err = s.Repo.UpdateAppHPA(ctx, hpa); err != nil {
+		return nil, common.InternalError("保存HPA配置失败", err)
+	}
+	return hpa, nil
+}
+
+// ConfigureHPA 以CPU、内存目标使用率的简写方式配置应用默认HPA
+func (s *HPAService) ConfigureHPA(ctx context.Context, appID types.Long, command *domain.ConfigureHPACommand) (*domain.AppHPA, error) {
+	existing, err := s.Repo.GetAppHPA(ctx, appID, 0)
+	if err != nil {
+		return nil, common.InternalError("查询HPA配置失败", err)
+	}
+	return s.SaveHPA(ctx, command.ToSaveCommand(appID, existing))
+}
+
+// DeleteHPA 删除应用默认或环境的HPA配置，环境删除后恢复使用应用默认配置
+func (s *HPAService) DeleteHPA(ctx context.Context, appID, envID types.Long) error {
+	hpa, err := s.GetHPA(ctx, appID, envID)
+	if err != nil {
+		return err
+	}
+	if err = s.Repo.DeleteAppHPA(ctx, hpa.ID); err != nil {
+		return common.InternalError("删除HPA配置失败", err)
+	}
+	return nil
+}
+
+// RenderHPA 将应用在环境下生效的HPA配置渲染为autoscaling/v2 YAML
+func (s *HPAService) RenderHPA(ctx context.Context, appID, envID types.Long) ([]byte, error) {
+	app, err := s.Repo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	env, err := s.Repo.GetAppEnvByID(ctx, envID)
+	if err != nil {
+		return nil, common.NotFoundError("环境不存在", err)
+	}
+	if err = s.checkScalable(ctx, appID); err != nil {
+		return nil, err
+	}
+	hpa, err := s.EffectiveHPA(ctx, appID, envID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := kube.MarshalYAML(domain.NewHPA(app.Name, env.Namespace, hpa))
+	if err != nil {
+		return nil, common.InternalError("生成HPA失败", err)
+	}
+	return data, nil
+}
+
+// checkScalable Job部署方式没有常驻副本，不能配置HPA
+func (s *HPAService) checkScalable(ctx context.Context, appID types.Long) error {
+	spec, err := s.Repo.GetLatestWorkloadSpec(ctx, appID)
+	if err != nil {
+		return common.InternalError("查询工作负载定义失败", err)
+	}
+	if spec != nil && spec.Spec.Mode == enum.DeployModeJob {
+		return common.RequestParamError("", errors.New("Job部署方式的应用不能配置HPA"))
+	}
+	return nil
+}
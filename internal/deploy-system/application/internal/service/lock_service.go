@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LockService 部署锁服务：同一应用在同一环境同时只能有一个部署，管理员可以冻结应用环境
+type LockService struct {
+	Repo *repository.AppRepository `inject:"ApplicationRepository"`
+}
+
+// NewLockService 创建部署锁服务实例
+func NewLockService() *LockService {
+	return &LockService{}
+}
+
+// Acquire 获取应用环境的部署租约，checkFreeze为true时冻结的应用环境不能获取。
+// 租约被其他部署持有或应用环境已冻结时返回冲突错误，错误数据为当前的锁
+func (s *LockService) Acquire(ctx context.Context, appID, envID types.Long, checkFreeze bool) (*domain.DeployLock, error) {
+	now := time.Now()
+	if checkFreeze {
+		freeze, err := s.Repo.GetDeployLock(ctx, appID, envID, domain.DeployLockFreeze)
+		if err != nil {
+			return nil, common.InternalError("查询部署锁失败", err)
+		}
+		if freeze != nil && !freeze.Expired(now) {
+			return nil, common.ConflictError(fmt.Sprintf("应用在该环境已冻结: %s", freeze.Reason), freeze)
+		}
+	}
+
+	expiresAt := now.Add(domain.DeployLockTTL)
+	lease := &domain.DeployLock{
+		AppID:     appID,
+		EnvID:     envID,
+		Kind:      domain.DeployLockLease,
+		ExpiresAt: &expiresAt,
+	}
+	lease.AuditCreated(ctx)
+	for attempt := 0; attempt < 2; attempt++ {
+		lease.ID = 0
+		created, err := s.Repo.CreateDeployLock(ctx, lease)
+		if err != nil {
+			return nil, common.InternalError("创建部署锁失败", err)
+		}
+		if created {
+			return lease, nil
+		}
+
+		// 租约已存在，过期的租约可以接管
+		current, err := s.Repo.GetDeployLock(ctx, appID, envID, domain.DeployLockLease)
+		if err != nil {
+			return nil, common.InternalError("查询部署锁失败", err)
+		}
+		if current == nil {
+			// 租约刚被释放，重试
+			continue
+		}
+		if !current.Expired(now) {
+			return nil, common.ConflictError("应用正在该环境部署，"+current.Holder(), current)
+		}
+		lease.ID = current.ID
+		ok, err := s.Repo.TakeOverDeployLock(ctx, lease, now)
+		if err != nil {
+			return nil, common.InternalError("接管部署锁失败", err)
+		}
+		if ok {
+			logrus.WithField("app", appID).WithField("env", envID).WithField("deployment", current.DeploymentID).
+				Warn("部署租约已过期，已被新的部署接管")
+			return lease, nil
+		}
+	}
+	return nil, common.ConflictError("部署锁正在被其他部署获取，请重试", nil)
+}
+
+// Attach 创建部署记录后将租约登记到部署记录，登记失败时租约到期后自动失效
+func (s *LockService) Attach(ctx context.Context, lease *domain.DeployLock, deploymentID types.Long) {
+	lease.DeploymentID = deploymentID
+	if err := s.Repo.UpdateDeployLock(ctx, lease); err != nil {
+		logrus.WithError(err).WithField("deployment", deploymentID).Error("登记部署锁失败")
+	}
+}
+
+// Abandon 没有创建部署记录时放弃获取的租约
+func (s *LockService) Abandon(ctx context.Context, lease *domain.DeployLock) {
+	if err := s.Repo.DeleteDeployLock(ctx, lease.ID); err != nil {
+		logrus.WithError(err).WithField("lock", lease.ID).Error("释放部署锁失败")
+	}
+}
+
+// Release 部署结束时释放部署记录持有的租约
+func (s *LockService) Release(ctx context.Context, deploymentID types.Long) {
+	if err := s.Repo.ReleaseDeployLock(ctx, deploymentID); err != nil {
+		logrus.WithError(err).WithField("deployment", deploymentID).Error("释放部署锁失败")
+	}
+}
+
+// KeepAlive 部署期间定期续期租约，返回停止续期的函数
+func (s *LockService) KeepAlive(ctx context.Context, deploymentID types.Long) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(domain.DeployLockRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := s.Repo.RenewDeployLock(ctx, deploymentID, time.Now().Add(domain.DeployLockTTL)); err != nil {
+					logrus.WithError(err).WithField("deployment", deploymentID).Warn("续期部署锁失败")
+				}
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// GetStatus 获取应用环境的部署锁与排队中的发布计划，过期的锁不返回
+func (s *LockService) GetStatus(ctx context.Context, appID, envID types.Long) (*domain.DeployLockStatus, error) {
+	if err := s.checkAppEnv(ctx, appID, envID); err != nil {
+		return nil, err
+	}
+	locks, err := s.Repo.ListDeployLocks(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询部署锁失败", err)
+	}
+	status := &domain.DeployLockStatus{AppID: appID, EnvID: envID}
+	now := time.Now()
+	for _, lock := range locks {
+		if lock.Expired(now) {
+			continue
+		}
+		switch lock.Kind {
+		case domain.DeployLockLease:
+			status.Lease = lock
+		case domain.DeployLockFreeze:
+			status.Freeze = lock
+		}
+	}
+	if status.Queue, err = s.Repo.ListQueuedReleasePlans(ctx, appID, envID); err != nil {
+		return nil, common.InternalError("查询排队中的发布计划失败", err)
+	}
+	return status, nil
+}
+
+// ListLocks 查询全部未过期的部署锁，appID或envID为0时不限制
+func (s *LockService) ListLocks(ctx context.Context, appID, envID types.Long) ([]*domain.DeployLock, error) {
+	locks, err := s.Repo.ListDeployLocks(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询部署锁失败", err)
+	}
+	now := time.Now()
+	active := make([]*domain.DeployLock, 0, len(locks))
+	for _, lock := range locks {
+		if !lock.Expired(now) {
+			active = append(active, lock)
+		}
+	}
+	return active, nil
+}
+
+// Freeze 冻结应用环境，冻结期间不能执行发布计划，只有系统管理员可以操作；已冻结时更新原因与期限
+func (s *LockService) Freeze(ctx context.Context, command *domain.FreezeCommand) (*domain.DeployLock, error) {
+	if err := requireSystemAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.checkAppEnv(ctx, command.AppID, command.EnvID); err != nil {
+		return nil, err
+	}
+	freeze, err := s.Repo.GetDeployLock(ctx, command.AppID, command.EnvID, domain.DeployLockFreeze)
+	if err != nil {
+		return nil, common.InternalError("查询部署锁失败", err)
+	}
+	if freeze == nil {
+		freeze = &domain.DeployLock{AppID: command.AppID, EnvID: command.EnvID, Kind: domain.DeployLockFreeze}
+		freeze.AuditCreated(ctx)
+	}
+	freeze.Reason = command.Reason
+	freeze.ExpiresAt = nil
+	if command.DurationMinutes > 0 {
+		expiresAt := time.Now().Add(time.Duration(command.DurationMinutes) * time.Minute)
+		freeze.ExpiresAt = &expiresAt
+	}
+	freeze.AuditModified(ctx)
+	if freeze.ID > 0 {
+		if err = s.Repo.UpdateDeployLock(ctx, freeze); err != nil {
+			return nil, common.InternalError("冻结应用环境失败", err)
+		}
+		return freeze, nil
+	}
+	created, err := s.Repo.CreateDeployLock(ctx, freeze)
+	if err != nil {
+		return nil, common.InternalError("冻结应用环境失败", err)
+	}
+	if !created {
+		return nil, common.ConflictError("应用环境正在被其他管理员冻结，请重试", nil)
+	}
+	return freeze, nil
+}
+
+// Unfreeze 解冻应用环境，只有系统管理员可以操作
+func (s *LockService) Unfreeze(ctx context.Context, appID, envID types.Long) error {
+	if err := requireSystemAdmin(ctx); err != nil {
+		return err
+	}
+	freeze, err := s.Repo.GetDeployLock(ctx, appID, envID, domain.DeployLockFreeze)
+	if err != nil {
+		return common.InternalError("查询部署锁失败", err)
+	}
+	if freeze == nil {
+		return common.NotFoundError("应用在该环境没有冻结", nil)
+	}
+	if err = s.Repo.DeleteDeployLock(ctx, freeze.ID); err != nil {
+		return common.InternalError("解冻应用环境失败", err)
+	}
+	return nil
+}
+
+// checkAppEnv 检查应用与环境存在
+func (s *LockService) checkAppEnv(ctx context.Context, appID, envID types.Long) error {
+	if _, err := s.Repo.GetApplicationByID(ctx, appID); err != nil {
+		return common.NotFoundError("应用不存在", err)
+	}
+	if _, err := s.Repo.GetAppEnvByID(ctx, envID); err != nil {
+		return common.NotFoundError("环境不存在", err)
+	}
+	return nil
+}
+
+// isLeaseConflict 是否因部署租约被其他部署持有而冲突，冻结导致的冲突不排队
+func isLeaseConflict(err error) bool {
+	var conflict *common.Error
+	if !errors.As(err, &conflict) || conflict.Type != common.ErrorTypeConflict {
+		return false
+	}
+	lock, ok := conflict.Data.(*domain.DeployLock)
+	return ok && lock.Kind == domain.DeployLockLease
+}
+
+// requireSystemAdmin 只有系统管理员可以执行
+func requireSystemAdmin(ctx context.Context) error {
+	user := security.GetUserContext(ctx)
+	if user == nil || !isSystemAdmin(user) {
+		return common.ForbiddenError("只有系统管理员可以冻结或解冻应用环境", nil)
+	}
+	return nil
+}
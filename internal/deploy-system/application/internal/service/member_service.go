@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/deploy-system/auth"
+	"devops-platform/internal/deploy-system/organization"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+)
+
+// MemberService 应用成员服务：成员角色在全局权限之外限制对单个应用的操作
+type MemberService struct {
+	service.Service
+	Repo        *repository.AppRepository      `inject:"ApplicationRepository"`
+	Users       auth.UserQuery                 `inject:"userQuery"`
+	Departments organization.DepartmentService `inject:"DepartmentService"`
+}
+
+// NewMemberService 创建应用成员服务实例
+func NewMemberService() *MemberService {
+	return &MemberService{}
+}
+
+// Authorize 检查当前用户在应用中的角色不低于required。
+// 没有用户上下文的后台任务与系统管理员不受限制；应用还没有成员时，创建人视为负责人
+func (s *MemberService) Authorize(ctx context.Context, appID types.Long, required string) error {
+	user := security.GetUserContext(ctx)
+	if user == nil || isSystemAdmin(user) {
+		return nil
+	}
+	role, err := s.memberRole(ctx, appID, user.UserID)
+	if err != nil {
+		return err
+	}
+	if !domain.AppRoleAtLeast(role, required) {
+		return common.ForbiddenError(fmt.Sprintf("需要应用%s及以上角色", domain.AppRoleName(required)), nil)
+	}
+	return nil
+}
+
+// GetOwnership 获取应用的所属部门、成员与值班联系人
+func (s *MemberService) GetOwnership(ctx context.Context, appID types.Long) (*domain.AppOwnershipVO, error) {
+	app, err := s.Repo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	members, err := s.Repo.ListAppMembers(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询应用成员失败", err)
+	}
+
+	ownership := &domain.AppOwnershipVO{
+		AppID:   appID,
+		DeptID:  app.DeptID,
+		Members: make([]*domain.AppMemberVO, 0, len(members)),
+		OnCall:  make([]*domain.AppMemberVO, 0),
+	}
+	if app.DeptID > 0 {
+		dept, err := s.Departments.GetDepartmentByID(ctx, app.DeptID)
+		if err != nil {
+			return nil, err
+		}
+		if dept != nil {
+			ownership.DeptName = dept.Name
+		}
+	}
+	for _, member := range members {
+		vo, err := s.memberVO(ctx, member)
+		if err != nil {
+			return nil, err
+		}
+		ownership.Members = append(ownership.Members, vo)
+		if member.OnCall {
+			ownership.OnCall = append(ownership.OnCall, vo)
+		}
+	}
+	return ownership, nil
+}
+
+// AddMember 添加应用成员，维护者可添加成员，只有负责人可添加负责人
+func (s *MemberService) AddMember(ctx context.Context, command *domain.AddMemberCommand) (member *domain.AppMember, err error) {
+	app, err := s.Repo.GetApplicationByID(ctx, command.AppID)
+	if err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	if err = s.authorizeManage(ctx, command.AppID, command.Role); err != nil {
+		return nil, err
+	}
+	if err = s.checkUser(ctx, command.UserID); err != nil {
+		return nil, err
+	}
+	existing, err := s.Repo.GetAppMember(ctx, command.AppID, command.UserID)
+	if err != nil {
+		return nil, common.InternalError("查询应用成员失败", err)
+	}
+	if existing != nil {
+		return nil, common.RequestParamError("", errors.New("用户已是应用成员"))
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "add app member")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "add app member")
+	}()
+
+	// 应用还没有成员时创建人视为负责人，添加第一个成员时将其登记为负责人
+	count, err := s.Repo.CountAppMembers(ctx, app.ID, "")
+	if err != nil {
+		return nil, common.InternalError("查询应用成员失败", err)
+	}
+	if count == 0 && app.Creator != 0 && app.Creator != command.UserID {
+		if err = s.AddOwner(ctx, app.ID, app.Creator); err != nil {
+			return nil, err
+		}
+	}
+
+	member = &domain.AppMember{
+		AppID:  command.AppID,
+		UserID: command.UserID,
+		Role:   command.Role,
+		OnCall: command.OnCall,
+	}
+	member.AuditCreated(ctx)
+	if _, err = s.Repo.CreateAppMember(ctx, member); err != nil {
+		return nil, common.InternalError("添加应用成员失败", err)
+	}
+	return member, nil
+}
+
+// UpdateMember 修改成员角色与值班标记，涉及负责人角色的修改只有负责人可以执行
+func (s *MemberService) UpdateMember(ctx context.Context, command *domain.UpdateMemberCommand) (*domain.AppMember, error) {
+	member, err := s.getMember(ctx, command.AppID, command.UserID)
+	if err != nil {
+		return nil, err
+	}
+	required := command.Role
+	if member.Role == domain.AppRoleOwner {
+		required = domain.AppRoleOwner
+	}
+	if err = s.authorizeManage(ctx, command.AppID, required); err != nil {
+		return nil, err
+	}
+	if member.Role == domain.AppRoleOwner && command.Role != domain.AppRoleOwner {
+		if err = s.checkLastOwner(ctx, command.AppID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = command.Role
+	if command.OnCall != nil {
+		member.OnCall = *command.OnCall
+	}
+	member.AuditModified(ctx)
+	if err = s.Repo.UpdateAppMember(ctx, member); err != nil {
+		return nil, common.InternalError("修改应用成员失败", err)
+	}
+	return member, nil
+}
+
+// RemoveMember 移除应用成员，应用至少保留一个负责人
+func (s *MemberService) RemoveMember(ctx context.Context, appID, userID types.Long) error {
+	member, err := s.getMember(ctx, appID, userID)
+	if err != nil {
+		return err
+	}
+	if err = s.authorizeManage(ctx, appID, member.Role); err != nil {
+		return err
+	}
+	if member.Role == domain.AppRoleOwner {
+		if err = s.checkLastOwner(ctx, appID); err != nil {
+			return err
+		}
+	}
+	if err = s.Repo.DeleteAppMember(ctx, member.ID); err != nil {
+		return common.InternalError("移除应用成员失败", err)
+	}
+	return nil
+}
+
+// AddOwner 将用户登记为应用负责人，用于创建应用时登记创建人
+func (s *MemberService) AddOwner(ctx context.Context, appID, userID types.Long) error {
+	member := &domain.AppMember{AppID: appID, UserID: userID, Role: domain.AppRoleOwner}
+	member.AuditCreated(ctx)
+	if _, err := s.Repo.CreateAppMember(ctx, member); err != nil {
+		return common.InternalError("登记应用负责人失败", err)
+	}
+	return nil
+}
+
+// CheckDepartment 检查应用所属部门在组织架构中存在
+func (s *MemberService) CheckDepartment(ctx context.Context, deptID types.Long) error {
+	dept, err := s.Departments.GetDepartmentByID(ctx, deptID)
+	if err != nil {
+		return err
+	}
+	if dept == nil {
+		return common.RequestParamError("", fmt.Errorf("部门 %s 不存在", deptID))
+	}
+	return nil
+}
+
+// authorizeManage 管理成员需要维护者角色，添加、修改或移除负责人需要负责人角色
+func (s *MemberService) authorizeManage(ctx context.Context, appID types.Long, role string) error {
+	if role == domain.AppRoleOwner {
+		return s.Authorize(ctx, appID, domain.AppRoleOwner)
+	}
+	return s.Authorize(ctx, appID, domain.AppRoleMaintainer)
+}
+
+// memberRole 获取用户在应用中的角色，不是成员时返回空
+func (s *MemberService) memberRole(ctx context.Context, appID, userID types.Long) (string, error) {
+	member, err := s.Repo.GetAppMember(ctx, appID, userID)
+	if err != nil {
+		return "", common.InternalError("查询应用成员失败", err)
+	}
+	if member != nil {
+		return member.Role, nil
+	}
+	count, err := s.Repo.CountAppMembers(ctx, appID, "")
+	if err != nil {
+		return "", common.InternalError("查询应用成员失败", err)
+	}
+	if count > 0 {
+		return "", nil
+	}
+	app, err := s.Repo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		return "", common.NotFoundError("应用不存在", err)
+	}
+	if app.Creator == userID {
+		return domain.AppRoleOwner, nil
+	}
+	return "", nil
+}
+
+// getMember 获取应用成员，不是成员时返回不存在错误
+func (s *MemberService) getMember(ctx context.Context, appID, userID types.Long) (*domain.AppMember, error) {
+	member, err := s.Repo.GetAppMember(ctx, appID, userID)
+	if err != nil {
+		return nil, common.InternalError("查询应用成员失败", err)
+	}
+	if member == nil {
+		return nil, common.NotFoundError("用户不是应用成员", nil)
+	}
+	return member, nil
+}
+
+// checkLastOwner 应用只剩一个负责人时不能移除或降级
+func (s *MemberService) checkLastOwner(ctx context.Context, appID types.Long) error {
+	owners, err := s.Repo.CountAppMembers(ctx, appID, domain.AppRoleOwner)
+	if err != nil {
+		return common.InternalError("查询应用成员失败", err)
+	}
+	if owners <= 1 {
+		return common.RequestParamError("", errors.New("应用至少需要一个负责人"))
+	}
+	return nil
+}
+
+// checkUser 检查用户存在
+func (s *MemberService) checkUser(ctx context.Context, userID types.Long) error {
+	user, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		return common.InternalError("查询用户失败", err)
+	}
+	if user == nil {
+		return common.RequestParamError("", fmt.Errorf("用户 %s 不存在", userID))
+	}
+	return nil
+}
+
+// memberVO 组装成员视图，用户已被删除时只返回用户ID
+func (s *MemberService) memberVO(ctx context.Context, member *domain.AppMember) (*domain.AppMemberVO, error) {
+	vo := &domain.AppMemberVO{
+		UserID:   member.UserID,
+		Role:     member.Role,
+		OnCall:   member.OnCall,
+		JoinedAt: member.CreatedAt,
+	}
+	user, err := s.Users.GetByID(ctx, member.UserID)
+	if err != nil {
+		return nil, common.InternalError("查询用户失败", err)
+	}
+	if user != nil {
+		vo.Username = user.Username
+		vo.Name = user.Name
+		vo.Mobile = user.Mobile
+		vo.Email = user.Email
+		vo.DeptName = user.DeptName
+	}
+	return vo, nil
+}
+
+// isSystemAdmin 系统管理员不受应用成员角色限制
+func isSystemAdmin(user *security.UserContext) bool {
+	return user.TokenInfo != nil && enum.SysRole(user.TokenInfo.Role) == enum.SysRoleAdminUser
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+)
+
+// PromotionService 环境晋级服务：版本按应用的晋级路径逐个环境发布，如 dev → test → uat → prod
+type PromotionService struct {
+	service.Service
+	Repo    *repository.AppRepository `inject:"ApplicationRepository"`
+	Deploy  *DeployService            `inject:"deployService"`
+	Members *MemberService            `inject:"memberService"`
+}
+
+// NewPromotionService 创建环境晋级服务实例
+func NewPromotionService() *PromotionService {
+	return &PromotionService{}
+}
+
+// GetPath 获取应用的晋级路径，附带每个阶段最近一次成功的部署；没有配置时返回空
+func (s *PromotionService) GetPath(ctx context.Context, appID types.Long) ([]*domain.PromotionStageVO, error) {
+	if _, err := s.Repo.GetApplicationByID(ctx, appID); err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	stages, err := s.Repo.ListPromotionStages(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询晋级路径失败", err)
+	}
+
+	path := make([]*domain.PromotionStageVO, 0, len(stages))
+	for _, stage := range stages {
+		vo := &domain.PromotionStageVO{
+			Position: stage.Position,
+			EnvID:    stage.EnvID,
+			Optional: stage.Optional,
+		}
+		if env, err := s.Repo.GetAppEnvByID(ctx, stage.EnvID); err == nil {
+			vo.EnvName = env.Name
+		}
+		if vo.LastDeployment, err = s.Repo.GetLatestSuccessfulDeployment(ctx, appID, stage.EnvID); err != nil {
+			return nil, common.InternalError("查询部署记录失败", err)
+		}
+		path = append(path, vo)
+	}
+	return path, nil
+}
+
+// SavePath 保存应用的晋级路径，整体替换原有路径，需要应用维护者及以上角色
+func (s *PromotionService) SavePath(ctx context.Context, command *domain.SavePromotionPathCommand) (path []*domain.PromotionStageVO, err error) {
+	if _, err = s.Repo.GetApplicationByID(ctx, command.AppID); err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	if err = command.Validate(); err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+	for _, stage := range command.Stages {
+		if _, err = s.Repo.GetAppEnvByID(ctx, stage.EnvID); err != nil {
+			return nil, common.RequestParamError("", fmt.Errorf("环境 %s 不存在", stage.EnvID))
+		}
+	}
+	if err = s.Members.Authorize(ctx, command.AppID, domain.AppRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	if err = s.replace(ctx, command.AppID, command.NewStages()); err != nil {
+		return nil, err
+	}
+	return s.GetPath(ctx, command.AppID)
+}
+
+// DeletePath 删除应用的晋级路径，删除后发布计划不再受阶段限制，需要应用维护者及以上角色
+func (s *PromotionService) DeletePath(ctx context.Context, appID types.Long) error {
+	if _, err := s.Repo.GetApplicationByID(ctx, appID); err != nil {
+		return common.NotFoundError("应用不存在", err)
+	}
+	if err := s.Members.Authorize(ctx, appID, domain.AppRoleMaintainer); err != nil {
+		return err
+	}
+	return s.replace(ctx, appID, nil)
+}
+
+// Promote 将来源阶段部署成功的镜像digest与配置版本晋级到下一阶段，生成待审批的发布计划
+func (s *PromotionService) Promote(ctx context.Context, command *domain.PromoteCommand) (*domain.PromotionResult, error) {
+	if _, err := s.Repo.GetApplicationByID(ctx, command.AppID); err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	if err := s.Members.Authorize(ctx, command.AppID, domain.AppRoleDeveloper); err != nil {
+		return nil, err
+	}
+	stages, err := s.Repo.ListPromotionStages(ctx, command.AppID)
+	if err != nil {
+		return nil, common.InternalError("查询晋级路径失败", err)
+	}
+	if len(stages) == 0 {
+		return nil, common.RequestParamError("", errors.New("应用没有配置晋级路径"))
+	}
+	var target *domain.AppPromotionStage
+	for i, stage := range stages {
+		if stage.EnvID != command.FromEnvID {
+			continue
+		}
+		if i == len(stages)-1 {
+			return nil, common.RequestParamError("", errors.New("来源环境已是晋级路径的最后一个阶段"))
+		}
+		target = stages[i+1]
+	}
+	if target == nil {
+		return nil, common.RequestParamError("", fmt.Errorf("环境 %s 不在应用的晋级路径中", command.FromEnvID))
+	}
+
+	source, err := s.source(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	strategy := command.Strategy
+	if strategy == "" {
+		strategy = domain.DeployStrategyRolling
+	}
+	plan := &domain.ReleasePlan{
+		AppID:            command.AppID,
+		EnvID:            target.EnvID,
+		Version:          source.ReleaseVersion(),
+		Strategy:         strategy,
+		Status:           domain.DeployStatusPending,
+		Digest:           source.Digest,
+		ConfigRevisionID: source.ConfigRevisionID,
+		PromotedFrom:     source.ID,
+	}
+	planID, err := s.Deploy.createPlan(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PromotionResult{
+		PlanID:           planID,
+		FromEnvID:        command.FromEnvID,
+		ToEnvID:          target.EnvID,
+		DeploymentID:     source.ID,
+		Version:          source.ReleaseVersion(),
+		Digest:           source.Digest,
+		ConfigRevisionID: source.ConfigRevisionID,
+	}, nil
+}
+
+// source 获取晋级来源的部署记录，未指定时使用来源环境最近一次成功的部署
+// 来源可能是回滚部署，晋级使用其发布版本
+func (s *PromotionService) source(ctx context.Context, command *domain.PromoteCommand) (*domain.Deployment, error) {
+	if command.DeploymentID == 0 {
+		deployment, err := s.Repo.GetLatestSuccessfulDeployment(ctx, command.AppID, command.FromEnvID)
+		if err != nil {
+			return nil, common.InternalError("查询部署记录失败", err)
+		}
+		if deployment == nil {
+			return nil, common.RequestParamError("", errors.New("来源环境没有部署成功的版本"))
+		}
+		return deployment, nil
+	}
+
+	deployment, err := s.Repo.GetDeploymentByID(ctx, command.DeploymentID)
+	if err != nil {
+		return nil, common.NotFoundError("部署记录不存在", err)
+	}
+	if deployment.AppID != command.AppID || deployment.EnvID != command.FromEnvID {
+		return nil, common.RequestParamError("", errors.New("部署记录不属于来源环境"))
+	}
+	if deployment.Status != domain.DeployStatusSuccess {
+		return nil, common.RequestParamError("", errors.New("只能晋级部署成功的版本"))
+	}
+	return deployment, nil
+}
+
+// replace 在事务中替换应用的晋级路径
+func (s *PromotionService) replace(ctx context.Context, appID types.Long, stages []*domain.AppPromotionStage) (err error) {
+	ctx, err = s.BeginTransaction(ctx, "save promotion path")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "save promotion path")
+	}()
+
+	for _, stage := range stages {
+		stage.AuditCreated(ctx)
+	}
+	if err = s.Repo.ReplacePromotionStages(ctx, appID, stages); err != nil {
+		return common.InternalError("保存晋级路径失败", err)
+	}
+	return nil
+}
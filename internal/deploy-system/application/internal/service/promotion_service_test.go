@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"testing"
+)
+
+func TestPromoteRollbackDeploymentUsesReleaseVersion(t *testing.T) {
+	trains, db := newTrainTest(t)
+	if err := db.AutoMigrate(&domain.AppPromotionStage{}); err != nil {
+		t.Fatal(err)
+	}
+	stages := []*domain.AppPromotionStage{{AppID: 1, EnvID: 1, Position: 1}, {AppID: 1, EnvID: 2, Position: 2}}
+	if err := db.Create(&stages).Error; err != nil {
+		t.Fatal(err)
+	}
+	original := createDeployment(t, db, 1, "v1")
+	broken := createDeployment(t, db, 1, "v2")
+	rollback := createDeployment(t, db, 1, original.Version+domain.RollbackVersionSuffix)
+	if err := db.Model(rollback).Update("rollback_of", broken.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	service := &PromotionService{Repo: trains.Repo, Deploy: trains.Deploy, Members: trains.Members}
+	result, err := service.Promote(context.Background(), &domain.PromoteCommand{AppID: 1, FromEnvID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DeploymentID != rollback.ID || result.Version != original.Version {
+		t.Errorf("expected rollback deployment %s promoted as %s, got %s as %s", rollback.ID, original.Version, result.DeploymentID, result.Version)
+	}
+	plan, err := service.Repo.GetReleasePlanByID(context.Background(), result.PlanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Version != original.Version || plan.EnvID != 2 {
+		t.Errorf("expected plan for %s in env 2, got %s in env %s", original.Version, plan.Version, plan.EnvID)
+	}
+}
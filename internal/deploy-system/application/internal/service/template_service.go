@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BuildConfigurer 按模板保存应用的构建配置，由构建模块实现
+type BuildConfigurer interface {
+	// SaveTemplateBuildConfig 校验并保存构建配置，返回构建配置ID
+	SaveTemplateBuildConfig(ctx context.Context, config *domain.TemplateBuildConfig) (types.Long, error)
+}
+
+// TemplateService 应用模板服务：按模板在一个事务中创建应用及工作负载定义、HPA、环境配置、镜像仓库关联与构建配置
+type TemplateService struct {
+	service.Service
+	Repo    *repository.AppRepository `inject:"ApplicationRepository"`
+	Members *MemberService            `inject:"memberService"`
+	HPA     *HPAService               `inject:"hpaService"`
+	Config  *ConfigService            `inject:"configService"`
+	Builds  BuildConfigurer           `inject:"BuildService"`
+}
+
+// NewTemplateService 创建应用模板服务实例
+func NewTemplateService() *TemplateService {
+	return &TemplateService{}
+}
+
+// ListTemplates 查询内置的应用模板
+func (s *TemplateService) ListTemplates() []*domain.AppTemplate {
+	return domain.AppTemplates
+}
+
+// CreateFromTemplate 按模板创建应用，任一步失败时全部回滚
+func (s *TemplateService) CreateFromTemplate(ctx context.Context, command *domain.CreateFromTemplateCommand) (result *domain.TemplateAppVO, err error) {
+	template, err := command.Validate()
+	if err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+	if existApp, _ := s.Repo.GetApplicationByName(ctx, command.Name); existApp != nil {
+		return nil, common.RequestParamError("", errors.New("应用名称已存在"))
+	}
+	if command.DeptID > 0 {
+		if err = s.Members.CheckDepartment(ctx, command.DeptID); err != nil {
+			return nil, err
+		}
+	}
+	registry, err := s.Repo.GetImageRegistryByID(ctx, command.RegistryID)
+	if err != nil {
+		return nil, common.RequestParamError("", errors.New("镜像仓库不存在"))
+	}
+	spec := template.Spec
+	spec.Image = domain.TemplateImage(registry.URL, command.ImageRepository)
+	if err = spec.Validate(); err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "create app from template")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "create app from template")
+	}()
+
+	app, err := s.createApp(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	result = &domain.TemplateAppVO{
+		AppID:             app.ID,
+		Template:          template.Name,
+		ConfigRevisionIDs: make([]types.Long, 0, len(command.EnvIDs)),
+		GroupIDs:          make([]types.Long, 0, len(command.GroupIDs)),
+	}
+
+	for _, groupID := range command.GroupIDs {
+		if _, err = s.Repo.GetAppGroupByID(ctx, groupID); err != nil {
+			return nil, common.RequestParamError("", fmt.Errorf("分组不存在: %s", groupID))
+		}
+		if err = s.Repo.AddAppToGroup(ctx, app.ID, groupID); err != nil {
+			return nil, common.InternalError("添加应用到分组失败", err)
+		}
+		result.GroupIDs = append(result.GroupIDs, groupID)
+	}
+
+	specCommand := &domain.SaveWorkloadSpecCommand{AppID: app.ID, WorkloadSpec: spec, Comment: "应用模板 " + template.Name}
+	workload := specCommand.NewRevision(1)
+	workload.AuditCreated(ctx)
+	if _, err = s.Repo.CreateWorkloadSpec(ctx, workload); err != nil {
+		return nil, common.InternalError("保存工作负载定义失败", err)
+	}
+	result.SpecRevision = workload.Revision
+
+	if template.HPA != nil {
+		hpa, err := s.HPA.SaveHPA(ctx, &domain.SaveHPACommand{
+			AppID:       app.ID,
+			MinReplicas: template.HPA.MinReplicas,
+			MaxReplicas: template.HPA.MaxReplicas,
+			Metrics:     template.HPA.Metrics,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.HPAID = hpa.ID
+	}
+
+	for _, envID := range command.EnvIDs {
+		if err = s.Config.checkAppEnv(ctx, app.ID, envID); err != nil {
+			return nil, err
+		}
+		configCommand := &domain.SaveConfigCommand{AppID: app.ID, EnvID: envID, Envs: template.Envs,
+			Comment: "应用模板 " + template.Name}
+		if err = configCommand.Validate(); err != nil {
+			return nil, common.RequestParamError("", err)
+		}
+		revision := configCommand.NewRevision(1)
+		if err = s.Config.createRevision(ctx, revision); err != nil {
+			return nil, err
+		}
+		result.ConfigRevisionIDs = append(result.ConfigRevisionIDs, revision.ID)
+	}
+
+	link := &domain.AppImageRegistry{AppID: app.ID, RegistryID: registry.ID}
+	link.AuditCreated(ctx)
+	if _, err = s.Repo.CreateAppImageRegistry(ctx, link); err != nil {
+		return nil, common.InternalError("关联镜像仓库失败", err)
+	}
+	result.RegistryID = registry.ID
+
+	if command.RepoURL != "" && template.BuildMethod != enum.BuildMethodNone {
+		build := &domain.TemplateBuildConfig{
+			AppID:           app.ID,
+			RepoURL:         strings.TrimSpace(command.RepoURL),
+			BuildMethod:     template.BuildMethod,
+			RegistryID:      registry.ID,
+			ImageRepository: command.ImageRepository,
+			TemplateBuild:   template.Build,
+		}
+		if command.Branch != "" {
+			build.Branch = command.Branch
+		}
+		if result.BuildConfigID, err = s.Builds.SaveTemplateBuildConfig(ctx, build); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// createApp 创建应用，创建人与所属部门取当前用户，创建人登记为应用负责人
+func (s *TemplateService) createApp(ctx context.Context, command *domain.CreateFromTemplateCommand) (*domain.Application, error) {
+	app := &domain.Application{
+		Name:        command.Name,
+		Description: command.Description,
+		DeptID:      command.DeptID,
+		Status:      domain.AppStatusActive,
+	}
+	if user := security.GetUserContext(ctx); user != nil {
+		app.Creator = user.UserID
+		if app.DeptID == 0 {
+			app.DeptID = user.DeptID
+		}
+	}
+	if _, err := s.Repo.CreateApplication(ctx, app); err != nil {
+		return nil, common.InternalError("创建应用失败", err)
+	}
+	if app.Creator != 0 {
+		if err := s.Members.AddOwner(ctx, app.ID, app.Creator); err != nil {
+			return nil, err
+		}
+	}
+	return app, nil
+}
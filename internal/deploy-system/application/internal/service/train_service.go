@@ -0,0 +1,520 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TrainService 发布列车服务：按依赖关系依次执行多个应用的发布计划，
+// 成员状态由部署记录同步，列车状态由成员状态汇总
+type TrainService struct {
+	service.Service
+	Repo    *repository.AppRepository `inject:"ApplicationRepository"`
+	Deploy  *DeployService            `inject:"deployService"`
+	Members *MemberService            `inject:"memberService"`
+
+	// 推进列车时加锁，避免执行操作与定时推进重复启动同一个成员；回滚部署耗时较长，在锁外执行
+	mu sync.Mutex
+	// 正在回滚成员的列车，回滚期间不推进也不能中止
+	rollingBack map[types.Long]bool
+}
+
+// trainRollback 结束发布列车时需要执行的回滚，按执行顺序倒序排列
+type trainRollback struct {
+	train   *domain.ReleaseTrain
+	members []*domain.ReleaseTrainMember
+	steps   []*trainRollbackStep
+}
+
+// trainRollbackStep 成员回滚到的部署：成员部署之前该应用环境最近一次成功的部署
+type trainRollbackStep struct {
+	member *domain.ReleaseTrainMember
+	target *domain.Deployment
+}
+
+// NewTrainService 创建发布列车服务实例
+func NewTrainService() *TrainService {
+	return &TrainService{rollingBack: make(map[types.Long]bool)}
+}
+
+// CreateTrain 创建发布列车，成员的发布计划必须待执行且不属于其他列车
+func (s *TrainService) CreateTrain(ctx context.Context, command *domain.CreateTrainCommand) (train *domain.ReleaseTrain, err error) {
+	members, err := command.ExecutionOrder()
+	if err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+	for _, member := range members {
+		plan, err := s.Repo.GetReleasePlanByID(ctx, member.PlanID)
+		if err != nil {
+			return nil, common.RequestParamError("", fmt.Errorf("发布计划 %s 不存在", member.PlanID))
+		}
+		if err = checkTrainPlan(plan); err != nil {
+			return nil, err
+		}
+		existing, err := s.Repo.GetTrainMemberByPlan(ctx, plan.ID)
+		if err != nil {
+			return nil, common.InternalError("查询发布列车失败", err)
+		}
+		if existing != nil {
+			return nil, common.RequestParamError("", fmt.Errorf("发布计划 %s 已属于发布列车 %s", plan.ID, existing.TrainID))
+		}
+		member.AppID = plan.AppID
+		member.EnvID = plan.EnvID
+		member.Version = plan.Version
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "create release train")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "create release train")
+	}()
+
+	train = command.NewTrain()
+	train.AuditCreated(ctx)
+	if _, err = s.Repo.CreateReleaseTrain(ctx, train); err != nil {
+		return nil, common.InternalError("创建发布列车失败", err)
+	}
+	for _, member := range members {
+		member.TrainID = train.ID
+		member.AuditCreated(ctx)
+	}
+	if err = s.Repo.CreateTrainMembers(ctx, members); err != nil {
+		return nil, common.InternalError("创建发布列车成员失败", err)
+	}
+	train.Members = members
+	return train, nil
+}
+
+// GetTrain 获取发布列车及其成员，执行中的成员状态按部署记录实时同步
+func (s *TrainService) GetTrain(ctx context.Context, id types.Long) (*domain.ReleaseTrain, error) {
+	train, err := s.getTrain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if train.Members, err = s.Repo.ListTrainMembers(ctx, id); err != nil {
+		return nil, common.InternalError("查询发布列车成员失败", err)
+	}
+	for _, member := range train.Members {
+		if _, err = s.sync(ctx, member); err != nil {
+			return nil, err
+		}
+	}
+	return train, nil
+}
+
+// ListTrains 分页查询发布列车，不含成员
+func (s *TrainService) ListTrains(ctx context.Context, query *domain.TrainQuery) ([]*domain.ReleaseTrain, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = 10
+	}
+	trains, total, err := s.Repo.ListReleaseTrains(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询发布列车失败", err)
+	}
+	return trains, total, nil
+}
+
+// DeleteTrain 删除待执行的发布列车，成员的发布计划恢复为可单独执行
+func (s *TrainService) DeleteTrain(ctx context.Context, id types.Long) (err error) {
+	train, err := s.getTrain(ctx, id)
+	if err != nil {
+		return err
+	}
+	if train.Status != domain.TrainStatusPending {
+		return common.RequestParamError("", errors.New("只有待执行的发布列车可以删除"))
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "delete release train")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "delete release train")
+	}()
+
+	if err = s.Repo.DeleteReleaseTrain(ctx, id); err != nil {
+		return common.InternalError("删除发布列车失败", err)
+	}
+	return nil
+}
+
+// Act 执行或中止发布列车
+func (s *TrainService) Act(ctx context.Context, id types.Long, command *domain.TrainActionCommand) (*domain.ReleaseTrain, error) {
+	var err error
+	switch command.Action {
+	case domain.TrainActionExecute:
+		err = s.execute(ctx, id)
+	case domain.TrainActionAbort:
+		err = s.abort(ctx, id, command.Rollback)
+	default:
+		err = common.RequestParamError("", fmt.Errorf("不支持的操作: %s", command.Action))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTrain(ctx, id)
+}
+
+// AdvanceTrains 推进全部执行中与中止中的发布列车，由定时任务调用
+func (s *TrainService) AdvanceTrains(ctx context.Context) {
+	trains, err := s.Repo.ListActiveReleaseTrains(ctx)
+	if err != nil {
+		logrus.Errorf("查询执行中的发布列车失败: %v", err)
+		return
+	}
+	for _, train := range trains {
+		if err = s.advance(ctx, train.ID); err != nil {
+			logrus.WithError(err).WithField("train", train.ID).Warn("推进发布列车失败")
+		}
+	}
+}
+
+// execute 开始执行发布列车，需要每个成员应用的开发者及以上角色
+func (s *TrainService) execute(ctx context.Context, id types.Long) error {
+	s.mu.Lock()
+	rollback, err := s.start(ctx, id)
+	s.mu.Unlock()
+	if err != nil || rollback == nil {
+		return err
+	}
+	return s.rollback(ctx, rollback)
+}
+
+// start 校验成员并启动没有依赖的成员，调用方负责加锁
+func (s *TrainService) start(ctx context.Context, id types.Long) (*trainRollback, error) {
+	train, err := s.getTrain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if train.Status != domain.TrainStatusPending {
+		return nil, common.RequestParamError("", errors.New("只有待执行的发布列车可以执行"))
+	}
+	members, err := s.Repo.ListTrainMembers(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询发布列车成员失败", err)
+	}
+	for _, member := range members {
+		if err = s.Members.Authorize(ctx, member.AppID, domain.AppRoleDeveloper); err != nil {
+			return nil, err
+		}
+		plan, err := s.Repo.GetReleasePlanByID(ctx, member.PlanID)
+		if err != nil {
+			return nil, common.NotFoundError(fmt.Sprintf("发布计划 %s 不存在", member.PlanID), err)
+		}
+		if err = checkTrainPlan(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	train.Status = domain.TrainStatusRunning
+	train.StartedAt = &now
+	train.AuditModified(ctx)
+	if err = s.Repo.UpdateReleaseTrain(ctx, train); err != nil {
+		return nil, common.InternalError("更新发布列车失败", err)
+	}
+	// 立即启动没有依赖的成员，后续成员由定时任务推进
+	return s.step(ctx, id)
+}
+
+// abort 中止发布列车：不再启动新的成员，运行中的部署结束后按需回滚已部署成功的成员
+func (s *TrainService) abort(ctx context.Context, id types.Long, rollback bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rollingBack[id] {
+		return common.RequestParamError("", errors.New("发布列车正在回滚，不能中止"))
+	}
+	train, err := s.getTrain(ctx, id)
+	if err != nil {
+		return err
+	}
+	if train.Status != domain.TrainStatusRunning {
+		return common.RequestParamError("", errors.New("只有执行中的发布列车可以中止"))
+	}
+	members, err := s.Repo.ListTrainMembers(ctx, id)
+	if err != nil {
+		return common.InternalError("查询发布列车成员失败", err)
+	}
+	for _, member := range members {
+		if err = s.Members.Authorize(ctx, member.AppID, domain.AppRoleDeveloper); err != nil {
+			return err
+		}
+	}
+
+	train.Status = domain.TrainStatusAborted
+	train.RollbackOnAbort = rollback
+	train.AuditModified(ctx)
+	if err = s.Repo.UpdateReleaseTrain(ctx, train); err != nil {
+		return common.InternalError("更新发布列车失败", err)
+	}
+	return nil
+}
+
+// advance 推进发布列车，列车结束需要回滚时在锁外执行回滚
+func (s *TrainService) advance(ctx context.Context, id types.Long) error {
+	s.mu.Lock()
+	rollback, err := s.step(ctx, id)
+	s.mu.Unlock()
+	if err != nil || rollback == nil {
+		return err
+	}
+	return s.rollback(ctx, rollback)
+}
+
+// step 同步成员状态，启动依赖已完成的成员；没有可推进的成员时结束列车，
+// 需要回滚时返回待执行的回滚，调用方负责加锁
+func (s *TrainService) step(ctx context.Context, id types.Long) (*trainRollback, error) {
+	if s.rollingBack[id] {
+		return nil, nil
+	}
+	train, err := s.getTrain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if train.Finished() || (train.Status != domain.TrainStatusRunning && train.Status != domain.TrainStatusAborted) {
+		return nil, nil
+	}
+	members, err := s.Repo.ListTrainMembers(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询发布列车成员失败", err)
+	}
+	byPlan := make(map[types.Long]*domain.ReleaseTrainMember, len(members))
+	failed := false
+	for _, member := range members {
+		byPlan[member.PlanID] = member
+		changed, err := s.sync(ctx, member)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			if err = s.Repo.UpdateTrainMember(ctx, member); err != nil {
+				return nil, common.InternalError("更新发布列车成员失败", err)
+			}
+		}
+		failed = failed || member.Status == domain.TrainMemberFailed
+	}
+
+	stopping := train.Status == domain.TrainStatusAborted || (failed && train.StopOnFailure)
+	if !stopping {
+		// 按执行顺序启动，依赖总是排在前面，同一轮中依赖失败的成员会被跳过
+		for _, member := range members {
+			if member.Status != domain.TrainMemberWaiting {
+				continue
+			}
+			ready, blocked := dependencyState(member, byPlan)
+			switch {
+			case blocked:
+				member.Status = domain.TrainMemberSkipped
+				member.Message = "依赖的成员未部署成功"
+			case ready:
+				s.launch(ctx, member)
+			default:
+				continue
+			}
+			if err = s.Repo.UpdateTrainMember(ctx, member); err != nil {
+				return nil, common.InternalError("更新发布列车成员失败", err)
+			}
+		}
+	}
+
+	for _, member := range members {
+		if member.Status == domain.TrainMemberRunning {
+			return nil, nil
+		}
+		if member.Status == domain.TrainMemberWaiting && !stopping {
+			return nil, nil
+		}
+	}
+	return s.finish(ctx, train, members)
+}
+
+// launch 执行成员的发布计划，部署租约被占用时继续等待，其他原因无法启动时成员失败
+func (s *TrainService) launch(ctx context.Context, member *domain.ReleaseTrainMember) {
+	plan, err := s.Repo.GetReleasePlanByID(ctx, member.PlanID)
+	if err == nil {
+		member.DeploymentID, err = s.Deploy.executePlan(ctx, plan)
+	}
+	if err != nil && isLeaseConflict(err) {
+		// 应用正在该环境部署，成员继续等待，下一轮推进时重试
+		member.Message = truncate("等待部署锁: "+err.Error(), 500)
+		return
+	}
+	if err != nil {
+		member.Status = domain.TrainMemberFailed
+		member.Message = truncate("启动部署失败: "+err.Error(), 500)
+		return
+	}
+	member.Status = domain.TrainMemberRunning
+	member.Message = ""
+}
+
+// finish 结束发布列车：跳过未启动的成员，需要回滚时按执行顺序倒序确定已部署成功的成员回滚到的部署，
+// 由调用方在锁外执行回滚；不需要回滚时直接汇总列车状态。调用方负责加锁
+func (s *TrainService) finish(ctx context.Context, train *domain.ReleaseTrain, members []*domain.ReleaseTrainMember) (*trainRollback, error) {
+	failed := false
+	for _, member := range members {
+		if member.Status == domain.TrainMemberWaiting {
+			member.Status = domain.TrainMemberSkipped
+			member.Message = "发布列车已停止"
+			if err := s.Repo.UpdateTrainMember(ctx, member); err != nil {
+				return nil, common.InternalError("更新发布列车成员失败", err)
+			}
+		}
+		failed = failed || member.Status == domain.TrainMemberFailed
+	}
+
+	aborted := train.Status == domain.TrainStatusAborted
+	if (aborted && train.RollbackOnAbort) || (!aborted && failed && train.RollbackOnFailure) {
+		rollback := &trainRollback{train: train, members: members}
+		for i := len(members) - 1; i >= 0; i-- {
+			member := members[i]
+			if member.Status != domain.TrainMemberSuccess {
+				continue
+			}
+			target, err := s.Repo.GetSuccessfulDeploymentBefore(ctx, member.AppID, member.EnvID, member.DeploymentID)
+			if err != nil {
+				return nil, common.InternalError("查询部署记录失败", err)
+			}
+			if target == nil {
+				member.Status = domain.TrainMemberRollbackFailed
+				member.Message = "应用在该环境没有更早的成功部署，无法回滚"
+				if err = s.Repo.UpdateTrainMember(ctx, member); err != nil {
+					return nil, common.InternalError("更新发布列车成员失败", err)
+				}
+				continue
+			}
+			rollback.steps = append(rollback.steps, &trainRollbackStep{member: member, target: target})
+		}
+		if len(rollback.steps) > 0 {
+			s.rollingBack[train.ID] = true
+			return rollback, nil
+		}
+	}
+	return nil, s.complete(ctx, train, members)
+}
+
+// rollback 在锁外依次将成员回滚到部署之前的版本，全部回滚后结束列车
+func (s *TrainService) rollback(ctx context.Context, rollback *trainRollback) error {
+	var updateErr error
+	for _, step := range rollback.steps {
+		member := step.member
+		if err := s.Deploy.RollbackDeployment(ctx, step.target.ID); err != nil {
+			member.Status = domain.TrainMemberRollbackFailed
+			member.Message = truncate("回滚失败: "+err.Error(), 500)
+		} else {
+			member.Status = domain.TrainMemberRolledBack
+			member.Message = fmt.Sprintf("发布列车已回滚到版本 %s（部署记录 %s）", step.target.Version, step.target.ID)
+		}
+		if err := s.Repo.UpdateTrainMember(ctx, member); err != nil && updateErr == nil {
+			updateErr = common.InternalError("更新发布列车成员失败", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rollingBack, rollback.train.ID)
+	if updateErr != nil {
+		// 列车不结束，下一轮推进时重新回滚状态未更新的成员
+		return updateErr
+	}
+	return s.complete(ctx, rollback.train, rollback.members)
+}
+
+// complete 汇总列车状态并结束列车，调用方负责加锁
+func (s *TrainService) complete(ctx context.Context, train *domain.ReleaseTrain, members []*domain.ReleaseTrainMember) error {
+	now := time.Now()
+	if train.Status != domain.TrainStatusAborted {
+		train.Status = domain.AggregateTrainStatus(members)
+	}
+	train.FinishedAt = &now
+	if err := s.Repo.UpdateReleaseTrain(ctx, train); err != nil {
+		return common.InternalError("更新发布列车失败", err)
+	}
+	logrus.WithField("train", train.ID).WithField("status", train.Status).Info("发布列车已结束")
+	return nil
+}
+
+// sync 按部署记录同步运行中成员的状态，返回状态是否变化
+func (s *TrainService) sync(ctx context.Context, member *domain.ReleaseTrainMember) (bool, error) {
+	if member.Status != domain.TrainMemberRunning || member.DeploymentID == 0 {
+		return false, nil
+	}
+	deployment, err := s.Repo.GetDeploymentByID(ctx, member.DeploymentID)
+	if err != nil {
+		return false, common.InternalError("查询部署记录失败", err)
+	}
+	switch deployment.Status {
+	case domain.DeployStatusSuccess:
+		member.Status = domain.TrainMemberSuccess
+	case domain.DeployStatusFailed:
+		member.Status = domain.TrainMemberFailed
+		member.Message = "部署失败"
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// getTrain 获取发布列车，不存在时返回不存在错误
+func (s *TrainService) getTrain(ctx context.Context, id types.Long) (*domain.ReleaseTrain, error) {
+	train, err := s.Repo.GetReleaseTrain(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询发布列车失败", err)
+	}
+	if train == nil {
+		return nil, common.NotFoundError("发布列车不存在", nil)
+	}
+	return train, nil
+}
+
+// checkTrainPlan 列车成员的发布计划必须待处理或已审批
+func checkTrainPlan(plan *domain.ReleasePlan) error {
+	if plan.Status != domain.DeployStatusPending && plan.Status != domain.DeployStatusApproved {
+		return common.RequestParamError("", fmt.Errorf("发布计划 %s 已执行，不能加入发布列车", plan.ID))
+	}
+	return nil
+}
+
+// dependencyState 成员的依赖是否都已部署成功，以及是否有依赖已不可能成功
+func dependencyState(member *domain.ReleaseTrainMember, byPlan map[types.Long]*domain.ReleaseTrainMember) (ready, blocked bool) {
+	ready = true
+	for _, planID := range member.DependsOn {
+		dep, ok := byPlan[planID]
+		if !ok {
+			continue
+		}
+		switch dep.Status {
+		case domain.TrainMemberSuccess:
+		case domain.TrainMemberFailed, domain.TrainMemberSkipped, domain.TrainMemberRolledBack:
+			return false, true
+		default:
+			ready = false
+		}
+	}
+	return ready, false
+}
+
+// truncate 按字符截断过长的消息
+func truncate(message string, size int) string {
+	runes := []rune(message)
+	if len(runes) <= size {
+		return message
+	}
+	return string(runes[:size])
+}
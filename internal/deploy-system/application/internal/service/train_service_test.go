@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTrainTest 使用内存SQLite创建发布列车服务，写入应用order、pay与环境prod
+func newTrainTest(t *testing.T) (*TrainService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err = db.AutoMigrate(&domain.Application{}, &domain.AppEnv{}, &domain.Deployment{}, &domain.ReleasePlan{},
+		&domain.ReleaseTrain{}, &domain.ReleaseTrainMember{}, &domain.DeployLock{}, &domain.AppHPA{}); err != nil {
+		t.Fatal(err)
+	}
+	seed := []interface{}{
+		&domain.Application{ID: 1, Name: "order", Status: domain.AppStatusActive},
+		&domain.Application{ID: 2, Name: "pay", Status: domain.AppStatusActive},
+		&domain.AppEnv{Module: module.Module{ID: 1}, Name: "prod", Namespace: "prod"},
+	}
+	for _, record := range seed {
+		if err = db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := repository.NewAppRepository()
+	repo.Inject(func(string) interface{} { return db })
+	service := NewTrainService()
+	service.Repo = repo
+	service.Members = &MemberService{Repo: repo}
+	service.Deploy = &DeployService{
+		Repo:    repo,
+		HPA:     &HPAService{Repo: repo},
+		Members: service.Members,
+		Locks:   &LockService{Repo: repo},
+	}
+	return service, db
+}
+
+// createDeployment 写入应用在prod环境的一次成功部署
+func createDeployment(t *testing.T, db *gorm.DB, appID types.Long, version string) *domain.Deployment {
+	deployment := &domain.Deployment{AppID: appID, EnvID: 1, Version: version, Status: domain.DeployStatusSuccess, StartTime: time.Now()}
+	if err := db.Create(deployment).Error; err != nil {
+		t.Fatal(err)
+	}
+	return deployment
+}
+
+// createFailedTrain 写入开启失败回滚的执行中列车：order部署成功，pay部署失败
+func createFailedTrain(t *testing.T, db *gorm.DB, orderDeployment *domain.Deployment) *domain.ReleaseTrain {
+	train := &domain.ReleaseTrain{Name: "release", Status: domain.TrainStatusRunning, StopOnFailure: true, RollbackOnFailure: true}
+	if err := db.Create(train).Error; err != nil {
+		t.Fatal(err)
+	}
+	members := []*domain.ReleaseTrainMember{
+		{TrainID: train.ID, PlanID: 11, AppID: orderDeployment.AppID, EnvID: orderDeployment.EnvID, Version: orderDeployment.Version,
+			Position: 1, Status: domain.TrainMemberSuccess, DeploymentID: orderDeployment.ID},
+		{TrainID: train.ID, PlanID: 12, AppID: 2, EnvID: 1, Version: "v3", Position: 2, Status: domain.TrainMemberFailed},
+	}
+	if err := db.Create(&members).Error; err != nil {
+		t.Fatal(err)
+	}
+	return train
+}
+
+func TestTrainRollbackRestoresPreviousVersion(t *testing.T) {
+	service, db := newTrainTest(t)
+	ctx := context.Background()
+	previous := createDeployment(t, db, 1, "v1")
+	deployed := createDeployment(t, db, 1, "v2")
+	train := createFailedTrain(t, db, deployed)
+
+	if err := service.advance(ctx, train.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	live, err := service.Repo.GetLatestSuccessfulDeployment(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if live.ID == deployed.ID || live.Version != previous.Version+domain.RollbackVersionSuffix {
+		t.Fatalf("expected %s to be live after rollback, got %s", previous.Version, live.Version)
+	}
+	if live.RollbackOf != deployed.ID {
+		t.Errorf("expected rollback of deployment %s, got %s", deployed.ID, live.RollbackOf)
+	}
+
+	result, err := service.GetTrain(ctx, train.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != domain.TrainStatusRolledBack || !result.Finished() {
+		t.Errorf("expected finished rolled back train, got %s", result.Status)
+	}
+	if member := result.Members[0]; member.Status != domain.TrainMemberRolledBack || !strings.Contains(member.Message, previous.Version) {
+		t.Errorf("expected member rolled back to %s, got %s: %s", previous.Version, member.Status, member.Message)
+	}
+	if service.rollingBack[train.ID] {
+		t.Error("expected rollback marker to be cleared")
+	}
+}
+
+func TestTrainRollbackWithoutPreviousDeployment(t *testing.T) {
+	service, db := newTrainTest(t)
+	ctx := context.Background()
+	deployed := createDeployment(t, db, 1, "v2")
+	train := createFailedTrain(t, db, deployed)
+
+	if err := service.advance(ctx, train.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	live, err := service.Repo.GetLatestSuccessfulDeployment(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if live.ID != deployed.ID {
+		t.Fatalf("expected %s to stay live, got %s", deployed.Version, live.Version)
+	}
+	result, err := service.GetTrain(ctx, train.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != domain.TrainStatusFailed {
+		t.Errorf("expected failed train, got %s", result.Status)
+	}
+	if member := result.Members[0]; member.Status != domain.TrainMemberRollbackFailed || member.Message == "" {
+		t.Errorf("expected member rollback failure with message, got %s: %s", member.Status, member.Message)
+	}
+}
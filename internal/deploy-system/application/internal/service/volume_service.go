@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// VolumeService 共享存储卷服务：维护存储卷目录，以及应用在各环境下的挂载
+type VolumeService struct {
+	service.Service
+	Repo    *repository.AppRepository `inject:"ApplicationRepository"`
+	Cluster ClusterClient             `inject:"appClusterClient"`
+}
+
+// NewVolumeService 创建共享存储卷服务实例
+func NewVolumeService() *VolumeService {
+	return &VolumeService{}
+}
+
+// ListVolumes 查询存储卷目录，envID不为0时只返回该环境可用的卷
+func (s *VolumeService) ListVolumes(ctx context.Context, envID types.Long) ([]*domain.AppVolume, error) {
+	volumes, err := s.Repo.ListVolumes(ctx, envID)
+	if err != nil {
+		return nil, common.InternalError("查询存储卷失败", err)
+	}
+	return volumes, nil
+}
+
+// GetVolume 获取存储卷
+func (s *VolumeService) GetVolume(ctx context.Context, id types.Long) (*domain.AppVolume, error) {
+	volume, err := s.Repo.GetVolumeByID(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询存储卷失败", err)
+	}
+	if volume == nil {
+		return nil, common.NotFoundError("存储卷不存在", nil)
+	}
+	return volume, nil
+}
+
+// CreateVolume 向目录中添加存储卷，PVC类型会检查所属环境中PVC是否存在
+func (s *VolumeService) CreateVolume(ctx context.Context, command *domain.SaveVolumeCommand) (*domain.AppVolume, error) {
+	if err := s.checkVolume(ctx, command); err != nil {
+		return nil, err
+	}
+	volume := &domain.AppVolume{}
+	command.ApplyTo(volume)
+	volume.AuditCreated(ctx)
+	if _, err := s.Repo.CreateVolume(ctx, volume); err != nil {
+		return nil, common.InternalError("创建存储卷失败", err)
+	}
+	return volume, nil
+}
+
+// UpdateVolume 修改存储卷，已挂载的PVC存储卷不能改到其他环境
+func (s *VolumeService) UpdateVolume(ctx context.Context, command *domain.SaveVolumeCommand) (*domain.AppVolume, error) {
+	volume, err := s.GetVolume(ctx, command.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkVolume(ctx, command); err != nil {
+		return nil, err
+	}
+	command.ApplyTo(volume)
+
+	mounts, err := s.Repo.ListVolumeMountsByVolume(ctx, volume.ID)
+	if err != nil {
+		return nil, common.InternalError("查询存储卷挂载失败", err)
+	}
+	for _, mount := range mounts {
+		if !volume.AvailableIn(mount.EnvID) {
+			return nil, common.RequestParamError("", fmt.Errorf("存储卷已被应用 %d 在环境 %d 中挂载，不能限定到其他环境", mount.AppID, mount.EnvID))
+		}
+	}
+
+	volume.AuditModified(ctx)
+	if err = s.Repo.UpdateVolume(ctx, volume); err != nil {
+		return nil, common.InternalError("更新存储卷失败", err)
+	}
+	return volume, nil
+}
+
+// DeleteVolume 删除存储卷，仍被挂载时不能删除
+func (s *VolumeService) DeleteVolume(ctx context.Context, id types.Long) error {
+	if _, err := s.GetVolume(ctx, id); err != nil {
+		return err
+	}
+	mounts, err := s.Repo.ListVolumeMountsByVolume(ctx, id)
+	if err != nil {
+		return common.InternalError("查询存储卷挂载失败", err)
+	}
+	if len(mounts) > 0 {
+		return common.RequestParamError("", fmt.Errorf("存储卷仍有 %d 处挂载，请先解除挂载", len(mounts)))
+	}
+	if err = s.Repo.DeleteVolume(ctx, id); err != nil {
+		return common.InternalError("删除存储卷失败", err)
+	}
+	return nil
+}
+
+// ListMounts 查询应用的存储卷挂载，envID为0时返回所有环境
+func (s *VolumeService) ListMounts(ctx context.Context, appID, envID types.Long) ([]*domain.VolumeMountVO, error) {
+	mounts, err := s.Repo.ListVolumeMounts(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询存储卷挂载失败", err)
+	}
+
+	volumeIDs := make([]types.Long, 0, len(mounts))
+	for _, mount := range mounts {
+		volumeIDs = append(volumeIDs, mount.VolumeID)
+	}
+	volumes, err := s.Repo.ListVolumesByIDs(ctx, volumeIDs)
+	if err != nil {
+		return nil, common.InternalError("查询存储卷失败", err)
+	}
+	volumeMap := make(map[types.Long]*domain.AppVolume, len(volumes))
+	for _, volume := range volumes {
+		volumeMap[volume.ID] = volume
+	}
+	envs, err := s.Repo.ListAppEnvs(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询环境失败", err)
+	}
+	envNames := make(map[types.Long]string, len(envs))
+	for _, env := range envs {
+		envNames[env.ID] = env.Name
+	}
+
+	result := make([]*domain.VolumeMountVO, 0, len(mounts))
+	for _, mount := range mounts {
+		result = append(result, &domain.VolumeMountVO{
+			AppVolumeMount: mount,
+			EnvName:        envNames[mount.EnvID],
+			Volume:         volumeMap[mount.VolumeID],
+		})
+	}
+	return result, nil
+}
+
+// PodVolumes 渲染应用在环境下的Pod卷与容器挂载
+func (s *VolumeService) PodVolumes(ctx context.Context, appID, envID types.Long) (*domain.PodVolumesVO, error) {
+	mounts, err := s.ListMounts(ctx, appID, envID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mount := range mounts {
+		if mount.Volume == nil {
+			return nil, common.InternalError(fmt.Sprintf("挂载 %s 的存储卷 %d 不存在", mount.MountPath, mount.VolumeID), nil)
+		}
+	}
+	volumes, volumeMounts := domain.NewPodVolumes(mounts)
+	return &domain.PodVolumesVO{Mounts: mounts, Volumes: volumes, VolumeMounts: volumeMounts}, nil
+}
+
+// CreateMount 为应用在环境中挂载存储卷
+func (s *VolumeService) CreateMount(ctx context.Context, command *domain.SaveVolumeMountCommand) (*domain.AppVolumeMount, error) {
+	if err := s.checkMount(ctx, command); err != nil {
+		return nil, err
+	}
+	mount := &domain.AppVolumeMount{}
+	command.ApplyTo(mount)
+	mount.AuditCreated(ctx)
+	if _, err := s.Repo.CreateVolumeMount(ctx, mount); err != nil {
+		return nil, common.InternalError("创建存储卷挂载失败", err)
+	}
+	return mount, nil
+}
+
+// UpdateMount 修改应用的存储卷挂载
+func (s *VolumeService) UpdateMount(ctx context.Context, command *domain.SaveVolumeMountCommand) (*domain.AppVolumeMount, error) {
+	mount, err := s.getMount(ctx, command.AppID, command.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkMount(ctx, command); err != nil {
+		return nil, err
+	}
+	command.ApplyTo(mount)
+	mount.AuditModified(ctx)
+	if err = s.Repo.UpdateVolumeMount(ctx, mount); err != nil {
+		return nil, common.InternalError("更新存储卷挂载失败", err)
+	}
+	return mount, nil
+}
+
+// DeleteMount 解除应用的存储卷挂载
+func (s *VolumeService) DeleteMount(ctx context.Context, appID, id types.Long) error {
+	if _, err := s.getMount(ctx, appID, id); err != nil {
+		return err
+	}
+	if err := s.Repo.DeleteVolumeMount(ctx, id); err != nil {
+		return common.InternalError("删除存储卷挂载失败", err)
+	}
+	return nil
+}
+
+// getMount 获取属于应用的挂载
+func (s *VolumeService) getMount(ctx context.Context, appID, id types.Long) (*domain.AppVolumeMount, error) {
+	mount, err := s.Repo.GetVolumeMountByID(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询存储卷挂载失败", err)
+	}
+	if mount == nil || mount.AppID != appID {
+		return nil, common.NotFoundError("存储卷挂载不存在", nil)
+	}
+	return mount, nil
+}
+
+// checkVolume 校验存储卷参数、名称唯一以及PVC是否存在
+func (s *VolumeService) checkVolume(ctx context.Context, command *domain.SaveVolumeCommand) error {
+	if err := command.Validate(); err != nil {
+		return common.RequestParamError("", err)
+	}
+	exist, err := s.Repo.GetVolumeByName(ctx, command.Name)
+	if err != nil {
+		return common.InternalError("查询存储卷失败", err)
+	}
+	if exist != nil && exist.ID != command.ID {
+		return common.RequestParamError("", fmt.Errorf("存储卷 %s 已存在", command.Name))
+	}
+
+	if command.EnvID == 0 {
+		return nil
+	}
+	env, err := s.Repo.GetAppEnvByID(ctx, command.EnvID)
+	if err != nil {
+		return common.RequestParamError("", errors.New("环境不存在"))
+	}
+	if command.Type == domain.VolumeTypePVC {
+		return s.checkPVC(ctx, env, command.PvcName)
+	}
+	return nil
+}
+
+// checkMount 校验挂载路径、存储卷在环境中可用，且不与同环境的其他挂载或配置文件目录冲突
+func (s *VolumeService) checkMount(ctx context.Context, command *domain.SaveVolumeMountCommand) error {
+	if err := command.Validate(); err != nil {
+		return common.RequestParamError("", err)
+	}
+	if _, err := s.Repo.GetApplicationByID(ctx, command.AppID); err != nil {
+		return common.NotFoundError("应用不存在", err)
+	}
+	env, err := s.Repo.GetAppEnvByID(ctx, command.EnvID)
+	if err != nil {
+		return common.RequestParamError("", errors.New("环境不存在"))
+	}
+	volume, err := s.Repo.GetVolumeByID(ctx, command.VolumeID)
+	if err != nil {
+		return common.InternalError("查询存储卷失败", err)
+	}
+	if volume == nil {
+		return common.RequestParamError("", errors.New("存储卷不存在"))
+	}
+	if !volume.AvailableIn(command.EnvID) {
+		return common.RequestParamError("", fmt.Errorf("存储卷 %s 不能在环境 %s 中使用", volume.Name, env.Name))
+	}
+
+	mounts, err := s.Repo.ListVolumeMounts(ctx, command.AppID, command.EnvID)
+	if err != nil {
+		return common.InternalError("查询存储卷挂载失败", err)
+	}
+	for _, mount := range mounts {
+		if mount.ID != command.ID && mount.MountPath == command.MountPath {
+			return common.RequestParamError("", fmt.Errorf("挂载路径 %s 已被使用", command.MountPath))
+		}
+	}
+	config, err := s.Repo.GetLatestConfigRevision(ctx, command.AppID, command.EnvID)
+	if err != nil {
+		return common.InternalError("查询应用配置失败", err)
+	}
+	if config != nil && config.MountPath == command.MountPath {
+		return common.RequestParamError("", fmt.Errorf("挂载路径 %s 已用于挂载配置文件", command.MountPath))
+	}
+
+	if volume.Spec.Type == domain.VolumeTypePVC {
+		return s.checkPVC(ctx, env, volume.Spec.PvcName)
+	}
+	return nil
+}
+
+// checkPVC 检查环境命名空间中PVC是否存在，未接入集群时跳过检查
+func (s *VolumeService) checkPVC(ctx context.Context, env *domain.AppEnv, name string) error {
+	exists, err := s.Cluster.PVCExists(ctx, env, name)
+	if errors.Is(err, kube.ErrNotInCluster) {
+		logrus.WithField("pvc", name).WithField("namespace", env.Namespace).Warn("未接入集群，跳过PVC存在性检查")
+		return nil
+	}
+	if err != nil {
+		return common.InternalError("查询PVC失败", err)
+	}
+	if !exists {
+		return common.RequestParamError("", fmt.Errorf("PVC %s 在命名空间 %s 中不存在", name, env.Namespace))
+	}
+	return nil
+}
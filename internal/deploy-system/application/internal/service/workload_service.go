@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application/internal/domain"
+	"devops-platform/internal/deploy-system/application/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+)
+
+// WorkloadService 应用工作负载定义服务：定义按应用版本化，环境覆盖副本数、资源与调度配置
+type WorkloadService struct {
+	service.Service
+	Repo    *repository.AppRepository `inject:"ApplicationRepository"`
+	Volumes *VolumeService            `inject:"volumeService"`
+}
+
+// NewWorkloadService 创建应用工作负载定义服务实例
+func NewWorkloadService() *WorkloadService {
+	return &WorkloadService{}
+}
+
+// GetSpec 获取应用的最新工作负载定义
+func (s *WorkloadService) GetSpec(ctx context.Context, appID types.Long) (*domain.AppWorkloadSpec, error) {
+	spec, err := s.Repo.GetLatestWorkloadSpec(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询工作负载定义失败", err)
+	}
+	if spec == nil {
+		return nil, common.NotFoundError("应用没有工作负载定义", nil)
+	}
+	return spec, nil
+}
+
+// SaveSpec 保存工作负载定义并生成新版本，与当前版本相同时不生成新版本
+func (s *WorkloadService) SaveSpec(ctx context.Context, command *domain.SaveWorkloadSpecCommand) (spec *domain.AppWorkloadSpec, err error) {
+	if _, err = s.Repo.GetApplicationByID(ctx, command.AppID); err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	if err = command.WorkloadSpec.Validate(); err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+	if command.Mode == enum.DeployModeJob {
+		hpas, err := s.Repo.ListAppHPAs(ctx, command.AppID)
+		if err != nil {
+			return nil, common.InternalError("查询HPA配置失败", err)
+		}
+		if len(hpas) > 0 {
+			return nil, common.RequestParamError("", errors.New("应用已配置HPA，请先删除HPA配置再切换为Job部署方式"))
+		}
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "save workload spec")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "save workload spec")
+	}()
+
+	latest, err := s.Repo.GetLatestWorkloadSpec(ctx, command.AppID)
+	if err != nil {
+		return nil, common.InternalError("查询工作负载定义失败", err)
+	}
+	number := 1
+	if latest != nil {
+		number = latest.Revision + 1
+	}
+	spec = command.NewRevision(number)
+	if latest != nil && latest.Checksum == spec.Checksum {
+		return nil, common.RequestParamError("工作负载定义未变化", nil)
+	}
+
+	// 新定义与已有的环境覆盖合并后也必须有效
+	envs, err := s.Repo.ListAppEnvs(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询环境失败", err)
+	}
+	for _, env := range envs {
+		override, err := s.Repo.GetWorkloadOverride(ctx, command.AppID, env.ID)
+		if err != nil {
+			return nil, common.InternalError("查询环境覆盖失败", err)
+		}
+		if override == nil {
+			continue
+		}
+		merged := spec.Spec.Merge(&override.Override)
+		if err = merged.Validate(); err != nil {
+			return nil, common.RequestParamError("", fmt.Errorf("与环境 %s 的覆盖合并后无效: %w", env.Name, err))
+		}
+	}
+
+	spec.AuditCreated(ctx)
+	if _, err = s.Repo.CreateWorkloadSpec(ctx, spec); err != nil {
+		if current, _ := s.Repo.GetWorkloadSpec(ctx, spec.AppID, spec.Revision); current != nil {
+			return nil, common.RequestParamError("工作负载定义已被其他人修改，请刷新后重试", err)
+		}
+		return nil, common.InternalError("保存工作负载定义失败", err)
+	}
+	return spec, nil
+}
+
+// ListSpecRevisions 分页查询工作负载定义版本
+func (s *WorkloadService) ListSpecRevisions(ctx context.Context, query *domain.WorkloadSpecQuery) ([]*domain.AppWorkloadSpec, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = 10
+	}
+	specs, total, err := s.Repo.ListWorkloadSpecs(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询工作负载定义版本失败", err)
+	}
+	return specs, total, nil
+}
+
+// GetSpecRevision 获取指定的工作负载定义版本
+func (s *WorkloadService) GetSpecRevision(ctx context.Context, appID types.Long, number int) (*domain.AppWorkloadSpec, error) {
+	spec, err := s.Repo.GetWorkloadSpec(ctx, appID, number)
+	if err != nil {
+		return nil, common.InternalError("查询工作负载定义失败", err)
+	}
+	if spec == nil {
+		return nil, common.NotFoundError(fmt.Sprintf("工作负载定义版本 %d 不存在", number), nil)
+	}
+	return spec, nil
+}
+
+// GetOverride 获取应用在环境下的工作负载覆盖
+func (s *WorkloadService) GetOverride(ctx context.Context, appID, envID types.Long) (*domain.AppWorkloadOverride, error) {
+	override, err := s.Repo.GetWorkloadOverride(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询环境覆盖失败", err)
+	}
+	if override == nil {
+		return nil, common.NotFoundError("应用在该环境下没有覆盖", nil)
+	}
+	return override, nil
+}
+
+// SaveOverride 保存应用在环境下的工作负载覆盖，已有定义时校验合并后的结果
+func (s *WorkloadService) SaveOverride(ctx context.Context, command *domain.SaveWorkloadOverrideCommand) (*domain.AppWorkloadOverride, error) {
+	if _, err := s.Repo.GetApplicationByID(ctx, command.AppID); err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	if _, err := s.Repo.GetAppEnvByID(ctx, command.EnvID); err != nil {
+		return nil, common.RequestParamError("", errors.New("环境不存在"))
+	}
+	if err := command.WorkloadOverride.Validate(); err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+	latest, err := s.Repo.GetLatestWorkloadSpec(ctx, command.AppID)
+	if err != nil {
+		return nil, common.InternalError("查询工作负载定义失败", err)
+	}
+	if latest != nil {
+		merged := latest.Spec.Merge(&command.WorkloadOverride)
+		if err = merged.Validate(); err != nil {
+			return nil, common.RequestParamError("", fmt.Errorf("与工作负载定义合并后无效: %w", err))
+		}
+	}
+
+	override, err := s.Repo.GetWorkloadOverride(ctx, command.AppID, command.EnvID)
+	if err != nil {
+		return nil, common.InternalError("查询环境覆盖失败", err)
+	}
+	if override == nil {
+		override = &domain.AppWorkloadOverride{AppID: command.AppID, EnvID: command.EnvID}
+		override.AuditCreated(ctx)
+	} else {
+		override.AuditModified(ctx)
+	}
+	override.Override = command.WorkloadOverride
+	if err = s.Repo.SaveWorkloadOverride(ctx, override); err != nil {
+		return nil, common.InternalError("保存环境覆盖失败", err)
+	}
+	return override, nil
+}
+
+// DeleteOverride 删除应用在环境下的工作负载覆盖，环境恢复使用应用定义
+func (s *WorkloadService) DeleteOverride(ctx context.Context, appID, envID types.Long) error {
+	override, err := s.GetOverride(ctx, appID, envID)
+	if err != nil {
+		return err
+	}
+	if err = s.Repo.DeleteWorkloadOverride(ctx, override.ID); err != nil {
+		return common.InternalError("删除环境覆盖失败", err)
+	}
+	return nil
+}
+
+// EffectiveSpec 获取应用在环境下实际生效的工作负载定义
+func (s *WorkloadService) EffectiveSpec(ctx context.Context, appID, envID types.Long) (*domain.EffectiveSpecVO, error) {
+	spec, err := s.GetSpec(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	override, err := s.Repo.GetWorkloadOverride(ctx, appID, envID)
+	if err != nil {
+		return nil, common.InternalError("查询环境覆盖失败", err)
+	}
+
+	effective := &domain.EffectiveSpecVO{AppID: appID, EnvID: envID, Revision: spec.Revision, Spec: spec.Spec}
+	if override != nil {
+		effective.Override = &override.Override
+		effective.Spec = spec.Spec.Merge(effective.Override)
+	}
+	return effective, nil
+}
+
+// RenderWorkload 将应用在环境下的工作负载定义、最新配置与共享存储卷渲染为YAML，
+// 按部署方式输出Deployment、一次性Job或CronJob
+func (s *WorkloadService) RenderWorkload(ctx context.Context, appID, envID types.Long, version string) ([]byte, error) {
+	if version == "" {
+		version = domain.DefaultImageTag
+	}
+	manifest, _, err := s.manifest(ctx, appID, envID, version, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := kube.MarshalYAML(workloadObject(manifest))
+	if err != nil {
+		return nil, common.InternalError("生成工作负载失败", err)
+	}
+	return data, nil
+}
+
+// workloadObject 按部署方式生成Deployment、一次性Job或CronJob
+func workloadObject(manifest *domain.WorkloadManifest) interface{} {
+	switch {
+	case manifest.Spec.Mode != enum.DeployModeJob:
+		return domain.NewDeployment(manifest)
+	case manifest.Spec.Job.Scheduled():
+		return domain.NewCronJob(manifest)
+	default:
+		return domain.NewJob(manifest)
+	}
+}
+
+// manifest 汇总渲染工作负载所需的内容，revision为0时使用最新的工作负载定义
+func (s *WorkloadService) manifest(ctx context.Context, appID, envID types.Long, version string, revision int) (*domain.WorkloadManifest, *domain.AppEnv, error) {
+	if !domain.IsImageTag(version) {
+		return nil, nil, common.RequestParamError("", fmt.Errorf("版本 %s 不能作为镜像标签", version))
+	}
+	app, err := s.Repo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		return nil, nil, common.NotFoundError("应用不存在", err)
+	}
+	env, err := s.Repo.GetAppEnvByID(ctx, envID)
+	if err != nil {
+		return nil, nil, common.NotFoundError("环境不存在", err)
+	}
+	effective, err := s.EffectiveSpec(ctx, appID, envID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if revision > 0 && revision != effective.Revision {
+		spec, err := s.GetSpecRevision(ctx, appID, revision)
+		if err != nil {
+			return nil, nil, err
+		}
+		effective.Revision = spec.Revision
+		effective.Spec = spec.Spec.Merge(effective.Override)
+	}
+	config, err := s.Repo.GetLatestConfigRevision(ctx, appID, envID)
+	if err != nil {
+		return nil, nil, common.InternalError("查询应用配置失败", err)
+	}
+	volumes, err := s.Volumes.PodVolumes(ctx, appID, envID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &domain.WorkloadManifest{
+		AppName:      app.Name,
+		Namespace:    env.Namespace,
+		Version:      version,
+		SpecRevision: effective.Revision,
+		Spec:         effective.Spec,
+		Config:       config,
+		Mounts:       volumes.Mounts,
+	}, env, nil
+}
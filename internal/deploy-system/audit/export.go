@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"devops-platform/internal/deploy-system/audit/internal/domain"
+	"devops-platform/internal/deploy-system/audit/internal/service"
+)
+
+// Bean常量
+const (
+	BeanAuditService = domain.BeanAuditService
+)
+
+// 审计操作
+const (
+	ActionCreate = domain.ActionCreate
+	ActionUpdate = domain.ActionUpdate
+	ActionDelete = domain.ActionDelete
+)
+
+// 领域对象类型别名
+type (
+	AuditLogVO    = domain.AuditLogVO
+	AuditLogQuery = domain.AuditLogQuery
+)
+
+// RegisterResource 登记需要审计的数据表及其资源类型，新增、修改、删除该表数据时自动记录审计日志
+func RegisterResource(table, resourceType string) {
+	service.RegisterResource(table, resourceType)
+}
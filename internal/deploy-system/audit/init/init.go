@@ -0,0 +1,28 @@
+package init
+
+import (
+	"devops-platform/internal/deploy-system/audit/internal/controller"
+	"devops-platform/internal/deploy-system/audit/internal/domain"
+	"devops-platform/internal/deploy-system/audit/internal/repository"
+	"devops-platform/internal/deploy-system/audit/internal/service"
+	"devops-platform/pkg/beans"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 使用标准的init函数进行初始化
+func init() {
+	// 注册仓储
+	beans.Register(domain.BeanAuditRepository, repository.NewRepository())
+
+	// 注册审计插件，向数据库连接注册GORM回调
+	beans.Register(domain.BeanAuditPlugin, service.NewAuditPlugin())
+
+	// 注册服务
+	beans.Register(domain.BeanAuditService, service.NewAuditService())
+
+	// 注册控制器
+	beans.Register(domain.BeanAuditController, controller.NewAuditController())
+
+	logrus.Info("审计日志模块初始化完成")
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"bytes"
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/audit/internal/domain"
+	"devops-platform/internal/deploy-system/audit/internal/service"
+	"devops-platform/internal/pkg/common"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditController 审计日志控制器
+type AuditController struct {
+	web.Controller
+	Service *service.AuditService `inject:"AuditService"`
+}
+
+// NewAuditController 创建审计日志控制器实例
+func NewAuditController() *AuditController {
+	return &AuditController{}
+}
+
+// ListAuditLogs 查询审计日志
+// @Summary 查询审计日志
+// @Description 分页查询应用、环境、角色、权限、部门及部署等资源的变更记录
+// @Tags 审计日志
+// @Produce json
+// @Param actor_id query int false "操作人ID"
+// @Param actor query string false "操作人（模糊匹配）"
+// @Param action query string false "操作: create, update, delete"
+// @Param resource_type query string false "资源类型: app, env, role, permission, department, deployment, release_plan"
+// @Param resource_id query int false "资源ID"
+// @Param request_id query string false "请求ID"
+// @Param start_time query string false "开始时间，格式 2006-01-02 15:04:05"
+// @Param end_time query string false "结束时间，格式 2006-01-02 15:04:05"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=common.PageResult{list=[]domain.AuditLogVO}}
+// @Router /api/v1/audit-logs [get]
+func (c *AuditController) ListAuditLogs(ctx *gin.Context) {
+	var query domain.AuditLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	logs, total, err := c.Service.ListAuditLogs(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccessWithPageExt(ctx, logs, total, query.Page, query.Size)
+}
+
+// ExportAuditLogs 导出审计日志
+// @Summary 导出审计日志
+// @Description 按查询条件导出审计日志为CSV文件，单次最多导出10000条
+// @Tags 审计日志
+// @Produce text/csv
+// @Param actor_id query int false "操作人ID"
+// @Param actor query string false "操作人（模糊匹配）"
+// @Param action query string false "操作: create, update, delete"
+// @Param resource_type query string false "资源类型"
+// @Param resource_id query int false "资源ID"
+// @Param request_id query string false "请求ID"
+// @Param start_time query string false "开始时间，格式 2006-01-02 15:04:05"
+// @Param end_time query string false "结束时间，格式 2006-01-02 15:04:05"
+// @Success 200 {file} file
+// @Router /api/v1/audit-logs/export [get]
+func (c *AuditController) ExportAuditLogs(ctx *gin.Context) {
+	var query domain.AuditLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	var buffer bytes.Buffer
+	if err := c.Service.ExportAuditLogs(ctx, &query, &buffer); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.csv", time.Now().Format("20060102150405"))
+	ctx.Header("Content-Disposition", "attachment; filename="+filename)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buffer.Bytes())
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Inject 实现依赖注入
+func (c *AuditController) Inject(getBean func(string) interface{}) {
+	c.injectRouting(getBean)
+}
+
+// injectRouting 注入路由
+func (c *AuditController) injectRouting(getBean func(string) interface{}) {
+	router, ok := getBean(web.BeanGinEngine).(gin.IRouter)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", web.BeanGinEngine)
+		return
+	}
+
+	// 审计日志路由组
+	auditGroup := router.Group("/api/v1/audit-logs")
+	auditGroup.Use(middleware.JWTAuth())
+	{
+		// 查询审计日志
+		auditGroup.GET("", c.ListAuditLogs)
+		// 导出审计日志
+		auditGroup.GET("/export", c.ExportAuditLogs)
+	}
+}
@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AuditLog 审计日志
+type AuditLog struct {
+	ID           types.Long `json:"id" gorm:"primaryKey;autoIncrement"`
+	ActorID      types.Long `json:"actor_id" gorm:"index;comment:'操作人ID'"`
+	ActorName    string     `json:"actor_name" gorm:"size:128;comment:'操作人'"`
+	IP           string     `json:"ip" gorm:"size:64;comment:'客户端IP'"`
+	UserAgent    string     `json:"user_agent" gorm:"size:255;comment:'用户代理'"`
+	Action       string     `json:"action" gorm:"size:16;index;comment:'操作 create/update/delete'"`
+	ResourceType string     `json:"resource_type" gorm:"size:64;index:idx_resource;comment:'资源类型'"`
+	ResourceID   types.Long `json:"resource_id" gorm:"index:idx_resource;comment:'资源ID'"`
+	ResourceName string     `json:"resource_name" gorm:"size:128;comment:'资源名称'"`
+	Before       string     `json:"before" gorm:"type:text;comment:'变更前的字段（JSON）'"`
+	After        string     `json:"after" gorm:"type:text;comment:'变更后的字段（JSON）'"`
+	RequestID    string     `json:"request_id" gorm:"size:64;index;comment:'请求ID'"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"index;comment:'操作时间'"`
+}
+
+// ToVO 转换为视图对象
+func (l *AuditLog) ToVO() *AuditLogVO {
+	vo := &AuditLogVO{
+		ID:           l.ID,
+		ActorID:      l.ActorID,
+		ActorName:    l.ActorName,
+		IP:           l.IP,
+		UserAgent:    l.UserAgent,
+		Action:       l.Action,
+		ResourceType: l.ResourceType,
+		ResourceID:   l.ResourceID,
+		ResourceName: l.ResourceName,
+		RequestID:    l.RequestID,
+		CreatedAt:    l.CreatedAt,
+	}
+	if l.Before != "" {
+		vo.Before = json.RawMessage(l.Before)
+	}
+	if l.After != "" {
+		vo.After = json.RawMessage(l.After)
+	}
+	vo.ChangedFields = changedFields(l.Before, l.After)
+	return vo
+}
+
+// CSVHeader 导出CSV的表头
+func CSVHeader() []string {
+	return []string{"ID", "操作时间", "操作人ID", "操作人", "IP", "User-Agent", "操作", "资源类型", "资源ID", "资源名称", "变更字段", "变更前", "变更后", "请求ID"}
+}
+
+// CSVRecord 转换为CSV行
+func (l *AuditLog) CSVRecord() []string {
+	return []string{
+		l.ID.String(),
+		l.CreatedAt.Format(types.TimeFormat),
+		l.ActorID.String(),
+		l.ActorName,
+		l.IP,
+		l.UserAgent,
+		l.Action,
+		l.ResourceType,
+		l.ResourceID.String(),
+		l.ResourceName,
+		strings.Join(changedFields(l.Before, l.After), ","),
+		l.Before,
+		l.After,
+		l.RequestID,
+	}
+}
+
+// AuditLogVO 审计日志视图对象
+type AuditLogVO struct {
+	ID            types.Long      `json:"id"`
+	ActorID       types.Long      `json:"actor_id"`
+	ActorName     string          `json:"actor_name"`
+	IP            string          `json:"ip"`
+	UserAgent     string          `json:"user_agent"`
+	Action        string          `json:"action"`
+	ResourceType  string          `json:"resource_type"`
+	ResourceID    types.Long      `json:"resource_id"`
+	ResourceName  string          `json:"resource_name"`
+	ChangedFields []string        `json:"changed_fields"`
+	Before        json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After         json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	RequestID     string          `json:"request_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AuditLogQuery 审计日志查询条件
+type AuditLogQuery struct {
+	ActorID      types.Long `form:"actor_id"`
+	Actor        string     `form:"actor"`
+	Action       string     `form:"action"`
+	ResourceType string     `form:"resource_type"`
+	ResourceID   types.Long `form:"resource_id"`
+	RequestID    string     `form:"request_id"`
+	StartTime    string     `form:"start_time"`
+	EndTime      string     `form:"end_time"`
+	Page         int        `form:"page"`
+	Size         int        `form:"size"`
+
+	Start time.Time `form:"-"`
+	End   time.Time `form:"-"`
+}
+
+// Validate 校验并解析查询条件，时间格式为 2006-01-02 15:04:05
+func (q *AuditLogQuery) Validate() error {
+	q.Action = strings.TrimSpace(q.Action)
+	if q.Action != "" && q.Action != ActionCreate && q.Action != ActionUpdate && q.Action != ActionDelete {
+		return common.RequestParamError("", fmt.Errorf("不支持的操作类型: %s", q.Action))
+	}
+
+	var err error
+	if q.StartTime != "" {
+		q.Start, err = time.ParseInLocation(types.TimeFormat, q.StartTime, time.Local)
+		if err != nil {
+			return common.RequestParamError("", errors.New("开始时间格式错误，应为 "+types.TimeFormat))
+		}
+	}
+	if q.EndTime != "" {
+		q.End, err = time.ParseInLocation(types.TimeFormat, q.EndTime, time.Local)
+		if err != nil {
+			return common.RequestParamError("", errors.New("结束时间格式错误，应为 "+types.TimeFormat))
+		}
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && q.End.Before(q.Start) {
+		return common.RequestParamError("", errors.New("结束时间不能早于开始时间"))
+	}
+
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.Size <= 0 {
+		q.Size = 10
+	}
+	return nil
+}
+
+// Snapshot 数据行快照，key为列名
+type Snapshot map[string]interface{}
+
+// Diff 比较变更前后的快照，返回发生变化的字段（忽略修改时间、修改人等审计字段）
+func Diff(before, after Snapshot) (Snapshot, Snapshot) {
+	changedBefore := make(Snapshot)
+	changedAfter := make(Snapshot)
+	for column, value := range after {
+		if ignoredColumns[column] {
+			continue
+		}
+		old, ok := before[column]
+		if ok && sameValue(old, value) {
+			continue
+		}
+		changedBefore[column] = old
+		changedAfter[column] = value
+	}
+	for column, old := range before {
+		if _, ok := after[column]; !ok && !ignoredColumns[column] {
+			changedBefore[column] = old
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+// Mask 敏感字段脱敏
+func (s Snapshot) Mask() Snapshot {
+	for column, value := range s {
+		if value == nil || value == "" {
+			continue
+		}
+		name := strings.ToLower(column)
+		for _, keyword := range sensitiveKeywords {
+			if strings.Contains(name, keyword) {
+				s[column] = MaskedValue
+				break
+			}
+		}
+	}
+	return s
+}
+
+// JSON 序列化快照，空快照返回空字符串
+func (s Snapshot) JSON() string {
+	if len(s) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Name 资源名称，取name列
+func (s Snapshot) Name() string {
+	if name, ok := s["name"].(string); ok {
+		return name
+	}
+	return ""
+}
+
+// sameValue 按JSON序列化结果比较字段值，避免驱动返回类型不同造成误判
+func sameValue(a, b interface{}) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
+
+// changedFields 解析变更前后的JSON，返回涉及的字段名
+func changedFields(before, after string) []string {
+	fields := make(map[string]bool)
+	for _, data := range []string{before, after} {
+		if data == "" {
+			continue
+		}
+		var snapshot map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+			continue
+		}
+		for field := range snapshot {
+			fields[field] = true
+		}
+	}
+
+	result := make([]string, 0, len(fields))
+	for field := range fields {
+		result = append(result, field)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,54 @@
+package domain
+
+const (
+	// 模块Bean名称常量
+	BeanAuditRepository = "AuditRepository"
+	BeanAuditService    = "AuditService"
+	BeanAuditPlugin     = "AuditPlugin"
+	BeanAuditController = "AuditController"
+
+	// 审计操作
+	ActionCreate = "create" // 新增
+	ActionUpdate = "update" // 修改
+	ActionDelete = "delete" // 删除
+
+	// 审计资源类型
+	ResourceApp         = "app"          // 应用
+	ResourceEnv         = "env"          // 应用环境
+	ResourceRole        = "role"         // 角色
+	ResourcePermission  = "permission"   // 权限
+	ResourceDepartment  = "department"   // 部门
+	ResourceDeployment  = "deployment"   // 部署记录
+	ResourceReleasePlan = "release_plan" // 发布计划
+
+	// MaskedValue 敏感字段脱敏后的值
+	MaskedValue = "******"
+
+	// MaxAuditRows 单条语句最多审计的记录数，超出部分不再记录
+	MaxAuditRows = 200
+
+	// MaxExportRows 单次导出的最大记录数
+	MaxExportRows = 10000
+)
+
+// DefaultResources 默认审计的数据表及其资源类型
+var DefaultResources = map[string]string{
+	"app":              ResourceApp,
+	"app_env":          ResourceEnv,
+	"role":             ResourceRole,
+	"permission":       ResourcePermission,
+	"department":       ResourceDepartment,
+	"deploy_history":   ResourceDeployment,
+	"app_release_plan": ResourceReleasePlan,
+}
+
+// ignoredColumns 比较变更时忽略的审计字段
+var ignoredColumns = map[string]bool{
+	"updated_at":            true,
+	"last_modified_at":      true,
+	"last_modified_by_id":   true,
+	"last_modified_by_name": true,
+}
+
+// sensitiveKeywords 列名包含这些关键字时脱敏
+var sensitiveKeywords = []string{"password", "secret", "token"}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"devops-platform/internal/common/repository"
+	"devops-platform/internal/deploy-system/audit/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Repository 审计日志仓储
+type Repository struct {
+	repository.Repository
+}
+
+// NewRepository 创建仓储实例
+func NewRepository() *Repository {
+	return &Repository{}
+}
+
+// ListAuditLogs 分页查询审计日志
+func (r *Repository) ListAuditLogs(ctx context.Context, query *domain.AuditLogQuery) ([]*domain.AuditLog, int64, error) {
+	db := r.filter(ctx, query)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*domain.AuditLog
+	err := db.Order("id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// FindAuditLogs 按条件查询审计日志，最多返回limit条
+func (r *Repository) FindAuditLogs(ctx context.Context, query *domain.AuditLogQuery, limit int) ([]*domain.AuditLog, error) {
+	var logs []*domain.AuditLog
+	err := r.filter(ctx, query).Order("id DESC").Limit(limit).Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// filter 构建查询条件
+func (r *Repository) filter(ctx context.Context, query *domain.AuditLogQuery) *gorm.DB {
+	db := r.DB(ctx).Model(&domain.AuditLog{})
+	if query.ActorID > 0 {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.Actor != "" {
+		db = db.Where("actor_name LIKE ?", "%"+query.Actor+"%")
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.ResourceType != "" {
+		db = db.Where("resource_type = ?", query.ResourceType)
+	}
+	if query.ResourceID > 0 {
+		db = db.Where("resource_id = ?", query.ResourceID)
+	}
+	if query.RequestID != "" {
+		db = db.Where("request_id = ?", query.RequestID)
+	}
+	if !query.Start.IsZero() {
+		db = db.Where("created_at >= ?", query.Start)
+	}
+	if !query.End.IsZero() {
+		db = db.Where("created_at <= ?", query.End)
+	}
+	return db
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"devops-platform/internal/common/database"
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/audit/internal/domain"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	auditPluginName = "audit"
+	// auditBeforeKey 语句执行前的数据快照在Statement中的Key
+	auditBeforeKey = "audit:before"
+)
+
+var (
+	resourcesMutex sync.RWMutex
+	resources      = make(map[string]string)
+)
+
+func init() {
+	for table, resourceType := range domain.DefaultResources {
+		RegisterResource(table, resourceType)
+	}
+}
+
+// RegisterResource 登记需要审计的数据表及其资源类型
+func RegisterResource(table, resourceType string) {
+	resourcesMutex.Lock()
+	defer resourcesMutex.Unlock()
+	resources[table] = resourceType
+}
+
+// resourceType 获取数据表对应的资源类型，未登记的表不审计
+func resourceType(table string) (string, bool) {
+	resourcesMutex.RLock()
+	defer resourcesMutex.RUnlock()
+	resourceType, ok := resources[table]
+	return resourceType, ok
+}
+
+// AuditPlugin GORM审计插件，在新增、修改、删除已登记的数据表时记录审计日志
+// 审计日志与业务数据写入同一事务，操作人、IP、请求ID取自语句上下文
+type AuditPlugin struct{}
+
+func NewAuditPlugin() *AuditPlugin {
+	return &AuditPlugin{}
+}
+
+// Inject 向全局数据库连接注册插件
+func (p *AuditPlugin) Inject(getBean func(string) interface{}) {
+	db, ok := getBean(database.BeanDB).(*gorm.DB)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", database.BeanDB)
+		return
+	}
+	if err := db.Use(p); err != nil {
+		logrus.WithError(err).Panic("注册审计插件失败")
+	}
+}
+
+// Name 插件名称
+func (p *AuditPlugin) Name() string {
+	return auditPluginName
+}
+
+// Initialize 注册GORM回调
+func (p *AuditPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Register("audit:after_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("audit:before_update", p.before); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("audit:after_update", p.afterUpdate); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("audit:before_delete", p.before); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("audit:after_delete", p.afterDelete)
+}
+
+// before 修改、删除前记录受影响数据的快照
+func (p *AuditPlugin) before(db *gorm.DB) {
+	if db.Error != nil || db.Statement.DryRun {
+		return
+	}
+	if _, ok := resourceType(db.Statement.Table); !ok {
+		return
+	}
+
+	ids := p.modelIDs(db)
+	if len(ids) == 0 {
+		// 按语句的查询条件定位受影响的记录，条件中可能引用主键，需带上模型
+		where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where)
+		if !ok || len(where.Exprs) == 0 {
+			return
+		}
+		query := p.session(db).Table(db.Statement.Table)
+		if db.Statement.Schema != nil {
+			query = query.Model(reflect.New(db.Statement.Schema.ModelType).Interface())
+		}
+		if err := query.Clauses(where).Limit(domain.MaxAuditRows).Pluck(p.primaryKey(db), &ids).Error; err != nil {
+			logrus.WithError(err).WithField("table", db.Statement.Table).Warn("审计时查询变更前数据失败")
+			return
+		}
+		if len(ids) == 0 {
+			return
+		}
+	}
+
+	rows, err := p.load(db, ids)
+	if err != nil {
+		logrus.WithError(err).WithField("table", db.Statement.Table).Warn("审计时查询变更前数据失败")
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows)
+}
+
+// afterCreate 新增后记录完整数据
+func (p *AuditPlugin) afterCreate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.DryRun || db.RowsAffected == 0 {
+		return
+	}
+	if _, ok := resourceType(db.Statement.Table); !ok {
+		return
+	}
+
+	ids := p.modelIDs(db)
+	if len(ids) == 0 {
+		return
+	}
+	rows, err := p.load(db, ids)
+	if err != nil {
+		logrus.WithError(err).WithField("table", db.Statement.Table).Warn("审计时查询新增数据失败")
+		return
+	}
+
+	logs := make([]*domain.AuditLog, 0, len(rows))
+	for _, row := range rows {
+		after := domain.Snapshot(row).Mask()
+		logs = append(logs, p.newLog(db, domain.ActionCreate, after, nil, after))
+	}
+	p.save(db, logs)
+}
+
+// afterUpdate 修改后与修改前的快照比较，仅记录发生变化的字段
+func (p *AuditPlugin) afterUpdate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.DryRun || db.RowsAffected == 0 {
+		return
+	}
+	befores := p.befores(db)
+	if len(befores) == 0 {
+		return
+	}
+
+	pk := p.primaryKey(db)
+	ids := make([]interface{}, 0, len(befores))
+	for _, row := range befores {
+		ids = append(ids, row[pk])
+	}
+	rows, err := p.load(db, ids)
+	if err != nil {
+		logrus.WithError(err).WithField("table", db.Statement.Table).Warn("审计时查询变更后数据失败")
+		return
+	}
+	afters := make(map[string]domain.Snapshot, len(rows))
+	for _, row := range rows {
+		afters[fmt.Sprint(row[pk])] = row
+	}
+
+	logs := make([]*domain.AuditLog, 0, len(befores))
+	for _, before := range befores {
+		after, ok := afters[fmt.Sprint(before[pk])]
+		if !ok {
+			continue
+		}
+		changedBefore, changedAfter := domain.Diff(before, after)
+		if len(changedAfter) == 0 && len(changedBefore) == 0 {
+			continue
+		}
+		logs = append(logs, p.newLog(db, domain.ActionUpdate, after, changedBefore.Mask(), changedAfter.Mask()))
+	}
+	p.save(db, logs)
+}
+
+// afterDelete 删除后记录被删除的数据
+func (p *AuditPlugin) afterDelete(db *gorm.DB) {
+	if db.Error != nil || db.Statement.DryRun || db.RowsAffected == 0 {
+		return
+	}
+
+	befores := p.befores(db)
+	logs := make([]*domain.AuditLog, 0, len(befores))
+	for _, before := range befores {
+		before = before.Mask()
+		logs = append(logs, p.newLog(db, domain.ActionDelete, before, before, nil))
+	}
+	p.save(db, logs)
+}
+
+// newLog 构建审计日志，操作人信息取自语句上下文
+func (p *AuditPlugin) newLog(db *gorm.DB, action string, row, before, after domain.Snapshot) *domain.AuditLog {
+	resourceType, _ := resourceType(db.Statement.Table)
+	log := &domain.AuditLog{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceName: row.Name(),
+		Before:       before.JSON(),
+		After:        after.JSON(),
+		CreatedAt:    time.Now(),
+	}
+	if id, err := types.StringToLong(fmt.Sprint(row[p.primaryKey(db)])); err == nil {
+		log.ResourceID = id
+	}
+
+	ctx := db.Statement.Context
+	if user := security.GetUserContext(ctx); user != nil {
+		log.ActorID = user.UserID
+		log.ActorName = user.Username
+		log.IP = user.IP
+		log.UserAgent = truncate(user.UserAgent, 255)
+	}
+	log.RequestID = web.RequestID(ctx)
+	return log
+}
+
+// save 在语句所在的连接（事务）中写入审计日志，写入失败不影响业务操作
+func (p *AuditPlugin) save(db *gorm.DB, logs []*domain.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+	if err := p.session(db).Create(&logs).Error; err != nil {
+		logrus.WithError(err).WithField("table", db.Statement.Table).Error("写入审计日志失败")
+	}
+}
+
+// load 按主键查询数据行
+func (p *AuditPlugin) load(db *gorm.DB, ids []interface{}) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	err := p.session(db).Table(db.Statement.Table).
+		Where(clause.IN{Column: clause.Column{Name: p.primaryKey(db)}, Values: ids}).
+		Find(&rows).Error
+	return rows, err
+}
+
+// befores 获取语句执行前的快照
+func (p *AuditPlugin) befores(db *gorm.DB) []domain.Snapshot {
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return nil
+	}
+	rows, _ := value.([]map[string]interface{})
+	snapshots := make([]domain.Snapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshots = append(snapshots, row)
+	}
+	return snapshots
+}
+
+// session 复用语句的连接与上下文，跳过钩子避免重复审计
+func (p *AuditPlugin) session(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+}
+
+// primaryKey 主键列名，默认为id
+func (p *AuditPlugin) primaryKey(db *gorm.DB) string {
+	if db.Statement.Schema != nil && db.Statement.Schema.PrioritizedPrimaryField != nil {
+		return db.Statement.Schema.PrioritizedPrimaryField.DBName
+	}
+	return "id"
+}
+
+// modelIDs 从语句的模型中取非零主键值
+func (p *AuditPlugin) modelIDs(db *gorm.DB) []interface{} {
+	if db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+
+	ids := make([]interface{}, 0)
+	collect := func(value reflect.Value) {
+		if id, zero := field.ValueOf(db.Statement.Context, value); !zero {
+			ids = append(ids, id)
+		}
+	}
+
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len() && len(ids) < domain.MaxAuditRows; i++ {
+			item := reflect.Indirect(value.Index(i))
+			if item.Kind() == reflect.Struct {
+				collect(item)
+			}
+		}
+	case reflect.Struct:
+		collect(value)
+	}
+	return ids
+}
+
+// truncate 按字符截断字符串
+func truncate(value string, size int) string {
+	runes := []rune(value)
+	if len(runes) <= size {
+		return value
+	}
+	return string(runes[:size])
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/audit/internal/domain"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"encoding/json"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const auditTestResource = "record"
+
+// auditRecord 审计测试使用的数据表，token列需脱敏
+type auditRecord struct {
+	ID     types.Long `gorm:"primaryKey;autoIncrement"`
+	Name   string
+	Token  string
+	Status int
+}
+
+func (auditRecord) TableName() string {
+	return "audit_record"
+}
+
+// newAuditTest 使用内存SQLite创建注册了审计插件的数据库连接
+func newAuditTest(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err = db.AutoMigrate(&auditRecord{}, &domain.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+	RegisterResource(auditRecord{}.TableName(), auditTestResource)
+	if err = db.Use(NewAuditPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// auditTestContext 携带操作人信息的上下文
+func auditTestContext() context.Context {
+	return security.SetUserContext(context.Background(), &security.UserContext{UserID: 7, Username: "alice", IP: "10.0.0.1"})
+}
+
+// auditLogs 按写入顺序查询审计日志
+func auditLogs(t *testing.T, db *gorm.DB) []*domain.AuditLog {
+	var logs []*domain.AuditLog
+	if err := db.Order("id").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	return logs
+}
+
+// decode 解析审计日志中的快照
+func decode(t *testing.T, value string) map[string]interface{} {
+	snapshot := make(map[string]interface{})
+	if value == "" {
+		return snapshot
+	}
+	if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+func TestAuditPluginCreate(t *testing.T) {
+	db := newAuditTest(t)
+	record := &auditRecord{Name: "order", Token: "s3cr3t", Status: 1}
+	if err := db.WithContext(auditTestContext()).Create(record).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := auditLogs(t, db)
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 audit log, got %d", len(logs))
+	}
+	log := logs[0]
+	if log.Action != domain.ActionCreate || log.ResourceType != auditTestResource || log.ResourceID != record.ID || log.ResourceName != "order" {
+		t.Errorf("unexpected audit log: %+v", log)
+	}
+	if log.ActorID != 7 || log.ActorName != "alice" || log.IP != "10.0.0.1" {
+		t.Errorf("expected actor alice from context, got %d %s %s", log.ActorID, log.ActorName, log.IP)
+	}
+	after := decode(t, log.After)
+	if after["token"] != domain.MaskedValue || after["name"] != "order" {
+		t.Errorf("expected masked token and name in after snapshot, got %v", after)
+	}
+	if log.Before != "" {
+		t.Errorf("expected empty before snapshot, got %s", log.Before)
+	}
+}
+
+func TestAuditPluginUpdateRecordsChangedColumns(t *testing.T) {
+	db := newAuditTest(t)
+	record := &auditRecord{Name: "order", Token: "s3cr3t", Status: 1}
+	if err := db.Create(record).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := auditTestContext()
+	if err := db.WithContext(ctx).Model(record).Update("status", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 不带主键的条件更新按查询条件定位受影响的记录
+	if err := db.WithContext(ctx).Model(&auditRecord{}).Where("name = ?", "order").Update("name", "pay").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := auditLogs(t, db)
+	if len(logs) != 3 {
+		t.Fatalf("expected 3 audit logs, got %d", len(logs))
+	}
+	for i, want := range []struct {
+		column        string
+		before, after interface{}
+	}{
+		{"status", float64(1), float64(2)},
+		{"name", "order", "pay"},
+	} {
+		log := logs[i+1]
+		before, after := decode(t, log.Before), decode(t, log.After)
+		if log.Action != domain.ActionUpdate || log.ResourceID != record.ID || log.ActorID != 7 {
+			t.Errorf("unexpected audit log: %+v", log)
+		}
+		if len(after) != 1 || before[want.column] != want.before || after[want.column] != want.after {
+			t.Errorf("expected only %s changed from %v to %v, got %v -> %v", want.column, want.before, want.after, before, after)
+		}
+	}
+}
+
+func TestAuditPluginDelete(t *testing.T) {
+	db := newAuditTest(t)
+	record := &auditRecord{Name: "order", Token: "s3cr3t", Status: 1}
+	if err := db.Create(record).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.WithContext(auditTestContext()).Delete(record).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := auditLogs(t, db)
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 audit logs, got %d", len(logs))
+	}
+	log := logs[1]
+	before := decode(t, log.Before)
+	if log.Action != domain.ActionDelete || log.ResourceID != record.ID || log.ResourceName != "order" || log.ActorID != 7 {
+		t.Errorf("unexpected audit log: %+v", log)
+	}
+	if before["token"] != domain.MaskedValue || log.After != "" {
+		t.Errorf("expected masked before snapshot and empty after, got %s -> %s", log.Before, log.After)
+	}
+}
+
+func TestAuditPluginWithoutUser(t *testing.T) {
+	db := newAuditTest(t)
+	ctx := context.Background()
+	record := &auditRecord{Name: "order", Status: 1}
+	if err := db.WithContext(ctx).Create(record).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(ctx).Model(record).Update("status", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(ctx).Delete(record).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := auditLogs(t, db)
+	if len(logs) != 3 {
+		t.Fatalf("expected create, update and delete to be audited without a user, got %d logs", len(logs))
+	}
+	for i, action := range []string{domain.ActionCreate, domain.ActionUpdate, domain.ActionDelete} {
+		if logs[i].Action != action || logs[i].ActorID != 0 || logs[i].ActorName != "" {
+			t.Errorf("expected anonymous %s log, got %s by %d %s", action, logs[i].Action, logs[i].ActorID, logs[i].ActorName)
+		}
+	}
+}
+
+func TestAuditPluginIgnoresUnregisteredTables(t *testing.T) {
+	db := newAuditTest(t)
+	if err := db.Create(&domain.AuditLog{Action: domain.ActionCreate}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if logs := auditLogs(t, db); len(logs) != 1 {
+		t.Errorf("expected no audit of unregistered table, got %d logs", len(logs))
+	}
+}
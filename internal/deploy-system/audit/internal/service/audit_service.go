@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/audit/internal/domain"
+	"devops-platform/internal/deploy-system/audit/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"encoding/csv"
+	"io"
+)
+
+// AuditService 审计日志服务
+type AuditService struct {
+	Repo *repository.Repository `inject:"AuditRepository"`
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+// ListAuditLogs 分页查询审计日志
+func (s *AuditService) ListAuditLogs(ctx context.Context, query *domain.AuditLogQuery) ([]*domain.AuditLogVO, int64, error) {
+	if err := query.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	logs, total, err := s.Repo.ListAuditLogs(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询审计日志失败", err)
+	}
+
+	vos := make([]*domain.AuditLogVO, 0, len(logs))
+	for _, log := range logs {
+		vos = append(vos, log.ToVO())
+	}
+	return vos, total, nil
+}
+
+// ExportAuditLogs 按条件导出审计日志为CSV，最多导出MaxExportRows条
+func (s *AuditService) ExportAuditLogs(ctx context.Context, query *domain.AuditLogQuery, w io.Writer) error {
+	if err := query.Validate(); err != nil {
+		return err
+	}
+
+	logs, err := s.Repo.FindAuditLogs(ctx, query, domain.MaxExportRows)
+	if err != nil {
+		return common.InternalError("查询审计日志失败", err)
+	}
+
+	// 写入UTF-8 BOM，便于Excel正确识别中文
+	if _, err = w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return common.InternalError("导出审计日志失败", err)
+	}
+	writer := csv.NewWriter(w)
+	if err = writer.Write(domain.CSVHeader()); err != nil {
+		return common.InternalError("导出审计日志失败", err)
+	}
+	for _, log := range logs {
+		if err = writer.Write(log.CSVRecord()); err != nil {
+			return common.InternalError("导出审计日志失败", err)
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return common.InternalError("导出审计日志失败", err)
+	}
+	return nil
+}
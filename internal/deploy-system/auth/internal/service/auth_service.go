@@ -105,6 +105,7 @@ func (s *AuthService) generateToken(user *domain.User) (string, time.Time, error
 		Username: user.Username,
 		Name:     user.Nickname,
 		Role:     int(user.RoleID),
+		DeptID:   user.DeptID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expireTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -172,6 +173,7 @@ func (s *AuthService) VerifyToken(ctx context.Context, token string) (*security.
 		Username:  claims.Username,
 		RealName:  claims.Name,
 		Role:      claims.Role,
+		DeptID:    claims.DeptID,
 		LoginTime: time.Unix(claims.IssuedAt.Unix(), 0),
 	}
 
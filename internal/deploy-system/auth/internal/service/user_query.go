@@ -13,9 +13,9 @@ import (
 
 // UserQuery 用户查询服务实现
 type UserQuery struct {
-	Repo              repository.Repository
-	Logger            *logrus.Logger
-	DepartmentService organization.DepartmentService
+	Repo              *repository.Repository         `inject:"AuthRepository"`
+	Logger            *logrus.Logger                 `inject:"Logger"`
+	DepartmentService organization.DepartmentService `inject:"DepartmentService"`
 }
 
 func NewUserQuery() *UserQuery {
@@ -13,19 +13,36 @@ const (
 	BeanAuthorizationService = domain.BeanAuthorizationService
 	BeanRoleService          = domain.BeanRoleService
 	BeanPermissionService    = domain.BeanPermissionService
+	BeanPolicyService        = domain.BeanPolicyService
+	BeanGrantService         = domain.BeanGrantService
+	BeanRouteService         = domain.BeanRouteService
 )
 
 // 领域对象类型别名
 type (
-	RoleVO                  = domain.RoleVO
-	PermissionVO            = domain.PermissionVO
-	MenuVO                  = domain.MenuVO
-	CreateRoleCommand       = domain.CreateRoleCommand
-	UpdateRoleCommand       = domain.UpdateRoleCommand
-	RoleQuery               = domain.RoleQuery
-	CreatePermissionCommand = domain.CreatePermissionCommand
-	UpdatePermissionCommand = domain.UpdatePermissionCommand
-	PermissionQuery         = domain.PermissionQuery
+	RoleVO                    = domain.RoleVO
+	RolePermissionDiffVO      = domain.RolePermissionDiffVO
+	PermissionVO              = domain.PermissionVO
+	MenuVO                    = domain.MenuVO
+	CreateRoleCommand         = domain.CreateRoleCommand
+	UpdateRoleCommand         = domain.UpdateRoleCommand
+	RoleQuery                 = domain.RoleQuery
+	CreatePermissionCommand   = domain.CreatePermissionCommand
+	UpdatePermissionCommand   = domain.UpdatePermissionCommand
+	PermissionQuery           = domain.PermissionQuery
+	PolicyDocument            = domain.PolicyDocument
+	PolicyImportResultVO      = domain.PolicyImportResultVO
+	UserRole                  = domain.UserRole
+	RoleGrantRequest          = domain.RoleGrantRequest
+	RoleGrantLog              = domain.RoleGrantLog
+	GrantRoleCommand          = domain.GrantRoleCommand
+	RevokeGrantCommand        = domain.RevokeGrantCommand
+	CreateGrantRequestCommand = domain.CreateGrantRequestCommand
+	ReviewGrantRequestCommand = domain.ReviewGrantRequestCommand
+	GrantRequestQuery         = domain.GrantRequestQuery
+	GrantLogQuery             = domain.GrantLogQuery
+	RouteSyncResultVO         = domain.RouteSyncResultVO
+	RouteReportVO             = domain.RouteReportVO
 )
 
 // 权限服务接口
@@ -54,8 +71,8 @@ type RoleService interface {
 	// CreateRole 创建角色
 	CreateRole(ctx context.Context, command *domain.CreateRoleCommand) (types.Long, error)
 
-	// UpdateRole 更新角色
-	UpdateRole(ctx context.Context, command *domain.UpdateRoleCommand) error
+	// UpdateRole 更新角色，父角色变化时返回有效权限差异
+	UpdateRole(ctx context.Context, command *domain.UpdateRoleCommand) (*domain.RolePermissionDiffVO, error)
 
 	// DeleteRole 删除角色
 	DeleteRole(ctx context.Context, roleID types.Long) error
@@ -93,3 +110,48 @@ type PermissionService interface {
 	// GetPermissionTree 获取权限树结构
 	GetPermissionTree(ctx context.Context) ([]*domain.PermissionVO, error)
 }
+
+// 权限策略导入导出接口
+type PolicyService interface {
+	// ExportPolicy 导出角色、权限树、角色权限绑定及用户角色绑定
+	ExportPolicy(ctx context.Context) (*domain.PolicyDocument, error)
+
+	// ImportPolicy 幂等导入权限策略文档，dryRun只返回变更计划，prune删除文档中未声明的数据
+	ImportPolicy(ctx context.Context, doc *domain.PolicyDocument, dryRun, prune bool) (*domain.PolicyImportResultVO, error)
+}
+
+// 临时角色授权接口
+type GrantService interface {
+	// GrantRole 临时授予用户角色
+	GrantRole(ctx context.Context, command *domain.GrantRoleCommand) error
+
+	// RevokeGrant 撤销用户的临时授权
+	RevokeGrant(ctx context.Context, command *domain.RevokeGrantCommand) error
+
+	// ListActiveGrants 获取临时授权列表，userID为0时查询所有用户
+	ListActiveGrants(ctx context.Context, userID types.Long) ([]*domain.UserRole, error)
+
+	// CreateGrantRequest 当前用户申请临时角色授权
+	CreateGrantRequest(ctx context.Context, command *domain.CreateGrantRequestCommand) (types.Long, error)
+
+	// ApproveGrantRequest 批准临时授权申请
+	ApproveGrantRequest(ctx context.Context, command *domain.ReviewGrantRequestCommand) error
+
+	// RejectGrantRequest 拒绝临时授权申请
+	RejectGrantRequest(ctx context.Context, command *domain.ReviewGrantRequestCommand) error
+
+	// ListGrantRequests 查询临时授权申请
+	ListGrantRequests(ctx context.Context, query *domain.GrantRequestQuery) ([]*domain.RoleGrantRequest, int64, error)
+
+	// ListGrantLogs 查询角色授权日志
+	ListGrantLogs(ctx context.Context, query *domain.GrantLogQuery) ([]*domain.RoleGrantLog, int64, error)
+}
+
+// 路由权限同步接口
+type RouteService interface {
+	// SyncRoutes 将已注册的路由同步为API权限，并标记路由已不存在的权限
+	SyncRoutes(ctx context.Context) (*domain.RouteSyncResultVO, error)
+
+	// GetRouteReport 获取没有任何角色可以访问的路由及孤立的API权限
+	GetRouteReport(ctx context.Context) (*domain.RouteReportVO, error)
+}
@@ -5,6 +5,7 @@ import (
 	"devops-platform/internal/deploy-system/authorization/internal/domain"
 	"devops-platform/internal/deploy-system/authorization/internal/repository"
 	"devops-platform/internal/deploy-system/authorization/internal/service"
+	"devops-platform/internal/pkg/periodic"
 	"devops-platform/pkg/beans"
 
 	"github.com/sirupsen/logrus"
@@ -24,6 +25,20 @@ func init() {
 	// 注册权限服务
 	beans.Register(domain.BeanPermissionService, service.NewPermissionService())
 
+	// 注册权限策略导入导出服务
+	beans.Register(domain.BeanPolicyService, service.NewPolicyService())
+
+	// 注册临时角色授权服务及过期回收任务
+	grantService := service.NewGrantService()
+	beans.Register(domain.BeanGrantService, grantService)
+	beans.Register(domain.BeanGrantReaper, periodic.New("临时授权回收", domain.GrantReapInterval, grantService.ReapGrants).RunOnStart())
+
+	// 注册路由权限同步服务
+	beans.Register(domain.BeanRouteService, service.NewRouteService())
+
+	// 注册数据权限解析服务，供各模块仓储按数据权限过滤
+	beans.Register(domain.BeanDataScopeService, service.NewDataScopeService())
+
 	// 注册控制器
 	beans.Register(domain.BeanAuthorizationController, controller.NewAuthorizationController())
 
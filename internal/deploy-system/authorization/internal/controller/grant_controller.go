@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/authorization"
+	"devops-platform/internal/deploy-system/authorization/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GrantController 临时角色授权控制器
+type GrantController struct {
+	grantService authorization.GrantService
+}
+
+// GrantRole 临时授予角色
+// @Summary 临时授予角色
+// @Description 为用户临时授予角色，到期后自动回收
+// @Tags 临时授权
+// @Accept  json
+// @Produce  json
+// @Param data body domain.GrantRoleCommand true "授权信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/authorization/grants [post]
+func (c *GrantController) GrantRole(ctx *gin.Context) {
+	var req domain.GrantRoleCommand
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := c.grantService.GrantRole(ctx, &req); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, nil)
+}
+
+// RevokeGrant 撤销临时授权
+// @Summary 撤销临时授权
+// @Description 提前撤销用户在指定角色上的临时授权
+// @Tags 临时授权
+// @Accept  json
+// @Produce  json
+// @Param data body domain.RevokeGrantCommand true "撤销信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/authorization/grants/revoke [post]
+func (c *GrantController) RevokeGrant(ctx *gin.Context) {
+	var req domain.RevokeGrantCommand
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := c.grantService.RevokeGrant(ctx, &req); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ListActiveGrants 查询临时授权
+// @Summary 查询临时授权
+// @Description 查询尚未回收的临时授权
+// @Tags 临时授权
+// @Produce  json
+// @Param user_id query int false "用户ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/authorization/grants [get]
+func (c *GrantController) ListActiveGrants(ctx *gin.Context) {
+	var userID types.Long
+	if value := ctx.Query("user_id"); value != "" {
+		id, err := types.StringToLong(value)
+		if err != nil {
+			common.ResponseBadRequest(ctx, "参数错误: 用户ID必须是数字")
+			return
+		}
+		userID = id
+	}
+
+	grants, err := c.grantService.ListActiveGrants(ctx, userID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, grants)
+}
+
+// ListGrantLogs 查询授权日志
+// @Summary 查询授权日志
+// @Description 查询角色的授予、撤销及过期回收记录
+// @Tags 临时授权
+// @Produce  json
+// @Param user_id query int false "用户ID"
+// @Param role_id query int false "角色ID"
+// @Param action query string false "动作: grant, revoke, expire"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response
+// @Router /api/v1/authorization/grants/logs [get]
+func (c *GrantController) ListGrantLogs(ctx *gin.Context) {
+	var query domain.GrantLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	logs, total, err := c.grantService.ListGrantLogs(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccessWithPageExt(ctx, logs, total, query.Page, query.Size)
+}
+
+// CreateGrantRequest 申请临时授权
+// @Summary 申请临时授权
+// @Description 当前用户申请临时角色授权（JIT提权），需要他人审批
+// @Tags 临时授权
+// @Accept  json
+// @Produce  json
+// @Param data body domain.CreateGrantRequestCommand true "申请信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/authorization/grant-requests [post]
+func (c *GrantController) CreateGrantRequest(ctx *gin.Context) {
+	var req domain.CreateGrantRequestCommand
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	id, err := c.grantService.CreateGrantRequest(ctx, &req)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, gin.H{"id": id})
+}
+
+// ListGrantRequests 查询临时授权申请
+// @Summary 查询临时授权申请
+// @Description 分页查询临时授权申请
+// @Tags 临时授权
+// @Produce  json
+// @Param user_id query int false "申请人ID"
+// @Param status query string false "状态: pending, approved, rejected"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response
+// @Router /api/v1/authorization/grant-requests [get]
+func (c *GrantController) ListGrantRequests(ctx *gin.Context) {
+	var query domain.GrantRequestQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	requests, total, err := c.grantService.ListGrantRequests(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccessWithPageExt(ctx, requests, total, query.Page, query.Size)
+}
+
+// ApproveGrantRequest 批准临时授权申请
+// @Summary 批准临时授权申请
+// @Description 批准后立即授予角色，授权时长从批准时开始计算
+// @Tags 临时授权
+// @Accept  json
+// @Produce  json
+// @Param id path int true "申请ID"
+// @Param data body domain.ReviewGrantRequestCommand false "审批意见"
+// @Success 200 {object} common.Response
+// @Router /api/v1/authorization/grant-requests/{id}/approve [post]
+func (c *GrantController) ApproveGrantRequest(ctx *gin.Context) {
+	req, ok := bindReviewCommand(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.grantService.ApproveGrantRequest(ctx, req); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, nil)
+}
+
+// RejectGrantRequest 拒绝临时授权申请
+// @Summary 拒绝临时授权申请
+// @Description 拒绝临时授权申请
+// @Tags 临时授权
+// @Accept  json
+// @Produce  json
+// @Param id path int true "申请ID"
+// @Param data body domain.ReviewGrantRequestCommand false "审批意见"
+// @Success 200 {object} common.Response
+// @Router /api/v1/authorization/grant-requests/{id}/reject [post]
+func (c *GrantController) RejectGrantRequest(ctx *gin.Context) {
+	req, ok := bindReviewCommand(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.grantService.RejectGrantRequest(ctx, req); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, nil)
+}
+
+// bindReviewCommand 绑定审批命令，请求体可为空
+func bindReviewCommand(ctx *gin.Context) (*domain.ReviewGrantRequestCommand, bool) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: 申请ID必须是数字")
+		return nil, false
+	}
+
+	var req domain.ReviewGrantRequestCommand
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+			return nil, false
+		}
+	}
+	req.ID = id
+	return &req, true
+}
+
+// InjectService 注入服务
+func (c *GrantController) InjectService(getBean func(string) interface{}) {
+	service, ok := getBean(domain.BeanGrantService).(authorization.GrantService)
+	if !ok {
+		logrus.Errorf("初始化时获取[%s]失败", domain.BeanGrantService)
+		return
+	}
+	c.grantService = service
+}
+
+// NewGrantController 创建临时角色授权控制器实例
+func NewGrantController() *GrantController {
+	return &GrantController{}
+}
@@ -15,6 +15,7 @@ import (
 // PermissionController 权限控制器
 type PermissionController struct {
 	permissionService authorization.PermissionService
+	routeService      authorization.RouteService
 	logger            *logrus.Logger
 }
 
@@ -191,6 +192,40 @@ func (c *PermissionController) GetPermissionTree(ctx *gin.Context) {
 	common.ResponseSuccess(ctx, tree)
 }
 
+// SyncRoutes 同步路由权限
+// @Summary 同步路由权限
+// @Description 将已注册的API路由同步为API权限，按模块归类，并标记路由已不存在的权限
+// @Tags 权限管理
+// @Produce  json
+// @Success 200 {object} common.Response{data=domain.RouteSyncResultVO}
+// @Router /api/v1/authorization/permissions/sync-routes [post]
+func (c *PermissionController) SyncRoutes(ctx *gin.Context) {
+	result, err := c.routeService.SyncRoutes(ctx)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, result)
+}
+
+// GetRouteReport 获取路由权限报告
+// @Summary 获取路由权限报告
+// @Description 列出没有任何角色可以访问的路由，以及路由已不存在的API权限
+// @Tags 权限管理
+// @Produce  json
+// @Success 200 {object} common.Response{data=domain.RouteReportVO}
+// @Router /api/v1/authorization/permissions/route-report [get]
+func (c *PermissionController) GetRouteReport(ctx *gin.Context) {
+	report, err := c.routeService.GetRouteReport(ctx)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, report)
+}
+
 // InjectService 注入服务
 func (c *PermissionController) InjectService(getBean func(string) interface{}) {
 	service, ok := getBean(domain.BeanPermissionService).(authorization.PermissionService)
@@ -199,6 +234,13 @@ func (c *PermissionController) InjectService(getBean func(string) interface{}) {
 		return
 	}
 	c.permissionService = service
+
+	routeService, ok := getBean(domain.BeanRouteService).(authorization.RouteService)
+	if !ok {
+		logrus.Errorf("初始化时获取[%s]失败", domain.BeanRouteService)
+		return
+	}
+	c.routeService = routeService
 }
 
 // NewPermissionController 创建权限控制器实例
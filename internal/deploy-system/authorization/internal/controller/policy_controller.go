@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"devops-platform/internal/deploy-system/authorization"
+	"devops-platform/internal/deploy-system/authorization/internal/domain"
+	"devops-platform/internal/pkg/common"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PolicyController 权限策略导入导出控制器
+type PolicyController struct {
+	policyService authorization.PolicyService
+}
+
+// ExportPolicy 导出权限策略
+// @Summary 导出权限策略
+// @Description 导出角色、权限树、角色权限绑定及用户角色绑定为一份YAML/JSON文档
+// @Tags 权限管理
+// @Produce  json,x-yaml
+// @Param format query string false "文档格式: yaml(默认)/json"
+// @Success 200 {object} domain.PolicyDocument
+// @Router /api/v1/authz/export [get]
+func (c *PolicyController) ExportPolicy(ctx *gin.Context) {
+	format := strings.ToLower(ctx.DefaultQuery("format", "yaml"))
+	if format != "yaml" && format != "json" {
+		common.ResponseBadRequest(ctx, "参数错误: format只支持yaml或json")
+		return
+	}
+
+	doc, err := c.policyService.ExportPolicy(ctx)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename=authz-policy."+format)
+	if format == "json" {
+		ctx.JSON(http.StatusOK, doc)
+		return
+	}
+	ctx.YAML(http.StatusOK, doc)
+}
+
+// ImportPolicy 导入权限策略
+// @Summary 导入权限策略
+// @Description 幂等导入权限策略文档，请求体为YAML（Content-Type: application/x-yaml）或JSON
+// @Tags 权限管理
+// @Accept  json,x-yaml
+// @Produce  json
+// @Param dry_run query bool false "仅返回变更计划，不实际执行"
+// @Param prune query bool false "删除文档中未声明的角色、权限及绑定"
+// @Param data body domain.PolicyDocument true "权限策略文档"
+// @Success 200 {object} common.Response{data=domain.PolicyImportResultVO}
+// @Router /api/v1/authz/import [post]
+func (c *PolicyController) ImportPolicy(ctx *gin.Context) {
+	dryRun, err := parseBoolQuery(ctx, "dry_run")
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: dry_run必须是布尔值")
+		return
+	}
+	prune, err := parseBoolQuery(ctx, "prune")
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: prune必须是布尔值")
+		return
+	}
+
+	var doc domain.PolicyDocument
+	if strings.Contains(ctx.ContentType(), "yaml") {
+		err = ctx.ShouldBindYAML(&doc)
+	} else {
+		err = ctx.ShouldBindJSON(&doc)
+	}
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := c.policyService.ImportPolicy(ctx, &doc, dryRun, prune)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+
+	common.ResponseSuccess(ctx, result)
+}
+
+// parseBoolQuery 解析布尔类型的查询参数，未传时为false
+func parseBoolQuery(ctx *gin.Context, key string) (bool, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// InjectService 注入服务
+func (c *PolicyController) InjectService(getBean func(string) interface{}) {
+	service, ok := getBean(domain.BeanPolicyService).(authorization.PolicyService)
+	if !ok {
+		logrus.Errorf("初始化时获取[%s]失败", domain.BeanPolicyService)
+		return
+	}
+	c.policyService = service
+}
+
+// NewPolicyController 创建权限策略控制器实例
+func NewPolicyController() *PolicyController {
+	return &PolicyController{}
+}
@@ -15,7 +15,7 @@ import (
 // 定义本地接口，只包含本控制器需要的方法
 type roleService interface {
 	CreateRole(ctx context.Context, command *domain.CreateRoleCommand) (types.Long, error)
-	UpdateRole(ctx context.Context, command *domain.UpdateRoleCommand) error
+	UpdateRole(ctx context.Context, command *domain.UpdateRoleCommand) (*domain.RolePermissionDiffVO, error)
 	DeleteRole(ctx context.Context, id types.Long) error
 	GetRoleByID(ctx context.Context, id types.Long) (*domain.RoleVO, error)
 	ListRoles(ctx context.Context, query *domain.RoleQuery) ([]*domain.RoleVO, int64, error)
@@ -57,7 +57,7 @@ func (c *RoleController) CreateRole(ctx *gin.Context) {
 
 // UpdateRole 更新角色
 // @Summary 更新角色
-// @Description 更新角色信息
+// @Description 更新角色信息，父角色变化时返回有效权限差异
 // @Tags 角色管理
 // @Accept  json
 // @Produce  json
@@ -88,14 +88,14 @@ func (c *RoleController) UpdateRole(ctx *gin.Context) {
 		return
 	}
 
-	// 更新角色
-	err = c.roleService.UpdateRole(ctx, &req)
+	// 更新角色，父角色变化时返回有效权限差异
+	diff, err := c.roleService.UpdateRole(ctx, &req)
 	if err != nil {
 		common.ResponseError(ctx, err)
 		return
 	}
 
-	common.ResponseSuccess(ctx, nil)
+	common.ResponseSuccess(ctx, diff)
 }
 
 // DeleteRole 删除角色
@@ -39,10 +39,14 @@ func (c *AuthorizationController) injectRouting(getBean func(string) interface{}
 	//获取其他控制器
 	roleController := NewRoleController()
 	permController := NewPermissionController()
+	policyController := NewPolicyController()
+	grantController := NewGrantController()
 
 	// 注入其他控制器的服务
 	roleController.InjectService(getBean)
 	permController.InjectService(getBean)
+	policyController.InjectService(getBean)
+	grantController.InjectService(getBean)
 
 	// 权限认证相关路由，需要登录认证
 	authzRouter := router.Group("/api/v1/authorization")
@@ -97,10 +101,36 @@ func (c *AuthorizationController) injectRouting(getBean func(string) interface{}
 		permRoute.POST("", permController.CreatePermission)
 		permRoute.GET("", permController.ListPermissions)
 		permRoute.GET("/tree", permController.GetPermissionTree)
+		permRoute.POST("/sync-routes", permController.SyncRoutes)
+		permRoute.GET("/route-report", permController.GetRouteReport)
 
 		// 2. 单独注册带ID参数的路由，使用唯一的路径变体防止冲突
 		permRoute.PUT("/detail/:id", permController.UpdatePermission)
 		permRoute.DELETE("/detail/:id", permController.DeletePermission)
 		permRoute.GET("/detail/:id", permController.GetPermissionByID)
+
+		// === 临时授权相关路由 ===
+		grantsRouter := authzRouter.Group("/grants")
+		{
+			grantsRouter.POST("", grantController.GrantRole)
+			grantsRouter.GET("", grantController.ListActiveGrants)
+			grantsRouter.POST("/revoke", grantController.RevokeGrant)
+			grantsRouter.GET("/logs", grantController.ListGrantLogs)
+		}
+		grantRequestsRouter := authzRouter.Group("/grant-requests")
+		{
+			grantRequestsRouter.POST("", grantController.CreateGrantRequest)
+			grantRequestsRouter.GET("", grantController.ListGrantRequests)
+			grantRequestsRouter.POST("/:id/approve", grantController.ApproveGrantRequest)
+			grantRequestsRouter.POST("/:id/reject", grantController.RejectGrantRequest)
+		}
+	}
+
+	// 权限策略导入导出路由
+	policyRouter := router.Group("/api/v1/authz")
+	policyRouter.Use(middleware.JWTAuth())
+	{
+		policyRouter.GET("/export", policyController.ExportPolicy)
+		policyRouter.POST("/import", policyController.ImportPolicy)
 	}
 }
@@ -5,6 +5,11 @@ const (
 	BeanAuthorizationService    = "AuthorizationService"
 	BeanRoleService             = "RoleService"
 	BeanPermissionService       = "PermissionService"
+	BeanPolicyService           = "PolicyService"
+	BeanGrantService            = "RoleGrantService"
+	BeanGrantReaper             = "RoleGrantReaper"
+	BeanRouteService            = "RouteService"
+	BeanDataScopeService        = "DataScopeService"
 	BeanRepository              = "AuthorizationRepository"
 	BeanAuthorizationController = "AuthorizationController"
 
@@ -1,11 +1,13 @@
 package domain
 
 import (
+	"devops-platform/internal/pkg/datascope"
 	"devops-platform/internal/pkg/enum"
 	"devops-platform/internal/pkg/module"
 	"devops-platform/pkg/common"
 	"devops-platform/pkg/types"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -13,11 +15,14 @@ import (
 // Role 角色实体
 type Role struct {
 	module.Module
+	ParentID    types.Long  `json:"parent_id" gorm:"comment:'父角色ID，继承父角色的全部权限'"`
 	Name        string      `json:"name" gorm:"size:128;comment:'角色名称'"`
 	Code        string      `json:"code" gorm:"size:64;uniqueIndex;comment:'角色唯一标识符'"`
 	Description string      `json:"description" gorm:"size:255;comment:'角色描述'"`
 	Status      enum.Status `json:"status" gorm:"comment:'状态 1:启用 0:禁用'"`
 	SortOrder   int         `json:"sort_order" gorm:"comment:'排序'"`
+	DataScope   string      `json:"data_scope" gorm:"size:32;default:'all';comment:'数据权限 all/dept/dept_and_children/own_apps/custom'"`
+	DataDepts   string      `json:"-" gorm:"column:data_scope_depts;size:1024;comment:'自定义数据权限的部门ID，逗号分隔'"`
 }
 
 // Validate 验证角色
@@ -31,15 +36,62 @@ func (r *Role) Validate() error {
 	return nil
 }
 
+// SetDataScope 设置角色数据权限，自定义范围必须指定部门
+func (r *Role) SetDataScope(scope string, deptIDs []types.Long) error {
+	scope = strings.TrimSpace(scope)
+	if !datascope.IsValid(scope) {
+		return fmt.Errorf("不支持的数据权限范围: %s", scope)
+	}
+	if scope == "" {
+		scope = datascope.ScopeAll
+	}
+	if scope != datascope.ScopeCustom {
+		r.DataScope = scope
+		r.DataDepts = ""
+		return nil
+	}
+	if len(deptIDs) == 0 {
+		return errors.New("自定义数据权限必须指定部门")
+	}
+
+	ids := make([]string, 0, len(deptIDs))
+	seen := make(map[types.Long]bool, len(deptIDs))
+	for _, id := range deptIDs {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id.String())
+	}
+	r.DataScope = scope
+	r.DataDepts = strings.Join(ids, ",")
+	return nil
+}
+
+// DataScopeDeptIDs 自定义数据权限的部门ID
+func (r *Role) DataScopeDeptIDs() []types.Long {
+	ids := make([]types.Long, 0)
+	for _, value := range strings.Split(r.DataDepts, ",") {
+		id, err := types.StringToLong(strings.TrimSpace(value))
+		if err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // ToVO 转换为视图对象
 func (r *Role) ToVO() *RoleVO {
 	return &RoleVO{
 		ID:          r.ID,
+		ParentID:    r.ParentID,
 		Name:        r.Name,
 		Code:        r.Code,
 		Description: r.Description,
 		Status:      r.Status,
 		SortOrder:   r.SortOrder,
+		DataScope:   r.DataScope,
+		DataDepts:   r.DataScopeDeptIDs(),
 		CreatedAt:   r.CreatedAt.Time,
 		UpdatedAt:   r.LastModifiedAt.Time,
 	}
@@ -47,14 +99,84 @@ func (r *Role) ToVO() *RoleVO {
 
 // RoleVO 角色视图对象
 type RoleVO struct {
-	ID          types.Long  `json:"id"`
-	Name        string      `json:"name"`
-	Code        string      `json:"code"`
-	Description string      `json:"description"`
-	Status      enum.Status `json:"status"`
-	SortOrder   int         `json:"sort_order"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID          types.Long   `json:"id"`
+	ParentID    types.Long   `json:"parent_id"`
+	Name        string       `json:"name"`
+	Code        string       `json:"code"`
+	Description string       `json:"description"`
+	Status      enum.Status  `json:"status"`
+	SortOrder   int          `json:"sort_order"`
+	DataScope   string       `json:"data_scope"`
+	DataDepts   []types.Long `json:"data_scope_depts"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// RoleHierarchy 角色继承关系，key为角色ID
+type RoleHierarchy map[types.Long]*Role
+
+// NewRoleHierarchy 根据角色列表构建角色继承关系
+func NewRoleHierarchy(roles []*Role) RoleHierarchy {
+	hierarchy := make(RoleHierarchy, len(roles))
+	for _, role := range roles {
+		hierarchy[role.ID] = role
+	}
+	return hierarchy
+}
+
+// Ancestors 获取角色的所有祖先角色ID（不含自身），由近及远排列
+func (h RoleHierarchy) Ancestors(roleID types.Long) []types.Long {
+	ancestors := make([]types.Long, 0)
+	visited := map[types.Long]bool{roleID: true}
+
+	role, ok := h[roleID]
+	for ok && role.ParentID > 0 && !visited[role.ParentID] {
+		visited[role.ParentID] = true
+		ancestors = append(ancestors, role.ParentID)
+		role, ok = h[role.ParentID]
+	}
+
+	return ancestors
+}
+
+// Expand 获取角色及其所有祖先角色ID（去重）
+func (h RoleHierarchy) Expand(roleIDs ...types.Long) []types.Long {
+	seen := make(map[types.Long]bool)
+	result := make([]types.Long, 0, len(roleIDs))
+	for _, roleID := range roleIDs {
+		for _, id := range append([]types.Long{roleID}, h.Ancestors(roleID)...) {
+			if !seen[id] {
+				seen[id] = true
+				result = append(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// WouldCycle 判断将角色的父角色设置为parentID后是否会形成循环继承
+func (h RoleHierarchy) WouldCycle(roleID, parentID types.Long) bool {
+	if parentID == 0 {
+		return false
+	}
+	if parentID == roleID {
+		return true
+	}
+	for _, id := range h.Ancestors(parentID) {
+		if id == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermissionDiffVO 角色继承关系变更导致的有效权限差异
+type RolePermissionDiffVO struct {
+	RoleID      types.Long      `json:"role_id"`
+	OldParentID types.Long      `json:"old_parent_id"`
+	NewParentID types.Long      `json:"new_parent_id"`
+	Added       []*PermissionVO `json:"added"`
+	Removed     []*PermissionVO `json:"removed"`
 }
 
 // Permission 权限实体
@@ -71,6 +193,7 @@ type Permission struct {
 	Status     enum.Status `json:"status" gorm:"comment:'状态 1:启用 0:禁用'"`
 	Hidden     bool        `json:"hidden" gorm:"comment:'是否隐藏'"`
 	SortOrder  int         `json:"sort_order" gorm:"comment:'排序'"`
+	Orphaned   bool        `json:"orphaned" gorm:"comment:'API权限对应的路由已不存在'"`
 	ApiPath    string      `json:"-" gorm:"-"` // API路径（用于Casbin集成）
 	ApiMethod  string      `json:"-" gorm:"-"` // API方法（用于Casbin集成）
 }
@@ -107,6 +230,7 @@ func (p *Permission) ToVO() *PermissionVO {
 		Status:     p.Status,
 		Hidden:     p.Hidden,
 		SortOrder:  p.SortOrder,
+		Orphaned:   p.Orphaned,
 		CreatedAt:  p.CreatedAt.Time,
 		UpdatedAt:  p.LastModifiedAt.Time,
 	}
@@ -126,6 +250,7 @@ type PermissionVO struct {
 	Status     enum.Status     `json:"status"`
 	Hidden     bool            `json:"hidden"`
 	SortOrder  int             `json:"sort_order"`
+	Orphaned   bool            `json:"orphaned"`
 	CreatedAt  time.Time       `json:"created_at"`
 	UpdatedAt  time.Time       `json:"updated_at"`
 	Children   []*PermissionVO `json:"children,omitempty"`
@@ -157,9 +282,16 @@ type UserRole struct {
 	ID        types.Long `json:"id" gorm:"primaryKey;autoIncrement"`
 	UserID    types.Long `json:"user_id" gorm:"index:idx_user_role;comment:'用户ID'"`
 	RoleID    types.Long `json:"role_id" gorm:"index:idx_user_role;comment:'角色ID'"`
+	ExpiresAt *time.Time `json:"expires_at" gorm:"index;comment:'过期时间，为空表示永久授权'"`
+	Reason    string     `json:"reason" gorm:"size:255;comment:'授权原因'"`
 	CreatedAt time.Time  `json:"created_at"`
 }
 
+// IsTemporary 是否为临时授权
+func (ur *UserRole) IsTemporary() bool {
+	return ur.ExpiresAt != nil
+}
+
 // CasbinRule Casbin规则实体
 type CasbinRule struct {
 	ID    types.Long `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -174,10 +306,13 @@ type CasbinRule struct {
 
 // CreateRoleCommand 创建角色命令
 type CreateRoleCommand struct {
-	Name        string `json:"name" binding:"required"`
-	Code        string `json:"code" binding:"required"`
-	Description string `json:"description"`
-	SortOrder   int    `json:"sort_order"`
+	ParentID    types.Long   `json:"parent_id"`
+	Name        string       `json:"name" binding:"required"`
+	Code        string       `json:"code" binding:"required"`
+	Description string       `json:"description"`
+	SortOrder   int          `json:"sort_order"`
+	DataScope   string       `json:"data_scope"`
+	DataDepts   []types.Long `json:"data_scope_depts"`
 }
 
 // ToRole 转换为角色实体
@@ -186,6 +321,7 @@ func (command *CreateRoleCommand) ToRole() (*Role, error) {
 	command.Code = strings.TrimSpace(command.Code)
 
 	role := &Role{
+		ParentID:    command.ParentID,
 		Name:        command.Name,
 		Code:        command.Code,
 		Description: command.Description,
@@ -198,17 +334,25 @@ func (command *CreateRoleCommand) ToRole() (*Role, error) {
 		return nil, common.RequestParamError("", err)
 	}
 
+	err = role.SetDataScope(command.DataScope, command.DataDepts)
+	if err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+
 	return role, nil
 }
 
 // UpdateRoleCommand 更新角色命令
 type UpdateRoleCommand struct {
-	ID          types.Long  `json:"-"`
-	Name        string      `json:"name" binding:"required"`
-	Code        string      `json:"code" binding:"required"`
-	Description string      `json:"description"`
-	Status      enum.Status `json:"status"`
-	SortOrder   int         `json:"sort_order"`
+	ID          types.Long   `json:"-"`
+	ParentID    types.Long   `json:"parent_id"`
+	Name        string       `json:"name" binding:"required"`
+	Code        string       `json:"code" binding:"required"`
+	Description string       `json:"description"`
+	Status      enum.Status  `json:"status"`
+	SortOrder   int          `json:"sort_order"`
+	DataScope   string       `json:"data_scope"`
+	DataDepts   []types.Long `json:"data_scope_depts"`
 }
 
 // Validate 验证命令参数
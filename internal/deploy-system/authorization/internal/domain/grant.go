@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 临时授权申请状态
+const (
+	GrantRequestStatusPending  = "pending"  // 待审批
+	GrantRequestStatusApproved = "approved" // 已批准
+	GrantRequestStatusRejected = "rejected" // 已拒绝
+)
+
+// 授权日志动作
+const (
+	GrantActionGrant  = "grant"  // 授予
+	GrantActionRevoke = "revoke" // 撤销
+	GrantActionExpire = "expire" // 过期回收
+)
+
+// MaxGrantDuration 临时授权的最长时长
+const MaxGrantDuration = 7 * 24 * time.Hour
+
+// GrantReapInterval 过期授权回收周期
+const GrantReapInterval = time.Minute
+
+// RoleGrantRequest 临时角色授权申请（JIT提权）
+type RoleGrantRequest struct {
+	module.Module
+	UserID          types.Long `json:"user_id" gorm:"index;comment:'申请人用户ID'"`
+	RoleID          types.Long `json:"role_id" gorm:"comment:'申请的角色ID'"`
+	DurationMinutes int        `json:"duration_minutes" gorm:"comment:'申请时长（分钟）'"`
+	Reason          string     `json:"reason" gorm:"size:255;comment:'申请原因'"`
+	Status          string     `json:"status" gorm:"size:20;index;comment:'状态: pending, approved, rejected'"`
+	ApproverID      types.Long `json:"approver_id" gorm:"comment:'审批人ID'"`
+	ApproverName    string     `json:"approver_name" gorm:"size:255;comment:'审批人姓名'"`
+	ApprovedAt      *time.Time `json:"approved_at" gorm:"comment:'审批时间'"`
+	Comment         string     `json:"comment" gorm:"size:255;comment:'审批意见'"`
+	ExpiresAt       *time.Time `json:"expires_at" gorm:"comment:'授权过期时间'"`
+}
+
+// RoleGrantLog 角色授权日志，记录每一次授予、撤销及过期回收
+type RoleGrantLog struct {
+	ID        types.Long `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    types.Long `json:"user_id" gorm:"index;comment:'用户ID'"`
+	RoleID    types.Long `json:"role_id" gorm:"comment:'角色ID'"`
+	RoleCode  string     `json:"role_code" gorm:"size:64;comment:'角色编码'"`
+	Action    string     `json:"action" gorm:"size:20;comment:'动作: grant, revoke, expire'"`
+	Reason    string     `json:"reason" gorm:"size:255;comment:'原因'"`
+	ExpiresAt *time.Time `json:"expires_at" gorm:"comment:'授权过期时间，为空表示永久'"`
+	RequestID types.Long `json:"request_id" gorm:"comment:'关联的授权申请ID'"`
+	module.Operation
+}
+
+// GrantRoleCommand 临时授予角色命令
+type GrantRoleCommand struct {
+	UserID   types.Long `json:"user_id" binding:"required"`
+	RoleID   types.Long `json:"role_id" binding:"required"`
+	Duration string     `json:"duration" binding:"required"` // 授权时长，如 4h、30m
+	Reason   string     `json:"reason" binding:"required"`
+}
+
+// Validate 验证命令并返回授权时长
+func (command *GrantRoleCommand) Validate() (time.Duration, error) {
+	command.Reason = strings.TrimSpace(command.Reason)
+	if command.Reason == "" {
+		return 0, errors.New("授权原因不能为空")
+	}
+	return ParseGrantDuration(command.Duration)
+}
+
+// RevokeGrantCommand 撤销临时授权命令
+type RevokeGrantCommand struct {
+	UserID types.Long `json:"user_id" binding:"required"`
+	RoleID types.Long `json:"role_id" binding:"required"`
+	Reason string     `json:"reason"`
+}
+
+// CreateGrantRequestCommand 创建临时授权申请命令
+type CreateGrantRequestCommand struct {
+	RoleID   types.Long `json:"role_id" binding:"required"`
+	Duration string     `json:"duration" binding:"required"` // 申请时长，如 4h
+	Reason   string     `json:"reason" binding:"required"`
+}
+
+// ToRequest 转换为授权申请实体
+func (command *CreateGrantRequestCommand) ToRequest(userID types.Long) (*RoleGrantRequest, error) {
+	command.Reason = strings.TrimSpace(command.Reason)
+	if command.Reason == "" {
+		return nil, errors.New("申请原因不能为空")
+	}
+	duration, err := ParseGrantDuration(command.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return &RoleGrantRequest{
+		UserID:          userID,
+		RoleID:          command.RoleID,
+		DurationMinutes: int(duration / time.Minute),
+		Reason:          command.Reason,
+		Status:          GrantRequestStatusPending,
+	}, nil
+}
+
+// ReviewGrantRequestCommand 审批临时授权申请命令
+type ReviewGrantRequestCommand struct {
+	ID      types.Long `json:"-"`
+	Comment string     `json:"comment"`
+}
+
+// GrantRequestQuery 临时授权申请查询
+type GrantRequestQuery struct {
+	UserID types.Long `form:"user_id"`
+	Status string     `form:"status"`
+	Page   int        `form:"page"`
+	Size   int        `form:"size"`
+}
+
+// GrantLogQuery 授权日志查询
+type GrantLogQuery struct {
+	UserID types.Long `form:"user_id"`
+	RoleID types.Long `form:"role_id"`
+	Action string     `form:"action"`
+	Page   int        `form:"page"`
+	Size   int        `form:"size"`
+}
+
+// ParseGrantDuration 解析授权时长，必须为整分钟且不超过最长时长
+func ParseGrantDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("授权时长格式错误: %s", value)
+	}
+	if duration < time.Minute {
+		return 0, errors.New("授权时长不能少于1分钟")
+	}
+	if duration > MaxGrantDuration {
+		return 0, fmt.Errorf("授权时长不能超过%s", MaxGrantDuration)
+	}
+	return duration.Truncate(time.Minute), nil
+}
@@ -0,0 +1,386 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PolicyDocumentVersion 权限策略文档版本
+const PolicyDocumentVersion = "v1"
+
+// 策略变更对象类型
+const (
+	PolicyKindRole           = "role"
+	PolicyKindPermission     = "permission"
+	PolicyKindRolePermission = "role_permission"
+	PolicyKindUserRole       = "user_role"
+)
+
+// 策略变更动作
+const (
+	PolicyActionCreate = "create"
+	PolicyActionUpdate = "update"
+	PolicyActionDelete = "delete"
+)
+
+// PolicyDocument 声明式权限策略文档
+// 所有对象均使用自然键（角色编码、权限标识、用户名）关联，便于在不同环境间迁移
+type PolicyDocument struct {
+	Version         string                   `json:"version" yaml:"version"`
+	Roles           []*RoleSpec              `json:"roles" yaml:"roles"`
+	Permissions     []*PermissionSpec        `json:"permissions" yaml:"permissions"`
+	RolePermissions []*RolePermissionBinding `json:"role_permissions" yaml:"role_permissions"`
+	UserRoles       []*UserRoleBinding       `json:"user_roles" yaml:"user_roles"`
+}
+
+// RoleSpec 角色声明
+type RoleSpec struct {
+	Code        string  `json:"code" yaml:"code"`
+	Name        string  `json:"name" yaml:"name"`
+	Parent      string  `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Status      int     `json:"status" yaml:"status"`
+	SortOrder   int     `json:"sort_order" yaml:"sort_order"`
+	DataScope   string  `json:"data_scope,omitempty" yaml:"data_scope,omitempty"`
+	DataDepts   []int64 `json:"data_scope_depts,omitempty" yaml:"data_scope_depts,omitempty"`
+}
+
+// PermissionSpec 权限声明，通过Children表达权限树
+type PermissionSpec struct {
+	Key        string            `json:"key" yaml:"key"`
+	Name       string            `json:"name" yaml:"name"`
+	Type       string            `json:"type" yaml:"type"`
+	Path       string            `json:"path,omitempty" yaml:"path,omitempty"`
+	Method     string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Icon       string            `json:"icon,omitempty" yaml:"icon,omitempty"`
+	Component  string            `json:"component,omitempty" yaml:"component,omitempty"`
+	Permission string            `json:"permission,omitempty" yaml:"permission,omitempty"`
+	Status     int               `json:"status" yaml:"status"`
+	Hidden     bool              `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	SortOrder  int               `json:"sort_order" yaml:"sort_order"`
+	Children   []*PermissionSpec `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// RolePermissionBinding 角色权限绑定
+type RolePermissionBinding struct {
+	Role        string   `json:"role" yaml:"role"`
+	Permissions []string `json:"permissions" yaml:"permissions"`
+}
+
+// UserRoleBinding 用户角色绑定
+type UserRoleBinding struct {
+	Username string   `json:"username" yaml:"username"`
+	Roles    []string `json:"roles" yaml:"roles"`
+}
+
+// UserRoleRecord 用户角色关联记录（含用户名）
+type UserRoleRecord struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	RoleID   int64  `json:"role_id"`
+}
+
+// PolicyChange 策略导入产生的单项变更
+type PolicyChange struct {
+	Kind   string `json:"kind"`
+	Action string `json:"action"`
+	Key    string `json:"key"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// PolicyImportResultVO 策略导入结果
+type PolicyImportResultVO struct {
+	DryRun  bool            `json:"dry_run"`
+	Prune   bool            `json:"prune"`
+	Changes []*PolicyChange `json:"changes"`
+	Summary map[string]int  `json:"summary"`
+}
+
+// NewPolicyImportResultVO 根据变更列表构建导入结果
+func NewPolicyImportResultVO(changes []*PolicyChange, dryRun, prune bool) *PolicyImportResultVO {
+	summary := map[string]int{
+		PolicyActionCreate: 0,
+		PolicyActionUpdate: 0,
+		PolicyActionDelete: 0,
+	}
+	for _, change := range changes {
+		summary[change.Action]++
+	}
+	return &PolicyImportResultVO{
+		DryRun:  dryRun,
+		Prune:   prune,
+		Changes: changes,
+		Summary: summary,
+	}
+}
+
+// Key 获取权限的自然键
+// 优先使用权限标识；API权限使用"METHOD path"；其余使用"类型:路径"或"类型:名称"
+func (p *Permission) Key() string {
+	if p.Permission != "" {
+		return p.Permission
+	}
+	if p.Type == PermTypeApi {
+		return strings.ToUpper(p.Method) + " " + p.Path
+	}
+	if p.Path != "" {
+		return p.Type + ":" + p.Path
+	}
+	return p.Type + ":" + p.Name
+}
+
+// ToSpec 转换为权限声明（不含子权限）
+func (p *Permission) ToSpec() *PermissionSpec {
+	return &PermissionSpec{
+		Key:        p.Key(),
+		Name:       p.Name,
+		Type:       p.Type,
+		Path:       p.Path,
+		Method:     p.Method,
+		Icon:       p.Icon,
+		Component:  p.Component,
+		Permission: p.Permission,
+		Status:     int(p.Status),
+		Hidden:     p.Hidden,
+		SortOrder:  p.SortOrder,
+	}
+}
+
+// Normalize 规范化权限声明，未指定key时按实体规则生成
+// key由权限属性推导，显式指定的key必须与推导结果一致，保证重复导入时能匹配到同一权限
+func (s *PermissionSpec) Normalize() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Type = strings.TrimSpace(s.Type)
+	s.Key = strings.TrimSpace(s.Key)
+
+	key := s.ToPermission().Key()
+	if s.Key != "" && s.Key != key {
+		return fmt.Errorf("权限[%s]的key与权限属性不一致，应为: %s", s.Key, key)
+	}
+	s.Key = key
+	return nil
+}
+
+// ToPermission 转换为权限实体（不含ID和父权限）
+func (s *PermissionSpec) ToPermission() *Permission {
+	permission := &Permission{}
+	s.ApplyTo(permission)
+	return permission
+}
+
+// ApplyTo 将声明的属性写入权限实体
+func (s *PermissionSpec) ApplyTo(p *Permission) {
+	p.Name = s.Name
+	p.Type = s.Type
+	p.Path = s.Path
+	p.Method = s.Method
+	p.Icon = s.Icon
+	p.Component = s.Component
+	p.Permission = s.Permission
+	p.Status = enum.Status(s.Status)
+	p.Hidden = s.Hidden
+	p.SortOrder = s.SortOrder
+}
+
+// DiffFields 比较声明与权限实体，返回不一致的字段名
+func (s *PermissionSpec) DiffFields(p *Permission) []string {
+	fields := make([]string, 0)
+	if s.Name != p.Name {
+		fields = append(fields, "name")
+	}
+	if s.Type != p.Type {
+		fields = append(fields, "type")
+	}
+	if s.Path != p.Path {
+		fields = append(fields, "path")
+	}
+	if s.Method != p.Method {
+		fields = append(fields, "method")
+	}
+	if s.Icon != p.Icon {
+		fields = append(fields, "icon")
+	}
+	if s.Component != p.Component {
+		fields = append(fields, "component")
+	}
+	if s.Permission != p.Permission {
+		fields = append(fields, "permission")
+	}
+	if enum.Status(s.Status) != p.Status {
+		fields = append(fields, "status")
+	}
+	if s.Hidden != p.Hidden {
+		fields = append(fields, "hidden")
+	}
+	if s.SortOrder != p.SortOrder {
+		fields = append(fields, "sort_order")
+	}
+	return fields
+}
+
+// ToSpec 转换为角色声明，parentCode为父角色编码
+func (r *Role) ToSpec(parentCode string) *RoleSpec {
+	return &RoleSpec{
+		Code:        r.Code,
+		Name:        r.Name,
+		Parent:      parentCode,
+		Description: r.Description,
+		Status:      int(r.Status),
+		SortOrder:   r.SortOrder,
+		DataScope:   r.DataScope,
+		DataDepts:   longsToInt64s(r.DataScopeDeptIDs()),
+	}
+}
+
+// ApplyTo 将声明的属性写入角色实体（不含父角色）
+func (s *RoleSpec) ApplyTo(r *Role) {
+	r.Code = s.Code
+	r.Name = s.Name
+	r.Description = s.Description
+	r.Status = enum.Status(s.Status)
+	r.SortOrder = s.SortOrder
+	_ = r.SetDataScope(s.DataScope, s.dataDeptIDs())
+}
+
+// DiffFields 比较声明与角色实体，返回不一致的字段名（不含父角色）
+func (s *RoleSpec) DiffFields(r *Role) []string {
+	fields := make([]string, 0)
+	if s.Name != r.Name {
+		fields = append(fields, "name")
+	}
+	if s.Description != r.Description {
+		fields = append(fields, "description")
+	}
+	if enum.Status(s.Status) != r.Status {
+		fields = append(fields, "status")
+	}
+	if s.SortOrder != r.SortOrder {
+		fields = append(fields, "sort_order")
+	}
+	scoped := Role{DataScope: r.DataScope}
+	_ = scoped.SetDataScope(s.DataScope, s.dataDeptIDs())
+	if scoped.DataScope != r.DataScope || scoped.DataDepts != r.DataDepts {
+		fields = append(fields, "data_scope")
+	}
+	return fields
+}
+
+// dataDeptIDs 自定义数据权限的部门ID
+func (s *RoleSpec) dataDeptIDs() []types.Long {
+	ids := make([]types.Long, 0, len(s.DataDepts))
+	for _, id := range s.DataDepts {
+		ids = append(ids, types.Long(id))
+	}
+	return ids
+}
+
+// longsToInt64s 转换ID列表，便于在策略文档中以数字输出
+func longsToInt64s(ids []types.Long) []int64 {
+	if len(ids) == 0 {
+		return nil
+	}
+	values := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, int64(id))
+	}
+	return values
+}
+
+// WalkPermissions 先序遍历权限树，fn的parent为父权限声明（根节点为nil）
+func WalkPermissions(specs []*PermissionSpec, parent *PermissionSpec, fn func(spec, parent *PermissionSpec) error) error {
+	for _, spec := range specs {
+		if err := fn(spec, parent); err != nil {
+			return err
+		}
+		if err := WalkPermissions(spec.Children, spec, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate 校验策略文档自身的完整性
+func (d *PolicyDocument) Validate() error {
+	if d.Version != "" && d.Version != PolicyDocumentVersion {
+		return fmt.Errorf("不支持的策略文档版本: %s", d.Version)
+	}
+
+	roleCodes := make(map[string]bool, len(d.Roles))
+	for _, role := range d.Roles {
+		role.Code = strings.TrimSpace(role.Code)
+		role.Name = strings.TrimSpace(role.Name)
+		role.Parent = strings.TrimSpace(role.Parent)
+		if role.Code == "" {
+			return errors.New("角色标识不能为空")
+		}
+		if role.Name == "" {
+			return fmt.Errorf("角色[%s]名称不能为空", role.Code)
+		}
+		if roleCodes[role.Code] {
+			return fmt.Errorf("角色标识重复: %s", role.Code)
+		}
+		if err := (&Role{}).SetDataScope(role.DataScope, role.dataDeptIDs()); err != nil {
+			return fmt.Errorf("角色[%s]%s", role.Code, err.Error())
+		}
+		roleCodes[role.Code] = true
+	}
+
+	permKeys := make(map[string]bool)
+	err := WalkPermissions(d.Permissions, nil, func(spec, _ *PermissionSpec) error {
+		if err := spec.Normalize(); err != nil {
+			return err
+		}
+		if err := spec.ToPermission().Validate(); err != nil {
+			return fmt.Errorf("权限[%s]: %w", spec.Key, err)
+		}
+		if permKeys[spec.Key] {
+			return fmt.Errorf("权限标识重复: %s", spec.Key)
+		}
+		permKeys[spec.Key] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bindingRoles := make(map[string]bool, len(d.RolePermissions))
+	for _, binding := range d.RolePermissions {
+		if bindingRoles[binding.Role] {
+			return fmt.Errorf("角色[%s]的权限绑定重复", binding.Role)
+		}
+		bindingRoles[binding.Role] = true
+		binding.Permissions = uniqueStrings(binding.Permissions)
+	}
+
+	usernames := make(map[string]bool, len(d.UserRoles))
+	for _, binding := range d.UserRoles {
+		if binding.Username == "" {
+			return errors.New("用户角色绑定的用户名不能为空")
+		}
+		if usernames[binding.Username] {
+			return fmt.Errorf("用户[%s]的角色绑定重复", binding.Username)
+		}
+		usernames[binding.Username] = true
+		binding.Roles = uniqueStrings(binding.Roles)
+	}
+
+	return nil
+}
+
+// uniqueStrings 去除重复及空白元素，保持原有顺序
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/pkg/types"
+	"strings"
+)
+
+// RouteApiPrefix 参与权限同步的路由前缀
+const RouteApiPrefix = "/api/"
+
+// RouteInfo 已注册的HTTP路由
+type RouteInfo struct {
+	Method  string     `json:"method"`
+	Path    string     `json:"path"`
+	Module  string     `json:"module"`
+	Handler string     `json:"handler"`
+	PermID  types.Long `json:"permission_id"`
+}
+
+// NewRouteInfo 根据路由信息构建，module取/api/{version}/{module}中的模块段
+func NewRouteInfo(method, path, handler string) *RouteInfo {
+	return &RouteInfo{
+		Method:  strings.ToUpper(method),
+		Path:    path,
+		Module:  RouteModule(path),
+		Handler: shortHandlerName(handler),
+	}
+}
+
+// Key 路由标识，格式为"METHOD path"
+func (r *RouteInfo) Key() string {
+	return r.Method + " " + r.Path
+}
+
+// ToPermission 转换为API权限实体
+func (r *RouteInfo) ToPermission(parentID types.Long) *Permission {
+	name := r.Handler
+	if name == "" {
+		name = r.Key()
+	}
+	return &Permission{
+		ParentID: parentID,
+		Name:     name,
+		Type:     PermTypeApi,
+		Path:     r.Path,
+		Method:   r.Method,
+		Status:   enum.StatusEnabled,
+	}
+}
+
+// RouteModule 获取路由所属模块，如 /api/v1/apps/:id -> apps
+func RouteModule(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 3 && segments[0] == "api" {
+		return segments[2]
+	}
+	if len(segments) > 0 {
+		return segments[len(segments)-1]
+	}
+	return ""
+}
+
+// ModulePermission 模块分组权限，作为该模块下API权限的父节点
+func ModulePermission(module string) *Permission {
+	return &Permission{
+		Name:       module + "接口",
+		Type:       PermTypeApi,
+		Path:       "/api/v1/" + module,
+		Permission: ModulePermissionKey(module),
+		Status:     enum.StatusEnabled,
+		Hidden:     true,
+	}
+}
+
+// ModulePermissionKey 模块分组权限的标识
+func ModulePermissionKey(module string) string {
+	return "api:" + module
+}
+
+// RouteKey API权限对应的路由标识，与RouteInfo.Key保持一致
+func (p *Permission) RouteKey() string {
+	return strings.ToUpper(p.Method) + " " + p.Path
+}
+
+// IsModuleGroup 是否为模块分组权限
+func (p *Permission) IsModuleGroup() bool {
+	return p.Type == PermTypeApi && p.Method == "" && strings.HasPrefix(p.Permission, "api:")
+}
+
+// shortHandlerName 简化处理函数名，如 .../controller.(*RoleController).CreateRole-fm -> RoleController.CreateRole
+func shortHandlerName(handler string) string {
+	if index := strings.LastIndex(handler, "/"); index >= 0 {
+		handler = handler[index+1:]
+	}
+	handler = strings.TrimSuffix(handler, "-fm")
+	if index := strings.Index(handler, "."); index >= 0 {
+		handler = handler[index+1:]
+	}
+	handler = strings.NewReplacer("(*", "", ")", "").Replace(handler)
+	return handler
+}
+
+// RouteSyncResultVO 路由同步结果
+type RouteSyncResultVO struct {
+	TotalRoutes int             `json:"total_routes"`
+	Created     []*PermissionVO `json:"created"`
+	Orphaned    []*PermissionVO `json:"orphaned"`
+}
+
+// RouteReportVO 路由权限报告
+type RouteReportVO struct {
+	TotalRoutes         int             `json:"total_routes"`
+	UnreachableRoutes   []*RouteInfo    `json:"unreachable_routes"`
+	OrphanedPermissions []*PermissionVO `json:"orphaned_permissions"`
+}
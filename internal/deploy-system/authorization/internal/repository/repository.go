@@ -95,6 +95,16 @@ func (r *Repository) ListRoles(ctx context.Context, query *domain.RoleQuery) ([]
 	return roles, total, nil
 }
 
+// GetAllRoles 获取所有角色
+func (r *Repository) GetAllRoles(ctx context.Context) ([]*domain.Role, error) {
+	var roles []*domain.Role
+	err := r.DB(ctx).Order("sort_order ASC, id ASC").Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
 // SaveRole 保存角色
 func (r *Repository) SaveRole(ctx context.Context, role *domain.Role) error {
 	return r.DB(ctx).Save(role).Error
@@ -104,11 +114,28 @@ func (r *Repository) SaveRole(ctx context.Context, role *domain.Role) error {
 func (r *Repository) DeleteRole(ctx context.Context, id types.Long) error {
 	// 开启事务
 	return r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		// 获取角色信息
+		var role domain.Role
+		if err := tx.First(&role, id).Error; err != nil {
+			return err
+		}
+
+		// 获取继承该角色的子角色
+		var children []*domain.Role
+		if err := tx.Where("parent_id = ?", id).Find(&children).Error; err != nil {
+			return err
+		}
+
 		// 删除角色
 		if err := tx.Delete(&domain.Role{}, id).Error; err != nil {
 			return err
 		}
 
+		// 子角色不再继承该角色
+		if err := tx.Model(&domain.Role{}).Where("parent_id = ?", id).Update("parent_id", 0).Error; err != nil {
+			return err
+		}
+
 		// 删除角色权限关联
 		if err := tx.Where("role_id = ?", id).Delete(&domain.RolePermission{}).Error; err != nil {
 			return err
@@ -119,10 +146,51 @@ func (r *Repository) DeleteRole(ctx context.Context, id types.Long) error {
 			return err
 		}
 
-		return nil
+		// 清除Casbin中的角色策略及继承关系
+		roleKey := fmt.Sprintf("%s%s", domain.CasbinRolePrefix, role.Code)
+		if _, err := casbin.RemoveFilteredPolicy(0, roleKey); err != nil {
+			return err
+		}
+		if _, err := casbin.DeleteRolesForUser(roleKey); err != nil {
+			return err
+		}
+		for _, child := range children {
+			childKey := fmt.Sprintf("%s%s", domain.CasbinRolePrefix, child.Code)
+			if _, err := casbin.DeleteRoleForUser(childKey, roleKey); err != nil {
+				return err
+			}
+		}
+
+		// 保存策略
+		return casbin.SavePolicy()
 	})
 }
 
+// UpdateRoleInheritance 更新角色继承关系（Casbin g 策略）
+// oldParent/newParent 为空表示没有父角色
+func (r *Repository) UpdateRoleInheritance(ctx context.Context, role *domain.Role, oldParent, newParent *domain.Role) error {
+	roleKey := fmt.Sprintf("%s%s", domain.CasbinRolePrefix, role.Code)
+
+	// 移除旧的继承关系
+	if oldParent != nil {
+		oldParentKey := fmt.Sprintf("%s%s", domain.CasbinRolePrefix, oldParent.Code)
+		if _, err := casbin.DeleteRoleForUser(roleKey, oldParentKey); err != nil {
+			return err
+		}
+	}
+
+	// 添加新的继承关系
+	if newParent != nil {
+		newParentKey := fmt.Sprintf("%s%s", domain.CasbinRolePrefix, newParent.Code)
+		if _, err := casbin.AddRoleForUser(roleKey, newParentKey); err != nil {
+			return err
+		}
+	}
+
+	// 保存策略
+	return casbin.SavePolicy()
+}
+
 // ============= 权限相关 =============
 
 // GetPermissionByID 根据ID获取权限
@@ -216,6 +284,28 @@ func (r *Repository) DeletePermission(ctx context.Context, id types.Long) error
 	})
 }
 
+// MarkPermissionsOrphaned 标记API权限对应的路由是否已不存在
+func (r *Repository) MarkPermissionsOrphaned(ctx context.Context, ids []types.Long, orphaned bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DB(ctx).Model(&domain.Permission{}).Where("id IN ?", ids).Update("orphaned", orphaned).Error
+}
+
+// GetBoundPermissionIDs 获取已绑定到任一角色的权限ID
+func (r *Repository) GetBoundPermissionIDs(ctx context.Context) (map[types.Long]bool, error) {
+	var ids []types.Long
+	err := r.DB(ctx).Model(&domain.RolePermission{}).Distinct().Pluck("permission_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[types.Long]bool, len(ids))
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
 // GetAllPermissions 获取所有权限
 func (r *Repository) GetAllPermissions(ctx context.Context) ([]*domain.Permission, error) {
 	var permissions []*domain.Permission
@@ -226,12 +316,14 @@ func (r *Repository) GetAllPermissions(ctx context.Context) ([]*domain.Permissio
 	return permissions, nil
 }
 
-// GetUserRoles 获取用户的角色列表
+// GetUserRoles 获取用户的角色列表（含未过期的临时授权）
 func (r *Repository) GetUserRoles(ctx context.Context, userID types.Long) ([]*domain.Role, error) {
 	var roles []*domain.Role
 	err := r.DB(ctx).Table("role").
+		Select("DISTINCT role.*").
 		Joins("JOIN user_role ON role.id = user_role.role_id").
 		Where("user_role.user_id = ?", userID).
+		Where("user_role.expires_at IS NULL OR user_role.expires_at > ?", time.Now()).
 		Find(&roles).Error
 	if err != nil {
 		return nil, err
@@ -239,11 +331,11 @@ func (r *Repository) GetUserRoles(ctx context.Context, userID types.Long) ([]*do
 	return roles, nil
 }
 
-// AssignRolesToUser 为用户分配角色
+// AssignRolesToUser 为用户分配角色（永久授权，不影响未过期的临时授权）
 func (r *Repository) AssignRolesToUser(ctx context.Context, userID types.Long, roleIDs []types.Long) error {
 	return r.DB(ctx).Transaction(func(tx *gorm.DB) error {
-		// 先清除用户的所有角色
-		if err := tx.Where("user_id = ?", userID).Delete(&domain.UserRole{}).Error; err != nil {
+		// 先清除用户的所有永久角色
+		if err := tx.Where("user_id = ? AND expires_at IS NULL", userID).Delete(&domain.UserRole{}).Error; err != nil {
 			return err
 		}
 
@@ -264,9 +356,11 @@ func (r *Repository) AssignRolesToUser(ctx context.Context, userID types.Long, r
 		// 更新Casbin关系
 		userKey := fmt.Sprintf("%s%d", domain.CasbinUserPrefix, userID)
 
-		// 获取所有角色
+		// 获取所有角色，包括未过期的临时授权角色
 		var roles []*domain.Role
-		if err := tx.Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+		activeGrants := tx.Model(&domain.UserRole{}).Select("role_id").
+			Where("user_id = ? AND expires_at > ?", userID, time.Now())
+		if err := tx.Where("id IN ? OR id IN (?)", roleIDs, activeGrants).Find(&roles).Error; err != nil {
 			return err
 		}
 
@@ -323,8 +417,232 @@ func (r *Repository) RemoveRoleFromUser(ctx context.Context, userID types.Long,
 	})
 }
 
+// GetAllUserRoles 获取所有永久的用户角色关联（含用户名）
+func (r *Repository) GetAllUserRoles(ctx context.Context) ([]*domain.UserRoleRecord, error) {
+	var records []*domain.UserRoleRecord
+	err := r.DB(ctx).Table("user_role").
+		Select("user_role.user_id, `user`.username, user_role.role_id").
+		Joins("JOIN `user` ON `user`.id = user_role.user_id").
+		Where("user_role.expires_at IS NULL").
+		Order("user_role.user_id ASC, user_role.role_id ASC").
+		Scan(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetUserIDsByUsernames 根据用户名批量获取用户ID
+func (r *Repository) GetUserIDsByUsernames(ctx context.Context, usernames []string) (map[string]types.Long, error) {
+	result := make(map[string]types.Long, len(usernames))
+	if len(usernames) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		ID       int64
+		Username string
+	}
+	err := r.DB(ctx).Table("user").
+		Select("id, username").
+		Where("username IN ?", usernames).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.Username] = types.Long(row.ID)
+	}
+	return result, nil
+}
+
+// ============= 临时授权 =============
+
+// GrantTemporaryRole 临时授予用户角色，已存在的临时授权将被新的过期时间覆盖
+func (r *Repository) GrantTemporaryRole(ctx context.Context, userRole *domain.UserRole, role *domain.Role) error {
+	return r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND role_id = ? AND expires_at IS NOT NULL", userRole.UserID, userRole.RoleID).
+			Delete(&domain.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(userRole).Error; err != nil {
+			return err
+		}
+
+		userKey := fmt.Sprintf("%s%d", domain.CasbinUserPrefix, userRole.UserID)
+		roleKey := fmt.Sprintf("%s%s", domain.CasbinRolePrefix, role.Code)
+		if _, err := casbin.AddRoleForUser(userKey, roleKey); err != nil {
+			return err
+		}
+
+		// 保存策略
+		return casbin.SavePolicy()
+	})
+}
+
+// GetTemporaryUserRoles 获取临时授权，userID为0时查询所有用户
+func (r *Repository) GetTemporaryUserRoles(ctx context.Context, userID types.Long) ([]*domain.UserRole, error) {
+	var userRoles []*domain.UserRole
+	db := r.DB(ctx).Where("expires_at IS NOT NULL")
+	if userID > 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+	err := db.Order("expires_at ASC").Find(&userRoles).Error
+	if err != nil {
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+// GetPermanentUserRoles 获取用户的永久角色关联
+func (r *Repository) GetPermanentUserRoles(ctx context.Context, userID types.Long) ([]*domain.UserRole, error) {
+	var userRoles []*domain.UserRole
+	err := r.DB(ctx).Where("user_id = ? AND expires_at IS NULL", userID).Find(&userRoles).Error
+	if err != nil {
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+// GetExpiredUserRoles 获取已过期的临时授权
+func (r *Repository) GetExpiredUserRoles(ctx context.Context, now time.Time) ([]*domain.UserRole, error) {
+	var userRoles []*domain.UserRole
+	err := r.DB(ctx).Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Order("expires_at ASC").
+		Find(&userRoles).Error
+	if err != nil {
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+// RemoveTemporaryGrants 删除用户在指定角色上的临时授权
+// 若用户仍通过其他授权持有该角色，则保留Casbin中的关系
+func (r *Repository) RemoveTemporaryGrants(ctx context.Context, grants []*domain.UserRole, role *domain.Role) error {
+	if len(grants) == 0 {
+		return nil
+	}
+	return r.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := make([]types.Long, 0, len(grants))
+		for _, grant := range grants {
+			ids = append(ids, grant.ID)
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&domain.UserRole{}).Error; err != nil {
+			return err
+		}
+
+		userID := grants[0].UserID
+		var remaining int64
+		err := tx.Model(&domain.UserRole{}).
+			Where("user_id = ? AND role_id = ?", userID, role.ID).
+			Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+			Count(&remaining).Error
+		if err != nil {
+			return err
+		}
+		if remaining > 0 {
+			return nil
+		}
+
+		userKey := fmt.Sprintf("%s%d", domain.CasbinUserPrefix, userID)
+		roleKey := fmt.Sprintf("%s%s", domain.CasbinRolePrefix, role.Code)
+		if _, err := casbin.DeleteRoleForUser(userKey, roleKey); err != nil {
+			return err
+		}
+
+		// 保存策略
+		return casbin.SavePolicy()
+	})
+}
+
+// GetGrantRequestByID 根据ID获取临时授权申请
+func (r *Repository) GetGrantRequestByID(ctx context.Context, id types.Long) (*domain.RoleGrantRequest, error) {
+	var request domain.RoleGrantRequest
+	err := r.DB(ctx).First(&request, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// SaveGrantRequest 保存临时授权申请
+func (r *Repository) SaveGrantRequest(ctx context.Context, request *domain.RoleGrantRequest) error {
+	return r.DB(ctx).Save(request).Error
+}
+
+// ListGrantRequests 查询临时授权申请列表
+func (r *Repository) ListGrantRequests(ctx context.Context, query *domain.GrantRequestQuery) ([]*domain.RoleGrantRequest, int64, error) {
+	db := r.DB(ctx).Model(&domain.RoleGrantRequest{})
+	if query.UserID > 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var requests []*domain.RoleGrantRequest
+	err := db.Order("id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&requests).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+// SaveGrantLog 保存角色授权日志
+func (r *Repository) SaveGrantLog(ctx context.Context, log *domain.RoleGrantLog) error {
+	return r.DB(ctx).Create(log).Error
+}
+
+// ListGrantLogs 查询角色授权日志
+func (r *Repository) ListGrantLogs(ctx context.Context, query *domain.GrantLogQuery) ([]*domain.RoleGrantLog, int64, error) {
+	db := r.DB(ctx).Model(&domain.RoleGrantLog{})
+	if query.UserID > 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.RoleID > 0 {
+		db = db.Where("role_id = ?", query.RoleID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*domain.RoleGrantLog
+	err := db.Order("id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
 // ============= 角色权限关联 =============
 
+// GetAllRolePermissions 获取所有角色权限关联
+func (r *Repository) GetAllRolePermissions(ctx context.Context) ([]*domain.RolePermission, error) {
+	var rolePermissions []*domain.RolePermission
+	err := r.DB(ctx).Order("role_id ASC, permission_id ASC").Find(&rolePermissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return rolePermissions, nil
+}
+
 // GetRolePermissions 获取角色的权限列表
 func (r *Repository) GetRolePermissions(ctx context.Context, roleID types.Long) ([]*domain.Permission, error) {
 	var permissions []*domain.Permission
@@ -338,6 +656,24 @@ func (r *Repository) GetRolePermissions(ctx context.Context, roleID types.Long)
 	return permissions, nil
 }
 
+// GetPermissionsByRoleIDs 获取多个角色的权限列表（去重）
+func (r *Repository) GetPermissionsByRoleIDs(ctx context.Context, roleIDs []types.Long) ([]*domain.Permission, error) {
+	var permissions []*domain.Permission
+	if len(roleIDs) == 0 {
+		return permissions, nil
+	}
+	err := r.DB(ctx).Table("permission").
+		Where("id IN (?)", r.DB(ctx).Table("role_permission").
+			Select("permission_id").
+			Where("role_id IN ?", roleIDs)).
+		Order("sort_order ASC, id ASC").
+		Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
 // AssignPermissionsToRole 为角色分配权限
 func (r *Repository) AssignPermissionsToRole(ctx context.Context, roleID types.Long, permissionIDs []types.Long) error {
 	return r.DB(ctx).Transaction(func(tx *gorm.DB) error {
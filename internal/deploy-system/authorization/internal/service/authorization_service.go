@@ -58,6 +58,7 @@ func (s *AuthorizationService) GetUserRoles(ctx context.Context, userID types.Lo
 }
 
 // GetUserPermissions 获取用户的权限列表
+// 权限包含用户角色及其所有祖先角色的权限
 func (s *AuthorizationService) GetUserPermissions(ctx context.Context, userID types.Long) ([]*domain.PermissionVO, error) {
 	// 获取用户的所有角色
 	roles, err := s.Repo.GetUserRoles(ctx, userID)
@@ -66,24 +67,29 @@ func (s *AuthorizationService) GetUserPermissions(ctx context.Context, userID ty
 		return nil, common.InternalError("获取用户角色失败", err)
 	}
 
-	// 获取每个角色的权限
-	permissionMap := make(map[types.Long]*domain.Permission)
+	// 展开角色继承关系
+	allRoles, err := s.Repo.GetAllRoles(ctx)
+	if err != nil {
+		s.Logger.WithError(err).Error("获取角色列表失败")
+		return nil, common.InternalError("获取角色列表失败", err)
+	}
+	hierarchy := domain.NewRoleHierarchy(allRoles)
+
+	roleIDs := make([]types.Long, 0, len(roles))
 	for _, role := range roles {
-		permissions, err := s.Repo.GetRolePermissions(ctx, role.ID)
-		if err != nil {
-			s.Logger.WithError(err).Error("获取角色权限失败")
-			return nil, common.InternalError("获取角色权限失败", err)
-		}
+		roleIDs = append(roleIDs, role.ID)
+	}
 
-		// 去重
-		for _, perm := range permissions {
-			permissionMap[perm.ID] = perm
-		}
+	// 获取所有有效角色的权限（已去重）
+	permissions, err := s.Repo.GetPermissionsByRoleIDs(ctx, hierarchy.Expand(roleIDs...))
+	if err != nil {
+		s.Logger.WithError(err).Error("获取角色权限失败")
+		return nil, common.InternalError("获取角色权限失败", err)
 	}
 
 	// 转换为VO
-	permissionVOs := make([]*domain.PermissionVO, 0, len(permissionMap))
-	for _, perm := range permissionMap {
+	permissionVOs := make([]*domain.PermissionVO, 0, len(permissions))
+	for _, perm := range permissions {
 		permissionVOs = append(permissionVOs, perm.ToVO())
 	}
 
@@ -104,12 +110,39 @@ func (s *AuthorizationService) AssignRolesToUser(ctx context.Context, userID typ
 		err = s.FinishTransaction(ctx, err, "assign roles to user")
 	}()
 
+	before, err := s.Repo.GetPermanentUserRoles(ctx, userID)
+	if err != nil {
+		return common.InternalError("查询用户角色失败", err)
+	}
+
 	err = s.Repo.AssignRolesToUser(ctx, userID, roleIDs)
 	if err != nil {
 		s.Logger.WithError(err).Error("分配角色失败")
 		return common.InternalError("分配角色失败", err)
 	}
 
+	// 记录授予及撤销的角色
+	current := make(map[types.Long]bool, len(before))
+	for _, userRole := range before {
+		current[userRole.RoleID] = true
+	}
+	desired := make(map[types.Long]bool, len(roleIDs))
+	for _, roleID := range roleIDs {
+		desired[roleID] = true
+		if !current[roleID] {
+			if err = s.recordGrantLog(ctx, userID, roleID, domain.GrantActionGrant); err != nil {
+				return err
+			}
+		}
+	}
+	for roleID := range current {
+		if !desired[roleID] {
+			if err = s.recordGrantLog(ctx, userID, roleID, domain.GrantActionRevoke); err != nil {
+				return err
+			}
+		}
+	}
+
 	return
 }
 
@@ -130,10 +163,28 @@ func (s *AuthorizationService) RemoveRoleFromUser(ctx context.Context, userID ty
 		return common.InternalError("移除角色失败", err)
 	}
 
-	return
+	return s.recordGrantLog(ctx, userID, roleID, domain.GrantActionRevoke)
+}
+
+// recordGrantLog 记录永久角色的授予或撤销
+func (s *AuthorizationService) recordGrantLog(ctx context.Context, userID, roleID types.Long, action string) error {
+	log := &domain.RoleGrantLog{
+		UserID: userID,
+		RoleID: roleID,
+		Action: action,
+	}
+	role, err := s.Repo.GetRoleByID(ctx, roleID)
+	if err != nil {
+		return common.InternalError("查询角色失败", err)
+	}
+	if role != nil {
+		log.RoleCode = role.Code
+	}
+	return recordGrantLog(ctx, s.Repo, log)
 }
 
 // GetUserMenus 获取用户菜单
+// 菜单来自用户的有效权限，包含继承自父角色的菜单
 func (s *AuthorizationService) GetUserMenus(ctx context.Context, userID types.Long) ([]*domain.MenuVO, error) {
 	// 获取用户的权限列表
 	permissionVOs, err := s.GetUserPermissions(ctx, userID)
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/authorization/internal/domain"
+	"devops-platform/internal/deploy-system/authorization/internal/repository"
+	"devops-platform/internal/deploy-system/organization"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/datascope"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+)
+
+// DataScopeService 根据用户角色解析数据权限范围
+type DataScopeService struct {
+	Repo              *repository.Repository         `inject:"AuthorizationRepository"`
+	DepartmentService organization.DepartmentService `inject:"DepartmentService"`
+}
+
+func NewDataScopeService() *DataScopeService {
+	return &DataScopeService{}
+}
+
+// ResolveDataScope 合并用户所有启用角色及其祖先角色的数据权限，取并集
+// 任一角色为全部数据时不做限制；没有可用角色时仅能访问本人数据
+func (s *DataScopeService) ResolveDataScope(ctx context.Context, user *security.UserContext) (*security.DataScope, error) {
+	roles, err := s.Repo.GetUserRoles(ctx, user.UserID)
+	if err != nil {
+		return nil, common.InternalError("查询用户角色失败", err)
+	}
+	roleIDs := make([]types.Long, 0, len(roles))
+	for _, role := range roles {
+		if role.Status == enum.StatusEnabled {
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+
+	// 展开角色继承关系，父角色的数据权限同样生效
+	allRoles, err := s.Repo.GetAllRoles(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询角色列表失败", err)
+	}
+	hierarchy := domain.NewRoleHierarchy(allRoles)
+
+	scope := &security.DataScope{UserID: user.UserID}
+	deptIDs := make([]types.Long, 0)
+	rootIDs := make([]types.Long, 0)
+	enabled := 0
+	for _, roleID := range hierarchy.Expand(roleIDs...) {
+		role, ok := hierarchy[roleID]
+		if !ok || role.Status != enum.StatusEnabled {
+			continue
+		}
+		enabled++
+
+		switch role.DataScope {
+		case "", datascope.ScopeAll:
+			scope.All = true
+			return scope, nil
+		case datascope.ScopeDept:
+			if user.DeptID > 0 {
+				deptIDs = append(deptIDs, user.DeptID)
+			}
+		case datascope.ScopeDeptAndChildren:
+			if user.DeptID > 0 {
+				rootIDs = append(rootIDs, user.DeptID)
+			}
+		case datascope.ScopeOwnApps:
+			scope.OwnApps = true
+		case datascope.ScopeCustom:
+			deptIDs = append(deptIDs, role.DataScopeDeptIDs()...)
+		}
+	}
+	if enabled == 0 {
+		scope.OwnApps = true
+		return scope, nil
+	}
+
+	if len(rootIDs) > 0 {
+		children, err := s.DepartmentService.GetSubDepartmentIDs(ctx, rootIDs...)
+		if err != nil {
+			return nil, err
+		}
+		deptIDs = append(deptIDs, children...)
+	}
+
+	seen := make(map[types.Long]bool, len(deptIDs))
+	for _, id := range deptIDs {
+		if !seen[id] {
+			seen[id] = true
+			scope.DeptIDs = append(scope.DeptIDs, id)
+		}
+	}
+	return scope, nil
+}
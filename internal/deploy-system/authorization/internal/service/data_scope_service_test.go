@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/authorization/internal/domain"
+	"devops-platform/internal/deploy-system/authorization/internal/repository"
+	"devops-platform/internal/pkg/datascope"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/module"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// newDataScopeTest 使用内存SQLite创建数据权限服务，写入给定角色并将userRoles分配给用户1
+func newDataScopeTest(t *testing.T, roles []*domain.Role, userRoles ...types.Long) *DataScopeService {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err = db.AutoMigrate(&domain.Role{}, &domain.UserRole{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Create(&roles).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, roleID := range userRoles {
+		if err = db.Create(&domain.UserRole{UserID: 1, RoleID: roleID}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := repository.NewRepository()
+	repo.Inject(func(string) interface{} { return db })
+	service := NewDataScopeService()
+	service.Repo = repo
+	return service
+}
+
+func TestResolveDataScopeInheritsParentRole(t *testing.T) {
+	service := newDataScopeTest(t, []*domain.Role{
+		{Module: module.Module{ID: 1}, Name: "研发", Code: "dev", Status: enum.StatusEnabled,
+			DataScope: datascope.ScopeCustom, DataDepts: "5,6"},
+		{Module: module.Module{ID: 2}, ParentID: 1, Name: "后端", Code: "backend", Status: enum.StatusEnabled,
+			DataScope: datascope.ScopeOwnApps},
+	}, 2)
+
+	scope, err := service.ResolveDataScope(context.Background(), &security.UserContext{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scope.All || !scope.OwnApps {
+		t.Errorf("expected own apps without full access, got all=%v own_apps=%v", scope.All, scope.OwnApps)
+	}
+	if want := []types.Long{5, 6}; !reflect.DeepEqual(scope.DeptIDs, want) {
+		t.Errorf("expected departments %v inherited from parent role, got %v", want, scope.DeptIDs)
+	}
+}
+
+func TestResolveDataScopeSkipsDisabledParentRole(t *testing.T) {
+	service := newDataScopeTest(t, []*domain.Role{
+		{Module: module.Module{ID: 1}, Name: "管理员", Code: "admin", Status: enum.StatusDisabled,
+			DataScope: datascope.ScopeAll},
+		{Module: module.Module{ID: 2}, ParentID: 1, Name: "后端", Code: "backend", Status: enum.StatusEnabled,
+			DataScope: datascope.ScopeOwnApps},
+	}, 2)
+
+	scope, err := service.ResolveDataScope(context.Background(), &security.UserContext{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scope.All || !scope.OwnApps {
+		t.Errorf("expected disabled parent role to be ignored, got all=%v own_apps=%v", scope.All, scope.OwnApps)
+	}
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/authorization/internal/domain"
+	"devops-platform/internal/deploy-system/authorization/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GrantService 临时角色授权服务实现
+type GrantService struct {
+	service.Service
+	Repo   *repository.Repository `inject:"AuthorizationRepository"`
+	Logger *logrus.Logger         `inject:"Logger"`
+}
+
+func NewGrantService() *GrantService {
+	return &GrantService{}
+}
+
+// GrantRole 临时授予用户角色
+func (s *GrantService) GrantRole(ctx context.Context, command *domain.GrantRoleCommand) (err error) {
+	duration, err := command.Validate()
+	if err != nil {
+		return common.RequestParamError("", err)
+	}
+
+	role, err := s.getRole(ctx, command.RoleID)
+	if err != nil {
+		return err
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "grant role")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "grant role")
+	}()
+
+	expiresAt := time.Now().Add(duration)
+	return s.grant(ctx, command.UserID, role, expiresAt, command.Reason, 0)
+}
+
+// RevokeGrant 撤销用户在指定角色上的临时授权
+func (s *GrantService) RevokeGrant(ctx context.Context, command *domain.RevokeGrantCommand) (err error) {
+	role, err := s.getRole(ctx, command.RoleID)
+	if err != nil {
+		return err
+	}
+
+	grants, err := s.Repo.GetTemporaryUserRoles(ctx, command.UserID)
+	if err != nil {
+		return common.InternalError("查询临时授权失败", err)
+	}
+	matched := make([]*domain.UserRole, 0, len(grants))
+	for _, grant := range grants {
+		if grant.RoleID == command.RoleID {
+			matched = append(matched, grant)
+		}
+	}
+	if len(matched) == 0 {
+		return common.NotFoundError("临时授权不存在", nil)
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "revoke grant")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "revoke grant")
+	}()
+
+	if err = s.Repo.RemoveTemporaryGrants(ctx, matched, role); err != nil {
+		return common.InternalError("撤销临时授权失败", err)
+	}
+
+	return recordGrantLog(ctx, s.Repo, &domain.RoleGrantLog{
+		UserID:   command.UserID,
+		RoleID:   role.ID,
+		RoleCode: role.Code,
+		Action:   domain.GrantActionRevoke,
+		Reason:   command.Reason,
+	})
+}
+
+// ListActiveGrants 获取临时授权列表，userID为0时查询所有用户
+func (s *GrantService) ListActiveGrants(ctx context.Context, userID types.Long) ([]*domain.UserRole, error) {
+	grants, err := s.Repo.GetTemporaryUserRoles(ctx, userID)
+	if err != nil {
+		return nil, common.InternalError("查询临时授权失败", err)
+	}
+	return grants, nil
+}
+
+// CreateGrantRequest 当前用户申请临时角色授权
+func (s *GrantService) CreateGrantRequest(ctx context.Context, command *domain.CreateGrantRequestCommand) (id types.Long, err error) {
+	user := security.GetUserContext(ctx)
+	if user == nil {
+		return 0, common.UnauthorizedError("", nil)
+	}
+
+	request, err := command.ToRequest(user.UserID)
+	if err != nil {
+		return 0, common.RequestParamError("", err)
+	}
+	if _, err = s.getRole(ctx, command.RoleID); err != nil {
+		return 0, err
+	}
+
+	request.AuditCreated(ctx)
+	if err = s.Repo.SaveGrantRequest(ctx, request); err != nil {
+		return 0, common.InternalError("创建授权申请失败", err)
+	}
+
+	s.Logger.WithField("request_id", request.ID).
+		WithField("user_id", request.UserID).
+		WithField("role_id", request.RoleID).
+		Info("提交临时角色授权申请")
+	return request.ID, nil
+}
+
+// ApproveGrantRequest 批准临时授权申请，授权时长从批准时开始计算
+func (s *GrantService) ApproveGrantRequest(ctx context.Context, command *domain.ReviewGrantRequestCommand) (err error) {
+	request, approver, err := s.getPendingRequest(ctx, command.ID)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.getRole(ctx, request.RoleID)
+	if err != nil {
+		return err
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "approve grant request")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "approve grant request")
+	}()
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(request.DurationMinutes) * time.Minute)
+	request.Status = domain.GrantRequestStatusApproved
+	request.ApproverID = approver.UserID
+	request.ApproverName = approver.RealName
+	request.ApprovedAt = &now
+	request.Comment = command.Comment
+	request.ExpiresAt = &expiresAt
+	request.AuditModified(ctx)
+	if err = s.Repo.SaveGrantRequest(ctx, request); err != nil {
+		return common.InternalError("更新授权申请失败", err)
+	}
+
+	return s.grant(ctx, request.UserID, role, expiresAt, request.Reason, request.ID)
+}
+
+// RejectGrantRequest 拒绝临时授权申请
+func (s *GrantService) RejectGrantRequest(ctx context.Context, command *domain.ReviewGrantRequestCommand) error {
+	request, approver, err := s.getPendingRequest(ctx, command.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	request.Status = domain.GrantRequestStatusRejected
+	request.ApproverID = approver.UserID
+	request.ApproverName = approver.RealName
+	request.ApprovedAt = &now
+	request.Comment = command.Comment
+	request.AuditModified(ctx)
+	if err = s.Repo.SaveGrantRequest(ctx, request); err != nil {
+		return common.InternalError("更新授权申请失败", err)
+	}
+
+	s.Logger.WithField("request_id", request.ID).
+		WithField("approver_id", approver.UserID).
+		Info("拒绝临时角色授权申请")
+	return nil
+}
+
+// ListGrantRequests 查询临时授权申请
+func (s *GrantService) ListGrantRequests(ctx context.Context, query *domain.GrantRequestQuery) ([]*domain.RoleGrantRequest, int64, error) {
+	normalizePage(&query.Page, &query.Size)
+	requests, total, err := s.Repo.ListGrantRequests(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询授权申请失败", err)
+	}
+	return requests, total, nil
+}
+
+// ListGrantLogs 查询角色授权日志
+func (s *GrantService) ListGrantLogs(ctx context.Context, query *domain.GrantLogQuery) ([]*domain.RoleGrantLog, int64, error) {
+	normalizePage(&query.Page, &query.Size)
+	logs, total, err := s.Repo.ListGrantLogs(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询授权日志失败", err)
+	}
+	return logs, total, nil
+}
+
+// ReapGrants 回收已过期的临时授权并记录结果，由定时任务调用
+func (s *GrantService) ReapGrants(ctx context.Context) {
+	count, err := s.ReapExpiredGrants(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("回收过期授权失败")
+		return
+	}
+	if count > 0 {
+		logrus.WithField("count", count).Info("已回收过期的临时授权")
+	}
+}
+
+// ReapExpiredGrants 回收已过期的临时授权，返回回收数量
+func (s *GrantService) ReapExpiredGrants(ctx context.Context) (count int, err error) {
+	expired, err := s.Repo.GetExpiredUserRoles(ctx, time.Now())
+	if err != nil {
+		return 0, common.InternalError("查询过期授权失败", err)
+	}
+
+	for _, grant := range expired {
+		if err = s.reap(ctx, grant); err != nil {
+			s.Logger.WithError(err).
+				WithField("user_id", grant.UserID).
+				WithField("role_id", grant.RoleID).
+				Error("回收过期授权失败")
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// reap 回收单条过期授权
+func (s *GrantService) reap(ctx context.Context, grant *domain.UserRole) (err error) {
+	ctx, err = s.BeginTransaction(ctx, "reap expired grant")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "reap expired grant")
+	}()
+
+	// 角色已被删除时仅清理授权记录
+	role, err := s.Repo.GetRoleByID(ctx, grant.RoleID)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		role = &domain.Role{}
+		role.ID = grant.RoleID
+	}
+
+	if err = s.Repo.RemoveTemporaryGrants(ctx, []*domain.UserRole{grant}, role); err != nil {
+		return err
+	}
+
+	return recordGrantLog(ctx, s.Repo, &domain.RoleGrantLog{
+		UserID:    grant.UserID,
+		RoleID:    grant.RoleID,
+		RoleCode:  role.Code,
+		Action:    domain.GrantActionExpire,
+		Reason:    grant.Reason,
+		ExpiresAt: grant.ExpiresAt,
+	})
+}
+
+// grant 写入临时授权及授权日志
+func (s *GrantService) grant(ctx context.Context, userID types.Long, role *domain.Role, expiresAt time.Time, reason string, requestID types.Long) error {
+	userRole := &domain.UserRole{
+		UserID:    userID,
+		RoleID:    role.ID,
+		ExpiresAt: &expiresAt,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if err := s.Repo.GrantTemporaryRole(ctx, userRole, role); err != nil {
+		return common.InternalError("授予临时角色失败", err)
+	}
+
+	return recordGrantLog(ctx, s.Repo, &domain.RoleGrantLog{
+		UserID:    userID,
+		RoleID:    role.ID,
+		RoleCode:  role.Code,
+		Action:    domain.GrantActionGrant,
+		Reason:    reason,
+		ExpiresAt: &expiresAt,
+		RequestID: requestID,
+	})
+}
+
+// getRole 获取角色，不存在时返回参数错误
+func (s *GrantService) getRole(ctx context.Context, roleID types.Long) (*domain.Role, error) {
+	role, err := s.Repo.GetRoleByID(ctx, roleID)
+	if err != nil {
+		return nil, common.InternalError("查询角色失败", err)
+	}
+	if role == nil {
+		return nil, common.RequestParamError("", errors.New("角色不存在"))
+	}
+	return role, nil
+}
+
+// getPendingRequest 获取待审批的申请及当前审批人，申请人不能审批自己的申请
+func (s *GrantService) getPendingRequest(ctx context.Context, id types.Long) (*domain.RoleGrantRequest, *security.UserContext, error) {
+	approver := security.GetUserContext(ctx)
+	if approver == nil {
+		return nil, nil, common.UnauthorizedError("", nil)
+	}
+
+	request, err := s.Repo.GetGrantRequestByID(ctx, id)
+	if err != nil {
+		return nil, nil, common.InternalError("查询授权申请失败", err)
+	}
+	if request == nil {
+		return nil, nil, common.NotFoundError("授权申请不存在", nil)
+	}
+	if request.Status != domain.GrantRequestStatusPending {
+		return nil, nil, common.RequestParamError("", errors.New("授权申请已处理"))
+	}
+	if request.UserID == approver.UserID {
+		return nil, nil, common.ForbiddenError("不能审批自己的授权申请", nil)
+	}
+	return request, approver, nil
+}
+
+// recordGrantLog 记录角色授权日志
+func recordGrantLog(ctx context.Context, repo *repository.Repository, log *domain.RoleGrantLog) error {
+	log.OperatingRecord(ctx)
+	if err := repo.SaveGrantLog(ctx, log); err != nil {
+		return common.InternalError("记录授权日志失败", err)
+	}
+
+	logrus.WithField("action", log.Action).
+		WithField("user_id", log.UserID).
+		WithField("role", log.RoleCode).
+		WithField("operator", log.Operator.Name).
+		WithField("reason", log.Reason).
+		Info("角色授权变更")
+	return nil
+}
+
+// normalizePage 规范化分页参数
+func normalizePage(page, size *int) {
+	if *page <= 0 {
+		*page = 1
+	}
+	if *size <= 0 {
+		*size = 10
+	}
+}
@@ -0,0 +1,657 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/authorization/internal/domain"
+	"devops-platform/internal/deploy-system/authorization/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PolicyService 权限策略导入导出服务实现
+type PolicyService struct {
+	service.Service
+	Repo   *repository.Repository `inject:"AuthorizationRepository"`
+	Logger *logrus.Logger         `inject:"Logger"`
+}
+
+func NewPolicyService() *PolicyService {
+	return &PolicyService{}
+}
+
+// ExportPolicy 导出当前的角色、权限树、角色权限绑定及用户角色绑定
+func (s *PolicyService) ExportPolicy(ctx context.Context) (*domain.PolicyDocument, error) {
+	state, err := s.loadPolicyState(ctx)
+	if err != nil {
+		return nil, common.InternalError("加载权限策略失败", err)
+	}
+
+	doc := &domain.PolicyDocument{
+		Version:         domain.PolicyDocumentVersion,
+		Roles:           make([]*domain.RoleSpec, 0, len(state.roles)),
+		Permissions:     make([]*domain.PermissionSpec, 0),
+		RolePermissions: make([]*domain.RolePermissionBinding, 0),
+		UserRoles:       make([]*domain.UserRoleBinding, 0),
+	}
+
+	// 角色
+	for _, role := range state.roles {
+		doc.Roles = append(doc.Roles, role.ToSpec(state.roleParentCode(role)))
+	}
+
+	// 权限树，父权限不存在的权限作为根节点
+	specs := make(map[types.Long]*domain.PermissionSpec, len(state.permissions))
+	for _, perm := range state.permissions {
+		specs[perm.ID] = perm.ToSpec()
+	}
+	for _, perm := range state.permissions {
+		if parent, ok := specs[perm.ParentID]; ok && perm.ParentID > 0 {
+			parent.Children = append(parent.Children, specs[perm.ID])
+		} else {
+			doc.Permissions = append(doc.Permissions, specs[perm.ID])
+		}
+	}
+
+	// 角色权限绑定
+	for _, role := range state.roles {
+		keys := sortedKeys(state.rolePerms[role.Code])
+		if len(keys) == 0 {
+			continue
+		}
+		doc.RolePermissions = append(doc.RolePermissions, &domain.RolePermissionBinding{
+			Role:        role.Code,
+			Permissions: keys,
+		})
+	}
+
+	// 用户角色绑定
+	for _, username := range sortedKeys(state.userRoles) {
+		doc.UserRoles = append(doc.UserRoles, &domain.UserRoleBinding{
+			Username: username,
+			Roles:    sortedKeys(state.userRoles[username]),
+		})
+	}
+
+	return doc, nil
+}
+
+// ImportPolicy 导入权限策略文档
+// 导入是幂等的：以自然键匹配已有数据，仅对存在差异的对象进行变更；
+// dryRun为true时只返回变更计划；prune为true时删除文档中未声明的角色、权限及绑定
+func (s *PolicyService) ImportPolicy(ctx context.Context, doc *domain.PolicyDocument, dryRun, prune bool) (result *domain.PolicyImportResultVO, err error) {
+	if err = doc.Validate(); err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+
+	state, err := s.loadPolicyState(ctx)
+	if err != nil {
+		return nil, common.InternalError("加载权限策略失败", err)
+	}
+
+	// 加载文档中声明的用户
+	usernames := make([]string, 0, len(doc.UserRoles))
+	for _, binding := range doc.UserRoles {
+		usernames = append(usernames, binding.Username)
+	}
+	userIDs, err := s.Repo.GetUserIDsByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, common.InternalError("查询用户失败", err)
+	}
+	for username, userID := range userIDs {
+		state.userIDs[username] = userID
+	}
+
+	if err = state.validateReferences(doc, prune); err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+
+	if !dryRun {
+		ctx, err = s.BeginTransaction(ctx, "import policy")
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			err = s.FinishTransaction(ctx, err, "import policy")
+		}()
+	}
+
+	syncer := &policySyncer{repo: s.Repo, state: state, apply: !dryRun}
+	if err = syncer.sync(ctx, doc, prune); err != nil {
+		return nil, common.InternalError("导入权限策略失败", err)
+	}
+
+	if !dryRun {
+		s.Logger.WithField("changes", len(syncer.changes)).WithField("prune", prune).Info("权限策略导入完成")
+	}
+
+	return domain.NewPolicyImportResultVO(syncer.changes, dryRun, prune), nil
+}
+
+// loadPolicyState 加载当前的权限策略状态
+func (s *PolicyService) loadPolicyState(ctx context.Context) (*policyState, error) {
+	roles, err := s.Repo.GetAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := s.Repo.GetAllPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rolePermissions, err := s.Repo.GetAllRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userRoles, err := s.Repo.GetAllUserRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &policyState{
+		roles:       roles,
+		permissions: permissions,
+		rolesByCode: make(map[string]*domain.Role, len(roles)),
+		rolesByID:   make(map[types.Long]*domain.Role, len(roles)),
+		permsByKey:  make(map[string]*domain.Permission, len(permissions)),
+		permsByID:   make(map[types.Long]*domain.Permission, len(permissions)),
+		rolePerms:   make(map[string]map[string]bool),
+		userRoles:   make(map[string]map[string]bool),
+		userIDs:     make(map[string]types.Long),
+	}
+	for _, role := range roles {
+		state.rolesByCode[role.Code] = role
+		state.rolesByID[role.ID] = role
+	}
+	for _, perm := range permissions {
+		state.permsByKey[perm.Key()] = perm
+		state.permsByID[perm.ID] = perm
+	}
+	for _, rp := range rolePermissions {
+		role, ok := state.rolesByID[rp.RoleID]
+		perm, found := state.permsByID[rp.PermissionID]
+		if !ok || !found {
+			continue
+		}
+		state.addRolePermission(role.Code, perm.Key())
+	}
+	for _, ur := range userRoles {
+		role, ok := state.rolesByID[types.Long(ur.RoleID)]
+		if !ok {
+			continue
+		}
+		state.userIDs[ur.Username] = types.Long(ur.UserID)
+		if state.userRoles[ur.Username] == nil {
+			state.userRoles[ur.Username] = make(map[string]bool)
+		}
+		state.userRoles[ur.Username][role.Code] = true
+	}
+
+	return state, nil
+}
+
+// policyState 当前权限策略状态，以自然键索引
+type policyState struct {
+	roles       []*domain.Role
+	permissions []*domain.Permission
+	rolesByCode map[string]*domain.Role
+	rolesByID   map[types.Long]*domain.Role
+	permsByKey  map[string]*domain.Permission
+	permsByID   map[types.Long]*domain.Permission
+	rolePerms   map[string]map[string]bool // 角色编码 -> 权限key集合
+	userRoles   map[string]map[string]bool // 用户名 -> 角色编码集合
+	userIDs     map[string]types.Long      // 用户名 -> 用户ID
+}
+
+func (st *policyState) addRolePermission(roleCode, permKey string) {
+	if st.rolePerms[roleCode] == nil {
+		st.rolePerms[roleCode] = make(map[string]bool)
+	}
+	st.rolePerms[roleCode][permKey] = true
+}
+
+// roleParentCode 获取角色的父角色编码
+func (st *policyState) roleParentCode(role *domain.Role) string {
+	if parent, ok := st.rolesByID[role.ParentID]; ok && role.ParentID > 0 {
+		return parent.Code
+	}
+	return ""
+}
+
+// permParentKey 获取权限的父权限key
+func (st *policyState) permParentKey(perm *domain.Permission) string {
+	if parent, ok := st.permsByID[perm.ParentID]; ok && perm.ParentID > 0 {
+		return parent.Key()
+	}
+	return ""
+}
+
+// validateReferences 校验文档中引用的角色、权限、用户在导入后均存在，且角色继承无循环
+func (st *policyState) validateReferences(doc *domain.PolicyDocument, prune bool) error {
+	// 导入后的角色继承关系：角色编码 -> 父角色编码
+	parents := make(map[string]string)
+	if !prune {
+		for _, role := range st.roles {
+			parents[role.Code] = st.roleParentCode(role)
+		}
+	}
+	for _, role := range doc.Roles {
+		parents[role.Code] = role.Parent
+	}
+	for code, parent := range parents {
+		if _, ok := parents[parent]; parent != "" && !ok {
+			return fmt.Errorf("角色[%s]的父角色[%s]不存在", code, parent)
+		}
+		visited := map[string]bool{code: true}
+		for p := parent; p != ""; p = parents[p] {
+			if visited[p] {
+				return fmt.Errorf("角色[%s]的继承关系存在循环", code)
+			}
+			visited[p] = true
+		}
+	}
+
+	// 导入后的权限集合
+	permKeys := make(map[string]bool)
+	if !prune {
+		for key := range st.permsByKey {
+			permKeys[key] = true
+		}
+	}
+	_ = domain.WalkPermissions(doc.Permissions, nil, func(spec, _ *domain.PermissionSpec) error {
+		permKeys[spec.Key] = true
+		return nil
+	})
+
+	for _, binding := range doc.RolePermissions {
+		if _, ok := parents[binding.Role]; !ok {
+			return fmt.Errorf("权限绑定引用的角色[%s]不存在", binding.Role)
+		}
+		for _, key := range binding.Permissions {
+			if !permKeys[key] {
+				return fmt.Errorf("角色[%s]绑定的权限[%s]不存在", binding.Role, key)
+			}
+		}
+	}
+
+	for _, binding := range doc.UserRoles {
+		if _, ok := st.userIDs[binding.Username]; !ok {
+			return fmt.Errorf("用户[%s]不存在", binding.Username)
+		}
+		for _, code := range binding.Roles {
+			if _, ok := parents[code]; !ok {
+				return fmt.Errorf("用户[%s]绑定的角色[%s]不存在", binding.Username, code)
+			}
+		}
+	}
+
+	return nil
+}
+
+// policySyncer 将策略文档与当前状态对比，记录变更并在apply为true时执行
+type policySyncer struct {
+	repo    *repository.Repository
+	state   *policyState
+	apply   bool
+	changes []*domain.PolicyChange
+}
+
+func (sy *policySyncer) record(kind, action, key, detail string) {
+	sy.changes = append(sy.changes, &domain.PolicyChange{
+		Kind:   kind,
+		Action: action,
+		Key:    key,
+		Detail: detail,
+	})
+}
+
+// sync 按权限、角色、角色继承、角色权限、用户角色、清理的顺序同步
+func (sy *policySyncer) sync(ctx context.Context, doc *domain.PolicyDocument, prune bool) error {
+	if err := sy.syncPermissions(ctx, doc); err != nil {
+		return err
+	}
+	if err := sy.syncRoles(ctx, doc); err != nil {
+		return err
+	}
+	if err := sy.syncRolePermissions(ctx, doc, prune); err != nil {
+		return err
+	}
+	if err := sy.syncUserRoles(ctx, doc, prune); err != nil {
+		return err
+	}
+	if prune {
+		if err := sy.pruneRoles(ctx, doc); err != nil {
+			return err
+		}
+		if err := sy.prunePermissions(ctx, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncPermissions 先序遍历权限树，保证父权限先于子权限创建
+func (sy *policySyncer) syncPermissions(ctx context.Context, doc *domain.PolicyDocument) error {
+	return domain.WalkPermissions(doc.Permissions, nil, func(spec, parent *domain.PermissionSpec) error {
+		parentKey := ""
+		if parent != nil {
+			parentKey = parent.Key
+		}
+
+		perm, exists := sy.state.permsByKey[spec.Key]
+		if !exists {
+			sy.record(domain.PolicyKindPermission, domain.PolicyActionCreate, spec.Key, spec.Name)
+			perm = spec.ToPermission()
+			sy.state.permsByKey[spec.Key] = perm
+			if sy.apply {
+				perm.ParentID = sy.permID(parentKey)
+				if err := sy.repo.SavePermission(ctx, perm); err != nil {
+					return err
+				}
+				sy.state.permsByID[perm.ID] = perm
+			}
+			return nil
+		}
+
+		fields := spec.DiffFields(perm)
+		if sy.state.permParentKey(perm) != parentKey {
+			fields = append(fields, "parent")
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+
+		sy.record(domain.PolicyKindPermission, domain.PolicyActionUpdate, spec.Key, strings.Join(fields, ","))
+		if sy.apply {
+			spec.ApplyTo(perm)
+			perm.ParentID = sy.permID(parentKey)
+			return sy.repo.SavePermission(ctx, perm)
+		}
+		return nil
+	})
+}
+
+// syncRoles 同步角色属性及继承关系，继承关系在所有角色创建后处理
+func (sy *policySyncer) syncRoles(ctx context.Context, doc *domain.PolicyDocument) error {
+	for _, spec := range doc.Roles {
+		role, exists := sy.state.rolesByCode[spec.Code]
+		if !exists {
+			sy.record(domain.PolicyKindRole, domain.PolicyActionCreate, spec.Code, spec.Name)
+			role = &domain.Role{}
+			spec.ApplyTo(role)
+			sy.state.rolesByCode[spec.Code] = role
+			if sy.apply {
+				if err := sy.repo.SaveRole(ctx, role); err != nil {
+					return err
+				}
+				sy.state.rolesByID[role.ID] = role
+			}
+			continue
+		}
+
+		fields := spec.DiffFields(role)
+		if len(fields) == 0 {
+			continue
+		}
+		sy.record(domain.PolicyKindRole, domain.PolicyActionUpdate, spec.Code, strings.Join(fields, ","))
+		if sy.apply {
+			spec.ApplyTo(role)
+			if err := sy.repo.SaveRole(ctx, role); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, spec := range doc.Roles {
+		role := sy.state.rolesByCode[spec.Code]
+		oldParentCode := ""
+		if role.ID > 0 {
+			oldParentCode = sy.state.roleParentCode(role)
+		}
+		if oldParentCode == spec.Parent {
+			continue
+		}
+
+		sy.record(domain.PolicyKindRole, domain.PolicyActionUpdate, spec.Code,
+			fmt.Sprintf("parent: %q -> %q", oldParentCode, spec.Parent))
+		if !sy.apply {
+			continue
+		}
+
+		oldParent := sy.state.rolesByCode[oldParentCode]
+		newParent := sy.state.rolesByCode[spec.Parent]
+		role.ParentID = 0
+		if newParent != nil {
+			role.ParentID = newParent.ID
+		}
+		if err := sy.repo.SaveRole(ctx, role); err != nil {
+			return err
+		}
+		if err := sy.repo.UpdateRoleInheritance(ctx, role, oldParent, newParent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncRolePermissions 同步角色权限绑定，prune模式下清空文档中未声明绑定的角色权限
+func (sy *policySyncer) syncRolePermissions(ctx context.Context, doc *domain.PolicyDocument, prune bool) error {
+	desired := make(map[string][]string, len(doc.RolePermissions))
+	for _, binding := range doc.RolePermissions {
+		desired[binding.Role] = binding.Permissions
+	}
+	if prune {
+		for _, spec := range doc.Roles {
+			if _, ok := desired[spec.Code]; !ok && len(sy.state.rolePerms[spec.Code]) > 0 {
+				desired[spec.Code] = nil
+			}
+		}
+	}
+
+	for _, code := range sortedKeys(desired) {
+		added, removed := diffSet(sy.state.rolePerms[code], desired[code])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+
+		action := domain.PolicyActionUpdate
+		if len(sy.state.rolePerms[code]) == 0 {
+			action = domain.PolicyActionCreate
+		} else if len(desired[code]) == 0 {
+			action = domain.PolicyActionDelete
+		}
+		sy.record(domain.PolicyKindRolePermission, action, code, formatSetDiff(added, removed))
+
+		if sy.apply {
+			permissionIDs := make([]types.Long, 0, len(desired[code]))
+			for _, key := range desired[code] {
+				permissionIDs = append(permissionIDs, sy.permID(key))
+			}
+			if err := sy.repo.AssignPermissionsToRole(ctx, sy.state.rolesByCode[code].ID, permissionIDs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncUserRoles 同步用户角色绑定，prune模式下清空文档中未声明用户的角色
+func (sy *policySyncer) syncUserRoles(ctx context.Context, doc *domain.PolicyDocument, prune bool) error {
+	desired := make(map[string][]string, len(doc.UserRoles))
+	for _, binding := range doc.UserRoles {
+		desired[binding.Username] = binding.Roles
+	}
+	if prune {
+		for username := range sy.state.userRoles {
+			if _, ok := desired[username]; !ok {
+				desired[username] = nil
+			}
+		}
+	}
+
+	for _, username := range sortedKeys(desired) {
+		added, removed := diffSet(sy.state.userRoles[username], desired[username])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+
+		action := domain.PolicyActionUpdate
+		if len(sy.state.userRoles[username]) == 0 {
+			action = domain.PolicyActionCreate
+		} else if len(desired[username]) == 0 {
+			action = domain.PolicyActionDelete
+		}
+		sy.record(domain.PolicyKindUserRole, action, username, formatSetDiff(added, removed))
+
+		if sy.apply {
+			roleIDs := make([]types.Long, 0, len(desired[username]))
+			for _, code := range desired[username] {
+				roleIDs = append(roleIDs, sy.state.rolesByCode[code].ID)
+			}
+			userID := sy.state.userIDs[username]
+			if err := sy.repo.AssignRolesToUser(ctx, userID, roleIDs); err != nil {
+				return err
+			}
+			if err := sy.recordGrantLogs(ctx, userID, added, domain.GrantActionGrant); err != nil {
+				return err
+			}
+			if err := sy.recordGrantLogs(ctx, userID, removed, domain.GrantActionRevoke); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordGrantLogs 记录策略导入引起的用户角色变更
+func (sy *policySyncer) recordGrantLogs(ctx context.Context, userID types.Long, roleCodes []string, action string) error {
+	for _, code := range roleCodes {
+		log := &domain.RoleGrantLog{
+			UserID:   userID,
+			RoleCode: code,
+			Action:   action,
+			Reason:   "权限策略导入",
+		}
+		if role, ok := sy.state.rolesByCode[code]; ok {
+			log.RoleID = role.ID
+		}
+		if err := recordGrantLog(ctx, sy.repo, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneRoles 删除文档中未声明的角色
+func (sy *policySyncer) pruneRoles(ctx context.Context, doc *domain.PolicyDocument) error {
+	declared := make(map[string]bool, len(doc.Roles))
+	for _, spec := range doc.Roles {
+		declared[spec.Code] = true
+	}
+
+	for _, role := range sy.state.roles {
+		if declared[role.Code] {
+			continue
+		}
+		sy.record(domain.PolicyKindRole, domain.PolicyActionDelete, role.Code, role.Name)
+		if sy.apply {
+			if err := sy.repo.DeleteRole(ctx, role.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// prunePermissions 删除文档中未声明的权限，按层级由深到浅删除
+func (sy *policySyncer) prunePermissions(ctx context.Context, doc *domain.PolicyDocument) error {
+	declared := make(map[string]bool)
+	_ = domain.WalkPermissions(doc.Permissions, nil, func(spec, _ *domain.PermissionSpec) error {
+		declared[spec.Key] = true
+		return nil
+	})
+
+	pruned := make([]*domain.Permission, 0)
+	for _, perm := range sy.state.permissions {
+		if !declared[perm.Key()] {
+			pruned = append(pruned, perm)
+		}
+	}
+	sort.SliceStable(pruned, func(i, j int) bool {
+		return sy.permDepth(pruned[i]) > sy.permDepth(pruned[j])
+	})
+
+	for _, perm := range pruned {
+		sy.record(domain.PolicyKindPermission, domain.PolicyActionDelete, perm.Key(), perm.Name)
+		if sy.apply {
+			if err := sy.repo.DeletePermission(ctx, perm.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// permID 根据权限key获取权限ID，不存在时返回0
+func (sy *policySyncer) permID(key string) types.Long {
+	if perm, ok := sy.state.permsByKey[key]; ok {
+		return perm.ID
+	}
+	return 0
+}
+
+// permDepth 计算权限在权限树中的深度
+func (sy *policySyncer) permDepth(perm *domain.Permission) int {
+	depth := 0
+	visited := map[types.Long]bool{perm.ID: true}
+	for parent, ok := sy.state.permsByID[perm.ParentID]; ok && !visited[parent.ID]; parent, ok = sy.state.permsByID[parent.ParentID] {
+		visited[parent.ID] = true
+		depth++
+	}
+	return depth
+}
+
+// diffSet 比较当前集合与目标列表，返回新增和移除的元素（已排序）
+func diffSet(current map[string]bool, desired []string) (added, removed []string) {
+	target := make(map[string]bool, len(desired))
+	for _, item := range desired {
+		target[item] = true
+		if !current[item] {
+			added = append(added, item)
+		}
+	}
+	for item := range current {
+		if !target[item] {
+			removed = append(removed, item)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// formatSetDiff 格式化集合差异
+func formatSetDiff(added, removed []string) string {
+	parts := make([]string, 0, 2)
+	if len(added) > 0 {
+		parts = append(parts, "+"+strings.Join(added, ",+"))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "-"+strings.Join(removed, ",-"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// sortedKeys 获取map的有序key列表
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
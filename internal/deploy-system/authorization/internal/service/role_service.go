@@ -35,6 +35,19 @@ func (s *RoleService) CreateRole(ctx context.Context, command *domain.CreateRole
 		return 0, common.RequestParamError("", errors.New("角色编码已存在"))
 	}
 
+	// 如果指定了父角色，检查父角色是否存在
+	var parent *domain.Role
+	if command.ParentID > 0 {
+		parent, err = s.Repo.GetRoleByID(ctx, command.ParentID)
+		if err != nil {
+			return 0, common.InternalError("查询父角色失败", err)
+		}
+
+		if parent == nil {
+			return 0, common.RequestParamError("", errors.New("父角色不存在"))
+		}
+	}
+
 	// 创建事务上下文
 	ctx, err = s.BeginTransaction(ctx, "create role")
 	if err != nil {
@@ -56,61 +69,179 @@ func (s *RoleService) CreateRole(ctx context.Context, command *domain.CreateRole
 		return 0, common.InternalError("保存角色失败", err)
 	}
 
+	// 同步角色继承关系
+	if parent != nil {
+		err = s.Repo.UpdateRoleInheritance(ctx, role, nil, parent)
+		if err != nil {
+			return 0, common.InternalError("同步角色继承关系失败", err)
+		}
+	}
+
 	return role.ID, nil
 }
 
 // UpdateRole 更新角色
-func (s *RoleService) UpdateRole(ctx context.Context, command *domain.UpdateRoleCommand) (err error) {
+// 当父角色发生变化时，返回角色有效权限的变化情况
+func (s *RoleService) UpdateRole(ctx context.Context, command *domain.UpdateRoleCommand) (diff *domain.RolePermissionDiffVO, err error) {
 	// 检查角色是否存在
 	role, err := s.Repo.GetRoleByID(ctx, command.ID)
 	if err != nil {
-		return common.InternalError("查询角色失败", err)
+		return nil, common.InternalError("查询角色失败", err)
 	}
 
 	if role == nil {
-		return common.RequestParamError("", errors.New("角色不存在"))
-	}
-
-	// 创建事务上下文
-	ctx, err = s.BeginTransaction(ctx, "update role")
-	if err != nil {
-		return err
+		return nil, common.RequestParamError("", errors.New("角色不存在"))
 	}
-	defer func() {
-		err = s.FinishTransaction(ctx, err, "update role")
-	}()
 
 	// 验证更新参数
 	err = command.Validate()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 检查角色编码是否已存在
 	if command.Code != role.Code {
 		existRole, err := s.Repo.GetRoleByCode(ctx, command.Code)
 		if err != nil {
-			return common.InternalError("检查角色编码失败", err)
+			return nil, common.InternalError("检查角色编码失败", err)
 		}
 
 		if existRole != nil {
-			return common.RequestParamError("", errors.New("角色编码已存在"))
+			return nil, common.RequestParamError("", errors.New("角色编码已存在"))
 		}
 	}
 
+	// 加载角色继承关系
+	roles, err := s.Repo.GetAllRoles(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询角色列表失败", err)
+	}
+	hierarchy := domain.NewRoleHierarchy(roles)
+
+	// 检查父角色是否存在，以及是否形成循环继承
+	var oldParent, newParent *domain.Role
+	if role.ParentID > 0 {
+		oldParent = hierarchy[role.ParentID]
+	}
+	if command.ParentID > 0 {
+		newParent = hierarchy[command.ParentID]
+		if newParent == nil {
+			return nil, common.RequestParamError("", errors.New("父角色不存在"))
+		}
+
+		if hierarchy.WouldCycle(role.ID, command.ParentID) {
+			return nil, common.RequestParamError("", errors.New("角色继承关系存在循环"))
+		}
+	}
+	parentChanged := command.ParentID != role.ParentID
+
+	// 记录变更前的有效权限
+	var before []*domain.Permission
+	if parentChanged {
+		before, err = s.Repo.GetPermissionsByRoleIDs(ctx, hierarchy.Expand(role.ID))
+		if err != nil {
+			return nil, common.InternalError("获取角色权限失败", err)
+		}
+	}
+
+	// 创建事务上下文
+	ctx, err = s.BeginTransaction(ctx, "update role")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "update role")
+	}()
+
+	// 记录变更前的角色，用于同步Casbin继承关系
+	oldRole := *role
+
 	// 更新角色信息
+	role.ParentID = command.ParentID
 	role.Name = command.Name
 	role.Code = command.Code
 	role.Description = command.Description
 	role.Status = command.Status
+	// 未指定数据权限时保持不变
+	if command.DataScope != "" {
+		if err = role.SetDataScope(command.DataScope, command.DataDepts); err != nil {
+			return nil, common.RequestParamError("", err)
+		}
+	}
 
 	// 保存角色
 	err = s.Repo.SaveRole(ctx, role)
 	if err != nil {
-		return common.InternalError("更新角色失败", err)
+		return nil, common.InternalError("更新角色失败", err)
 	}
 
-	return nil
+	// 同步角色继承关系
+	if parentChanged || oldRole.Code != role.Code {
+		err = s.Repo.UpdateRoleInheritance(ctx, &oldRole, oldParent, nil)
+		if err != nil {
+			return nil, common.InternalError("同步角色继承关系失败", err)
+		}
+		err = s.Repo.UpdateRoleInheritance(ctx, role, nil, newParent)
+		if err != nil {
+			return nil, common.InternalError("同步角色继承关系失败", err)
+		}
+	}
+
+	// 角色编码变更时，同步子角色的继承关系
+	if oldRole.Code != role.Code {
+		for _, child := range hierarchy {
+			if child.ParentID != role.ID {
+				continue
+			}
+			err = s.Repo.UpdateRoleInheritance(ctx, child, &oldRole, role)
+			if err != nil {
+				return nil, common.InternalError("同步角色继承关系失败", err)
+			}
+		}
+	}
+
+	if !parentChanged {
+		return nil, nil
+	}
+
+	// 计算变更后的有效权限
+	hierarchy[role.ID] = role
+	after, err := s.Repo.GetPermissionsByRoleIDs(ctx, hierarchy.Expand(role.ID))
+	if err != nil {
+		return nil, common.InternalError("获取角色权限失败", err)
+	}
+
+	return buildRolePermissionDiff(role.ID, oldRole.ParentID, role.ParentID, before, after), nil
+}
+
+// buildRolePermissionDiff 计算角色有效权限的差异
+func buildRolePermissionDiff(roleID, oldParentID, newParentID types.Long, before, after []*domain.Permission) *domain.RolePermissionDiffVO {
+	diff := &domain.RolePermissionDiffVO{
+		RoleID:      roleID,
+		OldParentID: oldParentID,
+		NewParentID: newParentID,
+		Added:       make([]*domain.PermissionVO, 0),
+		Removed:     make([]*domain.PermissionVO, 0),
+	}
+
+	beforeMap := make(map[types.Long]bool, len(before))
+	for _, perm := range before {
+		beforeMap[perm.ID] = true
+	}
+	afterMap := make(map[types.Long]bool, len(after))
+	for _, perm := range after {
+		afterMap[perm.ID] = true
+		if !beforeMap[perm.ID] {
+			diff.Added = append(diff.Added, perm.ToVO())
+		}
+	}
+	for _, perm := range before {
+		if !afterMap[perm.ID] {
+			diff.Removed = append(diff.Removed, perm.ToVO())
+		}
+	}
+
+	return diff
 }
 
 // DeleteRole 删除角色
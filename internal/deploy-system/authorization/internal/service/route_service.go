@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/authorization/internal/domain"
+	"devops-platform/internal/deploy-system/authorization/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RouteService 根据已注册的Gin路由同步API权限
+type RouteService struct {
+	service.Service
+	Repo   *repository.Repository `inject:"AuthorizationRepository"`
+	Engine *gin.Engine            `inject:"ginEngine"`
+	Logger *logrus.Logger         `inject:"Logger"`
+}
+
+func NewRouteService() *RouteService {
+	return &RouteService{}
+}
+
+func (s *RouteService) StartOrder() int {
+	return 5
+}
+
+// Start 启动时同步路由，此时所有控制器均已完成路由注册
+func (s *RouteService) Start() {
+	result, err := s.SyncRoutes(context.Background())
+	if err != nil {
+		logrus.WithError(err).Error("同步API权限失败")
+		return
+	}
+	logrus.WithField("routes", result.TotalRoutes).
+		WithField("created", len(result.Created)).
+		WithField("orphaned", len(result.Orphaned)).
+		Info("API权限同步完成")
+	for _, perm := range result.Orphaned {
+		logrus.WithField("method", perm.Method).WithField("path", perm.Path).Warn("API权限对应的路由已不存在")
+	}
+}
+
+// SyncRoutes 将路由同步为API权限，按模块挂在分组权限下，并标记路由已不存在的权限
+func (s *RouteService) SyncRoutes(ctx context.Context) (result *domain.RouteSyncResultVO, err error) {
+	routes := s.routes()
+
+	ctx, err = s.BeginTransaction(ctx, "sync routes")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "sync routes")
+	}()
+
+	permissions, err := s.Repo.GetAllPermissions(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询权限失败", err)
+	}
+
+	groups := make(map[string]*domain.Permission)
+	apis := make(map[string]*domain.Permission)
+	for _, perm := range permissions {
+		if perm.IsModuleGroup() {
+			groups[perm.Permission] = perm
+		} else if perm.Type == domain.PermTypeApi && perm.Method != "" {
+			apis[perm.RouteKey()] = perm
+		}
+	}
+
+	result = &domain.RouteSyncResultVO{
+		TotalRoutes: len(routes),
+		Created:     make([]*domain.PermissionVO, 0),
+		Orphaned:    make([]*domain.PermissionVO, 0),
+	}
+
+	registered := make(map[string]bool, len(routes))
+	revived := make([]types.Long, 0)
+	for _, route := range routes {
+		registered[route.Key()] = true
+
+		group, ok := groups[domain.ModulePermissionKey(route.Module)]
+		if !ok {
+			group = domain.ModulePermission(route.Module)
+			group.AuditCreated(ctx)
+			if err = s.Repo.SavePermission(ctx, group); err != nil {
+				return nil, common.InternalError("创建模块权限失败", err)
+			}
+			groups[group.Permission] = group
+			result.Created = append(result.Created, group.ToVO())
+		}
+
+		perm, ok := apis[route.Key()]
+		if !ok {
+			perm = route.ToPermission(group.ID)
+			perm.AuditCreated(ctx)
+			if err = s.Repo.SavePermission(ctx, perm); err != nil {
+				return nil, common.InternalError("创建API权限失败", err)
+			}
+			apis[route.Key()] = perm
+			result.Created = append(result.Created, perm.ToVO())
+			continue
+		}
+
+		// 已有权限仅在未归类时挂到模块下，不覆盖手工调整
+		if perm.ParentID == 0 {
+			perm.ParentID = group.ID
+			perm.Orphaned = false
+			perm.AuditModified(ctx)
+			if err = s.Repo.SavePermission(ctx, perm); err != nil {
+				return nil, common.InternalError("更新API权限失败", err)
+			}
+		} else if perm.Orphaned {
+			revived = append(revived, perm.ID)
+		}
+	}
+	if err = s.Repo.MarkPermissionsOrphaned(ctx, revived, false); err != nil {
+		return nil, common.InternalError("更新API权限失败", err)
+	}
+
+	// 标记路由已不存在的API权限
+	orphaned := make([]types.Long, 0)
+	for _, key := range sortedKeys(apis) {
+		perm := apis[key]
+		if registered[key] {
+			continue
+		}
+		if !perm.Orphaned {
+			orphaned = append(orphaned, perm.ID)
+			perm.Orphaned = true
+		}
+		result.Orphaned = append(result.Orphaned, perm.ToVO())
+	}
+	if err = s.Repo.MarkPermissionsOrphaned(ctx, orphaned, true); err != nil {
+		return nil, common.InternalError("标记孤立权限失败", err)
+	}
+
+	return result, nil
+}
+
+// GetRouteReport 获取路由权限报告：没有任何角色可以访问的路由，以及路由已不存在的API权限
+func (s *RouteService) GetRouteReport(ctx context.Context) (*domain.RouteReportVO, error) {
+	routes := s.routes()
+
+	permissions, err := s.Repo.GetAllPermissions(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询权限失败", err)
+	}
+	bound, err := s.Repo.GetBoundPermissionIDs(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询角色权限失败", err)
+	}
+
+	apis := make(map[string]*domain.Permission)
+	for _, perm := range permissions {
+		if perm.Type == domain.PermTypeApi && perm.Method != "" && !perm.IsModuleGroup() {
+			apis[perm.RouteKey()] = perm
+		}
+	}
+
+	report := &domain.RouteReportVO{
+		TotalRoutes:         len(routes),
+		UnreachableRoutes:   make([]*domain.RouteInfo, 0),
+		OrphanedPermissions: make([]*domain.PermissionVO, 0),
+	}
+
+	registered := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		registered[route.Key()] = true
+		perm, ok := apis[route.Key()]
+		if ok {
+			route.PermID = perm.ID
+		}
+		if !ok || !bound[perm.ID] {
+			report.UnreachableRoutes = append(report.UnreachableRoutes, route)
+		}
+	}
+
+	for _, key := range sortedKeys(apis) {
+		if !registered[key] {
+			report.OrphanedPermissions = append(report.OrphanedPermissions, apis[key].ToVO())
+		}
+	}
+
+	return report, nil
+}
+
+// routes 获取需要鉴权的API路由，按路径、方法排序
+func (s *RouteService) routes() []*domain.RouteInfo {
+	routes := make([]*domain.RouteInfo, 0)
+	if s.Engine == nil {
+		return routes
+	}
+
+	seen := make(map[string]bool)
+	for _, route := range s.Engine.Routes() {
+		if !strings.HasPrefix(route.Path, domain.RouteApiPrefix) || web.IsIgnoreUrl(route.Path) {
+			continue
+		}
+		info := domain.NewRouteInfo(route.Method, route.Path, route.Handler)
+		if seen[info.Key()] {
+			continue
+		}
+		seen[info.Key()] = true
+		routes = append(routes, info)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
@@ -0,0 +1,17 @@
+package build
+
+import (
+	"devops-platform/internal/deploy-system/build/internal/domain"
+)
+
+// Bean常量
+const (
+	BeanBuildService = domain.BeanBuildService
+)
+
+// 领域对象类型别名
+type (
+	BuildConfig = domain.BuildConfig
+	Build       = domain.Build
+	BuildStep   = domain.BuildStep
+)
@@ -0,0 +1,40 @@
+package init
+
+import (
+	"devops-platform/internal/deploy-system/build/internal/controller"
+	"devops-platform/internal/deploy-system/build/internal/domain"
+	"devops-platform/internal/deploy-system/build/internal/repository"
+	"devops-platform/internal/deploy-system/build/internal/service"
+	"devops-platform/internal/pkg/kube"
+	"devops-platform/internal/pkg/periodic"
+	"devops-platform/pkg/beans"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 使用标准的init函数进行初始化
+func init() {
+	// 注册仓储
+	beans.Register(domain.BeanBuildRepository, repository.NewRepository())
+
+	// 注册构建执行器，不在集群内运行时只能生成PipelineRun，不能提交
+	runner := service.NewTektonRunner(nil)
+	if client, err := kube.NewInClusterClient(); err != nil {
+		logrus.WithError(err).Warn("Tekton集群客户端不可用，构建将无法提交")
+	} else {
+		runner.Client = &service.KubeTektonClient{Client: client}
+	}
+	beans.Register(domain.BeanTektonRunner, runner)
+
+	// 注册服务
+	buildService := service.NewBuildService()
+	beans.Register(domain.BeanBuildService, buildService)
+
+	// 注册构建状态同步任务
+	beans.Register(domain.BeanBuildWatcher, periodic.New("构建状态同步", domain.SyncInterval, buildService.SyncBuilds))
+
+	// 注册控制器
+	beans.Register(domain.BeanBuildController, controller.NewBuildController())
+
+	logrus.Info("构建模块初始化完成")
+}
@@ -0,0 +1,246 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/build/internal/domain"
+	"devops-platform/internal/deploy-system/build/internal/service"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BuildController 构建控制器
+type BuildController struct {
+	web.Controller
+	Service *service.BuildService `inject:"BuildService"`
+}
+
+// NewBuildController 创建构建控制器实例
+func NewBuildController() *BuildController {
+	return &BuildController{}
+}
+
+// ListBuildMethods 查询构建方法
+// @Summary 查询构建方法
+// @Description 返回可构建的方法及对应的Tekton Pipeline（产出镜像的方法每个镜像构建工具一个）与任务
+// @Tags 构建管理
+// @Produce json
+// @Success 200 {object} common.Response{data=[]domain.BuildMethodVO}
+// @Router /api/v1/build-methods [get]
+func (c *BuildController) ListBuildMethods(ctx *gin.Context) {
+	common.ResponseSuccess(ctx, c.Service.ListBuildMethods())
+}
+
+// GetBuildConfig 获取构建配置
+// @Summary 获取构建配置
+// @Tags 构建管理
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response{data=domain.BuildConfig}
+// @Router /api/v1/apps/{id}/build-config [get]
+func (c *BuildController) GetBuildConfig(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "应用ID")
+	if !ok {
+		return
+	}
+	config, err := c.Service.GetBuildConfig(ctx, appID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, config)
+}
+
+// SaveBuildConfig 保存构建配置
+// @Summary 保存构建配置
+// @Description 不存在时创建。build_type: 0自定义分支（可按branch_pattern限制），1固定分支；deploy_env_id大于0时构建成功后为镜像创建发布计划
+// @Tags 构建管理
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.SaveBuildConfigCommand true "构建配置"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/build-config [put]
+func (c *BuildController) SaveBuildConfig(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "应用ID")
+	if !ok {
+		return
+	}
+	var command domain.SaveBuildConfigCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+
+	id, err := c.Service.SaveBuildConfig(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, gin.H{"id": id})
+}
+
+// RenderPipeline 预览构建定义
+// @Summary 预览构建定义
+// @Description 按构建配置生成下一次构建将提交的Secret、Task、Pipeline与PipelineRun并校验，凭据内容已隐藏，不创建构建记录
+// @Tags 构建管理
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param branch query string false "分支"
+// @Param commit query string false "提交SHA"
+// @Success 200 {object} common.Response{data=domain.PipelineVO}
+// @Router /api/v1/apps/{id}/pipeline [get]
+func (c *BuildController) RenderPipeline(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "应用ID")
+	if !ok {
+		return
+	}
+	var command domain.TriggerBuildCommand
+	if err := ctx.ShouldBindQuery(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+
+	pipeline, err := c.Service.RenderPipeline(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, pipeline)
+}
+
+// TriggerBuild 触发构建
+// @Summary 触发构建
+// @Description 固定分支构建忽略branch或要求与配置一致；commit为空时构建分支最新提交
+// @Tags 构建管理
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.TriggerBuildCommand false "构建参数"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/builds [post]
+func (c *BuildController) TriggerBuild(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "应用ID")
+	if !ok {
+		return
+	}
+	var command domain.TriggerBuildCommand
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&command); err != nil {
+			common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+			return
+		}
+	}
+	command.AppID = appID
+
+	id, err := c.Service.TriggerBuild(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, gin.H{"id": id})
+}
+
+// ListBuilds 查询构建记录
+// @Summary 查询构建记录
+// @Tags 构建管理
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param status query int false "状态: -2已取消, -1失败, 0待构建, 1构建中, 3成功"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=common.PageResult{list=[]domain.Build}}
+// @Router /api/v1/apps/{id}/builds [get]
+func (c *BuildController) ListBuilds(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "应用ID")
+	if !ok {
+		return
+	}
+	var query domain.BuildQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	query.AppID = appID
+
+	builds, total, err := c.Service.ListBuilds(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccessWithPageExt(ctx, builds, total, query.Page, query.Size)
+}
+
+// GetBuild 获取构建详情
+// @Summary 获取构建详情
+// @Tags 构建管理
+// @Produce json
+// @Param id path int true "构建ID"
+// @Success 200 {object} common.Response{data=domain.BuildDetailVO}
+// @Router /api/v1/builds/{id} [get]
+func (c *BuildController) GetBuild(ctx *gin.Context) {
+	id, ok := pathID(ctx, "构建ID")
+	if !ok {
+		return
+	}
+	build, err := c.Service.GetBuild(ctx, id)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, build)
+}
+
+// GetManifest 获取构建定义
+// @Summary 获取构建定义
+// @Description 返回提交给执行器的定义（Tekton资源的多文档YAML，凭据内容已隐藏）
+// @Tags 构建管理
+// @Produce plain
+// @Param id path int true "构建ID"
+// @Success 200 {string} string "Tekton YAML"
+// @Router /api/v1/builds/{id}/manifest [get]
+func (c *BuildController) GetManifest(ctx *gin.Context) {
+	id, ok := pathID(ctx, "构建ID")
+	if !ok {
+		return
+	}
+	manifest, err := c.Service.GetManifest(ctx, id)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/yaml; charset=utf-8", []byte(manifest))
+}
+
+// CancelBuild 取消构建
+// @Summary 取消构建
+// @Tags 构建管理
+// @Produce json
+// @Param id path int true "构建ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/builds/{id}/cancel [post]
+func (c *BuildController) CancelBuild(ctx *gin.Context) {
+	id, ok := pathID(ctx, "构建ID")
+	if !ok {
+		return
+	}
+	if err := c.Service.CancelBuild(ctx, id); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// pathID 解析路径中的ID参数，失败时直接返回400
+func pathID(ctx *gin.Context, name string) (types.Long, bool) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+name+"必须是数字")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Inject 实现依赖注入
+func (c *BuildController) Inject(getBean func(string) interface{}) {
+	c.injectRouting(getBean)
+}
+
+// injectRouting 注入路由
+func (c *BuildController) injectRouting(getBean func(string) interface{}) {
+	router, ok := getBean(web.BeanGinEngine).(gin.IRouter)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", web.BeanGinEngine)
+		return
+	}
+
+	authRouter := router.Group("/api/v1")
+	authRouter.Use(middleware.JWTAuth())
+	{
+		// 构建方法
+		authRouter.GET("/build-methods", c.ListBuildMethods)
+
+		// 应用构建配置与构建记录
+		authRouter.GET("/apps/:id/build-config", c.GetBuildConfig)
+		authRouter.PUT("/apps/:id/build-config", c.SaveBuildConfig)
+		authRouter.GET("/apps/:id/pipeline", c.RenderPipeline)
+		authRouter.GET("/apps/:id/builds", c.ListBuilds)
+		authRouter.POST("/apps/:id/builds", c.TriggerBuild)
+
+		// 构建
+		authRouter.GET("/builds/:id", c.GetBuild)
+		authRouter.GET("/builds/:id/manifest", c.GetManifest)
+		authRouter.POST("/builds/:id/cancel", c.CancelBuild)
+	}
+}
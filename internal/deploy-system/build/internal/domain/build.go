@@ -0,0 +1,387 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BuildConfig 应用构建配置，每个应用一个
+type BuildConfig struct {
+	module.Module
+	AppID           types.Long       `json:"app_id" gorm:"not null;uniqueIndex;comment:'应用ID'"`
+	RepoURL         string           `json:"repo_url" gorm:"size:500;not null;comment:'代码仓库地址'"`
+	BuildType       enum.BuildType   `json:"build_type" gorm:"not null;comment:'分支策略: 0自定义分支, 1固定分支'"`
+	Branch          string           `json:"branch" gorm:"size:255;comment:'固定分支或默认分支'"`
+	BranchPattern   string           `json:"branch_pattern" gorm:"size:255;comment:'自定义分支允许的分支正则，为空不限'"`
+	BuildMethod     enum.BuildMethod `json:"build_method" gorm:"not null;comment:'构建方法'"`
+	DockerfilePath  string           `json:"dockerfile_path" gorm:"size:255;comment:'Dockerfile路径'"`
+	ContextDir      string           `json:"context_dir" gorm:"size:255;comment:'镜像构建上下文目录'"`
+	RegistryID      types.Long       `json:"registry_id" gorm:"comment:'镜像仓库ID'"`
+	ImageRepository string           `json:"image_repository" gorm:"size:255;comment:'镜像名，如 team/demo-app'"`
+	ImageBuilder    string           `json:"image_builder" gorm:"size:20;comment:'镜像构建工具: kaniko, buildah'"`
+	Runner          string           `json:"runner" gorm:"size:20;not null;comment:'构建执行器'"`
+	DeployEnvID     types.Long       `json:"deploy_env_id" gorm:"comment:'构建成功后创建发布计划的环境，0表示不创建'"`
+	DeployStrategy  string           `json:"deploy_strategy" gorm:"size:50;comment:'发布策略'"`
+}
+
+// TableName 返回构建配置表名
+func (BuildConfig) TableName() string {
+	return "app_build_config"
+}
+
+// ResolveBranch 按分支策略确定构建分支：固定分支只能构建配置的分支，自定义分支需匹配分支正则
+func (c *BuildConfig) ResolveBranch(branch string) (string, error) {
+	branch = strings.TrimSpace(branch)
+	if c.BuildType == enum.BuildTypeFixed {
+		if branch != "" && branch != c.Branch {
+			return "", fmt.Errorf("固定分支构建只能构建分支: %s", c.Branch)
+		}
+		return c.Branch, nil
+	}
+
+	if branch == "" {
+		branch = c.Branch
+	}
+	if branch == "" {
+		return "", errors.New("请指定构建分支")
+	}
+	if c.BranchPattern != "" {
+		pattern, err := regexp.Compile(c.BranchPattern)
+		if err != nil {
+			return "", fmt.Errorf("分支正则无效: %w", err)
+		}
+		if !pattern.MatchString(branch) {
+			return "", fmt.Errorf("分支 %s 不匹配允许的分支: %s", branch, c.BranchPattern)
+		}
+	}
+	return branch, nil
+}
+
+// SaveBuildConfigCommand 保存构建配置命令
+type SaveBuildConfigCommand struct {
+	AppID           types.Long       `json:"-"`
+	RepoURL         string           `json:"repo_url" binding:"required,max=500"`
+	BuildType       enum.BuildType   `json:"build_type"`
+	Branch          string           `json:"branch" binding:"max=255"`
+	BranchPattern   string           `json:"branch_pattern" binding:"max=255"`
+	BuildMethod     enum.BuildMethod `json:"build_method" binding:"required"`
+	DockerfilePath  string           `json:"dockerfile_path" binding:"max=255"`
+	ContextDir      string           `json:"context_dir" binding:"max=255"`
+	RegistryID      types.Long       `json:"registry_id"`
+	ImageRepository string           `json:"image_repository" binding:"max=255"`
+	ImageBuilder    string           `json:"image_builder"`
+	Runner          string           `json:"runner"`
+	DeployEnvID     types.Long       `json:"deploy_env_id"`
+	DeployStrategy  string           `json:"deploy_strategy" binding:"max=50"`
+}
+
+// ApplyTo 校验命令并写入构建配置
+func (command *SaveBuildConfigCommand) ApplyTo(config *BuildConfig) error {
+	if MethodKey(command.BuildMethod) == "" {
+		return fmt.Errorf("不支持的构建方法: %d", command.BuildMethod)
+	}
+	switch command.BuildType {
+	case enum.BuildTypeFixed:
+		if strings.TrimSpace(command.Branch) == "" {
+			return errors.New("固定分支构建必须指定分支")
+		}
+	case enum.BuildTypeCustom:
+	default:
+		return fmt.Errorf("不支持的分支策略: %d", command.BuildType)
+	}
+	if _, err := regexp.Compile(command.BranchPattern); err != nil {
+		return fmt.Errorf("分支正则无效: %w", err)
+	}
+	if command.Runner == "" {
+		command.Runner = RunnerTekton
+	}
+	if _, ok := RunnerBeans[command.Runner]; !ok {
+		return fmt.Errorf("不支持的构建执行器: %s", command.Runner)
+	}
+
+	hasImage := command.BuildMethod.HasImageBuild()
+	if hasImage {
+		if command.RegistryID == 0 {
+			return errors.New("产出镜像的构建方法必须指定镜像仓库")
+		}
+		if command.ImageBuilder == "" {
+			command.ImageBuilder = ImageBuilderKaniko
+		}
+		if !IsImageBuilder(command.ImageBuilder) {
+			return fmt.Errorf("不支持的镜像构建工具: %s", command.ImageBuilder)
+		}
+	} else {
+		command.ImageBuilder = ""
+	}
+	if command.DeployEnvID > 0 {
+		if !hasImage {
+			return fmt.Errorf("构建方法[%s]不产出镜像，不能自动创建发布计划", command.BuildMethod)
+		}
+		if command.DeployStrategy == "" {
+			command.DeployStrategy = DefaultStrategy
+		}
+	} else {
+		command.DeployStrategy = ""
+	}
+	if command.DockerfilePath == "" {
+		command.DockerfilePath = DefaultDockerfile
+	}
+	if command.ContextDir == "" {
+		command.ContextDir = DefaultContextDir
+	}
+
+	config.AppID = command.AppID
+	config.RepoURL = strings.TrimSpace(command.RepoURL)
+	config.BuildType = command.BuildType
+	config.Branch = strings.TrimSpace(command.Branch)
+	config.BranchPattern = command.BranchPattern
+	config.BuildMethod = command.BuildMethod
+	config.DockerfilePath = command.DockerfilePath
+	config.ContextDir = command.ContextDir
+	config.RegistryID = command.RegistryID
+	config.ImageRepository = strings.Trim(strings.TrimSpace(command.ImageRepository), "/")
+	config.ImageBuilder = command.ImageBuilder
+	config.Runner = command.Runner
+	config.DeployEnvID = command.DeployEnvID
+	config.DeployStrategy = command.DeployStrategy
+	return nil
+}
+
+// Build 构建记录
+type Build struct {
+	module.Module
+	AppID          types.Long       `json:"app_id" gorm:"not null;uniqueIndex:uk_app_number;comment:'应用ID'"`
+	Number         int              `json:"number" gorm:"not null;uniqueIndex:uk_app_number;comment:'应用内构建序号'"`
+	Branch         string           `json:"branch" gorm:"size:255;comment:'构建分支'"`
+	Commit         string           `json:"commit" gorm:"size:64;comment:'构建提交'"`
+	BuildMethod    enum.BuildMethod `json:"build_method" gorm:"not null;comment:'构建方法'"`
+	Runner         string           `json:"runner" gorm:"size:20;comment:'构建执行器'"`
+	Namespace      string           `json:"namespace" gorm:"size:100;comment:'执行命名空间'"`
+	RunName        string           `json:"run_name" gorm:"size:100;comment:'执行器中的运行名称'"`
+	Manifest       string           `json:"-" gorm:"type:text;comment:'提交给执行器的定义'"`
+	Image          string           `json:"image" gorm:"size:500;comment:'产出镜像，不产出镜像时为空'"`
+	ImageTag       string           `json:"image_tag" gorm:"size:50;comment:'镜像tag，同时作为发布计划版本号'"`
+	Status         enum.BuildStatus `json:"status" gorm:"not null;index;comment:'构建状态'"`
+	Message        string           `json:"message" gorm:"size:1000;comment:'说明'"`
+	StartedAt      *time.Time       `json:"started_at" gorm:"comment:'开始时间'"`
+	FinishedAt     *time.Time       `json:"finished_at" gorm:"comment:'结束时间'"`
+	DeployEnvID    types.Long       `json:"deploy_env_id" gorm:"comment:'构建成功后创建发布计划的环境'"`
+	DeployStrategy string           `json:"deploy_strategy" gorm:"size:50;comment:'发布策略'"`
+	PlanID         types.Long       `json:"plan_id" gorm:"comment:'构建成功后创建的发布计划ID'"`
+}
+
+// TableName 返回构建记录表名
+func (Build) TableName() string {
+	return "app_build"
+}
+
+// Finished 构建是否已结束
+func (b *Build) Finished() bool {
+	switch b.Status {
+	case enum.BuildStatusSuccess, enum.BuildStatusFail, enum.BuildStatusCanceled:
+		return true
+	}
+	return false
+}
+
+// Finish 结束构建
+func (b *Build) Finish(status enum.BuildStatus, message string, now time.Time) {
+	b.Status = status
+	b.Message = truncate(message, 1000)
+	b.FinishedAt = &now
+}
+
+// BuildStep 构建步骤，对应Pipeline中的任务
+type BuildStep struct {
+	ID         types.Long       `json:"id" gorm:"primaryKey;autoIncrement"`
+	BuildID    types.Long       `json:"build_id" gorm:"not null;index;comment:'构建ID'"`
+	Name       string           `json:"name" gorm:"size:64;not null;comment:'步骤名称'"`
+	Sort       int              `json:"sort" gorm:"not null;comment:'顺序'"`
+	Status     enum.BuildStatus `json:"status" gorm:"not null;comment:'步骤状态'"`
+	Message    string           `json:"message" gorm:"size:1000;comment:'说明'"`
+	StartedAt  *time.Time       `json:"started_at" gorm:"comment:'开始时间'"`
+	FinishedAt *time.Time       `json:"finished_at" gorm:"comment:'结束时间'"`
+}
+
+// TableName 返回构建步骤表名
+func (BuildStep) TableName() string {
+	return "app_build_step"
+}
+
+// BuildDetailVO 构建详情
+type BuildDetailVO struct {
+	*Build
+	StatusName string       `json:"status_name"`
+	Steps      []*BuildStep `json:"steps"`
+}
+
+// BuildMethodVO 构建方法
+type BuildMethodVO struct {
+	Value     enum.BuildMethod `json:"value"`
+	Name      string           `json:"name"`
+	Pipelines []string         `json:"pipelines"`
+	HasImage  bool             `json:"has_image"`
+	Tasks     []string         `json:"tasks"`
+}
+
+// TriggerBuildCommand 触发构建命令
+type TriggerBuildCommand struct {
+	AppID  types.Long `json:"-"`
+	Branch string     `json:"branch" form:"branch" binding:"max=255"`
+	Commit string     `json:"commit" form:"commit" binding:"max=64"`
+}
+
+// PipelineVO 构建定义预览
+type PipelineVO struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`     // PipelineRun名称，构建ID分配前为占位
+	Pipeline  string   `json:"pipeline"` // 引用的Pipeline名称
+	Valid     bool     `json:"valid"`
+	Errors    []string `json:"errors"`
+	Manifest  string   `json:"manifest"` // 多文档YAML，凭据已隐藏
+}
+
+// BuildQuery 构建记录查询条件
+type BuildQuery struct {
+	AppID  types.Long `form:"-"`
+	Status *int       `form:"status"`
+	Page   int        `form:"page"`
+	Size   int        `form:"size"`
+}
+
+// BuildRun 提交给执行器的构建上下文
+type BuildRun struct {
+	Build    *Build
+	Config   *BuildConfig
+	AppName  string
+	Registry *Registry // 不产出镜像时为nil
+}
+
+// SetNumber 设置构建序号，产出镜像的构建同时生成镜像tag与镜像地址
+func (r *BuildRun) SetNumber(number int) {
+	build := r.Build
+	build.Number = number
+	if build.BuildMethod.HasImageBuild() && r.Registry != nil {
+		build.ImageTag = ImageTag(build.Branch, build.Number, build.Commit)
+		build.Image = ImageName(r.Registry.URL, r.Config.ImageRepository, build.ImageTag)
+	}
+}
+
+// Registry 镜像仓库及推送凭据
+type Registry struct {
+	ID       types.Long
+	URL      string
+	Username string
+	Password string
+	Email    string
+}
+
+// RunStatus 执行器返回的构建状态
+type RunStatus struct {
+	Status     enum.BuildStatus
+	Message    string
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	Steps      map[string]*StepStatus
+}
+
+// StepStatus 执行器返回的步骤状态
+type StepStatus struct {
+	Status     enum.BuildStatus
+	Message    string
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+// ImageTag 生成镜像tag：分支-构建序号[-提交SHA前8位]，不超过发布计划版本号长度
+func ImageTag(branch string, number int, commit string) string {
+	suffix := fmt.Sprintf("-%d", number)
+	if commit != "" {
+		if len(commit) > ShortCommitLength {
+			commit = commit[:ShortCommitLength]
+		}
+		suffix += "-" + commit
+	}
+	tag := strings.Trim(invalidTagChars.ReplaceAllString(branch, "-"), "-.")
+	if limit := MaxImageTagLength - len(suffix); len(tag) > limit {
+		tag = strings.TrimRight(tag[:limit], "-.")
+	}
+	if tag == "" {
+		tag = "build"
+	}
+	return tag + suffix
+}
+
+// ImageName 拼接完整镜像地址，镜像仓库地址去掉协议头
+func ImageName(registryURL, repository, tag string) string {
+	host := RegistryHost(registryURL)
+	if host == "" {
+		return repository + ":" + tag
+	}
+	return host + "/" + repository + ":" + tag
+}
+
+// RegistryHost 镜像仓库地址去掉协议头与结尾的斜杠
+func RegistryHost(registryURL string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(registryURL, "https://"), "http://")
+	return strings.TrimRight(host, "/")
+}
+
+// CompareURL 生成代码仓库比较两个提交的页面地址，支持 https 与 git@host:path 形式的仓库地址；
+// GitLab 使用 /-/compare，GitHub、Gitea 使用 /compare，无法识别时返回空字符串
+func CompareURL(repoURL, from, to string) string {
+	if from == "" || to == "" {
+		return ""
+	}
+	base := strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(repoURL), "/"), ".git")
+	if rest, ok := strings.CutPrefix(base, "git@"); ok {
+		host, path, found := strings.Cut(rest, ":")
+		if !found {
+			return ""
+		}
+		base = "https://" + host + "/" + path
+	}
+	if !strings.HasPrefix(base, "https://") && !strings.HasPrefix(base, "http://") {
+		return ""
+	}
+	if strings.Contains(base, "gitlab") {
+		return fmt.Sprintf("%s/-/compare/%s...%s", base, from, to)
+	}
+	return fmt.Sprintf("%s/compare/%s...%s", base, from, to)
+}
+
+// RunName 生成PipelineRun名称（DNS-1123标签）
+func RunName(appName string, buildID types.Long) string {
+	suffix := fmt.Sprintf("-build-%d", buildID)
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(appName), "-"), "-")
+	if limit := MaxRunNameLength - len(suffix); len(name) > limit {
+		name = strings.TrimRight(name[:limit], "-")
+	}
+	if name == "" {
+		name = "app"
+	}
+	return name + suffix
+}
+
+// RegistrySecretName 镜像仓库凭据在集群中的Secret名称（docker config格式）
+func RegistrySecretName(registryID types.Long) string {
+	return fmt.Sprintf("registry-%d", registryID)
+}
+
+var (
+	invalidTagChars  = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+	invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+func truncate(s string, max int) string {
+	if len([]rune(s)) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
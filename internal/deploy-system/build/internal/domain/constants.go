@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/enum"
+	"time"
+)
+
+const (
+	// 模块Bean名称常量
+	BeanBuildRepository = "BuildRepository"
+	BeanBuildService    = "BuildService"
+	BeanBuildWatcher    = "BuildWatcher"
+	BeanBuildController = "BuildController"
+	BeanTektonRunner    = "TektonRunner"
+
+	// 构建执行器
+	RunnerTekton = "tekton" // Tekton PipelineRun
+
+	// 构建配置默认值
+	DefaultDockerfile = "Dockerfile"
+	DefaultContextDir = "."
+	DefaultStrategy   = "rolling"
+
+	// DefaultNamespace tekton.system_name未配置时提交PipelineRun的命名空间
+	DefaultNamespace = "devops"
+
+	// SyncInterval 同步构建状态的间隔
+	SyncInterval = 10 * time.Second
+	// SyncBatchSize 每次同步的构建数量上限
+	SyncBatchSize = 50
+
+	// MaxImageTagLength 镜像tag长度上限，与发布计划版本号长度一致
+	MaxImageTagLength = 50
+	// MaxRunNameLength PipelineRun名称长度上限（DNS-1123标签）
+	MaxRunNameLength = 63
+	// ShortCommitLength 镜像tag中提交SHA的长度
+	ShortCommitLength = 8
+
+	// PipelineRun标签
+	LabelAppID   = "devops-platform/app-id"
+	LabelBuildID = "devops-platform/build-id"
+
+	// Pipeline参数
+	ParamGitURL      = "git-url"
+	ParamGitRevision = "git-revision"
+	ParamImage       = "image"
+	ParamDockerfile  = "dockerfile"
+	ParamContext     = "context"
+
+	// Pipeline工作空间
+	WorkspaceSource       = "source"
+	WorkspaceDockerConfig = "docker-config"
+
+	// SourceStorage 源码工作空间的临时存储大小
+	SourceStorage = "1Gi"
+
+	// 镜像构建工具
+	ImageBuilderKaniko  = "kaniko"  // 无需特权，默认
+	ImageBuilderBuildah = "buildah" // 需要特权容器
+
+	// Secret中docker config的键，挂载到工作空间时映射为config.json
+	DockerConfigKey  = ".dockerconfigjson"
+	DockerConfigFile = "config.json"
+
+	// Pipeline任务，同时作为构建步骤名称
+	TaskClone = "clone" // 拉取代码
+	TaskBuild = "build" // 编译打包
+	TaskTest  = "test"  // 单元测试
+	TaskImage = "image" // 构建并推送镜像
+
+	// CloneTaskName 拉取代码的Task名称，各构建方法共用
+	CloneTaskName = "devops-git-clone"
+)
+
+// RunnerBeans 构建执行器名称与Bean的对应关系，新增执行器时在此注册
+var RunnerBeans = map[string]string{
+	RunnerTekton: BeanTektonRunner,
+}
+
+// methodKeys 构建方法在Pipeline名称中的标识
+var methodKeys = map[enum.BuildMethod]string{
+	enum.BuildMethodJava:       "java",
+	enum.BuildMethodJavaApi:    "java-api",
+	enum.BuildMethodGolang:     "golang",
+	enum.BuildMethodYarn:       "yarn",
+	enum.BuildMethodNpm:        "npm",
+	enum.BuildMethodYarnHigh:   "yarn-high",
+	enum.BuildMethodNpmHigh:    "npm-high",
+	enum.BuildMethodAndroidSdk: "android-sdk",
+}
+
+// BuildMethods 可构建的方法，按枚举值排序
+var BuildMethods = []enum.BuildMethod{
+	enum.BuildMethodJava,
+	enum.BuildMethodJavaApi,
+	enum.BuildMethodGolang,
+	enum.BuildMethodYarn,
+	enum.BuildMethodNpm,
+	enum.BuildMethodYarnHigh,
+	enum.BuildMethodNpmHigh,
+	enum.BuildMethodAndroidSdk,
+}
+
+// ImageBuilders 支持的镜像构建工具
+var ImageBuilders = []string{ImageBuilderKaniko, ImageBuilderBuildah}
+
+// IsImageBuilder 是否为支持的镜像构建工具
+func IsImageBuilder(builder string) bool {
+	return builder == ImageBuilderKaniko || builder == ImageBuilderBuildah
+}
+
+// MethodKey 构建方法标识，不可构建的方法返回空
+func MethodKey(method enum.BuildMethod) string {
+	return methodKeys[method]
+}
+
+// PipelineName 构建方法对应的Tekton Pipeline名称，产出镜像的方法按镜像构建工具区分
+func PipelineName(method enum.BuildMethod, builder string) string {
+	name := "devops-build-" + MethodKey(method)
+	if method.HasImageBuild() {
+		name += "-" + builder
+	}
+	return name
+}
+
+// CompileTaskName 构建方法的编译Task名称
+func CompileTaskName(method enum.BuildMethod) string {
+	return "devops-" + MethodKey(method) + "-compile"
+}
+
+// TestTaskName 构建方法的测试Task名称
+func TestTaskName(method enum.BuildMethod) string {
+	return "devops-" + MethodKey(method) + "-test"
+}
+
+// ImageTaskName 镜像构建工具的Task名称
+func ImageTaskName(builder string) string {
+	return "devops-image-" + builder
+}
+
+// PipelineTasks 构建方法的Pipeline任务，不产出镜像的方法没有镜像任务
+func PipelineTasks(method enum.BuildMethod) []string {
+	tasks := []string{TaskClone, TaskBuild, TaskTest}
+	if method.HasImageBuild() {
+		tasks = append(tasks, TaskImage)
+	}
+	return tasks
+}
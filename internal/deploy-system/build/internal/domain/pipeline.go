@@ -0,0 +1,576 @@
+package domain
+
+import (
+	"bytes"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/kube"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Task内部的参数与工作空间名称，Pipeline通过绑定与Pipeline级别的名称对应
+const (
+	taskParamURL        = "url"
+	taskParamRevision   = "revision"
+	taskWorkspaceOutput = "output"
+	taskWorkspaceSource = "source"
+	taskWorkspaceDocker = "dockerconfig"
+
+	// MaskedValue 预览时替换敏感数据
+	MaskedValue = "******"
+)
+
+// 构建使用的镜像
+const (
+	imageGit     = "alpine/git:2.45.2"
+	imageMaven   = "maven:3.9-eclipse-temurin-17"
+	imageGolang  = "golang:1.23"
+	imageNode    = "node:16"
+	imageNodeLTS = "node:20"
+	imageAndroid = "cimg/android:2024.10.1"
+	imageKaniko  = "gcr.io/kaniko-project/executor:v1.23.2"
+	imageBuildah = "quay.io/buildah/stable:v1.37"
+)
+
+// toolchain 构建方法的编译与测试命令，在源码工作空间根目录执行
+type toolchain struct {
+	image string
+	build string
+	test  string
+}
+
+// toolchains 各构建方法的工具链
+var toolchains = map[enum.BuildMethod]toolchain{
+	enum.BuildMethodJava:       {image: imageMaven, build: "mvn -B -DskipTests package", test: "mvn -B test"},
+	enum.BuildMethodJavaApi:    {image: imageMaven, build: "mvn -B -DskipTests deploy", test: "mvn -B test"},
+	enum.BuildMethodGolang:     {image: imageGolang, build: "go build ./...", test: "go test ./..."},
+	enum.BuildMethodYarn:       {image: imageNode, build: "yarn install --frozen-lockfile\nyarn build", test: "yarn test"},
+	enum.BuildMethodNpm:        {image: imageNode, build: "npm ci\nnpm run build", test: "npm test"},
+	enum.BuildMethodYarnHigh:   {image: imageNodeLTS, build: "yarn install --frozen-lockfile\nyarn build", test: "yarn test"},
+	enum.BuildMethodNpmHigh:    {image: imageNodeLTS, build: "npm ci\nnpm run build", test: "npm test"},
+	enum.BuildMethodAndroidSdk: {image: imageAndroid, build: "./gradlew assembleRelease", test: "./gradlew testReleaseUnitTest"},
+}
+
+var (
+	// dns1123Label Kubernetes资源名称
+	dns1123Label = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// paramReference 引用参数的变量，如 $(params.url)
+	paramReference = regexp.MustCompile(`\$\(params\.([^)]+)\)`)
+	// workspaceReference 引用工作空间路径的变量，如 $(workspaces.source.path)
+	workspaceReference = regexp.MustCompile(`\$\(workspaces\.([^.)]+)\.path\)`)
+)
+
+// PipelineBundle 一次构建提交到集群的全部资源：镜像仓库凭据、Task、Pipeline与PipelineRun
+type PipelineBundle struct {
+	Namespace   string
+	Secret      *Secret // 不产出镜像时为nil
+	Tasks       []*Task
+	Pipeline    *Pipeline
+	PipelineRun *PipelineRun
+}
+
+// NewPipelineBundle 按构建方法与镜像构建工具生成构建资源
+func NewPipelineBundle(namespace string, run *BuildRun) *PipelineBundle {
+	method, builder := run.Build.BuildMethod, run.Config.ImageBuilder
+	bundle := &PipelineBundle{
+		Namespace:   namespace,
+		Tasks:       []*Task{newCloneTask(namespace), newCompileTask(namespace, method), newTestTask(namespace, method)},
+		Pipeline:    NewPipeline(namespace, method, builder),
+		PipelineRun: NewPipelineRun(namespace, run),
+	}
+	if method.HasImageBuild() {
+		bundle.Tasks = append(bundle.Tasks, newImageTask(namespace, builder))
+		if run.Registry != nil {
+			bundle.Secret = NewRegistrySecret(namespace, run.Registry)
+		}
+	}
+	return bundle
+}
+
+// NewPipeline 生成构建方法的Pipeline：拉取代码 -> 编译 -> 测试 -> 构建并推送镜像
+func NewPipeline(namespace string, method enum.BuildMethod, builder string) *Pipeline {
+	pipeline := &Pipeline{
+		APIVersion: TektonAPIVersion,
+		Kind:       KindPipeline,
+		Metadata:   ObjectMeta{Name: PipelineName(method, builder), Namespace: namespace},
+		Spec: PipelineSpec{
+			Description: fmt.Sprintf("%s 构建流水线", method.String()),
+			Params: []ParamSpec{
+				{Name: ParamGitURL, Type: "string", Description: "代码仓库地址"},
+				{Name: ParamGitRevision, Type: "string", Description: "分支或提交SHA"},
+			},
+			Workspaces: []WorkspaceDeclaration{{Name: WorkspaceSource, Description: "源码"}},
+			Tasks: []PipelineTask{
+				{
+					Name:    TaskClone,
+					TaskRef: &TaskRef{Name: CloneTaskName},
+					Params: []Param{
+						{Name: taskParamURL, Value: "$(params." + ParamGitURL + ")"},
+						{Name: taskParamRevision, Value: "$(params." + ParamGitRevision + ")"},
+					},
+					Workspaces: []WorkspacePipelineTaskBinding{{Name: taskWorkspaceOutput, Workspace: WorkspaceSource}},
+				},
+				{
+					Name:       TaskBuild,
+					TaskRef:    &TaskRef{Name: CompileTaskName(method)},
+					RunAfter:   []string{TaskClone},
+					Workspaces: []WorkspacePipelineTaskBinding{{Name: taskWorkspaceSource, Workspace: WorkspaceSource}},
+				},
+				{
+					Name:       TaskTest,
+					TaskRef:    &TaskRef{Name: TestTaskName(method)},
+					RunAfter:   []string{TaskBuild},
+					Workspaces: []WorkspacePipelineTaskBinding{{Name: taskWorkspaceSource, Workspace: WorkspaceSource}},
+				},
+			},
+		},
+	}
+	if !method.HasImageBuild() {
+		return pipeline
+	}
+
+	spec := &pipeline.Spec
+	spec.Params = append(spec.Params,
+		ParamSpec{Name: ParamImage, Type: "string", Description: "推送的镜像地址"},
+		ParamSpec{Name: ParamDockerfile, Type: "string", Description: "Dockerfile路径", Default: stringPtr(DefaultDockerfile)},
+		ParamSpec{Name: ParamContext, Type: "string", Description: "构建上下文目录", Default: stringPtr(DefaultContextDir)},
+	)
+	spec.Workspaces = append(spec.Workspaces, WorkspaceDeclaration{Name: WorkspaceDockerConfig, Description: "镜像仓库凭据"})
+	spec.Tasks = append(spec.Tasks, PipelineTask{
+		Name:     TaskImage,
+		TaskRef:  &TaskRef{Name: ImageTaskName(builder)},
+		RunAfter: []string{TaskTest},
+		Params: []Param{
+			{Name: ParamImage, Value: "$(params." + ParamImage + ")"},
+			{Name: ParamDockerfile, Value: "$(params." + ParamDockerfile + ")"},
+			{Name: ParamContext, Value: "$(params." + ParamContext + ")"},
+		},
+		Workspaces: []WorkspacePipelineTaskBinding{
+			{Name: taskWorkspaceSource, Workspace: WorkspaceSource},
+			{Name: taskWorkspaceDocker, Workspace: WorkspaceDockerConfig},
+		},
+	})
+	return pipeline
+}
+
+// newCloneTask 拉取代码到output工作空间，只拉取指定版本
+func newCloneTask(namespace string) *Task {
+	return newTask(namespace, CloneTaskName, TaskSpec{
+		Description: "拉取代码",
+		Params: []ParamSpec{
+			{Name: taskParamURL, Type: "string", Description: "代码仓库地址"},
+			{Name: taskParamRevision, Type: "string", Description: "分支或提交SHA"},
+		},
+		Workspaces: []WorkspaceDeclaration{{Name: taskWorkspaceOutput}},
+		Steps: []Step{{
+			Name:       TaskClone,
+			Image:      imageGit,
+			WorkingDir: "$(workspaces." + taskWorkspaceOutput + ".path)",
+			Script: shellScript(
+				"git init -q .",
+				`git remote add origin "$(params.`+taskParamURL+`)"`,
+				`git fetch -q --depth 1 origin "$(params.`+taskParamRevision+`)"`,
+				"git checkout -q FETCH_HEAD",
+			),
+		}},
+	})
+}
+
+// newCompileTask 构建方法的编译任务
+func newCompileTask(namespace string, method enum.BuildMethod) *Task {
+	tools := toolchains[method]
+	return newSourceTask(namespace, CompileTaskName(method), method.String()+" 编译", TaskBuild, tools.image, tools.build)
+}
+
+// newTestTask 构建方法的单元测试任务
+func newTestTask(namespace string, method enum.BuildMethod) *Task {
+	tools := toolchains[method]
+	return newSourceTask(namespace, TestTaskName(method), method.String()+" 单元测试", TaskTest, tools.image, tools.test)
+}
+
+// newSourceTask 在源码工作空间中执行命令的任务
+func newSourceTask(namespace, name, description, step, image, commands string) *Task {
+	return newTask(namespace, name, TaskSpec{
+		Description: description,
+		Workspaces:  []WorkspaceDeclaration{{Name: taskWorkspaceSource}},
+		Steps: []Step{{
+			Name:       step,
+			Image:      image,
+			WorkingDir: "$(workspaces." + taskWorkspaceSource + ".path)",
+			Script:     shellScript(strings.Split(commands, "\n")...),
+		}},
+	})
+}
+
+// newImageTask 构建并推送镜像的任务，kaniko无需特权，buildah使用特权容器
+func newImageTask(namespace, builder string) *Task {
+	spec := TaskSpec{
+		Description: "构建并推送镜像",
+		Params: []ParamSpec{
+			{Name: ParamImage, Type: "string", Description: "推送的镜像地址"},
+			{Name: ParamDockerfile, Type: "string", Default: stringPtr(DefaultDockerfile)},
+			{Name: ParamContext, Type: "string", Default: stringPtr(DefaultContextDir)},
+		},
+		Workspaces: []WorkspaceDeclaration{
+			{Name: taskWorkspaceSource},
+			{Name: taskWorkspaceDocker, ReadOnly: true},
+		},
+	}
+	source := "$(workspaces." + taskWorkspaceSource + ".path)"
+	dockerConfig := "$(workspaces." + taskWorkspaceDocker + ".path)"
+	switch builder {
+	case ImageBuilderBuildah:
+		spec.Steps = []Step{{
+			Name:       TaskImage,
+			Image:      imageBuildah,
+			WorkingDir: source,
+			Script: shellScript(
+				`buildah --storage-driver=vfs bud --format=docker -f "$(params.`+ParamDockerfile+`)" -t "$(params.`+ParamImage+`)" "$(params.`+ParamContext+`)"`,
+				`buildah --storage-driver=vfs push --authfile "`+dockerConfig+"/"+DockerConfigFile+`" "$(params.`+ParamImage+`)" "docker://$(params.`+ParamImage+`)"`,
+			),
+			SecurityContext: &SecurityContext{Privileged: boolPtr(true)},
+		}}
+	default:
+		spec.Steps = []Step{{
+			Name:  TaskImage,
+			Image: imageKaniko,
+			Env:   []EnvVar{{Name: "DOCKER_CONFIG", Value: dockerConfig}},
+			Args: []string{
+				"--dockerfile=" + source + "/$(params." + ParamDockerfile + ")",
+				"--context=" + source + "/$(params." + ParamContext + ")",
+				"--destination=$(params." + ParamImage + ")",
+			},
+		}}
+	}
+	return newTask(namespace, ImageTaskName(builder), spec)
+}
+
+func newTask(namespace, name string, spec TaskSpec) *Task {
+	return &Task{
+		APIVersion: TektonAPIVersion,
+		Kind:       KindTask,
+		Metadata:   ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       spec,
+	}
+}
+
+// NewRegistrySecret 生成镜像仓库的docker config凭据
+func NewRegistrySecret(namespace string, registry *Registry) *Secret {
+	auth := map[string]string{
+		"username": registry.Username,
+		"password": registry.Password,
+		"auth":     base64.StdEncoding.EncodeToString([]byte(registry.Username + ":" + registry.Password)),
+	}
+	if registry.Email != "" {
+		auth["email"] = registry.Email
+	}
+	config, _ := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{RegistryHost(registry.URL): auth},
+	})
+	return &Secret{
+		APIVersion: SecretAPIVersion,
+		Kind:       KindSecret,
+		Metadata:   ObjectMeta{Name: RegistrySecretName(registry.ID), Namespace: namespace},
+		Type:       SecretTypeDockerConfig,
+		Data:       map[string]string{DockerConfigKey: base64.StdEncoding.EncodeToString(config)},
+	}
+}
+
+// Validate 校验资源之间的引用关系，返回全部问题，为空表示可以提交
+func (b *PipelineBundle) Validate() []string {
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	tasks := make(map[string]*Task, len(b.Tasks))
+	for _, task := range b.Tasks {
+		validateName(report, "Task", task.Metadata.Name)
+		tasks[task.Metadata.Name] = task
+		validateTask(report, task)
+	}
+	if b.Pipeline == nil {
+		report("缺少Pipeline")
+		return problems
+	}
+	validateName(report, "Pipeline", b.Pipeline.Metadata.Name)
+	validatePipeline(report, b.Pipeline, tasks)
+	if b.PipelineRun == nil {
+		report("缺少PipelineRun")
+		return problems
+	}
+	validateName(report, "PipelineRun", b.PipelineRun.Metadata.Name)
+	b.validatePipelineRun(report)
+
+	for _, resource := range b.resources() {
+		if namespace := resourceNamespace(resource); namespace != b.Namespace {
+			report("资源命名空间 %s 与 %s 不一致", namespace, b.Namespace)
+		}
+	}
+	return problems
+}
+
+// validateTask 校验步骤中引用的参数与工作空间均已声明
+func validateTask(report func(string, ...interface{}), task *Task) {
+	name := task.Metadata.Name
+	if len(task.Spec.Steps) == 0 {
+		report("Task %s 没有步骤", name)
+	}
+	params := declaredParams(task.Spec.Params)
+	workspaces := declaredWorkspaces(task.Spec.Workspaces)
+	for _, step := range task.Spec.Steps {
+		if step.Image == "" {
+			report("Task %s 步骤 %s 未指定镜像", name, step.Name)
+		}
+		values := append([]string{step.Script, step.WorkingDir}, step.Args...)
+		for _, env := range step.Env {
+			values = append(values, env.Value)
+		}
+		for _, value := range values {
+			for _, ref := range references(paramReference, value) {
+				if _, ok := params[ref]; !ok {
+					report("Task %s 步骤 %s 引用了未声明的参数 %s", name, step.Name, ref)
+				}
+			}
+			for _, ref := range references(workspaceReference, value) {
+				if !workspaces[ref] {
+					report("Task %s 步骤 %s 引用了未声明的工作空间 %s", name, step.Name, ref)
+				}
+			}
+		}
+	}
+}
+
+// validatePipeline 校验Pipeline任务引用的Task、参数、工作空间与执行顺序
+func validatePipeline(report func(string, ...interface{}), pipeline *Pipeline, tasks map[string]*Task) {
+	params := declaredParams(pipeline.Spec.Params)
+	workspaces := declaredWorkspaces(pipeline.Spec.Workspaces)
+	names := make(map[string]bool, len(pipeline.Spec.Tasks))
+	for _, pipelineTask := range pipeline.Spec.Tasks {
+		names[pipelineTask.Name] = true
+	}
+
+	for _, pipelineTask := range pipeline.Spec.Tasks {
+		for _, after := range pipelineTask.RunAfter {
+			if !names[after] || after == pipelineTask.Name {
+				report("任务 %s 的runAfter引用了无效的任务 %s", pipelineTask.Name, after)
+			}
+		}
+		provided := make(map[string]bool, len(pipelineTask.Params))
+		for _, param := range pipelineTask.Params {
+			provided[param.Name] = true
+			for _, ref := range references(paramReference, param.Value) {
+				if _, ok := params[ref]; !ok {
+					report("任务 %s 引用了Pipeline未声明的参数 %s", pipelineTask.Name, ref)
+				}
+			}
+		}
+		bound := make(map[string]bool, len(pipelineTask.Workspaces))
+		for _, binding := range pipelineTask.Workspaces {
+			bound[binding.Name] = true
+			if !workspaces[binding.Workspace] {
+				report("任务 %s 绑定了Pipeline未声明的工作空间 %s", pipelineTask.Name, binding.Workspace)
+			}
+		}
+
+		if pipelineTask.TaskRef == nil {
+			report("任务 %s 未引用Task", pipelineTask.Name)
+			continue
+		}
+		task, ok := tasks[pipelineTask.TaskRef.Name]
+		if !ok {
+			report("任务 %s 引用的Task %s 不存在", pipelineTask.Name, pipelineTask.TaskRef.Name)
+			continue
+		}
+		taskParams := declaredParams(task.Spec.Params)
+		for name := range provided {
+			if _, ok := taskParams[name]; !ok {
+				report("任务 %s 传入了Task %s 未声明的参数 %s", pipelineTask.Name, task.Metadata.Name, name)
+			}
+		}
+		for name, required := range taskParams {
+			if required && !provided[name] {
+				report("任务 %s 缺少Task %s 的必填参数 %s", pipelineTask.Name, task.Metadata.Name, name)
+			}
+		}
+		taskWorkspaces := declaredWorkspaces(task.Spec.Workspaces)
+		for name := range bound {
+			if !taskWorkspaces[name] {
+				report("任务 %s 绑定了Task %s 未声明的工作空间 %s", pipelineTask.Name, task.Metadata.Name, name)
+			}
+		}
+		for name := range taskWorkspaces {
+			if !bound[name] {
+				report("任务 %s 未绑定Task %s 的工作空间 %s", pipelineTask.Name, task.Metadata.Name, name)
+			}
+		}
+	}
+}
+
+// validatePipelineRun 校验PipelineRun提供了Pipeline的必填参数与全部工作空间，凭据Secret存在且格式正确
+func (b *PipelineBundle) validatePipelineRun(report func(string, ...interface{})) {
+	run, pipeline := b.PipelineRun, b.Pipeline
+	if run.Spec.PipelineRef == nil || run.Spec.PipelineRef.Name != pipeline.Metadata.Name {
+		report("PipelineRun 未引用Pipeline %s", pipeline.Metadata.Name)
+	}
+
+	params := declaredParams(pipeline.Spec.Params)
+	provided := make(map[string]bool, len(run.Spec.Params))
+	for _, param := range run.Spec.Params {
+		provided[param.Name] = true
+		if _, ok := params[param.Name]; !ok {
+			report("PipelineRun 传入了Pipeline未声明的参数 %s", param.Name)
+		}
+	}
+	for name, required := range params {
+		if required && !provided[name] {
+			report("PipelineRun 缺少必填参数 %s", name)
+		}
+	}
+
+	workspaces := declaredWorkspaces(pipeline.Spec.Workspaces)
+	bound := make(map[string]bool, len(run.Spec.Workspaces))
+	for _, binding := range run.Spec.Workspaces {
+		bound[binding.Name] = true
+		if !workspaces[binding.Name] {
+			report("PipelineRun 绑定了Pipeline未声明的工作空间 %s", binding.Name)
+		}
+		if binding.Secret != nil {
+			b.validateSecret(report, binding.Secret)
+		}
+	}
+	for name := range workspaces {
+		if !bound[name] {
+			report("PipelineRun 未绑定工作空间 %s", name)
+		}
+	}
+}
+
+// validateSecret 校验工作空间引用的凭据Secret
+func (b *PipelineBundle) validateSecret(report func(string, ...interface{}), volume *SecretVolume) {
+	secret := b.Secret
+	if secret == nil || secret.Metadata.Name != volume.SecretName {
+		report("工作空间引用的Secret %s 不存在", volume.SecretName)
+		return
+	}
+	for _, item := range volume.Items {
+		if _, ok := secret.Data[item.Key]; !ok {
+			report("Secret %s 缺少键 %s", secret.Metadata.Name, item.Key)
+		}
+	}
+	data, ok := secret.Data[DockerConfigKey]
+	if !ok || data == MaskedValue {
+		return
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	var config struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+	if err != nil || json.Unmarshal(decoded, &config) != nil || len(config.Auths) == 0 {
+		report("Secret %s 不是有效的docker config", secret.Metadata.Name)
+	}
+}
+
+// Render 生成多文档YAML，mask为true时隐藏凭据内容
+func (b *PipelineBundle) Render(mask bool) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, resource := range b.resources() {
+		if secret, ok := resource.(*Secret); ok && mask {
+			masked := *secret
+			masked.Data = make(map[string]string, len(secret.Data))
+			for key := range secret.Data {
+				masked.Data[key] = MaskedValue
+			}
+			resource = &masked
+		}
+		data, err := kube.MarshalYAML(resource)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// resources 按提交顺序列出资源：Secret、Task、Pipeline、PipelineRun
+func (b *PipelineBundle) resources() []interface{} {
+	resources := make([]interface{}, 0, len(b.Tasks)+3)
+	if b.Secret != nil {
+		resources = append(resources, b.Secret)
+	}
+	for _, task := range b.Tasks {
+		resources = append(resources, task)
+	}
+	if b.Pipeline != nil {
+		resources = append(resources, b.Pipeline)
+	}
+	if b.PipelineRun != nil {
+		resources = append(resources, b.PipelineRun)
+	}
+	return resources
+}
+
+func resourceNamespace(resource interface{}) string {
+	switch r := resource.(type) {
+	case *Secret:
+		return r.Metadata.Namespace
+	case *Task:
+		return r.Metadata.Namespace
+	case *Pipeline:
+		return r.Metadata.Namespace
+	case *PipelineRun:
+		return r.Metadata.Namespace
+	}
+	return ""
+}
+
+func validateName(report func(string, ...interface{}), kind, name string) {
+	if len(name) > MaxRunNameLength || !dns1123Label.MatchString(name) {
+		report("%s 名称 %q 不是有效的DNS-1123标签", kind, name)
+	}
+}
+
+// declaredParams 声明的参数及是否必填
+func declaredParams(params []ParamSpec) map[string]bool {
+	declared := make(map[string]bool, len(params))
+	for _, param := range params {
+		declared[param.Name] = param.Default == nil
+	}
+	return declared
+}
+
+func declaredWorkspaces(workspaces []WorkspaceDeclaration) map[string]bool {
+	declared := make(map[string]bool, len(workspaces))
+	for _, workspace := range workspaces {
+		declared[workspace.Name] = true
+	}
+	return declared
+}
+
+func references(pattern *regexp.Regexp, value string) []string {
+	matches := pattern.FindAllStringSubmatch(value, -1)
+	refs := make([]string, 0, len(matches))
+	for _, match := range matches {
+		refs = append(refs, match[1])
+	}
+	return refs
+}
+
+// shellScript 拼接出错即退出的shell脚本
+func shellScript(commands ...string) string {
+	return "#!/bin/sh\nset -eu\n" + strings.Join(commands, "\n") + "\n"
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
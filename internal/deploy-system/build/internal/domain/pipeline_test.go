@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"bytes"
+	"devops-platform/internal/pkg/enum"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新golden文件")
+
+// testRun 固定的构建上下文，产出镜像的方法使用测试镜像仓库凭据
+func testRun(method enum.BuildMethod, builder string) *BuildRun {
+	run := &BuildRun{
+		AppName: "Demo-App",
+		Config: &BuildConfig{
+			RepoURL:         "https://git.example.com/team/demo-app.git",
+			BuildMethod:     method,
+			DockerfilePath:  DefaultDockerfile,
+			ContextDir:      DefaultContextDir,
+			RegistryID:      7,
+			ImageRepository: "team/demo-app",
+			ImageBuilder:    builder,
+		},
+		Build: &Build{
+			AppID:       5,
+			Branch:      "release/1.0",
+			Commit:      "9fceb02d0ae598e95dc970b74767f19372d61af8",
+			BuildMethod: method,
+		},
+	}
+	run.Build.ID = 12
+	if method.HasImageBuild() {
+		run.Registry = &Registry{ID: 7, URL: "https://harbor.example.com/", Username: "robot", Password: "s3cret", Email: "ci@example.com"}
+	}
+	run.SetNumber(3)
+	return run
+}
+
+// goldenCases 每个构建方法一个用例，产出镜像的方法按镜像构建工具各一个
+func goldenCases() map[string]*BuildRun {
+	cases := make(map[string]*BuildRun)
+	for _, method := range BuildMethods {
+		if !method.HasImageBuild() {
+			cases[MethodKey(method)] = testRun(method, "")
+			continue
+		}
+		for _, builder := range ImageBuilders {
+			cases[MethodKey(method)+"-"+builder] = testRun(method, builder)
+		}
+	}
+	return cases
+}
+
+func TestPipelineBundleGolden(t *testing.T) {
+	for name, run := range goldenCases() {
+		t.Run(name, func(t *testing.T) {
+			bundle := NewPipelineBundle("devops", run)
+			if problems := bundle.Validate(); len(problems) > 0 {
+				t.Fatalf("模板校验失败: %v", problems)
+			}
+			manifest, err := bundle.Render(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", name+".yaml")
+			if *update {
+				if err = os.WriteFile(golden, manifest, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("读取golden文件失败（使用 -update 生成）: %v", err)
+			}
+			if !bytes.Equal(manifest, expected) {
+				t.Errorf("生成的定义与 %s 不一致:\n%s", golden, manifest)
+			}
+		})
+	}
+}
+
+func TestPipelineBundleRenderMasksSecret(t *testing.T) {
+	bundle := NewPipelineBundle("devops", testRun(enum.BuildMethodGolang, ImageBuilderKaniko))
+	manifest, err := bundle.Render(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(manifest, []byte(bundle.Secret.Data[DockerConfigKey])) {
+		t.Fatal("预览中不应包含凭据内容")
+	}
+	if !bytes.Contains(manifest, []byte(MaskedValue)) {
+		t.Fatal("预览中的凭据应显示为掩码")
+	}
+	// 掩码不影响提交的Secret
+	if bundle.Secret.Data[DockerConfigKey] == MaskedValue {
+		t.Fatal("渲染预览不应修改Secret")
+	}
+	if problems := bundle.Validate(); len(problems) > 0 {
+		t.Fatalf("模板校验失败: %v", problems)
+	}
+}
+
+func TestPipelineBundleValidate(t *testing.T) {
+	cases := map[string]struct {
+		modify   func(bundle *PipelineBundle)
+		expected string
+	}{
+		"缺少Task": {
+			modify:   func(b *PipelineBundle) { b.Tasks = b.Tasks[:len(b.Tasks)-1] },
+			expected: "引用的Task devops-image-kaniko 不存在",
+		},
+		"步骤引用未声明的参数": {
+			modify:   func(b *PipelineBundle) { b.Tasks[0].Spec.Steps[0].Script += "echo $(params.missing)\n" },
+			expected: "引用了未声明的参数 missing",
+		},
+		"步骤引用未声明的工作空间": {
+			modify:   func(b *PipelineBundle) { b.Tasks[1].Spec.Steps[0].WorkingDir = "$(workspaces.cache.path)" },
+			expected: "引用了未声明的工作空间 cache",
+		},
+		"缺少Task必填参数": {
+			modify:   func(b *PipelineBundle) { b.Pipeline.Spec.Tasks[0].Params = b.Pipeline.Spec.Tasks[0].Params[:1] },
+			expected: "缺少Task devops-git-clone 的必填参数 revision",
+		},
+		"runAfter引用不存在的任务": {
+			modify:   func(b *PipelineBundle) { b.Pipeline.Spec.Tasks[1].RunAfter = []string{"checkout"} },
+			expected: "runAfter引用了无效的任务 checkout",
+		},
+		"未绑定Task工作空间": {
+			modify:   func(b *PipelineBundle) { b.Pipeline.Spec.Tasks[3].Workspaces = b.Pipeline.Spec.Tasks[3].Workspaces[:1] },
+			expected: "未绑定Task devops-image-kaniko 的工作空间 dockerconfig",
+		},
+		"PipelineRun缺少必填参数": {
+			modify:   func(b *PipelineBundle) { b.PipelineRun.Spec.Params = b.PipelineRun.Spec.Params[1:] },
+			expected: "PipelineRun 缺少必填参数 git-url",
+		},
+		"PipelineRun未绑定工作空间": {
+			modify:   func(b *PipelineBundle) { b.PipelineRun.Spec.Workspaces = b.PipelineRun.Spec.Workspaces[:1] },
+			expected: "PipelineRun 未绑定工作空间 docker-config",
+		},
+		"缺少凭据Secret": {
+			modify:   func(b *PipelineBundle) { b.Secret = nil },
+			expected: "Secret registry-7 不存在",
+		},
+		"凭据格式错误": {
+			modify:   func(b *PipelineBundle) { b.Secret.Data[DockerConfigKey] = "bm90LWpzb24=" },
+			expected: "不是有效的docker config",
+		},
+		"名称无效": {
+			modify:   func(b *PipelineBundle) { b.PipelineRun.Metadata.Name = "Demo_App" },
+			expected: "不是有效的DNS-1123标签",
+		},
+		"命名空间不一致": {
+			modify:   func(b *PipelineBundle) { b.Pipeline.Metadata.Namespace = "default" },
+			expected: "资源命名空间 default 与 devops 不一致",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			bundle := NewPipelineBundle("devops", testRun(enum.BuildMethodGolang, ImageBuilderKaniko))
+			c.modify(bundle)
+			problems := bundle.Validate()
+			for _, problem := range problems {
+				if strings.Contains(problem, c.expected) {
+					return
+				}
+			}
+			t.Fatalf("校验结果应包含 %q，实际 %v", c.expected, problems)
+		})
+	}
+}
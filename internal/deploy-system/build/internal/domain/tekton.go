@@ -0,0 +1,317 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/enum"
+	"time"
+)
+
+// Tekton资源定义，只包含平台生成与读取的字段
+const (
+	TektonAPIVersion = "tekton.dev/v1"
+	KindTask         = "Task"
+	KindPipeline     = "Pipeline"
+	KindPipelineRun  = "PipelineRun"
+	KindTaskRun      = "TaskRun"
+
+	// Secret资源
+	SecretAPIVersion       = "v1"
+	KindSecret             = "Secret"
+	SecretTypeDockerConfig = "kubernetes.io/dockerconfigjson"
+
+	// ConditionSucceeded PipelineRun/TaskRun的完成状态条件
+	ConditionSucceeded = "Succeeded"
+
+	// PipelineRunSpecStatusCancelled 取消PipelineRun时写入spec.status的值
+	PipelineRunSpecStatusCancelled = "Cancelled"
+)
+
+// ObjectMeta 资源元数据
+type ObjectMeta struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Task Tekton Task
+type Task struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       TaskSpec   `json:"spec"`
+}
+
+// TaskSpec Task定义
+type TaskSpec struct {
+	Description string                 `json:"description,omitempty"`
+	Params      []ParamSpec            `json:"params,omitempty"`
+	Workspaces  []WorkspaceDeclaration `json:"workspaces,omitempty"`
+	Steps       []Step                 `json:"steps"`
+}
+
+// ParamSpec 参数声明，Default为nil表示必填
+type ParamSpec struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Default     *string `json:"default,omitempty"`
+}
+
+// WorkspaceDeclaration 工作空间声明
+type WorkspaceDeclaration struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ReadOnly    bool   `json:"readOnly,omitempty"`
+}
+
+// Step Task中的容器步骤
+type Step struct {
+	Name            string           `json:"name"`
+	Image           string           `json:"image"`
+	WorkingDir      string           `json:"workingDir,omitempty"`
+	Env             []EnvVar         `json:"env,omitempty"`
+	Args            []string         `json:"args,omitempty"`
+	Script          string           `json:"script,omitempty"`
+	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
+}
+
+// EnvVar 环境变量
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SecurityContext 容器安全设置
+type SecurityContext struct {
+	Privileged *bool `json:"privileged,omitempty"`
+}
+
+// Pipeline Tekton Pipeline
+type Pipeline struct {
+	APIVersion string       `json:"apiVersion"`
+	Kind       string       `json:"kind"`
+	Metadata   ObjectMeta   `json:"metadata"`
+	Spec       PipelineSpec `json:"spec"`
+}
+
+// PipelineSpec Pipeline定义
+type PipelineSpec struct {
+	Description string                 `json:"description,omitempty"`
+	Params      []ParamSpec            `json:"params,omitempty"`
+	Workspaces  []WorkspaceDeclaration `json:"workspaces,omitempty"`
+	Tasks       []PipelineTask         `json:"tasks"`
+}
+
+// PipelineTask Pipeline中的任务
+type PipelineTask struct {
+	Name       string                         `json:"name"`
+	TaskRef    *TaskRef                       `json:"taskRef"`
+	RunAfter   []string                       `json:"runAfter,omitempty"`
+	Params     []Param                        `json:"params,omitempty"`
+	Workspaces []WorkspacePipelineTaskBinding `json:"workspaces,omitempty"`
+}
+
+// TaskRef 引用的Task
+type TaskRef struct {
+	Name string `json:"name"`
+}
+
+// WorkspacePipelineTaskBinding 将Pipeline工作空间绑定到任务的工作空间
+type WorkspacePipelineTaskBinding struct {
+	Name      string `json:"name"`
+	Workspace string `json:"workspace"`
+}
+
+// Secret Kubernetes Secret，Data为base64编码的值
+type Secret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   ObjectMeta        `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string]string `json:"data"`
+}
+
+// PipelineRun Tekton PipelineRun
+type PipelineRun struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   ObjectMeta         `json:"metadata"`
+	Spec       PipelineRunSpec    `json:"spec"`
+	Status     *PipelineRunStatus `json:"status,omitempty"`
+}
+
+// PipelineRunSpec PipelineRun定义
+type PipelineRunSpec struct {
+	PipelineRef     *PipelineRef       `json:"pipelineRef,omitempty"`
+	Params          []Param            `json:"params,omitempty"`
+	Workspaces      []WorkspaceBinding `json:"workspaces,omitempty"`
+	TaskRunTemplate *TaskRunTemplate   `json:"taskRunTemplate,omitempty"`
+	Timeouts        *Timeouts          `json:"timeouts,omitempty"`
+	Status          string             `json:"status,omitempty"`
+}
+
+// PipelineRef 引用的Pipeline
+type PipelineRef struct {
+	Name string `json:"name"`
+}
+
+// Param 参数值
+type Param struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// WorkspaceBinding 工作空间绑定
+type WorkspaceBinding struct {
+	Name                string               `json:"name"`
+	Secret              *SecretVolume        `json:"secret,omitempty"`
+	VolumeClaimTemplate *VolumeClaimTemplate `json:"volumeClaimTemplate,omitempty"`
+}
+
+// SecretVolume 以Secret作为工作空间
+type SecretVolume struct {
+	SecretName string      `json:"secretName"`
+	Items      []KeyToPath `json:"items,omitempty"`
+}
+
+// KeyToPath Secret键挂载为文件
+type KeyToPath struct {
+	Key  string `json:"key"`
+	Path string `json:"path"`
+}
+
+// VolumeClaimTemplate 为每次运行创建的临时PVC
+type VolumeClaimTemplate struct {
+	Spec VolumeClaimSpec `json:"spec"`
+}
+
+// VolumeClaimSpec PVC定义
+type VolumeClaimSpec struct {
+	AccessModes []string         `json:"accessModes"`
+	Resources   ResourceRequests `json:"resources"`
+}
+
+// ResourceRequests 资源请求
+type ResourceRequests struct {
+	Requests map[string]string `json:"requests"`
+}
+
+// TaskRunTemplate 任务运行模板
+type TaskRunTemplate struct {
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// Timeouts 超时设置
+type Timeouts struct {
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+// Condition 资源状态条件
+type Condition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// PipelineRunStatus PipelineRun状态
+type PipelineRunStatus struct {
+	Conditions      []Condition      `json:"conditions,omitempty"`
+	StartTime       *time.Time       `json:"startTime,omitempty"`
+	CompletionTime  *time.Time       `json:"completionTime,omitempty"`
+	ChildReferences []ChildReference `json:"childReferences,omitempty"`
+}
+
+// ChildReference PipelineRun创建的子资源
+type ChildReference struct {
+	Kind             string `json:"kind"`
+	Name             string `json:"name"`
+	PipelineTaskName string `json:"pipelineTaskName"`
+}
+
+// TaskRun Tekton TaskRun，只读取状态
+type TaskRun struct {
+	Metadata ObjectMeta     `json:"metadata"`
+	Status   *TaskRunStatus `json:"status,omitempty"`
+}
+
+// TaskRunStatus TaskRun状态
+type TaskRunStatus struct {
+	Conditions     []Condition `json:"conditions,omitempty"`
+	StartTime      *time.Time  `json:"startTime,omitempty"`
+	CompletionTime *time.Time  `json:"completionTime,omitempty"`
+}
+
+// NewPipelineRun 为构建生成PipelineRun：引用构建方法与镜像构建工具对应的Pipeline，源码使用临时PVC，镜像仓库凭据以Secret挂载
+func NewPipelineRun(namespace string, run *BuildRun) *PipelineRun {
+	build, config := run.Build, run.Config
+	revision := build.Commit
+	if revision == "" {
+		revision = build.Branch
+	}
+
+	pipelineRun := &PipelineRun{
+		APIVersion: TektonAPIVersion,
+		Kind:       KindPipelineRun,
+		Metadata: ObjectMeta{
+			Name:      RunName(run.AppName, build.ID),
+			Namespace: namespace,
+			Labels: map[string]string{
+				LabelAppID:   build.AppID.String(),
+				LabelBuildID: build.ID.String(),
+			},
+		},
+		Spec: PipelineRunSpec{
+			PipelineRef: &PipelineRef{Name: PipelineName(build.BuildMethod, config.ImageBuilder)},
+			Params: []Param{
+				{Name: ParamGitURL, Value: config.RepoURL},
+				{Name: ParamGitRevision, Value: revision},
+			},
+			Workspaces: []WorkspaceBinding{{
+				Name: WorkspaceSource,
+				VolumeClaimTemplate: &VolumeClaimTemplate{Spec: VolumeClaimSpec{
+					AccessModes: []string{"ReadWriteOnce"},
+					Resources:   ResourceRequests{Requests: map[string]string{"storage": SourceStorage}},
+				}},
+			}},
+		},
+	}
+	if build.BuildMethod.HasImageBuild() {
+		pipelineRun.Spec.Params = append(pipelineRun.Spec.Params,
+			Param{Name: ParamImage, Value: build.Image},
+			Param{Name: ParamDockerfile, Value: config.DockerfilePath},
+			Param{Name: ParamContext, Value: config.ContextDir},
+		)
+	}
+	if build.BuildMethod.HasImageBuild() && run.Registry != nil {
+		pipelineRun.Spec.Workspaces = append(pipelineRun.Spec.Workspaces, WorkspaceBinding{
+			Name: WorkspaceDockerConfig,
+			Secret: &SecretVolume{
+				SecretName: RegistrySecretName(run.Registry.ID),
+				Items:      []KeyToPath{{Key: DockerConfigKey, Path: DockerConfigFile}},
+			},
+		})
+	}
+	return pipelineRun
+}
+
+// BuildStatusOf 根据Succeeded条件换算构建状态：True成功，False失败或取消，其余为构建中
+func BuildStatusOf(conditions []Condition) (enum.BuildStatus, string) {
+	for _, condition := range conditions {
+		if condition.Type != ConditionSucceeded {
+			continue
+		}
+		switch condition.Status {
+		case "True":
+			return enum.BuildStatusSuccess, condition.Message
+		case "False":
+			switch condition.Reason {
+			case "Cancelled", "PipelineRunCancelled", "TaskRunCancelled", "CancelledRunFinally", "StoppedRunFinally":
+				return enum.BuildStatusCanceled, condition.Message
+			}
+			return enum.BuildStatusFail, condition.Message
+		}
+		return enum.BuildStatusBuilding, condition.Message
+	}
+	return enum.BuildStatusBuilding, ""
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"devops-platform/internal/common/repository"
+	"devops-platform/internal/deploy-system/build/internal/domain"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/pkg/types"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Repository 构建仓储
+type Repository struct {
+	repository.Repository
+}
+
+// NewRepository 创建仓储实例
+func NewRepository() *Repository {
+	return &Repository{}
+}
+
+// GetConfigByAppID 获取应用的构建配置，不存在时返回nil
+func (r *Repository) GetConfigByAppID(ctx context.Context, appID types.Long) (*domain.BuildConfig, error) {
+	var config domain.BuildConfig
+	err := r.DB(ctx).Where("app_id = ?", appID).First(&config).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SaveConfig 保存构建配置
+func (r *Repository) SaveConfig(ctx context.Context, config *domain.BuildConfig) error {
+	return r.DB(ctx).Save(config).Error
+}
+
+// GetMaxBuildNumber 获取应用当前最大的构建序号，没有构建时返回0
+func (r *Repository) GetMaxBuildNumber(ctx context.Context, appID types.Long) (int, error) {
+	var number int
+	err := r.DB(ctx).Model(&domain.Build{}).
+		Where("app_id = ?", appID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&number).Error
+	return number, err
+}
+
+// CreateBuild 创建构建记录及其步骤
+func (r *Repository) CreateBuild(ctx context.Context, build *domain.Build, steps []*domain.BuildStep) error {
+	if err := r.DB(ctx).Create(build).Error; err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	for _, step := range steps {
+		step.BuildID = build.ID
+	}
+	return r.DB(ctx).Create(&steps).Error
+}
+
+// GetBuildByID 根据ID获取构建记录，不存在时返回nil
+func (r *Repository) GetBuildByID(ctx context.Context, id types.Long) (*domain.Build, error) {
+	var build domain.Build
+	err := r.DB(ctx).First(&build, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &build, nil
+}
+
+// SaveBuild 保存构建记录
+func (r *Repository) SaveBuild(ctx context.Context, build *domain.Build) error {
+	return r.DB(ctx).Save(build).Error
+}
+
+// ListBuilds 分页查询应用的构建记录
+func (r *Repository) ListBuilds(ctx context.Context, query *domain.BuildQuery) ([]*domain.Build, int64, error) {
+	db := r.DB(ctx).Model(&domain.Build{}).Where("app_id = ?", query.AppID)
+	if query.Status != nil {
+		db = db.Where("status = ?", *query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var builds []*domain.Build
+	err := db.Order("id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&builds).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return builds, total, nil
+}
+
+// FindBuildsByStatus 查询指定状态的构建，按ID升序
+func (r *Repository) FindBuildsByStatus(ctx context.Context, status enum.BuildStatus, limit int) ([]*domain.Build, error) {
+	var builds []*domain.Build
+	err := r.DB(ctx).Where("status = ?", status).Order("id").Limit(limit).Find(&builds).Error
+	if err != nil {
+		return nil, err
+	}
+	return builds, nil
+}
+
+// GetSuccessfulBuildByImageTag 获取应用产出指定镜像tag的最近一次成功构建，不存在时返回nil
+func (r *Repository) GetSuccessfulBuildByImageTag(ctx context.Context, appID types.Long, imageTag string) (*domain.Build, error) {
+	var build domain.Build
+	err := r.DB(ctx).Where("app_id = ? AND image_tag = ? AND status = ?", appID, imageTag, enum.BuildStatusSuccess).
+		Order("number DESC").First(&build).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &build, nil
+}
+
+// ListSuccessfulBuildsBetween 查询应用构建序号在 (fromNumber, toNumber] 之间的成功构建，按序号倒序
+func (r *Repository) ListSuccessfulBuildsBetween(ctx context.Context, appID types.Long, fromNumber, toNumber int) ([]*domain.Build, error) {
+	var builds []*domain.Build
+	err := r.DB(ctx).Where("app_id = ? AND number > ? AND number <= ? AND status = ?", appID, fromNumber, toNumber, enum.BuildStatusSuccess).
+		Order("number DESC").Find(&builds).Error
+	return builds, err
+}
+
+// ListSteps 查询构建的步骤
+func (r *Repository) ListSteps(ctx context.Context, buildID types.Long) ([]*domain.BuildStep, error) {
+	var steps []*domain.BuildStep
+	if err := r.DB(ctx).Where("build_id = ?", buildID).Order("sort").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// SaveStep 保存构建步骤
+func (r *Repository) SaveStep(ctx context.Context, step *domain.BuildStep) error {
+	return r.DB(ctx).Save(step).Error
+}
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application"
+	"devops-platform/internal/deploy-system/build/internal/domain"
+	"devops-platform/internal/deploy-system/build/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/pkg/types"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BuildService 构建服务：管理构建配置，触发构建并同步状态，构建成功后为产出的镜像创建发布计划
+type BuildService struct {
+	service.Service
+	Repo          *repository.Repository     `inject:"BuildRepository"`
+	AppQuery      application.AppQueryServer `inject:"appQuery"`
+	DeployService application.DeployService  `inject:"deployService"`
+	Runners       map[string]Runner
+}
+
+func NewBuildService() *BuildService {
+	return &BuildService{}
+}
+
+// Inject 按domain.RunnerBeans装配构建执行器
+func (s *BuildService) Inject(getBean func(string) interface{}) {
+	s.Runners = make(map[string]Runner, len(domain.RunnerBeans))
+	for name, beanName := range domain.RunnerBeans {
+		runner, ok := getBean(beanName).(Runner)
+		if !ok {
+			logrus.Panicf("初始化时获取[%s]失败", beanName)
+			return
+		}
+		s.Runners[name] = runner
+	}
+}
+
+// ListBuildMethods 查询可构建的方法
+func (s *BuildService) ListBuildMethods() []*domain.BuildMethodVO {
+	methods := make([]*domain.BuildMethodVO, 0, len(domain.BuildMethods))
+	for _, method := range domain.BuildMethods {
+		vo := &domain.BuildMethodVO{
+			Value:    method,
+			Name:     method.String(),
+			HasImage: method.HasImageBuild(),
+			Tasks:    domain.PipelineTasks(method),
+		}
+		if vo.HasImage {
+			for _, builder := range domain.ImageBuilders {
+				vo.Pipelines = append(vo.Pipelines, domain.PipelineName(method, builder))
+			}
+		} else {
+			vo.Pipelines = []string{domain.PipelineName(method, "")}
+		}
+		methods = append(methods, vo)
+	}
+	return methods
+}
+
+// GetBuildConfig 获取应用的构建配置
+func (s *BuildService) GetBuildConfig(ctx context.Context, appID types.Long) (*domain.BuildConfig, error) {
+	config, err := s.Repo.GetConfigByAppID(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询构建配置失败", err)
+	}
+	if config == nil {
+		return nil, common.NotFoundError("应用未配置构建", nil)
+	}
+	return config, nil
+}
+
+// SaveBuildConfig 保存应用的构建配置，不存在时创建
+func (s *BuildService) SaveBuildConfig(ctx context.Context, command *domain.SaveBuildConfigCommand) (types.Long, error) {
+	app, err := s.AppQuery.GetApplicationByID(ctx, command.AppID)
+	if err != nil {
+		return 0, common.NotFoundError("应用不存在", err)
+	}
+	config, err := s.Repo.GetConfigByAppID(ctx, command.AppID)
+	if err != nil {
+		return 0, common.InternalError("查询构建配置失败", err)
+	}
+	if config == nil {
+		config = &domain.BuildConfig{}
+		config.AuditCreated(ctx)
+	} else {
+		config.AuditModified(ctx)
+	}
+
+	if err = command.ApplyTo(config); err != nil {
+		return 0, common.RequestParamError("", err)
+	}
+	if config.BuildMethod.HasImageBuild() {
+		if _, err = s.AppQuery.GetImageRegistryByID(ctx, config.RegistryID); err != nil {
+			return 0, common.RequestParamError("", errors.New("镜像仓库不存在"))
+		}
+		if config.ImageRepository == "" {
+			// 镜像名只能使用小写字母
+			config.ImageRepository = strings.ToLower(app.Name)
+		}
+	}
+	if config.DeployEnvID > 0 {
+		if _, err = s.AppQuery.GetAppEnvByID(ctx, config.DeployEnvID); err != nil {
+			return 0, common.RequestParamError("", errors.New("发布环境不存在"))
+		}
+	}
+
+	if err = s.Repo.SaveConfig(ctx, config); err != nil {
+		return 0, common.InternalError("保存构建配置失败", err)
+	}
+	return config.ID, nil
+}
+
+// SaveTemplateBuildConfig 保存按应用模板创建应用时的构建配置，在创建应用的事务中执行
+func (s *BuildService) SaveTemplateBuildConfig(ctx context.Context, config *application.TemplateBuildConfig) (types.Long, error) {
+	return s.SaveBuildConfig(ctx, &domain.SaveBuildConfigCommand{
+		AppID:           config.AppID,
+		RepoURL:         config.RepoURL,
+		BuildType:       config.BuildType,
+		Branch:          config.Branch,
+		BuildMethod:     config.BuildMethod,
+		DockerfilePath:  config.DockerfilePath,
+		ContextDir:      config.ContextDir,
+		RegistryID:      config.RegistryID,
+		ImageRepository: config.ImageRepository,
+	})
+}
+
+// TriggerBuild 触发构建：按分支策略确定分支，创建构建记录后生成构建定义并提交给执行器
+func (s *BuildService) TriggerBuild(ctx context.Context, command *domain.TriggerBuildCommand) (types.Long, error) {
+	run, err := s.prepareBuild(ctx, command)
+	if err != nil {
+		return 0, err
+	}
+	if err = s.createBuild(ctx, run); err != nil {
+		return 0, err
+	}
+
+	build := run.Build
+	runner := s.Runners[build.Runner]
+	now := time.Now()
+	namespace, name, manifest, err := runner.Render(ctx, run)
+	if err == nil {
+		build.Namespace = namespace
+		build.RunName = name
+		build.Manifest = string(manifest)
+		_, _, err = runner.Submit(ctx, run)
+	}
+	if err != nil {
+		build.Finish(enum.BuildStatusFail, "提交构建失败: "+err.Error(), now)
+		s.finishSteps(ctx, build)
+	} else {
+		build.Status = enum.BuildStatusBuilding
+		build.StartedAt = &now
+	}
+	if err = s.Repo.SaveBuild(ctx, build); err != nil {
+		return build.ID, common.InternalError("更新构建记录失败", err)
+	}
+	return build.ID, nil
+}
+
+// prepareBuild 校验构建配置并组装构建上下文，构建记录尚未保存
+func (s *BuildService) prepareBuild(ctx context.Context, command *domain.TriggerBuildCommand) (*domain.BuildRun, error) {
+	app, err := s.AppQuery.GetApplicationByID(ctx, command.AppID)
+	if err != nil {
+		return nil, common.NotFoundError("应用不存在", err)
+	}
+	config, err := s.GetBuildConfig(ctx, command.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.Runners[config.Runner]; !ok {
+		return nil, common.RequestParamError("", errors.New("不支持的构建执行器: "+config.Runner))
+	}
+	branch, err := config.ResolveBranch(command.Branch)
+	if err != nil {
+		return nil, common.RequestParamError("", err)
+	}
+
+	run := &domain.BuildRun{
+		Config:  config,
+		AppName: app.Name,
+		Build: &domain.Build{
+			AppID:          app.ID,
+			Branch:         branch,
+			Commit:         command.Commit,
+			BuildMethod:    config.BuildMethod,
+			Runner:         config.Runner,
+			Status:         enum.BuildStatusCreated,
+			DeployEnvID:    config.DeployEnvID,
+			DeployStrategy: config.DeployStrategy,
+		},
+	}
+	if config.BuildMethod.HasImageBuild() {
+		registry, err := s.AppQuery.GetImageRegistryByID(ctx, config.RegistryID)
+		if err != nil {
+			return nil, common.RequestParamError("", errors.New("镜像仓库不存在"))
+		}
+		run.Registry = &domain.Registry{
+			ID:       registry.ID,
+			URL:      registry.URL,
+			Username: registry.Username,
+			Password: registry.Password,
+			Email:    registry.Email,
+		}
+	}
+	run.Build.AuditCreated(ctx)
+	return run, nil
+}
+
+// createBuild 分配构建序号并保存构建记录与步骤
+func (s *BuildService) createBuild(ctx context.Context, run *domain.BuildRun) (err error) {
+	ctx, err = s.BeginTransaction(ctx, "create build")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "create build")
+	}()
+
+	build := run.Build
+	number, err := s.Repo.GetMaxBuildNumber(ctx, build.AppID)
+	if err != nil {
+		return common.InternalError("查询构建序号失败", err)
+	}
+	run.SetNumber(number + 1)
+
+	tasks := domain.PipelineTasks(build.BuildMethod)
+	steps := make([]*domain.BuildStep, 0, len(tasks))
+	for i, task := range tasks {
+		steps = append(steps, &domain.BuildStep{Name: task, Sort: i + 1, Status: enum.BuildStatusCreated})
+	}
+	if err = s.Repo.CreateBuild(ctx, build, steps); err != nil {
+		return common.InternalError("创建构建记录失败", err)
+	}
+	return nil
+}
+
+// RenderPipeline 预览应用下一次构建将提交的定义并校验，不创建构建记录
+func (s *BuildService) RenderPipeline(ctx context.Context, command *domain.TriggerBuildCommand) (*domain.PipelineVO, error) {
+	run, err := s.prepareBuild(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	number, err := s.Repo.GetMaxBuildNumber(ctx, command.AppID)
+	if err != nil {
+		return nil, common.InternalError("查询构建序号失败", err)
+	}
+	run.SetNumber(number + 1)
+
+	runner := s.Runners[run.Build.Runner]
+	namespace, name, manifest, err := runner.Render(ctx, run)
+	if err != nil {
+		return nil, common.InternalError("生成构建定义失败", err)
+	}
+	problems := runner.Validate(ctx, run)
+	return &domain.PipelineVO{
+		Namespace: namespace,
+		Name:      name,
+		Pipeline:  domain.PipelineName(run.Config.BuildMethod, run.Config.ImageBuilder),
+		Valid:     len(problems) == 0,
+		Errors:    problems,
+		Manifest:  string(manifest),
+	}, nil
+}
+
+// ListBuilds 分页查询应用的构建记录
+func (s *BuildService) ListBuilds(ctx context.Context, query *domain.BuildQuery) ([]*domain.Build, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = 10
+	}
+	builds, total, err := s.Repo.ListBuilds(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询构建记录失败", err)
+	}
+	return builds, total, nil
+}
+
+// GetBuild 获取构建详情及步骤
+func (s *BuildService) GetBuild(ctx context.Context, id types.Long) (*domain.BuildDetailVO, error) {
+	build, err := s.getBuild(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := s.Repo.ListSteps(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询构建步骤失败", err)
+	}
+	return &domain.BuildDetailVO{Build: build, StatusName: build.Status.String(), Steps: steps}, nil
+}
+
+// GetManifest 获取构建提交给执行器的定义
+func (s *BuildService) GetManifest(ctx context.Context, id types.Long) (string, error) {
+	build, err := s.getBuild(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if build.Manifest == "" {
+		return "", common.NotFoundError("构建没有生成执行定义", nil)
+	}
+	return build.Manifest, nil
+}
+
+// CancelBuild 取消未结束的构建
+func (s *BuildService) CancelBuild(ctx context.Context, id types.Long) error {
+	build, err := s.getBuild(ctx, id)
+	if err != nil {
+		return err
+	}
+	if build.Finished() {
+		return common.RequestParamError("", errors.New("构建已结束，不能取消"))
+	}
+	if build.Status == enum.BuildStatusBuilding {
+		if runner, ok := s.Runners[build.Runner]; ok {
+			if err = runner.Cancel(ctx, build); err != nil {
+				return common.InternalError("取消构建失败", err)
+			}
+		}
+	}
+
+	build.Finish(enum.BuildStatusCanceled, "构建已取消", time.Now())
+	build.AuditModified(ctx)
+	s.finishSteps(ctx, build)
+	if err = s.Repo.SaveBuild(ctx, build); err != nil {
+		return common.InternalError("更新构建记录失败", err)
+	}
+	return nil
+}
+
+// SyncBuilds 同步构建中的记录状态，由定时任务调用
+func (s *BuildService) SyncBuilds(ctx context.Context) {
+	builds, err := s.Repo.FindBuildsByStatus(ctx, enum.BuildStatusBuilding, domain.SyncBatchSize)
+	if err != nil {
+		logrus.WithError(err).Error("查询构建中的记录失败")
+		return
+	}
+	for _, build := range builds {
+		if err = s.SyncBuild(ctx, build); err != nil {
+			logrus.WithError(err).WithField("build_id", build.ID).Error("同步构建状态失败")
+		}
+	}
+}
+
+// SyncBuild 从执行器同步一条构建的状态，构建成功且配置了发布环境时创建发布计划
+func (s *BuildService) SyncBuild(ctx context.Context, build *domain.Build) error {
+	runner, ok := s.Runners[build.Runner]
+	if !ok {
+		return errors.New("不支持的构建执行器: " + build.Runner)
+	}
+	status, err := runner.Sync(ctx, build)
+	if err != nil {
+		return err
+	}
+
+	steps, err := s.Repo.ListSteps(ctx, build.ID)
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		stepStatus, ok := status.Steps[step.Name]
+		if !ok || (step.Status == stepStatus.Status && step.FinishedAt != nil) {
+			continue
+		}
+		step.Status = stepStatus.Status
+		step.Message = stepStatus.Message
+		step.StartedAt = stepStatus.StartedAt
+		step.FinishedAt = stepStatus.FinishedAt
+		if err = s.Repo.SaveStep(ctx, step); err != nil {
+			return err
+		}
+	}
+
+	if status.StartedAt != nil {
+		build.StartedAt = status.StartedAt
+	}
+	switch status.Status {
+	case enum.BuildStatusSuccess, enum.BuildStatusFail, enum.BuildStatusCanceled:
+		finishedAt := time.Now()
+		if status.FinishedAt != nil {
+			finishedAt = *status.FinishedAt
+		}
+		build.Finish(status.Status, status.Message, finishedAt)
+		if build.Status == enum.BuildStatusSuccess {
+			s.createReleasePlan(ctx, build)
+		} else {
+			s.finishSteps(ctx, build)
+		}
+	}
+	return s.Repo.SaveBuild(ctx, build)
+}
+
+// createReleasePlan 为构建产出的镜像创建发布计划，失败时记录在构建说明中
+func (s *BuildService) createReleasePlan(ctx context.Context, build *domain.Build) {
+	if build.Image == "" || build.DeployEnvID == 0 {
+		return
+	}
+	planID, err := s.DeployService.CreateReleasePlan(ctx, &application.CreateReleaseCommand{
+		AppID:    build.AppID,
+		EnvID:    build.DeployEnvID,
+		Version:  build.ImageTag,
+		Strategy: build.DeployStrategy,
+	})
+	if err != nil {
+		build.Message = "构建成功，创建发布计划失败: " + err.Error()
+		return
+	}
+	build.PlanID = planID
+}
+
+// finishSteps 构建结束后将未完成的步骤标记为已取消
+func (s *BuildService) finishSteps(ctx context.Context, build *domain.Build) {
+	steps, err := s.Repo.ListSteps(ctx, build.ID)
+	if err != nil {
+		logrus.WithError(err).WithField("build_id", build.ID).Error("查询构建步骤失败")
+		return
+	}
+	for _, step := range steps {
+		if step.Status != enum.BuildStatusCreated && step.Status != enum.BuildStatusBuilding {
+			continue
+		}
+		step.Status = enum.BuildStatusCanceled
+		step.FinishedAt = build.FinishedAt
+		if err = s.Repo.SaveStep(ctx, step); err != nil {
+			logrus.WithError(err).WithField("step_id", step.ID).Error("更新构建步骤失败")
+		}
+	}
+}
+
+// ListCommits 按构建记录列出应用两个版本（镜像tag）之间成功构建过的提交，供发布差异使用；
+// 没有目标版本的构建记录时返回nil，没有当前版本的构建记录时只返回目标版本的提交
+func (s *BuildService) ListCommits(ctx context.Context, appID types.Long, fromVersion, toVersion string) (*application.CommitRange, error) {
+	to, err := s.Repo.GetSuccessfulBuildByImageTag(ctx, appID, toVersion)
+	if err != nil {
+		return nil, common.InternalError("查询构建记录失败", err)
+	}
+	if to == nil || to.Commit == "" {
+		return nil, nil
+	}
+	from, err := s.Repo.GetSuccessfulBuildByImageTag(ctx, appID, fromVersion)
+	if err != nil {
+		return nil, common.InternalError("查询构建记录失败", err)
+	}
+
+	commits := &application.CommitRange{ToCommit: to.Commit, Commits: make([]*application.BuildCommit, 0)}
+	builds := []*domain.Build{to}
+	if from != nil && from.Commit != "" {
+		commits.FromCommit = from.Commit
+		// 目标版本早于当前版本时列出将被回退的提交
+		low, high := from.Number, to.Number
+		if high < low {
+			low, high = high, low
+			commits.Reverted = true
+		}
+		if builds, err = s.Repo.ListSuccessfulBuildsBetween(ctx, appID, low, high); err != nil {
+			return nil, common.InternalError("查询构建记录失败", err)
+		}
+		if config, err := s.Repo.GetConfigByAppID(ctx, appID); err == nil && config != nil {
+			commits.CompareURL = domain.CompareURL(config.RepoURL, from.Commit, to.Commit)
+		}
+	}
+
+	// 同一提交可能被构建多次，只保留序号最大的一次；区间起点的提交不算变化
+	base := commits.FromCommit
+	if commits.Reverted {
+		base = commits.ToCommit
+	}
+	seen := make(map[string]bool, len(builds))
+	for _, build := range builds {
+		if build.Commit == "" || seen[build.Commit] || build.Commit == base {
+			continue
+		}
+		seen[build.Commit] = true
+		commits.Commits = append(commits.Commits, &application.BuildCommit{
+			Commit:      build.Commit,
+			Branch:      build.Branch,
+			BuildID:     build.ID,
+			BuildNumber: build.Number,
+			ImageTag:    build.ImageTag,
+			BuiltAt:     build.FinishedAt,
+		})
+	}
+	return commits, nil
+}
+
+// getBuild 获取构建记录，不存在时返回NotFound错误
+func (s *BuildService) getBuild(ctx context.Context, id types.Long) (*domain.Build, error) {
+	build, err := s.Repo.GetBuildByID(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询构建记录失败", err)
+	}
+	if build == nil {
+		return nil, common.NotFoundError("构建记录不存在", nil)
+	}
+	return build, nil
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/build/internal/domain"
+)
+
+// Runner 构建执行器，新增执行器时实现该接口，注册Bean并加入domain.RunnerBeans
+type Runner interface {
+	// Render 生成将要提交的构建定义（YAML），用于记录与预览
+	Render(ctx context.Context, run *domain.BuildRun) (namespace, name string, manifest []byte, err error)
+	// Validate 校验构建定义，返回全部问题，为空表示可以提交
+	Validate(ctx context.Context, run *domain.BuildRun) []string
+	// Submit 提交构建，返回执行器中的命名空间与运行名称
+	Submit(ctx context.Context, run *domain.BuildRun) (namespace, name string, err error)
+	// Sync 查询构建状态
+	Sync(ctx context.Context, build *domain.Build) (*domain.RunStatus, error)
+	// Cancel 取消构建
+	Cancel(ctx context.Context, build *domain.Build) error
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/config"
+	"devops-platform/internal/deploy-system/build/internal/domain"
+	"devops-platform/internal/pkg/enum"
+	"devops-platform/internal/pkg/kube"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tektonConfig Tekton配置
+type tektonConfig interface {
+	GetSystemName() string
+}
+
+// TektonClient 访问集群中Tekton资源的客户端
+type TektonClient interface {
+	ApplySecret(ctx context.Context, secret *domain.Secret) error
+	ApplyTask(ctx context.Context, task *domain.Task) error
+	ApplyPipeline(ctx context.Context, pipeline *domain.Pipeline) error
+	CreatePipelineRun(ctx context.Context, run *domain.PipelineRun) error
+	GetPipelineRun(ctx context.Context, namespace, name string) (*domain.PipelineRun, error)
+	GetTaskRun(ctx context.Context, namespace, name string) (*domain.TaskRun, error)
+	CancelPipelineRun(ctx context.Context, namespace, name string) error
+}
+
+// errNoCluster 未配置集群客户端
+var errNoCluster = errors.New("未配置Tekton集群客户端，无法提交构建")
+
+// TektonRunner 以Tekton PipelineRun执行构建，提交到tekton.system_name命名空间
+type TektonRunner struct {
+	Client    TektonClient
+	namespace string
+}
+
+// NewTektonRunner 创建Tekton执行器，client为nil时只能预览不能提交
+func NewTektonRunner(client TektonClient) *TektonRunner {
+	return &TektonRunner{Client: client}
+}
+
+// PreInject 读取Tekton配置
+func (r *TektonRunner) PreInject(getBean func(string) interface{}) {
+	r.namespace = domain.DefaultNamespace
+	if cfg, ok := getBean(config.BeanTekton).(tektonConfig); ok && cfg.GetSystemName() != "" {
+		r.namespace = cfg.GetSystemName()
+	}
+}
+
+// Namespace 提交PipelineRun的命名空间
+func (r *TektonRunner) Namespace() string {
+	if r.namespace == "" {
+		return domain.DefaultNamespace
+	}
+	return r.namespace
+}
+
+// Bundle 生成构建提交的全部资源
+func (r *TektonRunner) Bundle(run *domain.BuildRun) *domain.PipelineBundle {
+	return domain.NewPipelineBundle(r.Namespace(), run)
+}
+
+// Render 生成构建资源的YAML，凭据内容已隐藏
+func (r *TektonRunner) Render(ctx context.Context, run *domain.BuildRun) (string, string, []byte, error) {
+	bundle := r.Bundle(run)
+	manifest, err := bundle.Render(true)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return bundle.Namespace, bundle.PipelineRun.Metadata.Name, manifest, nil
+}
+
+func (r *TektonRunner) Validate(ctx context.Context, run *domain.BuildRun) []string {
+	return r.Bundle(run).Validate()
+}
+
+// Submit 校验构建资源后依次写入凭据、Task与Pipeline，再创建PipelineRun
+func (r *TektonRunner) Submit(ctx context.Context, run *domain.BuildRun) (string, string, error) {
+	if r.Client == nil {
+		return "", "", errNoCluster
+	}
+	bundle := r.Bundle(run)
+	if problems := bundle.Validate(); len(problems) > 0 {
+		return "", "", errors.New("构建定义校验失败: " + strings.Join(problems, "; "))
+	}
+	if bundle.Secret != nil {
+		if err := r.Client.ApplySecret(ctx, bundle.Secret); err != nil {
+			return "", "", err
+		}
+	}
+	for _, task := range bundle.Tasks {
+		if err := r.Client.ApplyTask(ctx, task); err != nil {
+			return "", "", err
+		}
+	}
+	if err := r.Client.ApplyPipeline(ctx, bundle.Pipeline); err != nil {
+		return "", "", err
+	}
+	pipelineRun := bundle.PipelineRun
+	if err := r.Client.CreatePipelineRun(ctx, pipelineRun); err != nil {
+		return "", "", err
+	}
+	return pipelineRun.Metadata.Namespace, pipelineRun.Metadata.Name, nil
+}
+
+func (r *TektonRunner) Sync(ctx context.Context, build *domain.Build) (*domain.RunStatus, error) {
+	if r.Client == nil {
+		return nil, errNoCluster
+	}
+	pipelineRun, err := r.Client.GetPipelineRun(ctx, build.Namespace, build.RunName)
+	if err != nil {
+		return nil, err
+	}
+	status := &domain.RunStatus{Steps: make(map[string]*domain.StepStatus)}
+	if pipelineRun.Status == nil {
+		status.Status, _ = domain.BuildStatusOf(nil)
+		return status, nil
+	}
+	status.Status, status.Message = domain.BuildStatusOf(pipelineRun.Status.Conditions)
+	status.StartedAt = pipelineRun.Status.StartTime
+	status.FinishedAt = pipelineRun.Status.CompletionTime
+
+	for _, child := range pipelineRun.Status.ChildReferences {
+		if child.Kind != domain.KindTaskRun {
+			continue
+		}
+		taskRun, err := r.Client.GetTaskRun(ctx, build.Namespace, child.Name)
+		if err != nil {
+			// 单个任务状态查询失败不影响整体状态
+			logrus.WithError(err).WithField("task_run", child.Name).Warn("查询TaskRun状态失败")
+			continue
+		}
+		step := &domain.StepStatus{}
+		if taskRun.Status != nil {
+			step.Status, step.Message = domain.BuildStatusOf(taskRun.Status.Conditions)
+			step.StartedAt = taskRun.Status.StartTime
+			step.FinishedAt = taskRun.Status.CompletionTime
+		} else {
+			step.Status = enum.BuildStatusCreated
+		}
+		status.Steps[child.PipelineTaskName] = step
+	}
+	return status, nil
+}
+
+func (r *TektonRunner) Cancel(ctx context.Context, build *domain.Build) error {
+	if r.Client == nil {
+		return errNoCluster
+	}
+	err := r.Client.CancelPipelineRun(ctx, build.Namespace, build.RunName)
+	if kube.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// KubeTektonClient 通过Kubernetes API访问Tekton资源
+type KubeTektonClient struct {
+	Client *kube.Client
+}
+
+func (c *KubeTektonClient) ApplySecret(ctx context.Context, secret *domain.Secret) error {
+	path := fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", secret.Metadata.Namespace, secret.Metadata.Name)
+	return c.Client.Apply(ctx, path, secret, nil)
+}
+
+func (c *KubeTektonClient) ApplyTask(ctx context.Context, task *domain.Task) error {
+	return c.Client.Apply(ctx, tektonPath(task.Metadata.Namespace, "tasks", task.Metadata.Name), task, nil)
+}
+
+func (c *KubeTektonClient) ApplyPipeline(ctx context.Context, pipeline *domain.Pipeline) error {
+	return c.Client.Apply(ctx, tektonPath(pipeline.Metadata.Namespace, "pipelines", pipeline.Metadata.Name), pipeline, nil)
+}
+
+func (c *KubeTektonClient) CreatePipelineRun(ctx context.Context, run *domain.PipelineRun) error {
+	return c.Client.Create(ctx, tektonPath(run.Metadata.Namespace, "pipelineruns", ""), run, nil)
+}
+
+func (c *KubeTektonClient) GetPipelineRun(ctx context.Context, namespace, name string) (*domain.PipelineRun, error) {
+	var run domain.PipelineRun
+	if err := c.Client.Get(ctx, tektonPath(namespace, "pipelineruns", name), &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (c *KubeTektonClient) GetTaskRun(ctx context.Context, namespace, name string) (*domain.TaskRun, error) {
+	var run domain.TaskRun
+	if err := c.Client.Get(ctx, tektonPath(namespace, "taskruns", name), &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (c *KubeTektonClient) CancelPipelineRun(ctx context.Context, namespace, name string) error {
+	patch := map[string]interface{}{"spec": map[string]string{"status": domain.PipelineRunSpecStatusCancelled}}
+	return c.Client.Patch(ctx, tektonPath(namespace, "pipelineruns", name), patch, nil)
+}
+
+// tektonPath Tekton资源的API路径
+func tektonPath(namespace, resource, name string) string {
+	path := fmt.Sprintf("/apis/%s/namespaces/%s/%s", domain.TektonAPIVersion, namespace, resource)
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
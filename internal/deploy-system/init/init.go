@@ -1,9 +1,14 @@
 package init
 
 import (
+	_ "devops-platform/internal/deploy-system/analytics/init"
 	_ "devops-platform/internal/deploy-system/application/init"
+	_ "devops-platform/internal/deploy-system/audit/init"
 	_ "devops-platform/internal/deploy-system/auth/init"
 	_ "devops-platform/internal/deploy-system/authorization/init"
+	_ "devops-platform/internal/deploy-system/build/init"
 	_ "devops-platform/internal/deploy-system/middleware/init"
+	_ "devops-platform/internal/deploy-system/notification/init"
 	_ "devops-platform/internal/deploy-system/organization/init"
+	_ "devops-platform/internal/deploy-system/trigger/init"
 )
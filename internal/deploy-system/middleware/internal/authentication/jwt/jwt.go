@@ -80,6 +80,7 @@ func JWTAuth() gin.HandlerFunc {
 			UserID:      claims.UserID,
 			Username:    claims.Name,
 			RealName:    claims.Name,
+			DeptID:      claims.DeptID,
 			TokenString: tokenString,
 			TokenInfo: &security.TokenInfo{
 				Token:     tokenString,
@@ -88,6 +89,7 @@ func JWTAuth() gin.HandlerFunc {
 				Username:  claims.Name,
 				RealName:  claims.Name,
 				Role:      int(claims.Role),
+				DeptID:    claims.DeptID,
 				LoginTime: time.Unix(claims.IssuedAt.Unix(), 0),
 			},
 			IP:        c.ClientIP(),
@@ -96,6 +98,8 @@ func JWTAuth() gin.HandlerFunc {
 
 		// 使用web包存储用户上下文，确保与控制器使用相同的机制
 		web.SetCurrentUser(c, userContext)
+		// 同时写入gin上下文，服务层可通过security.GetUserContext获取当前用户
+		security.SetUserContext(c, userContext)
 
 		c.Next()
 	}
@@ -140,6 +144,7 @@ func OptionalJWTAuth() gin.HandlerFunc {
 			UserID:      claims.UserID,
 			Username:    claims.Name,
 			RealName:    claims.Name,
+			DeptID:      claims.DeptID,
 			TokenString: tokenString,
 			TokenInfo: &security.TokenInfo{
 				Token:     tokenString,
@@ -148,6 +153,7 @@ func OptionalJWTAuth() gin.HandlerFunc {
 				Username:  claims.Name,
 				RealName:  claims.Name,
 				Role:      int(claims.Role),
+				DeptID:    claims.DeptID,
 				LoginTime: time.Unix(claims.IssuedAt.Unix(), 0),
 			},
 			IP:        c.ClientIP(),
@@ -156,6 +162,8 @@ func OptionalJWTAuth() gin.HandlerFunc {
 
 		// 使用web包存储用户上下文，确保与控制器使用相同的机制
 		web.SetCurrentUser(c, userContext)
+		// 同时写入gin上下文，服务层可通过security.GetUserContext获取当前用户
+		security.SetUserContext(c, userContext)
 
 		c.Next()
 	}
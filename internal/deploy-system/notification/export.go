@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"context"
+
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+)
+
+// Bean常量
+const (
+	BeanNotificationService = domain.BeanNotificationService
+)
+
+// 通知事件类型
+const (
+	EventDeployStarted    = domain.EventDeployStarted
+	EventDeploySucceeded  = domain.EventDeploySucceeded
+	EventDeployFailed     = domain.EventDeployFailed
+	EventDeployRolledBack = domain.EventDeployRolledBack
+	EventPlanApproved     = domain.EventPlanApproved
+	EventJobRunFailed     = domain.EventJobRunFailed
+	EventDriftDetected    = domain.EventDriftDetected
+)
+
+// Publisher 通知事件发布接口
+type Publisher interface {
+	// Publish 发布事件，按订阅异步投递到各通知渠道，发布失败不影响调用方
+	Publish(ctx context.Context, event *domain.Event)
+}
+
+// 领域对象类型别名
+type (
+	Event          = domain.Event
+	ChannelVO      = domain.ChannelVO
+	SubscriptionVO = domain.SubscriptionVO
+	Delivery       = domain.Delivery
+)
@@ -0,0 +1,28 @@
+package init
+
+import (
+	"devops-platform/internal/deploy-system/notification/internal/controller"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"devops-platform/internal/deploy-system/notification/internal/repository"
+	"devops-platform/internal/deploy-system/notification/internal/service"
+	"devops-platform/pkg/beans"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 使用标准的init函数进行初始化
+func init() {
+	// 注册仓储
+	beans.Register(domain.BeanNotificationRepository, repository.NewRepository())
+
+	// 注册投递器，异步投递并按退避策略重试
+	beans.Register(domain.BeanDispatcher, service.NewDispatcher())
+
+	// 注册服务
+	beans.Register(domain.BeanNotificationService, service.NewNotificationService())
+
+	// 注册控制器
+	beans.Register(domain.BeanNotificationController, controller.NewNotificationController())
+
+	logrus.Info("通知模块初始化完成")
+}
@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"devops-platform/internal/deploy-system/notification/internal/service"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationController 通知控制器
+type NotificationController struct {
+	web.Controller
+	Service *service.NotificationService `inject:"NotificationService"`
+}
+
+// NewNotificationController 创建通知控制器实例
+func NewNotificationController() *NotificationController {
+	return &NotificationController{}
+}
+
+// ListChannels 查询通知渠道
+// @Summary 查询通知渠道
+// @Description 查询全部通知渠道，不返回签名密钥与SMTP密码
+// @Tags 通知管理
+// @Produce json
+// @Success 200 {object} common.Response{data=[]domain.ChannelVO}
+// @Router /api/v1/notifications/channels [get]
+func (c *NotificationController) ListChannels(ctx *gin.Context) {
+	channels, err := c.Service.ListChannels(ctx)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, channels)
+}
+
+// GetChannel 获取通知渠道
+// @Summary 获取通知渠道
+// @Tags 通知管理
+// @Produce json
+// @Param id path int true "渠道ID"
+// @Success 200 {object} common.Response{data=domain.ChannelVO}
+// @Router /api/v1/notifications/channels/{id} [get]
+func (c *NotificationController) GetChannel(ctx *gin.Context) {
+	id, ok := pathID(ctx, "渠道ID")
+	if !ok {
+		return
+	}
+	channel, err := c.Service.GetChannel(ctx, id)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, channel)
+}
+
+// CreateChannel 创建通知渠道
+// @Summary 创建通知渠道
+// @Description 渠道类型: webhook（HMAC签名）, dingtalk, wecom, slack, email（SMTP）
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Param data body domain.SaveChannelCommand true "渠道信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/notifications/channels [post]
+func (c *NotificationController) CreateChannel(ctx *gin.Context) {
+	var command domain.SaveChannelCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	id, err := c.Service.CreateChannel(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, gin.H{"id": id})
+}
+
+// UpdateChannel 更新通知渠道
+// @Summary 更新通知渠道
+// @Description secret、smtp_password为空时保持原值
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Param id path int true "渠道ID"
+// @Param data body domain.SaveChannelCommand true "渠道信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/notifications/channels/{id} [put]
+func (c *NotificationController) UpdateChannel(ctx *gin.Context) {
+	id, ok := pathID(ctx, "渠道ID")
+	if !ok {
+		return
+	}
+	var command domain.SaveChannelCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.ID = id
+
+	if err := c.Service.UpdateChannel(ctx, &command); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// DeleteChannel 删除通知渠道
+// @Summary 删除通知渠道
+// @Description 同时删除该渠道的订阅
+// @Tags 通知管理
+// @Produce json
+// @Param id path int true "渠道ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/notifications/channels/{id} [delete]
+func (c *NotificationController) DeleteChannel(ctx *gin.Context) {
+	id, ok := pathID(ctx, "渠道ID")
+	if !ok {
+		return
+	}
+	if err := c.Service.DeleteChannel(ctx, id); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// TestChannel 发送测试消息
+// @Summary 发送测试消息
+// @Description 同步向渠道发送一条测试消息，用于验证配置
+// @Tags 通知管理
+// @Produce json
+// @Param id path int true "渠道ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/notifications/channels/{id}/test [post]
+func (c *NotificationController) TestChannel(ctx *gin.Context) {
+	id, ok := pathID(ctx, "渠道ID")
+	if !ok {
+		return
+	}
+	if err := c.Service.TestChannel(ctx, id); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ListSubscriptions 查询通知订阅
+// @Summary 查询通知订阅
+// @Tags 通知管理
+// @Produce json
+// @Param channel_id query int false "渠道ID"
+// @Param app_id query int false "应用ID"
+// @Param env_id query int false "环境ID"
+// @Success 200 {object} common.Response{data=[]domain.SubscriptionVO}
+// @Router /api/v1/notifications/subscriptions [get]
+func (c *NotificationController) ListSubscriptions(ctx *gin.Context) {
+	var query domain.SubscriptionQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	subscriptions, err := c.Service.ListSubscriptions(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, subscriptions)
+}
+
+// CreateSubscription 创建通知订阅
+// @Summary 创建通知订阅
+// @Description app_id、env_id为0表示全部；事件类型: deploy.started, deploy.succeeded, deploy.failed, deploy.rolled_back, plan.approved, job.failed, drift.detected
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Param data body domain.SaveSubscriptionCommand true "订阅信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/notifications/subscriptions [post]
+func (c *NotificationController) CreateSubscription(ctx *gin.Context) {
+	var command domain.SaveSubscriptionCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	id, err := c.Service.CreateSubscription(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, gin.H{"id": id})
+}
+
+// UpdateSubscription 更新通知订阅
+// @Summary 更新通知订阅
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param data body domain.SaveSubscriptionCommand true "订阅信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/notifications/subscriptions/{id} [put]
+func (c *NotificationController) UpdateSubscription(ctx *gin.Context) {
+	id, ok := pathID(ctx, "订阅ID")
+	if !ok {
+		return
+	}
+	var command domain.SaveSubscriptionCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.ID = id
+
+	if err := c.Service.UpdateSubscription(ctx, &command); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// DeleteSubscription 删除通知订阅
+// @Summary 删除通知订阅
+// @Tags 通知管理
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/notifications/subscriptions/{id} [delete]
+func (c *NotificationController) DeleteSubscription(ctx *gin.Context) {
+	id, ok := pathID(ctx, "订阅ID")
+	if !ok {
+		return
+	}
+	if err := c.Service.DeleteSubscription(ctx, id); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ListDeliveries 查询投递记录
+// @Summary 查询投递记录
+// @Tags 通知管理
+// @Produce json
+// @Param channel_id query int false "渠道ID"
+// @Param app_id query int false "应用ID"
+// @Param event_type query string false "事件类型"
+// @Param event_id query string false "事件ID"
+// @Param status query string false "状态: pending, sending, success, failed"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=common.PageResult{list=[]domain.Delivery}}
+// @Router /api/v1/notifications/deliveries [get]
+func (c *NotificationController) ListDeliveries(ctx *gin.Context) {
+	var query domain.DeliveryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	deliveries, total, err := c.Service.ListDeliveries(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccessWithPageExt(ctx, deliveries, total, query.Page, query.Size)
+}
+
+// RetryDelivery 重新投递
+// @Summary 重新投递
+// @Description 对投递失败的记录再投递一次
+// @Tags 通知管理
+// @Produce json
+// @Param id path int true "投递记录ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/notifications/deliveries/{id}/retry [post]
+func (c *NotificationController) RetryDelivery(ctx *gin.Context) {
+	id, ok := pathID(ctx, "投递记录ID")
+	if !ok {
+		return
+	}
+	if err := c.Service.RetryDelivery(ctx, id); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// pathID 解析路径中的ID参数，失败时直接返回400
+func pathID(ctx *gin.Context, name string) (types.Long, bool) {
+	id, err := types.StringToLong(ctx.Param("id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+name+"必须是数字")
+		return 0, false
+	}
+	return id, true
+}
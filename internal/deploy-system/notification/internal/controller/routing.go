@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Inject 实现依赖注入
+func (c *NotificationController) Inject(getBean func(string) interface{}) {
+	c.injectRouting(getBean)
+}
+
+// injectRouting 注入路由
+func (c *NotificationController) injectRouting(getBean func(string) interface{}) {
+	router, ok := getBean(web.BeanGinEngine).(gin.IRouter)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", web.BeanGinEngine)
+		return
+	}
+
+	// 通知路由组
+	notificationGroup := router.Group("/api/v1/notifications")
+	notificationGroup.Use(middleware.JWTAuth())
+	{
+		// 通知渠道
+		notificationGroup.GET("/channels", c.ListChannels)
+		notificationGroup.POST("/channels", c.CreateChannel)
+		notificationGroup.GET("/channels/:id", c.GetChannel)
+		notificationGroup.PUT("/channels/:id", c.UpdateChannel)
+		notificationGroup.DELETE("/channels/:id", c.DeleteChannel)
+		notificationGroup.POST("/channels/:id/test", c.TestChannel)
+
+		// 通知订阅
+		notificationGroup.GET("/subscriptions", c.ListSubscriptions)
+		notificationGroup.POST("/subscriptions", c.CreateSubscription)
+		notificationGroup.PUT("/subscriptions/:id", c.UpdateSubscription)
+		notificationGroup.DELETE("/subscriptions/:id", c.DeleteSubscription)
+
+		// 投递记录
+		notificationGroup.GET("/deliveries", c.ListDeliveries)
+		notificationGroup.POST("/deliveries/:id/retry", c.RetryDelivery)
+	}
+}
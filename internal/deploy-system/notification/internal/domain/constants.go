@@ -0,0 +1,96 @@
+package domain
+
+import "time"
+
+const (
+	// 模块Bean名称常量
+	BeanNotificationRepository = "NotificationRepository"
+	BeanNotificationService    = "NotificationService"
+	BeanDispatcher             = "NotificationDispatcher"
+	BeanNotificationController = "NotificationController"
+
+	// 通知渠道类型
+	ChannelTypeWebhook  = "webhook"  // 通用Webhook（HMAC签名）
+	ChannelTypeDingTalk = "dingtalk" // 钉钉机器人
+	ChannelTypeWeCom    = "wecom"    // 企业微信机器人
+	ChannelTypeSlack    = "slack"    // Slack兼容的Incoming Webhook
+	ChannelTypeEmail    = "email"    // SMTP邮件
+
+	// 通知事件类型
+	EventDeployStarted    = "deploy.started"     // 部署开始
+	EventDeploySucceeded  = "deploy.succeeded"   // 部署成功
+	EventDeployFailed     = "deploy.failed"      // 部署失败
+	EventDeployRolledBack = "deploy.rolled_back" // 部署已回滚
+	EventPlanApproved     = "plan.approved"      // 发布计划已审批
+	EventJobRunFailed     = "job.failed"         // 定时任务运行失败
+	EventDriftDetected    = "drift.detected"     // 检测到配置漂移
+
+	// 投递状态
+	DeliveryStatusPending = "pending" // 待投递（含等待重试）
+	DeliveryStatusSending = "sending" // 投递中
+	DeliveryStatusSuccess = "success" // 投递成功
+	DeliveryStatusFailed  = "failed"  // 重试耗尽，投递失败
+
+	// Webhook签名相关的请求头
+	HeaderEvent     = "X-Devops-Event"
+	HeaderDelivery  = "X-Devops-Delivery"
+	HeaderTimestamp = "X-Devops-Timestamp"
+	HeaderSignature = "X-Devops-Signature"
+
+	// DefaultMaxAttempts 默认最大投递次数（含首次）
+	DefaultMaxAttempts = 5
+)
+
+const (
+	// RetryBaseInterval 首次重试的等待时间，之后按指数退避
+	RetryBaseInterval = 10 * time.Second
+	// RetryMaxInterval 重试等待时间上限
+	RetryMaxInterval = 10 * time.Minute
+	// SendTimeout 单次投递超时时间
+	SendTimeout = 10 * time.Second
+	// SendingTimeout 投递中状态的超时时间，超时视为实例中断，重新投递
+	SendingTimeout = 5 * time.Minute
+)
+
+// EventTypes 支持订阅的事件类型
+var EventTypes = []string{
+	EventDeployStarted,
+	EventDeploySucceeded,
+	EventDeployFailed,
+	EventDeployRolledBack,
+	EventPlanApproved,
+	EventJobRunFailed,
+	EventDriftDetected,
+}
+
+// ChannelTypes 支持的通知渠道类型
+var ChannelTypes = []string{
+	ChannelTypeWebhook,
+	ChannelTypeDingTalk,
+	ChannelTypeWeCom,
+	ChannelTypeSlack,
+	ChannelTypeEmail,
+}
+
+// eventTitles 事件标题
+var eventTitles = map[string]string{
+	EventDeployStarted:    "部署开始",
+	EventDeploySucceeded:  "部署成功",
+	EventDeployFailed:     "部署失败",
+	EventDeployRolledBack: "部署已回滚",
+	EventPlanApproved:     "发布计划已审批",
+	EventJobRunFailed:     "定时任务运行失败",
+	EventDriftDetected:    "检测到配置漂移",
+}
+
+// RetryDelay 第attempts次投递失败后的重试等待时间：10s、20s、40s……最长10分钟
+func RetryDelay(attempts int) time.Duration {
+	delay := RetryBaseInterval
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= RetryMaxInterval {
+			return RetryMaxInterval
+		}
+	}
+	return delay
+}
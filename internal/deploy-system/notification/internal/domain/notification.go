@@ -0,0 +1,438 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Channel 通知渠道
+type Channel struct {
+	module.Module
+	Name         string `json:"name" gorm:"size:64;not null;uniqueIndex;comment:'渠道名称'"`
+	Type         string `json:"type" gorm:"size:20;not null;comment:'渠道类型: webhook, dingtalk, wecom, slack, email'"`
+	URL          string `json:"url" gorm:"size:512;comment:'Webhook地址'"`
+	Secret       string `json:"-" gorm:"size:255;comment:'签名密钥'"`
+	SMTPHost     string `json:"smtp_host" gorm:"size:128;comment:'SMTP服务器'"`
+	SMTPPort     int    `json:"smtp_port" gorm:"comment:'SMTP端口'"`
+	SMTPUsername string `json:"smtp_username" gorm:"size:128;comment:'SMTP用户名'"`
+	SMTPPassword string `json:"-" gorm:"size:255;comment:'SMTP密码'"`
+	SMTPFrom     string `json:"smtp_from" gorm:"size:128;comment:'发件人'"`
+	Recipients   string `json:"recipients" gorm:"size:1000;comment:'收件人，逗号分隔'"`
+	Enabled      bool   `json:"enabled" gorm:"not null;comment:'是否启用'"`
+	Description  string `json:"description" gorm:"size:255;comment:'描述'"`
+}
+
+// TableName 返回通知渠道表名
+func (Channel) TableName() string {
+	return "notify_channel"
+}
+
+// RecipientList 收件人列表
+func (c *Channel) RecipientList() []string {
+	return splitList(c.Recipients)
+}
+
+// ToVO 转换为视图对象，不返回密钥与密码
+func (c *Channel) ToVO() *ChannelVO {
+	return &ChannelVO{
+		ID:           c.ID,
+		Name:         c.Name,
+		Type:         c.Type,
+		URL:          c.URL,
+		HasSecret:    c.Secret != "",
+		SMTPHost:     c.SMTPHost,
+		SMTPPort:     c.SMTPPort,
+		SMTPUsername: c.SMTPUsername,
+		HasPassword:  c.SMTPPassword != "",
+		SMTPFrom:     c.SMTPFrom,
+		Recipients:   c.RecipientList(),
+		Enabled:      c.Enabled,
+		Description:  c.Description,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.LastModifiedAt,
+	}
+}
+
+// ChannelVO 通知渠道视图对象
+type ChannelVO struct {
+	ID           types.Long `json:"id"`
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	URL          string     `json:"url"`
+	HasSecret    bool       `json:"has_secret"`
+	SMTPHost     string     `json:"smtp_host"`
+	SMTPPort     int        `json:"smtp_port"`
+	SMTPUsername string     `json:"smtp_username"`
+	HasPassword  bool       `json:"has_password"`
+	SMTPFrom     string     `json:"smtp_from"`
+	Recipients   []string   `json:"recipients"`
+	Enabled      bool       `json:"enabled"`
+	Description  string     `json:"description"`
+	CreatedAt    types.Time `json:"created_at"`
+	UpdatedAt    types.Time `json:"updated_at"`
+}
+
+// SaveChannelCommand 创建/更新通知渠道命令
+type SaveChannelCommand struct {
+	ID           types.Long `json:"-"`
+	Name         string     `json:"name" binding:"required"`
+	Type         string     `json:"type" binding:"required"`
+	URL          string     `json:"url"`
+	Secret       string     `json:"secret"` // 更新时为空表示保持不变
+	SMTPHost     string     `json:"smtp_host"`
+	SMTPPort     int        `json:"smtp_port"`
+	SMTPUsername string     `json:"smtp_username"`
+	SMTPPassword string     `json:"smtp_password"` // 更新时为空表示保持不变
+	SMTPFrom     string     `json:"smtp_from"`
+	Recipients   []string   `json:"recipients"`
+	Enabled      *bool      `json:"enabled"`
+	Description  string     `json:"description"`
+}
+
+// ApplyTo 校验命令并写入渠道
+func (command *SaveChannelCommand) ApplyTo(channel *Channel) error {
+	command.Name = strings.TrimSpace(command.Name)
+	if command.Name == "" {
+		return errors.New("渠道名称不能为空")
+	}
+
+	channel.Name = command.Name
+	channel.Type = command.Type
+	channel.Description = command.Description
+	if command.Enabled != nil {
+		channel.Enabled = *command.Enabled
+	}
+	if command.Secret != "" {
+		channel.Secret = command.Secret
+	}
+
+	switch command.Type {
+	case ChannelTypeWebhook, ChannelTypeDingTalk, ChannelTypeWeCom, ChannelTypeSlack:
+		target, err := url.Parse(strings.TrimSpace(command.URL))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return errors.New("Webhook地址必须是有效的http(s)地址")
+		}
+		channel.URL = target.String()
+		channel.SMTPHost, channel.SMTPPort, channel.SMTPUsername, channel.SMTPPassword, channel.SMTPFrom = "", 0, "", "", ""
+		channel.Recipients = ""
+		if command.Type == ChannelTypeWebhook && channel.Secret == "" {
+			return errors.New("通用Webhook必须配置签名密钥")
+		}
+	case ChannelTypeEmail:
+		if strings.TrimSpace(command.SMTPHost) == "" {
+			return errors.New("SMTP服务器不能为空")
+		}
+		if command.SMTPPort <= 0 || command.SMTPPort > 65535 {
+			return errors.New("SMTP端口无效")
+		}
+		if _, err := mail.ParseAddress(command.SMTPFrom); err != nil {
+			return fmt.Errorf("发件人地址无效: %s", command.SMTPFrom)
+		}
+		recipients := make([]string, 0, len(command.Recipients))
+		for _, recipient := range command.Recipients {
+			recipient = strings.TrimSpace(recipient)
+			if recipient == "" {
+				continue
+			}
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				return fmt.Errorf("收件人地址无效: %s", recipient)
+			}
+			recipients = append(recipients, recipient)
+		}
+		if len(recipients) == 0 {
+			return errors.New("收件人不能为空")
+		}
+		channel.URL, channel.Secret = "", ""
+		channel.SMTPHost = strings.TrimSpace(command.SMTPHost)
+		channel.SMTPPort = command.SMTPPort
+		channel.SMTPUsername = command.SMTPUsername
+		if command.SMTPPassword != "" {
+			channel.SMTPPassword = command.SMTPPassword
+		}
+		channel.SMTPFrom = command.SMTPFrom
+		channel.Recipients = strings.Join(recipients, ",")
+	default:
+		return fmt.Errorf("不支持的渠道类型: %s", command.Type)
+	}
+	return nil
+}
+
+// Subscription 通知订阅，AppID/EnvID为0表示全部应用/环境
+type Subscription struct {
+	module.Module
+	ChannelID types.Long `json:"channel_id" gorm:"not null;index;comment:'通知渠道ID'"`
+	AppID     types.Long `json:"app_id" gorm:"not null;default:0;index;comment:'应用ID，0表示全部应用'"`
+	EnvID     types.Long `json:"env_id" gorm:"not null;default:0;comment:'环境ID，0表示全部环境'"`
+	Events    string     `json:"-" gorm:"size:255;not null;comment:'订阅的事件类型，逗号分隔'"`
+	Enabled   bool       `json:"enabled" gorm:"not null;comment:'是否启用'"`
+}
+
+// TableName 返回通知订阅表名
+func (Subscription) TableName() string {
+	return "notify_subscription"
+}
+
+// EventTypes 订阅的事件类型
+func (s *Subscription) EventTypes() []string {
+	return splitList(s.Events)
+}
+
+// Matches 判断订阅是否匹配事件
+func (s *Subscription) Matches(event *Event) bool {
+	if !s.Enabled {
+		return false
+	}
+	if s.AppID != 0 && s.AppID != event.AppID {
+		return false
+	}
+	if s.EnvID != 0 && s.EnvID != event.EnvID {
+		return false
+	}
+	for _, eventType := range s.EventTypes() {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// ToVO 转换为视图对象
+func (s *Subscription) ToVO() *SubscriptionVO {
+	return &SubscriptionVO{
+		ID:        s.ID,
+		ChannelID: s.ChannelID,
+		AppID:     s.AppID,
+		EnvID:     s.EnvID,
+		Events:    s.EventTypes(),
+		Enabled:   s.Enabled,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+// SubscriptionVO 通知订阅视图对象
+type SubscriptionVO struct {
+	ID          types.Long `json:"id"`
+	ChannelID   types.Long `json:"channel_id"`
+	ChannelName string     `json:"channel_name"`
+	ChannelType string     `json:"channel_type"`
+	AppID       types.Long `json:"app_id"`
+	EnvID       types.Long `json:"env_id"`
+	Events      []string   `json:"events"`
+	Enabled     bool       `json:"enabled"`
+	CreatedAt   types.Time `json:"created_at"`
+}
+
+// SaveSubscriptionCommand 创建/更新通知订阅命令
+type SaveSubscriptionCommand struct {
+	ID        types.Long `json:"-"`
+	ChannelID types.Long `json:"channel_id" binding:"required"`
+	AppID     types.Long `json:"app_id"`
+	EnvID     types.Long `json:"env_id"`
+	Events    []string   `json:"events" binding:"required"`
+	Enabled   *bool      `json:"enabled"`
+}
+
+// ApplyTo 校验命令并写入订阅
+func (command *SaveSubscriptionCommand) ApplyTo(subscription *Subscription) error {
+	events := make([]string, 0, len(command.Events))
+	seen := make(map[string]bool, len(command.Events))
+	for _, event := range command.Events {
+		event = strings.TrimSpace(event)
+		if !IsEventType(event) {
+			return fmt.Errorf("不支持的事件类型: %s", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return errors.New("订阅的事件类型不能为空")
+	}
+
+	subscription.ChannelID = command.ChannelID
+	subscription.AppID = command.AppID
+	subscription.EnvID = command.EnvID
+	subscription.Events = strings.Join(events, ",")
+	if command.Enabled != nil {
+		subscription.Enabled = *command.Enabled
+	}
+	return nil
+}
+
+// SubscriptionQuery 通知订阅查询条件
+type SubscriptionQuery struct {
+	ChannelID types.Long `form:"channel_id"`
+	AppID     types.Long `form:"app_id"`
+	EnvID     types.Long `form:"env_id"`
+}
+
+// Delivery 通知投递记录，每个事件在每个匹配的订阅上生成一条
+type Delivery struct {
+	ID             types.Long `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID        string     `json:"event_id" gorm:"size:64;index;comment:'事件ID'"`
+	EventType      string     `json:"event_type" gorm:"size:32;index;comment:'事件类型'"`
+	ChannelID      types.Long `json:"channel_id" gorm:"index;comment:'通知渠道ID'"`
+	ChannelType    string     `json:"channel_type" gorm:"size:20;comment:'渠道类型'"`
+	SubscriptionID types.Long `json:"subscription_id" gorm:"comment:'订阅ID'"`
+	AppID          types.Long `json:"app_id" gorm:"index;comment:'应用ID'"`
+	EnvID          types.Long `json:"env_id" gorm:"comment:'环境ID'"`
+	Payload        string     `json:"payload" gorm:"type:text;comment:'事件内容（JSON）'"`
+	Status         string     `json:"status" gorm:"size:16;index:idx_status_retry;comment:'状态: pending, sending, success, failed'"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0;comment:'已投递次数'"`
+	MaxAttempts    int        `json:"max_attempts" gorm:"not null;default:5;comment:'最大投递次数'"`
+	ResponseCode   int        `json:"response_code" gorm:"comment:'最近一次响应码'"`
+	LastError      string     `json:"last_error" gorm:"size:1000;comment:'最近一次错误'"`
+	NextRetryAt    *time.Time `json:"next_retry_at" gorm:"index:idx_status_retry;comment:'下次投递时间'"`
+	DeliveredAt    *time.Time `json:"delivered_at" gorm:"comment:'投递成功时间'"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"comment:'创建时间'"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"comment:'更新时间'"`
+}
+
+// TableName 返回通知投递记录表名
+func (Delivery) TableName() string {
+	return "notify_delivery"
+}
+
+// Event 解析投递记录中的事件
+func (d *Delivery) Event() (*Event, error) {
+	var event Event
+	if err := json.Unmarshal([]byte(d.Payload), &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Failed 记录一次失败的投递，未超过最大次数时按指数退避安排重试
+func (d *Delivery) Failed(code int, err error, now time.Time) {
+	d.ResponseCode = code
+	d.LastError = truncate(err.Error(), 1000)
+	if d.Attempts >= d.MaxAttempts {
+		d.Status = DeliveryStatusFailed
+		d.NextRetryAt = nil
+		return
+	}
+	next := now.Add(RetryDelay(d.Attempts))
+	d.Status = DeliveryStatusPending
+	d.NextRetryAt = &next
+}
+
+// Succeeded 记录一次成功的投递
+func (d *Delivery) Succeeded(code int, now time.Time) {
+	d.Status = DeliveryStatusSuccess
+	d.ResponseCode = code
+	d.LastError = ""
+	d.NextRetryAt = nil
+	d.DeliveredAt = &now
+}
+
+// DeliveryQuery 投递记录查询条件
+type DeliveryQuery struct {
+	ChannelID types.Long `form:"channel_id"`
+	AppID     types.Long `form:"app_id"`
+	EventType string     `form:"event_type"`
+	EventID   string     `form:"event_id"`
+	Status    string     `form:"status"`
+	Page      int        `form:"page"`
+	Size      int        `form:"size"`
+}
+
+// Event 通知事件
+type Event struct {
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	AppID        types.Long `json:"app_id"`
+	AppName      string     `json:"app_name"`
+	EnvID        types.Long `json:"env_id"`
+	EnvName      string     `json:"env_name"`
+	PlanID       types.Long `json:"plan_id,omitempty"`
+	DeploymentID types.Long `json:"deployment_id,omitempty"`
+	Version      string     `json:"version"`
+	Strategy     string     `json:"strategy,omitempty"`
+	Operator     string     `json:"operator,omitempty"`
+	Message      string     `json:"message,omitempty"`
+	OccurredAt   time.Time  `json:"occurred_at"`
+}
+
+// Title 事件标题
+func (e *Event) Title() string {
+	title, ok := eventTitles[e.Type]
+	if !ok {
+		title = e.Type
+	}
+	return fmt.Sprintf("[%s] %s %s", title, e.AppName, e.Version)
+}
+
+// Lines 事件详情，每行一个字段
+func (e *Event) Lines() []string {
+	lines := []string{
+		"应用: " + e.AppName,
+		"环境: " + e.EnvName,
+		"版本: " + e.Version,
+	}
+	if e.Strategy != "" {
+		lines = append(lines, "策略: "+e.Strategy)
+	}
+	if e.PlanID != 0 {
+		lines = append(lines, "发布计划: "+e.PlanID.String())
+	}
+	if e.DeploymentID != 0 {
+		lines = append(lines, "部署记录: "+e.DeploymentID.String())
+	}
+	if e.Operator != "" {
+		lines = append(lines, "操作人: "+e.Operator)
+	}
+	if e.Message != "" {
+		lines = append(lines, "说明: "+e.Message)
+	}
+	lines = append(lines, "时间: "+e.OccurredAt.Format(types.TimeFormat))
+	return lines
+}
+
+// Text 纯文本内容
+func (e *Event) Text() string {
+	return e.Title() + "\n" + strings.Join(e.Lines(), "\n")
+}
+
+// Markdown Markdown格式内容
+func (e *Event) Markdown() string {
+	var builder strings.Builder
+	builder.WriteString("### " + e.Title() + "\n")
+	for _, line := range e.Lines() {
+		builder.WriteString("- " + line + "\n")
+	}
+	return builder.String()
+}
+
+// IsEventType 是否是支持的事件类型
+func IsEventType(eventType string) bool {
+	_, ok := eventTitles[eventType]
+	return ok
+}
+
+// splitList 拆分逗号分隔的列表
+func splitList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// truncate 按字符截断字符串
+func truncate(value string, size int) string {
+	runes := []rune(value)
+	if len(runes) <= size {
+		return value
+	}
+	return string(runes[:size])
+}
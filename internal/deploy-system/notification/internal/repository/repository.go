@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"devops-platform/internal/common/repository"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"devops-platform/pkg/types"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repository 通知仓储
+type Repository struct {
+	repository.Repository
+}
+
+// NewRepository 创建仓储实例
+func NewRepository() *Repository {
+	return &Repository{}
+}
+
+// GetChannelByID 根据ID获取通知渠道，不存在时返回nil
+func (r *Repository) GetChannelByID(ctx context.Context, id types.Long) (*domain.Channel, error) {
+	var channel domain.Channel
+	err := r.DB(ctx).First(&channel, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// GetChannelByName 根据名称获取通知渠道，不存在时返回nil
+func (r *Repository) GetChannelByName(ctx context.Context, name string) (*domain.Channel, error) {
+	var channel domain.Channel
+	err := r.DB(ctx).Where("name = ?", name).First(&channel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// ListChannels 查询全部通知渠道
+func (r *Repository) ListChannels(ctx context.Context) ([]*domain.Channel, error) {
+	var channels []*domain.Channel
+	if err := r.DB(ctx).Order("id").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// FindChannels 根据ID批量查询通知渠道
+func (r *Repository) FindChannels(ctx context.Context, ids []types.Long) ([]*domain.Channel, error) {
+	var channels []*domain.Channel
+	if len(ids) == 0 {
+		return channels, nil
+	}
+	if err := r.DB(ctx).Where("id IN ?", ids).Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// SaveChannel 保存通知渠道
+func (r *Repository) SaveChannel(ctx context.Context, channel *domain.Channel) error {
+	return r.DB(ctx).Save(channel).Error
+}
+
+// DeleteChannel 删除通知渠道及其订阅
+func (r *Repository) DeleteChannel(ctx context.Context, id types.Long) error {
+	if err := r.DB(ctx).Where("channel_id = ?", id).Delete(&domain.Subscription{}).Error; err != nil {
+		return err
+	}
+	return r.DB(ctx).Delete(&domain.Channel{}, id).Error
+}
+
+// GetSubscriptionByID 根据ID获取通知订阅，不存在时返回nil
+func (r *Repository) GetSubscriptionByID(ctx context.Context, id types.Long) (*domain.Subscription, error) {
+	var subscription domain.Subscription
+	err := r.DB(ctx).First(&subscription, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions 查询通知订阅
+func (r *Repository) ListSubscriptions(ctx context.Context, query *domain.SubscriptionQuery) ([]*domain.Subscription, error) {
+	db := r.DB(ctx).Model(&domain.Subscription{})
+	if query.ChannelID > 0 {
+		db = db.Where("channel_id = ?", query.ChannelID)
+	}
+	if query.AppID > 0 {
+		db = db.Where("app_id = ?", query.AppID)
+	}
+	if query.EnvID > 0 {
+		db = db.Where("env_id = ?", query.EnvID)
+	}
+
+	var subscriptions []*domain.Subscription
+	if err := db.Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// FindCandidateSubscriptions 查询可能匹配事件的已启用订阅（事件类型由调用方过滤）
+func (r *Repository) FindCandidateSubscriptions(ctx context.Context, appID, envID types.Long) ([]*domain.Subscription, error) {
+	var subscriptions []*domain.Subscription
+	err := r.DB(ctx).
+		Where("enabled = ?", true).
+		Where("app_id IN ?", []types.Long{0, appID}).
+		Where("env_id IN ?", []types.Long{0, envID}).
+		Order("id").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// SaveSubscription 保存通知订阅
+func (r *Repository) SaveSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	return r.DB(ctx).Save(subscription).Error
+}
+
+// DeleteSubscription 删除通知订阅
+func (r *Repository) DeleteSubscription(ctx context.Context, id types.Long) error {
+	return r.DB(ctx).Delete(&domain.Subscription{}, id).Error
+}
+
+// CreateDeliveries 批量创建投递记录
+func (r *Repository) CreateDeliveries(ctx context.Context, deliveries []*domain.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.DB(ctx).Create(&deliveries).Error
+}
+
+// GetDeliveryByID 根据ID获取投递记录，不存在时返回nil
+func (r *Repository) GetDeliveryByID(ctx context.Context, id types.Long) (*domain.Delivery, error) {
+	var delivery domain.Delivery
+	err := r.DB(ctx).First(&delivery, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ClaimDelivery 将到期的待投递记录标记为投递中，返回是否抢占成功，避免多个协程或实例重复投递
+func (r *Repository) ClaimDelivery(ctx context.Context, id types.Long, now time.Time) (bool, error) {
+	result := r.DB(ctx).Model(&domain.Delivery{}).
+		Where("id = ? AND status = ? AND next_retry_at <= ?", id, domain.DeliveryStatusPending, now).
+		Updates(map[string]interface{}{
+			"status":     domain.DeliveryStatusSending,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SaveDelivery 保存投递记录
+func (r *Repository) SaveDelivery(ctx context.Context, delivery *domain.Delivery) error {
+	return r.DB(ctx).Save(delivery).Error
+}
+
+// FindDueDeliveryIDs 查询到期待投递的记录ID
+func (r *Repository) FindDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]types.Long, error) {
+	var ids []types.Long
+	err := r.DB(ctx).Model(&domain.Delivery{}).
+		Where("status = ? AND next_retry_at <= ?", domain.DeliveryStatusPending, now).
+		Order("next_retry_at").Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ResetStaleDeliveries 将超时未完成的投递中记录重置为待投递
+func (r *Repository) ResetStaleDeliveries(ctx context.Context, before, now time.Time) (int64, error) {
+	result := r.DB(ctx).Model(&domain.Delivery{}).
+		Where("status = ? AND updated_at < ?", domain.DeliveryStatusSending, before).
+		Updates(map[string]interface{}{
+			"status":        domain.DeliveryStatusPending,
+			"next_retry_at": now,
+			"updated_at":    now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListDeliveries 分页查询投递记录
+func (r *Repository) ListDeliveries(ctx context.Context, query *domain.DeliveryQuery) ([]*domain.Delivery, int64, error) {
+	db := r.DB(ctx).Model(&domain.Delivery{})
+	if query.ChannelID > 0 {
+		db = db.Where("channel_id = ?", query.ChannelID)
+	}
+	if query.AppID > 0 {
+		db = db.Where("app_id = ?", query.AppID)
+	}
+	if query.EventType != "" {
+		db = db.Where("event_type = ?", query.EventType)
+	}
+	if query.EventID != "" {
+		db = db.Where("event_id = ?", query.EventID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []*domain.Delivery
+	err := db.Order("id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"devops-platform/internal/deploy-system/notification/internal/repository"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// dispatcherWorkers 投递协程数
+	dispatcherWorkers = 4
+	// dispatcherQueueSize 投递队列长度，队列满时由轮询补偿
+	dispatcherQueueSize = 1024
+	// dispatcherPollInterval 轮询到期重试记录的周期
+	dispatcherPollInterval = 5 * time.Second
+	// dispatcherPollBatch 每次轮询的最大记录数
+	dispatcherPollBatch = 100
+)
+
+// Dispatcher 异步投递通知，失败后按指数退避重试，投递结果写入投递记录表
+type Dispatcher struct {
+	Repo    *repository.Repository `inject:"NotificationRepository"`
+	Senders map[string]Sender
+
+	queue chan types.Long
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		Senders: NewSenders(&http.Client{Timeout: domain.SendTimeout}),
+		queue:   make(chan types.Long, dispatcherQueueSize),
+	}
+}
+
+func (d *Dispatcher) StartOrder() int {
+	return 10
+}
+
+func (d *Dispatcher) StopOrder() int {
+	return 0
+}
+
+// Start 启动投递协程与重试轮询
+func (d *Dispatcher) Start() {
+	d.stop = make(chan struct{})
+	for i := 0; i < dispatcherWorkers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	d.wg.Add(1)
+	go d.poll()
+	logrus.WithField("workers", dispatcherWorkers).Info("通知投递任务已启动")
+}
+
+// Stop 停止投递，未完成的记录在下次启动后由轮询继续投递
+func (d *Dispatcher) Stop() {
+	if d.stop == nil {
+		return
+	}
+	close(d.stop)
+	d.wg.Wait()
+	logrus.Info("通知投递任务已停止")
+}
+
+// Enqueue 将投递记录加入队列，队列已满时跳过，等待轮询补偿
+func (d *Dispatcher) Enqueue(ids ...types.Long) {
+	for _, id := range ids {
+		select {
+		case d.queue <- id:
+		default:
+			logrus.WithField("delivery_id", id).Warn("通知投递队列已满，等待轮询投递")
+		}
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case id := <-d.queue:
+			if err := d.Deliver(context.Background(), id); err != nil {
+				logrus.WithError(err).WithField("delivery_id", id).Error("投递通知失败")
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) poll() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(dispatcherPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.enqueueDue(context.Background())
+		}
+	}
+}
+
+// enqueueDue 回收超时的投递中记录，并将到期的待投递记录加入队列
+func (d *Dispatcher) enqueueDue(ctx context.Context) {
+	now := time.Now()
+	if count, err := d.Repo.ResetStaleDeliveries(ctx, now.Add(-domain.SendingTimeout), now); err != nil {
+		logrus.WithError(err).Error("回收超时的通知投递失败")
+	} else if count > 0 {
+		logrus.WithField("count", count).Warn("已回收超时的通知投递")
+	}
+
+	ids, err := d.Repo.FindDueDeliveryIDs(ctx, now, dispatcherPollBatch)
+	if err != nil {
+		logrus.WithError(err).Error("查询待投递通知失败")
+		return
+	}
+	d.Enqueue(ids...)
+}
+
+// Deliver 投递一条记录，已被其他协程抢占或未到期的记录直接跳过
+func (d *Dispatcher) Deliver(ctx context.Context, id types.Long) error {
+	claimed, err := d.Repo.ClaimDelivery(ctx, id, time.Now())
+	if err != nil || !claimed {
+		return err
+	}
+	delivery, err := d.Repo.GetDeliveryByID(ctx, id)
+	if err != nil || delivery == nil {
+		return err
+	}
+
+	code, err := d.send(ctx, delivery)
+	if err != nil {
+		delivery.Failed(code, err, time.Now())
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"delivery_id": delivery.ID,
+			"channel_id":  delivery.ChannelID,
+			"attempts":    delivery.Attempts,
+			"status":      delivery.Status,
+		}).Warn("通知投递失败")
+	} else {
+		delivery.Succeeded(code, time.Now())
+	}
+	return d.Repo.SaveDelivery(ctx, delivery)
+}
+
+// send 调用渠道发送器，渠道已删除或停用时不再重试
+func (d *Dispatcher) send(ctx context.Context, delivery *domain.Delivery) (int, error) {
+	channel, err := d.Repo.GetChannelByID(ctx, delivery.ChannelID)
+	if err != nil {
+		return 0, err
+	}
+	if channel == nil || !channel.Enabled {
+		delivery.Attempts = delivery.MaxAttempts
+		return 0, errors.New("通知渠道不存在或已停用")
+	}
+	sender, ok := d.Senders[channel.Type]
+	if !ok {
+		delivery.Attempts = delivery.MaxAttempts
+		return 0, fmt.Errorf("不支持的渠道类型: %s", channel.Type)
+	}
+	event, err := delivery.Event()
+	if err != nil {
+		delivery.Attempts = delivery.MaxAttempts
+		return 0, fmt.Errorf("解析事件失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, domain.SendTimeout)
+	defer cancel()
+	return sender.Send(ctx, channel, delivery, event)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"devops-platform/internal/deploy-system/notification/internal/repository"
+	"devops-platform/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestService 使用内存SQLite创建通知服务，投递器不启动，由测试直接调用Deliver
+func newTestService(t *testing.T) (*NotificationService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err = db.AutoMigrate(&domain.Channel{}, &domain.Subscription{}, &domain.Delivery{}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := repository.NewRepository()
+	repo.Inject(func(string) interface{} { return db })
+	dispatcher := NewDispatcher()
+	dispatcher.Repo = repo
+	return &NotificationService{Repo: repo, Dispatcher: dispatcher}, db
+}
+
+func createChannel(t *testing.T, db *gorm.DB, name, url string, enabled bool) *domain.Channel {
+	channel := &domain.Channel{Name: name, Type: domain.ChannelTypeSlack, URL: url, Enabled: enabled}
+	if err := db.Create(channel).Error; err != nil {
+		t.Fatal(err)
+	}
+	return channel
+}
+
+func createSubscription(t *testing.T, db *gorm.DB, channelID, appID, envID types.Long, events string) {
+	subscription := &domain.Subscription{ChannelID: channelID, AppID: appID, EnvID: envID, Events: events, Enabled: true}
+	if err := db.Create(subscription).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func queuedIDs(dispatcher *Dispatcher) []types.Long {
+	ids := make([]types.Long, 0)
+	for {
+		select {
+		case id := <-dispatcher.queue:
+			ids = append(ids, id)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestPublishMatchesSubscriptions(t *testing.T) {
+	s, db := newTestService(t)
+	all := createChannel(t, db, "all", "http://127.0.0.1/all", true)
+	prod := createChannel(t, db, "prod", "http://127.0.0.1/prod", true)
+	other := createChannel(t, db, "other-app", "http://127.0.0.1/other", true)
+	disabled := createChannel(t, db, "disabled", "http://127.0.0.1/disabled", false)
+
+	createSubscription(t, db, all.ID, 0, 0, "deploy.failed,deploy.succeeded")
+	createSubscription(t, db, all.ID, 1, 0, "deploy.succeeded") // 同一渠道重复匹配只投递一次
+	createSubscription(t, db, prod.ID, 1, 2, "deploy.succeeded")
+	createSubscription(t, db, prod.ID, 1, 3, "deploy.succeeded")
+	createSubscription(t, db, other.ID, 9, 0, "deploy.succeeded")
+	createSubscription(t, db, disabled.ID, 0, 0, "deploy.succeeded")
+
+	s.Publish(context.Background(), testEvent())
+
+	var deliveries []*domain.Delivery
+	if err := db.Order("channel_id").Find(&deliveries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("应生成2条投递记录，实际 %d", len(deliveries))
+	}
+	if deliveries[0].ChannelID != all.ID || deliveries[1].ChannelID != prod.ID {
+		t.Fatalf("投递渠道错误: %d, %d", deliveries[0].ChannelID, deliveries[1].ChannelID)
+	}
+	for _, delivery := range deliveries {
+		if delivery.Status != domain.DeliveryStatusPending || delivery.EventID != "evt-1" || delivery.MaxAttempts != domain.DefaultMaxAttempts {
+			t.Fatalf("投递记录错误: %+v", delivery)
+		}
+	}
+	if ids := queuedIDs(s.Dispatcher); len(ids) != 2 {
+		t.Fatalf("应加入投递队列2条，实际 %d", len(ids))
+	}
+
+	// 不匹配任何订阅的事件不生成投递记录
+	event := testEvent()
+	event.Type = domain.EventPlanApproved
+	s.Publish(context.Background(), event)
+	var count int64
+	db.Model(&domain.Delivery{}).Count(&count)
+	if count != 2 {
+		t.Fatalf("未订阅的事件不应投递，实际记录数 %d", count)
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s, db := newTestService(t)
+	channel := createChannel(t, db, "slack", server.URL, true)
+	createSubscription(t, db, channel.ID, 0, 0, "deploy.succeeded")
+	s.Publish(context.Background(), testEvent())
+	ids := queuedIDs(s.Dispatcher)
+	if len(ids) != 1 {
+		t.Fatalf("应加入投递队列1条，实际 %d", len(ids))
+	}
+	id := ids[0]
+
+	// 首次投递失败，按退避时间安排重试
+	before := time.Now()
+	if err := s.Dispatcher.Deliver(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	delivery, _ := s.Repo.GetDeliveryByID(context.Background(), id)
+	if delivery.Status != domain.DeliveryStatusPending || delivery.Attempts != 1 || delivery.ResponseCode != http.StatusServiceUnavailable {
+		t.Fatalf("首次失败后的记录错误: %+v", delivery)
+	}
+	if delivery.NextRetryAt == nil || delivery.NextRetryAt.Before(before.Add(domain.RetryBaseInterval-time.Second)) {
+		t.Fatalf("下次投递时间错误: %v", delivery.NextRetryAt)
+	}
+
+	// 未到重试时间不投递
+	if err := s.Dispatcher.Deliver(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("未到重试时间不应投递，实际调用 %d 次", calls)
+	}
+
+	// 到期后重试成功
+	if err := db.Model(&domain.Delivery{}).Where("id = ?", id).Update("next_retry_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := s.Repo.FindDueDeliveryIDs(context.Background(), time.Now(), 10); err != nil || len(ids) != 1 {
+		t.Fatalf("应查询到1条到期记录: %v, %v", ids, err)
+	}
+	if err := s.Dispatcher.Deliver(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	delivery, _ = s.Repo.GetDeliveryByID(context.Background(), id)
+	if delivery.Status != domain.DeliveryStatusSuccess || delivery.Attempts != 2 || delivery.DeliveredAt == nil || delivery.LastError != "" {
+		t.Fatalf("重试成功后的记录错误: %+v", delivery)
+	}
+}
+
+func TestDeliverGivesUpAfterMaxAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	s, db := newTestService(t)
+	channel := createChannel(t, db, "slack", server.URL, true)
+	createSubscription(t, db, channel.ID, 0, 0, "deploy.succeeded")
+	s.Publish(context.Background(), testEvent())
+	id := queuedIDs(s.Dispatcher)[0]
+	if err := db.Model(&domain.Delivery{}).Where("id = ?", id).Update("max_attempts", 1).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Dispatcher.Deliver(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	delivery, _ := s.Repo.GetDeliveryByID(context.Background(), id)
+	if delivery.Status != domain.DeliveryStatusFailed || delivery.NextRetryAt != nil || delivery.LastError == "" {
+		t.Fatalf("重试耗尽后应标记为失败: %+v", delivery)
+	}
+
+	// 手动重试再投递一次
+	if err := s.RetryDelivery(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Dispatcher.Deliver(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	delivery, _ = s.Repo.GetDeliveryByID(context.Background(), id)
+	if delivery.Status != domain.DeliveryStatusFailed || delivery.Attempts != 2 {
+		t.Fatalf("手动重试后的记录错误: %+v", delivery)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		3:  40 * time.Second,
+		6:  320 * time.Second,
+		7:  domain.RetryMaxInterval,
+		20: domain.RetryMaxInterval,
+	}
+	for attempts, expected := range cases {
+		if delay := domain.RetryDelay(attempts); delay != expected {
+			t.Errorf("第%d次失败后的重试间隔应为%s，实际%s", attempts, expected, delay)
+		}
+	}
+}
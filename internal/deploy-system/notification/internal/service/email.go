@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpsPort 隐式TLS的SMTP端口
+const smtpsPort = 465
+
+// EmailSender SMTP邮件发送器，服务器支持时自动使用STARTTLS，465端口使用隐式TLS
+type EmailSender struct {
+	Timeout time.Duration
+}
+
+// Send 发送纯文本邮件，返回SMTP应答码
+func (s *EmailSender) Send(ctx context.Context, channel *domain.Channel, delivery *domain.Delivery, event *domain.Event) (code int, err error) {
+	defer func() {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) {
+			code = protoErr.Code
+		}
+	}()
+
+	from, err := mail.ParseAddress(channel.SMTPFrom)
+	if err != nil {
+		return 0, err
+	}
+	recipients := channel.RecipientList()
+	if len(recipients) == 0 {
+		return 0, errors.New("收件人不能为空")
+	}
+
+	conn, err := s.dial(ctx, channel)
+	if err != nil {
+		return 0, err
+	}
+	client, err := smtp.NewClient(conn, channel.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return 0, err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && channel.SMTPPort != smtpsPort {
+		if err = client.StartTLS(&tls.Config{ServerName: channel.SMTPHost}); err != nil {
+			return 0, err
+		}
+	}
+	if channel.SMTPUsername != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			auth := smtp.PlainAuth("", channel.SMTPUsername, channel.SMTPPassword, channel.SMTPHost)
+			if err = client.Auth(auth); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err = client.Mail(from.Address); err != nil {
+		return 0, err
+	}
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return 0, err
+		}
+		if err = client.Rcpt(address.Address); err != nil {
+			return 0, err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return 0, err
+	}
+	if _, err = writer.Write(BuildEmail(from.String(), recipients, event)); err != nil {
+		return 0, err
+	}
+	if err = writer.Close(); err != nil {
+		return 0, err
+	}
+	if err = client.Quit(); err != nil {
+		return 0, err
+	}
+	return 250, nil
+}
+
+// dial 建立SMTP连接并设置整体超时
+func (s *EmailSender) dial(ctx context.Context, channel *domain.Channel) (net.Conn, error) {
+	address := net.JoinHostPort(channel.SMTPHost, strconv.Itoa(channel.SMTPPort))
+	dialer := &net.Dialer{Timeout: s.Timeout}
+
+	var conn net.Conn
+	var err error
+	if channel.SMTPPort == smtpsPort {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: channel.SMTPHost}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.Timeout > 0 {
+		if err = conn.SetDeadline(time.Now().Add(s.Timeout)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// BuildEmail 构建UTF-8纯文本邮件，正文使用base64编码
+func BuildEmail(from string, recipients []string, event *domain.Event) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString("From: " + from + "\r\n")
+	buffer.WriteString("To: " + strings.Join(recipients, ", ") + "\r\n")
+	buffer.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", event.Title()) + "\r\n")
+	buffer.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buffer.WriteString(domain.HeaderEvent + ": " + event.Type + "\r\n")
+	buffer.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(event.Text()))
+	for len(encoded) > 76 {
+		buffer.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buffer.WriteString(encoded + "\r\n")
+	return buffer.Bytes()
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpMessage SMTP桩服务器收到的邮件
+type smtpMessage struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// startSMTPStub 启动本地SMTP桩服务器，rejected中的收件人返回550
+func startSMTPStub(t *testing.T, rejected ...string) (string, int, chan smtpMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan smtpMessage, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, rejected, messages)
+		}
+	}()
+
+	address := listener.Addr().(*net.TCPAddr)
+	return address.IP.String(), address.Port, messages
+}
+
+func serveSMTP(conn net.Conn, rejected []string, messages chan smtpMessage) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = io.WriteString(conn, line+"\r\n")
+	}
+
+	var message smtpMessage
+	reply("220 localhost ESMTP stub")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message.from = smtpPath(line)
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			recipient := smtpPath(line)
+			if contains(rejected, recipient) {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			message.recipients = append(message.recipients, recipient)
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			message.data = data.String()
+			reply("250 OK queued")
+			messages <- message
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// smtpPath 取MAIL FROM/RCPT TO命令中尖括号内的地址，忽略BODY=8BITMIME等参数
+func smtpPath(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEmailSender(t *testing.T) {
+	host, port, messages := startSMTPStub(t)
+	channel := &domain.Channel{
+		Type:       domain.ChannelTypeEmail,
+		SMTPHost:   host,
+		SMTPPort:   port,
+		SMTPFrom:   "DevOps <devops@example.com>",
+		Recipients: "ops@example.com,dev@example.com",
+	}
+	event := testEvent()
+
+	code, err := (&EmailSender{Timeout: 5 * time.Second}).Send(context.Background(), channel, testDelivery(t, event), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 250 {
+		t.Fatalf("应答码错误: %d", code)
+	}
+
+	message := <-messages
+	if message.from != "devops@example.com" {
+		t.Fatalf("发件人错误: %s", message.from)
+	}
+	if strings.Join(message.recipients, ",") != "ops@example.com,dev@example.com" {
+		t.Fatalf("收件人错误: %v", message.recipients)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(message.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != event.Title() {
+		t.Fatalf("主题错误: %s", subject)
+	}
+	body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, parsed.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != event.Text() {
+		t.Fatalf("正文错误: %s", body)
+	}
+}
+
+func TestEmailSenderRejectedRecipient(t *testing.T) {
+	host, port, _ := startSMTPStub(t, "nobody@example.com")
+	channel := &domain.Channel{
+		Type:       domain.ChannelTypeEmail,
+		SMTPHost:   host,
+		SMTPPort:   port,
+		SMTPFrom:   "devops@example.com",
+		Recipients: "nobody@example.com",
+	}
+	event := testEvent()
+
+	code, err := (&EmailSender{Timeout: 5 * time.Second}).Send(context.Background(), channel, testDelivery(t, event), event)
+	if err == nil {
+		t.Fatal("收件人被拒绝时应返回错误")
+	}
+	if code != 550 {
+		t.Fatalf("应答码应为550，实际 %d", code)
+	}
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"devops-platform/internal/deploy-system/notification/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/internal/pkg/security"
+	"devops-platform/pkg/types"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// NotificationService 通知服务实现，管理渠道与订阅，并将事件分发给匹配的订阅
+type NotificationService struct {
+	service.Service
+	Repo       *repository.Repository `inject:"NotificationRepository"`
+	Dispatcher *Dispatcher            `inject:"NotificationDispatcher"`
+}
+
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+// Publish 发布事件：为每个匹配的订阅生成投递记录并异步投递，失败只记录日志，不影响业务流程
+func (s *NotificationService) Publish(ctx context.Context, event *domain.Event) {
+	if err := s.publish(ctx, event); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"event":  event.Type,
+			"app_id": event.AppID,
+			"env_id": event.EnvID,
+		}).Error("发布通知事件失败")
+	}
+}
+
+func (s *NotificationService) publish(ctx context.Context, event *domain.Event) error {
+	if !domain.IsEventType(event.Type) {
+		return errors.New("不支持的事件类型: " + event.Type)
+	}
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.Operator == "" {
+		if user := security.GetUserContext(ctx); user != nil {
+			event.Operator = user.RealName
+		}
+	}
+
+	subscriptions, err := s.Repo.FindCandidateSubscriptions(ctx, event.AppID, event.EnvID)
+	if err != nil {
+		return err
+	}
+	matched := make([]*domain.Subscription, 0, len(subscriptions))
+	channelIDs := make([]types.Long, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if subscription.Matches(event) {
+			matched = append(matched, subscription)
+			channelIDs = append(channelIDs, subscription.ChannelID)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	channels, err := s.Repo.FindChannels(ctx, channelIDs)
+	if err != nil {
+		return err
+	}
+	enabled := make(map[types.Long]*domain.Channel, len(channels))
+	for _, channel := range channels {
+		if channel.Enabled {
+			enabled[channel.ID] = channel
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	deliveries := make([]*domain.Delivery, 0, len(matched))
+	notified := make(map[types.Long]bool, len(matched))
+	for _, subscription := range matched {
+		channel, ok := enabled[subscription.ChannelID]
+		// 同一渠道的多个订阅同时匹配时只投递一次
+		if !ok || notified[channel.ID] {
+			continue
+		}
+		notified[channel.ID] = true
+		deliveries = append(deliveries, &domain.Delivery{
+			EventID:        event.ID,
+			EventType:      event.Type,
+			ChannelID:      channel.ID,
+			ChannelType:    channel.Type,
+			SubscriptionID: subscription.ID,
+			AppID:          event.AppID,
+			EnvID:          event.EnvID,
+			Payload:        string(payload),
+			Status:         domain.DeliveryStatusPending,
+			MaxAttempts:    domain.DefaultMaxAttempts,
+			NextRetryAt:    &now,
+		})
+	}
+	if err = s.Repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+
+	ids := make([]types.Long, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	s.Dispatcher.Enqueue(ids...)
+	return nil
+}
+
+// CreateChannel 创建通知渠道
+func (s *NotificationService) CreateChannel(ctx context.Context, command *domain.SaveChannelCommand) (types.Long, error) {
+	channel := &domain.Channel{Enabled: true}
+	if err := command.ApplyTo(channel); err != nil {
+		return 0, common.RequestParamError("", err)
+	}
+	if err := s.checkChannelName(ctx, 0, channel.Name); err != nil {
+		return 0, err
+	}
+
+	channel.AuditCreated(ctx)
+	if err := s.Repo.SaveChannel(ctx, channel); err != nil {
+		return 0, common.InternalError("创建通知渠道失败", err)
+	}
+	return channel.ID, nil
+}
+
+// UpdateChannel 更新通知渠道，密钥与密码为空时保持不变
+func (s *NotificationService) UpdateChannel(ctx context.Context, command *domain.SaveChannelCommand) error {
+	channel, err := s.getChannel(ctx, command.ID)
+	if err != nil {
+		return err
+	}
+	if err = command.ApplyTo(channel); err != nil {
+		return common.RequestParamError("", err)
+	}
+	if err = s.checkChannelName(ctx, channel.ID, channel.Name); err != nil {
+		return err
+	}
+
+	channel.AuditModified(ctx)
+	if err = s.Repo.SaveChannel(ctx, channel); err != nil {
+		return common.InternalError("更新通知渠道失败", err)
+	}
+	return nil
+}
+
+// DeleteChannel 删除通知渠道及其订阅
+func (s *NotificationService) DeleteChannel(ctx context.Context, id types.Long) (err error) {
+	if _, err = s.getChannel(ctx, id); err != nil {
+		return err
+	}
+
+	ctx, err = s.BeginTransaction(ctx, "delete notify channel")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = s.FinishTransaction(ctx, err, "delete notify channel")
+	}()
+
+	if err = s.Repo.DeleteChannel(ctx, id); err != nil {
+		return common.InternalError("删除通知渠道失败", err)
+	}
+	return nil
+}
+
+// GetChannel 获取通知渠道
+func (s *NotificationService) GetChannel(ctx context.Context, id types.Long) (*domain.ChannelVO, error) {
+	channel, err := s.getChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return channel.ToVO(), nil
+}
+
+// ListChannels 查询通知渠道列表
+func (s *NotificationService) ListChannels(ctx context.Context) ([]*domain.ChannelVO, error) {
+	channels, err := s.Repo.ListChannels(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询通知渠道失败", err)
+	}
+	vos := make([]*domain.ChannelVO, 0, len(channels))
+	for _, channel := range channels {
+		vos = append(vos, channel.ToVO())
+	}
+	return vos, nil
+}
+
+// TestChannel 同步发送一条测试消息，用于验证渠道配置，不生成投递记录
+func (s *NotificationService) TestChannel(ctx context.Context, id types.Long) error {
+	channel, err := s.getChannel(ctx, id)
+	if err != nil {
+		return err
+	}
+	sender, ok := s.Dispatcher.Senders[channel.Type]
+	if !ok {
+		return common.RequestParamError("", errors.New("不支持的渠道类型: "+channel.Type))
+	}
+
+	event := &domain.Event{
+		ID:         uuid.NewString(),
+		Type:       domain.EventDeploySucceeded,
+		AppName:    "demo-app",
+		EnvName:    "test",
+		Version:    "v0.0.0",
+		Message:    "这是一条测试消息",
+		OccurredAt: time.Now(),
+	}
+	if user := security.GetUserContext(ctx); user != nil {
+		event.Operator = user.RealName
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return common.InternalError("构建测试消息失败", err)
+	}
+	delivery := &domain.Delivery{EventID: event.ID, EventType: event.Type, ChannelID: channel.ID, Payload: string(payload)}
+
+	ctx, cancel := context.WithTimeout(ctx, domain.SendTimeout)
+	defer cancel()
+	if _, err = sender.Send(ctx, channel, delivery, event); err != nil {
+		return common.RequestParamError("", errors.New("测试消息发送失败: "+err.Error()))
+	}
+	return nil
+}
+
+// CreateSubscription 创建通知订阅
+func (s *NotificationService) CreateSubscription(ctx context.Context, command *domain.SaveSubscriptionCommand) (types.Long, error) {
+	subscription := &domain.Subscription{Enabled: true}
+	if err := command.ApplyTo(subscription); err != nil {
+		return 0, common.RequestParamError("", err)
+	}
+	if _, err := s.getChannel(ctx, subscription.ChannelID); err != nil {
+		return 0, err
+	}
+
+	subscription.AuditCreated(ctx)
+	if err := s.Repo.SaveSubscription(ctx, subscription); err != nil {
+		return 0, common.InternalError("创建通知订阅失败", err)
+	}
+	return subscription.ID, nil
+}
+
+// UpdateSubscription 更新通知订阅
+func (s *NotificationService) UpdateSubscription(ctx context.Context, command *domain.SaveSubscriptionCommand) error {
+	subscription, err := s.Repo.GetSubscriptionByID(ctx, command.ID)
+	if err != nil {
+		return common.InternalError("查询通知订阅失败", err)
+	}
+	if subscription == nil {
+		return common.NotFoundError("通知订阅不存在", nil)
+	}
+	if err = command.ApplyTo(subscription); err != nil {
+		return common.RequestParamError("", err)
+	}
+	if _, err = s.getChannel(ctx, subscription.ChannelID); err != nil {
+		return err
+	}
+
+	subscription.AuditModified(ctx)
+	if err = s.Repo.SaveSubscription(ctx, subscription); err != nil {
+		return common.InternalError("更新通知订阅失败", err)
+	}
+	return nil
+}
+
+// DeleteSubscription 删除通知订阅
+func (s *NotificationService) DeleteSubscription(ctx context.Context, id types.Long) error {
+	subscription, err := s.Repo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return common.InternalError("查询通知订阅失败", err)
+	}
+	if subscription == nil {
+		return common.NotFoundError("通知订阅不存在", nil)
+	}
+	if err = s.Repo.DeleteSubscription(ctx, id); err != nil {
+		return common.InternalError("删除通知订阅失败", err)
+	}
+	return nil
+}
+
+// ListSubscriptions 查询通知订阅列表
+func (s *NotificationService) ListSubscriptions(ctx context.Context, query *domain.SubscriptionQuery) ([]*domain.SubscriptionVO, error) {
+	subscriptions, err := s.Repo.ListSubscriptions(ctx, query)
+	if err != nil {
+		return nil, common.InternalError("查询通知订阅失败", err)
+	}
+	channels, err := s.Repo.ListChannels(ctx)
+	if err != nil {
+		return nil, common.InternalError("查询通知渠道失败", err)
+	}
+	channelMap := make(map[types.Long]*domain.Channel, len(channels))
+	for _, channel := range channels {
+		channelMap[channel.ID] = channel
+	}
+
+	vos := make([]*domain.SubscriptionVO, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		vo := subscription.ToVO()
+		if channel, ok := channelMap[subscription.ChannelID]; ok {
+			vo.ChannelName = channel.Name
+			vo.ChannelType = channel.Type
+		}
+		vos = append(vos, vo)
+	}
+	return vos, nil
+}
+
+// ListDeliveries 分页查询投递记录
+func (s *NotificationService) ListDeliveries(ctx context.Context, query *domain.DeliveryQuery) ([]*domain.Delivery, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = 10
+	}
+	deliveries, total, err := s.Repo.ListDeliveries(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询投递记录失败", err)
+	}
+	return deliveries, total, nil
+}
+
+// RetryDelivery 手动重新投递一条失败的记录
+func (s *NotificationService) RetryDelivery(ctx context.Context, id types.Long) error {
+	delivery, err := s.Repo.GetDeliveryByID(ctx, id)
+	if err != nil {
+		return common.InternalError("查询投递记录失败", err)
+	}
+	if delivery == nil {
+		return common.NotFoundError("投递记录不存在", nil)
+	}
+	if delivery.Status != domain.DeliveryStatusFailed {
+		return common.RequestParamError("", errors.New("只有投递失败的记录可以重试"))
+	}
+
+	now := time.Now()
+	delivery.Status = domain.DeliveryStatusPending
+	delivery.MaxAttempts = delivery.Attempts + 1
+	delivery.NextRetryAt = &now
+	if err = s.Repo.SaveDelivery(ctx, delivery); err != nil {
+		return common.InternalError("重试投递失败", err)
+	}
+	s.Dispatcher.Enqueue(delivery.ID)
+	return nil
+}
+
+// getChannel 获取通知渠道，不存在时返回NotFound错误
+func (s *NotificationService) getChannel(ctx context.Context, id types.Long) (*domain.Channel, error) {
+	channel, err := s.Repo.GetChannelByID(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询通知渠道失败", err)
+	}
+	if channel == nil {
+		return nil, common.NotFoundError("通知渠道不存在", nil)
+	}
+	return channel, nil
+}
+
+// checkChannelName 检查渠道名称是否重复
+func (s *NotificationService) checkChannelName(ctx context.Context, id types.Long, name string) error {
+	existing, err := s.Repo.GetChannelByName(ctx, name)
+	if err != nil {
+		return common.InternalError("查询通知渠道失败", err)
+	}
+	if existing != nil && existing.ID != id {
+		return common.RequestParamError("", errors.New("渠道名称已存在: "+name))
+	}
+	return nil
+}
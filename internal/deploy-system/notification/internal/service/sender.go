@@ -0,0 +1,198 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Sender 通知渠道发送器，返回响应码（HTTP状态码或SMTP应答码）
+type Sender interface {
+	Send(ctx context.Context, channel *domain.Channel, delivery *domain.Delivery, event *domain.Event) (int, error)
+}
+
+// NewSenders 创建各渠道类型的发送器
+func NewSenders(client *http.Client) map[string]Sender {
+	return map[string]Sender{
+		domain.ChannelTypeWebhook:  &WebhookSender{Client: client},
+		domain.ChannelTypeDingTalk: &DingTalkSender{Client: client},
+		domain.ChannelTypeWeCom:    &WeComSender{Client: client},
+		domain.ChannelTypeSlack:    &SlackSender{Client: client},
+		domain.ChannelTypeEmail:    &EmailSender{Timeout: domain.SendTimeout},
+	}
+}
+
+// WebhookSender 通用Webhook，请求体为事件JSON，使用HMAC-SHA256签名
+type WebhookSender struct {
+	Client *http.Client
+}
+
+// Send 发送事件
+func (s *WebhookSender) Send(ctx context.Context, channel *domain.Channel, delivery *domain.Delivery, event *domain.Event) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		domain.HeaderEvent:     event.Type,
+		domain.HeaderDelivery:  delivery.ID.String(),
+		domain.HeaderTimestamp: timestamp,
+		domain.HeaderSignature: "sha256=" + WebhookSignature(channel.Secret, timestamp, body),
+	}
+	code, _, err := postJSON(ctx, s.Client, channel.URL, body, headers)
+	return code, err
+}
+
+// WebhookSignature 计算通用Webhook签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+func WebhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DingTalkSender 钉钉自定义机器人，配置密钥时使用加签
+type DingTalkSender struct {
+	Client *http.Client
+}
+
+// Send 发送Markdown消息
+func (s *DingTalkSender) Send(ctx context.Context, channel *domain.Channel, delivery *domain.Delivery, event *domain.Event) (int, error) {
+	target := channel.URL
+	if channel.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		signed, err := url.Parse(target)
+		if err != nil {
+			return 0, err
+		}
+		query := signed.Query()
+		query.Set("timestamp", timestamp)
+		query.Set("sign", DingTalkSignature(channel.Secret, timestamp))
+		signed.RawQuery = query.Encode()
+		target = signed.String()
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": event.Title(),
+			"text":  event.Markdown(),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	code, response, err := postJSON(ctx, s.Client, target, body, nil)
+	if err != nil {
+		return code, err
+	}
+	return code, checkRobotResponse(response)
+}
+
+// DingTalkSignature 计算钉钉加签：base64(HMAC-SHA256(secret, timestamp + "\n" + secret))
+func DingTalkSignature(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// WeComSender 企业微信群机器人
+type WeComSender struct {
+	Client *http.Client
+}
+
+// Send 发送Markdown消息
+func (s *WeComSender) Send(ctx context.Context, channel *domain.Channel, delivery *domain.Delivery, event *domain.Event) (int, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": event.Markdown(),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	code, response, err := postJSON(ctx, s.Client, channel.URL, body, nil)
+	if err != nil {
+		return code, err
+	}
+	return code, checkRobotResponse(response)
+}
+
+// SlackSender Slack兼容的Incoming Webhook（Mattermost、Rocket.Chat等同样适用）
+type SlackSender struct {
+	Client *http.Client
+}
+
+// Send 发送文本消息
+func (s *SlackSender) Send(ctx context.Context, channel *domain.Channel, delivery *domain.Delivery, event *domain.Event) (int, error) {
+	body, err := json.Marshal(map[string]string{
+		"text": event.Text(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	code, _, err := postJSON(ctx, s.Client, channel.URL, body, nil)
+	return code, err
+}
+
+// postJSON 发送JSON请求，非2xx响应视为失败
+func postJSON(ctx context.Context, client *http.Client, target string, body []byte, headers map[string]string) (int, []byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, 64*1024))
+	if err != nil {
+		return response.StatusCode, nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, data, fmt.Errorf("HTTP %d: %s", response.StatusCode, truncate(string(data), 200))
+	}
+	return response.StatusCode, data, nil
+}
+
+// checkRobotResponse 钉钉、企业微信机器人返回200时通过errcode表示是否成功
+func checkRobotResponse(data []byte) error {
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("解析机器人响应失败: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("机器人返回错误 %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// truncate 按字符截断字符串
+func truncate(value string, size int) string {
+	runes := []rune(value)
+	if len(runes) <= size {
+		return value
+	}
+	return string(runes[:size])
+}
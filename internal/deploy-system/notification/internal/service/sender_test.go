@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"devops-platform/internal/deploy-system/notification/internal/domain"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEvent() *domain.Event {
+	return &domain.Event{
+		ID:           "evt-1",
+		Type:         domain.EventDeploySucceeded,
+		AppID:        1,
+		AppName:      "order-service",
+		EnvID:        2,
+		EnvName:      "prod",
+		DeploymentID: 3,
+		Version:      "v1.2.0",
+		OccurredAt:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local),
+	}
+}
+
+func testDelivery(t *testing.T, event *domain.Event) *domain.Delivery {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &domain.Delivery{ID: 42, EventID: event.ID, EventType: event.Type, Payload: string(payload)}
+}
+
+// recordedRequest 测试服务器收到的请求
+type recordedRequest struct {
+	header http.Header
+	query  map[string]string
+	body   []byte
+}
+
+func newRecordingServer(t *testing.T, status int, response string) (*httptest.Server, chan recordedRequest) {
+	requests := make(chan recordedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query := make(map[string]string)
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		requests <- recordedRequest{header: r.Header.Clone(), query: query, body: body}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestWebhookSenderSignsPayload(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusNoContent, "")
+	channel := &domain.Channel{Type: domain.ChannelTypeWebhook, URL: server.URL, Secret: "s3cr3t"}
+	event := testEvent()
+	delivery := testDelivery(t, event)
+
+	code, err := (&WebhookSender{Client: server.Client()}).Send(context.Background(), channel, delivery, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != http.StatusNoContent {
+		t.Fatalf("响应码错误: %d", code)
+	}
+
+	request := <-requests
+	if string(request.body) != delivery.Payload {
+		t.Fatalf("请求体应为事件JSON: %s", request.body)
+	}
+	if request.header.Get(domain.HeaderEvent) != domain.EventDeploySucceeded {
+		t.Fatalf("事件头错误: %s", request.header.Get(domain.HeaderEvent))
+	}
+	if request.header.Get(domain.HeaderDelivery) != "42" {
+		t.Fatalf("投递ID头错误: %s", request.header.Get(domain.HeaderDelivery))
+	}
+	timestamp := request.header.Get(domain.HeaderTimestamp)
+	expected := "sha256=" + WebhookSignature("s3cr3t", timestamp, request.body)
+	if request.header.Get(domain.HeaderSignature) != expected {
+		t.Fatalf("签名错误: %s, 期望 %s", request.header.Get(domain.HeaderSignature), expected)
+	}
+	if WebhookSignature("other", timestamp, request.body) == WebhookSignature("s3cr3t", timestamp, request.body) {
+		t.Fatal("不同密钥的签名不应相同")
+	}
+}
+
+func TestWebhookSenderNon2xxIsError(t *testing.T) {
+	server, _ := newRecordingServer(t, http.StatusBadGateway, "upstream down")
+	channel := &domain.Channel{Type: domain.ChannelTypeWebhook, URL: server.URL, Secret: "s3cr3t"}
+	event := testEvent()
+
+	code, err := (&WebhookSender{Client: server.Client()}).Send(context.Background(), channel, testDelivery(t, event), event)
+	if err == nil {
+		t.Fatal("非2xx响应应返回错误")
+	}
+	if code != http.StatusBadGateway {
+		t.Fatalf("响应码错误: %d", code)
+	}
+}
+
+func TestDingTalkSender(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusOK, `{"errcode":0,"errmsg":"ok"}`)
+	channel := &domain.Channel{Type: domain.ChannelTypeDingTalk, URL: server.URL + "/robot/send?access_token=abc", Secret: "SEC123"}
+	event := testEvent()
+
+	if _, err := (&DingTalkSender{Client: server.Client()}).Send(context.Background(), channel, testDelivery(t, event), event); err != nil {
+		t.Fatal(err)
+	}
+
+	request := <-requests
+	if request.query["access_token"] != "abc" {
+		t.Fatalf("应保留原有的access_token: %v", request.query)
+	}
+	if request.query["sign"] != DingTalkSignature("SEC123", request.query["timestamp"]) {
+		t.Fatalf("加签错误: %v", request.query)
+	}
+
+	var message struct {
+		MsgType  string `json:"msgtype"`
+		Markdown struct {
+			Title string `json:"title"`
+			Text  string `json:"text"`
+		} `json:"markdown"`
+	}
+	if err := json.Unmarshal(request.body, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.MsgType != "markdown" || !strings.Contains(message.Markdown.Text, "order-service") {
+		t.Fatalf("消息内容错误: %s", request.body)
+	}
+}
+
+func TestDingTalkSenderErrCode(t *testing.T) {
+	server, _ := newRecordingServer(t, http.StatusOK, `{"errcode":310000,"errmsg":"sign not match"}`)
+	channel := &domain.Channel{Type: domain.ChannelTypeDingTalk, URL: server.URL, Secret: "SEC123"}
+	event := testEvent()
+
+	_, err := (&DingTalkSender{Client: server.Client()}).Send(context.Background(), channel, testDelivery(t, event), event)
+	if err == nil || !strings.Contains(err.Error(), "310000") {
+		t.Fatalf("errcode非0时应返回错误: %v", err)
+	}
+}
+
+func TestWeComSender(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusOK, `{"errcode":0,"errmsg":"ok"}`)
+	channel := &domain.Channel{Type: domain.ChannelTypeWeCom, URL: server.URL}
+	event := testEvent()
+
+	if _, err := (&WeComSender{Client: server.Client()}).Send(context.Background(), channel, testDelivery(t, event), event); err != nil {
+		t.Fatal(err)
+	}
+
+	var message struct {
+		MsgType  string `json:"msgtype"`
+		Markdown struct {
+			Content string `json:"content"`
+		} `json:"markdown"`
+	}
+	request := <-requests
+	if err := json.Unmarshal(request.body, &message); err != nil {
+		t.Fatal(err)
+	}
+	if message.MsgType != "markdown" || !strings.Contains(message.Markdown.Content, "v1.2.0") {
+		t.Fatalf("消息内容错误: %s", request.body)
+	}
+}
+
+func TestSlackSender(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusOK, "ok")
+	channel := &domain.Channel{Type: domain.ChannelTypeSlack, URL: server.URL}
+	event := testEvent()
+
+	if _, err := (&SlackSender{Client: server.Client()}).Send(context.Background(), channel, testDelivery(t, event), event); err != nil {
+		t.Fatal(err)
+	}
+
+	var message struct {
+		Text string `json:"text"`
+	}
+	request := <-requests
+	if err := json.Unmarshal(request.body, &message); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(message.Text, event.Title()) || !strings.Contains(message.Text, "prod") {
+		t.Fatalf("消息内容错误: %s", message.Text)
+	}
+}
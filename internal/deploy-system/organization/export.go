@@ -43,6 +43,9 @@ type DepartmentService interface {
 	// GetDepartmentTree 获取部门树结构
 	GetDepartmentTree(ctx context.Context) ([]*domain.DepartmentVO, error)
 
+	// GetSubDepartmentIDs 获取指定部门及其全部下级部门的ID
+	GetSubDepartmentIDs(ctx context.Context, departmentIDs ...types.Long) ([]types.Long, error)
+
 	// GetUserDepartments 获取用户所属部门
 	GetUserDepartments(ctx context.Context, userID types.Long) ([]*domain.DepartmentVO, error)
 
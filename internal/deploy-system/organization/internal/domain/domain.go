@@ -47,6 +47,36 @@ func (d *Department) ToVO() *DepartmentVO {
 	}
 }
 
+// SubDepartmentIDs 获取指定部门及其全部下级部门的ID，不存在的部门会被忽略
+func SubDepartmentIDs(departments []*Department, rootIDs ...types.Long) []types.Long {
+	exists := make(map[types.Long]bool, len(departments))
+	children := make(map[types.Long][]types.Long, len(departments))
+	for _, dept := range departments {
+		exists[dept.ID] = true
+		children[dept.ParentID] = append(children[dept.ParentID], dept.ID)
+	}
+
+	ids := make([]types.Long, 0)
+	visited := make(map[types.Long]bool)
+	queue := make([]types.Long, 0, len(rootIDs))
+	for _, id := range rootIDs {
+		if exists[id] {
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		ids = append(ids, id)
+		queue = append(queue, children[id]...)
+	}
+	return ids
+}
+
 // DepartmentVO 部门视图对象
 type DepartmentVO struct {
 	ID          types.Long      `json:"id"`
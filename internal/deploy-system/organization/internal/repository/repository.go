@@ -4,6 +4,7 @@ import (
 	"context"
 	"devops-platform/internal/common/repository"
 	"devops-platform/internal/deploy-system/organization/internal/domain"
+	"devops-platform/internal/pkg/datascope"
 	"devops-platform/internal/pkg/enum"
 	"devops-platform/pkg/types"
 	"errors"
@@ -15,6 +16,7 @@ import (
 // Repository 部门仓储实现
 type Repository struct {
 	repository.Repository
+	DataScope datascope.Resolver `inject:"DataScopeService"`
 }
 
 // NewRepository 创建仓储实例
@@ -205,7 +207,7 @@ func (r *Repository) RemoveDepartmentFromUser(ctx context.Context, userID types.
 	return r.DB(ctx).Where("user_id = ? AND department_id = ?", userID, departmentID).Delete(&domain.UserDepartment{}).Error
 }
 
-// ListDepartmentUsers 获取部门用户列表
+// ListDepartmentUsers 获取部门用户列表，按当前用户的数据权限过滤
 func (r *Repository) ListDepartmentUsers(ctx context.Context, departmentID types.Long, query *domain.UserQuery) ([]*domain.UserVO, int64, error) {
 	// 用于查询的自定义结构体
 	type UserWithType struct {
@@ -222,7 +224,8 @@ func (r *Repository) ListDepartmentUsers(ctx context.Context, departmentID types
 	db := r.DB(ctx).Table("users").
 		Select("users.id, users.username, users.nickname, users.email, users.phone, users.status, users.created_at, user_department.type").
 		Joins("JOIN user_department ON user_department.user_id = users.id").
-		Where("user_department.department_id = ?", departmentID)
+		Where("user_department.department_id = ?", departmentID).
+		Scopes(datascope.Owned(ctx, r.DataScope, "user_department.department_id", "users.id"))
 
 	// 应用查询条件
 	if query.Username != "" {
@@ -253,6 +253,17 @@ func (s *DepartmentService) GetDepartmentTree(ctx context.Context) ([]*domain.De
 	return roots, nil
 }
 
+// GetSubDepartmentIDs 获取指定部门及其全部下级部门的ID
+func (s *DepartmentService) GetSubDepartmentIDs(ctx context.Context, departmentIDs ...types.Long) ([]types.Long, error) {
+	departments, err := s.Repo.GetAllDepartments(ctx)
+	if err != nil {
+		s.Logger.WithError(err).Error("获取部门列表失败")
+		return nil, common.InternalError("获取部门列表失败", err)
+	}
+
+	return domain.SubDepartmentIDs(departments, departmentIDs...), nil
+}
+
 // GetUserDepartments 获取用户所属部门
 func (s *DepartmentService) GetUserDepartments(ctx context.Context, userID types.Long) ([]*domain.DepartmentVO, error) {
 	departments, err := s.Repo.GetUserDepartments(ctx, userID)
@@ -0,0 +1,17 @@
+package trigger
+
+import (
+	"devops-platform/internal/deploy-system/trigger/internal/domain"
+)
+
+// Bean常量
+const (
+	BeanTriggerService = domain.BeanTriggerService
+)
+
+// 领域对象类型别名
+type (
+	TriggerRule  = domain.TriggerRule
+	TriggerEvent = domain.TriggerEvent
+	Push         = domain.Push
+)
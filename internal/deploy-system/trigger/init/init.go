@@ -0,0 +1,25 @@
+package init
+
+import (
+	"devops-platform/internal/deploy-system/trigger/internal/controller"
+	"devops-platform/internal/deploy-system/trigger/internal/domain"
+	"devops-platform/internal/deploy-system/trigger/internal/repository"
+	"devops-platform/internal/deploy-system/trigger/internal/service"
+	"devops-platform/pkg/beans"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 使用标准的init函数进行初始化
+func init() {
+	// 注册仓储
+	beans.Register(domain.BeanTriggerRepository, repository.NewRepository())
+
+	// 注册服务
+	beans.Register(domain.BeanTriggerService, service.NewTriggerService())
+
+	// 注册控制器
+	beans.Register(domain.BeanTriggerController, controller.NewTriggerController())
+
+	logrus.Info("触发器模块初始化完成")
+}
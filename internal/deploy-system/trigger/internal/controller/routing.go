@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/middleware"
+	"devops-platform/internal/deploy-system/trigger/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Inject 实现依赖注入
+func (c *TriggerController) Inject(getBean func(string) interface{}) {
+	c.injectRouting(getBean)
+}
+
+// injectRouting 注入路由
+func (c *TriggerController) injectRouting(getBean func(string) interface{}) {
+	router, ok := getBean(web.BeanGinEngine).(gin.IRouter)
+	if !ok {
+		logrus.Panicf("初始化时获取[%s]失败", web.BeanGinEngine)
+		return
+	}
+
+	// 入站Webhook由代码仓库/镜像仓库调用，不走登录认证，按应用密钥校验签名
+	router.POST(domain.HookPathPrefix+":provider/:app_id", c.ReceiveHook)
+	web.AddIgnoreUrls(domain.HookPathPrefix)
+
+	// 应用触发器路由组
+	appGroup := router.Group("/api/v1/apps")
+	appGroup.Use(middleware.JWTAuth())
+	{
+		// Webhook密钥
+		appGroup.GET("/:id/webhook", c.GetWebhook)
+		appGroup.POST("/:id/webhook", c.RotateSecret)
+		appGroup.DELETE("/:id/webhook", c.DisableWebhook)
+
+		// 触发规则
+		appGroup.GET("/:id/triggers", c.ListRules)
+		appGroup.POST("/:id/triggers", c.CreateRule)
+		appGroup.PUT("/:id/triggers/:rule_id", c.UpdateRule)
+		appGroup.DELETE("/:id/triggers/:rule_id", c.DeleteRule)
+
+		// 触发记录
+		appGroup.GET("/:id/trigger-events", c.ListEvents)
+	}
+}
@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"devops-platform/internal/common/web"
+	"devops-platform/internal/deploy-system/trigger/internal/domain"
+	"devops-platform/internal/deploy-system/trigger/internal/service"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TriggerController 触发器控制器
+type TriggerController struct {
+	web.Controller
+	Service *service.TriggerService `inject:"TriggerService"`
+}
+
+// NewTriggerController 创建触发器控制器实例
+func NewTriggerController() *TriggerController {
+	return &TriggerController{}
+}
+
+// ReceiveHook 接收入站Webhook
+// @Summary 接收入站Webhook
+// @Description 无需登录，按应用密钥校验：github使用X-Hub-Signature-256，gitea使用X-Gitea-Signature，gitlab使用X-Gitlab-Token，registry使用Authorization: Bearer <secret>
+// @Tags 触发器
+// @Accept json
+// @Produce json
+// @Param provider path string true "来源: github, gitlab, gitea, registry"
+// @Param app_id path int true "应用ID"
+// @Success 200 {object} common.Response{data=domain.HookResultVO}
+// @Router /api/v1/hooks/{provider}/{app_id} [post]
+func (c *TriggerController) ReceiveHook(ctx *gin.Context) {
+	appID, err := types.StringToLong(ctx.Param("app_id"))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: 应用ID必须是数字")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, domain.MaxPayloadSize))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := c.Service.HandleHook(ctx, ctx.Param("provider"), appID, ctx.Request.Header, body)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, result)
+}
+
+// GetWebhook 获取应用Webhook
+// @Summary 获取应用Webhook
+// @Description 返回是否启用及各来源的Webhook地址，不返回密钥
+// @Tags 触发器
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response{data=domain.AppWebhookVO}
+// @Router /api/v1/apps/{id}/webhook [get]
+func (c *TriggerController) GetWebhook(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "id", "应用ID")
+	if !ok {
+		return
+	}
+	webhook, err := c.Service.GetWebhook(ctx, appID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, webhook)
+}
+
+// RotateSecret 生成应用Webhook密钥
+// @Summary 生成应用Webhook密钥
+// @Description 生成新密钥并启用Webhook，旧密钥立即失效；明文密钥只在本次返回
+// @Tags 触发器
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response{data=domain.AppWebhookVO}
+// @Router /api/v1/apps/{id}/webhook [post]
+func (c *TriggerController) RotateSecret(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "id", "应用ID")
+	if !ok {
+		return
+	}
+	webhook, err := c.Service.RotateSecret(ctx, appID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, webhook)
+}
+
+// DisableWebhook 停用应用Webhook
+// @Summary 停用应用Webhook
+// @Tags 触发器
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/webhook [delete]
+func (c *TriggerController) DisableWebhook(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "id", "应用ID")
+	if !ok {
+		return
+	}
+	if err := c.Service.DisableWebhook(ctx, appID); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ListRules 查询触发规则
+// @Summary 查询触发规则
+// @Tags 触发器
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} common.Response{data=[]domain.TriggerRule}
+// @Router /api/v1/apps/{id}/triggers [get]
+func (c *TriggerController) ListRules(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "id", "应用ID")
+	if !ok {
+		return
+	}
+	rules, err := c.Service.ListRules(ctx, appID)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, rules)
+}
+
+// CreateRule 创建触发规则
+// @Summary 创建触发规则
+// @Description 推送的分支/标签匹配pattern正则时为env_id创建发布计划，auto_execute为true时立即执行
+// @Tags 触发器
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param data body domain.SaveTriggerRuleCommand true "规则信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/triggers [post]
+func (c *TriggerController) CreateRule(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "id", "应用ID")
+	if !ok {
+		return
+	}
+	var command domain.SaveTriggerRuleCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.AppID = appID
+
+	id, err := c.Service.CreateRule(ctx, &command)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, gin.H{"id": id})
+}
+
+// UpdateRule 更新触发规则
+// @Summary 更新触发规则
+// @Tags 触发器
+// @Accept json
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param rule_id path int true "规则ID"
+// @Param data body domain.SaveTriggerRuleCommand true "规则信息"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/triggers/{rule_id} [put]
+func (c *TriggerController) UpdateRule(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "id", "应用ID")
+	if !ok {
+		return
+	}
+	ruleID, ok := pathID(ctx, "rule_id", "规则ID")
+	if !ok {
+		return
+	}
+	var command domain.SaveTriggerRuleCommand
+	if err := ctx.ShouldBindJSON(&command); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	command.ID = ruleID
+	command.AppID = appID
+
+	if err := c.Service.UpdateRule(ctx, &command); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// DeleteRule 删除触发规则
+// @Summary 删除触发规则
+// @Tags 触发器
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param rule_id path int true "规则ID"
+// @Success 200 {object} common.Response
+// @Router /api/v1/apps/{id}/triggers/{rule_id} [delete]
+func (c *TriggerController) DeleteRule(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "id", "应用ID")
+	if !ok {
+		return
+	}
+	ruleID, ok := pathID(ctx, "rule_id", "规则ID")
+	if !ok {
+		return
+	}
+	if err := c.Service.DeleteRule(ctx, appID, ruleID); err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccess(ctx, nil)
+}
+
+// ListEvents 查询触发记录
+// @Summary 查询触发记录
+// @Tags 触发器
+// @Produce json
+// @Param id path int true "应用ID"
+// @Param status query string false "状态: created, executed, ignored, failed"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Success 200 {object} common.Response{data=common.PageResult{list=[]domain.TriggerEvent}}
+// @Router /api/v1/apps/{id}/trigger-events [get]
+func (c *TriggerController) ListEvents(ctx *gin.Context) {
+	appID, ok := pathID(ctx, "id", "应用ID")
+	if !ok {
+		return
+	}
+	var query domain.TriggerEventQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	query.AppID = appID
+
+	events, total, err := c.Service.ListEvents(ctx, &query)
+	if err != nil {
+		common.ResponseError(ctx, err)
+		return
+	}
+	common.ResponseSuccessWithPageExt(ctx, events, total, query.Page, query.Size)
+}
+
+// pathID 解析路径中的ID参数，失败时直接返回400
+func pathID(ctx *gin.Context, key, name string) (types.Long, bool) {
+	id, err := types.StringToLong(ctx.Param(key))
+	if err != nil {
+		common.ResponseBadRequest(ctx, "参数错误: "+name+"必须是数字")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,54 @@
+package domain
+
+const (
+	// 模块Bean名称常量
+	BeanTriggerRepository = "TriggerRepository"
+	BeanTriggerService    = "TriggerService"
+	BeanTriggerController = "TriggerController"
+
+	// Webhook来源
+	ProviderGitHub   = "github"   // GitHub
+	ProviderGitLab   = "gitlab"   // GitLab
+	ProviderGitea    = "gitea"    // Gitea
+	ProviderRegistry = "registry" // Docker Registry通知
+
+	// 触发来源
+	SourceGit      = "git"      // 代码仓库推送
+	SourceRegistry = "registry" // 镜像推送
+
+	// 引用类型
+	RefTypeBranch = "branch" // 分支
+	RefTypeTag    = "tag"    // 标签（镜像推送的tag同样视为标签）
+
+	// 触发记录状态
+	EventStatusCreated  = "created"  // 已创建发布计划
+	EventStatusExecuted = "executed" // 已创建并执行发布计划
+	EventStatusIgnored  = "ignored"  // 没有匹配的触发规则
+	EventStatusFailed   = "failed"   // 创建或执行发布计划失败
+
+	// 签名相关请求头
+	HeaderGitHubSignature = "X-Hub-Signature-256"
+	HeaderGitHubEvent     = "X-GitHub-Event"
+	HeaderGitLabToken     = "X-Gitlab-Token"
+	HeaderGitLabEvent     = "X-Gitlab-Event"
+	HeaderGiteaSignature  = "X-Gitea-Signature"
+	HeaderGiteaEvent      = "X-Gitea-Event"
+
+	// HookPathPrefix 入站Webhook地址前缀，无需登录，按应用密钥校验
+	HookPathPrefix = "/api/v1/hooks/"
+
+	// MaxPayloadSize 入站Webhook请求体上限
+	MaxPayloadSize = 5 << 20
+
+	// SecretLength 生成的应用Webhook密钥字节数
+	SecretLength = 24
+
+	// ShortCommitLength 分支推送时作为版本号的提交SHA长度
+	ShortCommitLength = 8
+
+	// MaxVersionLength 发布计划版本号的最大长度
+	MaxVersionLength = 50
+)
+
+// Providers 支持的Webhook来源
+var Providers = []string{ProviderGitHub, ProviderGitLab, ProviderGitea, ProviderRegistry}
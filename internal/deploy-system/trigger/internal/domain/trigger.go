@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"devops-platform/internal/pkg/module"
+	"devops-platform/pkg/types"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// AppWebhook 应用的入站Webhook配置，每个应用一个密钥
+type AppWebhook struct {
+	module.Module
+	AppID   types.Long `json:"app_id" gorm:"not null;uniqueIndex;comment:'应用ID'"`
+	Secret  string     `json:"-" gorm:"size:128;not null;comment:'签名密钥'"`
+	Enabled bool       `json:"enabled" gorm:"not null;comment:'是否启用'"`
+}
+
+// TableName 返回应用Webhook表名
+func (AppWebhook) TableName() string {
+	return "app_webhook"
+}
+
+// AppWebhookVO 应用Webhook视图对象，仅在生成密钥时返回明文密钥
+type AppWebhookVO struct {
+	AppID   types.Long        `json:"app_id"`
+	Enabled bool              `json:"enabled"`
+	Secret  string            `json:"secret,omitempty"`
+	URLs    map[string]string `json:"urls"`
+}
+
+// TriggerRule 触发规则：推送的分支/标签匹配正则时，为指定环境创建发布计划
+type TriggerRule struct {
+	module.Module
+	AppID       types.Long `json:"app_id" gorm:"not null;index;comment:'应用ID'"`
+	Name        string     `json:"name" gorm:"size:64;not null;comment:'规则名称'"`
+	Source      string     `json:"source" gorm:"size:20;not null;comment:'来源: git, registry'"`
+	RefType     string     `json:"ref_type" gorm:"size:20;not null;comment:'引用类型: branch, tag'"`
+	Pattern     string     `json:"pattern" gorm:"size:255;not null;comment:'分支/标签正则'"`
+	Repository  string     `json:"repository" gorm:"size:255;comment:'仓库名，为空表示不限'"`
+	EnvID       types.Long `json:"env_id" gorm:"not null;comment:'目标环境ID'"`
+	Strategy    string     `json:"strategy" gorm:"size:50;not null;comment:'发布策略'"`
+	AutoExecute bool       `json:"auto_execute" gorm:"not null;comment:'是否自动执行发布计划'"`
+	Enabled     bool       `json:"enabled" gorm:"not null;comment:'是否启用'"`
+}
+
+// TableName 返回触发规则表名
+func (TriggerRule) TableName() string {
+	return "app_trigger_rule"
+}
+
+// Matches 判断推送是否匹配规则
+func (r *TriggerRule) Matches(push *Push) bool {
+	if !r.Enabled || r.Source != push.Source || r.RefType != push.RefType {
+		return false
+	}
+	if r.Repository != "" && !strings.EqualFold(r.Repository, push.Repository) {
+		return false
+	}
+	pattern, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return false
+	}
+	return pattern.MatchString(push.Ref)
+}
+
+// SaveTriggerRuleCommand 创建/更新触发规则命令
+type SaveTriggerRuleCommand struct {
+	ID          types.Long `json:"-"`
+	AppID       types.Long `json:"-"`
+	Name        string     `json:"name" binding:"required,max=64"`
+	Source      string     `json:"source" binding:"required"`
+	RefType     string     `json:"ref_type"`
+	Pattern     string     `json:"pattern" binding:"required,max=255"`
+	Repository  string     `json:"repository" binding:"max=255"`
+	EnvID       types.Long `json:"env_id" binding:"required"`
+	Strategy    string     `json:"strategy" binding:"required,max=50"`
+	AutoExecute bool       `json:"auto_execute"`
+	Enabled     *bool      `json:"enabled"`
+}
+
+// ApplyTo 校验命令并写入规则
+func (command *SaveTriggerRuleCommand) ApplyTo(rule *TriggerRule) error {
+	switch command.Source {
+	case SourceGit:
+		if command.RefType != RefTypeBranch && command.RefType != RefTypeTag {
+			return fmt.Errorf("不支持的引用类型: %s", command.RefType)
+		}
+	case SourceRegistry:
+		// 镜像推送只有tag
+		command.RefType = RefTypeTag
+	default:
+		return fmt.Errorf("不支持的触发来源: %s", command.Source)
+	}
+	if _, err := regexp.Compile(command.Pattern); err != nil {
+		return fmt.Errorf("正则表达式无效: %w", err)
+	}
+	command.Name = strings.TrimSpace(command.Name)
+	if command.Name == "" {
+		return errors.New("规则名称不能为空")
+	}
+
+	rule.AppID = command.AppID
+	rule.Name = command.Name
+	rule.Source = command.Source
+	rule.RefType = command.RefType
+	rule.Pattern = command.Pattern
+	rule.Repository = strings.TrimSpace(command.Repository)
+	rule.EnvID = command.EnvID
+	rule.Strategy = command.Strategy
+	rule.AutoExecute = command.AutoExecute
+	if command.Enabled != nil {
+		rule.Enabled = *command.Enabled
+	}
+	return nil
+}
+
+// Push 从各来源的Webhook中解析出的推送
+type Push struct {
+	Source     string `json:"source"`
+	RefType    string `json:"ref_type"`
+	Ref        string `json:"ref"`        // 分支名或标签名
+	Commit     string `json:"commit"`     // 提交SHA或镜像digest
+	Repository string `json:"repository"` // 代码仓库全名或镜像仓库名
+	Actor      string `json:"actor"`
+}
+
+// Version 发布计划版本号：标签推送使用标签名，分支推送使用提交SHA前8位
+func (p *Push) Version() string {
+	version := p.Ref
+	if p.RefType == RefTypeBranch {
+		version = p.Commit
+		if len(version) > ShortCommitLength {
+			version = version[:ShortCommitLength]
+		}
+	}
+	if len(version) > MaxVersionLength {
+		version = version[:MaxVersionLength]
+	}
+	return version
+}
+
+// Digest 镜像digest，只有镜像仓库推送才有，用于将发布计划固定到推送的镜像
+func (p *Push) Digest() string {
+	if p.Source != SourceRegistry {
+		return ""
+	}
+	return p.Commit
+}
+
+// TriggerEvent 入站Webhook触发记录
+type TriggerEvent struct {
+	ID         types.Long `json:"id" gorm:"primaryKey;autoIncrement"`
+	AppID      types.Long `json:"app_id" gorm:"index;comment:'应用ID'"`
+	Provider   string     `json:"provider" gorm:"size:20;comment:'Webhook来源'"`
+	EventType  string     `json:"event_type" gorm:"size:64;comment:'来源事件类型'"`
+	Source     string     `json:"source" gorm:"size:20;comment:'来源: git, registry'"`
+	RefType    string     `json:"ref_type" gorm:"size:20;comment:'引用类型'"`
+	Ref        string     `json:"ref" gorm:"size:255;comment:'分支/标签'"`
+	Commit     string     `json:"commit" gorm:"size:128;comment:'提交SHA或镜像digest'"`
+	Repository string     `json:"repository" gorm:"size:255;comment:'仓库'"`
+	Actor      string     `json:"actor" gorm:"size:128;comment:'推送人'"`
+	Version    string     `json:"version" gorm:"size:50;comment:'版本号'"`
+	RuleID     types.Long `json:"rule_id" gorm:"comment:'匹配的规则ID'"`
+	EnvID      types.Long `json:"env_id" gorm:"comment:'目标环境ID'"`
+	PlanID     types.Long `json:"plan_id" gorm:"comment:'创建的发布计划ID'"`
+	DeployID   types.Long `json:"deploy_id" gorm:"comment:'自动执行的部署ID'"`
+	Status     string     `json:"status" gorm:"size:20;index;comment:'状态: created, executed, ignored, failed'"`
+	Message    string     `json:"message" gorm:"size:1000;comment:'说明'"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index;comment:'触发时间'"`
+}
+
+// TableName 返回触发记录表名
+func (TriggerEvent) TableName() string {
+	return "app_trigger_event"
+}
+
+// NewTriggerEvent 根据推送创建触发记录
+func NewTriggerEvent(appID types.Long, provider, eventType string, push *Push) *TriggerEvent {
+	return &TriggerEvent{
+		AppID:      appID,
+		Provider:   provider,
+		EventType:  eventType,
+		Source:     push.Source,
+		RefType:    push.RefType,
+		Ref:        push.Ref,
+		Commit:     push.Commit,
+		Repository: push.Repository,
+		Actor:      push.Actor,
+		Version:    push.Version(),
+		CreatedAt:  time.Now(),
+	}
+}
+
+// TriggerEventQuery 触发记录查询条件
+type TriggerEventQuery struct {
+	AppID  types.Long `form:"-"`
+	Status string     `form:"status"`
+	Page   int        `form:"page"`
+	Size   int        `form:"size"`
+}
+
+// HookResultVO 入站Webhook处理结果
+type HookResultVO struct {
+	EventType string          `json:"event_type"`
+	Pushes    int             `json:"pushes"`
+	Events    []*TriggerEvent `json:"events"`
+}
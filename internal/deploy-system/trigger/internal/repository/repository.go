@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"devops-platform/internal/common/repository"
+	"devops-platform/internal/deploy-system/trigger/internal/domain"
+	"devops-platform/pkg/types"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Repository 触发器仓储
+type Repository struct {
+	repository.Repository
+}
+
+// NewRepository 创建仓储实例
+func NewRepository() *Repository {
+	return &Repository{}
+}
+
+// GetWebhookByAppID 获取应用的Webhook配置，不存在时返回nil
+func (r *Repository) GetWebhookByAppID(ctx context.Context, appID types.Long) (*domain.AppWebhook, error) {
+	var webhook domain.AppWebhook
+	err := r.DB(ctx).Where("app_id = ?", appID).First(&webhook).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// SaveWebhook 保存应用Webhook配置
+func (r *Repository) SaveWebhook(ctx context.Context, webhook *domain.AppWebhook) error {
+	return r.DB(ctx).Save(webhook).Error
+}
+
+// GetRuleByID 根据ID获取触发规则，不存在时返回nil
+func (r *Repository) GetRuleByID(ctx context.Context, id types.Long) (*domain.TriggerRule, error) {
+	var rule domain.TriggerRule
+	err := r.DB(ctx).First(&rule, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListRules 查询应用的全部触发规则
+func (r *Repository) ListRules(ctx context.Context, appID types.Long) ([]*domain.TriggerRule, error) {
+	var rules []*domain.TriggerRule
+	if err := r.DB(ctx).Where("app_id = ?", appID).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ListEnabledRules 查询应用已启用的触发规则
+func (r *Repository) ListEnabledRules(ctx context.Context, appID types.Long) ([]*domain.TriggerRule, error) {
+	var rules []*domain.TriggerRule
+	err := r.DB(ctx).Where("app_id = ? AND enabled = ?", appID, true).Order("id").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SaveRule 保存触发规则
+func (r *Repository) SaveRule(ctx context.Context, rule *domain.TriggerRule) error {
+	return r.DB(ctx).Save(rule).Error
+}
+
+// DeleteRule 删除触发规则
+func (r *Repository) DeleteRule(ctx context.Context, id types.Long) error {
+	return r.DB(ctx).Delete(&domain.TriggerRule{}, id).Error
+}
+
+// CreateEvents 批量写入触发记录
+func (r *Repository) CreateEvents(ctx context.Context, events []*domain.TriggerEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.DB(ctx).Create(&events).Error
+}
+
+// ListEvents 分页查询触发记录
+func (r *Repository) ListEvents(ctx context.Context, query *domain.TriggerEventQuery) ([]*domain.TriggerEvent, int64, error) {
+	db := r.DB(ctx).Model(&domain.TriggerEvent{}).Where("app_id = ?", query.AppID)
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []*domain.TriggerEvent
+	err := db.Order("id DESC").
+		Offset((query.Page - 1) * query.Size).Limit(query.Size).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
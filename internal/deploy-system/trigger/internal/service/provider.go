@@ -0,0 +1,287 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"devops-platform/internal/deploy-system/trigger/internal/domain"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Provider 入站Webhook来源，负责校验请求并解析出推送
+type Provider interface {
+	// Verify 使用应用密钥校验请求
+	Verify(header http.Header, body []byte, secret string) error
+	// EventType 来源的事件类型
+	EventType(header http.Header, body []byte) string
+	// Parse 解析推送，非推送事件（如ping）返回空列表
+	Parse(header http.Header, body []byte) ([]*domain.Push, error)
+}
+
+var (
+	errMissingSignature = errors.New("缺少签名")
+	errInvalidSignature = errors.New("签名校验失败")
+)
+
+// providers 各来源的实现
+var providers = map[string]Provider{
+	domain.ProviderGitHub:   &GitHubProvider{},
+	domain.ProviderGitLab:   &GitLabProvider{},
+	domain.ProviderGitea:    &GiteaProvider{},
+	domain.ProviderRegistry: &RegistryProvider{},
+}
+
+// GitHubProvider GitHub：X-Hub-Signature-256 为 "sha256=" + hex(HMAC-SHA256(secret, body))
+type GitHubProvider struct{}
+
+func (p *GitHubProvider) Verify(header http.Header, body []byte, secret string) error {
+	signature := header.Get(domain.HeaderGitHubSignature)
+	if signature == "" {
+		return errMissingSignature
+	}
+	if !strings.HasPrefix(signature, "sha256=") {
+		return errInvalidSignature
+	}
+	return verifyHMAC(strings.TrimPrefix(signature, "sha256="), body, secret)
+}
+
+func (p *GitHubProvider) EventType(header http.Header, body []byte) string {
+	return header.Get(domain.HeaderGitHubEvent)
+}
+
+func (p *GitHubProvider) Parse(header http.Header, body []byte) ([]*domain.Push, error) {
+	if p.EventType(header, body) != "push" {
+		return nil, nil
+	}
+	var payload struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Deleted    bool   `json:"deleted"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Pusher struct {
+			Name string `json:"name"`
+		} `json:"pusher"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Deleted {
+		return nil, nil
+	}
+	return gitPush(payload.Ref, payload.After, payload.Repository.FullName, payload.Pusher.Name), nil
+}
+
+// GiteaProvider Gitea：X-Gitea-Signature 为 hex(HMAC-SHA256(secret, body))，推送内容与GitHub兼容
+type GiteaProvider struct{}
+
+func (p *GiteaProvider) Verify(header http.Header, body []byte, secret string) error {
+	signature := header.Get(domain.HeaderGiteaSignature)
+	if signature == "" {
+		return errMissingSignature
+	}
+	return verifyHMAC(signature, body, secret)
+}
+
+func (p *GiteaProvider) EventType(header http.Header, body []byte) string {
+	return header.Get(domain.HeaderGiteaEvent)
+}
+
+func (p *GiteaProvider) Parse(header http.Header, body []byte) ([]*domain.Push, error) {
+	if p.EventType(header, body) != "push" {
+		return nil, nil
+	}
+	var payload struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Pusher struct {
+			Login    string `json:"login"`
+			Username string `json:"username"`
+		} `json:"pusher"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	actor := payload.Pusher.Login
+	if actor == "" {
+		actor = payload.Pusher.Username
+	}
+	return gitPush(payload.Ref, payload.After, payload.Repository.FullName, actor), nil
+}
+
+// GitLabProvider GitLab：X-Gitlab-Token 为配置的密钥；支持推送、标签推送及合并请求合并
+type GitLabProvider struct{}
+
+func (p *GitLabProvider) Verify(header http.Header, body []byte, secret string) error {
+	token := header.Get(domain.HeaderGitLabToken)
+	if token == "" {
+		return errMissingSignature
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+func (p *GitLabProvider) EventType(header http.Header, body []byte) string {
+	return header.Get(domain.HeaderGitLabEvent)
+}
+
+func (p *GitLabProvider) Parse(header http.Header, body []byte) ([]*domain.Push, error) {
+	switch p.EventType(header, body) {
+	case "Push Hook", "Tag Push Hook":
+		var payload struct {
+			Ref          string `json:"ref"`
+			After        string `json:"after"`
+			CheckoutSHA  string `json:"checkout_sha"`
+			UserUsername string `json:"user_username"`
+			Project      struct {
+				PathWithNamespace string `json:"path_with_namespace"`
+			} `json:"project"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		commit := payload.CheckoutSHA
+		if commit == "" {
+			commit = payload.After
+		}
+		return gitPush(payload.Ref, commit, payload.Project.PathWithNamespace, payload.UserUsername), nil
+	case "Merge Request Hook":
+		var payload struct {
+			User struct {
+				Username string `json:"username"`
+			} `json:"user"`
+			Project struct {
+				PathWithNamespace string `json:"path_with_namespace"`
+			} `json:"project"`
+			ObjectAttributes struct {
+				Action         string `json:"action"`
+				State          string `json:"state"`
+				TargetBranch   string `json:"target_branch"`
+				MergeCommitSHA string `json:"merge_commit_sha"`
+			} `json:"object_attributes"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		attributes := payload.ObjectAttributes
+		// 合并请求只在合并时触发，视为目标分支的一次推送
+		if attributes.Action != "merge" || attributes.State != "merged" || attributes.MergeCommitSHA == "" {
+			return nil, nil
+		}
+		return gitPush("refs/heads/"+attributes.TargetBranch, attributes.MergeCommitSHA, payload.Project.PathWithNamespace, payload.User.Username), nil
+	}
+	return nil, nil
+}
+
+// RegistryProvider Docker Registry通知：在registry的notifications.endpoints中配置请求头
+// Authorization: Bearer <secret>，只处理带tag的manifest推送
+type RegistryProvider struct{}
+
+func (p *RegistryProvider) Verify(header http.Header, body []byte, secret string) error {
+	authorization := header.Get("Authorization")
+	if authorization == "" {
+		return errMissingSignature
+	}
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if token == authorization || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+func (p *RegistryProvider) EventType(header http.Header, body []byte) string {
+	return "push"
+}
+
+func (p *RegistryProvider) Parse(header http.Header, body []byte) ([]*domain.Push, error) {
+	var envelope struct {
+		Events []struct {
+			Action string `json:"action"`
+			Target struct {
+				Digest     string `json:"digest"`
+				Repository string `json:"repository"`
+				Tag        string `json:"tag"`
+			} `json:"target"`
+			Actor struct {
+				Name string `json:"name"`
+			} `json:"actor"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+
+	pushes := make([]*domain.Push, 0, len(envelope.Events))
+	seen := make(map[string]*domain.Push)
+	for _, event := range envelope.Events {
+		target := event.Target
+		if event.Action != "push" || target.Tag == "" {
+			continue
+		}
+		// 多架构镜像会为同一tag推送多次，只触发一次，tag指向最后推送的digest
+		key := target.Repository + ":" + target.Tag
+		if push, ok := seen[key]; ok {
+			push.Commit = target.Digest
+			continue
+		}
+		push := &domain.Push{
+			Source:     domain.SourceRegistry,
+			RefType:    domain.RefTypeTag,
+			Ref:        target.Tag,
+			Commit:     target.Digest,
+			Repository: target.Repository,
+			Actor:      event.Actor.Name,
+		}
+		seen[key] = push
+		pushes = append(pushes, push)
+	}
+	return pushes, nil
+}
+
+// gitPush 将Git引用转换为推送，删除分支/标签（after全为0）时不触发
+func gitPush(ref, commit, repository, actor string) []*domain.Push {
+	if strings.Trim(commit, "0") == "" {
+		return nil
+	}
+	push := &domain.Push{
+		Source:     domain.SourceGit,
+		Commit:     commit,
+		Repository: repository,
+		Actor:      actor,
+	}
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		push.RefType = domain.RefTypeBranch
+		push.Ref = strings.TrimPrefix(ref, "refs/heads/")
+	case strings.HasPrefix(ref, "refs/tags/"):
+		push.RefType = domain.RefTypeTag
+		push.Ref = strings.TrimPrefix(ref, "refs/tags/")
+	default:
+		return nil
+	}
+	return []*domain.Push{push}
+}
+
+// verifyHMAC 校验十六进制的HMAC-SHA256签名
+func verifyHMAC(signature string, body []byte, secret string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return errInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(expected, mac.Sum(nil)) {
+		return errInvalidSignature
+	}
+	return nil
+}
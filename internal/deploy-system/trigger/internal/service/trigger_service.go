@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"devops-platform/internal/common/service"
+	"devops-platform/internal/deploy-system/application"
+	"devops-platform/internal/deploy-system/trigger/internal/domain"
+	"devops-platform/internal/deploy-system/trigger/internal/repository"
+	"devops-platform/internal/pkg/common"
+	"devops-platform/pkg/types"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TriggerService 触发器服务：校验入站Webhook，按应用的触发规则创建发布计划
+type TriggerService struct {
+	service.Service
+	Repo          *repository.Repository     `inject:"TriggerRepository"`
+	DeployService application.DeployService  `inject:"deployService"`
+	AppQuery      application.AppQueryServer `inject:"appQuery"`
+}
+
+func NewTriggerService() *TriggerService {
+	return &TriggerService{}
+}
+
+// HandleHook 处理入站Webhook：校验签名、解析推送，并为每条匹配的规则创建（及执行）发布计划
+func (s *TriggerService) HandleHook(ctx context.Context, providerName string, appID types.Long, header http.Header, body []byte) (*domain.HookResultVO, error) {
+	provider, ok := providers[providerName]
+	if !ok {
+		return nil, common.RequestParamError("", errors.New("不支持的Webhook来源: "+providerName))
+	}
+	webhook, err := s.Repo.GetWebhookByAppID(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询应用Webhook失败", err)
+	}
+	if webhook == nil || !webhook.Enabled {
+		return nil, common.NotFoundError("应用未启用Webhook", nil)
+	}
+	if err = provider.Verify(header, body, webhook.Secret); err != nil {
+		return nil, common.UnauthorizedError("Webhook"+err.Error(), err)
+	}
+
+	pushes, err := provider.Parse(header, body)
+	if err != nil {
+		return nil, common.RequestParamError("", fmt.Errorf("解析Webhook内容失败: %w", err))
+	}
+	result := &domain.HookResultVO{
+		EventType: provider.EventType(header, body),
+		Pushes:    len(pushes),
+		Events:    make([]*domain.TriggerEvent, 0, len(pushes)),
+	}
+	if len(pushes) == 0 {
+		return result, nil
+	}
+
+	rules, err := s.Repo.ListEnabledRules(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询触发规则失败", err)
+	}
+	for _, push := range pushes {
+		result.Events = append(result.Events, s.trigger(ctx, appID, providerName, result.EventType, push, rules)...)
+	}
+	if err = s.Repo.CreateEvents(ctx, result.Events); err != nil {
+		// 发布计划已创建，记录失败不影响结果
+		logrus.WithError(err).WithField("app_id", appID).Error("保存触发记录失败")
+	}
+	return result, nil
+}
+
+// trigger 为推送匹配的每条规则创建发布计划，一条规则失败不影响其他规则
+func (s *TriggerService) trigger(ctx context.Context, appID types.Long, provider, eventType string, push *domain.Push, rules []*domain.TriggerRule) []*domain.TriggerEvent {
+	events := make([]*domain.TriggerEvent, 0)
+	for _, rule := range rules {
+		if !rule.Matches(push) {
+			continue
+		}
+		event := domain.NewTriggerEvent(appID, provider, eventType, push)
+		event.RuleID = rule.ID
+		event.EnvID = rule.EnvID
+		events = append(events, event)
+
+		planID, err := s.DeployService.CreateReleasePlan(ctx, &application.CreateReleaseCommand{
+			AppID:    appID,
+			EnvID:    rule.EnvID,
+			Version:  event.Version,
+			Strategy: rule.Strategy,
+			Digest:   push.Digest(),
+		})
+		if err != nil {
+			event.Status = domain.EventStatusFailed
+			event.Message = "创建发布计划失败: " + err.Error()
+			continue
+		}
+		event.PlanID = planID
+		event.Status = domain.EventStatusCreated
+		event.Message = fmt.Sprintf("规则[%s]已创建发布计划", rule.Name)
+		if !rule.AutoExecute {
+			continue
+		}
+
+		deployID, err := s.DeployService.ExecuteReleasePlan(ctx, planID)
+		if err != nil {
+			event.Status = domain.EventStatusFailed
+			event.Message = "执行发布计划失败: " + err.Error()
+			continue
+		}
+		event.DeployID = deployID
+		event.Status = domain.EventStatusExecuted
+		event.Message = fmt.Sprintf("规则[%s]已创建并执行发布计划", rule.Name)
+	}
+
+	if len(events) == 0 {
+		event := domain.NewTriggerEvent(appID, provider, eventType, push)
+		event.Status = domain.EventStatusIgnored
+		event.Message = "没有匹配的触发规则"
+		events = append(events, event)
+	}
+	return events
+}
+
+// GetWebhook 获取应用的Webhook配置，不返回密钥
+func (s *TriggerService) GetWebhook(ctx context.Context, appID types.Long) (*domain.AppWebhookVO, error) {
+	if err := s.checkApp(ctx, appID); err != nil {
+		return nil, err
+	}
+	webhook, err := s.Repo.GetWebhookByAppID(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询应用Webhook失败", err)
+	}
+	vo := &domain.AppWebhookVO{AppID: appID, URLs: hookURLs(appID)}
+	if webhook != nil {
+		vo.Enabled = webhook.Enabled
+	}
+	return vo, nil
+}
+
+// RotateSecret 生成新的Webhook密钥并启用，旧密钥立即失效；明文密钥只在此时返回
+func (s *TriggerService) RotateSecret(ctx context.Context, appID types.Long) (*domain.AppWebhookVO, error) {
+	if err := s.checkApp(ctx, appID); err != nil {
+		return nil, err
+	}
+	webhook, err := s.Repo.GetWebhookByAppID(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询应用Webhook失败", err)
+	}
+	if webhook == nil {
+		webhook = &domain.AppWebhook{AppID: appID}
+		webhook.AuditCreated(ctx)
+	} else {
+		webhook.AuditModified(ctx)
+	}
+
+	secret := make([]byte, domain.SecretLength)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, common.InternalError("生成Webhook密钥失败", err)
+	}
+	webhook.Secret = hex.EncodeToString(secret)
+	webhook.Enabled = true
+	if err = s.Repo.SaveWebhook(ctx, webhook); err != nil {
+		return nil, common.InternalError("保存应用Webhook失败", err)
+	}
+	return &domain.AppWebhookVO{AppID: appID, Enabled: true, Secret: webhook.Secret, URLs: hookURLs(appID)}, nil
+}
+
+// DisableWebhook 停用应用Webhook，再次生成密钥时重新启用
+func (s *TriggerService) DisableWebhook(ctx context.Context, appID types.Long) error {
+	webhook, err := s.Repo.GetWebhookByAppID(ctx, appID)
+	if err != nil {
+		return common.InternalError("查询应用Webhook失败", err)
+	}
+	if webhook == nil {
+		return common.NotFoundError("应用未配置Webhook", nil)
+	}
+	webhook.Enabled = false
+	webhook.AuditModified(ctx)
+	if err = s.Repo.SaveWebhook(ctx, webhook); err != nil {
+		return common.InternalError("停用应用Webhook失败", err)
+	}
+	return nil
+}
+
+// ListRules 查询应用的触发规则
+func (s *TriggerService) ListRules(ctx context.Context, appID types.Long) ([]*domain.TriggerRule, error) {
+	rules, err := s.Repo.ListRules(ctx, appID)
+	if err != nil {
+		return nil, common.InternalError("查询触发规则失败", err)
+	}
+	return rules, nil
+}
+
+// CreateRule 创建触发规则
+func (s *TriggerService) CreateRule(ctx context.Context, command *domain.SaveTriggerRuleCommand) (types.Long, error) {
+	rule := &domain.TriggerRule{Enabled: true}
+	if err := s.applyRule(ctx, command, rule); err != nil {
+		return 0, err
+	}
+
+	rule.AuditCreated(ctx)
+	if err := s.Repo.SaveRule(ctx, rule); err != nil {
+		return 0, common.InternalError("创建触发规则失败", err)
+	}
+	return rule.ID, nil
+}
+
+// UpdateRule 更新触发规则
+func (s *TriggerService) UpdateRule(ctx context.Context, command *domain.SaveTriggerRuleCommand) error {
+	rule, err := s.getRule(ctx, command.AppID, command.ID)
+	if err != nil {
+		return err
+	}
+	if err = s.applyRule(ctx, command, rule); err != nil {
+		return err
+	}
+
+	rule.AuditModified(ctx)
+	if err = s.Repo.SaveRule(ctx, rule); err != nil {
+		return common.InternalError("更新触发规则失败", err)
+	}
+	return nil
+}
+
+// DeleteRule 删除触发规则
+func (s *TriggerService) DeleteRule(ctx context.Context, appID, id types.Long) error {
+	if _, err := s.getRule(ctx, appID, id); err != nil {
+		return err
+	}
+	if err := s.Repo.DeleteRule(ctx, id); err != nil {
+		return common.InternalError("删除触发规则失败", err)
+	}
+	return nil
+}
+
+// ListEvents 分页查询应用的触发记录
+func (s *TriggerService) ListEvents(ctx context.Context, query *domain.TriggerEventQuery) ([]*domain.TriggerEvent, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Size <= 0 {
+		query.Size = 10
+	}
+	events, total, err := s.Repo.ListEvents(ctx, query)
+	if err != nil {
+		return nil, 0, common.InternalError("查询触发记录失败", err)
+	}
+	return events, total, nil
+}
+
+// applyRule 校验应用与目标环境后写入规则
+func (s *TriggerService) applyRule(ctx context.Context, command *domain.SaveTriggerRuleCommand, rule *domain.TriggerRule) error {
+	if err := s.checkApp(ctx, command.AppID); err != nil {
+		return err
+	}
+	if err := command.ApplyTo(rule); err != nil {
+		return common.RequestParamError("", err)
+	}
+	if _, err := s.AppQuery.GetAppEnvByID(ctx, rule.EnvID); err != nil {
+		return common.RequestParamError("", errors.New("目标环境不存在"))
+	}
+	return nil
+}
+
+// getRule 获取应用的触发规则，不存在时返回NotFound错误
+func (s *TriggerService) getRule(ctx context.Context, appID, id types.Long) (*domain.TriggerRule, error) {
+	rule, err := s.Repo.GetRuleByID(ctx, id)
+	if err != nil {
+		return nil, common.InternalError("查询触发规则失败", err)
+	}
+	if rule == nil || rule.AppID != appID {
+		return nil, common.NotFoundError("触发规则不存在", nil)
+	}
+	return rule, nil
+}
+
+// checkApp 检查应用是否存在
+func (s *TriggerService) checkApp(ctx context.Context, appID types.Long) error {
+	if _, err := s.AppQuery.GetApplicationByID(ctx, appID); err != nil {
+		return common.NotFoundError("应用不存在", err)
+	}
+	return nil
+}
+
+// hookURLs 应用在各来源中配置的Webhook地址
+func hookURLs(appID types.Long) map[string]string {
+	urls := make(map[string]string, len(domain.Providers))
+	for _, provider := range domain.Providers {
+		urls[provider] = fmt.Sprintf("%s%s/%d", domain.HookPathPrefix, provider, appID)
+	}
+	return urls
+}
@@ -16,6 +16,8 @@ const (
 	ErrorTypeForbidden = "ForbiddenError"
 	// ErrorTypeNotFound 资源不存在
 	ErrorTypeNotFound = "NotFoundError"
+	// ErrorTypeConflict 资源冲突
+	ErrorTypeConflict = "ConflictError"
 )
 
 // Error 自定义错误类型
@@ -26,6 +28,8 @@ type Error struct {
 	Message string
 	// Cause 原始错误
 	Cause error
+	// Data 随错误响应返回的数据，如冲突时当前的持有者
+	Data interface{}
 }
 
 // Error 实现error接口
@@ -105,3 +109,16 @@ func NotFoundError(message string, cause error) *Error {
 		Cause:   cause,
 	}
 }
+
+// ConflictError 创建资源冲突错误，data随错误响应返回
+func ConflictError(message string, data interface{}) *Error {
+	if message == "" {
+		message = "资源冲突"
+	}
+
+	return &Error{
+		Type:    ErrorTypeConflict,
+		Message: message,
+		Data:    data,
+	}
+}
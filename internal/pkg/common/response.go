@@ -8,6 +8,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// RequestIDKey 请求ID在gin上下文中的Key
+const RequestIDKey = "request_id"
+
 // Response 基础响应结构
 type Response struct {
 	// Code 响应码
@@ -28,6 +31,8 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 	// RequestID 请求ID
 	RequestID string `json:"request_id"`
+	// Data 错误相关的数据
+	Data interface{} `json:"data,omitempty"`
 }
 
 // ResponseSuccess 成功响应
@@ -124,6 +129,18 @@ func ResponseNotFound(ctx *gin.Context, message string, requestID ...string) {
 	ctx.JSON(http.StatusNotFound, res)
 }
 
+// ResponseConflict 资源冲突
+func ResponseConflict(ctx *gin.Context, message string, data interface{}, requestID ...string) {
+	rid := ""
+	if len(requestID) > 0 {
+		rid = requestID[0]
+	}
+
+	res := newErrorResponse(http.StatusConflict, "Conflict", message, rid)
+	res.Data = data
+	ctx.JSON(http.StatusConflict, res)
+}
+
 // ResponseInternalError 系统内部错误
 func ResponseInternalError(ctx *gin.Context, message string, err error, requestID ...string) {
 	rid := ""
@@ -145,8 +162,11 @@ func ResponseInternalError(ctx *gin.Context, message string, err error, requestI
 
 // ResponseError 通用错误处理
 func ResponseError(ctx *gin.Context, err error) {
-	// 生成请求ID
-	requestID := uuid.New().String()
+	// 优先使用请求ID中间件分配的ID
+	requestID := ctx.GetString(RequestIDKey)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
 
 	// 如果是自定义错误，按照错误类型处理
 	if commonErr, ok := err.(*Error); ok {
@@ -167,6 +187,8 @@ func ResponseError(ctx *gin.Context, err error) {
 			ResponseForbidden(ctx, commonErr.Message, requestID)
 		case ErrorTypeNotFound:
 			ResponseNotFound(ctx, commonErr.Message, requestID)
+		case ErrorTypeConflict:
+			ResponseConflict(ctx, commonErr.Message, commonErr.Data, requestID)
 		default:
 			// 内部错误及其他类型使用ResponseInternalError
 			ResponseInternalError(ctx, commonErr.Message, commonErr.Cause, requestID)
@@ -0,0 +1,83 @@
+package datascope
+
+import (
+	"context"
+	"devops-platform/internal/pkg/security"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 数据权限范围
+const (
+	ScopeAll             = "all"               // 全部数据
+	ScopeDept            = "dept"              // 本部门
+	ScopeDeptAndChildren = "dept_and_children" // 本部门及下级部门
+	ScopeOwnApps         = "own_apps"          // 仅本人应用
+	ScopeCustom          = "custom"            // 自定义部门
+)
+
+// IsValid 是否为合法的数据权限范围，空值视为全部数据
+func IsValid(scope string) bool {
+	switch scope {
+	case "", ScopeAll, ScopeDept, ScopeDeptAndChildren, ScopeOwnApps, ScopeCustom:
+		return true
+	}
+	return false
+}
+
+// BeanResolver 数据权限解析器的bean名称，仓储通过 `inject:"DataScopeService"` 注入
+const BeanResolver = "DataScopeService"
+
+// Resolver 数据权限解析器，根据用户角色计算可访问的数据范围
+type Resolver interface {
+	ResolveDataScope(ctx context.Context, user *security.UserContext) (*security.DataScope, error)
+}
+
+// Current 获取当前用户的数据范围，解析结果缓存在用户上下文中
+// 没有用户上下文（如后台任务）或未注入解析器时不做限制
+func Current(ctx context.Context, resolver Resolver) (*security.DataScope, error) {
+	user := security.GetUserContext(ctx)
+	if user == nil || resolver == nil {
+		return &security.DataScope{All: true}, nil
+	}
+	if user.DataScope != nil {
+		return user.DataScope, nil
+	}
+
+	scope, err := resolver.ResolveDataScope(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	user.DataScope = scope
+	return scope, nil
+}
+
+// Owned GORM作用域：仅保留deptColumn属于可访问部门，或userColumn为当前用户（本人数据）的记录
+func Owned(ctx context.Context, resolver Resolver, deptColumn, userColumn string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope, err := Current(ctx, resolver)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		if scope.All {
+			return db
+		}
+
+		conditions := make([]string, 0, 2)
+		args := make([]interface{}, 0, 2)
+		if deptColumn != "" && len(scope.DeptIDs) > 0 {
+			conditions = append(conditions, deptColumn+" IN ?")
+			args = append(args, scope.DeptIDs)
+		}
+		if userColumn != "" && scope.OwnApps {
+			conditions = append(conditions, userColumn+" = ?")
+			args = append(args, scope.UserID)
+		}
+		if len(conditions) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
@@ -0,0 +1,203 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 集群内ServiceAccount凭据路径
+const (
+	serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCA    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// FieldManager 平台以Server-Side Apply写入资源时的字段管理者
+const FieldManager = "devops-platform"
+
+// ErrNotInCluster 不在集群内运行，无法使用ServiceAccount访问API Server
+var ErrNotInCluster = errors.New("未在Kubernetes集群内运行，无法访问集群")
+
+// StatusError API Server返回的错误
+type StatusError struct {
+	Code    int
+	Reason  string
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("kubernetes api %d %s: %s", e.Code, e.Reason, e.Message)
+}
+
+// IsNotFound 判断错误是否为资源不存在
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound
+}
+
+// IsConflict 判断错误是否为资源已存在或版本冲突
+func IsConflict(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Code == http.StatusConflict
+}
+
+// Client 基于REST的Kubernetes API客户端，只提供平台用到的最小能力
+type Client struct {
+	Host       string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewInClusterClient 使用Pod的ServiceAccount创建客户端
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+	token, err := os.ReadFile(serviceAccountToken)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(serviceAccountCA)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("解析集群CA证书失败")
+	}
+
+	return &Client{
+		Host:  "https://" + net.JoinHostPort(host, port),
+		Token: strings.TrimSpace(string(token)),
+		HTTPClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+		},
+	}, nil
+}
+
+// Get 获取资源，out为nil时忽略响应内容
+func (c *Client) Get(ctx context.Context, path string, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, nil, out)
+}
+
+// Create 创建资源
+func (c *Client) Create(ctx context.Context, path string, in, out interface{}) error {
+	return c.Do(ctx, http.MethodPost, path, in, out)
+}
+
+// Delete 删除资源，资源不存在时返回NotFound错误
+func (c *Client) Delete(ctx context.Context, path string) error {
+	return c.Do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// Patch 以JSON Merge Patch方式更新资源
+func (c *Client) Patch(ctx context.Context, path string, patch, out interface{}) error {
+	return c.do(ctx, http.MethodPatch, path, "application/merge-patch+json", patch, out)
+}
+
+// Apply 以Server-Side Apply方式创建或更新资源，path为资源的完整路径（含名称）
+func (c *Client) Apply(ctx context.Context, path string, obj, out interface{}) error {
+	return c.do(ctx, http.MethodPatch, path+"?fieldManager="+FieldManager+"&force=true", "application/apply-patch+yaml", obj, out)
+}
+
+// Do 发送请求，请求与响应均为JSON；out为*[]byte时返回原始响应，如Pod日志
+func (c *Client) Do(ctx context.Context, method, path string, in, out interface{}) error {
+	return c.do(ctx, method, path, "application/json", in, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path, contentType string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.Host+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		status := struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		}{}
+		if json.Unmarshal(data, &status) != nil || status.Message == "" {
+			status.Message = strings.TrimSpace(string(data))
+		}
+		return &StatusError{Code: resp.StatusCode, Reason: status.Reason, Message: status.Message}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// MarshalYAML 按JSON标签将对象转换为块格式的YAML，字段顺序与结构体一致
+func MarshalYAML(obj interface{}) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	// JSON是YAML的子集，解析为节点可以保留字段顺序
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err = encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetStyle 清除JSON的流式与引号样式，由编码器按需加引号
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
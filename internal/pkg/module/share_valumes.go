@@ -7,6 +7,7 @@ import (
 	"fmt"
 )
 
+// ShareVolume 共享存储卷，NFS使用NfsServer与NfsPath，PVC使用PvcName
 type ShareVolume struct {
 	Name      string `json:"name"`
 	Type      string `json:"type"` //nfs,pvc
@@ -21,8 +22,13 @@ func (ShareVolume) GormDataType() string {
 
 // 实现 sql.Scanner 接口，Scan 将 value 扫描至 Jsonb
 func (v *ShareVolume) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
+	var bytes []byte
+	switch data := value.(type) {
+	case []byte:
+		bytes = data
+	case string:
+		bytes = []byte(data)
+	default:
 		return errors.New(fmt.Sprint("Failed to unmarshal JSON value:", value))
 	}
 
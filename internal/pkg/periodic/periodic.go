@@ -0,0 +1,76 @@
+package periodic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Task 周期任务：服务启动后在后台按固定间隔执行，注册为bean即随服务启动与停止
+type Task struct {
+	name       string
+	interval   time.Duration
+	run        func(ctx context.Context)
+	runOnStart bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New 创建周期任务，name用于日志，run每个间隔执行一次，上一次未结束时不会重叠执行
+func New(name string, interval time.Duration, run func(ctx context.Context)) *Task {
+	return &Task{name: name, interval: interval, run: run}
+}
+
+// RunOnStart 启动时立即执行一次，不等待第一个间隔
+func (t *Task) RunOnStart() *Task {
+	t.runOnStart = true
+	return t
+}
+
+func (t *Task) StartOrder() int {
+	return 10
+}
+
+func (t *Task) StopOrder() int {
+	return 0
+}
+
+// Start 启动周期任务
+func (t *Task) Start() {
+	t.stop = make(chan struct{})
+	t.wg.Add(1)
+	go t.loop()
+	logrus.WithField("interval", t.interval).Infof("%s任务已启动", t.name)
+}
+
+// Stop 停止周期任务，等待正在进行的一次执行结束
+func (t *Task) Stop() {
+	if t.stop == nil {
+		return
+	}
+	close(t.stop)
+	t.wg.Wait()
+	logrus.Infof("%s任务已停止", t.name)
+}
+
+func (t *Task) loop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	if t.runOnStart {
+		t.run(context.Background())
+	}
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.run(context.Background())
+		}
+	}
+}
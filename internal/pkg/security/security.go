@@ -26,6 +26,15 @@ type UserContext struct {
 	TokenInfo   *TokenInfo // 令牌信息
 	IP          string     // 客户端IP
 	UserAgent   string     // 用户代理
+	DataScope   *DataScope // 数据权限范围，首次查询时解析
+}
+
+// DataScope 用户可访问的数据范围，由用户所有角色的数据权限合并而成
+type DataScope struct {
+	All     bool         // 全部数据
+	UserID  types.Long   // 用户ID，用于本人应用范围
+	DeptIDs []types.Long // 可访问的部门ID
+	OwnApps bool         // 可访问本人创建的应用
 }
 
 // TokenInfo 令牌信息
@@ -94,6 +103,13 @@ func GetUserContext(ctx context.Context) *UserContext {
 			return uc
 		}
 	}
+
+	// 由gin上下文派生的标准上下文（如事务上下文），通过字符串key回溯gin上下文中的值
+	if val := ctx.Value(string(userContextKey)); val != nil {
+		if uc, ok := val.(*UserContext); ok {
+			return uc
+		}
+	}
 	return nil
 }
 
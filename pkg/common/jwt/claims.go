@@ -19,6 +19,7 @@ type Claims struct {
 	Username         string     `json:"username"` // 用户名
 	Name             string     `json:"name"`     // 真实姓名
 	Role             int        `json:"role"`     // 用户角色
+	DeptID           types.Long `json:"dept_id"`  // 部门ID
 	RegisteredClaims            // 内嵌标准的声明
 }
 
@@ -15,8 +15,13 @@ func (Envs) GormDataType() string {
 
 // 实现 sql.Scanner 接口，Scan 将 value 扫描至 Jsonb
 func (envs *Envs) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
 		return errors.New(fmt.Sprint("Failed to unmarshal JSON value:", value))
 	}
 
@@ -24,8 +29,8 @@ func (envs *Envs) Scan(value interface{}) error {
 	return err
 }
 
-// 实现 driver.Valuer 接口，Value 返回 json value
-func (envs *Envs) Value() (driver.Value, error) {
+// 实现 driver.Valuer 接口，Value 返回 json value；值接收者使map字段也能写入
+func (envs Envs) Value() (driver.Value, error) {
 
 	jsonStr, err := json.Marshal(envs)
 	if err != nil {
@@ -33,11 +33,14 @@ CREATE TABLE `user` (
 -- 2. 角色表
 CREATE TABLE `role` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '角色ID',
+  `parent_id` BIGINT DEFAULT 0 COMMENT '父角色ID，继承父角色的全部权限',
   `name` VARCHAR(128) NOT NULL COMMENT '角色名称',
   `code` VARCHAR(64) NOT NULL COMMENT '角色唯一标识符',
   `description` VARCHAR(255) DEFAULT NULL COMMENT '角色描述',
   `status` TINYINT DEFAULT 1 COMMENT '状态 1:启用 0:禁用',
   `sort_order` INT DEFAULT 0 COMMENT '排序',
+  `data_scope` VARCHAR(32) DEFAULT 'all' COMMENT '数据权限 all/dept/dept_and_children/own_apps/custom',
+  `data_scope_depts` VARCHAR(1024) DEFAULT NULL COMMENT '自定义数据权限的部门ID，逗号分隔',
   `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
   `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
@@ -45,7 +48,8 @@ CREATE TABLE `role` (
   `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
   `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
   PRIMARY KEY (`id`),
-  UNIQUE KEY `uk_code` (`code`)
+  UNIQUE KEY `uk_code` (`code`),
+  KEY `idx_parent_id` (`parent_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色表';
 
 -- 3. 权限表
@@ -62,6 +66,7 @@ CREATE TABLE `permission` (
   `status` TINYINT DEFAULT 1 COMMENT '状态 1:启用 0:禁用',
   `hidden` TINYINT(1) DEFAULT 0 COMMENT '是否隐藏',
   `sort_order` INT DEFAULT 0 COMMENT '排序',
+  `orphaned` TINYINT(1) DEFAULT 0 COMMENT 'API权限对应的路由已不存在',
   `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
   `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
@@ -69,7 +74,8 @@ CREATE TABLE `permission` (
   `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
   `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
   PRIMARY KEY (`id`),
-  KEY `idx_parent_id` (`parent_id`)
+  KEY `idx_parent_id` (`parent_id`),
+  KEY `idx_method_path` (`method`, `path`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='权限表';
 
 -- 4. 角色权限关联表
@@ -87,9 +93,12 @@ CREATE TABLE `user_role` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
   `user_id` BIGINT NOT NULL COMMENT '用户ID',
   `role_id` BIGINT NOT NULL COMMENT '角色ID',
+  `expires_at` DATETIME DEFAULT NULL COMMENT '过期时间，为空表示永久授权',
+  `reason` VARCHAR(255) DEFAULT NULL COMMENT '授权原因',
   `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   PRIMARY KEY (`id`),
-  KEY `idx_user_role` (`user_id`, `role_id`)
+  KEY `idx_user_role` (`user_id`, `role_id`),
+  KEY `idx_expires_at` (`expires_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户角色关联表';
 
 -- 6. Casbin规则表
@@ -143,6 +152,7 @@ CREATE TABLE `app` (
   `name` VARCHAR(100) NOT NULL COMMENT '应用名称',
   `description` VARCHAR(500) DEFAULT NULL COMMENT '应用描述',
   `creator` BIGINT NOT NULL COMMENT '创建者ID',
+  `dept_id` BIGINT DEFAULT 0 COMMENT '所属部门ID',
   `status` VARCHAR(20) NOT NULL DEFAULT 'active' COMMENT '应用状态',
   `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
@@ -153,7 +163,8 @@ CREATE TABLE `app` (
   `updated_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_name` (`name`),
-  KEY `idx_creator` (`creator`)
+  KEY `idx_creator` (`creator`),
+  KEY `idx_dept_id` (`dept_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用表';
 
 -- 10. 应用分组表
@@ -202,10 +213,13 @@ CREATE TABLE `app_env` (
 CREATE TABLE `app_hpa` (
   `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'HPA配置ID',
   `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL DEFAULT 0 COMMENT '环境ID，0表示应用默认配置',
   `min_replicas` INT NOT NULL DEFAULT 1 COMMENT '最小副本数',
   `max_replicas` INT NOT NULL DEFAULT 10 COMMENT '最大副本数',
-  `target_cpu` INT NOT NULL DEFAULT 80 COMMENT '目标CPU使用率',
-  `target_memory` INT DEFAULT 0 COMMENT '目标内存使用率',
+  `target_cpu` INT NOT NULL DEFAULT 0 COMMENT '目标CPU使用率，由metrics同步，0表示未设置',
+  `target_memory` INT NOT NULL DEFAULT 0 COMMENT '目标内存使用率，由metrics同步，0表示未设置',
+  `metrics` JSON COMMENT '伸缩指标：Resource、Pods、External',
+  `behavior` JSON COMMENT '扩容与缩容行为',
   `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
   `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
@@ -213,7 +227,7 @@ CREATE TABLE `app_hpa` (
   `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
   `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
   PRIMARY KEY (`id`),
-  KEY `idx_app_id` (`app_id`)
+  UNIQUE KEY `uk_hpa_app_env` (`app_id`, `env_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用HPA配置表';
 
 -- 14. 镜像仓库表
@@ -258,6 +272,9 @@ CREATE TABLE `app_release_plan` (
   `version` VARCHAR(50) NOT NULL COMMENT '版本号',
   `strategy` VARCHAR(50) NOT NULL DEFAULT 'rolling' COMMENT '发布策略',
   `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '发布状态',
+  `digest` VARCHAR(100) DEFAULT NULL COMMENT '镜像digest，为空时按标签部署',
+  `config_revision_id` BIGINT DEFAULT 0 COMMENT '晋级锁定的配置版本ID，0表示使用目标环境的最新配置',
+  `promoted_from` BIGINT DEFAULT 0 COMMENT '晋级来源部署ID，0表示不是晋级产生的计划',
   `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
   `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
@@ -278,6 +295,13 @@ CREATE TABLE `deploy_history` (
   `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '部署状态',
   `start_time` DATETIME NOT NULL COMMENT '开始时间',
   `end_time` DATETIME DEFAULT NULL COMMENT '结束时间',
+  `digest` VARCHAR(100) DEFAULT NULL COMMENT '部署的镜像digest',
+  `config_revision_id` BIGINT DEFAULT 0 COMMENT '部署使用的配置版本ID',
+  `config_revision` INT DEFAULT 0 COMMENT '部署使用的配置版本号',
+  `spec_revision` INT DEFAULT 0 COMMENT '部署使用的工作负载定义版本号',
+  `mode` TINYINT DEFAULT 0 COMMENT '部署方式：0-Deployment，1-Job',
+  `hpa_spec` JSON DEFAULT NULL COMMENT '部署时生效的HPA规格，没有HPA时为空',
+  `rollback_of` BIGINT DEFAULT 0 COMMENT '回滚替换掉的部署ID，0表示不是回滚部署',
   `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
   `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
@@ -286,7 +310,9 @@ CREATE TABLE `deploy_history` (
   `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
   PRIMARY KEY (`id`),
   KEY `idx_app_id` (`app_id`),
-  KEY `idx_env_id` (`env_id`)
+  KEY `idx_env_id` (`env_id`),
+  KEY `idx_start_time` (`start_time`),
+  KEY `idx_rollback_of` (`rollback_of`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='部署历史表';
 
 -- 18. 部署步骤表
@@ -324,4 +350,517 @@ CREATE TABLE `login_log` (
   KEY `idx_username` (`username`),
   KEY `idx_created_at` (`created_at`),
   KEY `idx_status` (`status`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录日志表';
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录日志表';
+
+-- 20. 临时角色授权申请表
+CREATE TABLE `role_grant_request` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '申请ID',
+  `user_id` BIGINT NOT NULL COMMENT '申请人用户ID',
+  `role_id` BIGINT NOT NULL COMMENT '申请的角色ID',
+  `duration_minutes` INT NOT NULL COMMENT '申请时长（分钟）',
+  `reason` VARCHAR(255) NOT NULL COMMENT '申请原因',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '状态: pending, approved, rejected',
+  `approver_id` BIGINT DEFAULT 0 COMMENT '审批人ID',
+  `approver_name` VARCHAR(255) DEFAULT NULL COMMENT '审批人姓名',
+  `approved_at` DATETIME DEFAULT NULL COMMENT '审批时间',
+  `comment` VARCHAR(255) DEFAULT NULL COMMENT '审批意见',
+  `expires_at` DATETIME DEFAULT NULL COMMENT '授权过期时间',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='临时角色授权申请表';
+
+-- 21. 角色授权日志表
+CREATE TABLE `role_grant_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '日志ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `role_id` BIGINT NOT NULL COMMENT '角色ID',
+  `role_code` VARCHAR(64) DEFAULT NULL COMMENT '角色编码',
+  `action` VARCHAR(20) NOT NULL COMMENT '动作: grant, revoke, expire',
+  `reason` VARCHAR(255) DEFAULT NULL COMMENT '原因',
+  `expires_at` DATETIME DEFAULT NULL COMMENT '授权过期时间，为空表示永久',
+  `request_id` BIGINT DEFAULT 0 COMMENT '关联的授权申请ID',
+  `operator_id` BIGINT DEFAULT 0 COMMENT '操作人ID',
+  `operator_name` VARCHAR(255) DEFAULT '系统' COMMENT '操作人姓名',
+  `operated_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色授权日志表';
+
+-- 22. 审计日志表
+CREATE TABLE `audit_log` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '日志ID',
+  `actor_id` BIGINT DEFAULT 0 COMMENT '操作人ID',
+  `actor_name` VARCHAR(128) DEFAULT NULL COMMENT '操作人',
+  `ip` VARCHAR(64) DEFAULT NULL COMMENT '客户端IP',
+  `user_agent` VARCHAR(255) DEFAULT NULL COMMENT '用户代理',
+  `action` VARCHAR(16) NOT NULL COMMENT '操作 create/update/delete',
+  `resource_type` VARCHAR(64) NOT NULL COMMENT '资源类型',
+  `resource_id` BIGINT DEFAULT 0 COMMENT '资源ID',
+  `resource_name` VARCHAR(128) DEFAULT NULL COMMENT '资源名称',
+  `before` TEXT COMMENT '变更前的字段（JSON）',
+  `after` TEXT COMMENT '变更后的字段（JSON）',
+  `request_id` VARCHAR(64) DEFAULT NULL COMMENT '请求ID',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_actor_id` (`actor_id`),
+  KEY `idx_action` (`action`),
+  KEY `idx_resource` (`resource_type`, `resource_id`),
+  KEY `idx_request_id` (`request_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='审计日志表';
+
+-- 23. 通知渠道表
+CREATE TABLE `notify_channel` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '渠道ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '渠道名称',
+  `type` VARCHAR(20) NOT NULL COMMENT '渠道类型: webhook, dingtalk, wecom, slack, email',
+  `url` VARCHAR(512) DEFAULT NULL COMMENT 'Webhook地址',
+  `secret` VARCHAR(255) DEFAULT NULL COMMENT '签名密钥',
+  `smtp_host` VARCHAR(128) DEFAULT NULL COMMENT 'SMTP服务器',
+  `smtp_port` INT DEFAULT 0 COMMENT 'SMTP端口',
+  `smtp_username` VARCHAR(128) DEFAULT NULL COMMENT 'SMTP用户名',
+  `smtp_password` VARCHAR(255) DEFAULT NULL COMMENT 'SMTP密码',
+  `smtp_from` VARCHAR(128) DEFAULT NULL COMMENT '发件人',
+  `recipients` VARCHAR(1000) DEFAULT NULL COMMENT '收件人，逗号分隔',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `description` VARCHAR(255) DEFAULT NULL COMMENT '描述',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='通知渠道表';
+
+-- 24. 通知订阅表
+CREATE TABLE `notify_subscription` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '订阅ID',
+  `channel_id` BIGINT NOT NULL COMMENT '通知渠道ID',
+  `app_id` BIGINT NOT NULL DEFAULT 0 COMMENT '应用ID，0表示全部应用',
+  `env_id` BIGINT NOT NULL DEFAULT 0 COMMENT '环境ID，0表示全部环境',
+  `events` VARCHAR(255) NOT NULL COMMENT '订阅的事件类型，逗号分隔',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  KEY `idx_channel_id` (`channel_id`),
+  KEY `idx_app_id` (`app_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='通知订阅表';
+
+-- 25. 通知投递记录表
+CREATE TABLE `notify_delivery` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '投递记录ID',
+  `event_id` VARCHAR(64) NOT NULL COMMENT '事件ID',
+  `event_type` VARCHAR(32) NOT NULL COMMENT '事件类型',
+  `channel_id` BIGINT NOT NULL COMMENT '通知渠道ID',
+  `channel_type` VARCHAR(20) DEFAULT NULL COMMENT '渠道类型',
+  `subscription_id` BIGINT DEFAULT 0 COMMENT '订阅ID',
+  `app_id` BIGINT DEFAULT 0 COMMENT '应用ID',
+  `env_id` BIGINT DEFAULT 0 COMMENT '环境ID',
+  `payload` TEXT COMMENT '事件内容（JSON）',
+  `status` VARCHAR(16) NOT NULL DEFAULT 'pending' COMMENT '状态: pending, sending, success, failed',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT '已投递次数',
+  `max_attempts` INT NOT NULL DEFAULT 5 COMMENT '最大投递次数',
+  `response_code` INT DEFAULT 0 COMMENT '最近一次响应码',
+  `last_error` VARCHAR(1000) DEFAULT NULL COMMENT '最近一次错误',
+  `next_retry_at` DATETIME(3) DEFAULT NULL COMMENT '下次投递时间',
+  `delivered_at` DATETIME(3) DEFAULT NULL COMMENT '投递成功时间',
+  `created_at` DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3) COMMENT '创建时间',
+  `updated_at` DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3) COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_event_id` (`event_id`),
+  KEY `idx_event_type` (`event_type`),
+  KEY `idx_channel_id` (`channel_id`),
+  KEY `idx_app_id` (`app_id`),
+  KEY `idx_status_retry` (`status`, `next_retry_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='通知投递记录表';
+
+-- 26. 应用Webhook表
+CREATE TABLE `app_webhook` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `secret` VARCHAR(128) NOT NULL COMMENT '签名密钥',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_app_id` (`app_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用Webhook表';
+
+-- 27. 应用触发规则表
+CREATE TABLE `app_trigger_rule` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '规则ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '规则名称',
+  `source` VARCHAR(20) NOT NULL COMMENT '来源: git, registry',
+  `ref_type` VARCHAR(20) NOT NULL COMMENT '引用类型: branch, tag',
+  `pattern` VARCHAR(255) NOT NULL COMMENT '分支/标签正则',
+  `repository` VARCHAR(255) DEFAULT NULL COMMENT '仓库名，为空表示不限',
+  `env_id` BIGINT NOT NULL COMMENT '目标环境ID',
+  `strategy` VARCHAR(50) NOT NULL DEFAULT 'rolling' COMMENT '发布策略',
+  `auto_execute` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否自动执行发布计划',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  KEY `idx_app_id` (`app_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用触发规则表';
+
+-- 28. 触发记录表
+CREATE TABLE `app_trigger_event` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '记录ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `provider` VARCHAR(20) DEFAULT NULL COMMENT 'Webhook来源',
+  `event_type` VARCHAR(64) DEFAULT NULL COMMENT '来源事件类型',
+  `source` VARCHAR(20) DEFAULT NULL COMMENT '来源: git, registry',
+  `ref_type` VARCHAR(20) DEFAULT NULL COMMENT '引用类型',
+  `ref` VARCHAR(255) DEFAULT NULL COMMENT '分支/标签',
+  `commit` VARCHAR(128) DEFAULT NULL COMMENT '提交SHA或镜像digest',
+  `repository` VARCHAR(255) DEFAULT NULL COMMENT '仓库',
+  `actor` VARCHAR(128) DEFAULT NULL COMMENT '推送人',
+  `version` VARCHAR(50) DEFAULT NULL COMMENT '版本号',
+  `rule_id` BIGINT DEFAULT 0 COMMENT '匹配的规则ID',
+  `env_id` BIGINT DEFAULT 0 COMMENT '目标环境ID',
+  `plan_id` BIGINT DEFAULT 0 COMMENT '创建的发布计划ID',
+  `deploy_id` BIGINT DEFAULT 0 COMMENT '自动执行的部署ID',
+  `status` VARCHAR(20) NOT NULL COMMENT '状态: created, executed, ignored, failed',
+  `message` VARCHAR(1000) DEFAULT NULL COMMENT '说明',
+  `created_at` DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3) COMMENT '触发时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_app_id` (`app_id`),
+  KEY `idx_status` (`status`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='触发记录表';
+
+-- 29. 应用构建配置表
+CREATE TABLE `app_build_config` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '配置ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `repo_url` VARCHAR(500) NOT NULL COMMENT '代码仓库地址',
+  `build_type` INT NOT NULL DEFAULT 0 COMMENT '分支策略: 0自定义分支, 1固定分支',
+  `branch` VARCHAR(255) DEFAULT NULL COMMENT '固定分支或默认分支',
+  `branch_pattern` VARCHAR(255) DEFAULT NULL COMMENT '自定义分支允许的分支正则，为空不限',
+  `build_method` INT NOT NULL COMMENT '构建方法',
+  `dockerfile_path` VARCHAR(255) DEFAULT 'Dockerfile' COMMENT 'Dockerfile路径',
+  `context_dir` VARCHAR(255) DEFAULT '.' COMMENT '镜像构建上下文目录',
+  `registry_id` BIGINT DEFAULT 0 COMMENT '镜像仓库ID',
+  `image_repository` VARCHAR(255) DEFAULT NULL COMMENT '镜像名，如 team/demo-app',
+  `image_builder` VARCHAR(20) DEFAULT NULL COMMENT '镜像构建工具: kaniko, buildah',
+  `runner` VARCHAR(20) NOT NULL DEFAULT 'tekton' COMMENT '构建执行器',
+  `deploy_env_id` BIGINT DEFAULT 0 COMMENT '构建成功后创建发布计划的环境，0表示不创建',
+  `deploy_strategy` VARCHAR(50) DEFAULT NULL COMMENT '发布策略',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_app_id` (`app_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用构建配置表';
+
+-- 30. 构建记录表
+CREATE TABLE `app_build` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '构建ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `number` INT NOT NULL COMMENT '应用内构建序号',
+  `branch` VARCHAR(255) DEFAULT NULL COMMENT '构建分支',
+  `commit` VARCHAR(64) DEFAULT NULL COMMENT '构建提交',
+  `build_method` INT NOT NULL COMMENT '构建方法',
+  `runner` VARCHAR(20) DEFAULT NULL COMMENT '构建执行器',
+  `namespace` VARCHAR(100) DEFAULT NULL COMMENT '执行命名空间',
+  `run_name` VARCHAR(100) DEFAULT NULL COMMENT '执行器中的运行名称',
+  `manifest` TEXT COMMENT '提交给执行器的定义',
+  `image` VARCHAR(500) DEFAULT NULL COMMENT '产出镜像，不产出镜像时为空',
+  `image_tag` VARCHAR(50) DEFAULT NULL COMMENT '镜像tag，同时作为发布计划版本号',
+  `status` INT NOT NULL DEFAULT 0 COMMENT '构建状态: -2已取消, -1失败, 0待构建, 1构建中, 3成功',
+  `message` VARCHAR(1000) DEFAULT NULL COMMENT '说明',
+  `started_at` DATETIME(3) DEFAULT NULL COMMENT '开始时间',
+  `finished_at` DATETIME(3) DEFAULT NULL COMMENT '结束时间',
+  `deploy_env_id` BIGINT DEFAULT 0 COMMENT '构建成功后创建发布计划的环境',
+  `deploy_strategy` VARCHAR(50) DEFAULT NULL COMMENT '发布策略',
+  `plan_id` BIGINT DEFAULT 0 COMMENT '构建成功后创建的发布计划ID',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_app_number` (`app_id`, `number`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='构建记录表';
+
+-- 31. 构建步骤表
+CREATE TABLE `app_build_step` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '步骤ID',
+  `build_id` BIGINT NOT NULL COMMENT '构建ID',
+  `name` VARCHAR(64) NOT NULL COMMENT '步骤名称',
+  `sort` INT NOT NULL COMMENT '顺序',
+  `status` INT NOT NULL DEFAULT 0 COMMENT '步骤状态',
+  `message` VARCHAR(1000) DEFAULT NULL COMMENT '说明',
+  `started_at` DATETIME(3) DEFAULT NULL COMMENT '开始时间',
+  `finished_at` DATETIME(3) DEFAULT NULL COMMENT '结束时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_build_id` (`build_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='构建步骤表';
+
+-- 32. 应用配置版本表
+CREATE TABLE `app_config_revision` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '配置版本ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL COMMENT '环境ID',
+  `revision` INT NOT NULL COMMENT '版本号，从1开始',
+  `envs` JSON DEFAULT NULL COMMENT '环境变量',
+  `files` JSON DEFAULT NULL COMMENT '配置文件，文件名到内容',
+  `mount_path` VARCHAR(255) DEFAULT NULL COMMENT '配置文件挂载目录',
+  `secret_refs` JSON DEFAULT NULL COMMENT '引用的Secret',
+  `checksum` VARCHAR(64) NOT NULL COMMENT '配置内容摘要',
+  `comment` VARCHAR(500) DEFAULT NULL COMMENT '变更说明',
+  `restored_id` BIGINT DEFAULT 0 COMMENT '恢复或晋级自的配置版本ID，0表示直接修改',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_app_env_revision` (`app_id`, `env_id`, `revision`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用配置版本表';
+
+-- 33. 共享存储卷表
+CREATE TABLE `app_volume` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '存储卷ID',
+  `name` VARCHAR(63) NOT NULL COMMENT '卷名称，同时作为Pod中的卷名',
+  `description` VARCHAR(500) DEFAULT NULL COMMENT '描述',
+  `env_id` BIGINT DEFAULT 0 COMMENT '限定的环境ID，0表示所有环境可用',
+  `spec` JSON NOT NULL COMMENT '存储卷定义',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`),
+  KEY `idx_env_id` (`env_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='共享存储卷表';
+
+-- 34. 应用存储卷挂载表
+CREATE TABLE `app_volume_mount` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '挂载ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL COMMENT '环境ID',
+  `volume_id` BIGINT NOT NULL COMMENT '存储卷ID',
+  `mount_path` VARCHAR(255) NOT NULL COMMENT '容器内挂载路径',
+  `sub_path` VARCHAR(255) DEFAULT NULL COMMENT '卷内子路径',
+  `read_only` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否只读',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_app_env_mount_path` (`app_id`, `env_id`, `mount_path`),
+  KEY `idx_volume_id` (`volume_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用存储卷挂载表';
+
+-- 35. 应用工作负载定义表
+CREATE TABLE `app_workload_spec` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '定义版本ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `revision` INT NOT NULL COMMENT '版本号，从1开始',
+  `spec` JSON NOT NULL COMMENT '工作负载定义',
+  `checksum` VARCHAR(64) NOT NULL COMMENT '定义内容摘要',
+  `comment` VARCHAR(500) DEFAULT NULL COMMENT '变更说明',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_app_revision` (`app_id`, `revision`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用工作负载定义表';
+
+-- 36. 应用工作负载环境覆盖表
+CREATE TABLE `app_workload_override` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '覆盖ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL COMMENT '环境ID',
+  `override` JSON NOT NULL COMMENT '覆盖内容',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_app_env` (`app_id`, `env_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用工作负载环境覆盖表';
+
+-- 37. Job运行记录表
+CREATE TABLE `app_job_run` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '运行记录ID',
+  `deploy_id` BIGINT NOT NULL COMMENT '部署记录ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL COMMENT '环境ID',
+  `job_name` VARCHAR(63) NOT NULL COMMENT 'Job名称',
+  `scheduled` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否由CronJob调度产生',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'running' COMMENT '运行状态：running、success、failed',
+  `exit_code` INT DEFAULT NULL COMMENT '容器退出码，未能获取时为空',
+  `message` VARCHAR(1000) DEFAULT NULL COMMENT '结果说明',
+  `log` TEXT COMMENT '容器日志末尾',
+  `start_time` DATETIME NOT NULL COMMENT '开始时间',
+  `end_time` DATETIME DEFAULT NULL COMMENT '结束时间',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_deploy_job` (`deploy_id`, `job_name`),
+  KEY `idx_app_env` (`app_id`, `env_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Job运行记录表';
+
+-- 38. 应用成员表
+CREATE TABLE `app_member` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '应用成员ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `user_id` BIGINT NOT NULL COMMENT '用户ID',
+  `role` VARCHAR(20) NOT NULL COMMENT '成员角色：owner、maintainer、developer、viewer',
+  `on_call` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为值班联系人',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_member_app_user` (`app_id`, `user_id`),
+  KEY `idx_member_user` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用成员表';
+
+-- 39. 应用晋级阶段表
+CREATE TABLE `app_promotion_stage` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '晋级阶段ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL COMMENT '环境ID',
+  `position` INT NOT NULL COMMENT '阶段顺序，从1开始',
+  `optional` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为可选阶段',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_stage_app_env` (`app_id`, `env_id`),
+  UNIQUE KEY `uk_stage_app_position` (`app_id`, `position`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='应用晋级阶段表';
+
+-- 40. 发布列车表
+CREATE TABLE `release_train` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '发布列车ID',
+  `name` VARCHAR(100) NOT NULL COMMENT '列车名称',
+  `description` VARCHAR(500) DEFAULT NULL COMMENT '描述',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '列车状态：pending、running、success、failed、aborted、rolled_back',
+  `stop_on_failure` TINYINT(1) NOT NULL COMMENT '有成员失败时是否停止启动新的成员',
+  `rollback_on_failure` TINYINT(1) NOT NULL COMMENT '失败时是否回滚已部署成功的成员',
+  `rollback_on_abort` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '中止时是否回滚已部署成功的成员',
+  `started_at` DATETIME DEFAULT NULL COMMENT '开始执行时间',
+  `finished_at` DATETIME DEFAULT NULL COMMENT '结束时间',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  KEY `idx_train_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='发布列车表';
+
+-- 41. 发布列车成员表
+CREATE TABLE `release_train_member` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '发布列车成员ID',
+  `train_id` BIGINT NOT NULL COMMENT '发布列车ID',
+  `plan_id` BIGINT NOT NULL COMMENT '发布计划ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL COMMENT '环境ID',
+  `version` VARCHAR(50) NOT NULL COMMENT '版本号',
+  `position` INT NOT NULL COMMENT '执行顺序',
+  `depends_on` JSON DEFAULT NULL COMMENT '依赖的成员的发布计划ID',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'waiting' COMMENT '成员状态：waiting、running、success、failed、skipped、rolled_back',
+  `deployment_id` BIGINT DEFAULT 0 COMMENT '部署记录ID',
+  `message` VARCHAR(500) DEFAULT NULL COMMENT '状态说明',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_train_member_plan` (`plan_id`),
+  KEY `idx_train_member_train` (`train_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='发布列车成员表';
+
+-- 42. 部署锁表
+CREATE TABLE `deploy_lock` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '部署锁ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL COMMENT '环境ID',
+  `kind` VARCHAR(20) NOT NULL COMMENT '锁类型：lease、freeze',
+  `deployment_id` BIGINT DEFAULT 0 COMMENT '持有租约的部署记录ID',
+  `reason` VARCHAR(500) DEFAULT NULL COMMENT '冻结原因',
+  `expires_at` DATETIME DEFAULT NULL COMMENT '过期时间，冻结为空表示直到手工解冻',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_lock_app_env_kind` (`app_id`, `env_id`, `kind`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='部署锁表';
+
+-- 43. 配置漂移表
+CREATE TABLE `app_drift_finding` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT '配置漂移ID',
+  `app_id` BIGINT NOT NULL COMMENT '应用ID',
+  `env_id` BIGINT NOT NULL COMMENT '环境ID',
+  `deployment_id` BIGINT NOT NULL COMMENT '比较时使用的部署记录ID',
+  `field` VARCHAR(20) NOT NULL COMMENT '漂移项：workload、image、replicas、hpa',
+  `expected` VARCHAR(1000) DEFAULT NULL COMMENT '期望值',
+  `actual` VARCHAR(1000) DEFAULT NULL COMMENT '实际值',
+  `status` VARCHAR(20) NOT NULL COMMENT '漂移状态：open、resolved',
+  `detected_at` DATETIME NOT NULL COMMENT '首次发现时间',
+  `last_seen_at` DATETIME NOT NULL COMMENT '最近一次发现时间',
+  `resolved_at` DATETIME DEFAULT NULL COMMENT '恢复时间',
+  `created_at` DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `created_by_id` BIGINT DEFAULT 0 COMMENT '创建人ID',
+  `created_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '创建人姓名',
+  `last_modified_at` DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
+  `last_modified_by_id` BIGINT DEFAULT 0 COMMENT '最后修改人ID',
+  `last_modified_by_name` VARCHAR(255) DEFAULT '系统' COMMENT '最后修改人姓名',
+  PRIMARY KEY (`id`),
+  KEY `idx_drift_app_env` (`app_id`, `env_id`),
+  KEY `idx_drift_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='配置漂移表';